@@ -23,6 +23,7 @@ import (
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/barriers"
 	"github.com/aacfactory/fns/clusters"
+	"github.com/aacfactory/fns/commons/jsons"
 	"github.com/aacfactory/fns/commons/procs"
 	"github.com/aacfactory/fns/commons/switchs"
 	"github.com/aacfactory/fns/commons/uid"
@@ -34,8 +35,11 @@ import (
 	"github.com/aacfactory/fns/proxies"
 	"github.com/aacfactory/fns/runtime"
 	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/metrics"
 	"github.com/aacfactory/fns/shareds"
 	"github.com/aacfactory/fns/transports"
+	_ "github.com/aacfactory/fns/transports/fast"
+	_ "github.com/aacfactory/fns/transports/standard"
 	"github.com/aacfactory/workers"
 	"os"
 	"os/signal"
@@ -74,7 +78,7 @@ func New(options ...Option) (app Application) {
 	// status
 	status := &switchs.Switch{}
 	// config
-	configRetriever, configRetrieverErr := configures.NewRetriever(opt.configRetrieverOption)
+	configRetriever, configRetrieverErr := configs.NewRetriever(opt.configRetrieverOption)
 	if configRetrieverErr != nil {
 		panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed for invalid config retriever").WithCause(configRetrieverErr)))
 		return
@@ -84,12 +88,37 @@ func New(options ...Option) (app Application) {
 		panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed, get config via retriever failed").WithCause(configureErr)))
 		return
 	}
+	configRaw, interpolateErr := configs.Interpolate(configure.Raw())
+	if interpolateErr != nil {
+		panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed, interpolate config failed").WithCause(interpolateErr)))
+		return
+	}
+	configure, configureErr = configures.NewJsonConfig(configRaw)
+	if configureErr != nil {
+		panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed, get config via retriever failed").WithCause(configureErr)))
+		return
+	}
 	config := configs.Config{}
 	configErr := configure.As(&config)
 	if configErr != nil {
 		panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed, decode config failed").WithCause(configErr)))
 		return
 	}
+	validateErr := configs.Validate(configure.Raw(), config)
+	if validateOnly() {
+		if validateErr != nil {
+			fmt.Println(validateErr.Error())
+			os.Exit(1)
+			return
+		}
+		fmt.Println("fns: config is valid")
+		os.Exit(0)
+		return
+	}
+	if validateErr != nil {
+		panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed, config is invalid").WithCause(validateErr)))
+		return
+	}
 	// log
 	logger, loggerErr := logs.New(config.Log, opt.logWriters)
 	if loggerErr != nil {
@@ -107,24 +136,81 @@ func New(options ...Option) (app Application) {
 	if workersMaxIdleSeconds := config.Runtime.Workers.MaxIdleSeconds; workersMaxIdleSeconds > 0 {
 		workerOptions = append(workerOptions, workers.MaxIdleWorkerDuration(time.Duration(workersMaxIdleSeconds)*time.Second))
 	}
-	worker := workers.New(workerOptions...)
+	var worker workers.Workers = workers.New(workerOptions...)
+	if config.Runtime.Workers.Lanes.Enable {
+		lanes, lanesErr := services.NewLanes(worker, config.Runtime.Workers.Lanes)
+		if lanesErr != nil {
+			panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed").WithCause(lanesErr)))
+			return
+		}
+		worker = lanes
+	}
 
 	handlers := make([]transports.MuxHandler, 0, 1)
 
 	var manager services.EndpointsManager
 
-	local := services.New(appId, appVersion, logger.With("fns", "endpoints"), config.Services, worker)
+	timeFormat, timeFormatErr := jsons.ParseTimeFormat(config.Runtime.TimeFormat)
+	if timeFormatErr != nil {
+		panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed").WithCause(timeFormatErr)))
+		return
+	}
+	jsons.SetTimeFormat(timeFormat)
+	durationFormat, durationFormatErr := jsons.ParseDurationFormat(config.Runtime.DurationFormat)
+	if durationFormatErr != nil {
+		panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed").WithCause(durationFormatErr)))
+		return
+	}
+	jsons.SetDurationFormat(durationFormat)
+	jsons.SetMaxDepth(config.Runtime.MaxJsonDepth)
+	services.SetMaxRequestDepth(config.Runtime.MaxRequestDepth)
+	services.SetPanicReporter(logger.With("fns", "panics"), appVersion, opt.panicReporter)
+	local := services.New(appId, appVersion, logger.With("fns", "endpoints"), config.Services, worker, services.WithDependencies(config.Dependencies, opt.providers...))
 
-	handlers = append(handlers, services.Handler(local))
+	slowThreshold, slowThresholdErr := config.Log.GetSlowThreshold()
+	if slowThresholdErr != nil {
+		panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed").WithCause(slowThresholdErr)))
+		return
+	}
+	endpointsHandler := services.Handler(local, slowThreshold, opt.requestHooks...)
+	handlers = append(handlers, services.BatchHandler(local, endpointsHandler), endpointsHandler)
+	// health is always served by transport, cause cluster and load balancer check it.
 	handlers = append(handlers, runtime.HealthHandler())
+	managementHandlers := []transports.MuxHandler{runtime.DebugHandler(), runtime.StatsHandler(), runtime.ConfigHandler(configure), runtime.LogLevelHandler(), metrics.SLAHandler(), services.ReadinessHandler(local), services.ReloadHandler(local)}
+	if config.Management != nil {
+		managementHandlers = append(managementHandlers, runtime.HealthHandler())
+	} else if config.ExposeManagement {
+		// opted in, management handlers are served by public transport
+		handlers = append(managementHandlers, handlers...)
+	}
 
 	// barrier
 	var barrier barriers.Barrier
 	// shared
 	var shared shareds.Shared
+	// internal
+	var internalHandlers []transports.MuxHandler
+	clusterTransportConfig := config.Transport
+	if config.Internal != nil {
+		internalHandlers = make([]transports.MuxHandler, 0, 1)
+		clusterTransportConfig = *config.Internal
+	}
+	transport := opt.transport
+	if transport == nil {
+		var transportErr error
+		transport, transportErr = transports.New(config.Transport.Name)
+		if transportErr != nil {
+			panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed, new transport failed").WithCause(transportErr)))
+			return
+		}
+	}
+	clusterDialer := transports.ClientMiddlewaresDialer(
+		transports.BasePathDialer(transport, clusterTransportConfig.GetBasePath()),
+		opt.clientMiddlewares...,
+	)
 	// cluster
 	if clusterConfig := config.Cluster; clusterConfig.Name != "" {
-		port, portErr := config.Transport.GetPort()
+		port, portErr := clusterTransportConfig.GetPort()
 		if portErr != nil {
 			panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed").WithCause(portErr)))
 			return
@@ -134,18 +220,23 @@ func New(options ...Option) (app Application) {
 		manager, shared, barrier, clusterHandlers, clusterErr = clusters.New(clusters.Options{
 			Id:      appId,
 			Version: appVersion,
+			Host:    clusterTransportConfig.Host,
 			Port:    port,
 			Log:     logger.With("fns", "cluster"),
 			Worker:  worker,
 			Local:   local,
-			Dialer:  opt.transport,
+			Dialer:  clusterDialer,
 			Config:  clusterConfig,
 		})
 		if clusterErr != nil {
 			panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed").WithCause(clusterErr)))
 			return
 		}
-		handlers = append(handlers, clusterHandlers...)
+		if config.Internal != nil {
+			internalHandlers = append(internalHandlers, clusterHandlers...)
+		} else {
+			handlers = append(handlers, clusterHandlers...)
+		}
 	} else {
 		var sharedErr error
 		shared, sharedErr = shareds.Local(logger.With("shared", "local"), config.Runtime.Shared)
@@ -194,6 +285,7 @@ func New(options ...Option) (app Application) {
 	}
 	// handler
 	mux := transports.NewMux()
+	mux.SetBasePath(config.Transport.GetBasePath())
 	handlers = append(handlers, opt.handlers...)
 	for _, handler := range handlers {
 		handlerConfig, handlerConfigErr := config.Transport.HandlerConfig(handler.Name())
@@ -215,7 +307,6 @@ func New(options ...Option) (app Application) {
 			builtins = append(builtins, builtin.Services()...)
 		}
 	}
-	transport := opt.transport
 	transportErr := transport.Construct(transports.Options{
 		Log:     logger.With("transport", transport.Name()),
 		Config:  config.Transport,
@@ -225,6 +316,44 @@ func New(options ...Option) (app Application) {
 		panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed, new transport failed").WithCause(transportErr)))
 		return
 	}
+	// internal listener
+	var internal *listener
+	if config.Internal != nil {
+		internalTransport := opt.internalTransport
+		if internalTransport == nil {
+			var internalTransportErr error
+			internalTransport, internalTransportErr = transports.New(config.Internal.Name)
+			if internalTransportErr != nil {
+				panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed, new internal transport failed").WithCause(internalTransportErr)))
+				return
+			}
+		}
+		var internalErr error
+		internal, internalErr = newListener("internal", logger, rt, internalTransport, *config.Internal, internalHandlers)
+		if internalErr != nil {
+			panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed, new internal listener failed").WithCause(internalErr)))
+			return
+		}
+	}
+	// management listener
+	var management *listener
+	if config.Management != nil {
+		managementTransport := opt.managementTransport
+		if managementTransport == nil {
+			var managementTransportErr error
+			managementTransport, managementTransportErr = transports.New(config.Management.Name)
+			if managementTransportErr != nil {
+				panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed, new management transport failed").WithCause(managementTransportErr)))
+				return
+			}
+		}
+		var managementErr error
+		management, managementErr = newListener("management", logger, rt, managementTransport, *config.Management, managementHandlers)
+		if managementErr != nil {
+			panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed, new management listener failed").WithCause(managementErr)))
+			return
+		}
+	}
 	// transport <<<
 
 	// proxy >>>
@@ -246,7 +375,7 @@ func New(options ...Option) (app Application) {
 			Config:  config.Proxy,
 			Runtime: rt,
 			Manager: cluster,
-			Dialer:  transport,
+			Dialer:  clusterDialer,
 		})
 		if constructErr != nil {
 			panic(fmt.Errorf("%+v", errors.Warning("fns: new application failed, new proxy failed").WithCause(constructErr)))
@@ -294,10 +423,14 @@ func New(options ...Option) (app Application) {
 		worker:          worker,
 		manager:         manager,
 		middlewares:     middleware,
+		mux:             mux,
 		transport:       transport,
+		internal:        internal,
+		management:      management,
 		proxy:           proxy,
 		hooks:           opt.hooks,
 		shutdownTimeout: opt.shutdownTimeout,
+		configOption:    opt.configRetrieverOption,
 		synced:          false,
 		signalCh:        signalCh,
 	}
@@ -321,10 +454,14 @@ type application struct {
 	worker          workers.Workers
 	manager         services.EndpointsManager
 	middlewares     transports.Middlewares
+	mux             *transports.Mux
 	transport       transports.Transport
+	internal        *listener
+	management      *listener
 	proxy           proxies.Proxy
 	hooks           []hooks.Hook
 	shutdownTimeout time.Duration
+	configOption    configures.RetrieverOption
 	synced          bool
 	signalCh        chan os.Signal
 }
@@ -366,6 +503,22 @@ func (app *application) Run(ctx context.Context) Application {
 	if app.log.DebugEnabled() {
 		app.log.Debug().With("port", strconv.Itoa(app.transport.Port())).Message("fns: transport is serving...")
 	}
+	// internal
+	if app.internal != nil {
+		if inErr := app.internal.serve(); inErr != nil {
+			app.shutdown()
+			panic(fmt.Sprintf("%+v", errors.Warning("fns: application run failed").WithCause(inErr)))
+			return app
+		}
+	}
+	// management
+	if app.management != nil {
+		if mgErr := app.management.serve(); mgErr != nil {
+			app.shutdown()
+			panic(fmt.Sprintf("%+v", errors.Warning("fns: application run failed").WithCause(mgErr)))
+			return app
+		}
+	}
 
 	// endpoints
 	lnErr := app.manager.Listen(ctx)
@@ -374,6 +527,13 @@ func (app *application) Run(ctx context.Context) Application {
 		panic(fmt.Sprintf("%+v", errors.Warning("fns: application run failed").WithCause(lnErr)))
 		return app
 	}
+	// warmup, transport keeps answering 425 until all services are ready
+	warmErr := app.manager.Warmup(ctx)
+	if warmErr != nil {
+		app.shutdown()
+		panic(fmt.Sprintf("%+v", errors.Warning("fns: application run failed").WithCause(warmErr)))
+		return app
+	}
 	// confirm
 	app.status.Confirm()
 	// proxy
@@ -433,6 +593,7 @@ func (app *application) Run(ctx context.Context) Application {
 	if app.log.DebugEnabled() {
 		app.log.Debug().Message("fns: application is running...")
 	}
+	app.banner()
 	return app
 }
 
@@ -472,6 +633,13 @@ func (app *application) shutdown() {
 		// transport
 		app.middlewares.Close()
 		app.transport.Shutdown(ctx)
+		_ = app.mux.Close()
+		if app.internal != nil {
+			app.internal.shutdown(ctx)
+		}
+		if app.management != nil {
+			app.management.shutdown(ctx)
+		}
 		// proxy
 		if app.proxy != nil {
 			app.proxy.Shutdown(ctx)
@@ -489,3 +657,19 @@ func (app *application) shutdown() {
 		app.log.Debug().Message("fns: application is stopped!!!")
 	}
 }
+
+const (
+	validateConfigFlag = "--validate-config"
+)
+
+// validateOnly
+// when --validate-config is in args, application only validates config then exits,
+// exit code is 1 when config is invalid.
+func validateOnly() bool {
+	for _, arg := range os.Args[1:] {
+		if arg == validateConfigFlag {
+			return true
+		}
+	}
+	return false
+}
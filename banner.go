@@ -0,0 +1,66 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fns
+
+import (
+	"github.com/aacfactory/fns/configs"
+	"github.com/aacfactory/fns/logs"
+	"strconv"
+	"strings"
+)
+
+// banner
+// logs effective settings in one info log, so operators can confirm the deployment at a glance.
+func (app *application) banner() {
+	if app.config.Log.DisableBanner || app.config.Log.Formatter == logs.JsonConsoleFormatter || !app.log.InfoEnabled() {
+		return
+	}
+	cluster := app.config.Cluster.Name
+	if cluster == "" {
+		cluster = "standalone"
+	}
+	middlewares := make([]string, 0, len(app.middlewares))
+	for _, middleware := range app.middlewares {
+		// runtime is always there
+		if name := middleware.Name(); name != "runtime" {
+			middlewares = append(middlewares, name)
+		}
+	}
+	active := app.configOption.Active
+	if active == "" {
+		active = "-"
+	}
+	event := app.log.Info().
+		With("id", app.id).
+		With("name", app.name).
+		With("version", app.version.String()).
+		With("transport", app.transport.Name()+":"+strconv.Itoa(app.transport.Port())).
+		With("cluster", cluster).
+		With("middlewares", strings.Join(middlewares, ",")).
+		With("config", configs.Source(app.configOption)+" (format: "+strings.ToLower(app.configOption.Format)+", active: "+active+")")
+	if app.internal != nil {
+		event = event.With("internal", app.internal.transport.Name()+":"+strconv.Itoa(app.internal.transport.Port()))
+	}
+	if app.management != nil {
+		event = event.With("management", app.management.transport.Name()+":"+strconv.Itoa(app.management.transport.Port()))
+	}
+	if app.proxy != nil {
+		event = event.With("proxy", strconv.Itoa(app.proxy.Port()))
+	}
+	event.Message("fns: application is running")
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package clusters
+
+import (
+	"bytes"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/transports"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ChaosConfig
+// failure injection for resilience testing, it must not be enabled in production.
+// it wraps the outbound client of internal requests, not the handler,
+// so the injected failures are seen by the caller node.
+type ChaosConfig struct {
+	Enable  bool          `json:"enable,omitempty"`
+	Targets []ChaosTarget `json:"targets,omitempty"`
+}
+
+// ChaosTarget
+// empty or * service and fn match any.
+// latency is uniform between minLatency and maxLatency.
+type ChaosTarget struct {
+	Service     string  `json:"service,omitempty"`
+	Fn          string  `json:"fn,omitempty"`
+	FailureRate float64 `json:"failureRate,omitempty"`
+	ErrorCode   int     `json:"errorCode,omitempty"`
+	LatencyRate float64 `json:"latencyRate,omitempty"`
+	MinLatency  string  `json:"minLatency,omitempty"`
+	MaxLatency  string  `json:"maxLatency,omitempty"`
+}
+
+type chaosTarget struct {
+	service     []byte
+	fn          []byte
+	failureRate float64
+	errorCode   int
+	latencyRate float64
+	minLatency  time.Duration
+	maxLatency  time.Duration
+}
+
+func (target *chaosTarget) match(path []byte) bool {
+	items := bytes.Split(path, slashBytes)
+	if len(items) != 3 {
+		return false
+	}
+	if len(target.service) > 0 && !bytes.Equal(target.service, items[1]) {
+		return false
+	}
+	if len(target.fn) > 0 && !bytes.Equal(target.fn, items[2]) {
+		return false
+	}
+	return true
+}
+
+func (target *chaosTarget) latency() time.Duration {
+	if target.maxLatency <= target.minLatency {
+		return target.minLatency
+	}
+	return target.minLatency + rand.N(target.maxLatency-target.minLatency)
+}
+
+func newChaosMiddleware(config ChaosConfig) (middleware transports.ClientMiddleware, err error) {
+	targets := make([]*chaosTarget, 0, len(config.Targets))
+	for _, tc := range config.Targets {
+		target := &chaosTarget{
+			failureRate: tc.FailureRate,
+			errorCode:   tc.ErrorCode,
+			latencyRate: tc.LatencyRate,
+		}
+		if service := strings.TrimSpace(tc.Service); service != "" && service != "*" {
+			target.service = []byte(service)
+		}
+		if fn := strings.TrimSpace(tc.Fn); fn != "" && fn != "*" {
+			target.fn = []byte(fn)
+		}
+		if target.errorCode < 400 {
+			target.errorCode = http.StatusServiceUnavailable
+		}
+		if minLatency := strings.TrimSpace(tc.MinLatency); minLatency != "" {
+			target.minLatency, err = time.ParseDuration(minLatency)
+			if err != nil {
+				err = errors.Warning("fns: new chaos failed").WithCause(err).WithMeta("config", "minLatency")
+				return
+			}
+		}
+		if maxLatency := strings.TrimSpace(tc.MaxLatency); maxLatency != "" {
+			target.maxLatency, err = time.ParseDuration(maxLatency)
+			if err != nil {
+				err = errors.Warning("fns: new chaos failed").WithCause(err).WithMeta("config", "maxLatency")
+				return
+			}
+		}
+		targets = append(targets, target)
+	}
+	middleware = func(next transports.ClientDo) transports.ClientDo {
+		return func(ctx context.Context, method []byte, path []byte, header transports.Header, body []byte) (status int, responseHeader transports.Header, responseBody []byte, err error) {
+			if bytes.Equal(method, transports.MethodPost) {
+				for _, target := range targets {
+					if !target.match(path) {
+						continue
+					}
+					if target.latencyRate > 0 && rand.Float64() < target.latencyRate {
+						timer := time.NewTimer(target.latency())
+						select {
+						case <-timer.C:
+							break
+						case <-ctx.Done():
+							timer.Stop()
+							err = ctx.Err()
+							return
+						}
+					}
+					if target.failureRate > 0 && rand.Float64() < target.failureRate {
+						err = errors.New(target.errorCode, "***CHAOS***", "fns: failure is injected by chaos").WithMeta("path", string(path))
+						return
+					}
+					break
+				}
+			}
+			status, responseHeader, responseBody, err = next(ctx, method, path, header, body)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package clusters
+
+import (
+	sc "context"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/transports"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func chaosDo(handled *int) transports.ClientDo {
+	return func(ctx context.Context, method []byte, path []byte, header transports.Header, body []byte) (status int, responseHeader transports.Header, responseBody []byte, err error) {
+		*handled++
+		status = http.StatusOK
+		return
+	}
+}
+
+func TestChaos_Failure(t *testing.T) {
+	middleware, err := newChaosMiddleware(ChaosConfig{
+		Enable:  true,
+		Targets: []ChaosTarget{{Service: "users", Fn: "get", FailureRate: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handled := 0
+	do := middleware(chaosDo(&handled))
+	_, _, _, err = do(context.TODO(), transports.MethodPost, []byte("/users/get"), transports.NewHeader(), nil)
+	if err == nil || errors.Wrap(err).Code() != http.StatusServiceUnavailable || handled != 0 {
+		t.Fatal("failure must be injected with 503", err, handled)
+	}
+	// not matched
+	for _, c := range []struct {
+		method []byte
+		path   string
+	}{
+		{transports.MethodPost, "/users/list"},
+		{transports.MethodPost, "/orders/get"},
+		{transports.MethodGet, "/users/get"},
+	} {
+		if _, _, _, err = do(context.TODO(), c.method, []byte(c.path), transports.NewHeader(), nil); err != nil {
+			t.Fatal("failure must not be injected", string(c.method), c.path, err)
+		}
+	}
+	if handled != 3 {
+		t.Fatal("unmatched requests must be passed", handled)
+	}
+}
+
+func TestChaos_Latency(t *testing.T) {
+	middleware, err := newChaosMiddleware(ChaosConfig{
+		Enable:  true,
+		Targets: []ChaosTarget{{Service: "*", LatencyRate: 1, MinLatency: "50ms", MaxLatency: "50ms", ErrorCode: http.StatusBadGateway}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handled := 0
+	do := middleware(chaosDo(&handled))
+	begin := time.Now()
+	if _, _, _, err = do(context.TODO(), transports.MethodPost, []byte("/users/get"), transports.NewHeader(), nil); err != nil || handled != 1 {
+		t.Fatal("delayed request must be passed", err, handled)
+	}
+	if elapsed := time.Since(begin); elapsed < 50*time.Millisecond {
+		t.Fatal("latency was not injected", elapsed)
+	}
+	// canceled while waiting
+	ctx, cancel := sc.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, _, err = do(context.Wrap(ctx), transports.MethodPost, []byte("/users/get"), transports.NewHeader(), nil); err == nil || handled != 1 {
+		t.Fatal("canceled request must not be passed", err, handled)
+	}
+	if _, err = newChaosMiddleware(ChaosConfig{Targets: []ChaosTarget{{MinLatency: "x"}}}); err == nil {
+		t.Fatal("invalid latency must be rejected")
+	}
+}
@@ -30,6 +30,8 @@ import (
 	"github.com/aacfactory/fns/shareds"
 	"github.com/aacfactory/fns/transports"
 	"github.com/aacfactory/workers"
+	"net"
+	"strconv"
 	"strings"
 )
 
@@ -49,6 +51,9 @@ type Cluster interface {
 	NodeEvents() (events <-chan NodeEvent)
 	Shared() (shared shareds.Shared)
 	Barrier() (barrier barriers.Barrier)
+	// Leader
+	// elects one node as the leader of key, lost is closed when leadership is lost, release gives up leadership, see StoreLeader.
+	Leader(ctx context.Context, key []byte) (isLeader bool, lost <-chan struct{}, release func())
 }
 
 type ClusterBuilderOptions struct {
@@ -72,33 +77,54 @@ func loadCluster(name string) (cluster Cluster, has bool) {
 type Options struct {
 	Id      string
 	Version versions.Version
-	Port    int
-	Log     logs.Logger
-	Worker  workers.Workers
-	Local   services.EndpointsManager
-	Dialer  transports.Dialer
-	Config  Config
+	// Host
+	// bind host of transport, it is used as host of node when it is a specific ip and HostRetriever is not set.
+	Host   string
+	Port   int
+	Log    logs.Logger
+	Worker workers.Workers
+	Local  services.EndpointsManager
+	Dialer transports.Dialer
+	Config Config
 }
 
 func New(options Options) (manager services.EndpointsManager, shared shareds.Shared, barrier barriers.Barrier, handlers []transports.MuxHandler, err error) {
+	// chaos
+	if chaos := options.Config.Chaos; chaos != nil && chaos.Enable {
+		chaosMiddleware, chaosErr := newChaosMiddleware(*chaos)
+		if chaosErr != nil {
+			err = errors.Warning("fns: new cluster failed").WithCause(chaosErr)
+			return
+		}
+		options.Dialer = transports.ClientMiddlewaresDialer(options.Dialer, chaosMiddleware)
+		if options.Log.WarnEnabled() {
+			options.Log.Warn().Message("fns: chaos of cluster is enabled, do not use it in production")
+		}
+	}
 	// signature
 	signature := NewSignature(options.Config.Secret)
 	// host
 	hostRetrieverName := strings.TrimSpace(options.Config.HostRetriever)
-	if hostRetrieverName == "" {
-		hostRetrieverName = "default"
-	}
-	hostRetriever, hasHostRetriever := getHostRetriever(hostRetrieverName)
-	if !hasHostRetriever {
-		err = errors.Warning("fns: new cluster failed").WithCause(fmt.Errorf("host retriever was not found")).WithMeta("name", hostRetrieverName)
-		return
-	}
-	host, hostErr := hostRetriever()
-	if hostErr != nil {
-		err = errors.Warning("fns: new cluster failed").WithCause(hostErr)
-		return
+	host := ""
+	if bindIp := net.ParseIP(strings.TrimSpace(options.Host)); hostRetrieverName == "" && bindIp != nil && !bindIp.IsUnspecified() {
+		host = bindIp.String()
+	} else {
+		if hostRetrieverName == "" {
+			hostRetrieverName = "default"
+		}
+		hostRetriever, hasHostRetriever := getHostRetriever(hostRetrieverName)
+		if !hasHostRetriever {
+			err = errors.Warning("fns: new cluster failed").WithCause(fmt.Errorf("host retriever was not found")).WithMeta("name", hostRetrieverName)
+			return
+		}
+		var hostErr error
+		host, hostErr = hostRetriever()
+		if hostErr != nil {
+			err = errors.Warning("fns: new cluster failed").WithCause(hostErr)
+			return
+		}
 	}
-	address := fmt.Sprintf("%s:%d", host, options.Port)
+	address := net.JoinHostPort(host, strconv.Itoa(options.Port))
 	// cluster
 	var cluster Cluster
 	if options.Config.Name == developmentName {
@@ -134,8 +160,30 @@ func New(options Options) (manager services.EndpointsManager, shared shareds.Sha
 	shared = cluster.Shared()
 	// barrier
 	barrier = cluster.Barrier()
+	// splits
+	splits := make(map[string]*VersionSplit)
+	for _, splitConfig := range options.Config.Splits {
+		split, splitErr := NewVersionSplit(splitConfig)
+		if splitErr != nil {
+			err = errors.Warning("fns: new cluster failed").WithCause(splitErr)
+			return
+		}
+		splits[splitConfig.Service] = split
+	}
+	// warmup
+	warmup, warmupErr := NewWarmup(options.Config.Warmup)
+	if warmupErr != nil {
+		err = errors.Warning("fns: new cluster failed").WithCause(warmupErr)
+		return
+	}
+	// shared responses
+	responses, responsesErr := NewSharedResponses(options.Config.SharedResponses, shared.Store())
+	if responsesErr != nil {
+		err = errors.Warning("fns: new cluster failed").WithCause(responsesErr)
+		return
+	}
 	// manager
-	manager = NewManager(options.Id, options.Version, address, cluster, options.Local, options.Worker, options.Log, options.Dialer, signature)
+	manager = NewManager(options.Id, options.Version, address, cluster, options.Local, options.Worker, options.Log, options.Dialer, signature, splits, warmup, responses)
 	// handlers
 	handlers = make([]transports.MuxHandler, 0, 1)
 	handlers = append(handlers, NewInternalHandler(options.Local, signature))
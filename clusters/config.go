@@ -27,4 +27,10 @@ type Config struct {
 	Name          string          `json:"name"`
 	Proxy         bool            `json:"proxy"`
 	Option        json.RawMessage `json:"option"`
+	Chaos         *ChaosConfig    `json:"chaos,omitempty"`
+	Splits        []SplitConfig   `json:"splits,omitempty"`
+	Warmup        *WarmupConfig   `json:"warmup,omitempty"`
+	// SharedResponses
+	// keeps bodies of readonly fns of peers in shared store, see SharedResponses.
+	SharedResponses *SharedResponsesConfig `json:"sharedResponses,omitempty"`
 }
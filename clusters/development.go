@@ -23,13 +23,13 @@ import (
 	"github.com/aacfactory/fns/barriers"
 	"github.com/aacfactory/fns/clusters/proxy"
 	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
 	"github.com/aacfactory/fns/commons/mmhash"
 	"github.com/aacfactory/fns/commons/signatures"
 	"github.com/aacfactory/fns/context"
 	"github.com/aacfactory/fns/logs"
 	"github.com/aacfactory/fns/shareds"
 	"github.com/aacfactory/fns/transports"
-	"github.com/aacfactory/json"
 	"strings"
 	"time"
 )
@@ -121,6 +121,14 @@ func (cluster *Development) Barrier() (barrier barriers.Barrier) {
 	return
 }
 
+// Leader
+// development node is always the leader, cause it is the only one node which is developing, so lost is never closed.
+func (cluster *Development) Leader(_ context.Context, _ []byte) (isLeader bool, lost <-chan struct{}, release func()) {
+	isLeader = true
+	release = func() {}
+	return
+}
+
 func (cluster *Development) watching(ctx context.Context) {
 	cluster.fetchAndUpdate(ctx)
 	stop := false
@@ -152,8 +160,8 @@ func (cluster *Development) fetchAndUpdate(ctx context.Context) {
 			Node: cluster.nodes[0],
 		}
 	} else {
-		op, _ := json.Marshal(cluster.nodes)
-		np, _ := json.Marshal(nodes)
+		op, _ := jsons.Marshal(cluster.nodes)
+		np, _ := jsons.Marshal(nodes)
 		if mmhash.Sum64(op) == mmhash.Sum64(np) {
 			return
 		}
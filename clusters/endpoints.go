@@ -65,6 +65,7 @@ type Endpoint struct {
 	client    transports.Client
 	signature signatures.Signature
 	errs      *window.Times
+	responses *SharedResponses
 }
 
 func (endpoint *Endpoint) Running() bool {
@@ -104,27 +105,26 @@ func (endpoint *Endpoint) IsHealth() bool {
 	return endpoint.errs.Value() < 5
 }
 
-func (endpoint *Endpoint) AddFn(name string, internal bool, readonly bool) {
+func (endpoint *Endpoint) AddFn(info services.FnInfo) {
 	fn := &Fn{
-		log:          endpoint.log.With("fn", name),
+		log:          endpoint.log.With("fn", info.Name),
 		endpointName: endpoint.info.Name,
 		address:      endpoint.info.Address,
-		name:         name,
-		internal:     internal,
-		readonly:     readonly,
-		path:         bytex.FromString(fmt.Sprintf("/%s/%s", endpoint.info.Name, name)),
+		name:         info.Name,
+		internal:     info.Internal,
+		readonly:     info.Readonly,
+		since:        info.Since,
+		removed:      info.Removed,
+		path:         bytex.FromString(fmt.Sprintf("/%s/%s", endpoint.info.Name, info.Name)),
 		signature:    endpoint.signature,
 		errs:         endpoint.errs,
 		health:       atomic.Bool{},
 		client:       endpoint.client,
+		responses:    endpoint.responses,
 	}
 	fn.health.Store(true)
 	endpoint.functions = endpoint.functions.Add(fn)
-	endpoint.info.Functions = append(endpoint.info.Functions, services.FnInfo{
-		Name:     name,
-		Readonly: readonly,
-		Internal: internal,
-	})
+	endpoint.info.Functions = append(endpoint.info.Functions, info)
 	sort.Sort(endpoint.info.Functions)
 }
 
@@ -281,6 +281,15 @@ func (endpoints *Endpoints) MaxOne() (ep *Endpoint) {
 	return
 }
 
+func (endpoints *Endpoints) Version(version versions.Version) (ep *Endpoint) {
+	endpoints.lock.RLock()
+	defer endpoints.lock.RUnlock()
+	if vps := endpoints.values.Get(version); vps != nil {
+		ep = vps.Next()
+	}
+	return
+}
+
 func (endpoints *Endpoints) Get(id []byte) *Endpoint {
 	endpoints.lock.RLock()
 	defer endpoints.lock.RUnlock()
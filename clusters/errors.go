@@ -28,6 +28,7 @@ var (
 	ErrInvalidPath            = errors.Warning("fns: invalid path")
 	ErrInvalidBody            = errors.Warning("fns: invalid body")
 	ErrInvalidRequestVersions = errors.Warning("fns: invalid request versions")
+	ErrInvalidRequestDepth    = errors.Warning("fns: invalid request depth")
 	ErrTooMayRequest          = errors.TooMayRequest("fns: too may request, try again later")
 	ErrSignatureLost          = errors.New(488, "***SIGNATURE LOST***", "X-Fns-Signature was required")
 	ErrSignatureUnverified    = errors.New(458, "***SIGNATURE INVALID***", "X-Fns-Signature was invalid")
@@ -23,15 +23,18 @@ import (
 	"github.com/aacfactory/avro"
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/commons/avros"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
 	"github.com/aacfactory/fns/commons/signatures"
+	"github.com/aacfactory/fns/commons/versions"
 	"github.com/aacfactory/fns/commons/window"
 	"github.com/aacfactory/fns/logs"
 	"github.com/aacfactory/fns/services"
 	"github.com/aacfactory/fns/services/tracings"
 	"github.com/aacfactory/fns/transports"
 	"github.com/aacfactory/fns/transports/middlewares/compress"
-	"github.com/aacfactory/json"
 	"net/http"
+	"strconv"
 	"sync/atomic"
 )
 
@@ -42,11 +45,14 @@ type Fn struct {
 	name         string
 	internal     bool
 	readonly     bool
+	since        versions.Version
+	removed      versions.Version
 	path         []byte
 	signature    signatures.Signature
 	errs         *window.Times
 	health       atomic.Bool
 	client       transports.Client
+	responses    *SharedResponses
 }
 
 func (fn *Fn) Enable() bool {
@@ -65,6 +71,14 @@ func (fn *Fn) Readonly() bool {
 	return fn.readonly
 }
 
+func (fn *Fn) Since() versions.Version {
+	return fn.since
+}
+
+func (fn *Fn) Removed() versions.Version {
+	return fn.removed
+}
+
 func (fn *Fn) Handle(ctx services.Request) (v interface{}, err error) {
 	if !ctx.Header().Internal() {
 		err = errors.Warning("fns: request must be internal")
@@ -108,6 +122,15 @@ func (fn *Fn) Handle(ctx services.Request) (v interface{}, err error) {
 	if len(deviceIp) > 0 {
 		header.Set(transports.DeviceIpHeaderName, deviceIp)
 	}
+	// tenant
+	tenant := ctx.Header().Tenant()
+	if len(tenant) > 0 {
+		header.Set(transports.TenantHeaderName, tenant)
+	}
+	// request depth
+	if depth := ctx.Header().Depth(); depth > 0 {
+		header.Set(transports.RequestDepthHeaderName, bytex.FromString(strconv.Itoa(depth)))
+	}
 	// request id
 	requestId := ctx.Header().RequestId()
 	if len(requestId) > 0 {
@@ -128,7 +151,7 @@ func (fn *Fn) Handle(ctx services.Request) (v interface{}, err error) {
 	// body
 	userValues := make([]Entry, 0, 1)
 	ctx.UserValues(func(key []byte, val any) {
-		p, encodeErr := json.Marshal(val)
+		p, encodeErr := jsons.Marshal(val)
 		if encodeErr != nil {
 			return
 		}
@@ -160,6 +183,17 @@ func (fn *Fn) Handle(ctx services.Request) (v interface{}, err error) {
 	signature := fn.signature.Sign(body)
 	header.Set(transports.SignatureHeaderName, signature)
 
+	// shared response
+	var sharedKey []byte
+	var shared sharedResponse
+	hasShared := false
+	if fn.responses != nil && fn.readonly {
+		sharedKey, shared, hasShared = fn.responses.get(fn.log, ctx)
+		if hasShared {
+			header.Set(transports.CacheControlHeaderIfNonMatch, shared.ETag)
+		}
+	}
+
 	// do
 	status, respHeader, respBody, doErr := fn.client.Do(ctx, transports.MethodPost, fn.path, header, body)
 	if doErr != nil {
@@ -194,6 +228,14 @@ func (fn *Fn) Handle(ctx services.Request) (v interface{}, err error) {
 		})
 	}
 
+	if status == http.StatusNotModified && hasShared {
+		if fn.errs.Value() > 0 {
+			fn.errs.Decr()
+		}
+		v = avros.RawMessage(shared.Data)
+		return
+	}
+
 	if status == 200 {
 		if fn.errs.Value() > 0 {
 			fn.errs.Decr()
@@ -217,6 +259,9 @@ func (fn *Fn) Handle(ctx services.Request) (v interface{}, err error) {
 			}
 		}
 		if rsb.Succeed {
+			if len(sharedKey) > 0 {
+				fn.responses.set(fn.log, ctx, sharedKey, respHeader.Get(transports.ETagHeaderName), rsb.Data)
+			}
 			v = avros.RawMessage(rsb.Data)
 		} else {
 			codeErr := &errors.CodeErrorImpl{}
@@ -30,6 +30,8 @@ import (
 	"github.com/aacfactory/fns/services/tracings"
 	"github.com/aacfactory/fns/transports"
 	"github.com/aacfactory/json"
+	"net/http"
+	"strconv"
 )
 
 var (
@@ -161,6 +163,11 @@ func (handler *InternalHandler) Handle(w transports.ResponseWriter, r transports
 	if len(deviceIp) > 0 {
 		options = append(options, services.WithDeviceIp(deviceIp))
 	}
+	// tenant
+	tenant := r.Header().Get(transports.TenantHeaderName)
+	if len(tenant) > 0 {
+		options = append(options, services.WithTenant(tenant))
+	}
 	// request id
 	requestId := r.Header().Get(transports.RequestIdHeaderName)
 	hasRequestId := len(requestId) > 0
@@ -177,6 +184,15 @@ func (handler *InternalHandler) Handle(w transports.ResponseWriter, r transports
 		}
 		options = append(options, services.WithRequestVersions(intervals))
 	}
+	// request depth
+	if depth := r.Header().Get(transports.RequestDepthHeaderName); len(depth) > 0 {
+		n, nErr := strconv.Atoi(bytex.ToString(depth))
+		if nErr != nil {
+			w.Failed(ErrInvalidRequestDepth.WithMeta("path", bytex.ToString(path)).WithMeta("depth", bytex.ToString(depth)).WithCause(nErr))
+			return
+		}
+		options = append(options, services.WithRequestDepth(n))
+	}
 	// authorization
 	authorization := r.Header().Get(transports.AuthorizationHeaderName)
 	if len(authorization) > 0 {
@@ -232,6 +248,16 @@ func (handler *InternalHandler) Handle(w transports.ResponseWriter, r transports
 		})
 	}
 
+	// etag of data, a peer which keeps the same data gets 304 without body, see SharedResponses
+	if succeed {
+		etag := etagOfData(data)
+		if inm := r.Header().Get(transports.CacheControlHeaderIfNonMatch); len(inm) > 0 && bytes.Equal(inm, etag) {
+			w.SetStatus(http.StatusNotModified)
+			return
+		}
+		w.Header().Set(transports.ETagHeaderName, etag)
+	}
+
 	p, encodeErr := avro.Marshal(rsb)
 	if encodeErr != nil {
 		w.Failed(errors.Warning("fns: proto marshal failed").WithCause(encodeErr))
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package clusters
+
+import (
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/shareds"
+	"time"
+)
+
+var (
+	leaderKeyPrefix = []byte("fns:clusters:leaders:")
+)
+
+// StoreLeader
+// lease based leader election over shared store, it is a building block of Cluster.Leader, see shareds.Lease.
+// the node which takes the lease of key is the leader, the lease is renewed every third of ttl until release is called or ctx is done.
+// when the leader dies, its lease is not renewed by anyone, so another node can become the leader after ttl at most.
+// a node that is not the leader should try again later.
+// lost is closed when the leader loses its lease, such as it was paused longer than ttl and another node took over,
+// the leader must stop leading work then.
+// release stops renewing and removes the lease, so other nodes can take over at once.
+func StoreLeader(ctx context.Context, store shareds.Store, key []byte, ttl time.Duration) (isLeader bool, lost <-chan struct{}, release func()) {
+	release = func() {}
+	if ttl < time.Second {
+		ttl = 10 * time.Second
+	}
+	key = append(append(make([]byte, 0, len(leaderKeyPrefix)+len(key)), leaderKeyPrefix...), key...)
+	lease, ok, err := shareds.AcquireLease(ctx, store, key, ttl)
+	if err != nil || !ok {
+		return
+	}
+	isLeader = true
+	lost = lease.Lost()
+	release = func() {
+		lease.Release(ctx)
+	}
+	return
+}
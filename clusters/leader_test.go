@@ -0,0 +1,69 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package clusters_test
+
+import (
+	"github.com/aacfactory/fns/clusters"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/shareds"
+	"testing"
+	"time"
+)
+
+func TestStoreLeader(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	store := shared.Store()
+	key := []byte("leader")
+	ttl := time.Second
+	// leader dies when its ctx is done
+	leaderCtx, cancel := context.WithCancel(context.TODO())
+	isLeader, lost, _ := clusters.StoreLeader(leaderCtx, store, key, ttl)
+	if !isLeader {
+		t.Fatal("first node must be the leader")
+	}
+	ctx := context.TODO()
+	if isFollowerLeader, _, _ := clusters.StoreLeader(ctx, store, key, ttl); isFollowerLeader {
+		t.Fatal("only one node can be the leader")
+	}
+	cancel()
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("leader was not notified")
+	}
+	deadline := time.Now().Add(3 * ttl)
+	for {
+		isFollowerLeader, _, release := clusters.StoreLeader(ctx, store, key, ttl)
+		if isFollowerLeader {
+			release()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("follower did not take over after ttl")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -37,7 +37,7 @@ import (
 	"time"
 )
 
-func NewManager(id string, version versions.Version, address string, cluster Cluster, local services.EndpointsManager, worker workers.Workers, log logs.Logger, dialer transports.Dialer, signature signatures.Signature) ClusterEndpointsManager {
+func NewManager(id string, version versions.Version, address string, cluster Cluster, local services.EndpointsManager, worker workers.Workers, log logs.Logger, dialer transports.Dialer, signature signatures.Signature, splits map[string]*VersionSplit, warmup *Warmup, responses *SharedResponses) ClusterEndpointsManager {
 	v := &Manager{
 		id:        id,
 		version:   version,
@@ -48,8 +48,11 @@ func NewManager(id string, version versions.Version, address string, cluster Clu
 		worker:    worker,
 		dialer:    dialer,
 		signature: signature,
+		warmup:    warmup,
+		responses: responses,
 		registration: &Registration{
 			values: sync.Map{},
+			splits: splits,
 		},
 	}
 	return v
@@ -70,6 +73,8 @@ type Manager struct {
 	worker       workers.Workers
 	dialer       transports.Dialer
 	signature    signatures.Signature
+	warmup       *Warmup
+	responses    *SharedResponses
 	registration *Registration
 }
 
@@ -80,11 +85,7 @@ func (manager *Manager) Add(service services.Service) (err error) {
 	}
 	functions := make(services.FnInfos, 0, len(service.Functions()))
 	for _, fn := range service.Functions() {
-		functions = append(functions, services.FnInfo{
-			Name:     fn.Name(),
-			Readonly: fn.Readonly(),
-			Internal: service.Internal() || fn.Internal(),
-		})
+		functions = append(functions, services.NewFnInfo(fn, service.Internal()))
 	}
 	sort.Sort(functions)
 	info, infoErr := NewService(service.Name(), service.Internal(), functions, service.Document())
@@ -107,8 +108,38 @@ func (manager *Manager) Info() (infos services.EndpointInfos) {
 	return
 }
 
+// split
+// applies split of service before local one, so local service does not take all traffic of its own node.
+// remote is the endpoint of picked version when it is not the local one,
+// and it is nil when service is not split, picked version is local or there is no endpoint of picked version.
+func (manager *Manager) split(ctx context.Context, name []byte, inLocal bool) (remote *Endpoint) {
+	version, has := manager.registration.Split(name, splitDeviceId(ctx))
+	if !has {
+		return
+	}
+	if inLocal && version.Equals(manager.version) {
+		exposeSplit(ctx, version)
+		return
+	}
+	remote = manager.registration.Version(name, version)
+	if remote != nil {
+		exposeSplit(ctx, version)
+	}
+	return
+}
+
 func (manager *Manager) FnAddress(ctx context.Context, endpoint []byte, fnName []byte, options ...services.EndpointGetOption) (address string, internal bool, has bool) {
 	local, inLocal := manager.local.Get(ctx, endpoint, options...)
+	if len(options) == 0 {
+		if remote := manager.split(ctx, endpoint, inLocal); remote != nil {
+			if fn, hasFn := remote.Functions().Find(fnName); hasFn {
+				address = remote.Address()
+				internal = fn.Internal()
+				has = true
+			}
+			return
+		}
+	}
 	if inLocal {
 		fnNameString := bytex.ToString(fnName)
 		for _, fn := range local.Functions() {
@@ -168,8 +199,16 @@ func (manager *Manager) FnAddress(ctx context.Context, endpoint []byte, fnName [
 }
 
 func (manager *Manager) Get(ctx context.Context, name []byte, options ...services.EndpointGetOption) (endpoint services.Endpoint, has bool) {
-	// local
 	local, inLocal := manager.local.Get(ctx, name, options...)
+	// split
+	if len(options) == 0 {
+		if remote := manager.split(ctx, name, inLocal); remote != nil {
+			endpoint = remote
+			has = true
+			return
+		}
+	}
+	// local
 	if inLocal {
 		endpoint = local
 		has = true
@@ -177,8 +216,12 @@ func (manager *Manager) Get(ctx context.Context, name []byte, options ...service
 	}
 	// max one
 	if len(options) == 0 {
-		endpoint = manager.registration.MaxOne(name)
-		has = !reflect.ValueOf(endpoint).IsNil()
+		matched := manager.registration.MaxOne(name)
+		if matched == nil {
+			return
+		}
+		endpoint = matched
+		has = true
 		return
 	}
 
@@ -217,6 +260,13 @@ func (manager *Manager) RequestAsync(ctx context.Context, name []byte, fn []byte
 	}
 	// request
 	req := services.NewRequest(ctx, name, fn, param, options...)
+	// depth
+	if err = services.CheckRequestDepth(req); err != nil {
+		if manager.log.WarnEnabled() {
+			manager.log.Warn().Cause(err).Message("fns: request is too deep, it may be a cycle of services")
+		}
+		return
+	}
 	// get endpoint
 	var endpointGetOptions []services.EndpointGetOption
 	if endpointId := req.Header().EndpointId(); len(endpointId) > 0 {
@@ -244,8 +294,11 @@ func (manager *Manager) RequestAsync(ctx context.Context, name []byte, fn []byte
 			WithMeta("fn", bytex.ToString(fn))
 		return
 	}
+	if err = services.CheckFnVersions(name, function, req.Header().AcceptedVersions()); err != nil {
+		return
+	}
 	// log
-	logs.With(req, manager.log.With("service", bytex.ToString(name)).With("fn", bytex.ToString(fn)))
+	services.WithRequestLog(req, manager.log)
 	// components
 	service, ok := endpoint.(services.Service)
 	if ok {
@@ -296,6 +349,13 @@ func (manager *Manager) Request(ctx context.Context, name []byte, fn []byte, par
 	// request
 	req := services.AcquireRequest(ctx, name, fn, param, options...)
 	defer services.ReleaseRequest(req)
+	// depth
+	if err = services.CheckRequestDepth(req); err != nil {
+		if manager.log.WarnEnabled() {
+			manager.log.Warn().Cause(err).Message("fns: request is too deep, it may be a cycle of services")
+		}
+		return
+	}
 	// get endpoint
 	var endpointGetOptions []services.EndpointGetOption
 	if endpointId := req.Header().EndpointId(); len(endpointId) > 0 {
@@ -323,8 +383,11 @@ func (manager *Manager) Request(ctx context.Context, name []byte, fn []byte, par
 			WithMeta("fn", bytex.ToString(fn))
 		return
 	}
+	if err = services.CheckFnVersions(name, function, req.Header().AcceptedVersions()); err != nil {
+		return
+	}
 	// log
-	logs.With(req, manager.log.With("service", bytex.ToString(name)).With("fn", bytex.ToString(fn)))
+	services.WithRequestLog(req, manager.log)
 	// components
 	service, ok := endpoint.(services.Service)
 	if ok {
@@ -376,6 +439,16 @@ func (manager *Manager) Listen(ctx context.Context) (err error) {
 	return
 }
 
+func (manager *Manager) Warmup(ctx context.Context) (err error) {
+	err = manager.local.Warmup(ctx)
+	return
+}
+
+func (manager *Manager) Readiness() (v services.Readinesses) {
+	v = manager.local.Readiness()
+	return
+}
+
 func (manager *Manager) Shutdown(ctx context.Context) {
 	leaveErr := manager.cluster.Leave(ctx)
 	if leaveErr != nil {
@@ -461,11 +534,19 @@ func (manager *Manager) watching() {
 						continue
 					}
 					ep := NewEndpoint(manager.log, event.Node.Address, event.Node.Id, event.Node.Version, endpoint.Name, endpoint.Internal, document, client, eps.signature)
+					ep.responses = eps.responses
 					for _, fnInfo := range endpoint.Functions {
-						ep.AddFn(fnInfo.Name, fnInfo.Internal, fnInfo.Readonly)
+						ep.AddFn(fnInfo)
 					}
 					endpoints = append(endpoints, ep)
 				}
+				// warmup
+				if eps.warmup != nil && len(endpoints) > 0 {
+					primed := eps.warmup.Prime(client, endpoints)
+					if eps.log.DebugEnabled() {
+						eps.log.Debug().With("cluster", "registrations").Message(fmt.Sprintf("fns: %d connections of %s are primed", primed, event.Node.Address))
+					}
+				}
 				for _, endpoint := range endpoints {
 					eps.registration.Add(endpoint)
 				}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package clusters
+
+import (
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/documents"
+	"testing"
+)
+
+func TestManager_Split(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	localVersion, _ := versions.Parse([]byte("v1.2.0"))
+	remoteVersion, _ := versions.Parse([]byte("v1.3.0"))
+	local := services.New("local", localVersion, log, services.Config{}, nil)
+	users := services.NewAbstract("users", false)
+	if err := local.Add(&users); err != nil {
+		t.Fatal(err)
+	}
+	split, splitErr := NewVersionSplit(SplitConfig{
+		Service: "users",
+		Weights: []SplitWeight{{Version: "v1.3.0", Weight: 50}, {Version: "v1.2.0", Weight: 50}},
+	})
+	if splitErr != nil {
+		t.Fatal(splitErr)
+	}
+	manager := NewManager("local", localVersion, "local", nil, local, nil, log, nil, nil, map[string]*VersionSplit{"users": split}, nil, nil).(*Manager)
+	manager.registration.Add(NewEndpoint(log, "remote", "remote", remoteVersion, "users", false, documents.Endpoint{}, nil, nil))
+
+	// split is applied before local shortcut, so remote one takes its share of traffic
+	hits := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		endpoint, has := manager.Get(context.TODO(), []byte("users"))
+		if !has {
+			t.Fatal("users must be found")
+		}
+		if _, remote := endpoint.(*Endpoint); remote {
+			hits["remote"]++
+		} else {
+			hits["local"]++
+		}
+	}
+	if hits["remote"] == 0 || hits["local"] == 0 {
+		t.Fatal("split was not applied", hits)
+	}
+}
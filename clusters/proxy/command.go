@@ -19,6 +19,7 @@ package proxy
 
 import (
 	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/jsons"
 	"github.com/aacfactory/fns/commons/signatures"
 	"github.com/aacfactory/fns/transports"
 	"github.com/aacfactory/json"
@@ -35,7 +36,7 @@ func ParseCommand(r transports.Request) (cmd Command, err error) {
 		err = errors.Warning("fns: parse proxy command failed").WithCause(bodyErr)
 		return
 	}
-	err = json.Unmarshal(body, &cmd)
+	err = jsons.Unmarshal(body, &cmd)
 	if err != nil {
 		err = errors.Warning("fns: parse proxy command failed").WithCause(err)
 		return
@@ -44,7 +45,7 @@ func ParseCommand(r transports.Request) (cmd Command, err error) {
 }
 
 func encodeCommand(cmd Command, signature signatures.Signature) (body []byte, sign []byte, err error) {
-	body, err = json.Marshal(cmd)
+	body, err = jsons.Marshal(cmd)
 	if err != nil {
 		err = errors.Warning("fns: encode proxy command failed").WithCause(err)
 		return
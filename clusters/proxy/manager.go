@@ -19,11 +19,11 @@ package proxy
 
 import (
 	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/jsons"
 	"github.com/aacfactory/fns/commons/signatures"
 	"github.com/aacfactory/fns/context"
 	"github.com/aacfactory/fns/services"
 	"github.com/aacfactory/fns/transports"
-	"github.com/aacfactory/json"
 	"strconv"
 )
 
@@ -52,7 +52,7 @@ func FetchEndpointInfos(ctx context.Context, client transports.Client, signature
 	}
 	if status == 200 {
 		infos = make(services.EndpointInfos, 0, 1)
-		err = json.Unmarshal(respBody, &infos)
+		err = jsons.Unmarshal(respBody, &infos)
 		if err != nil {
 			err = errors.Warning("fns: fetch endpoint infos failed").WithCause(err)
 			return
@@ -20,6 +20,7 @@ package proxy
 import (
 	"bytes"
 	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/jsons"
 	"github.com/aacfactory/fns/commons/signatures"
 	"github.com/aacfactory/fns/context"
 	"github.com/aacfactory/fns/shareds"
@@ -53,12 +54,12 @@ func (store *Store) Get(ctx context.Context, key []byte) (value []byte, has bool
 	param := StoreGetParam{
 		Key: key,
 	}
-	p, _ := json.Marshal(param)
+	p, _ := jsons.Marshal(param)
 	command := Command{
 		Command: "get",
 		Payload: p,
 	}
-	body, _ := json.Marshal(command)
+	body, _ := jsons.Marshal(command)
 
 	header := transports.AcquireHeader()
 	defer transports.ReleaseHeader(header)
@@ -74,7 +75,7 @@ func (store *Store) Get(ctx context.Context, key []byte) (value []byte, has bool
 	}
 	if status == 200 {
 		result := StoreGetResult{}
-		decodeErr := json.Unmarshal(responseBody, &result)
+		decodeErr := jsons.Unmarshal(responseBody, &result)
 		if decodeErr != nil {
 			err = errors.Warning("fns: development store get failed").WithCause(decodeErr)
 			return
@@ -106,12 +107,12 @@ func (store *Store) Set(ctx context.Context, key []byte, value []byte) (err erro
 		Key:   key,
 		Value: value,
 	}
-	p, _ := json.Marshal(param)
+	p, _ := jsons.Marshal(param)
 	command := Command{
 		Command: "set",
 		Payload: p,
 	}
-	body, _ := json.Marshal(command)
+	body, _ := jsons.Marshal(command)
 
 	header := transports.AcquireHeader()
 	defer transports.ReleaseHeader(header)
@@ -127,7 +128,7 @@ func (store *Store) Set(ctx context.Context, key []byte, value []byte) (err erro
 	}
 	if status == 200 {
 		result := StoreSetResult{}
-		decodeErr := json.Unmarshal(responseBody, &result)
+		decodeErr := jsons.Unmarshal(responseBody, &result)
 		if decodeErr != nil {
 			err = errors.Warning("fns: development store set failed").WithCause(decodeErr)
 			return
@@ -159,12 +160,12 @@ func (store *Store) SetWithTTL(ctx context.Context, key []byte, value []byte, tt
 		Value: value,
 		TTL:   ttl,
 	}
-	p, _ := json.Marshal(param)
+	p, _ := jsons.Marshal(param)
 	command := Command{
 		Command: "setWithTTL",
 		Payload: p,
 	}
-	body, _ := json.Marshal(command)
+	body, _ := jsons.Marshal(command)
 
 	header := transports.AcquireHeader()
 	defer transports.ReleaseHeader(header)
@@ -180,7 +181,7 @@ func (store *Store) SetWithTTL(ctx context.Context, key []byte, value []byte, tt
 	}
 	if status == 200 {
 		result := StoreSetWithTTLResult{}
-		decodeErr := json.Unmarshal(responseBody, &result)
+		decodeErr := jsons.Unmarshal(responseBody, &result)
 		if decodeErr != nil {
 			err = errors.Warning("fns: development store set with ttl failed").WithCause(decodeErr)
 			return
@@ -211,12 +212,12 @@ func (store *Store) Incr(ctx context.Context, key []byte, delta int64) (v int64,
 		Key:   key,
 		Delta: delta,
 	}
-	p, _ := json.Marshal(param)
+	p, _ := jsons.Marshal(param)
 	command := Command{
 		Command: "incr",
 		Payload: p,
 	}
-	body, _ := json.Marshal(command)
+	body, _ := jsons.Marshal(command)
 
 	header := transports.AcquireHeader()
 	defer transports.ReleaseHeader(header)
@@ -232,7 +233,7 @@ func (store *Store) Incr(ctx context.Context, key []byte, delta int64) (v int64,
 	}
 	if status == 200 {
 		result := StoreIncrResult{}
-		decodeErr := json.Unmarshal(responseBody, &result)
+		decodeErr := jsons.Unmarshal(responseBody, &result)
 		if decodeErr != nil {
 			err = errors.Warning("fns: development store incr failed").WithCause(decodeErr)
 			return
@@ -261,12 +262,12 @@ func (store *Store) Remove(ctx context.Context, key []byte) (err error) {
 	param := StoreRemoveParam{
 		Key: key,
 	}
-	p, _ := json.Marshal(param)
+	p, _ := jsons.Marshal(param)
 	command := Command{
 		Command: "remove",
 		Payload: p,
 	}
-	body, _ := json.Marshal(command)
+	body, _ := jsons.Marshal(command)
 
 	header := transports.AcquireHeader()
 	defer transports.ReleaseHeader(header)
@@ -282,7 +283,7 @@ func (store *Store) Remove(ctx context.Context, key []byte) (err error) {
 	}
 	if status == 200 {
 		result := StoreRemoveResult{}
-		decodeErr := json.Unmarshal(responseBody, &result)
+		decodeErr := jsons.Unmarshal(responseBody, &result)
 		if decodeErr != nil {
 			err = errors.Warning("fns: development store remove failed").WithCause(decodeErr)
 			return
@@ -312,12 +313,12 @@ func (store *Store) Expire(ctx context.Context, key []byte, ttl time.Duration) (
 		Key: key,
 		TTL: ttl,
 	}
-	p, _ := json.Marshal(param)
+	p, _ := jsons.Marshal(param)
 	command := Command{
 		Command: "expire",
 		Payload: p,
 	}
-	body, _ := json.Marshal(command)
+	body, _ := jsons.Marshal(command)
 
 	header := transports.AcquireHeader()
 	defer transports.ReleaseHeader(header)
@@ -333,7 +334,7 @@ func (store *Store) Expire(ctx context.Context, key []byte, ttl time.Duration) (
 	}
 	if status == 200 {
 		result := StoreExpireResult{}
-		decodeErr := json.Unmarshal(responseBody, &result)
+		decodeErr := jsons.Unmarshal(responseBody, &result)
 		if decodeErr != nil {
 			err = errors.Warning("fns: development store expire failed").WithCause(decodeErr)
 			return
@@ -369,7 +370,7 @@ func (handler *SharedStoreHandler) Handle(w transports.ResponseWriter, r transpo
 		return
 	}
 	cmd := Command{}
-	decodeErr := json.Unmarshal(body, &cmd)
+	decodeErr := jsons.Unmarshal(body, &cmd)
 	if decodeErr != nil {
 		w.Failed(ErrInvalidBody.WithCause(decodeErr))
 		return
@@ -377,7 +378,7 @@ func (handler *SharedStoreHandler) Handle(w transports.ResponseWriter, r transpo
 	switch cmd.Command {
 	case "get":
 		param := StoreGetParam{}
-		paramErr := json.Unmarshal(cmd.Payload, &param)
+		paramErr := jsons.Unmarshal(cmd.Payload, &param)
 		if paramErr != nil {
 			w.Failed(ErrInvalidBody.WithCause(paramErr))
 			return
@@ -388,13 +389,13 @@ func (handler *SharedStoreHandler) Handle(w transports.ResponseWriter, r transpo
 			result.Value = value
 			result.Has = has
 		} else {
-			result.Error, _ = json.Marshal(errors.Wrap(err))
+			result.Error, _ = jsons.Marshal(errors.Wrap(err))
 		}
 		w.Succeed(result)
 		break
 	case "set":
 		param := StoreSetParam{}
-		paramErr := json.Unmarshal(cmd.Payload, &param)
+		paramErr := jsons.Unmarshal(cmd.Payload, &param)
 		if paramErr != nil {
 			w.Failed(ErrInvalidBody.WithCause(paramErr))
 			return
@@ -403,13 +404,13 @@ func (handler *SharedStoreHandler) Handle(w transports.ResponseWriter, r transpo
 		err := handler.store.Set(r, param.Key, param.Value)
 		if err == nil {
 		} else {
-			result.Error, _ = json.Marshal(errors.Wrap(err))
+			result.Error, _ = jsons.Marshal(errors.Wrap(err))
 		}
 		w.Succeed(result)
 		break
 	case "setWithTTL":
 		param := StoreSetWithTTLParam{}
-		paramErr := json.Unmarshal(cmd.Payload, &param)
+		paramErr := jsons.Unmarshal(cmd.Payload, &param)
 		if paramErr != nil {
 			w.Failed(ErrInvalidBody.WithCause(paramErr))
 			return
@@ -418,13 +419,13 @@ func (handler *SharedStoreHandler) Handle(w transports.ResponseWriter, r transpo
 		err := handler.store.SetWithTTL(r, param.Key, param.Value, param.TTL)
 		if err == nil {
 		} else {
-			result.Error, _ = json.Marshal(errors.Wrap(err))
+			result.Error, _ = jsons.Marshal(errors.Wrap(err))
 		}
 		w.Succeed(result)
 		break
 	case "incr":
 		param := StoreIncrParam{}
-		paramErr := json.Unmarshal(cmd.Payload, &param)
+		paramErr := jsons.Unmarshal(cmd.Payload, &param)
 		if paramErr != nil {
 			w.Failed(ErrInvalidBody.WithCause(paramErr))
 			return
@@ -434,13 +435,13 @@ func (handler *SharedStoreHandler) Handle(w transports.ResponseWriter, r transpo
 		if err == nil {
 			result.N = n
 		} else {
-			result.Error, _ = json.Marshal(errors.Wrap(err))
+			result.Error, _ = jsons.Marshal(errors.Wrap(err))
 		}
 		w.Succeed(result)
 		break
 	case "remove":
 		param := StoreRemoveParam{}
-		paramErr := json.Unmarshal(cmd.Payload, &param)
+		paramErr := jsons.Unmarshal(cmd.Payload, &param)
 		if paramErr != nil {
 			w.Failed(ErrInvalidBody.WithCause(paramErr))
 			return
@@ -449,13 +450,13 @@ func (handler *SharedStoreHandler) Handle(w transports.ResponseWriter, r transpo
 		err := handler.store.Remove(r, param.Key)
 		if err == nil {
 		} else {
-			result.Error, _ = json.Marshal(errors.Wrap(err))
+			result.Error, _ = jsons.Marshal(errors.Wrap(err))
 		}
 		w.Succeed(result)
 		break
 	case "expire":
 		param := StoreExpireParam{}
-		paramErr := json.Unmarshal(cmd.Payload, &param)
+		paramErr := jsons.Unmarshal(cmd.Payload, &param)
 		if paramErr != nil {
 			w.Failed(ErrInvalidBody.WithCause(paramErr))
 			return
@@ -464,7 +465,7 @@ func (handler *SharedStoreHandler) Handle(w transports.ResponseWriter, r transpo
 		err := handler.store.Expire(r, param.Key, param.TTL)
 		if err == nil {
 		} else {
-			result.Error, _ = json.Marshal(errors.Wrap(err))
+			result.Error, _ = jsons.Marshal(errors.Wrap(err))
 		}
 		w.Succeed(result)
 		break
@@ -26,6 +26,7 @@ import (
 
 type Registration struct {
 	values sync.Map
+	splits map[string]*VersionSplit
 }
 
 func (r *Registration) Add(endpoint *Endpoint) {
@@ -84,6 +85,28 @@ func (r *Registration) MaxOne(name []byte) *Endpoint {
 	return eps.MaxOne()
 }
 
+// Split
+// picks version of service by its split, has is false when service is not split.
+func (r *Registration) Split(name []byte, deviceId []byte) (version versions.Version, has bool) {
+	s, hasSplit := r.splits[bytex.ToString(name)]
+	if !hasSplit {
+		return
+	}
+	version = s.Pick(deviceId)
+	has = true
+	return
+}
+
+func (r *Registration) Version(name []byte, version versions.Version) *Endpoint {
+	key := bytex.ToString(name)
+	exist, has := r.values.Load(key)
+	if !has {
+		return nil
+	}
+	eps := exist.(*Endpoints)
+	return eps.Version(version)
+}
+
 func (r *Registration) Infos() (v services.EndpointInfos) {
 	r.values.Range(func(key, value any) bool {
 		eps := value.(*Endpoints)
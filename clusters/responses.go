@@ -0,0 +1,152 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package clusters
+
+import (
+	"fmt"
+	"github.com/aacfactory/avro"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/shareds"
+	"github.com/cespare/xxhash/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSharedResponsesTTL = 60 * time.Second
+)
+
+var (
+	sharedResponsesKeyPrefix = []byte("fns:clusters:responses:")
+)
+
+// SharedResponsesConfig
+// TTL is how long a body is kept in shared store, default is 60s.
+type SharedResponsesConfig struct {
+	TTL string `json:"ttl,omitempty"`
+}
+
+// NewSharedResponses
+// returns nil when config is nil, which means responses of peers are not shared.
+func NewSharedResponses(config *SharedResponsesConfig, store shareds.Store) (responses *SharedResponses, err error) {
+	if config == nil || store == nil {
+		return
+	}
+	ttl := defaultSharedResponsesTTL
+	if s := strings.TrimSpace(config.TTL); s != "" {
+		ttl, err = time.ParseDuration(s)
+		if err != nil {
+			err = errors.Warning("fns: new shared responses failed").WithCause(err).WithMeta("ttl", s)
+			return
+		}
+		if ttl <= 0 {
+			err = errors.Warning("fns: new shared responses failed").WithCause(fmt.Errorf("ttl must be positive")).WithMeta("ttl", s)
+			return
+		}
+	}
+	responses = &SharedResponses{
+		store: store,
+		ttl:   ttl,
+	}
+	return
+}
+
+type sharedResponse struct {
+	ETag []byte `json:"etag" avro:"etag"`
+	Data []byte `json:"data" avro:"data"`
+}
+
+// SharedResponses
+// keeps bodies of readonly fns of peers in the shared store of cluster, keyed by hash of request (endpoint, fn, param, token and tenant),
+// so any node which requests the same fn sends the etag of kept body by If-None-Match,
+// and reuses the kept body when the peer answers 304, even the body was kept by another node.
+//
+// Consistency: the peer always handles the request and compares etag of its fresh result,
+// so a kept body is used only when it is equal to the fresh one, and it is never stale.
+// It saves transfer of body, not handling of fn, and span of the peer is not mounted when 304 is answered.
+//
+// Fallback: when shared store is unavailable, request is sent without If-None-Match and body is not kept,
+// so it works as there is no SharedResponses.
+type SharedResponses struct {
+	store shareds.Store
+	ttl   time.Duration
+}
+
+func (responses *SharedResponses) key(r services.Request) (key []byte, err error) {
+	options := make([]services.HashRequestOption, 0, 1)
+	if len(r.Header().Token()) > 0 {
+		options = append(options, services.HashRequestWithToken())
+	}
+	hash, hashErr := services.HashRequest(r, options...)
+	if hashErr != nil {
+		err = hashErr
+		return
+	}
+	key = append(append(make([]byte, 0, len(sharedResponsesKeyPrefix)+len(hash)), sharedResponsesKeyPrefix...), hash...)
+	return
+}
+
+func (responses *SharedResponses) get(log logs.Logger, r services.Request) (key []byte, response sharedResponse, has bool) {
+	var err error
+	key, err = responses.key(r)
+	if err != nil {
+		key = nil
+		return
+	}
+	p, exist, getErr := responses.store.Get(r, key)
+	if getErr != nil {
+		if log.DebugEnabled() {
+			log.Debug().Cause(getErr).Message("fns: get shared response failed")
+		}
+		return
+	}
+	if !exist {
+		return
+	}
+	if decodeErr := avro.Unmarshal(p, &response); decodeErr != nil {
+		return
+	}
+	has = len(response.ETag) > 0
+	return
+}
+
+func (responses *SharedResponses) set(log logs.Logger, r services.Request, key []byte, etag []byte, data []byte) {
+	if len(key) == 0 || len(etag) == 0 {
+		return
+	}
+	p, encodeErr := avro.Marshal(sharedResponse{
+		ETag: etag,
+		Data: data,
+	})
+	if encodeErr != nil {
+		return
+	}
+	if setErr := responses.store.SetWithTTL(r, key, p, responses.ttl); setErr != nil {
+		if log.DebugEnabled() {
+			log.Debug().Cause(setErr).Message("fns: set shared response failed")
+		}
+	}
+}
+
+func etagOfData(data []byte) []byte {
+	return bytex.FromString(strconv.FormatUint(xxhash.Sum64(data), 16))
+}
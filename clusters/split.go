@@ -0,0 +1,116 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package clusters
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/transports"
+	"hash/fnv"
+	"math/rand"
+)
+
+type SplitWeight struct {
+	Version string `json:"version"`
+	Weight  int    `json:"weight"`
+}
+
+// SplitConfig
+// splits traffic of service between versions by weight, such as 1.3.0 with 10 and 1.2.0 with 90.
+// when sticky is true, a device stays in one version, it is bucketed by hash of device id.
+type SplitConfig struct {
+	Service string        `json:"service"`
+	Sticky  bool          `json:"sticky"`
+	Weights []SplitWeight `json:"weights"`
+}
+
+func NewVersionSplit(config SplitConfig) (split *VersionSplit, err error) {
+	if config.Service == "" {
+		err = errors.Warning("fns: new version split failed").WithCause(fmt.Errorf("service is required"))
+		return
+	}
+	if len(config.Weights) == 0 {
+		err = errors.Warning("fns: new version split failed").WithCause(fmt.Errorf("weights is required")).WithMeta("service", config.Service)
+		return
+	}
+	split = &VersionSplit{
+		sticky:   config.Sticky,
+		versions: make([]versions.Version, 0, len(config.Weights)),
+		bounds:   make([]int, 0, len(config.Weights)),
+		total:    0,
+	}
+	for _, weight := range config.Weights {
+		if weight.Weight < 1 {
+			err = errors.Warning("fns: new version split failed").WithCause(fmt.Errorf("weight must be positive")).WithMeta("service", config.Service).WithMeta("version", weight.Version)
+			return
+		}
+		version, parseErr := versions.Parse(bytex.FromString(weight.Version))
+		if parseErr != nil {
+			err = errors.Warning("fns: new version split failed").WithCause(parseErr).WithMeta("service", config.Service).WithMeta("version", weight.Version)
+			return
+		}
+		split.total += weight.Weight
+		split.versions = append(split.versions, version)
+		split.bounds = append(split.bounds, split.total)
+	}
+	return
+}
+
+type VersionSplit struct {
+	sticky   bool
+	versions []versions.Version
+	bounds   []int
+	total    int
+}
+
+func (split *VersionSplit) Pick(deviceId []byte) versions.Version {
+	var n int
+	if split.sticky && len(deviceId) > 0 {
+		h := fnv.New32a()
+		_, _ = h.Write(deviceId)
+		n = int(h.Sum32() % uint32(split.total))
+	} else {
+		n = rand.Intn(split.total)
+	}
+	for i, bound := range split.bounds {
+		if n < bound {
+			return split.versions[i]
+		}
+	}
+	return split.versions[len(split.versions)-1]
+}
+
+func splitDeviceId(ctx context.Context) []byte {
+	if r, ok := services.TryLoadRequest(ctx); ok {
+		return r.Header().DeviceId()
+	}
+	if r, ok := transports.TryLoadRequest(ctx); ok {
+		return r.Header().Get(transports.DeviceIdHeaderName)
+	}
+	return nil
+}
+
+func exposeSplit(ctx context.Context, version versions.Version) {
+	if header, has := transports.TryLoadResponseHeader(ctx); has {
+		header.Set(transports.EndpointVersionHeaderName, bytex.FromString(version.String()))
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package clusters_test
+
+import (
+	"fmt"
+	"github.com/aacfactory/fns/clusters"
+	"testing"
+)
+
+func TestVersionSplit(t *testing.T) {
+	split, err := clusters.NewVersionSplit(clusters.SplitConfig{
+		Service: "users",
+		Sticky:  true,
+		Weights: []clusters.SplitWeight{{Version: "v1.3.0", Weight: 10}, {Version: "v1.2.0", Weight: 90}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		device := []byte(fmt.Sprintf("device-%d", i))
+		version := split.Pick(device)
+		if !version.Equals(split.Pick(device)) {
+			t.Fatal("sticky split must pick same version for same device")
+		}
+		hits[version.String()]++
+	}
+	if n := hits["v1.3.0"]; n < 800 || n > 1200 {
+		t.Fatal("weight of v1.3.0 mismatched", hits)
+	}
+	if _, err = clusters.NewVersionSplit(clusters.SplitConfig{Service: "users", Weights: []clusters.SplitWeight{{Version: "v1.3.0", Weight: 0}}}); err == nil {
+		t.Fatal("zero weight must be invalid")
+	}
+}
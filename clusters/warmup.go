@@ -0,0 +1,112 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package clusters
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/transports"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultWarmupConnections = 4
+	defaultWarmupTimeout     = 2 * time.Second
+)
+
+// WarmupConfig
+// primes connections of a node which joins the cluster before its endpoints are registered,
+// so the first requests after a deploy do not pay for dialing.
+type WarmupConfig struct {
+	// Connections
+	// number of concurrent health checks, each of them holds a pooled connection of client.
+	Connections int `json:"connections"`
+	// Timeout
+	// bound of each health check, default is 2s.
+	Timeout string `json:"timeout"`
+}
+
+func NewWarmup(config *WarmupConfig) (warmup *Warmup, err error) {
+	if config == nil {
+		return
+	}
+	connections := config.Connections
+	if connections < 1 {
+		connections = defaultWarmupConnections
+	}
+	timeout := defaultWarmupTimeout
+	if s := strings.TrimSpace(config.Timeout); s != "" {
+		timeout, err = time.ParseDuration(s)
+		if err != nil {
+			err = errors.Warning("fns: new cluster warmup failed").WithCause(err).WithMeta("timeout", s)
+			return
+		}
+	}
+	warmup = &Warmup{
+		connections: connections,
+		timeout:     timeout,
+	}
+	return
+}
+
+type Warmup struct {
+	connections int
+	timeout     time.Duration
+}
+
+// Prime
+// sends concurrent health checks by client, results are counted into the error window of endpoints,
+// so a failing node trips the same breaker as failed requests do.
+// It is skipped when the breaker of any endpoint is already open.
+func (warmup *Warmup) Prime(client transports.Client, endpoints []*Endpoint) (primed int) {
+	for _, endpoint := range endpoints {
+		if !endpoint.IsHealth() {
+			return
+		}
+	}
+	n := new(atomic.Int64)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < warmup.connections; i++ {
+		wg.Add(1)
+		go func(client transports.Client, n *atomic.Int64, wg *sync.WaitGroup) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.TODO(), warmup.timeout)
+			ok := runtime.CheckHealth(ctx, client)
+			cancel()
+			for _, endpoint := range endpoints {
+				if ok {
+					if endpoint.errs.Value() > 0 {
+						endpoint.errs.Decr()
+					}
+				} else {
+					endpoint.errs.Incr()
+				}
+			}
+			if ok {
+				n.Add(1)
+			}
+		}(client, n, wg)
+	}
+	wg.Wait()
+	primed = int(n.Load())
+	return
+}
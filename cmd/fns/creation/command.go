@@ -0,0 +1,163 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package creation
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/cmd/generates/files"
+	"github.com/urfave/cli/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var Command = &cli.Command{
+	Name:        "create",
+	Aliases:     nil,
+	Usage:       "fns create service --dir={project dir} {name}",
+	Description: "create fns components",
+	ArgsUsage:   "",
+	Category:    "",
+	Subcommands: []*cli.Command{
+		serviceCommand,
+	},
+}
+
+var serviceCommand = &cli.Command{
+	Name:        "service",
+	Usage:       "fns create service --dir={project dir} {name}",
+	Description: "create a service with an example fn in modules of project, then run `go generate` to deploy it",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "dir",
+			Aliases:  []string{"d"},
+			Required: false,
+			Usage:    "project dir, default is current dir",
+		},
+	},
+	Action: func(ctx *cli.Context) (err error) {
+		name := strings.TrimSpace(ctx.Args().First())
+		if !serviceNamePattern.MatchString(name) {
+			err = errors.Warning("fns: create service failed").WithCause(fmt.Errorf("name must be lower case letters, digits or underscores, and starts with a letter")).WithMeta("name", name)
+			return
+		}
+		projectDir := strings.TrimSpace(ctx.String("dir"))
+		if projectDir == "" {
+			projectDir = "."
+		}
+		projectDir, err = filepath.Abs(projectDir)
+		if err != nil {
+			err = errors.Warning("fns: create service failed").WithCause(err).WithMeta("dir", projectDir)
+			return
+		}
+		modulesDir := filepath.ToSlash(filepath.Join(projectDir, "modules"))
+		if !files.ExistFile(modulesDir) {
+			err = errors.Warning("fns: create service failed").WithCause(fmt.Errorf("modules dir is not found, project must be initialized by `fns init`")).WithMeta("dir", modulesDir)
+			return
+		}
+		serviceDir := filepath.ToSlash(filepath.Join(modulesDir, name))
+		if files.ExistFile(serviceDir) {
+			err = errors.Warning("fns: create service failed").WithCause(fmt.Errorf("service dir already exists")).WithMeta("dir", serviceDir)
+			return
+		}
+		err = WriteService(serviceDir, name)
+		if err != nil {
+			err = errors.Warning("fns: create service failed").WithCause(err).WithMeta("name", name)
+			return
+		}
+		fmt.Println(fmt.Sprintf("fns: service %s has been created in %s, please run `go generate` to deploy it into modules.Services()", name, serviceDir))
+		return
+	},
+}
+
+var (
+	serviceNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+)
+
+// WriteService
+// writes doc.go with @service and example.go with an example fn into dir.
+// generator registers the service into endpoints of modules, which is returned by modules.Services().
+func WriteService(dir string, name string) (err error) {
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	title := strings.ToUpper(name[:1]) + strings.ReplaceAll(name[1:], "_", " ")
+	doc := fmt.Sprintf(`// Package %s
+// @service %s
+// @title %s
+// @description %s service
+package %s
+`, name, name, title, title, name)
+	if err = os.WriteFile(filepath.ToSlash(filepath.Join(dir, "doc.go")), []byte(doc), 0644); err != nil {
+		return
+	}
+	example := strings.ReplaceAll(`package #name#
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+)
+
+// ExampleParam
+// @title Example function param
+// @description Example function param
+type ExampleParam struct {
+	// Id
+	// @title Id
+	// @description Id
+	Id string `+"`"+`json:"id" validate:"required" validate-message:"id is required"`+"`"+`
+}
+
+// ExampleResult
+// @title Example function result
+// @description Example function result
+type ExampleResult struct {
+	// Id
+	// @title Id
+	// @description Id
+	Id string `+"`"+`json:"id"`+"`"+`
+}
+
+// example
+// @fn example
+// @readonly
+// @errors >>>
+// #name#_example_failed
+// zh: 错误
+// en: failed
+// <<<
+// @title Example
+// @description >>>
+// Example
+// <<<
+func example(ctx context.Context, param ExampleParam) (result ExampleResult, err error) {
+	if param.Id == "" {
+		err = errors.ServiceError("#name#_example_failed")
+		return
+	}
+	result = ExampleResult{
+		Id: param.Id,
+	}
+	return
+}
+`, "#name#", name)
+	err = os.WriteFile(filepath.ToSlash(filepath.Join(dir, "example.go")), []byte(example), 0644)
+	return
+}
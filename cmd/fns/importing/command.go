@@ -0,0 +1,89 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package importing
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/cmd/generates/files"
+	"github.com/aacfactory/fns/configs"
+	"github.com/urfave/cli/v2"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var Command = &cli.Command{
+	Name:        "import",
+	Aliases:     nil,
+	Usage:       "fns import --dir={project dir} {openapi document file}",
+	Description: "create services from an openapi 3 document, the document can be json or yaml, then run `go generate` to deploy them",
+	ArgsUsage:   "",
+	Category:    "",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "dir",
+			Aliases:  []string{"d"},
+			Required: false,
+			Usage:    "project dir, default is current dir",
+		},
+	},
+	Action: func(ctx *cli.Context) (err error) {
+		filename := strings.TrimSpace(ctx.Args().First())
+		if filename == "" {
+			err = errors.Warning("fns: import openapi failed").WithCause(fmt.Errorf("openapi document file is required"))
+			return
+		}
+		content, readErr := os.ReadFile(filename)
+		if readErr != nil {
+			err = errors.Warning("fns: import openapi failed").WithCause(readErr).WithMeta("file", filename)
+			return
+		}
+		content, err = configs.DecodeFile(filename, content)
+		if err != nil {
+			err = errors.Warning("fns: import openapi failed").WithCause(err)
+			return
+		}
+		doc, docErr := Decode(content)
+		if docErr != nil {
+			err = errors.Warning("fns: import openapi failed").WithCause(docErr).WithMeta("file", filename)
+			return
+		}
+		projectDir := strings.TrimSpace(ctx.String("dir"))
+		if projectDir == "" {
+			projectDir = "."
+		}
+		projectDir, err = filepath.Abs(projectDir)
+		if err != nil {
+			err = errors.Warning("fns: import openapi failed").WithCause(err).WithMeta("dir", projectDir)
+			return
+		}
+		modulesDir := filepath.ToSlash(filepath.Join(projectDir, "modules"))
+		if !files.ExistFile(modulesDir) {
+			err = errors.Warning("fns: import openapi failed").WithCause(fmt.Errorf("modules dir is not found, project must be initialized by `fns init`")).WithMeta("dir", modulesDir)
+			return
+		}
+		names, importErr := Import(doc, modulesDir)
+		if importErr != nil {
+			err = importErr
+			return
+		}
+		fmt.Println(fmt.Sprintf("fns: services %s have been created in %s, please implement fns and run `go generate` to deploy them into modules.Services()", strings.Join(names, ", "), modulesDir))
+		return
+	},
+}
@@ -0,0 +1,473 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package importing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/cmd/generates/files"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Decode
+// decodes openapi 3 document, content must be json, yaml is converted by caller.
+func Decode(content []byte) (doc *Document, err error) {
+	doc = &Document{}
+	if err = json.Unmarshal(content, doc); err != nil {
+		doc = nil
+		err = errors.Warning("fns: decode openapi document failed").WithCause(err)
+		return
+	}
+	if !strings.HasPrefix(doc.Openapi, "3.") {
+		doc = nil
+		err = errors.Warning("fns: decode openapi document failed").WithCause(fmt.Errorf("only openapi 3 is supported")).WithMeta("openapi", doc.Openapi)
+		return
+	}
+	return
+}
+
+// Import
+// writes a service dir into modulesDir for each tag of operations of doc,
+// operations without tags are grouped by first segment of path.
+// each service dir has a doc.go, a types.go with schemas and a file with a stub for each fn,
+// stubs return not implemented errors, and generated names of services are returned.
+func Import(doc *Document, modulesDir string) (names []string, err error) {
+	services, servicesErr := collect(doc)
+	if servicesErr != nil {
+		err = servicesErr
+		return
+	}
+	for _, service := range services {
+		if files.ExistFile(filepath.Join(modulesDir, service.name)) {
+			err = errors.Warning("fns: import openapi failed").WithCause(fmt.Errorf("service dir already exists")).WithMeta("service", service.name)
+			return
+		}
+	}
+	for _, service := range services {
+		if err = service.write(filepath.Join(modulesDir, service.name)); err != nil {
+			err = errors.Warning("fns: import openapi failed").WithCause(err).WithMeta("service", service.name)
+			return
+		}
+		names = append(names, service.name)
+	}
+	return
+}
+
+type service struct {
+	name        string
+	description string
+	fns         []*fn
+	types       *typeWriter
+}
+
+type fn struct {
+	name          string
+	ident         string
+	title         string
+	description   string
+	method        string
+	path          string
+	readonly      bool
+	authorization bool
+	deprecated    bool
+	validation    bool
+	param         string
+	result        string
+}
+
+func collect(doc *Document) (services []*service, err error) {
+	descriptions := make(map[string]string)
+	for _, tag := range doc.Tags {
+		descriptions[tag.Name] = tag.Description
+	}
+	index := make(map[string]*service)
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		item := doc.Paths[path]
+		if item == nil {
+			continue
+		}
+		methods, operations := item.Operations()
+		for i, operation := range operations {
+			method := methods[i]
+			group := ""
+			if len(operation.Tags) > 0 {
+				group = operation.Tags[0]
+			} else {
+				group = strings.Split(strings.Trim(path, "/"), "/")[0]
+			}
+			name := snakeName(group)
+			if name == "" {
+				err = errors.Warning("fns: import openapi failed").WithCause(fmt.Errorf("service name can not be made")).WithMeta("path", path).WithMeta("method", method)
+				return
+			}
+			svc, has := index[name]
+			if !has {
+				svc = &service{
+					name:        name,
+					description: descriptions[group],
+					types:       newTypeWriter(doc),
+				}
+				index[name] = svc
+				services = append(services, svc)
+			}
+			f, fnErr := svc.fn(doc, path, method, item, operation)
+			if fnErr != nil {
+				err = errors.Warning("fns: import openapi failed").WithCause(fnErr).WithMeta("path", path).WithMeta("method", method)
+				return
+			}
+			svc.fns = append(svc.fns, f)
+		}
+	}
+	return
+}
+
+func (svc *service) fn(doc *Document, path string, method string, item *PathItem, operation *Operation) (f *fn, err error) {
+	name := snakeName(operation.OperationId)
+	if name == "" {
+		name = snakeName(strings.ToLower(method) + "_" + strings.NewReplacer("{", "by_", "}", "").Replace(path))
+	}
+	for i := 2; svc.hasFn(name); i++ {
+		name = fmt.Sprintf("%s_%d", strings.TrimRight(name, "_0123456789"), i)
+	}
+	f = &fn{
+		name:        name,
+		ident:       goIdent(lowerCamel(name)),
+		title:       operation.Summary,
+		description: operation.Description,
+		method:      method,
+		path:        path,
+		readonly:    method == "GET",
+		deprecated:  operation.Deprecated,
+	}
+	security := doc.Security
+	if operation.Security != nil {
+		security = *operation.Security
+	}
+	for _, requirement := range security {
+		if len(requirement) > 0 {
+			f.authorization = true
+			break
+		}
+	}
+	typePrefix := upperCamel(name)
+	// param
+	parameters := make([]*Parameter, 0, 1)
+	for _, parameter := range append(append([]*Parameter{}, item.Parameters...), operation.Parameters...) {
+		parameter, err = svc.types.parameter(parameter)
+		if err != nil {
+			return
+		}
+		if parameter.In != "path" && parameter.In != "query" {
+			continue
+		}
+		replaced := false
+		for i, exist := range parameters {
+			if exist.Name == parameter.Name && exist.In == parameter.In {
+				parameters[i] = parameter
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			parameters = append(parameters, parameter)
+		}
+	}
+	var body *Schema
+	if operation.RequestBody != nil {
+		requestBody, requestBodyErr := svc.types.requestBody(operation.RequestBody)
+		if requestBodyErr != nil {
+			err = requestBodyErr
+			return
+		}
+		body = jsonSchema(requestBody.Content)
+	}
+	if len(parameters) == 0 && body != nil {
+		f.param, err = svc.types.named(body, typePrefix+"Param")
+	} else if len(parameters) > 0 {
+		object := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		for _, parameter := range parameters {
+			schema := parameter.Schema
+			if schema == nil {
+				schema = &Schema{Type: "string"}
+			}
+			if parameter.Description != "" || parameter.Deprecated {
+				schema = &Schema{AllOf: []*Schema{schema}, Description: parameter.Description, Deprecated: parameter.Deprecated}
+			}
+			object.Properties[parameter.Name] = schema
+			if parameter.Required || parameter.In == "path" {
+				object.Required = append(object.Required, parameter.Name)
+			}
+		}
+		if body != nil {
+			resolved, resolveErr := svc.types.resolve(body)
+			if resolveErr != nil {
+				err = resolveErr
+				return
+			}
+			if isObject(resolved) {
+				resolved, err = svc.types.merge(resolved, 0)
+				if err != nil {
+					return
+				}
+				for key, property := range resolved.Properties {
+					if _, exist := object.Properties[key]; !exist {
+						object.Properties[key] = property
+					}
+				}
+				object.Required = append(object.Required, resolved.Required...)
+			} else {
+				object.Properties["body"] = body
+			}
+		}
+		f.param, err = svc.types.named(object, typePrefix+"Param")
+	}
+	if err != nil {
+		return
+	}
+	f.validation = f.param != "" && svc.types.validated[f.param]
+	// result
+	if result := successResponse(operation.Responses); result != nil {
+		response, responseErr := svc.types.response(result)
+		if responseErr != nil {
+			err = responseErr
+			return
+		}
+		if schema := jsonSchema(response.Content); schema != nil {
+			f.result, err = svc.types.named(schema, typePrefix+"Result")
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+func (svc *service) hasFn(name string) bool {
+	for _, f := range svc.fns {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (svc *service) write(dir string) (err error) {
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	title := svc.name
+	description := svc.description
+	if description == "" {
+		description = title
+	}
+	doc := bytes.NewBuffer(nil)
+	_, _ = fmt.Fprintf(doc, "// Package %s\n// @service %s\n// @title %s\n", svc.name, svc.name, title)
+	writeDescription(doc, "", description)
+	_, _ = fmt.Fprintf(doc, "package %s\n", svc.name)
+	if err = writeSource(filepath.Join(dir, "doc.go"), doc.Bytes()); err != nil {
+		return
+	}
+	if types := svc.types.source(svc.name); types != nil {
+		if err = writeSource(filepath.Join(dir, "types.go"), types); err != nil {
+			return
+		}
+	}
+	for _, f := range svc.fns {
+		filename := f.name
+		if filename == "fns" || filename == "doc" || filename == "types" || strings.HasSuffix(filename, "_test") {
+			filename = filename + "_fn"
+		}
+		if err = writeSource(filepath.Join(dir, filename+".go"), f.source(svc.name)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (f *fn) source(service string) []byte {
+	buf := bytes.NewBuffer(nil)
+	_, _ = fmt.Fprintf(buf, "package %s\n\nimport (\n\t\"github.com/aacfactory/errors\"\n\t\"github.com/aacfactory/fns/context\"\n)\n\n", service)
+	_, _ = fmt.Fprintf(buf, "// %s\n// @fn %s\n", f.ident, f.name)
+	if f.readonly {
+		buf.WriteString("// @readonly\n")
+	}
+	if f.method == "GET" || f.method == "POST" {
+		_, _ = fmt.Fprintf(buf, "// @http %s %s\n", f.method, f.path)
+	}
+	if f.authorization {
+		buf.WriteString("// @authorization\n")
+	}
+	if f.deprecated {
+		buf.WriteString("// @deprecated\n")
+	}
+	if f.validation {
+		buf.WriteString("// @validation\n")
+	}
+	title := f.title
+	if title == "" {
+		title = f.name
+	}
+	_, _ = fmt.Fprintf(buf, "// @title %s\n", singleLine(title))
+	description := f.description
+	if description == "" {
+		description = fmt.Sprintf("%s %s", f.method, f.path)
+	}
+	writeDescription(buf, "", description)
+	_, _ = fmt.Fprintf(buf, "func %s(ctx context.Context", f.ident)
+	if f.param != "" {
+		_, _ = fmt.Fprintf(buf, ", param %s", f.param)
+	}
+	buf.WriteString(") (")
+	if f.result != "" {
+		_, _ = fmt.Fprintf(buf, "result %s, ", f.result)
+	}
+	buf.WriteString("err error) {\n")
+	_, _ = fmt.Fprintf(buf, "\terr = errors.NotImplemented(%q)\n\treturn\n}\n", fmt.Sprintf("%s: %s is not implemented", service, f.name))
+	return buf.Bytes()
+}
+
+func writeSource(filename string, source []byte) (err error) {
+	formatted, formatErr := format.Source(source)
+	if formatErr != nil {
+		err = errors.Warning("fns: format source failed").WithCause(formatErr).WithMeta("file", filename)
+		return
+	}
+	err = os.WriteFile(filepath.ToSlash(filename), formatted, 0644)
+	return
+}
+
+func writeDescription(buf *bytes.Buffer, indent string, description string) {
+	description = strings.TrimSpace(description)
+	if !strings.Contains(description, "\n") {
+		_, _ = fmt.Fprintf(buf, "%s// @description %s\n", indent, description)
+		return
+	}
+	_, _ = fmt.Fprintf(buf, "%s// @description >>>\n", indent)
+	for _, line := range strings.Split(description, "\n") {
+		_, _ = fmt.Fprintf(buf, "%s// %s\n", indent, strings.TrimRight(line, " \t\r"))
+	}
+	_, _ = fmt.Fprintf(buf, "%s// <<<\n", indent)
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func jsonSchema(content map[string]*MediaType) *Schema {
+	if media, has := content["application/json"]; has && media != nil && media.Schema != nil {
+		return media.Schema
+	}
+	keys := make([]string, 0, len(content))
+	for key := range content {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.HasSuffix(key, "+json") && content[key] != nil && content[key].Schema != nil {
+			return content[key].Schema
+		}
+	}
+	return nil
+}
+
+func successResponse(responses map[string]*Response) *Response {
+	for _, code := range []string{"200", "201", "202", "2XX", "default"} {
+		if response, has := responses[code]; has && response != nil {
+			return response
+		}
+	}
+	return nil
+}
+
+// snakeName
+// converts operation ids, tags and paths to lower snake case, such as getUserById to get_user_by_id.
+func snakeName(s string) string {
+	buf := make([]rune, 0, len(s))
+	runes := []rune(strings.TrimSpace(s))
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 && len(buf) > 0 && buf[len(buf)-1] != '_' &&
+				(unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				buf = append(buf, '_')
+			}
+			buf = append(buf, unicode.ToLower(r))
+		case r < unicode.MaxASCII && (unicode.IsLower(r) || unicode.IsDigit(r)):
+			buf = append(buf, r)
+		default:
+			if len(buf) > 0 && buf[len(buf)-1] != '_' {
+				buf = append(buf, '_')
+			}
+		}
+	}
+	name := strings.Trim(string(buf), "_")
+	if name != "" && !unicode.IsLower(rune(name[0])) {
+		name = "x_" + name
+	}
+	return name
+}
+
+func upperCamel(s string) string {
+	buf := strings.Builder{}
+	for _, word := range strings.Split(snakeName(s), "_") {
+		if word == "" {
+			continue
+		}
+		buf.WriteString(strings.ToUpper(word[:1]))
+		buf.WriteString(word[1:])
+	}
+	return buf.String()
+}
+
+func lowerCamel(s string) string {
+	name := upperCamel(s)
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func goIdent(name string) string {
+	if token.IsKeyword(name) || predeclared[name] || name == "context" || name == "errors" {
+		return name + "Fn"
+	}
+	return name
+}
+
+var predeclared = map[string]bool{
+	"any": true, "bool": true, "byte": true, "error": true, "string": true, "rune": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+	"true": true, "false": true, "nil": true, "iota": true,
+	"append": true, "cap": true, "close": true, "copy": true, "delete": true, "len": true, "make": true,
+	"new": true, "panic": true, "print": true, "println": true, "recover": true, "min": true, "max": true, "clear": true,
+}
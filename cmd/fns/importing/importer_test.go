@@ -0,0 +1,143 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package importing_test
+
+import (
+	"context"
+	"github.com/aacfactory/fns/cmd/fns/importing"
+	"github.com/aacfactory/fns/cmd/generates/modules"
+	"github.com/aacfactory/fns/cmd/generates/sources"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const spec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "pets", "version": "1.0.0"},
+  "tags": [{"name": "pets", "description": "pet store"}],
+  "security": [{"bearer": []}],
+  "paths": {
+    "/pets/{id}": {
+      "parameters": [{"$ref": "#/components/parameters/Id"}],
+      "get": {
+        "operationId": "getPet",
+        "tags": ["pets"],
+        "summary": "Get pet",
+        "responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}}
+      }
+    },
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "tags": ["pets"],
+        "security": [],
+        "parameters": [{"name": "status", "in": "query", "schema": {"$ref": "#/components/schemas/Status"}}],
+        "responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}}}}
+      },
+      "put": {
+        "operationId": "replacePet",
+        "tags": ["pets"],
+        "deprecated": true,
+        "requestBody": {"$ref": "#/components/requestBodies/Pet"},
+        "responses": {"204": {"description": "no content"}}
+      }
+    }
+  },
+  "components": {
+    "parameters": {"Id": {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}},
+    "requestBodies": {"Pet": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}},
+    "schemas": {
+      "Status": {"type": "string", "enum": ["available", "sold"]},
+      "Pet": {
+        "allOf": [{"$ref": "#/components/schemas/Base"}],
+        "required": ["name"],
+        "properties": {
+          "name": {"type": "string"},
+          "status": {"$ref": "#/components/schemas/Status"},
+          "parent": {"$ref": "#/components/schemas/Pet"},
+          "born": {"type": "string", "format": "date-time"}
+        }
+      },
+      "Base": {"type": "object", "properties": {"id": {"type": "string"}}}
+    }
+  }
+}`
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/pets\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc, docErr := importing.Decode([]byte(spec))
+	if docErr != nil {
+		t.Fatal(docErr)
+	}
+	names, importErr := importing.Import(doc, filepath.Join(dir, modules.DefaultDir))
+	if importErr != nil {
+		t.Fatalf("%+v", importErr)
+	}
+	if len(names) != 1 || names[0] != "pets" {
+		t.Fatal("services", names)
+	}
+	source, _ := os.ReadFile(filepath.Join(dir, modules.DefaultDir, "pets", "get_pet.go"))
+	for _, expect := range []string{"@http GET /pets/{id}", "@authorization", "@validation", "param GetPetParam) (result Pet, err error)"} {
+		if !strings.Contains(string(source), expect) {
+			t.Errorf("%s is not found in get_pet.go:\n%s", expect, source)
+		}
+	}
+	source, _ = os.ReadFile(filepath.Join(dir, modules.DefaultDir, "pets", "types.go"))
+	for _, expect := range []string{"@enum available,sold", "Parent *Pet", "oneof=available sold", "Born time.Time"} {
+		if !strings.Contains(string(source), expect) {
+			t.Errorf("%s is not found in types.go:\n%s", expect, source)
+		}
+	}
+
+	mod, modErr := sources.New(filepath.Join(dir, "go.mod"))
+	if modErr != nil {
+		t.Fatal(modErr)
+	}
+	if err := mod.Parse(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	services, loadErr := modules.Load(mod, modules.DefaultDir)
+	if loadErr != nil {
+		t.Fatalf("%+v", loadErr)
+	}
+	if len(services) != 1 || len(services[0].Functions) != 3 {
+		t.Fatal("services", services)
+	}
+	for _, function := range services[0].Functions {
+		if err := function.Parse(context.TODO()); err != nil {
+			t.Fatalf("%+v", err)
+		}
+		switch function.Name() {
+		case "list_pets":
+			if function.Authorization() || !function.Readonly() {
+				t.Error("list_pets must be readonly without authorization")
+			}
+			break
+		case "replace_pet":
+			if !function.Deprecated() || function.Result != nil {
+				t.Error("replace_pet must be deprecated without result")
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package importing
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Document
+// is the subset of openapi 3 document which is used to import services.
+type Document struct {
+	Openapi    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Tags       []Tag                 `json:"tags"`
+	Paths      map[string]*PathItem  `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type Components struct {
+	Schemas       map[string]*Schema      `json:"schemas"`
+	Parameters    map[string]*Parameter   `json:"parameters"`
+	RequestBodies map[string]*RequestBody `json:"requestBodies"`
+	Responses     map[string]*Response    `json:"responses"`
+}
+
+type PathItem struct {
+	Ref        string       `json:"$ref"`
+	Parameters []*Parameter `json:"parameters"`
+	Get        *Operation   `json:"get"`
+	Put        *Operation   `json:"put"`
+	Post       *Operation   `json:"post"`
+	Delete     *Operation   `json:"delete"`
+	Patch      *Operation   `json:"patch"`
+}
+
+// Operations
+// returns operations of path item in a stable order.
+func (item *PathItem) Operations() (methods []string, operations []*Operation) {
+	candidates := []*Operation{item.Get, item.Post, item.Put, item.Patch, item.Delete}
+	for i, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE"} {
+		if candidates[i] == nil {
+			continue
+		}
+		methods = append(methods, method)
+		operations = append(operations, candidates[i])
+	}
+	return
+}
+
+type Operation struct {
+	OperationId string                 `json:"operationId"`
+	Tags        []string               `json:"tags"`
+	Summary     string                 `json:"summary"`
+	Description string                 `json:"description"`
+	Parameters  []*Parameter           `json:"parameters"`
+	RequestBody *RequestBody           `json:"requestBody"`
+	Responses   map[string]*Response   `json:"responses"`
+	Deprecated  bool                   `json:"deprecated"`
+	Security    *[]map[string][]string `json:"security"`
+}
+
+type Parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Deprecated  bool    `json:"deprecated"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Required    bool                  `json:"required"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Title                string             `json:"title"`
+	Description          string             `json:"description"`
+	Type                 SchemaType         `json:"type"`
+	Format               string             `json:"format"`
+	Enum                 []interface{}      `json:"enum"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*Schema `json:"properties"`
+	Items                *Schema            `json:"items"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	AllOf                []*Schema          `json:"allOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+	AnyOf                []*Schema          `json:"anyOf"`
+	Deprecated           bool               `json:"deprecated"`
+}
+
+// AdditionalSchema
+// returns schema of additionalProperties when it is not a boolean.
+func (schema *Schema) AdditionalSchema() (v *Schema, has bool) {
+	if len(schema.AdditionalProperties) == 0 || schema.AdditionalProperties[0] != '{' {
+		return
+	}
+	v = &Schema{}
+	if err := json.Unmarshal(schema.AdditionalProperties, v); err != nil {
+		v = nil
+		return
+	}
+	has = true
+	return
+}
+
+// SchemaType
+// is type of schema, openapi 3.1 allows a list of types such as ["string", "null"], then the first non-null one is used.
+type SchemaType string
+
+func (t *SchemaType) UnmarshalJSON(p []byte) (err error) {
+	if len(p) > 0 && p[0] == '[' {
+		types := make([]string, 0, 1)
+		if err = json.Unmarshal(p, &types); err != nil {
+			return
+		}
+		for _, typ := range types {
+			if typ != "null" {
+				*t = SchemaType(typ)
+				break
+			}
+		}
+		return
+	}
+	s := ""
+	if err = json.Unmarshal(p, &s); err != nil {
+		return
+	}
+	*t = SchemaType(strings.TrimSpace(s))
+	return
+}
@@ -0,0 +1,468 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package importing
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"sort"
+	"strings"
+)
+
+func newTypeWriter(doc *Document) *typeWriter {
+	return &typeWriter{
+		doc:       doc,
+		decls:     make([]*decl, 0, 1),
+		refs:      make(map[string]string),
+		taken:     make(map[string]bool),
+		validated: make(map[string]bool),
+		imports:   make(map[string]bool),
+	}
+}
+
+type decl struct {
+	name   string
+	source string
+}
+
+// typeWriter
+// converts schemas of a service to go types, a schema of components is declared once even when it is referenced by many fns.
+type typeWriter struct {
+	doc       *Document
+	decls     []*decl
+	refs      map[string]string
+	taken     map[string]bool
+	validated map[string]bool
+	imports   map[string]bool
+}
+
+func (w *typeWriter) parameter(parameter *Parameter) (v *Parameter, err error) {
+	v = parameter
+	for depth := 0; v.Ref != ""; depth++ {
+		name, ok := refName(v.Ref, "parameters")
+		target, has := w.doc.Components.Parameters[name]
+		if !ok || !has || target == nil || depth > 8 {
+			err = errors.Warning("fns: resolve $ref failed").WithMeta("ref", v.Ref)
+			return
+		}
+		v = target
+	}
+	return
+}
+
+func (w *typeWriter) requestBody(body *RequestBody) (v *RequestBody, err error) {
+	v = body
+	for depth := 0; v.Ref != ""; depth++ {
+		name, ok := refName(v.Ref, "requestBodies")
+		target, has := w.doc.Components.RequestBodies[name]
+		if !ok || !has || target == nil || depth > 8 {
+			err = errors.Warning("fns: resolve $ref failed").WithMeta("ref", v.Ref)
+			return
+		}
+		v = target
+	}
+	return
+}
+
+func (w *typeWriter) response(response *Response) (v *Response, err error) {
+	v = response
+	for depth := 0; v.Ref != ""; depth++ {
+		name, ok := refName(v.Ref, "responses")
+		target, has := w.doc.Components.Responses[name]
+		if !ok || !has || target == nil || depth > 8 {
+			err = errors.Warning("fns: resolve $ref failed").WithMeta("ref", v.Ref)
+			return
+		}
+		v = target
+	}
+	return
+}
+
+// resolve
+// follows $ref of schema until a schema without $ref.
+func (w *typeWriter) resolve(schema *Schema) (v *Schema, err error) {
+	v = schema
+	for depth := 0; v.Ref != ""; depth++ {
+		name, ok := refName(v.Ref, "schemas")
+		target, has := w.doc.Components.Schemas[name]
+		if !ok || !has || target == nil || depth > 8 {
+			err = errors.Warning("fns: resolve $ref failed").WithMeta("ref", v.Ref)
+			return
+		}
+		v = target
+	}
+	return
+}
+
+// named
+// returns a named struct type of schema for param or result of fn,
+// generator only accepts value objects, so a schema which is not an object is wrapped into a struct with a value field.
+func (w *typeWriter) named(schema *Schema, hint string) (name string, err error) {
+	expr, exprErr := w.typeOf(schema, hint)
+	if exprErr != nil {
+		err = exprErr
+		return
+	}
+	if w.declared(expr) {
+		name = expr
+		return
+	}
+	name = w.unique(hint)
+	buf := bytes.NewBuffer(nil)
+	_, _ = fmt.Fprintf(buf, "// %s\n// @title %s\n// @description schema of openapi is not an object, so it is wrapped as value\ntype %s struct {\n", name, name, name)
+	_, _ = fmt.Fprintf(buf, "\t// Value\n\t// @title Value\n\tValue %s `json:\"value\"`\n}\n", expr)
+	w.decls = append(w.decls, &decl{name: name, source: buf.String()})
+	return
+}
+
+func (w *typeWriter) typeOf(schema *Schema, hint string) (expr string, err error) {
+	if schema == nil {
+		w.imports["encoding/json"] = true
+		expr = "json.RawMessage"
+		return
+	}
+	if schema.Ref != "" {
+		target, resolveErr := w.resolve(schema)
+		if resolveErr != nil {
+			err = resolveErr
+			return
+		}
+		if !isObject(target) {
+			name, _ := refName(schema.Ref, "schemas")
+			expr, err = w.typeOf(target, upperCamel(name))
+			return
+		}
+		if declared, has := w.refs[schema.Ref]; has {
+			expr = declared
+			return
+		}
+		name, _ := refName(schema.Ref, "schemas")
+		expr = w.unique(upperCamel(name))
+		w.refs[schema.Ref] = expr
+		err = w.structOf(expr, target)
+		return
+	}
+	if len(schema.AllOf) == 1 && len(schema.Properties) == 0 {
+		expr, err = w.typeOf(schema.AllOf[0], hint)
+		return
+	}
+	if len(schema.AllOf) > 0 || len(schema.Properties) > 0 {
+		expr = w.unique(hint)
+		err = w.structOf(expr, schema)
+		return
+	}
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		w.imports["encoding/json"] = true
+		expr = "json.RawMessage"
+		return
+	}
+	switch schema.Type {
+	case "object":
+		if additional, has := schema.AdditionalSchema(); has {
+			value, valueErr := w.typeOf(additional, hint+"Value")
+			if valueErr != nil {
+				err = valueErr
+				return
+			}
+			expr = "map[string]" + value
+			break
+		}
+		w.imports["encoding/json"] = true
+		expr = "json.RawMessage"
+		break
+	case "array":
+		item, itemErr := w.typeOf(schema.Items, hint+"Item")
+		if itemErr != nil {
+			err = itemErr
+			return
+		}
+		expr = "[]" + item
+		break
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			w.imports["time"] = true
+			expr = "time.Time"
+			break
+		case "byte":
+			expr = "[]byte"
+			break
+		case "uuid":
+			w.imports["github.com/aacfactory/fns/commons/uuids"] = true
+			expr = "uuids.UUID"
+			break
+		case "decimal":
+			w.imports["github.com/aacfactory/fns/commons/decimals"] = true
+			expr = "decimals.Decimal"
+			break
+		default:
+			expr = "string"
+			break
+		}
+		break
+	case "integer":
+		if schema.Format == "int32" {
+			expr = "int32"
+		} else {
+			expr = "int64"
+		}
+		break
+	case "number":
+		if schema.Format == "float" {
+			expr = "float32"
+		} else {
+			expr = "float64"
+		}
+		break
+	case "boolean":
+		expr = "bool"
+		break
+	default:
+		w.imports["encoding/json"] = true
+		expr = "json.RawMessage"
+		break
+	}
+	return
+}
+
+// structOf
+// declares a struct named name, the declaration is reserved before fields are converted, so recursive schemas are supported.
+func (w *typeWriter) structOf(name string, schema *Schema) (err error) {
+	d := &decl{name: name}
+	w.decls = append(w.decls, d)
+	object, mergeErr := w.merge(schema, 0)
+	if mergeErr != nil {
+		err = mergeErr
+		return
+	}
+	required := make(map[string]bool)
+	for _, key := range object.Required {
+		required[key] = true
+	}
+	keys := make([]string, 0, len(object.Properties))
+	for key := range object.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	buf := bytes.NewBuffer(nil)
+	title := schema.Title
+	if title == "" {
+		title = name
+	}
+	_, _ = fmt.Fprintf(buf, "// %s\n// @title %s\n", name, singleLine(title))
+	if schema.Description != "" {
+		writeDescription(buf, "", schema.Description)
+	}
+	_, _ = fmt.Fprintf(buf, "type %s struct {\n", name)
+	fields := make(map[string]bool)
+	for _, key := range keys {
+		property := object.Properties[key]
+		field := upperCamel(key)
+		if field == "" {
+			field = "Field"
+		}
+		for i := 2; fields[field]; i++ {
+			field = fmt.Sprintf("%s%d", strings.TrimRight(field, "0123456789"), i)
+		}
+		fields[field] = true
+		expr, exprErr := w.typeOf(property, name+field)
+		if exprErr != nil {
+			err = errors.Warning("fns: convert schema failed").WithCause(exprErr).WithMeta("type", name).WithMeta("property", key)
+			return
+		}
+		if w.declared(expr) && (!required[key] || expr == name) {
+			expr = "*" + expr
+		}
+		description, deprecated, enum := w.annotations(property)
+		_, _ = fmt.Fprintf(buf, "\t// %s\n\t// @title %s\n", field, key)
+		if description != "" {
+			writeDescription(buf, "\t", description)
+		}
+		if len(enum) > 0 {
+			_, _ = fmt.Fprintf(buf, "\t// @enum %s\n", strings.Join(enum, ","))
+		}
+		if deprecated {
+			buf.WriteString("\t// @deprecated\n")
+		}
+		tag := fmt.Sprintf("json:\"%s\"", key)
+		if !required[key] {
+			tag = fmt.Sprintf("json:\"%s,omitempty\"", key)
+		}
+		validates := make([]string, 0, 1)
+		if required[key] && zeroIsMissing(expr) {
+			validates = append(validates, "required")
+		}
+		if oneOf := oneOfValidate(enum); oneOf != "" {
+			if !required[key] {
+				validates = append(validates, "omitempty")
+			}
+			validates = append(validates, oneOf)
+		}
+		if len(validates) > 0 {
+			tag = fmt.Sprintf("%s validate:\"%s\" validate-message:\"%s is invalid\"", tag, strings.Join(validates, ","), key)
+			w.validated[name] = true
+		}
+		_, _ = fmt.Fprintf(buf, "\t%s %s `%s`\n", field, expr, tag)
+	}
+	buf.WriteString("}\n")
+	d.source = buf.String()
+	return
+}
+
+// merge
+// merges allOf of schema into an object schema.
+func (w *typeWriter) merge(schema *Schema, depth int) (object *Schema, err error) {
+	if depth > 8 {
+		err = errors.Warning("fns: merge allOf failed, it is too deep")
+		return
+	}
+	object = &Schema{Properties: make(map[string]*Schema)}
+	for _, part := range schema.AllOf {
+		resolved, resolveErr := w.resolve(part)
+		if resolveErr != nil {
+			err = resolveErr
+			return
+		}
+		merged, mergeErr := w.merge(resolved, depth+1)
+		if mergeErr != nil {
+			err = mergeErr
+			return
+		}
+		for key, property := range merged.Properties {
+			object.Properties[key] = property
+		}
+		object.Required = append(object.Required, merged.Required...)
+	}
+	for key, property := range schema.Properties {
+		object.Properties[key] = property
+	}
+	object.Required = append(object.Required, schema.Required...)
+	return
+}
+
+// annotations
+// returns description, deprecated and enum of property, they are read through $ref and single allOf.
+func (w *typeWriter) annotations(schema *Schema) (description string, deprecated bool, enum []string) {
+	for depth := 0; schema != nil && depth < 8; depth++ {
+		if description == "" {
+			description = schema.Description
+		}
+		deprecated = deprecated || schema.Deprecated
+		if len(enum) == 0 {
+			for _, value := range schema.Enum {
+				if value == nil {
+					continue
+				}
+				enum = append(enum, fmt.Sprintf("%v", value))
+			}
+		}
+		if schema.Ref != "" {
+			resolved, resolveErr := w.resolve(schema)
+			if resolveErr != nil || isObject(resolved) {
+				return
+			}
+			schema = resolved
+			continue
+		}
+		if len(schema.AllOf) == 1 && len(schema.Properties) == 0 {
+			schema = schema.AllOf[0]
+			continue
+		}
+		return
+	}
+	return
+}
+
+func (w *typeWriter) declared(name string) bool {
+	for _, d := range w.decls {
+		if d.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *typeWriter) unique(name string) string {
+	if name == "" {
+		name = "Type"
+	}
+	v := name
+	for i := 2; w.taken[v]; i++ {
+		v = fmt.Sprintf("%s%d", name, i)
+	}
+	w.taken[v] = true
+	return v
+}
+
+func (w *typeWriter) source(pkg string) []byte {
+	if len(w.decls) == 0 {
+		return nil
+	}
+	buf := bytes.NewBuffer(nil)
+	_, _ = fmt.Fprintf(buf, "package %s\n\n", pkg)
+	if len(w.imports) > 0 {
+		imports := make([]string, 0, len(w.imports))
+		for path := range w.imports {
+			imports = append(imports, path)
+		}
+		sort.Strings(imports)
+		buf.WriteString("import (\n")
+		for _, path := range imports {
+			_, _ = fmt.Fprintf(buf, "\t%q\n", path)
+		}
+		buf.WriteString(")\n\n")
+	}
+	for _, d := range w.decls {
+		buf.WriteString(d.source)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+func refName(ref string, kind string) (name string, ok bool) {
+	prefix := "#/components/" + kind + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return
+	}
+	name = strings.ReplaceAll(strings.ReplaceAll(ref[len(prefix):], "~1", "/"), "~0", "~")
+	ok = name != ""
+	return
+}
+
+func isObject(schema *Schema) bool {
+	return len(schema.Properties) > 0 || len(schema.AllOf) > 0
+}
+
+func zeroIsMissing(expr string) bool {
+	return expr == "string" || expr == "json.RawMessage" || strings.HasPrefix(expr, "[]") || strings.HasPrefix(expr, "map[")
+}
+
+// oneOfValidate
+// returns oneof rule of validator, values with spaces can not be validated by oneof, then nothing is returned.
+func oneOfValidate(enum []string) string {
+	if len(enum) == 0 {
+		return ""
+	}
+	for _, value := range enum {
+		if value == "" || strings.ContainsAny(value, " ,\"'`|") {
+			return ""
+		}
+	}
+	return "oneof=" + strings.Join(enum, " ")
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package linting
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/cmd/fns/listing"
+	"github.com/aacfactory/fns/cmd/generates/modules"
+	"github.com/urfave/cli/v2"
+	"path/filepath"
+	"strings"
+)
+
+var Command = &cli.Command{
+	Name:        "lint",
+	Aliases:     nil,
+	Usage:       "fns lint --allow={annotation} --errors --json --work={go.work} {project dir}",
+	Description: "check annotations of fns, exit with 1 when there are problems",
+	ArgsUsage:   "",
+	Category:    "",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:     "allow",
+			Aliases:  []string{"a"},
+			Required: false,
+			Usage:    "annotations of custom writers, such as -a sql",
+		},
+		&cli.BoolFlag{
+			Name:     "errors",
+			Required: false,
+			Usage:    "check @errors against errors made in bodies of fns, it is best-effort",
+		},
+		&cli.BoolFlag{
+			Name:     "json",
+			Required: false,
+			Usage:    "output as json",
+		},
+		&cli.StringFlag{
+			Name:     "work",
+			Aliases:  []string{"w"},
+			Required: false,
+			Usage:    "go work file path",
+		},
+	},
+	Action: func(ctx *cli.Context) (err error) {
+		projectDir := strings.TrimSpace(ctx.Args().First())
+		if projectDir == "" {
+			projectDir = "."
+		}
+		projectDir, err = filepath.Abs(projectDir)
+		if err != nil {
+			err = errors.Warning("fns: lint failed").WithCause(err).WithMeta("dir", projectDir)
+			return
+		}
+		mod, modErr := listing.LoadModule(ctx.Context, projectDir, ctx.String("work"))
+		if modErr != nil {
+			err = errors.Warning("fns: lint failed").WithCause(modErr)
+			return
+		}
+		diagnostics, lintErr := modules.Lint(ctx.Context, mod, modules.DefaultDir, nil, ctx.StringSlice("allow")...)
+		if lintErr != nil {
+			err = errors.Warning("fns: lint failed").WithCause(lintErr)
+			return
+		}
+		if ctx.Bool("errors") {
+			errorDiagnostics, lintErrorsErr := modules.LintErrors(ctx.Context, mod, modules.DefaultDir)
+			if lintErrorsErr != nil {
+				err = errors.Warning("fns: lint failed").WithCause(lintErrorsErr)
+				return
+			}
+			diagnostics = append(diagnostics, errorDiagnostics...)
+		}
+		if ctx.Bool("json") {
+			if diagnostics == nil {
+				diagnostics = make([]modules.Diagnostic, 0)
+			}
+			p, encodeErr := json.MarshalIndent(diagnostics, "", "  ")
+			if encodeErr != nil {
+				err = errors.Warning("fns: lint failed").WithCause(encodeErr)
+				return
+			}
+			fmt.Println(string(p))
+		} else {
+			for _, diagnostic := range diagnostics {
+				fmt.Println(diagnostic.String())
+			}
+		}
+		if len(diagnostics) > 0 {
+			err = cli.Exit(fmt.Sprintf("fns: %d problem(s) found", len(diagnostics)), 1)
+			return
+		}
+		return
+	},
+}
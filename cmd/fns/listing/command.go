@@ -0,0 +1,166 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package listing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/cmd/generates/files"
+	"github.com/aacfactory/fns/cmd/generates/modules"
+	"github.com/aacfactory/fns/cmd/generates/sources"
+	"github.com/urfave/cli/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+var Command = &cli.Command{
+	Name:        "list",
+	Aliases:     []string{"ls"},
+	Usage:       "fns list --json --annotation={annotation} --work={go.work} {project dir}",
+	Description: "list services and fns of project, parsed as same as generation",
+	ArgsUsage:   "",
+	Category:    "",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:     "json",
+			Required: false,
+			Usage:    "output as json",
+		},
+		&cli.StringSliceFlag{
+			Name:     "annotation",
+			Aliases:  []string{"a"},
+			Required: false,
+			Usage:    "only list fns which have all of annotations, such as -a deprecated -a authorization",
+		},
+		&cli.StringFlag{
+			Name:     "work",
+			Aliases:  []string{"w"},
+			Required: false,
+			Usage:    "go work file path",
+		},
+	},
+	Action: func(ctx *cli.Context) (err error) {
+		projectDir := strings.TrimSpace(ctx.Args().First())
+		if projectDir == "" {
+			projectDir = "."
+		}
+		projectDir, err = filepath.Abs(projectDir)
+		if err != nil {
+			err = errors.Warning("fns: list failed").WithCause(err).WithMeta("dir", projectDir)
+			return
+		}
+		mod, modErr := LoadModule(ctx.Context, projectDir, ctx.String("work"))
+		if modErr != nil {
+			err = errors.Warning("fns: list failed").WithCause(modErr)
+			return
+		}
+		services, inspectErr := modules.Inspect(ctx.Context, mod, modules.DefaultDir)
+		if inspectErr != nil {
+			err = errors.Warning("fns: list failed").WithCause(inspectErr)
+			return
+		}
+		services = Filter(services, ctx.StringSlice("annotation"))
+		if ctx.Bool("json") {
+			p, encodeErr := json.MarshalIndent(services, "", "  ")
+			if encodeErr != nil {
+				err = errors.Warning("fns: list failed").WithCause(encodeErr)
+				return
+			}
+			fmt.Println(string(p))
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "SERVICE\tFN\tPARAM\tRESULT\tANNOTATIONS\tFILE")
+		for _, service := range services {
+			for _, fn := range service.Functions {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s:%d\n", service.Name, fn.Name, fn.Param, fn.Result, annotations(fn), fn.File, fn.Line)
+			}
+		}
+		err = w.Flush()
+		return
+	},
+}
+
+// LoadModule
+// parses go.mod in project dir, go.work in parent dir is used when work is empty and it exists.
+func LoadModule(ctx context.Context, projectDir string, work string) (mod *sources.Module, err error) {
+	work = strings.TrimSpace(work)
+	if work == "" {
+		if parentWork := filepath.Join(filepath.Dir(projectDir), "go.work"); files.ExistFile(parentWork) {
+			work = parentWork
+		}
+	}
+	mod, err = sources.NewWithWork(filepath.Join(projectDir, "go.mod"), work)
+	if err != nil {
+		return
+	}
+	err = mod.Parse(ctx)
+	return
+}
+
+// Filter
+// keeps fns which have all of annotations, and services which have fns left.
+func Filter(services []modules.ServiceInfo, annotations []string) (v []modules.ServiceInfo) {
+	if len(annotations) == 0 {
+		v = services
+		return
+	}
+	v = make([]modules.ServiceInfo, 0, len(services))
+	for _, service := range services {
+		fns := make([]modules.FunctionInfo, 0, len(service.Functions))
+		for _, fn := range service.Functions {
+			matched := true
+			for _, annotation := range annotations {
+				if !fn.Has(strings.TrimPrefix(strings.TrimSpace(annotation), "@")) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				fns = append(fns, fn)
+			}
+		}
+		if len(fns) == 0 {
+			continue
+		}
+		service.Functions = fns
+		v = append(v, service)
+	}
+	return
+}
+
+func annotations(fn modules.FunctionInfo) string {
+	names := make([]string, 0, len(fn.Annotations))
+	for name, params := range fn.Annotations {
+		switch name {
+		case "fn", "title", "description", "errors":
+			continue
+		}
+		if len(params) > 0 && len(params) < 3 && len(strings.Join(params, " ")) < 32 {
+			name = fmt.Sprintf("%s(%s)", name, strings.Join(params, " "))
+		}
+		names = append(names, "@"+name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
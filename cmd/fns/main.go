@@ -20,7 +20,11 @@ package main
 import (
 	"context"
 	"fmt"
+	"github.com/aacfactory/fns/cmd/fns/creation"
+	"github.com/aacfactory/fns/cmd/fns/importing"
 	"github.com/aacfactory/fns/cmd/fns/initialization"
+	"github.com/aacfactory/fns/cmd/fns/linting"
+	"github.com/aacfactory/fns/cmd/fns/listing"
 	"github.com/aacfactory/fns/cmd/fns/ssc"
 	"github.com/urfave/cli/v2"
 	"os"
@@ -59,6 +63,10 @@ func main() {
 	app.Copyright = Copyright
 	app.Commands = []*cli.Command{
 		initialization.Command,
+		creation.Command,
+		importing.Command,
+		listing.Command,
+		linting.Command,
 		ssc.Command,
 	}
 	if err := app.RunContext(context.Background(), os.Args); err != nil {
@@ -42,6 +42,15 @@ func WithModulesDir(dir string) Option {
 	}
 }
 
+// RegisterAnnotations
+// registers writers of custom fn annotations for all generators, plugin package can call it in init,
+// then it only needs to be imported by main of generator, such as `import _ "foo.com/plugins/ratelimit"`.
+func RegisterAnnotations(annotations ...modules.FnAnnotationCodeWriter) {
+	for _, annotation := range annotations {
+		modules.RegisterAnnotation(annotation)
+	}
+}
+
 func WithAnnotations(annotations ...modules.FnAnnotationCodeWriter) Option {
 	return func(options *Options) {
 		if options.annotations == nil {
@@ -88,9 +97,16 @@ func New(options ...Option) (cmd Command) {
 	if name == "" {
 		name = callerPKG()
 	}
+	// registered writers are overridden by writers of options which have same annotation
+	annotations := modules.FnAnnotationCodeWriters(opt.annotations)
+	for _, registered := range modules.RegisteredAnnotations() {
+		if _, has := annotations.Get(registered.Annotation()); !has {
+			annotations = append(annotations, registered)
+		}
+	}
 	act := &action{
 		modulesDir:   opt.modulesDir,
-		annotations:  opt.annotations,
+		annotations:  annotations,
 		builtinTypes: opt.builtinTypes,
 		generators:   opt.generators,
 	}
@@ -19,9 +19,16 @@ package modules
 
 import (
 	"context"
+	"fmt"
+	"github.com/aacfactory/errors"
 	"github.com/aacfactory/gcg"
+	"strings"
+	"sync"
 )
 
+// FnAnnotationCodeWriter
+// writes code of custom fn annotation, such as @sql of fns-contrib.
+// ctx carries the function being generated, use LoadFunction to read it, for example to know whether it is readonly.
 type FnAnnotationCodeWriter interface {
 	Annotation() (annotation string)
 	HandleBefore(ctx context.Context, params []string, hasFnParam bool, hasFnResult bool) (code gcg.Code, err error)
@@ -42,3 +49,97 @@ func (writers FnAnnotationCodeWriters) Get(annotation string) (w FnAnnotationCod
 	}
 	return
 }
+
+var (
+	registeredAnnotations       = make(FnAnnotationCodeWriters, 0, 1)
+	registeredAnnotationsLocker = sync.Mutex{}
+)
+
+// RegisterAnnotation
+// registers writer of custom fn annotation, it is usually called in init of a plugin package,
+// then generators created after it use the writer, see generates.New.
+// it panics when annotation is empty, builtin or registered.
+func RegisterAnnotation(writer FnAnnotationCodeWriter) {
+	if writer == nil {
+		panic(fmt.Sprintf("%+v", errors.Warning("fns: register annotation failed").WithCause(fmt.Errorf("writer is nil"))))
+		return
+	}
+	annotation := strings.TrimSpace(writer.Annotation())
+	if annotation == "" {
+		panic(fmt.Sprintf("%+v", errors.Warning("fns: register annotation failed").WithCause(fmt.Errorf("annotation is empty"))))
+		return
+	}
+	for _, builtin := range BuiltinFnAnnotations {
+		if builtin == annotation {
+			panic(fmt.Sprintf("%+v", errors.Warning("fns: register annotation failed").WithCause(fmt.Errorf("annotation is builtin")).WithMeta("annotation", annotation)))
+			return
+		}
+	}
+	registeredAnnotationsLocker.Lock()
+	defer registeredAnnotationsLocker.Unlock()
+	if _, has := registeredAnnotations.Get(annotation); has {
+		panic(fmt.Sprintf("%+v", errors.Warning("fns: register annotation failed").WithCause(fmt.Errorf("annotation was registered")).WithMeta("annotation", annotation)))
+		return
+	}
+	registeredAnnotations = append(registeredAnnotations, writer)
+}
+
+// RegisteredAnnotations
+// returns writers which are registered by RegisterAnnotation.
+func RegisteredAnnotations() (writers FnAnnotationCodeWriters) {
+	registeredAnnotationsLocker.Lock()
+	writers = make(FnAnnotationCodeWriters, len(registeredAnnotations))
+	copy(writers, registeredAnnotations)
+	registeredAnnotationsLocker.Unlock()
+	return
+}
+
+type functionContextKey struct{}
+
+func withFunction(ctx context.Context, function *Function) context.Context {
+	return context.WithValue(ctx, functionContextKey{}, function)
+}
+
+// LoadFunction
+// returns the function whose code is being written, it is available in FnAnnotationCodeWriter.
+func LoadFunction(ctx context.Context) (function *Function, has bool) {
+	function, has = ctx.Value(functionContextKey{}).(*Function)
+	return
+}
+
+const (
+	DatabaseRolePrimary = "primary"
+	DatabaseRoleRead    = "read"
+)
+
+// ParseDatabaseAnnotationParam
+// parses `{name}[:{role}]` of database annotation, such as `@sql main:read`.
+// role is primary or read, default is primary.
+// read role is only allowed by readonly fn, so writes of fn always target the primary.
+// the sql writer of fns-contrib generates sql.WithOptions(ctx, sql.Database(name)) for primary,
+// and routes to read replicas of the database for read.
+func ParseDatabaseAnnotationParam(ctx context.Context, param string) (name string, role string, err error) {
+	param = strings.TrimSpace(param)
+	name, role, _ = strings.Cut(param, ":")
+	name = strings.TrimSpace(name)
+	role = strings.TrimSpace(role)
+	if name == "" {
+		err = errors.Warning("fns: parse database annotation failed").WithCause(fmt.Errorf("name is required")).WithMeta("param", param)
+		return
+	}
+	switch role {
+	case "", DatabaseRolePrimary:
+		role = DatabaseRolePrimary
+		break
+	case DatabaseRoleRead:
+		if function, has := LoadFunction(ctx); has && !function.Readonly() {
+			err = errors.Warning("fns: parse database annotation failed").WithCause(fmt.Errorf("read role requires @readonly")).WithMeta("param", param)
+			return
+		}
+		break
+	default:
+		err = errors.Warning("fns: parse database annotation failed").WithCause(fmt.Errorf("role must be primary or read")).WithMeta("param", param)
+		return
+	}
+	return
+}
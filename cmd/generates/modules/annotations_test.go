@@ -0,0 +1,58 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package modules
+
+import (
+	"context"
+	"github.com/aacfactory/fns/cmd/generates/sources"
+	"testing"
+)
+
+func TestParseDatabaseAnnotationParam(t *testing.T) {
+	readonly := withFunction(context.TODO(), &Function{Annotations: sources.Annotations{sources.NewAnnotation("readonly")}})
+	writable := withFunction(context.TODO(), &Function{Annotations: sources.Annotations{}})
+	for _, c := range []struct {
+		ctx  context.Context
+		in   string
+		name string
+		role string
+	}{
+		{context.TODO(), "main", "main", DatabaseRolePrimary},
+		{writable, " main : primary ", "main", DatabaseRolePrimary},
+		{readonly, "main:read", "main", DatabaseRoleRead},
+		{context.TODO(), "main:read", "main", DatabaseRoleRead},
+	} {
+		name, role, err := ParseDatabaseAnnotationParam(c.ctx, c.in)
+		if err != nil || name != c.name || role != c.role {
+			t.Fatal("unexpected", c.in, name, role, err)
+		}
+	}
+	for _, c := range []struct {
+		ctx context.Context
+		in  string
+	}{
+		{context.TODO(), ""},
+		{context.TODO(), ":read"},
+		{context.TODO(), "main:replica"},
+		{writable, "main:read"},
+	} {
+		if _, _, err := ParseDatabaseAnnotationParam(c.ctx, c.in); err == nil {
+			t.Fatal("want error", c.in)
+		}
+	}
+}
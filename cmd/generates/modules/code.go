@@ -215,6 +215,24 @@ func (s *ServiceFile) componentCode(ctx context.Context) (code gcg.Code, err err
 	fn.Body(body)
 
 	stmt.Add(fn.Build()).Line()
+
+	if s.service.Components != nil && s.service.Components.Len() > 0 {
+		path := fmt.Sprintf("%s/components", s.service.Path)
+		for _, component := range s.service.Components {
+			componentCode := gcg.QualifiedIdent(gcg.NewPackage(path), component.Indent)
+			accessor := gcg.Func()
+			accessor.Name(component.AccessorName())
+			accessor.AddParam("ctx", contextCode())
+			accessor.AddResult("component", gcg.Star().Add(componentCode))
+			accessor.AddResult("has", gcg.Token("bool"))
+			accessorBody := gcg.Statements()
+			accessorBody.Tab().Token("component, has = services.LoadComponentOf[*").Add(componentCode).Token("](ctx, _endpointName)").Line()
+			accessorBody.Tab().Return()
+			accessor.Body(accessorBody)
+			stmt.Add(gcg.Token(fmt.Sprintf("// %s\n// returns the %s component of service.", component.AccessorName(), component.Indent)).Line())
+			stmt.Add(accessor.Build()).Line()
+		}
+	}
 	code = stmt
 	return
 }
@@ -253,6 +271,13 @@ func (s *ServiceFile) serviceCode(ctx context.Context) (code gcg.Code, err error
 	if constructFnCode != nil {
 		stmt.Add(constructFnCode).Line()
 	}
+	// renew
+	renewCode, renewCodeErr := s.serviceRenewCode(ctx)
+	if renewCodeErr != nil {
+		err = renewCodeErr
+		return
+	}
+	stmt.Add(renewCode).Line()
 	// doc
 	docCode, docCodeErr := s.serviceDocumentCode(ctx)
 	if docCodeErr != nil {
@@ -290,6 +315,10 @@ func (s *ServiceFile) serviceInstanceCode(ctx context.Context) (code gcg.Code, e
 		path := fmt.Sprintf("%s/components", s.service.Path)
 		for _, component := range s.service.Components {
 			componentCode := gcg.QualifiedIdent(gcg.NewPackage(path), component.Indent)
+			if component.Lazy {
+				body.Tab().Tab().Token("services.Lazy(&").Add(componentCode).Token("{})").Symbol(",").Line()
+				continue
+			}
 			body.Tab().Tab().Token("&").Add(componentCode).Token("{}").Symbol(",").Line()
 		}
 	}
@@ -317,6 +346,27 @@ func (s *ServiceFile) serviceTypeCode(ctx context.Context) (code gcg.Code, err e
 	return
 }
 
+// serviceRenewCode
+// makes service renewable, so it can be reloaded by services.Manager in development.
+func (s *ServiceFile) serviceRenewCode(ctx context.Context) (code gcg.Code, err error) {
+	if ctx.Err() != nil {
+		err = errors.Warning("modules: service write failed").
+			WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+			WithCause(ctx.Err())
+		return
+	}
+	renew := gcg.Func()
+	renew.Name("Renew")
+	renew.Receiver("svc", gcg.Star().Ident("_service"))
+	renew.AddResult("v", gcg.QualifiedIdent(gcg.NewPackage("github.com/aacfactory/fns/services"), "Service"))
+	body := gcg.Statements()
+	body.Tab().Token("v = Service()").Line()
+	body.Tab().Return()
+	renew.Body(body)
+	code = renew.Build()
+	return
+}
+
 func (s *ServiceFile) serviceConstructCode(ctx context.Context) (code gcg.Code, err error) {
 	if ctx.Err() != nil {
 		err = errors.Warning("modules: service write failed").
@@ -338,42 +388,24 @@ func (s *ServiceFile) serviceConstructCode(ctx context.Context) (code gcg.Code,
 	for _, function := range s.service.Functions {
 		param := gcg.QualifiedIdent(gcg.NewPackage("github.com/aacfactory/fns/services"), "Empty")
 		if function.Param != nil {
-			if s.service.Path == function.Param.Type.Path {
-				param = gcg.Ident(function.Param.Type.Name)
-			} else {
-				pkg, hasPKG := s.service.Imports.Path(function.Param.Type.Path)
-				if !hasPKG {
-					err = errors.Warning("modules: make function handle function code failed").
-						WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
-						WithMeta("function", function.Name()).
-						WithCause(errors.Warning("import of param was not found").WithMeta("path", function.Param.Type.Path))
-					return
-				}
-				if pkg.Alias == "" {
-					param = gcg.QualifiedIdent(gcg.NewPackage(pkg.Path), function.Param.Type.Name)
-				} else {
-					param = gcg.QualifiedIdent(gcg.NewPackageWithAlias(pkg.Path, pkg.Alias), function.Param.Type.Name)
-				}
+			param, err = s.fieldTypeCode(function.Param.Type)
+			if err != nil {
+				err = errors.Warning("modules: make function handle function code failed").
+					WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+					WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+					WithCause(err)
+				return
 			}
 		}
 		result := gcg.QualifiedIdent(gcg.NewPackage("github.com/aacfactory/fns/services"), "Empty")
 		if function.Result != nil {
-			if s.service.Path == function.Result.Type.Path {
-				result = gcg.Ident(function.Result.Type.Name)
-			} else {
-				pkg, hasPKG := s.service.Imports.Path(function.Result.Type.Path)
-				if !hasPKG {
-					err = errors.Warning("modules: make function proxy code failed").
-						WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
-						WithMeta("function", function.Name()).
-						WithCause(errors.Warning("import of result was not found").WithMeta("path", function.Result.Type.Path))
-					return
-				}
-				if pkg.Alias == "" {
-					result = gcg.QualifiedIdent(gcg.NewPackage(pkg.Path), function.Result.Type.Name)
-				} else {
-					result = gcg.QualifiedIdent(gcg.NewPackageWithAlias(pkg.Path, pkg.Alias), function.Result.Type.Name)
-				}
+			result, err = s.fieldTypeCode(function.Result.Type)
+			if err != nil {
+				err = errors.Warning("modules: make function proxy code failed").
+					WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+					WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+					WithCause(err)
+				return
 			}
 		}
 		body.Tab().Token(fmt.Sprintf("// %s", function.Name())).Line()
@@ -392,11 +424,11 @@ func (s *ServiceFile) serviceConstructCode(ctx context.Context) (code gcg.Code,
 			}
 		}
 		for i, annotationWriter := range matchedAnnotationWriters {
-			annotationCode, annotationCodeErr := annotationWriter.HandleBefore(ctx, matchedAnnotations[i].Params, function.Param != nil, function.Result != nil)
+			annotationCode, annotationCodeErr := annotationWriter.HandleBefore(withFunction(ctx, function), matchedAnnotations[i].Params, function.Param != nil, function.Result != nil)
 			if annotationCodeErr != nil {
 				err = errors.Warning("modules: make function proxy code failed").
 					WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
-					WithMeta("function", function.Name()).
+					WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
 					WithCause(annotationCodeErr).WithMeta("annotation", annotationWriter.Annotation())
 				return
 			}
@@ -422,11 +454,11 @@ func (s *ServiceFile) serviceConstructCode(ctx context.Context) (code gcg.Code,
 		body.Tab().Token("}").Line()
 		// annotation writers after
 		for i, annotationWriter := range matchedAnnotationWriters {
-			annotationCode, annotationCodeErr := annotationWriter.HandleAfter(ctx, matchedAnnotations[i].Params, function.Param != nil, function.Result != nil)
+			annotationCode, annotationCodeErr := annotationWriter.HandleAfter(withFunction(ctx, function), matchedAnnotations[i].Params, function.Param != nil, function.Result != nil)
 			if annotationCodeErr != nil {
 				err = errors.Warning("modules: make function handler code failed").
 					WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
-					WithMeta("function", function.Name()).
+					WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
 					WithCause(annotationCodeErr).WithMeta("annotation", annotationWriter.Annotation())
 				return
 			}
@@ -440,6 +472,9 @@ func (s *ServiceFile) serviceConstructCode(ctx context.Context) (code gcg.Code,
 		if function.Readonly() {
 			body.Token("commons.Readonly(),").Line()
 		}
+		if function.Stream() {
+			body.Token("commons.Stream(),").Line()
+		}
 		if function.Internal() {
 			body.Token("commons.Internal(),").Line()
 		}
@@ -468,13 +503,95 @@ func (s *ServiceFile) serviceConstructCode(ctx context.Context) (code gcg.Code,
 		if ccErr != nil {
 			err = errors.Warning("modules: make function handler code failed").
 				WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
-				WithMeta("function", function.Name()).
+				WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
 				WithCause(ccErr).WithMeta("annotation", "@cache-control")
 			return
 		}
 		if hasCC {
 			body.Token(fmt.Sprintf("commons.CacheControl(%d, %v, %v, %v),", maxAge, public, mustRevalidate, proxyRevalidate)).Line()
 		}
+		httpMethod, httpPattern, hasHTTP, httpErr := function.HTTP()
+		if httpErr != nil {
+			err = errors.Warning("modules: make function handler code failed").
+				WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+				WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+				WithCause(httpErr).WithMeta("annotation", "@http")
+			return
+		}
+		if hasHTTP {
+			body.Token(fmt.Sprintf("commons.Route(\"%s\", \"%s\"),", httpMethod, httpPattern)).Line()
+		}
+		headers, headersErr := function.Headers()
+		if headersErr != nil {
+			err = errors.Warning("modules: make function handler code failed").
+				WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+				WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+				WithCause(headersErr).WithMeta("annotation", "@header")
+			return
+		}
+		for _, header := range headers {
+			body.Token(fmt.Sprintf("commons.Header(%q, %q),", header[0], header[1])).Line()
+		}
+		if spec, hasCron := function.Cron(); hasCron {
+			body.Token(fmt.Sprintf("commons.Cron(%q),", spec)).Line()
+		}
+		if feature, hasFeature := function.Feature(); hasFeature {
+			body.Token(fmt.Sprintf("commons.Feature(%q),", feature)).Line()
+		}
+		if priority, hasPriority, validPriority := function.Priority(); hasPriority {
+			if !validPriority {
+				err = errors.Warning("modules: make function handler code failed").
+					WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+					WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+					WithCause(errors.Warning("priority must be low, normal or high")).WithMeta("annotation", "@priority")
+				return
+			}
+			body.Token(fmt.Sprintf("commons.Priority(%q),", priority)).Line()
+		}
+		auditAction, auditResource, hasAudit, auditErr := function.Audit()
+		if auditErr != nil {
+			err = errors.Warning("modules: make function handler code failed").
+				WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+				WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+				WithCause(auditErr).WithMeta("annotation", "@audit")
+			return
+		}
+		if hasAudit {
+			body.Token(fmt.Sprintf("commons.Audit(%q, %q),", auditAction, auditResource)).Line()
+		}
+		sla, hasSLA, slaErr := function.SLA()
+		if slaErr != nil {
+			err = errors.Warning("modules: make function handler code failed").
+				WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+				WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+				WithCause(slaErr).WithMeta("annotation", "@sla")
+			return
+		}
+		if hasSLA {
+			body.Token(fmt.Sprintf("commons.SLA(%q),", sla)).Line()
+		}
+		since, hasSince, sinceErr := function.Since()
+		if sinceErr != nil {
+			err = errors.Warning("modules: make function handler code failed").
+				WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+				WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+				WithCause(sinceErr).WithMeta("annotation", "@since")
+			return
+		}
+		if hasSince {
+			body.Token(fmt.Sprintf("commons.Since(%q),", since)).Line()
+		}
+		removed, hasRemoved, removedErr := function.Removed()
+		if removedErr != nil {
+			err = errors.Warning("modules: make function handler code failed").
+				WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+				WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+				WithCause(removedErr).WithMeta("annotation", "@removed")
+			return
+		}
+		if hasRemoved {
+			body.Token(fmt.Sprintf("commons.Removed(%q),", removed)).Line()
+		}
 		body.Token("))").Line()
 	}
 	body.Tab().Return()
@@ -510,6 +627,12 @@ func (s *ServiceFile) serviceDocumentCode(ctx context.Context) (code gcg.Code, e
 		fnCode.Tab().Tab().
 			Token(fmt.Sprintf("SetAuthorization(%v)", function.Authorization())).Dot().
 			Token(fmt.Sprintf("SetPermission(%v)", function.Permission())).Dot().Line()
+		if since, hasSince, _ := function.Since(); hasSince {
+			fnCode.Tab().Tab().Token(fmt.Sprintf("SetSince(%q)", since)).Dot().Line()
+		}
+		if removed, hasRemoved, _ := function.Removed(); hasRemoved {
+			fnCode.Tab().Tab().Token(fmt.Sprintf("SetRemoved(%q)", removed)).Dot().Line()
+		}
 		if function.Param == nil {
 			fnCode.Tab().Tab().Token("SetParam(documents.Nil())").Dot().Line()
 		} else {
@@ -517,7 +640,7 @@ func (s *ServiceFile) serviceDocumentCode(ctx context.Context) (code gcg.Code, e
 			if paramCodeErr != nil {
 				err = errors.Warning("modules: make service document code failed").
 					WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
-					WithMeta("function", function.Name()).
+					WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
 					WithCause(paramCodeErr)
 				return
 			}
@@ -530,7 +653,7 @@ func (s *ServiceFile) serviceDocumentCode(ctx context.Context) (code gcg.Code, e
 			if resultCodeErr != nil {
 				err = errors.Warning("modules: make service document code failed").
 					WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
-					WithMeta("function", function.Name()).
+					WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
 					WithCause(resultCodeErr)
 				return
 			}
@@ -585,11 +708,53 @@ func (s *ServiceFile) functionProxiesCode(ctx context.Context) (code gcg.Code, e
 			return
 		}
 		stmt.Add(proxyAsync).Line()
+		// cache evict
+		if cacheCmd, _, hasCache := function.Cache(); hasCache && function.Param != nil && (cacheCmd == "get" || cacheCmd == "get-set") {
+			evict, evictErr := s.functionCacheEvictCode(ctx, function)
+			if evictErr != nil {
+				err = evictErr
+				return
+			}
+			stmt.Add(evict).Line()
+		}
 	}
 	code = stmt
 	return
 }
 
+// functionCacheEvictCode
+// makes Evict{Proxy}Cache for fn with @cache get or get-set, so write fns evict value cached by the fn with the same key.
+func (s *ServiceFile) functionCacheEvictCode(_ context.Context, function *Function) (code gcg.Code, err error) {
+	evict := gcg.Func()
+	evict.Name(fmt.Sprintf("Evict%sCache", function.ProxyIdent))
+	evict.AddParam("ctx", contextCode())
+	param, paramErr := s.fieldTypeCode(function.Param.Type)
+	if paramErr != nil {
+		err = errors.Warning("modules: make function cache evict code failed").
+			WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+			WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+			WithCause(paramErr)
+		return
+	}
+	evict.AddParam("param", param)
+	evict.AddResult("err", gcg.Ident("error"))
+	body := gcg.Statements()
+	if _, isList := function.CachedList(); isList {
+		body.Tab().Token("for _, item := range param {").Line()
+		body.Tab().Tab().Token("if err = caches.Evict(ctx, item); err != nil {", gcg.NewPackage("github.com/aacfactory/fns/services/caches")).Line()
+		body.Tab().Tab().Tab().Token("return").Line()
+		body.Tab().Tab().Token("}").Line()
+		body.Tab().Token("}").Line()
+		body.Tab().Token("return")
+	} else {
+		body.Tab().Token("err = caches.Evict(ctx, param)", gcg.NewPackage("github.com/aacfactory/fns/services/caches")).Line()
+		body.Tab().Token("return")
+	}
+	evict.Body(body)
+	code = evict.Build()
+	return
+}
+
 func (s *ServiceFile) functionProxyAsyncCode(ctx context.Context, function *Function) (code gcg.Code, err error) {
 	proxyIdent := function.ProxyAsyncIdent
 	proxy := gcg.Func()
@@ -597,49 +762,36 @@ func (s *ServiceFile) functionProxyAsyncCode(ctx context.Context, function *Func
 	proxy.AddParam("ctx", contextCode())
 	if function.Param != nil {
 		var param gcg.Code = nil
-		if s.service.Path == function.Param.Type.Path {
-			param = gcg.Ident(function.Param.Type.Name)
-		} else {
-			pkg, hasPKG := s.service.Imports.Path(function.Param.Type.Path)
-			if !hasPKG {
-				err = errors.Warning("modules: make function proxy code failed").
-					WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
-					WithMeta("function", function.Name()).
-					WithCause(errors.Warning("import of param was not found").WithMeta("path", function.Param.Type.Path))
-				return
-			}
-			if pkg.Alias == "" {
-				param = gcg.QualifiedIdent(gcg.NewPackage(pkg.Path), function.Param.Type.Name)
-			} else {
-				param = gcg.QualifiedIdent(gcg.NewPackageWithAlias(pkg.Path, pkg.Alias), function.Param.Type.Name)
-			}
+		param, err = s.fieldTypeCode(function.Param.Type)
+		if err != nil {
+			err = errors.Warning("modules: make function proxy code failed").
+				WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+				WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+				WithCause(err)
+			return
 		}
 		proxy.AddParam("param", param)
 	}
 	var result gcg.Code = nil
 	if function.Result != nil {
-		if s.service.Path == function.Result.Type.Path {
-			result = gcg.Ident(function.Result.Type.Name)
-		} else {
-			pkg, hasPKG := s.service.Imports.Path(function.Result.Type.Path)
-			if !hasPKG {
-				err = errors.Warning("modules: make function proxy code failed").
-					WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
-					WithMeta("function", function.Name()).
-					WithCause(errors.Warning("import of result was not found").WithMeta("path", function.Result.Type.Path))
-				return
-			}
-			if pkg.Alias == "" {
-				result = gcg.QualifiedIdent(gcg.NewPackage(pkg.Path), function.Result.Type.Name)
-			} else {
-				result = gcg.QualifiedIdent(gcg.NewPackageWithAlias(pkg.Path, pkg.Alias), function.Result.Type.Name)
-			}
+		result, err = s.fieldTypeCode(function.Result.Type)
+		if err != nil {
+			err = errors.Warning("modules: make function proxy code failed").
+				WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+				WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+				WithCause(err)
+			return
 		}
 	}
 	proxy.AddResult("future", gcg.QualifiedIdent(gcg.NewPackage("github.com/aacfactory/fns/commons/futures"), "Future"))
 	proxy.AddResult("err", gcg.Ident("error"))
 	// body >>>
 	body := gcg.Statements()
+	// context
+	body.Tab().Token("// check context").Line()
+	body.Tab().Token("if err = services.ContextErr(ctx); err != nil {").Line()
+	body.Tab().Tab().Token("return").Line()
+	body.Tab().Token("}").Line()
 	if function.Param != nil {
 		// validate
 		if validTitle, valid := function.Validation(); valid {
@@ -657,7 +809,8 @@ func (s *ServiceFile) functionProxyAsyncCode(ctx context.Context, function *Func
 		// cache
 		cacheCmd, _, hasCache := function.Cache()
 		if hasCache && function.Result != nil {
-			if cacheCmd == "get" || cacheCmd == "get-set" {
+			// items of cached list are loaded by fn in one round trip, see commons.Cache, so proxy does not load them
+			if _, isList := function.CachedList(); !isList && (cacheCmd == "get" || cacheCmd == "get-set") {
 				body.Tab().Token("// cache get").Line()
 				body.Tab().Tab().Token("cached, cacheExist, cacheGetErr := caches.Load[").Add(result).Token("](ctx, param)").Line()
 				body.Tab().Token("if cacheGetErr != nil {").Line()
@@ -699,49 +852,36 @@ func (s *ServiceFile) functionProxyCode(ctx context.Context, function *Function)
 	proxy.AddParam("ctx", contextCode())
 	if function.Param != nil {
 		var param gcg.Code = nil
-		if s.service.Path == function.Param.Type.Path {
-			param = gcg.Ident(function.Param.Type.Name)
-		} else {
-			pkg, hasPKG := s.service.Imports.Path(function.Param.Type.Path)
-			if !hasPKG {
-				err = errors.Warning("modules: make function proxy code failed").
-					WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
-					WithMeta("function", function.Name()).
-					WithCause(errors.Warning("import of param was not found").WithMeta("path", function.Param.Type.Path))
-				return
-			}
-			if pkg.Alias == "" {
-				param = gcg.QualifiedIdent(gcg.NewPackage(pkg.Path), function.Param.Type.Name)
-			} else {
-				param = gcg.QualifiedIdent(gcg.NewPackageWithAlias(pkg.Path, pkg.Alias), function.Param.Type.Name)
-			}
+		param, err = s.fieldTypeCode(function.Param.Type)
+		if err != nil {
+			err = errors.Warning("modules: make function proxy code failed").
+				WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+				WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+				WithCause(err)
+			return
 		}
 		proxy.AddParam("param", param)
 	}
 	var result gcg.Code = nil
 	if function.Result != nil {
-		if s.service.Path == function.Result.Type.Path {
-			result = gcg.Ident(function.Result.Type.Name)
-		} else {
-			pkg, hasPKG := s.service.Imports.Path(function.Result.Type.Path)
-			if !hasPKG {
-				err = errors.Warning("modules: make function proxy code failed").
-					WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
-					WithMeta("function", function.Name()).
-					WithCause(errors.Warning("import of result was not found").WithMeta("path", function.Result.Type.Path))
-				return
-			}
-			if pkg.Alias == "" {
-				result = gcg.QualifiedIdent(gcg.NewPackage(pkg.Path), function.Result.Type.Name)
-			} else {
-				result = gcg.QualifiedIdent(gcg.NewPackageWithAlias(pkg.Path, pkg.Alias), function.Result.Type.Name)
-			}
+		result, err = s.fieldTypeCode(function.Result.Type)
+		if err != nil {
+			err = errors.Warning("modules: make function proxy code failed").
+				WithMeta("kind", "service").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+				WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+				WithCause(err)
+			return
 		}
 		proxy.AddResult("result", result)
 	}
 	proxy.AddResult("err", gcg.Ident("error"))
 	// body >>>
 	body := gcg.Statements()
+	// context
+	body.Tab().Token("// check context").Line()
+	body.Tab().Token("if err = services.ContextErr(ctx); err != nil {").Line()
+	body.Tab().Tab().Token("return").Line()
+	body.Tab().Token("}").Line()
 	if function.Param != nil {
 		// validate
 		if validTitle, valid := function.Validation(); valid {
@@ -759,7 +899,8 @@ func (s *ServiceFile) functionProxyCode(ctx context.Context, function *Function)
 		// cache
 		cacheCmd, _, hasCache := function.Cache()
 		if hasCache && function.Result != nil {
-			if cacheCmd == "get" || cacheCmd == "get-set" {
+			// items of cached list are loaded by fn in one round trip, see commons.Cache, so proxy does not load them
+			if _, isList := function.CachedList(); !isList && (cacheCmd == "get" || cacheCmd == "get-set") {
 				body.Tab().Token("// cache get").Line()
 				body.Tab().Tab().Token("cached, cacheExist, cacheGetErr := caches.Load[").Add(result).Token("](ctx, param)").Line()
 				body.Tab().Token("if cacheGetErr != nil {").Line()
@@ -808,3 +949,73 @@ func (s *ServiceFile) functionProxyCode(ctx context.Context, function *Function)
 	code = proxy.Build()
 	return
 }
+
+func (s *ServiceFile) fieldTypeCode(typ *sources.Type) (code *gcg.Statement, err error) {
+	switch typ.Kind {
+	case sources.PointerKind:
+		element, elementErr := s.fieldTypeCode(typ.Elements[0])
+		if elementErr != nil {
+			err = elementErr
+			return
+		}
+		code = gcg.Statements().Star().Add(element)
+		return
+	case sources.ArrayKind:
+		if typ.Path == "" {
+			element, elementErr := s.fieldTypeCode(typ.Elements[0])
+			if elementErr != nil {
+				err = elementErr
+				return
+			}
+			code = gcg.Statements().Symbol("[]").Add(element)
+			return
+		}
+		break
+	case sources.ParadigmKind:
+		code, err = s.fieldTypeCode(typ.Elements[0])
+		if err != nil {
+			return
+		}
+		code = code.Symbol("[")
+		for i, paradigm := range typ.Paradigms {
+			if i > 0 {
+				code = code.Symbol(", ")
+			}
+			paradigmCode, paradigmErr := s.fieldTypeCode(paradigm.Types[0])
+			if paradigmErr != nil {
+				err = paradigmErr
+				return
+			}
+			code = code.Add(paradigmCode)
+		}
+		code = code.Symbol("]")
+		return
+	default:
+		break
+	}
+	if typ.Path == "" {
+		if name, basic := typ.Basic(); basic {
+			code = gcg.Ident(name)
+			return
+		}
+	}
+	if s.service.Path == typ.Path {
+		code = gcg.Ident(typ.Name)
+		return
+	}
+	pkg, hasPKG := s.service.Imports.Path(typ.Path)
+	if !hasPKG {
+		notFound := errors.Warning("import of type was not found").WithMeta("path", typ.Path).WithMeta("name", typ.Name)
+		if typ.Position.IsValid() {
+			notFound = notFound.WithMeta("position", typ.Position.String())
+		}
+		err = notFound
+		return
+	}
+	if pkg.Alias == "" {
+		code = gcg.QualifiedIdent(gcg.NewPackage(pkg.Path), typ.Name)
+	} else {
+		code = gcg.QualifiedIdent(gcg.NewPackageWithAlias(pkg.Path, pkg.Alias), typ.Name)
+	}
+	return
+}
@@ -17,8 +17,22 @@
 
 package modules
 
+import "strings"
+
 type Component struct {
 	Indent string
+	// Lazy
+	// component is constructed at its first load, it is marked by @lazy.
+	Lazy bool
+}
+
+// AccessorName
+// is the name of generated typed accessor, such as CacheComponent for Cache.
+func (component *Component) AccessorName() string {
+	if component.Indent != "Component" && strings.HasSuffix(component.Indent, "Component") {
+		return component.Indent
+	}
+	return component.Indent + "Component"
 }
 
 type Components []*Component
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package modules_test
+
+import (
+	"context"
+	"github.com/aacfactory/fns/cmd/generates/modules"
+	"github.com/aacfactory/fns/cmd/generates/sources"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComponentAccessors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) {
+		filename := filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(filename), 0755)
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/accessors\n\ngo 1.22\n")
+	write("modules/users/doc.go", "// Package users\n// @service users\npackage users\n")
+	write("modules/users/components/components.go", `package components
+
+// RedisCache
+// @component
+type RedisCache struct{}
+
+// StoreComponent
+// @component
+// @lazy
+type StoreComponent struct{}
+`)
+	mod, modErr := sources.New(filepath.Join(dir, "go.mod"))
+	if modErr != nil {
+		t.Fatal(modErr)
+	}
+	if err := mod.Parse(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	services, loadErr := modules.Load(mod, modules.DefaultDir)
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+	file := modules.NewServiceFile(services[0], nil)
+	if err := file.Write(context.TODO()); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	p, readErr := os.ReadFile(file.Name())
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	code := string(p)
+	for _, expect := range []string{
+		"func RedisCacheComponent(ctx context.Context) (component *components.RedisCache, has bool)",
+		"services.LoadComponentOf[*components.RedisCache](ctx, _endpointName)",
+		"func StoreComponent(ctx context.Context) (component *components.StoreComponent, has bool)",
+		"services.Lazy(&components.StoreComponent{})",
+	} {
+		if !strings.Contains(code, expect) {
+			t.Errorf("%s is not generated", expect)
+		}
+	}
+}
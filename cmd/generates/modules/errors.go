@@ -0,0 +1,187 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package modules
+
+import (
+	"context"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/cmd/generates/sources"
+	"github.com/aacfactory/fns/services/documents"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+)
+
+const (
+	errorsPath = "github.com/aacfactory/errors"
+)
+
+// DeclaredErrors
+// returns names of errors in @errors of fn.
+func (f *Function) DeclaredErrors() (names []string) {
+	for _, e := range documents.NewErrors(f.Errors()) {
+		names = append(names, e.Name)
+	}
+	return
+}
+
+// ReturnedErrors
+// returns names of errors which are made in body of fn by errors.New or errors.NewWithDepth with literal names,
+// package level vars in the same file which are made by them are followed too.
+// it is best-effort, errors made by other functions are not found.
+func (f *Function) ReturnedErrors() (names []string) {
+	if f.decl == nil || f.decl.Body == nil {
+		return
+	}
+	found := make(map[string]struct{})
+	visited := make(map[*ast.Object]struct{})
+	var inspect func(node ast.Node) bool
+	inspect = func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.CallExpr:
+			if name, ok := f.errorNameOfCall(n); ok {
+				found[name] = struct{}{}
+			}
+			break
+		case *ast.Ident:
+			if n.Obj == nil || n.Obj.Kind != ast.Var {
+				break
+			}
+			if _, has := visited[n.Obj]; has {
+				break
+			}
+			visited[n.Obj] = struct{}{}
+			if spec, isValue := n.Obj.Decl.(*ast.ValueSpec); isValue {
+				for _, value := range spec.Values {
+					ast.Inspect(value, inspect)
+				}
+			}
+			break
+		}
+		return true
+	}
+	ast.Inspect(f.decl.Body, inspect)
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+func (f *Function) errorNameOfCall(call *ast.CallExpr) (name string, ok bool) {
+	selector, isSelector := call.Fun.(*ast.SelectorExpr)
+	if !isSelector {
+		return
+	}
+	if selector.Sel.Name != "New" && selector.Sel.Name != "NewWithDepth" {
+		return
+	}
+	pkg, isIdent := selector.X.(*ast.Ident)
+	if !isIdent {
+		return
+	}
+	imported, has := f.imports.Find(pkg.Name)
+	if !has || imported.Path != errorsPath {
+		return
+	}
+	if len(call.Args) < 2 {
+		return
+	}
+	lit, isLit := call.Args[1].(*ast.BasicLit)
+	if !isLit || lit.Kind != token.STRING {
+		return
+	}
+	value, unquoteErr := strconv.Unquote(lit.Value)
+	if unquoteErr != nil || value == "" {
+		return
+	}
+	name, ok = value, true
+	return
+}
+
+// LintErrors
+// checks @errors of fns against errors made in their bodies, see Function.ReturnedErrors.
+// a made error which is not declared and a declared error which is never made are reported,
+// the later only when fn makes any named error, because errors of fns which call others can not be found.
+func LintErrors(ctx context.Context, mod *sources.Module, dir string) (diagnostics []Diagnostic, err error) {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	services, loadErr := Load(mod, dir)
+	if loadErr != nil {
+		err = errors.Warning("modules: lint errors failed").WithCause(loadErr)
+		return
+	}
+	for _, service := range services {
+		for _, function := range service.Functions {
+			if parseErr := function.Parse(ctx); parseErr != nil {
+				// reported by Lint
+				continue
+			}
+			diagnostics = append(diagnostics, lintFunctionErrors(function)...)
+		}
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].File == diagnostics[j].File {
+			return diagnostics[i].Line < diagnostics[j].Line
+		}
+		return diagnostics[i].File < diagnostics[j].File
+	})
+	return
+}
+
+func lintFunctionErrors(function *Function) (diagnostics []Diagnostic) {
+	declared := make(map[string]bool)
+	for _, name := range function.DeclaredErrors() {
+		declared[name] = false
+	}
+	returned := function.ReturnedErrors()
+	report := func(line int, message string) {
+		diagnostics = append(diagnostics, Diagnostic{
+			File:       function.filename,
+			Line:       line,
+			Service:    function.hostServiceName,
+			Fn:         function.Ident,
+			Annotation: "errors",
+			Message:    message,
+		})
+	}
+	line := annotationLine(function, "errors", function.Position().Line)
+	for _, name := range returned {
+		if _, has := declared[name]; has {
+			declared[name] = true
+			continue
+		}
+		report(line, name+" is returned but not declared")
+	}
+	if len(returned) == 0 {
+		return
+	}
+	names := make([]string, 0, len(declared))
+	for name, used := range declared {
+		if !used {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		report(line, name+" is declared but never returned")
+	}
+	return
+}
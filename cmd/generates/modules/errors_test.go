@@ -0,0 +1,99 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package modules_test
+
+import (
+	"context"
+	"github.com/aacfactory/fns/cmd/generates/modules"
+	"github.com/aacfactory/fns/cmd/generates/sources"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintErrors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) {
+		filename := filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(filename), 0755)
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/errs\n\ngo 1.22\n")
+	write("modules/users/doc.go", "// Package users\n// @service users\npackage users\n")
+	write("modules/users/fns.go", `package users
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+)
+
+var ErrUserExists = errors.New(409, "user_exists", "user exists")
+
+type Param struct {
+	Id string `+"`json:\"id\"`"+`
+}
+
+// add
+// @fn add
+// @errors >>>
+// user_exists
+// zh: exists
+// user_banned
+// zh: banned
+// <<<
+func add(ctx context.Context, param Param) (err error) {
+	if param.Id == "" {
+		err = errors.New(400, "user_invalid", "invalid")
+		return
+	}
+	err = ErrUserExists
+	return
+}
+
+// get
+// @fn get
+// @errors >>>
+// user_not_found
+// zh: not found
+// <<<
+func get(ctx context.Context, param Param) (err error) {
+	err = errors.Warning("failed")
+	return
+}
+`)
+	mod, modErr := sources.New(filepath.Join(dir, "go.mod"))
+	if modErr != nil {
+		t.Fatal(modErr)
+	}
+	if err := mod.Parse(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	diagnostics, err := modules.LintErrors(context.TODO(), mod, modules.DefaultDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := make(map[string]bool)
+	for _, diagnostic := range diagnostics {
+		messages[diagnostic.Fn+": "+diagnostic.Message] = true
+	}
+	if len(diagnostics) != 2 || !messages["add: user_invalid is returned but not declared"] || !messages["add: user_banned is declared but never returned"] {
+		t.Fatal("unexpected diagnostics", diagnostics)
+	}
+}
@@ -22,10 +22,14 @@ import (
 	"fmt"
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/cmd/generates/sources"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/services"
 	"go/ast"
+	"go/token"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type FunctionField struct {
@@ -72,6 +76,13 @@ func (f *Function) HostServiceName() (name string) {
 	return
 }
 
+// Position
+// returns position of func declaration in source.
+func (f *Function) Position() (position token.Position) {
+	position = sources.Position(f.filename, f.decl.Pos())
+	return
+}
+
 func (f *Function) Name() (name string) {
 	name, _ = f.Annotations.Value("fn")
 	return
@@ -82,6 +93,11 @@ func (f *Function) Readonly() (ok bool) {
 	return
 }
 
+func (f *Function) Stream() (ok bool) {
+	_, ok = f.Annotations.Get("stream")
+	return
+}
+
 func (f *Function) Internal() (ok bool) {
 	_, ok = f.Annotations.Get("internal")
 	return
@@ -214,6 +230,221 @@ func (f *Function) Cache() (cmd string, ttl string, has bool) {
 	return
 }
 
+// ValidateCache
+// Cache falls back to defaults for invalid values, this reports them instead.
+func (f *Function) ValidateCache() (err error) {
+	anno, exist := f.Annotations.Get("cache")
+	if !exist {
+		return
+	}
+	if len(anno.Params) == 0 {
+		err = errors.Warning("fns: parse @cache failed").WithCause(fmt.Errorf("it must be @cache {get|set|remove|get-set} [ttl seconds]"))
+		return
+	}
+	cmd := strings.TrimSpace(anno.Params[0])
+	switch cmd {
+	case "get", "remove":
+		if len(anno.Params) > 1 {
+			err = errors.Warning("fns: parse @cache failed").WithCause(fmt.Errorf("%s has no ttl", cmd))
+			return
+		}
+		break
+	case "set", "get-set":
+		if len(anno.Params) > 1 {
+			sec, ttlErr := strconv.Atoi(anno.Params[1])
+			if ttlErr != nil || sec < 1 {
+				err = errors.Warning("fns: parse @cache failed").WithCause(fmt.Errorf("ttl must be positive seconds")).WithMeta("ttl", anno.Params[1])
+				return
+			}
+		}
+		break
+	default:
+		err = errors.Warning("fns: parse @cache failed").WithCause(fmt.Errorf("command must be get, set, remove or get-set")).WithMeta("command", cmd)
+		return
+	}
+	return
+}
+
+// CachedList
+// returns element type of result when param and result are both lists,
+// then items of result are cached one by one, keyed by items of param.
+func (f *Function) CachedList() (element *sources.Type, ok bool) {
+	if f.Param == nil || f.Result == nil {
+		return
+	}
+	if f.Param.Type.Kind != sources.ArrayKind || f.Result.Type.Kind != sources.ArrayKind {
+		return
+	}
+	element, ok = f.Result.Type.Elements[0], true
+	return
+}
+
+func (f *Function) HTTP() (method string, pattern string, has bool, err error) {
+	anno, exist := f.Annotations.Get("http")
+	if !exist {
+		return
+	}
+	if len(anno.Params) != 2 {
+		err = errors.Warning("fns: parse @http failed").WithCause(fmt.Errorf("it must be @http {GET|POST} {pattern}"))
+		return
+	}
+	// validated by services.NewFnRoute, which is called by generated code at runtime too
+	route, routeErr := services.NewFnRoute(anno.Params[0], anno.Params[1])
+	if routeErr != nil {
+		err = errors.Warning("fns: parse @http failed").WithCause(routeErr)
+		return
+	}
+	method, pattern = route.Method, route.Pattern
+	has = true
+	return
+}
+
+func (f *Function) Cron() (spec string, has bool) {
+	spec, has = f.Annotations.Value("cron")
+	if has {
+		spec = strings.TrimSpace(spec)
+		has = spec != ""
+	}
+	return
+}
+
+// Priority
+// returns lane of @priority, it is one of low, normal and high, ok is false when value is not one of them.
+func (f *Function) Priority() (priority string, has bool, ok bool) {
+	priority, has = f.Annotations.FirstParam("priority")
+	if !has {
+		return
+	}
+	priority = strings.ToLower(strings.TrimSpace(priority))
+	ok = priority == "low" || priority == "normal" || priority == "high"
+	return
+}
+
+func (f *Function) Feature() (name string, has bool) {
+	name, has = f.Annotations.FirstParam("feature")
+	if has {
+		name = strings.TrimSpace(name)
+		has = name != ""
+	}
+	return
+}
+
+func (f *Function) Audit() (action string, resource string, has bool, err error) {
+	annotation, exist := f.Annotations.Get("audit")
+	if !exist {
+		return
+	}
+	for _, param := range annotation.Params {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok {
+			err = errors.Warning("fns: parse @audit failed").WithCause(fmt.Errorf("param must be key=value")).WithMeta("param", param)
+			return
+		}
+		switch strings.TrimSpace(key) {
+		case "action":
+			action = strings.TrimSpace(value)
+			break
+		case "resource":
+			resource = strings.TrimSpace(value)
+			break
+		default:
+			err = errors.Warning("fns: parse @audit failed").WithCause(fmt.Errorf("key must be action or resource")).WithMeta("param", param)
+			return
+		}
+	}
+	if action == "" {
+		err = errors.Warning("fns: parse @audit failed").WithCause(fmt.Errorf("action is required"))
+		return
+	}
+	if resource != "" && f.Param == nil {
+		err = errors.Warning("fns: parse @audit failed").WithCause(fmt.Errorf("resource is set but fn has no param")).WithMeta("resource", resource)
+		return
+	}
+	has = true
+	return
+}
+
+func (f *Function) SLA() (budget string, has bool, err error) {
+	budget, has = f.Annotations.FirstParam("sla")
+	if !has {
+		return
+	}
+	d, parseErr := time.ParseDuration(budget)
+	if parseErr != nil {
+		err = errors.Warning("fns: parse @sla failed").WithCause(parseErr).WithMeta("sla", budget)
+		return
+	}
+	if d <= 0 {
+		err = errors.Warning("fns: parse @sla failed").WithCause(fmt.Errorf("budget must be positive")).WithMeta("sla", budget)
+		return
+	}
+	return
+}
+
+func (f *Function) Since() (version string, has bool, err error) {
+	version, has = f.Annotations.FirstParam("since")
+	if !has {
+		return
+	}
+	_, err = versions.Parse([]byte(version))
+	if err != nil {
+		err = errors.Warning("fns: parse @since failed").WithCause(err).WithMeta("version", version)
+		return
+	}
+	return
+}
+
+func (f *Function) Removed() (version string, has bool, err error) {
+	version, has = f.Annotations.FirstParam("removed")
+	if !has {
+		return
+	}
+	_, err = versions.Parse([]byte(version))
+	if err != nil {
+		err = errors.Warning("fns: parse @removed failed").WithCause(err).WithMeta("version", version)
+		return
+	}
+	return
+}
+
+// Headers
+// @header {name}: {value}, it is repeatable.
+func (f *Function) Headers() (headers [][2]string, err error) {
+	anno, exist := f.Annotations.Get("header")
+	if !exist {
+		return
+	}
+	for _, param := range anno.Params {
+		name, value, found := strings.Cut(param, ":")
+		if !found {
+			err = errors.Warning("fns: parse @header failed").WithCause(fmt.Errorf("it must be @header {name}: {value}")).WithMeta("header", param)
+			return
+		}
+		name = strings.TrimSpace(name)
+		if !validHeaderName(name) {
+			err = errors.Warning("fns: parse @header failed").WithCause(fmt.Errorf("name is invalid")).WithMeta("header", param)
+			return
+		}
+		headers = append(headers, [2]string{name, strings.TrimSpace(value)})
+	}
+	return
+}
+
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+			continue
+		}
+		if !strings.ContainsRune("!#$%&'*+-.^_`|~", c) {
+			return false
+		}
+	}
+	return true
+}
+
 func (f *Function) CacheControl() (maxAge int, public bool, mustRevalidate bool, proxyRevalidate bool, has bool, err error) {
 	anno, exist := f.Annotations.Get("cache-control")
 	if !exist {
@@ -296,12 +527,12 @@ func (f *Function) FieldImports() (v sources.Imports) {
 func (f *Function) Parse(ctx context.Context) (err error) {
 	if ctx.Err() != nil {
 		err = errors.Warning("modules: parse function failed").WithCause(ctx.Err()).
-			WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("file", f.filename)
+			WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("position", f.Position().String())
 		return
 	}
 	if f.decl.Type.TypeParams != nil && f.decl.Type.TypeParams.List != nil && len(f.decl.Type.TypeParams.List) > 0 {
 		err = errors.Warning("modules: parse function failed").WithCause(errors.Warning("function can not use paradigm")).
-			WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("file", f.filename)
+			WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("position", f.Position().String())
 		return
 	}
 
@@ -309,20 +540,20 @@ func (f *Function) Parse(ctx context.Context) (err error) {
 	params := f.decl.Type.Params
 	if params == nil || params.List == nil || len(params.List) == 0 || len(params.List) > 2 {
 		err = errors.Warning("modules: parse function failed").WithCause(errors.Warning("params length must be one or two")).
-			WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("file", f.filename)
+			WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("position", f.Position().String())
 		return
 	}
 
 	if !f.mod.Types().IsContextType(params.List[0].Type, f.imports) {
 		err = errors.Warning("modules: parse function failed").WithCause(errors.Warning("first param must be context.Context")).
-			WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("file", f.filename)
+			WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("position", f.Position().String())
 		return
 	}
 	if len(params.List) == 2 {
 		param, parseParamErr := f.parseField(ctx, params.List[1])
 		if parseParamErr != nil {
 			err = errors.Warning("modules: parse function failed").WithCause(parseParamErr).
-				WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("file", f.filename)
+				WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("position", f.Position().String())
 			return
 		}
 		f.Param = param
@@ -331,25 +562,25 @@ func (f *Function) Parse(ctx context.Context) (err error) {
 	results := f.decl.Type.Results
 	if results == nil || results.List == nil || len(results.List) == 0 || len(results.List) > 2 {
 		err = errors.Warning("modules: parse function failed").WithCause(errors.Warning("results length must be one or two")).
-			WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("file", f.filename)
+			WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("position", f.Position().String())
 		return
 	}
 	if len(results.List) == 1 {
 		if !f.mod.Types().IsCodeErrorType(results.List[0].Type, f.imports) {
 			err = errors.Warning("modules: parse function failed").WithCause(errors.Warning("the last results must be error or github.com/aacfactory/errors.CodeError")).
-				WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("file", f.filename)
+				WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("position", f.Position().String())
 			return
 		}
 	} else {
 		if !f.mod.Types().IsCodeErrorType(results.List[1].Type, f.imports) {
 			err = errors.Warning("modules: parse function failed").WithCause(errors.Warning("the last results must be error or github.com/aacfactory/errors.CodeError")).
-				WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("file", f.filename)
+				WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("position", f.Position().String())
 			return
 		}
 		result, parseResultErr := f.parseField(ctx, results.List[0])
 		if parseResultErr != nil {
 			err = errors.Warning("modules: parse function failed").WithCause(parseResultErr).
-				WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("file", f.filename)
+				WithMeta("service", f.hostServiceName).WithMeta("function", f.Ident).WithMeta("position", f.Position().String())
 			return
 		}
 		f.Result = result
@@ -380,6 +611,7 @@ func (f *Function) parseFieldType(ctx context.Context, e ast.Expr) (typ *sources
 	switch e.(type) {
 	case *ast.Ident:
 		typ, err = f.mod.Types().ParseExpr(ctx, e, &sources.TypeScope{
+			Filename:   f.filename,
 			Path:       f.path,
 			Mod:        f.mod,
 			Imports:    f.imports,
@@ -397,6 +629,7 @@ func (f *Function) parseFieldType(ctx context.Context, e ast.Expr) (typ *sources
 		typ.Name = e.(*ast.Ident).Name
 	case *ast.SelectorExpr:
 		typ, err = f.mod.Types().ParseExpr(ctx, e, &sources.TypeScope{
+			Filename:   f.filename,
 			Path:       f.path,
 			Mod:        f.mod,
 			Imports:    f.imports,
@@ -411,8 +644,24 @@ func (f *Function) parseFieldType(ctx context.Context, e ast.Expr) (typ *sources
 			return
 		}
 		break
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		typ, err = f.mod.Types().ParseExpr(ctx, e, &sources.TypeScope{
+			Filename:   f.filename,
+			Path:       f.path,
+			Mod:        f.mod,
+			Imports:    f.imports,
+			GenericDoc: "",
+		})
+		if err != nil {
+			return
+		}
+		if typ.ParadigmsPacked == nil {
+			err = errors.Warning("modules: field type of paradigm must be instantiated")
+			return
+		}
+		break
 	default:
-		err = errors.Warning("modules: field type only support no paradigms value object or typed slice").WithMeta("expr", reflect.TypeOf(e).String())
+		err = errors.Warning("modules: field type only support value object or instantiated paradigm value object").WithMeta("expr", reflect.TypeOf(e).String())
 		return
 	}
 	return
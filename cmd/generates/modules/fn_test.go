@@ -0,0 +1,39 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package modules_test
+
+import (
+	"github.com/aacfactory/fns/cmd/generates/modules"
+	"github.com/aacfactory/fns/cmd/generates/sources"
+	"testing"
+)
+
+func TestFunction_HTTP(t *testing.T) {
+	fn := &modules.Function{Annotations: sources.Annotations{sources.NewAnnotation("http", "get", "/users/:id")}}
+	method, pattern, has, err := fn.HTTP()
+	if err != nil || !has || method != "GET" || pattern != "/users/:id" {
+		t.Fatal("unexpected route", method, pattern, has, err)
+	}
+	// validated as services.NewFnRoute
+	for _, params := range [][]string{{"PUT", "/users"}, {"GET", "users"}, {"GET"}} {
+		fn = &modules.Function{Annotations: sources.Annotations{sources.NewAnnotation("http", params...)}}
+		if _, _, _, err = fn.HTTP(); err == nil {
+			t.Fatal("invalid @http must fail", params)
+		}
+	}
+}
@@ -68,6 +68,7 @@ func (generator *Generator) Generate(ctx context.Context, mod *sources.Module) (
 			functionParseUnits = append(functionParseUnits, function)
 		}
 		serviceCodeFileUnits = append(serviceCodeFileUnits, Unit(NewServiceFile(service, generator.annotations)))
+		serviceCodeFileUnits = append(serviceCodeFileUnits, Unit(NewMockFile(service)))
 	}
 	process.Add("generates: parsing", functionParseUnits...)
 	process.Add("generates: writing", serviceCodeFileUnits...)
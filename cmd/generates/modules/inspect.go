@@ -0,0 +1,104 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package modules
+
+import (
+	"context"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/cmd/generates/sources"
+)
+
+type FunctionInfo struct {
+	Name        string              `json:"name"`
+	Ident       string              `json:"ident"`
+	File        string              `json:"file"`
+	Line        int                 `json:"line"`
+	Param       string              `json:"param,omitempty"`
+	Result      string              `json:"result,omitempty"`
+	Annotations map[string][]string `json:"annotations"`
+}
+
+func (info FunctionInfo) Has(annotation string) (ok bool) {
+	_, ok = info.Annotations[annotation]
+	return
+}
+
+type ServiceInfo struct {
+	Name        string         `json:"name"`
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Internal    bool           `json:"internal"`
+	Dir         string         `json:"dir"`
+	Functions   []FunctionInfo `json:"functions"`
+	Components  []string       `json:"components,omitempty"`
+}
+
+// Inspect
+// loads and parses services in dir of mod as Generator does, but writes nothing.
+func Inspect(ctx context.Context, mod *sources.Module, dir string) (infos []ServiceInfo, err error) {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	services, loadErr := Load(mod, dir)
+	if loadErr != nil {
+		err = errors.Warning("modules: inspect failed").WithCause(loadErr)
+		return
+	}
+	infos = make([]ServiceInfo, 0, len(services))
+	for _, service := range services {
+		info := ServiceInfo{
+			Name:        service.Name,
+			Title:       service.Title,
+			Description: service.Description,
+			Internal:    service.Internal,
+			Dir:         service.Dir,
+			Functions:   make([]FunctionInfo, 0, len(service.Functions)),
+		}
+		for _, component := range service.Components {
+			info.Components = append(info.Components, component.Indent)
+		}
+		for _, function := range service.Functions {
+			if err = function.Parse(ctx); err != nil {
+				err = errors.Warning("modules: inspect failed").WithCause(err)
+				return
+			}
+			fi := FunctionInfo{
+				Name:        function.Name(),
+				Ident:       function.Ident,
+				File:        function.filename,
+				Line:        function.Position().Line,
+				Annotations: make(map[string][]string),
+			}
+			if function.Param != nil {
+				fi.Param = function.Param.Type.String()
+			}
+			if function.Result != nil {
+				fi.Result = function.Result.Type.String()
+			}
+			function.ForeachAnnotations(func(name string, params []string) {
+				if params == nil {
+					params = []string{}
+				}
+				fi.Annotations[name] = params
+			})
+			info.Functions = append(info.Functions, fi)
+		}
+		infos = append(infos, info)
+	}
+	return
+}
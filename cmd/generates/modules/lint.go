@@ -0,0 +1,259 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package modules
+
+import (
+	"context"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/cmd/generates/sources"
+	"github.com/aacfactory/fns/commons/versions"
+	"sort"
+	"strings"
+)
+
+var (
+	// BuiltinFnAnnotations
+	// annotations of fn which are handled by generator.
+	BuiltinFnAnnotations = []string{
+		"fn", "title", "description", "errors", "validation", "readonly", "stream", "internal",
+		"deprecated", "authorization", "permission", "metric", "barrier", "cache", "cache-control",
+		"http", "cron", "feature", "priority", "audit", "sla", "since", "removed", "header",
+	}
+)
+
+type Diagnostic struct {
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	Service    string `json:"service"`
+	Fn         string `json:"fn"`
+	Annotation string `json:"annotation,omitempty"`
+	Message    string `json:"message"`
+}
+
+func (diagnostic Diagnostic) String() string {
+	if diagnostic.Annotation == "" {
+		return fmt.Sprintf("%s:%d: %s.%s: %s", diagnostic.File, diagnostic.Line, diagnostic.Service, diagnostic.Fn, diagnostic.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s.%s: @%s: %s", diagnostic.File, diagnostic.Line, diagnostic.Service, diagnostic.Fn, diagnostic.Annotation, diagnostic.Message)
+}
+
+// Lint
+// checks annotations of fns in dir of mod by the same parsers which generator uses.
+// annotations of writers and extras are known besides BuiltinFnAnnotations.
+// err is only returned when services can not be loaded, problems of fns are diagnostics.
+func Lint(ctx context.Context, mod *sources.Module, dir string, writers FnAnnotationCodeWriters, extras ...string) (diagnostics []Diagnostic, err error) {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	services, loadErr := Load(mod, dir)
+	if loadErr != nil {
+		err = errors.Warning("modules: lint failed").WithCause(loadErr)
+		return
+	}
+	known := make(map[string]bool)
+	for _, name := range BuiltinFnAnnotations {
+		known[name] = true
+	}
+	for _, writer := range writers {
+		known[writer.Annotation()] = true
+	}
+	for _, extra := range extras {
+		known[strings.TrimPrefix(strings.TrimSpace(extra), "@")] = true
+	}
+	for _, service := range services {
+		for _, function := range service.Functions {
+			diagnostics = append(diagnostics, lintFunction(ctx, function, known)...)
+		}
+	}
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].File == diagnostics[j].File {
+			return diagnostics[i].Line < diagnostics[j].Line
+		}
+		return diagnostics[i].File < diagnostics[j].File
+	})
+	return
+}
+
+func lintFunction(ctx context.Context, function *Function, known map[string]bool) (diagnostics []Diagnostic) {
+	line := function.Position().Line
+	report := func(annotation string, format string, args ...any) {
+		diagnostics = append(diagnostics, Diagnostic{
+			File:       function.filename,
+			Line:       annotationLine(function, annotation, line),
+			Service:    function.hostServiceName,
+			Fn:         function.Ident,
+			Annotation: annotation,
+			Message:    fmt.Sprintf(format, args...),
+		})
+	}
+	if parseErr := function.Parse(ctx); parseErr != nil {
+		report("", "%s", causeMessage(parseErr))
+	}
+	if name, _ := function.Annotations.Value("fn"); strings.TrimSpace(name) == "" {
+		report("fn", "name is required")
+	}
+	function.ForeachAnnotations(func(name string, params []string) {
+		if known[name] {
+			return
+		}
+		if suggestion := nearest(name, known); suggestion != "" {
+			report(name, "unknown annotation, did you mean @%s", suggestion)
+			return
+		}
+		report(name, "unknown annotation")
+	})
+	// values
+	if err := function.ValidateCache(); err != nil {
+		report("cache", "%s", causeMessage(err))
+	}
+	if _, _, _, _, _, err := function.CacheControl(); err != nil {
+		report("cache-control", "%s", causeMessage(err))
+	}
+	if _, _, _, err := function.HTTP(); err != nil {
+		report("http", "%s", causeMessage(err))
+	}
+	if _, _, _, err := function.Audit(); err != nil {
+		report("audit", "%s", causeMessage(err))
+	}
+	if _, _, err := function.SLA(); err != nil {
+		report("sla", "%s", causeMessage(err))
+	}
+	if _, err := function.Headers(); err != nil {
+		report("header", "%s", causeMessage(err))
+	}
+	since, hasSince, sinceErr := function.Since()
+	if sinceErr != nil {
+		report("since", "%s", causeMessage(sinceErr))
+	}
+	removed, hasRemoved, removedErr := function.Removed()
+	if removedErr != nil {
+		report("removed", "%s", causeMessage(removedErr))
+	}
+	if hasSince && hasRemoved && sinceErr == nil && removedErr == nil {
+		sv, _ := versions.Parse([]byte(since))
+		rv, _ := versions.Parse([]byte(removed))
+		if !sv.LessThan(rv) {
+			report("removed", "version must be greater than @since %s", since)
+		}
+	}
+	if _, has := function.Annotations.Get("cron"); has {
+		if _, ok := function.Cron(); !ok {
+			report("cron", "spec is required")
+		}
+	}
+	if _, has := function.Annotations.Get("feature"); has {
+		if _, ok := function.Feature(); !ok {
+			report("feature", "name is required")
+		}
+	}
+	if _, has, ok := function.Priority(); has && !ok {
+		report("priority", "must be low, normal or high")
+	}
+	// companions
+	if function.Permission() && !function.Authorization() {
+		report("permission", "requires @authorization")
+	}
+	if _, _, _, _, has, _ := function.CacheControl(); has && (!function.Readonly() || function.Internal()) {
+		report("cache-control", "only works with @readonly and without @internal")
+	}
+	if _, has := function.Annotations.Get("validation"); has && function.Param == nil && function.decl.Type.Params.NumFields() < 2 {
+		report("validation", "fn has no param to validate")
+	}
+	return
+}
+
+// annotationLine
+// returns line of the first comment of annotation in doc of function, or def when it is not found.
+func annotationLine(function *Function, annotation string, def int) int {
+	if annotation == "" || function.decl.Doc == nil {
+		return def
+	}
+	for _, comment := range function.decl.Doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		name, _, _ := strings.Cut(text, " ")
+		if name == "@"+annotation {
+			if line := sources.Position(function.filename, comment.Pos()).Line; line > 0 {
+				return line
+			}
+		}
+	}
+	return def
+}
+
+// causeMessage
+// flattens messages and meta of code error chain into one line.
+func causeMessage(err error) string {
+	var impl *errors.CodeErrorImpl
+	switch e := err.(type) {
+	case nil:
+		return ""
+	case errors.CodeErrorImpl:
+		impl = &e
+		break
+	case *errors.CodeErrorImpl:
+		impl = e
+		break
+	default:
+		return err.Error()
+	}
+	b := strings.Builder{}
+	for ; impl != nil; impl = impl.Cause_ {
+		if b.Len() > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(impl.Message_)
+		for _, pair := range impl.Meta_ {
+			b.WriteString(fmt.Sprintf(" (%s: %s)", pair.Key, pair.Value))
+		}
+	}
+	return b.String()
+}
+
+// nearest
+// returns known name whose edit distance to name is at most 2.
+func nearest(name string, known map[string]bool) (v string) {
+	best := 3
+	for candidate := range known {
+		if d := distance(name, candidate); d < best || (d == best && candidate < v) {
+			best = d
+			v = candidate
+		}
+	}
+	return
+}
+
+func distance(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
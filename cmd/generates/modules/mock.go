@@ -0,0 +1,242 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package modules
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/gcg"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MockBuildTag
+// the generated mock is only compiled with it, e.g. go test -tags fnsmock ./...
+const MockBuildTag = "fnsmock"
+
+func NewMockFile(service *Service) (file CodeFileWriter) {
+	file = &MockFile{
+		service: service,
+		source:  &ServiceFile{service: service},
+	}
+	return
+}
+
+type MockFile struct {
+	service *Service
+	source  *ServiceFile
+}
+
+func (s *MockFile) Name() (name string) {
+	name = filepath.ToSlash(filepath.Join(s.service.Dir, "fns_mock.go"))
+	return
+}
+
+func (s *MockFile) Write(ctx context.Context) (err error) {
+	if ctx.Err() != nil {
+		err = errors.Warning("modules: mock write failed").
+			WithMeta("kind", "mock").WithMeta("service", s.service.Name).
+			WithCause(ctx.Err())
+		return
+	}
+
+	file := gcg.NewFileWithoutNote(s.service.Path[strings.LastIndex(s.service.Path, "/")+1:])
+	file.FileComments("NOTE: this file has been automatically generated, DON'T EDIT IT!!!\n")
+
+	packages, importsErr := s.source.importsCode(ctx)
+	if importsErr != nil {
+		err = errors.Warning("modules: mock code file write failed").
+			WithMeta("kind", "mock").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+			WithCause(importsErr)
+		return
+	}
+	for _, importer := range packages {
+		file.AddImport(importer)
+	}
+
+	mock, mockErr := s.mockCode(ctx)
+	if mockErr != nil {
+		err = errors.Warning("modules: mock code file write failed").
+			WithMeta("kind", "mock").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+			WithCause(mockErr)
+		return
+	}
+	file.AddCode(mock)
+
+	buf := bytes.NewBuffer([]byte{})
+	// build constraint must be the first line and be followed by a blank line
+	buf.WriteString(fmt.Sprintf("//go:build %s\n\n", MockBuildTag))
+	renderErr := file.Render(buf)
+	if renderErr != nil {
+		err = errors.Warning("modules: mock code file write failed").
+			WithMeta("kind", "mock").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+			WithCause(renderErr)
+		return
+	}
+
+	writer, openErr := os.OpenFile(s.Name(), os.O_CREATE|os.O_TRUNC|os.O_RDWR|os.O_SYNC, 0644)
+	if openErr != nil {
+		err = errors.Warning("modules: mock code file write failed").
+			WithMeta("kind", "mock").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+			WithCause(openErr)
+		return
+	}
+	n := 0
+	bodyLen := buf.Len()
+	content := buf.Bytes()
+	for n < bodyLen {
+		nn, writeErr := writer.Write(content[n:])
+		if writeErr != nil {
+			err = errors.Warning("modules: mock code file write failed").
+				WithMeta("kind", "mock").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+				WithCause(writeErr)
+			return
+		}
+		n += nn
+	}
+	syncErr := writer.Sync()
+	if syncErr != nil {
+		err = errors.Warning("modules: mock code file write failed").
+			WithMeta("kind", "mock").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+			WithCause(syncErr)
+		return
+	}
+	closeErr := writer.Close()
+	if closeErr != nil {
+		err = errors.Warning("modules: mock code file write failed").
+			WithMeta("kind", "mock").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+			WithCause(closeErr)
+		return
+	}
+	return
+}
+
+func (s *MockFile) mockCode(ctx context.Context) (code gcg.Code, err error) {
+	if ctx.Err() != nil {
+		err = errors.Warning("modules: mock write failed").
+			WithMeta("kind", "mock").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+			WithCause(ctx.Err())
+		return
+	}
+	stmt := gcg.Statements()
+	stmt.Add(gcg.Token("// +-------------------------------------------------------------------------------------------------------------------+").Line().Line())
+	// type
+	mockStructCode := gcg.Struct()
+	for _, function := range s.service.Functions {
+		handler := gcg.Token("func(ctx ").Add(contextCode())
+		if function.Param != nil {
+			param, paramErr := s.source.fieldTypeCode(function.Param.Type)
+			if paramErr != nil {
+				err = errors.Warning("modules: make mock code failed").
+					WithMeta("kind", "mock").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+					WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+					WithCause(paramErr)
+				return
+			}
+			handler.Token(", param ").Add(param)
+		}
+		handler.Token(") (")
+		if function.Result != nil {
+			result, resultErr := s.source.fieldTypeCode(function.Result.Type)
+			if resultErr != nil {
+				err = errors.Warning("modules: make mock code failed").
+					WithMeta("kind", "mock").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+					WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+					WithCause(resultErr)
+				return
+			}
+			handler.Token("result ").Add(result).Token(", ")
+		}
+		handler.Token("err error)")
+		field := gcg.StructField(function.ProxyIdent)
+		field.Type(handler)
+		mockStructCode.AddField(field)
+	}
+	stmt.Add(gcg.Token("// Mock\n// replaces the service in tests, each fn calls the func of the same name, and fails with not implemented when it is nil.").Line())
+	stmt.Add(gcg.Type("Mock", mockStructCode.Build())).Line()
+
+	// service
+	instance := gcg.Func()
+	instance.Name("Service")
+	instance.Receiver("mock", gcg.Star().Ident("Mock"))
+	instance.AddResult("v", gcg.Token("services.Service"))
+	body := gcg.Statements()
+	if s.service.Internal {
+		body.Tab().Token("svc := services.NewAbstract(string(_endpointName), true)").Line()
+	} else {
+		body.Tab().Token("svc := services.NewAbstract(string(_endpointName), false)").Line()
+	}
+	for _, function := range s.service.Functions {
+		param := gcg.QualifiedIdent(gcg.NewPackage("github.com/aacfactory/fns/services"), "Empty")
+		if function.Param != nil {
+			param, err = s.source.fieldTypeCode(function.Param.Type)
+			if err != nil {
+				err = errors.Warning("modules: make mock code failed").
+					WithMeta("kind", "mock").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+					WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+					WithCause(err)
+				return
+			}
+		}
+		result := gcg.QualifiedIdent(gcg.NewPackage("github.com/aacfactory/fns/services"), "Empty")
+		if function.Result != nil {
+			result, err = s.source.fieldTypeCode(function.Result.Type)
+			if err != nil {
+				err = errors.Warning("modules: make mock code failed").
+					WithMeta("kind", "mock").WithMeta("service", s.service.Name).WithMeta("file", s.Name()).
+					WithMeta("function", function.Name()).WithMeta("position", function.Position().String()).
+					WithCause(err)
+				return
+			}
+		}
+		body.Tab().Token(fmt.Sprintf("// %s", function.Name())).Line()
+		body.Tab().Token("svc.AddFunction(")
+		body.Token("commons.NewFn[", gcg.NewPackage("github.com/aacfactory/fns/services/commons")).Add(param).Token(", ").Add(result).Token("](").Line()
+		body.Token(fmt.Sprintf("string(%s),", function.VarIdent)).Line()
+		body.Token("func(ctx context.Context, param ").Add(param).Token(") (v ").Add(result).Token(", err error) {").Line()
+		body.Tab().Token(fmt.Sprintf("if mock.%s == nil {", function.ProxyIdent)).Line()
+		body.Tab().Tab().Token(fmt.Sprintf("err = errors.NotImplemented(\"%s: %s was not mocked\")", s.service.Name, function.Name())).Line()
+		body.Tab().Tab().Token("return").Line()
+		body.Tab().Token("}").Line()
+		if function.Param == nil && function.Result == nil {
+			body.Tab().Token(fmt.Sprintf("err = mock.%s(ctx)", function.ProxyIdent)).Line()
+		} else if function.Param == nil && function.Result != nil {
+			body.Tab().Token(fmt.Sprintf("v, err = mock.%s(ctx)", function.ProxyIdent)).Line()
+		} else if function.Param != nil && function.Result == nil {
+			body.Tab().Token(fmt.Sprintf("err = mock.%s(ctx, param)", function.ProxyIdent)).Line()
+		} else {
+			body.Tab().Token(fmt.Sprintf("v, err = mock.%s(ctx, param)", function.ProxyIdent)).Line()
+		}
+		body.Tab().Token("return").Line()
+		body.Token("},").Line()
+		if function.Internal() {
+			body.Token("commons.Internal(),").Line()
+		}
+		body.Token("))").Line()
+	}
+	body.Tab().Token("v = &svc").Line()
+	body.Tab().Return()
+	instance.Body(body)
+	stmt.Add(instance.Build()).Line()
+
+	code = stmt
+	return
+}
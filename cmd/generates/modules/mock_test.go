@@ -0,0 +1,125 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package modules_test
+
+import (
+	"context"
+	"github.com/aacfactory/fns/cmd/generates/modules"
+	"github.com/aacfactory/fns/cmd/generates/sources"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMockFile(t *testing.T) {
+	gobin, lookErr := exec.LookPath("go")
+	if lookErr != nil {
+		t.Skip("go is not found")
+	}
+	root, rootErr := filepath.Abs(filepath.Join("..", "..", ".."))
+	if rootErr != nil {
+		t.Fatal(rootErr)
+	}
+	sum, sumErr := os.ReadFile(filepath.Join(root, "go.sum"))
+	if sumErr != nil {
+		t.Fatal(sumErr)
+	}
+	dir := t.TempDir()
+	write := func(name string, content string) {
+		filename := filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(filename), 0755)
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/mocks\n\ngo 1.22\n\nrequire github.com/aacfactory/fns v0.0.0\n\nreplace github.com/aacfactory/fns => "+filepath.ToSlash(root)+"\n")
+	write("go.sum", string(sum))
+	write("modules/users/doc.go", "// Package users\n// @service users\npackage users\n")
+	write("modules/users/users.go", `package users
+
+import "github.com/aacfactory/fns/context"
+
+type GetParam struct {
+	Id string `+"`json:\"id\"`"+`
+}
+
+type User struct {
+	Id string `+"`json:\"id\"`"+`
+}
+
+// get
+// @fn get
+func get(ctx context.Context, param GetParam) (v User, err error) {
+	return
+}
+
+// ping
+// @fn ping
+// @internal
+func ping(ctx context.Context) (err error) {
+	return
+}
+`)
+	mod, modErr := sources.New(filepath.Join(dir, "go.mod"))
+	if modErr != nil {
+		t.Fatal(modErr)
+	}
+	if err := mod.Parse(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	services, loadErr := modules.Load(mod, modules.DefaultDir)
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+	for _, function := range services[0].Functions {
+		if err := function.Parse(context.TODO()); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	serviceFile := modules.NewServiceFile(services[0], nil)
+	if err := serviceFile.Write(context.TODO()); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	mockFile := modules.NewMockFile(services[0])
+	if err := mockFile.Write(context.TODO()); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if filepath.Base(mockFile.Name()) != "fns_mock.go" {
+		t.Fatal("unexpected mock file name", mockFile.Name())
+	}
+	service, _ := os.ReadFile(serviceFile.Name())
+	if strings.Contains(string(service), "Mock") {
+		t.Fatal("mock must not be generated into fns.go")
+	}
+	mock, _ := os.ReadFile(mockFile.Name())
+	if !strings.HasPrefix(string(mock), "//go:build "+modules.MockBuildTag+"\n\n") {
+		t.Fatal("mock must be built with tag only")
+	}
+	// both with and without the tag must compile
+	for _, args := range [][]string{{"build", "./..."}, {"build", "-tags", modules.MockBuildTag, "./..."}} {
+		cmd := exec.Command(gobin, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+		out, runErr := cmd.CombinedOutput()
+		if runErr != nil {
+			t.Fatal(args, runErr, string(out), string(mock))
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package modules_test
+
+import (
+	"context"
+	"github.com/aacfactory/fns/cmd/generates/modules"
+	"github.com/aacfactory/fns/cmd/generates/sources"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPageResult(t *testing.T) {
+	root, rootErr := filepath.Abs(filepath.Join("..", "..", ".."))
+	if rootErr != nil {
+		t.Fatal(rootErr)
+	}
+	dir := t.TempDir()
+	write := func(name string, content string) {
+		filename := filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(filename), 0755)
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/pages\n\ngo 1.22\n\nrequire github.com/aacfactory/fns v0.0.0\n\nreplace github.com/aacfactory/fns v0.0.0 => "+filepath.ToSlash(root)+"\n")
+	write("modules/users/doc.go", "// Package users\n// @service users\npackage users\n")
+	write("modules/users/users.go", `package users
+
+import (
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/services"
+)
+
+// User
+// @title user
+type User struct {
+	// Id
+	// @title id
+	Id string `+"`json:\"id\"`"+`
+}
+
+// list
+// @fn list
+func list(ctx context.Context) (v services.Page[User], err error) {
+	return
+}
+`)
+	mod, modErr := sources.New(filepath.Join(dir, "go.mod"))
+	if modErr != nil {
+		t.Fatal(modErr)
+	}
+	if err := mod.Parse(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	services, loadErr := modules.Load(mod, modules.DefaultDir)
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+	for _, function := range services[0].Functions {
+		if err := function.Parse(context.TODO()); err != nil {
+			t.Fatalf("%+v", err)
+		}
+	}
+	file := modules.NewServiceFile(services[0], nil)
+	if err := file.Write(context.TODO()); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	p, readErr := os.ReadFile(file.Name())
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	code := string(p)
+	for _, expect := range []string{
+		// proxy
+		"func List(ctx context.Context) (result services.Page[User], err error)",
+		"services.ValueOfResponse[services.Page[User]](response)",
+		"commons.NewFn[services.Empty, services.Page[User]](",
+		// document
+		`documents.Struct("github.com/aacfactory/fns/services", "Page+example.com/pages/modules/users.User")`,
+		`documents.Array(documents.Struct("example.com/pages/modules/users", "User")`,
+		`"total",`,
+		`"cursor",`,
+	} {
+		if !strings.Contains(code, expect) {
+			t.Errorf("%s is not generated", expect)
+		}
+	}
+	if strings.Contains(code, "omitempty") {
+		t.Error("options of json tag must not be in property name")
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package modules_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/aacfactory/fns/cmd/generates/modules"
+	"github.com/aacfactory/fns/cmd/generates/sources"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPositions(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) {
+		filename := filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(filename), 0755)
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/positions\n\ngo 1.22\n")
+	write("modules/users/doc.go", "// Package users\n// @service users\npackage users\n")
+	write("modules/users/fns.go", `package users
+
+import "github.com/aacfactory/fns/context"
+
+// Param
+// @title param
+type Param struct {
+	Id string `+"`json:\"id\"`"+`
+}
+
+// get
+// @fn get
+func get(ctx context.Context, param Param) (v Param, err error) {
+	return
+}
+
+// bad
+// @fn bad
+func bad(param Param) (err error) {
+	return
+}
+`)
+	mod, modErr := sources.New(filepath.Join(dir, "go.mod"))
+	if modErr != nil {
+		t.Fatal(modErr)
+	}
+	if err := mod.Parse(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	services, loadErr := modules.Load(mod, modules.DefaultDir)
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+	for _, function := range services[0].Functions {
+		err := function.Parse(context.TODO())
+		switch function.Ident {
+		case "bad":
+			if err == nil || !strings.Contains(fmt.Sprintf("%+v", err), "fns.go:19:1") {
+				t.Errorf("position of bad is not in error: %+v", err)
+			}
+			break
+		case "get":
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if position := function.Position(); position.Line != 13 {
+				t.Error("position of get", position)
+			}
+			if position := function.Param.Type.Position; position.Line != 7 || filepath.Base(position.Filename) != "fns.go" {
+				t.Error("position of param", position)
+			}
+			break
+		}
+	}
+}
@@ -285,6 +285,7 @@ func (service *Service) loadComponents() (err error) {
 				}
 				service.Components = append(service.Components, &Component{
 					Indent: ident,
+					Lazy:   strings.Contains(doc, "@lazy"),
 				})
 			}
 		}
@@ -21,11 +21,15 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/cmd/generates/sources"
+	"github.com/aacfactory/fns/commons/jsons"
 	"github.com/aacfactory/gcg"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func mapTypeToFunctionElementCode(ctx context.Context, typ *sources.Type) (code gcg.Code, err error) {
@@ -139,6 +143,14 @@ func mapBasicTypeToFunctionElementCode(ctx context.Context, typ *sources.Type) (
 			stmt.Token(fmt.Sprintf("documents.Password()"))
 			break
 		}
+		if typ.Path == "github.com/aacfactory/fns/commons/decimals" && typ.Name == "Decimal" {
+			stmt.Token(fmt.Sprintf("documents.Decimal()"))
+			break
+		}
+		if typ.Path == "github.com/aacfactory/fns/commons/uuids" && typ.Name == "UUID" {
+			stmt.Token(fmt.Sprintf("documents.UUID()"))
+			break
+		}
 		if typ.Path == "github.com/aacfactory/json" && typ.Name == "Date" {
 			stmt.Token(fmt.Sprintf("documents.Date()"))
 			break
@@ -177,6 +189,10 @@ func mapBuiltinTypeToFunctionElementCode(ctx context.Context, typ *sources.Type)
 			WithCause(ctx.Err())
 		return
 	}
+	if typ.Path == "github.com/aacfactory/fns/services" && typ.Name == "File" {
+		code = gcg.Statements().Token("documents.Binary()")
+		return
+	}
 	code = gcg.Statements().Token("documents.Ref(").Token(fmt.Sprintf("\"%s\",\"%s\"", typ.Path, typ.Name)).Symbol(")")
 	return
 }
@@ -264,12 +280,14 @@ func mapStructTypeToFunctionElementCode(ctx context.Context, typ *sources.Type)
 	}
 	for _, field := range typ.Elements {
 		name, hasName := field.Tags["json"]
-		if !hasName {
-			name = field.Name
-		}
 		if name == "-" {
 			continue
 		}
+		// options such as omitempty are not part of name
+		name, _, _ = strings.Cut(name, ",")
+		if !hasName || name == "" {
+			name = field.Name
+		}
 		fieldCode, fieldCodeErr := mapTypeToFunctionElementCode(ctx, field)
 		if fieldCodeErr != nil {
 			err = errors.Warning("modules: mapping struct type to function element code failed").
@@ -303,6 +321,17 @@ func mapStructFieldTypeToFunctionElementCode(ctx context.Context, typ *sources.T
 			WithCause(elementCodeErr)
 		return
 	}
+	// time format
+	if fieldTimeFormat, hasFieldTimeFormat := typ.Tags[jsons.TimeFormatTag]; hasFieldTimeFormat && isDateTimeType(typ.Elements[0]) {
+		timeFormat, timeFormatErr := jsons.ParseTimeFormat(fieldTimeFormat)
+		if timeFormatErr != nil {
+			err = errors.Warning("modules: mapping struct field type to function element code failed").
+				WithMeta("field", typ.Name).WithMeta("timeFormat", fieldTimeFormat).
+				WithCause(timeFormatErr)
+			return
+		}
+		elementCode = gcg.Statements().Token(fmt.Sprintf("documents.DateTimeOf(\"%s\")", timeFormat))
+	}
 	stmt := elementCode.(*gcg.Statement)
 	fieldTitle, hasFieldTitle := typ.Annotations.Value("title")
 	if hasFieldTitle {
@@ -316,6 +345,17 @@ func mapStructFieldTypeToFunctionElementCode(ctx context.Context, typ *sources.T
 	if hasFieldDeprecated {
 		stmt = stmt.Dot().Line().Token("AsDeprecated()")
 	}
+	// default
+	if fieldDefault, hasFieldDefault := typ.Tags["default"]; hasFieldDefault {
+		defaultValue, defaultErr := coerceDefaultValue(typ.Elements[0], fieldDefault)
+		if defaultErr != nil {
+			err = errors.Warning("modules: mapping struct field type to function element code failed").
+				WithMeta("field", typ.Name).WithMeta("default", fieldDefault).
+				WithCause(defaultErr)
+			return
+		}
+		stmt = stmt.Dot().Line().Token("SetDefault(").Token(strconv.Quote(defaultValue)).Symbol(")")
+	}
 	// password
 	_, hasFieldPassword := typ.Annotations.Get("password")
 	if hasFieldPassword {
@@ -337,7 +377,14 @@ func mapStructFieldTypeToFunctionElementCode(ctx context.Context, typ *sources.T
 	// validation
 	fieldValidate, hasFieldValidate := typ.Tags["validate"]
 	if hasFieldValidate && fieldValidate != "" {
-		fieldRequired := strings.Contains(fieldValidate, "required")
+		// required_if and required_with are conditional, so they do not make field required
+		fieldRequired := false
+		for _, rule := range strings.Split(fieldValidate, ",") {
+			if strings.TrimSpace(rule) == "required" {
+				fieldRequired = true
+				break
+			}
+		}
 		if fieldRequired {
 			stmt = stmt.Dot().Line().Token("AsRequired()")
 		}
@@ -478,6 +525,18 @@ func mapParadigmTypeToFunctionElementCode(ctx context.Context, typ *sources.Type
 			WithCause(ctx.Err())
 		return
 	}
+	if x := typ.Elements[0]; x.Path == "github.com/aacfactory/fns/services" && x.Name == "Optional" && len(typ.Paradigms) == 1 {
+		// optional is the nullable value type
+		code, err = mapTypeToFunctionElementCode(ctx, typ.Paradigms[0].Types[0])
+		if err != nil {
+			err = errors.Warning("modules: mapping paradigm type to function document element code failed").
+				WithMeta("path", typ.Path).WithMeta("name", typ.Name).WithMeta("kind", typ.Kind.String()).
+				WithCause(err)
+			return
+		}
+		code = code.(*gcg.Statement).Dot().Line().Token("AsNullable()")
+		return
+	}
 	code, err = mapTypeToFunctionElementCode(ctx, typ.ParadigmsPacked)
 	if err != nil {
 		err = errors.Warning("modules: mapping paradigm type to function document element code failed").
@@ -515,3 +574,91 @@ func mapReferenceTypeToFunctionElementCode(ctx context.Context, typ *sources.Typ
 	code = gcg.Statements().Token(fmt.Sprintf("documents.Ref(\"%s\", \"%s\")", typ.Path, typ.Name))
 	return
 }
+
+// coerceDefaultValue
+// returns json literal of default tag, it must be same as defaults.Parse.
+func coerceDefaultValue(typ *sources.Type, literal string) (v string, err error) {
+	if typ.Kind == sources.PointerKind {
+		v, err = coerceDefaultValue(typ.Elements[0], literal)
+		return
+	}
+	if typ.Kind == sources.BasicKind && typ.Path == "time" && typ.Name == "Duration" {
+		d, parseErr := time.ParseDuration(literal)
+		if parseErr != nil {
+			err = errors.Warning("default of duration is invalid").WithCause(parseErr)
+			return
+		}
+		v = strconv.FormatInt(int64(d), 10)
+		return
+	}
+	name, basic := typ.Basic()
+	if !basic || typ.Kind == sources.BasicKind && typ.Path != "" {
+		if !json.Valid([]byte(literal)) {
+			err = errors.Warning("default of non basic type must be json")
+			return
+		}
+		v = literal
+		return
+	}
+	switch name {
+	case "string":
+		v = strconv.Quote(literal)
+		break
+	case "bool":
+		b, parseErr := strconv.ParseBool(literal)
+		if parseErr != nil {
+			err = errors.Warning("default of bool is invalid").WithCause(parseErr)
+			return
+		}
+		v = strconv.FormatBool(b)
+		break
+	case "int", "int8", "int16", "int32", "int64":
+		n, parseErr := strconv.ParseInt(literal, 10, basicBits(name))
+		if parseErr != nil {
+			err = errors.Warning("default of int is invalid").WithCause(parseErr)
+			return
+		}
+		v = strconv.FormatInt(n, 10)
+		break
+	case "uint", "uint8", "byte", "uint16", "uint32", "uint64":
+		n, parseErr := strconv.ParseUint(literal, 10, basicBits(name))
+		if parseErr != nil {
+			err = errors.Warning("default of uint is invalid").WithCause(parseErr)
+			return
+		}
+		v = strconv.FormatUint(n, 10)
+		break
+	case "float32", "float64":
+		n, parseErr := strconv.ParseFloat(literal, basicBits(name))
+		if parseErr != nil {
+			err = errors.Warning("default of float is invalid").WithCause(parseErr)
+			return
+		}
+		v = strconv.FormatFloat(n, 'g', -1, basicBits(name))
+		break
+	default:
+		err = errors.Warning("default is not supported").WithMeta("type", name)
+		break
+	}
+	return
+}
+
+func basicBits(name string) int {
+	switch name {
+	case "int8", "uint8", "byte":
+		return 8
+	case "int16", "uint16":
+		return 16
+	case "int32", "uint32", "float32":
+		return 32
+	default:
+		return 64
+	}
+}
+
+func isDateTimeType(typ *sources.Type) bool {
+	if typ.Kind == sources.PointerKind {
+		typ = typ.Elements[0]
+	}
+	return typ.Path == "time" && typ.Name == "Time"
+}
@@ -142,6 +142,18 @@ func (annotations *Annotations) Set(name string, param string) {
 	*annotations = ss
 }
 
+var (
+	repeatableAnnotations = map[string]struct{}{
+		"header": {},
+	}
+)
+
+// RegisterRepeatableAnnotation
+// each line of repeatable annotation is added as one param, and it can be declared more than once.
+func RegisterRepeatableAnnotation(name string) {
+	repeatableAnnotations[strings.TrimSpace(name)] = struct{}{}
+}
+
 func ParseAnnotations(s string) (annotations Annotations, err error) {
 	annotations = make(Annotations, 0, 1)
 	if s == "" || !strings.Contains(s, "@") {
@@ -183,6 +195,10 @@ func ParseAnnotations(s string) (annotations Annotations, err error) {
 			continue
 		} else {
 			name = strings.TrimSpace(string(content[0:paramsIdx]))
+			if _, repeatable := repeatableAnnotations[name]; repeatable {
+				annotations.Add(name, string(bytes.TrimSpace(content[paramsIdx:])))
+				continue
+			}
 			_, has := annotations.Get(name)
 			if has {
 				err = errors.Warning("sources: parse annotations failed").WithCause(fmt.Errorf("@%s is duplicated", name)).WithMeta("source", s)
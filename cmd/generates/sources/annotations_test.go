@@ -39,7 +39,9 @@ func TestParseAnnotations(t *testing.T) {
 @auth
 @permission
 @sql:tx name
-@cache get-set 10`
+@cache get-set 10
+@header Cache-Control: no-store
+@header X-App-Feature: foo bar`
 	annos, err := sources.ParseAnnotations(s)
 	if err != nil {
 		fmt.Println(fmt.Sprintf("%+v", err))
@@ -48,4 +50,8 @@ func TestParseAnnotations(t *testing.T) {
 	for _, anno := range annos {
 		fmt.Println(anno.Name, len(anno.Params), fmt.Sprintf("%+v", anno.Params))
 	}
+	header, hasHeader := annos.Get("header")
+	if !hasHeader || len(header.Params) != 2 || header.Params[1] != "X-App-Feature: foo bar" {
+		t.Error("repeatable @header was not parsed")
+	}
 }
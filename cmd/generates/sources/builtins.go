@@ -28,6 +28,26 @@ func registerBuiltinTypes(mode *Module) {
 		Tags:        nil,
 		Elements:    nil,
 	})
+	// github.com/aacfactory/fns/commons/decimals.Decimal
+	mode.RegisterBuiltinType(&Type{
+		Kind:        BasicKind,
+		Path:        "github.com/aacfactory/fns/commons/decimals",
+		Name:        "Decimal",
+		Annotations: nil,
+		Paradigms:   nil,
+		Tags:        nil,
+		Elements:    nil,
+	})
+	// github.com/aacfactory/fns/commons/uuids.UUID
+	mode.RegisterBuiltinType(&Type{
+		Kind:        BasicKind,
+		Path:        "github.com/aacfactory/fns/commons/uuids",
+		Name:        "UUID",
+		Annotations: nil,
+		Paradigms:   nil,
+		Tags:        nil,
+		Elements:    nil,
+	})
 	// time.Time
 	mode.RegisterBuiltinType(&Type{
 		Kind:        BasicKind,
@@ -118,6 +138,48 @@ func registerBuiltinTypes(mode *Module) {
 		Tags:        nil,
 		Elements:    nil,
 	})
+	// github.com/aacfactory/fns/services.File
+	mode.RegisterBuiltinType(&Type{
+		Kind:        BuiltinKind,
+		Path:        "github.com/aacfactory/fns/services",
+		Name:        "File",
+		Annotations: nil,
+		Paradigms:   nil,
+		Tags:        nil,
+		Elements:    nil,
+	})
+	// github.com/aacfactory/fns/services.Redirection
+	mode.RegisterBuiltinType(&Type{
+		Kind: StructKind,
+		Path: "github.com/aacfactory/fns/services",
+		Name: "Redirection",
+		Annotations: Annotations{
+			NewAnnotation("title", "Redirection"),
+			NewAnnotation("description", "Redirect to location"),
+		},
+		Paradigms: nil,
+		Tags:      nil,
+		Elements: []*Type{
+			{
+				Kind: StructFieldKind,
+				Name: "Location",
+				Tags: map[string]string{"json": "location"},
+				Elements: []*Type{{
+					Kind: BasicKind,
+					Name: "string",
+				}},
+			},
+			{
+				Kind: StructFieldKind,
+				Name: "Status",
+				Tags: map[string]string{"json": "status"},
+				Elements: []*Type{{
+					Kind: BasicKind,
+					Name: "int",
+				}},
+			},
+		},
+	})
 	// github.com/aacfactory/errors.CodeErr
 	mode.RegisterBuiltinType(&Type{
 		Kind: StructKind,
@@ -371,7 +371,7 @@ func (mod *Module) ParseType(ctx context.Context, path string, name string) (typ
 		return
 	}
 	// spec
-	spec, specImports, genDoc, findSpecErr := typeModule.sources.FindTypeSpec(path, name)
+	spec, specImports, genDoc, filename, findSpecErr := typeModule.sources.FindTypeSpec(path, name)
 	if findSpecErr != nil {
 		err = errors.Warning("sources: mod parse type failed").
 			WithMeta("path", path).WithMeta("name", name).
@@ -381,6 +381,7 @@ func (mod *Module) ParseType(ctx context.Context, path string, name string) (typ
 
 	typ, err = typeModule.types.parseType(ctx, spec, &TypeScope{
 		Path:       path,
+		Filename:   filename,
 		Mod:        typeModule,
 		Imports:    specImports,
 		GenericDoc: genDoc,
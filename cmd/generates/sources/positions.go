@@ -0,0 +1,48 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sources
+
+import (
+	"bytes"
+	"go/token"
+	"os"
+)
+
+// Position
+// returns position of pos in filename, file must be parsed with its own token.FileSet, as Sources does.
+// invalid position is returned when it can not be resolved.
+func Position(filename string, pos token.Pos) (position token.Position) {
+	if !pos.IsValid() || filename == "" {
+		return
+	}
+	content, readErr := os.ReadFile(filename)
+	if readErr != nil {
+		return
+	}
+	offset := int(pos) - 1
+	if offset > len(content) {
+		return
+	}
+	position = token.Position{
+		Filename: filename,
+		Offset:   offset,
+		Line:     bytes.Count(content[:offset], []byte{'\n'}) + 1,
+		Column:   offset - bytes.LastIndexByte(content[:offset], '\n'),
+	}
+	return
+}
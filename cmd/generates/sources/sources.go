@@ -152,7 +152,7 @@ func (sources *Sources) FindFileInDir(path string, matcher func(file *ast.File)
 	return
 }
 
-func (sources *Sources) FindTypeSpec(path string, name string) (spec *ast.TypeSpec, imports Imports, genericDoc string, err error) {
+func (sources *Sources) FindTypeSpec(path string, name string) (spec *ast.TypeSpec, imports Imports, genericDoc string, filename string, err error) {
 	reader, readerErr := sources.getReader(path)
 	if readerErr != nil {
 		err = errors.Warning("sources: find type spec in source dir failed").
@@ -186,6 +186,7 @@ func (sources *Sources) FindTypeSpec(path string, name string) (spec *ast.TypeSp
 				}
 				if ts.Name.Name == name {
 					spec = ts
+					filename = sf.filename
 					imports = NewImportsFromAstFileImports(file.Imports)
 					if genDecl.Doc != nil {
 						genericDoc = genDecl.Doc.Text()
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"github.com/aacfactory/errors"
 	"go/ast"
+	"go/token"
 	"golang.org/x/sync/singleflight"
 	"reflect"
 	"sync"
@@ -115,6 +116,9 @@ type Type struct {
 	Tags            map[string]string
 	Elements        []*Type
 	ParadigmsPacked *Type
+	// Position
+	// declaration of named type, it is invalid for unnamed and builtin types.
+	Position token.Position
 }
 
 func (typ *Type) Flats() (v map[string]*Type) {
@@ -269,6 +273,12 @@ func (typ *Type) GetTopPaths() (paths []string) {
 		paths = append(paths, typ.Elements[0].GetTopPaths()...)
 		paths = append(paths, typ.Elements[1].GetTopPaths()...)
 		break
+	case ParadigmKind:
+		paths = append(paths, typ.Elements[0].GetTopPaths()...)
+		for _, paradigm := range typ.Paradigms {
+			paths = append(paths, paradigm.Types[0].GetTopPaths()...)
+		}
+		break
 	default:
 		break
 	}
@@ -298,6 +308,7 @@ func (typ *Type) Clone() (v *Type) {
 		Tags:            typ.Tags,
 		Elements:        nil,
 		ParadigmsPacked: typ.ParadigmsPacked,
+		Position:        typ.Position,
 	}
 	if typ.Elements != nil && len(typ.Elements) > 0 {
 		v.Elements = make([]*Type, 0, 1)
@@ -455,6 +466,7 @@ func (typ *Type) packParadigms(ctx context.Context) (err error) {
 
 type TypeScope struct {
 	Path       string
+	Filename   string
 	Mod        *Module
 	Imports    Imports
 	GenericDoc string
@@ -667,6 +679,7 @@ func (types *Types) parseType(ctx context.Context, spec *ast.TypeSpec, scope *Ty
 		if err != nil {
 			return
 		}
+		result.Position = Position(scope.Filename, spec.Pos())
 		types.values.Store(key, result)
 		v = result
 		return
@@ -0,0 +1,366 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package decimals
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal
+// fixed-point decimal, use it for money instead of float64.
+// value of it is unscaled * 10^-scale, scale is kept, so 1.50 is encoded as "1.50".
+// the zero value is 0, and Decimal is immutable, all operations return new one.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+const (
+	// MaxParseScale
+	// limits exponent of parsed string, it stops 1e999999999 from allocating huge number.
+	MaxParseScale = 1024
+)
+
+var (
+	ten  = big.NewInt(10)
+	Zero = Decimal{}
+)
+
+// New
+// returns unscaled * 10^-scale, such as New(150, 2) is 1.50.
+func New(unscaled int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{
+			unscaled: new(big.Int).Mul(big.NewInt(unscaled), pow10(-scale)),
+		}
+	}
+	return Decimal{
+		unscaled: big.NewInt(unscaled),
+		scale:    scale,
+	}
+}
+
+func FromInt(n int64) Decimal {
+	return New(n, 0)
+}
+
+// FromFloat
+// returns the shortest decimal which is equal to f, it panics when f is NaN or Inf.
+func FromFloat(f float64) Decimal {
+	d, err := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		panic(fmt.Errorf("decimals: %v is not a decimal", f))
+		return Zero
+	}
+	return d
+}
+
+// Parse
+// parses decimal string, such as -12.50, .5 and 1.2e3.
+func Parse(s string) (d Decimal, err error) {
+	s = strings.TrimSpace(s)
+	src := s
+	if s == "" {
+		err = fmt.Errorf("decimals: empty string is not a decimal")
+		return
+	}
+	exp := int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err = strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			err = fmt.Errorf("decimals: %s is not a decimal", src)
+			return
+		}
+		s = s[:i]
+	}
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	integer, fraction, _ := strings.Cut(s, ".")
+	digits := integer + fraction
+	if digits == "" {
+		err = fmt.Errorf("decimals: %s is not a decimal", src)
+		return
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			err = fmt.Errorf("decimals: %s is not a decimal", src)
+			return
+		}
+	}
+	unscaled, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		unscaled.Neg(unscaled)
+	}
+	scale := int64(len(fraction)) - exp
+	if scale > MaxParseScale || scale < -MaxParseScale {
+		err = fmt.Errorf("decimals: exponent of %s is out of range", src)
+		return
+	}
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(int32(-scale)))
+		scale = 0
+	}
+	d = Decimal{
+		unscaled: unscaled,
+		scale:    int32(scale),
+	}
+	return
+}
+
+// MustParse
+// is Parse but panics when s is invalid.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+		return Zero
+	}
+	return d
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) value() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale == d.scale {
+		return d.value()
+	}
+	return new(big.Int).Mul(d.value(), pow10(scale-d.scale))
+}
+
+func align(x Decimal, y Decimal) (a *big.Int, b *big.Int, scale int32) {
+	scale = max(x.scale, y.scale)
+	a = x.rescale(scale)
+	b = y.rescale(scale)
+	return
+}
+
+// Scale
+// returns number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+func (d Decimal) Sign() int {
+	return d.value().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp
+// compares values, so 1.5 equals 1.50.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := align(d, o)
+	return a.Cmp(b)
+}
+
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+func (d Decimal) LessThan(o Decimal) bool {
+	return d.Cmp(o) < 0
+}
+
+func (d Decimal) GreaterThan(o Decimal) bool {
+	return d.Cmp(o) > 0
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{
+		unscaled: new(big.Int).Neg(d.value()),
+		scale:    d.scale,
+	}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{
+		unscaled: new(big.Int).Abs(d.value()),
+		scale:    d.scale,
+	}
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return Decimal{
+		unscaled: new(big.Int).Add(a, b),
+		scale:    scale,
+	}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, scale := align(d, o)
+	return Decimal{
+		unscaled: new(big.Int).Sub(a, b),
+		scale:    scale,
+	}
+}
+
+// Mul
+// scale of result is sum of scales, use Round to reduce it.
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{
+		unscaled: new(big.Int).Mul(d.value(), o.value()),
+		scale:    d.scale + o.scale,
+	}
+}
+
+// Div
+// returns d / o rounded half away from zero to scale, it panics when o is zero.
+func (d Decimal) Div(o Decimal, scale int32) Decimal {
+	if o.IsZero() {
+		panic(fmt.Errorf("decimals: division by zero"))
+		return Zero
+	}
+	// d/o = (du * 10^(scale+os-ds)) / ou * 10^-scale
+	shift := scale + o.scale - d.scale
+	num := d.value()
+	den := o.value()
+	if shift >= 0 {
+		num = new(big.Int).Mul(num, pow10(shift))
+	} else {
+		den = new(big.Int).Mul(den, pow10(-shift))
+	}
+	return Decimal{
+		unscaled: quoRound(num, den),
+		scale:    scale,
+	}
+}
+
+// Round
+// rounds half away from zero to scale, such as 2.345 to 2.35 and -2.345 to -2.35.
+// trailing zeros are added when scale is greater than the current one.
+func (d Decimal) Round(scale int32) Decimal {
+	if scale >= d.scale {
+		return Decimal{
+			unscaled: d.rescale(scale),
+			scale:    scale,
+		}
+	}
+	return Decimal{
+		unscaled: quoRound(d.value(), pow10(d.scale-scale)),
+		scale:    scale,
+	}
+}
+
+// Truncate
+// drops digits after scale without rounding.
+func (d Decimal) Truncate(scale int32) Decimal {
+	if scale >= d.scale {
+		return d.Round(scale)
+	}
+	return Decimal{
+		unscaled: new(big.Int).Quo(d.value(), pow10(d.scale-scale)),
+		scale:    scale,
+	}
+}
+
+func quoRound(num *big.Int, den *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	// |2r| >= |den| means half or more
+	r2 := new(big.Int).Abs(r)
+	r2.Lsh(r2, 1)
+	if r2.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if num.Sign()*den.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.value()).String()
+	if d.scale > 0 {
+		if n := int(d.scale) + 1 - len(s); n > 0 {
+			s = strings.Repeat("0", n) + s
+		}
+		s = s[:len(s)-int(d.scale)] + "." + s[len(s)-int(d.scale):]
+	}
+	if d.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// Float64
+// may lose precision, use it only for display or statistics.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// MarshalJSON
+// encodes as string to keep precision in javascript clients.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON
+// accepts string and number, null and empty string are zero.
+func (d *Decimal) UnmarshalJSON(p []byte) (err error) {
+	p = bytes.TrimSpace(p)
+	if len(p) == 0 || string(p) == "null" {
+		*d = Zero
+		return
+	}
+	s := string(p)
+	if p[0] == '"' {
+		s, err = strconv.Unquote(s)
+		if err != nil {
+			err = fmt.Errorf("decimals: %s is not a decimal", string(p))
+			return
+		}
+		if strings.TrimSpace(s) == "" {
+			*d = Zero
+			return
+		}
+	}
+	*d, err = Parse(s)
+	return
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(p []byte) (err error) {
+	*d, err = Parse(string(p))
+	return
+}
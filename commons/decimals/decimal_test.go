@@ -0,0 +1,119 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package decimals_test
+
+import (
+	"github.com/aacfactory/fns/commons/decimals"
+	"github.com/aacfactory/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for src, expect := range map[string]string{
+		"1.50":     "1.50",
+		"-0.05":    "-0.05",
+		"+3":       "3",
+		".5":       "0.5",
+		"2.":       "2",
+		"1.2e3":    "1200",
+		"-12.5e-2": "-0.125",
+		"0.000":    "0.000",
+	} {
+		d, err := decimals.Parse(src)
+		if err != nil {
+			t.Fatal(src, err)
+		}
+		if d.String() != expect {
+			t.Fatal("unexpected", src, d.String(), expect)
+		}
+	}
+	for _, src := range []string{"", "-", ".", "1.2.3", "1a", "1e", "1e99999"} {
+		if _, err := decimals.Parse(src); err == nil {
+			t.Fatal("invalid decimal was parsed", src)
+		}
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	a := decimals.MustParse("0.1")
+	b := decimals.MustParse("0.2")
+	if sum := a.Add(b); sum.String() != "0.3" || !sum.Equal(decimals.MustParse("0.30")) {
+		t.Fatal("unexpected sum", sum)
+	}
+	if diff := a.Sub(b); diff.String() != "-0.1" || diff.Sign() >= 0 {
+		t.Fatal("unexpected diff", diff)
+	}
+	price := decimals.MustParse("19.99")
+	if total := price.Mul(decimals.FromInt(3)); total.String() != "59.97" {
+		t.Fatal("unexpected total", total)
+	}
+	if q := decimals.FromInt(10).Div(decimals.FromInt(3), 2); q.String() != "3.33" {
+		t.Fatal("unexpected quotient", q)
+	}
+	if q := decimals.FromInt(-2).Div(decimals.FromInt(3), 2); q.String() != "-0.67" {
+		t.Fatal("unexpected quotient", q)
+	}
+	for src, expect := range map[string]string{
+		"2.345":  "2.35",
+		"-2.345": "-2.35",
+		"2.344":  "2.34",
+		"2.5":    "2.50",
+		"-0.001": "0.00",
+	} {
+		if r := decimals.MustParse(src).Round(2); r.String() != expect {
+			t.Fatal("unexpected round", src, r, expect)
+		}
+	}
+	if r := decimals.MustParse("-2.349").Truncate(2); r.String() != "-2.34" {
+		t.Fatal("unexpected truncate", r)
+	}
+	if !decimals.Zero.IsZero() || decimals.Zero.String() != "0" {
+		t.Fatal("zero value is not zero")
+	}
+	if decimals.FromFloat(0.1).String() != "0.1" {
+		t.Fatal("unexpected float")
+	}
+}
+
+type Price struct {
+	Amount decimals.Decimal `json:"amount"`
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	p, err := json.Marshal(Price{Amount: decimals.MustParse("-10.50")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(p) != `{"amount":"-10.50"}` {
+		t.Fatal("unexpected", string(p))
+	}
+	for src, expect := range map[string]string{
+		`{"amount":"-10.50"}`:             "-10.50",
+		`{"amount":12345678901234567.89}`: "12345678901234567.89",
+		`{"amount":null}`:                 "0",
+		`{"amount":""}`:                   "0",
+	} {
+		v := Price{}
+		if err = json.Unmarshal([]byte(src), &v); err != nil {
+			t.Fatal(src, err)
+		}
+		if v.Amount.String() != expect {
+			t.Fatal("unexpected", src, v.Amount, expect)
+		}
+	}
+}
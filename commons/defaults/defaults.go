@@ -0,0 +1,224 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package defaults
+// fills struct fields by `default` tag, it is applied before unmarshalling,
+// so field which is absent in json keeps the default.
+// field which is null in json is not zeroed by Fill, it follows json unmarshalling of github.com/aacfactory/json,
+// pointer, slice and map are set to nil, string is set to empty, numbers, bools and structs keep the default.
+// so use a pointer when null must be told from absent.
+//
+//	type Param struct {
+//		Size    int           `json:"size" default:"20"`
+//		Timeout time.Duration `json:"timeout" default:"1s"`
+//		Tags    []string      `json:"tags" default:"[\"a\"]"`
+//	}
+package defaults
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	plans        = sync.Map{}
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+type field struct {
+	index   []int
+	value   reflect.Value
+	literal string
+	shared  bool
+	nested  *plan
+}
+
+type plan struct {
+	fields []field
+}
+
+func (p *plan) empty() bool {
+	return p == nil || len(p.fields) == 0
+}
+
+// Fill
+// sets defaults into dst, dst must be a pointer of struct, others are ignored.
+func Fill(dst any) (err error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return
+	}
+	p, planErr := load(rv.Elem().Type())
+	if planErr != nil {
+		err = errors.Warning("fns: fill defaults failed").WithCause(planErr).WithMeta("type", rv.Elem().Type().String())
+		return
+	}
+	if p.empty() {
+		return
+	}
+	p.fill(rv.Elem())
+	return
+}
+
+func (p *plan) fill(rv reflect.Value) {
+	for _, f := range p.fields {
+		fv := rv.FieldByIndex(f.index)
+		if f.nested != nil {
+			f.nested.fill(fv)
+			continue
+		}
+		if f.shared {
+			fv.Set(f.value)
+			continue
+		}
+		// value of reference kind is parsed every time, so that it is not shared between requests
+		if value, err := Parse(fv.Type(), f.literal); err == nil {
+			fv.Set(value)
+		}
+	}
+}
+
+type result struct {
+	plan *plan
+	err  error
+}
+
+func load(rt reflect.Type) (p *plan, err error) {
+	if cached, has := plans.Load(rt); has {
+		r := cached.(result)
+		p, err = r.plan, r.err
+		return
+	}
+	p, err = build(rt, 0)
+	plans.Store(rt, result{plan: p, err: err})
+	return
+}
+
+func build(rt reflect.Type, depth int) (p *plan, err error) {
+	if depth > 8 {
+		return
+	}
+	p = &plan{}
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		literal, has := sf.Tag.Lookup("default")
+		if !has {
+			if sf.Type.Kind() == reflect.Struct && sf.Type != reflect.TypeOf(time.Time{}) {
+				nested, nestedErr := build(sf.Type, depth+1)
+				if nestedErr != nil {
+					err = nestedErr
+					return
+				}
+				if !nested.empty() {
+					p.fields = append(p.fields, field{index: sf.Index, nested: nested})
+				}
+			}
+			continue
+		}
+		value, parseErr := Parse(sf.Type, literal)
+		if parseErr != nil {
+			err = fmt.Errorf("default of %s is invalid, %v", sf.Name, parseErr)
+			return
+		}
+		shared := true
+		switch sf.Type.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Struct, reflect.Interface:
+			shared = false
+			break
+		default:
+			break
+		}
+		p.fields = append(p.fields, field{index: sf.Index, value: value, literal: literal, shared: shared})
+	}
+	return
+}
+
+// Parse
+// coerces literal into value of typ.
+func Parse(typ reflect.Type, literal string) (v reflect.Value, err error) {
+	if typ.Kind() == reflect.Ptr {
+		elem, elemErr := Parse(typ.Elem(), literal)
+		if elemErr != nil {
+			err = elemErr
+			return
+		}
+		v = reflect.New(typ.Elem())
+		v.Elem().Set(elem)
+		return
+	}
+	v = reflect.New(typ).Elem()
+	if typ == durationType {
+		d, parseErr := time.ParseDuration(literal)
+		if parseErr != nil {
+			err = parseErr
+			return
+		}
+		v.SetInt(int64(d))
+		return
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(literal)
+		break
+	case reflect.Bool:
+		b, parseErr := strconv.ParseBool(literal)
+		if parseErr != nil {
+			err = parseErr
+			return
+		}
+		v.SetBool(b)
+		break
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, parseErr := strconv.ParseInt(literal, 10, typ.Bits())
+		if parseErr != nil {
+			err = parseErr
+			return
+		}
+		v.SetInt(n)
+		break
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, parseErr := strconv.ParseUint(literal, 10, typ.Bits())
+		if parseErr != nil {
+			err = parseErr
+			return
+		}
+		v.SetUint(n)
+		break
+	case reflect.Float32, reflect.Float64:
+		n, parseErr := strconv.ParseFloat(literal, typ.Bits())
+		if parseErr != nil {
+			err = parseErr
+			return
+		}
+		v.SetFloat(n)
+		break
+	default:
+		if err = json.Unmarshal([]byte(literal), v.Addr().Interface()); err != nil {
+			return
+		}
+		break
+	}
+	return
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package defaults_test
+
+import (
+	"github.com/aacfactory/fns/commons/defaults"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/json"
+	"testing"
+	"time"
+)
+
+type Page struct {
+	Size int `json:"size" default:"20"`
+}
+
+type Param struct {
+	Name    string        `json:"name" default:"anonymous"`
+	Age     int           `json:"age" default:"18"`
+	Nick    *string       `json:"nick" default:"nick"`
+	Timeout time.Duration `json:"timeout" default:"1s"`
+	Tags    []string      `json:"tags" default:"[\"a\",\"b\"]"`
+	Page    Page          `json:"page"`
+}
+
+func TestFill(t *testing.T) {
+	// absent
+	v, err := services.ValueOfParam[Param](services.NewParam(json.RawMessage(`{}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "anonymous" || v.Age != 18 || v.Nick == nil || *v.Nick != "nick" || v.Timeout != time.Second || len(v.Tags) != 2 || v.Page.Size != 20 {
+		t.Fatal("absent fields must be defaults", v)
+	}
+	// zero value
+	v, err = services.ValueOfParam[Param](services.NewParam(json.RawMessage(`{"name":"","age":0,"tags":[],"page":{"size":0}}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "" || v.Age != 0 || len(v.Tags) != 0 || v.Page.Size != 0 {
+		t.Fatal("explicit zero values must be kept", v)
+	}
+	// explicit null
+	v, err = services.ValueOfParam[Param](services.NewParam(json.RawMessage(`{"nick":null,"tags":null}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Nick != nil || v.Tags != nil || v.Age != 18 {
+		t.Fatal("explicit null must be nil", v)
+	}
+	// explicit null of non pointer keeps the default, except string
+	v, err = services.ValueOfParam[Param](services.NewParam(json.RawMessage(`{"name":null,"age":null,"timeout":null,"page":null}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Age != 18 || v.Timeout != time.Second || v.Page.Size != 20 {
+		t.Fatal("explicit null of non pointer must keep the default", v)
+	}
+	if v.Name != "" {
+		t.Fatal("explicit null of string must be empty", v)
+	}
+	// reference values are not shared
+	a, b := Param{}, Param{}
+	_ = defaults.Fill(&a)
+	_ = defaults.Fill(&b)
+	a.Tags[0] = "x"
+	*a.Nick = "x"
+	if b.Tags[0] != "a" || *b.Nick != "nick" {
+		t.Fatal("default of reference kind must not be shared")
+	}
+}
+
+type Invalid struct {
+	Age int `json:"age" default:"eighteen"`
+}
+
+func TestFillInvalid(t *testing.T) {
+	if err := defaults.Fill(&Invalid{}); err == nil {
+		t.Fatal("invalid default must fail")
+	}
+}
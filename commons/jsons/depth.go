@@ -0,0 +1,128 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package jsons
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+const (
+	DefaultMaxDepth = 64
+)
+
+var (
+	// ErrTooDeep
+	// is returned when objects and arrays of json are nested deeper than the max depth.
+	ErrTooDeep = errors.New("jsons: json is nested too deeply")
+	maxDepth   = new(atomic.Int64)
+)
+
+func init() {
+	maxDepth.Store(DefaultMaxDepth)
+}
+
+// SetMaxDepth
+// sets the max nesting depth of json which Unmarshal accepts, n less than 1 resets it to DefaultMaxDepth.
+func SetMaxDepth(n int) {
+	if n < 1 {
+		n = DefaultMaxDepth
+	}
+	maxDepth.Store(int64(n))
+}
+
+func MaxDepth() int {
+	return int(maxDepth.Load())
+}
+
+// CheckDepth
+// scans p without decoding and returns ErrTooDeep when nesting of it is deeper than MaxDepth.
+// p is not validated, so unbalanced json is left to decoder.
+func CheckDepth(p []byte) (err error) {
+	scanner := depthScanner{limit: maxDepth.Load()}
+	err = scanner.scan(p)
+	return
+}
+
+// depthScanner
+// keeps state between scans, so json which is read in pieces is checked as a whole.
+type depthScanner struct {
+	limit    int64
+	depth    int64
+	inString bool
+	escaped  bool
+}
+
+func (scanner *depthScanner) scan(p []byte) (err error) {
+	for _, c := range p {
+		if scanner.inString {
+			if scanner.escaped {
+				scanner.escaped = false
+			} else if c == '\\' {
+				scanner.escaped = true
+			} else if c == '"' {
+				scanner.inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			scanner.inString = true
+			break
+		case '{', '[':
+			scanner.depth++
+			if scanner.depth > scanner.limit {
+				err = fmt.Errorf("%w, max depth is %d", ErrTooDeep, scanner.limit)
+				return
+			}
+			break
+		case '}', ']':
+			scanner.depth--
+			break
+		default:
+			break
+		}
+	}
+	return
+}
+
+// depthReader
+// fails with ErrTooDeep once json read from reader is nested deeper than limit of scanner.
+type depthReader struct {
+	reader  io.Reader
+	scanner depthScanner
+	err     error
+}
+
+func (r *depthReader) Read(p []byte) (n int, err error) {
+	if r.err != nil {
+		err = r.err
+		return
+	}
+	n, err = r.reader.Read(p)
+	if n > 0 {
+		if scanErr := r.scanner.scan(p[:n]); scanErr != nil {
+			r.err = scanErr
+			n = 0
+			err = scanErr
+		}
+	}
+	return
+}
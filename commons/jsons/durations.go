@@ -0,0 +1,192 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package jsons
+
+import (
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// DurationFormat
+// is how time.Duration is encoded by the default encoder.
+type DurationFormat string
+
+const (
+	// StringDurationFormat
+	// encodes time.Duration as string such as 1h30m, it is the default one.
+	StringDurationFormat = DurationFormat("string")
+	// NanosecondDurationFormat
+	// encodes time.Duration as number of nanoseconds, it is same as encoding/json.
+	NanosecondDurationFormat = DurationFormat("nanosecond")
+	// MillisecondDurationFormat
+	// encodes time.Duration as number of milliseconds.
+	MillisecondDurationFormat = DurationFormat("millisecond")
+	// SecondDurationFormat
+	// encodes time.Duration as number of seconds.
+	SecondDurationFormat = DurationFormat("second")
+)
+
+func ParseDurationFormat(s string) (format DurationFormat, err error) {
+	switch DurationFormat(strings.ToLower(strings.TrimSpace(s))) {
+	case "", StringDurationFormat:
+		format = StringDurationFormat
+		break
+	case NanosecondDurationFormat:
+		format = NanosecondDurationFormat
+		break
+	case MillisecondDurationFormat:
+		format = MillisecondDurationFormat
+		break
+	case SecondDurationFormat:
+		format = SecondDurationFormat
+		break
+	default:
+		err = fmt.Errorf("duration format %s is unsupported, string, nanosecond, millisecond and second are supported", s)
+		break
+	}
+	return
+}
+
+// Unit
+// returns the duration of one number, it is zero when format is StringDurationFormat.
+func (format DurationFormat) Unit() (unit time.Duration) {
+	switch format {
+	case NanosecondDurationFormat:
+		unit = time.Nanosecond
+		break
+	case MillisecondDurationFormat:
+		unit = time.Millisecond
+		break
+	case SecondDurationFormat:
+		unit = time.Second
+		break
+	default:
+		break
+	}
+	return
+}
+
+var (
+	durationFormat = new(atomic.Value)
+)
+
+func init() {
+	durationFormat.Store(StringDurationFormat)
+	jsoniter.RegisterTypeEncoderFunc("time.Duration", encodeDuration, isZeroDuration)
+	jsoniter.RegisterTypeDecoderFunc("time.Duration", decodeDuration)
+}
+
+// SetDurationFormat
+// sets the encoding format of time.Duration, empty format resets it to StringDurationFormat.
+// decoding accepts both string and number whatever the format is, numbers are in unit of the format.
+func SetDurationFormat(format DurationFormat) {
+	if format == "" {
+		format = StringDurationFormat
+	}
+	durationFormat.Store(format)
+}
+
+func CurrentDurationFormat() DurationFormat {
+	return durationFormat.Load().(DurationFormat)
+}
+
+func isZeroDuration(ptr unsafe.Pointer) bool {
+	return *(*time.Duration)(ptr) == 0
+}
+
+func encodeDuration(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	v := *(*time.Duration)(ptr)
+	format := CurrentDurationFormat()
+	unit := format.Unit()
+	if unit == 0 {
+		stream.WriteString(v.String())
+		return
+	}
+	if v%unit == 0 {
+		stream.WriteInt64(int64(v / unit))
+		return
+	}
+	stream.WriteFloat64(float64(v) / float64(unit))
+}
+
+func decodeDuration(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	switch iter.WhatIsNext() {
+	case jsoniter.NilValue:
+		iter.Skip()
+		break
+	case jsoniter.NumberValue:
+		n := iter.ReadNumber()
+		if iter.Error != nil {
+			return
+		}
+		v, parseErr := parseDurationNumber(string(n))
+		if parseErr != nil {
+			iter.ReportError("unmarshal time.Duration", parseErr.Error())
+			return
+		}
+		*(*time.Duration)(ptr) = v
+		break
+	case jsoniter.StringValue:
+		s := strings.TrimSpace(iter.ReadString())
+		if iter.Error != nil {
+			return
+		}
+		if s == "" {
+			*(*time.Duration)(ptr) = 0
+			break
+		}
+		v, parseErr := time.ParseDuration(s)
+		if parseErr != nil {
+			// such as "90", it is in unit of format
+			n, numberErr := parseDurationNumber(s)
+			if numberErr != nil {
+				iter.ReportError("unmarshal time.Duration", parseErr.Error())
+				return
+			}
+			v = n
+		}
+		*(*time.Duration)(ptr) = v
+		break
+	default:
+		iter.ReportError("unmarshal time.Duration", "value is neither string nor number")
+		break
+	}
+}
+
+func parseDurationNumber(s string) (v time.Duration, err error) {
+	unit := CurrentDurationFormat().Unit()
+	if unit == 0 {
+		unit = time.Nanosecond
+	}
+	if n, intErr := strconv.ParseInt(s, 10, 64); intErr == nil {
+		v = time.Duration(n) * unit
+		return
+	}
+	f, floatErr := strconv.ParseFloat(s, 64)
+	if floatErr != nil {
+		err = fmt.Errorf("%s is not a duration", s)
+		return
+	}
+	v = time.Duration(f * float64(unit))
+	return
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package jsons
+
+import (
+	"github.com/aacfactory/json"
+	"io"
+	"sync/atomic"
+)
+
+// Encoder
+// json encoding used by transport results, endpoints handler and cluster discovery.
+// the default one is github.com/aacfactory/json, use Use to replace it with a faster one,
+// such as sonic on amd64 in a file guarded by build tag.
+type Encoder interface {
+	Marshal(v any) (p []byte, err error)
+	Unmarshal(p []byte, v any) (err error)
+}
+
+// StreamDecoder
+// is optional for Encoder, it decodes json from reader without buffering it as a whole.
+// encoder which does not implement it is fed with the whole content of reader, see Decode.
+type StreamDecoder interface {
+	Decode(r io.Reader, v any) (err error)
+}
+
+type defaultEncoder struct{}
+
+func (encoder defaultEncoder) Marshal(v any) (p []byte, err error) {
+	p, err = json.Marshal(v)
+	return
+}
+
+func (encoder defaultEncoder) Unmarshal(p []byte, v any) (err error) {
+	err = json.Unmarshal(p, v)
+	return
+}
+
+func (encoder defaultEncoder) Decode(r io.Reader, v any) (err error) {
+	err = json.Default().NewDecoder(r).Decode(v)
+	return
+}
+
+type holder struct {
+	encoder Encoder
+}
+
+var encoder atomic.Pointer[holder]
+
+func init() {
+	encoder.Store(&holder{encoder: defaultEncoder{}})
+}
+
+// Use
+// replaces encoder, it should be called before application is created.
+// encoder must support json.RawMessage and types of github.com/aacfactory/json.
+func Use(e Encoder) {
+	if e == nil {
+		e = defaultEncoder{}
+	}
+	encoder.Store(&holder{encoder: e})
+}
+
+func Marshal(v any) (p []byte, err error) {
+	p, err = encoder.Load().encoder.Marshal(v)
+	return
+}
+
+// Unmarshal
+// returns ErrTooDeep when p is nested deeper than MaxDepth, see SetMaxDepth.
+func Unmarshal(p []byte, v any) (err error) {
+	if err = CheckDepth(p); err != nil {
+		return
+	}
+	err = encoder.Load().encoder.Unmarshal(p, v)
+	return
+}
+
+// Decode
+// decodes one json value from r, and returns ErrTooDeep when it is nested deeper than MaxDepth, see SetMaxDepth.
+func Decode(r io.Reader, v any) (err error) {
+	dr := &depthReader{
+		reader:  r,
+		scanner: depthScanner{limit: maxDepth.Load()},
+	}
+	if decoder, ok := encoder.Load().encoder.(StreamDecoder); ok {
+		err = decoder.Decode(dr, v)
+	} else {
+		p, readErr := io.ReadAll(dr)
+		if readErr != nil {
+			err = readErr
+		} else {
+			err = encoder.Load().encoder.Unmarshal(p, v)
+		}
+	}
+	if err != nil && dr.err != nil {
+		// decoder may hide error of reader
+		err = dr.err
+	}
+	return
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package jsons_test
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"github.com/aacfactory/fns/commons/jsons"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sample struct {
+	Id       string            `json:"id"`
+	Name     string            `json:"name"`
+	Age      int               `json:"age"`
+	Tags     []string          `json:"tags"`
+	Attrs    map[string]string `json:"attrs"`
+	CreateAt time.Time         `json:"createAt"`
+}
+
+var sampleValue = sample{
+	Id:       "1",
+	Name:     "name",
+	Age:      18,
+	Tags:     []string{"a", "b", "c"},
+	Attrs:    map[string]string{"k1": "v1", "k2": "v2"},
+	CreateAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+type stdEncoder struct{}
+
+func (encoder stdEncoder) Marshal(v any) ([]byte, error) {
+	return stdjson.Marshal(v)
+}
+
+func (encoder stdEncoder) Unmarshal(p []byte, v any) error {
+	return stdjson.Unmarshal(p, v)
+}
+
+func TestUse(t *testing.T) {
+	defer jsons.Use(nil)
+	jsons.Use(stdEncoder{})
+	p, err := jsons.Marshal(sampleValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := sample{}
+	if err = jsons.Unmarshal(p, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != sampleValue.Name || !v.CreateAt.Equal(sampleValue.CreateAt) {
+		t.Fatal("invalid value", v)
+	}
+}
+
+func benchmarkEncoder(b *testing.B, encoder jsons.Encoder) {
+	defer jsons.Use(nil)
+	jsons.Use(encoder)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p, _ := jsons.Marshal(sampleValue)
+		v := sample{}
+		_ = jsons.Unmarshal(p, &v)
+	}
+}
+
+func BenchmarkDefault(b *testing.B) {
+	benchmarkEncoder(b, nil)
+}
+
+func BenchmarkStd(b *testing.B) {
+	benchmarkEncoder(b, stdEncoder{})
+}
+
+type timeFormatSample struct {
+	CreateAt time.Time  `json:"createAt"`
+	UpdateAt time.Time  `json:"updateAt" timeFormat:"unixmilli"`
+	DeleteAt *time.Time `json:"deleteAt,omitempty" timeFormat:"rfc3339"`
+}
+
+func TestSetTimeFormat(t *testing.T) {
+	defer jsons.SetTimeFormat("")
+	jsons.SetTimeFormat(jsons.UnixTimeFormat)
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p, err := jsons.Marshal(timeFormatSample{CreateAt: at, UpdateAt: at, DeleteAt: &at})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(p) != `{"createAt":1704067200,"updateAt":1704067200000,"deleteAt":"2024-01-01T00:00:00Z"}` {
+		t.Fatal("unexpected", string(p))
+	}
+	v := timeFormatSample{}
+	if err = jsons.Unmarshal(p, &v); err != nil {
+		t.Fatal(err)
+	}
+	if !v.CreateAt.Equal(at) || !v.UpdateAt.Equal(at) || v.DeleteAt == nil || !v.DeleteAt.Equal(at) {
+		t.Fatal("invalid value", v)
+	}
+	if err = jsons.Unmarshal([]byte(`{"createAt":"2024-01-01T00:00:00Z"}`), &v); err == nil {
+		t.Fatal("rfc3339 should be rejected in unix format")
+	}
+}
+
+type durationSample struct {
+	Timeout time.Duration `json:"timeout"`
+}
+
+func TestSetDurationFormat(t *testing.T) {
+	p, err := jsons.Marshal(durationSample{Timeout: 90 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(p) != `{"timeout":"1h30m0s"}` {
+		t.Fatal("unexpected", string(p))
+	}
+	defer jsons.SetDurationFormat("")
+	jsons.SetDurationFormat(jsons.MillisecondDurationFormat)
+	if p, err = jsons.Marshal(durationSample{Timeout: 1500 * time.Microsecond}); err != nil {
+		t.Fatal(err)
+	}
+	if string(p) != `{"timeout":1.5}` {
+		t.Fatal("unexpected", string(p))
+	}
+	for src, expect := range map[string]time.Duration{
+		`{"timeout":"1h30m"}`: 90 * time.Minute,
+		`{"timeout":1500}`:    1500 * time.Millisecond,
+		`{"timeout":"1500"}`:  1500 * time.Millisecond,
+		`{"timeout":""}`:      0,
+	} {
+		v := durationSample{}
+		if err = jsons.Unmarshal([]byte(src), &v); err != nil {
+			t.Fatal(src, err)
+		}
+		if v.Timeout != expect {
+			t.Fatal("unexpected", src, v.Timeout)
+		}
+	}
+}
+
+func TestCheckDepth(t *testing.T) {
+	defer jsons.SetMaxDepth(0)
+	jsons.SetMaxDepth(3)
+	if err := jsons.CheckDepth([]byte(`{"a":[{"b":"[[[[{{{{"}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	deep := []byte(strings.Repeat("[", 4) + strings.Repeat("]", 4))
+	if err := jsons.CheckDepth(deep); !errors.Is(err, jsons.ErrTooDeep) {
+		t.Fatal("over-deep json was accepted", err)
+	}
+	var v any
+	if err := jsons.Unmarshal(deep, &v); !errors.Is(err, jsons.ErrTooDeep) {
+		t.Fatal("over-deep json was decoded", err)
+	}
+}
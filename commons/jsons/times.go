@@ -0,0 +1,262 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package jsons
+
+import (
+	"fmt"
+	jsoniter "github.com/json-iterator/go"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// TimeFormat
+// is how time.Time is encoded by the default encoder.
+type TimeFormat string
+
+const (
+	// RFC3339TimeFormat
+	// encodes time.Time as string such as 2022-01-10T19:13:07+08:00, it is the default one.
+	RFC3339TimeFormat = TimeFormat("rfc3339")
+	// UnixTimeFormat
+	// encodes time.Time as number of seconds since epoch.
+	UnixTimeFormat = TimeFormat("unix")
+	// UnixMilliTimeFormat
+	// encodes time.Time as number of milliseconds since epoch.
+	UnixMilliTimeFormat = TimeFormat("unixmilli")
+)
+
+const (
+	// TimeFormatTag
+	// is the struct tag to override the time format of one time.Time or *time.Time field, such as `timeFormat:"unixmilli"`.
+	TimeFormatTag = "timeFormat"
+)
+
+func ParseTimeFormat(s string) (format TimeFormat, err error) {
+	switch TimeFormat(strings.ToLower(strings.TrimSpace(s))) {
+	case "", RFC3339TimeFormat:
+		format = RFC3339TimeFormat
+		break
+	case UnixTimeFormat:
+		format = UnixTimeFormat
+		break
+	case UnixMilliTimeFormat:
+		format = UnixMilliTimeFormat
+		break
+	default:
+		err = fmt.Errorf("time format %s is unsupported, rfc3339, unix and unixmilli are supported", s)
+		break
+	}
+	return
+}
+
+var (
+	timeFormat  = new(atomic.Value)
+	timeType    = reflect.TypeOf(time.Time{})
+	timePtrType = reflect.TypeOf(&time.Time{})
+)
+
+func init() {
+	timeFormat.Store(RFC3339TimeFormat)
+	jsoniter.RegisterTypeEncoderFunc("time.Time", func(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+		encodeTime(CurrentTimeFormat(), ptr, stream)
+	}, isZeroTime)
+	jsoniter.RegisterTypeDecoderFunc("time.Time", func(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+		decodeTime(CurrentTimeFormat(), ptr, iter)
+	})
+	jsoniter.RegisterExtension(&timeFormatExtension{})
+}
+
+// SetTimeFormat
+// sets the time format of time.Time which has no timeFormat tag, empty format resets it to RFC3339TimeFormat.
+// it takes effect on encoding and decoding at once, but encoder replaced by Use may ignore it.
+func SetTimeFormat(format TimeFormat) {
+	if format == "" {
+		format = RFC3339TimeFormat
+	}
+	timeFormat.Store(format)
+}
+
+func CurrentTimeFormat() TimeFormat {
+	return timeFormat.Load().(TimeFormat)
+}
+
+func isZeroTime(ptr unsafe.Pointer) bool {
+	return (*time.Time)(ptr).IsZero()
+}
+
+func encodeTime(format TimeFormat, ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	v := *(*time.Time)(ptr)
+	switch format {
+	case UnixTimeFormat:
+		if v.IsZero() {
+			stream.WriteInt64(0)
+			break
+		}
+		stream.WriteInt64(v.Unix())
+		break
+	case UnixMilliTimeFormat:
+		if v.IsZero() {
+			stream.WriteInt64(0)
+			break
+		}
+		stream.WriteInt64(v.UnixMilli())
+		break
+	default:
+		if v.IsZero() {
+			stream.WriteString("")
+			break
+		}
+		stream.WriteString(v.Format(time.RFC3339))
+		break
+	}
+}
+
+func decodeTime(format TimeFormat, ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	switch iter.WhatIsNext() {
+	case jsoniter.NilValue:
+		iter.Skip()
+		break
+	case jsoniter.NumberValue:
+		n := iter.ReadInt64()
+		if iter.Error != nil {
+			return
+		}
+		if n == 0 {
+			*(*time.Time)(ptr) = time.Time{}
+			break
+		}
+		switch format {
+		case UnixTimeFormat:
+			*(*time.Time)(ptr) = time.Unix(n, 0)
+			break
+		case UnixMilliTimeFormat:
+			*(*time.Time)(ptr) = time.UnixMilli(n)
+			break
+		default:
+			iter.ReportError("unmarshal time.Time", fmt.Sprintf("number is not %s format", format))
+			break
+		}
+		break
+	case jsoniter.StringValue:
+		s := iter.ReadString()
+		if iter.Error != nil {
+			return
+		}
+		if s == "" {
+			*(*time.Time)(ptr) = time.Time{}
+			break
+		}
+		if format != RFC3339TimeFormat {
+			iter.ReportError("unmarshal time.Time", fmt.Sprintf("string is not %s format", format))
+			break
+		}
+		v, parseErr := time.Parse(time.RFC3339, s)
+		if parseErr != nil {
+			iter.ReportError("unmarshal time.Time", parseErr.Error())
+			break
+		}
+		*(*time.Time)(ptr) = v
+		break
+	default:
+		iter.ReportError("unmarshal time.Time", "value is neither string nor number")
+		break
+	}
+}
+
+type timeFormatCodec struct {
+	format TimeFormat
+}
+
+func (codec *timeFormatCodec) IsEmpty(ptr unsafe.Pointer) bool {
+	return isZeroTime(ptr)
+}
+
+func (codec *timeFormatCodec) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	encodeTime(codec.format, ptr, stream)
+}
+
+func (codec *timeFormatCodec) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	decodeTime(codec.format, ptr, iter)
+}
+
+type timePtrFormatCodec struct {
+	format TimeFormat
+}
+
+func (codec *timePtrFormatCodec) IsEmpty(ptr unsafe.Pointer) bool {
+	return *(**time.Time)(ptr) == nil
+}
+
+func (codec *timePtrFormatCodec) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
+	v := *(**time.Time)(ptr)
+	if v == nil {
+		stream.WriteNil()
+		return
+	}
+	encodeTime(codec.format, unsafe.Pointer(v), stream)
+}
+
+func (codec *timePtrFormatCodec) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
+	if iter.ReadNil() {
+		*(**time.Time)(ptr) = nil
+		return
+	}
+	v := new(time.Time)
+	decodeTime(codec.format, unsafe.Pointer(v), iter)
+	*(**time.Time)(ptr) = v
+}
+
+// timeFormatExtension
+// replaces codec of time.Time fields which have timeFormat tag.
+type timeFormatExtension struct {
+	jsoniter.DummyExtension
+}
+
+func (extension *timeFormatExtension) UpdateStructDescriptor(descriptor *jsoniter.StructDescriptor) {
+	for _, binding := range descriptor.Fields {
+		fieldType := binding.Field.Type().Type1()
+		if fieldType != timeType && fieldType != timePtrType {
+			continue
+		}
+		tag, has := binding.Field.Tag().Lookup(TimeFormatTag)
+		if !has {
+			continue
+		}
+		format, formatErr := ParseTimeFormat(tag)
+		if formatErr != nil {
+			panic(fmt.Errorf("fns: invalid %s tag of %s.%s, %v", TimeFormatTag, descriptor.Type.String(), binding.Field.Name(), formatErr))
+			return
+		}
+		if fieldType == timePtrType {
+			codec := &timePtrFormatCodec{
+				format: format,
+			}
+			binding.Encoder = codec
+			binding.Decoder = codec
+			continue
+		}
+		codec := &timeFormatCodec{
+			format: format,
+		}
+		binding.Encoder = codec
+		binding.Decoder = codec
+	}
+}
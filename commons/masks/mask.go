@@ -0,0 +1,79 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package masks
+
+import (
+	"strings"
+)
+
+const (
+	Value = "******"
+)
+
+var (
+	defaultKeys = []string{"password", "passwd", "secret", "token", "accessToken", "refreshToken", "authorization"}
+)
+
+// Keys
+// field names whose values are sensitive, names are case-insensitive.
+type Keys map[string]struct{}
+
+// New
+// returns default keys with extra ones,
+// defaults are password, passwd, secret, token, accessToken, refreshToken and authorization.
+func New(extra ...string) (keys Keys) {
+	keys = make(Keys, len(defaultKeys)+len(extra))
+	for _, key := range defaultKeys {
+		keys[strings.ToLower(key)] = struct{}{}
+	}
+	for _, key := range extra {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			keys[key] = struct{}{}
+		}
+	}
+	return
+}
+
+// Mask
+// replaces values of keys in v which is decoded from json, such as map[string]any and []any, with Value.
+func (keys Keys) Mask(v any) (masked bool) {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, item := range value {
+			if _, has := keys[strings.ToLower(key)]; has {
+				value[key] = Value
+				masked = true
+				continue
+			}
+			if keys.Mask(item) {
+				masked = true
+			}
+		}
+		break
+	case []any:
+		for _, item := range value {
+			if keys.Mask(item) {
+				masked = true
+			}
+		}
+		break
+	default:
+		break
+	}
+	return
+}
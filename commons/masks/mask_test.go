@@ -0,0 +1,49 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package masks_test
+
+import (
+	"github.com/aacfactory/fns/commons/masks"
+	"reflect"
+	"testing"
+)
+
+func TestKeys_Mask(t *testing.T) {
+	keys := masks.New("Pin")
+	v := map[string]any{
+		"name":     "a",
+		"Password": "p",
+		"pin":      "1",
+		"items":    []any{map[string]any{"accessToken": "t", "n": 1}},
+	}
+	if !keys.Mask(v) {
+		t.Fatal("v must be masked")
+	}
+	expect := map[string]any{
+		"name":     "a",
+		"Password": masks.Value,
+		"pin":      masks.Value,
+		"items":    []any{map[string]any{"accessToken": masks.Value, "n": 1}},
+	}
+	if !reflect.DeepEqual(v, expect) {
+		t.Fatal("unexpected", v)
+	}
+	if keys.Mask(map[string]any{"name": "a"}) || keys.Mask("password") {
+		t.Fatal("nothing must be masked")
+	}
+}
@@ -94,6 +94,16 @@ func (obj object) Unmarshal(dst interface{}) (err error) {
 	return
 }
 
+// TryValue
+// returns value of obj when it holds a T, obj is never unmarshalled.
+func TryValue[T any](obj Object) (v T, ok bool) {
+	o, isObject := obj.(object)
+	if isObject {
+		v, ok = o.value.(T)
+	}
+	return
+}
+
 func Value[T any](obj Object) (v T, err error) {
 	o, ok := obj.(object)
 	if ok {
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package uuids
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UUID
+// RFC 4122 uuid, it is encoded as canonical string such as 6ba7b810-9dad-11d1-80b4-00c04fd430c8.
+// decoding a malformed string fails, so fn which takes it as param responds 400 without more validation.
+type UUID [16]byte
+
+var (
+	Nil = UUID{}
+)
+
+// New
+// returns a random (version 4) uuid.
+func New() (id UUID) {
+	if _, err := rand.Read(id[:]); err != nil {
+		panic(fmt.Errorf("uuids: read random failed, %v", err))
+		return
+	}
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+	return
+}
+
+// NewV7
+// returns a time ordered (version 7) uuid, it is better than New for primary key of database.
+func NewV7() (id UUID) {
+	if _, err := rand.Read(id[6:]); err != nil {
+		panic(fmt.Errorf("uuids: read random failed, %v", err))
+		return
+	}
+	ms := uint64(time.Now().UnixMilli())
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], ms)
+	copy(id[0:6], ts[2:])
+	id[6] = (id[6] & 0x0f) | 0x70
+	id[8] = (id[8] & 0x3f) | 0x80
+	return
+}
+
+// Parse
+// parses canonical string, upper case, {...}, urn:uuid:... and 32 hex digits without hyphens are accepted too.
+func Parse(s string) (id UUID, err error) {
+	src := s
+	s = strings.TrimSpace(s)
+	if len(s) > 9 && strings.EqualFold(s[:9], "urn:uuid:") {
+		s = s[9:]
+	} else if len(s) == 38 && s[0] == '{' && s[37] == '}' {
+		s = s[1:37]
+	}
+	switch len(s) {
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			err = fmt.Errorf("uuids: %s is not a uuid", src)
+			return
+		}
+		s = s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+		break
+	case 32:
+		break
+	default:
+		err = fmt.Errorf("uuids: %s is not a uuid", src)
+		return
+	}
+	if _, decodeErr := hex.Decode(id[:], []byte(s)); decodeErr != nil {
+		id = Nil
+		err = fmt.Errorf("uuids: %s is not a uuid", src)
+		return
+	}
+	return
+}
+
+// MustParse
+// is Parse but panics when s is invalid.
+func MustParse(s string) UUID {
+	id, err := Parse(s)
+	if err != nil {
+		panic(err)
+		return Nil
+	}
+	return id
+}
+
+func (id UUID) IsNil() bool {
+	return id == Nil
+}
+
+func (id UUID) Version() int {
+	return int(id[6] >> 4)
+}
+
+func (id UUID) String() string {
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+	return string(buf)
+}
+
+func (id UUID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+func (id *UUID) UnmarshalText(p []byte) (err error) {
+	*id, err = Parse(string(p))
+	return
+}
+
+// MarshalJSON
+// Nil is encoded as empty string.
+func (id UUID) MarshalJSON() ([]byte, error) {
+	if id.IsNil() {
+		return []byte(`""`), nil
+	}
+	return []byte(strconv.Quote(id.String())), nil
+}
+
+// UnmarshalJSON
+// null and empty string are Nil, use `validate:"required"` to reject them.
+func (id *UUID) UnmarshalJSON(p []byte) (err error) {
+	p = bytes.TrimSpace(p)
+	if len(p) == 0 || string(p) == "null" {
+		*id = Nil
+		return
+	}
+	s, unquoteErr := strconv.Unquote(string(p))
+	if unquoteErr != nil {
+		err = fmt.Errorf("uuids: %s is not a uuid", string(p))
+		return
+	}
+	if s == "" {
+		*id = Nil
+		return
+	}
+	*id, err = Parse(s)
+	return
+}
+
+// Value
+// implements driver.Valuer, Nil is NULL.
+func (id UUID) Value() (driver.Value, error) {
+	if id.IsNil() {
+		return nil, nil
+	}
+	return id.String(), nil
+}
+
+// Scan
+// implements sql.Scanner, it accepts string and 16 bytes.
+func (id *UUID) Scan(src any) (err error) {
+	switch value := src.(type) {
+	case nil:
+		*id = Nil
+		break
+	case string:
+		if value == "" {
+			*id = Nil
+			break
+		}
+		*id, err = Parse(value)
+		break
+	case []byte:
+		if len(value) == 0 {
+			*id = Nil
+			break
+		}
+		if len(value) == 16 {
+			copy(id[:], value)
+			break
+		}
+		*id, err = Parse(string(value))
+		break
+	default:
+		err = fmt.Errorf("uuids: can not scan %T into uuid", src)
+		break
+	}
+	return
+}
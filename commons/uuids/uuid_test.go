@@ -0,0 +1,80 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package uuids_test
+
+import (
+	"github.com/aacfactory/fns/commons/uuids"
+	"github.com/aacfactory/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	const canonical = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	for _, src := range []string{
+		canonical,
+		"6BA7B810-9DAD-11D1-80B4-00C04FD430C8",
+		"{6ba7b810-9dad-11d1-80b4-00c04fd430c8}",
+		"urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"6ba7b8109dad11d180b400c04fd430c8",
+	} {
+		id, err := uuids.Parse(src)
+		if err != nil {
+			t.Fatal(src, err)
+		}
+		if id.String() != canonical {
+			t.Fatal("unexpected", src, id)
+		}
+	}
+	for _, src := range []string{"", "6ba7b810", "6ba7b810-9dad-11d1-80b4_00c04fd430c8", "zba7b810-9dad-11d1-80b4-00c04fd430c8"} {
+		if _, err := uuids.Parse(src); err == nil {
+			t.Fatal("invalid uuid was parsed", src)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	if id := uuids.New(); id.Version() != 4 || id.IsNil() {
+		t.Fatal("unexpected", id)
+	}
+	a, b := uuids.NewV7(), uuids.NewV7()
+	if a.Version() != 7 || a == b {
+		t.Fatal("unexpected", a, b)
+	}
+}
+
+type Order struct {
+	Id uuids.UUID `json:"id"`
+}
+
+func TestUUID_JSON(t *testing.T) {
+	id := uuids.New()
+	p, err := json.Marshal(Order{Id: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := Order{}
+	if err = json.Unmarshal(p, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Id != id {
+		t.Fatal("unexpected", string(p), v.Id)
+	}
+	if err = json.Unmarshal([]byte(`{"id":"not-a-uuid"}`), &v); err == nil {
+		t.Fatal("malformed uuid was decoded")
+	}
+}
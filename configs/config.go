@@ -33,6 +33,9 @@ import (
 type WorkersConfig struct {
 	Max            int `json:"max" yaml:"max,omitempty"`
 	MaxIdleSeconds int `json:"maxIdleSeconds" yaml:"maxIdleSeconds,omitempty"`
+	// Lanes
+	// priority lanes of requests when workers are busy, see services.LanesConfig.
+	Lanes services.LanesConfig `json:"lanes,omitempty" yaml:"lanes,omitempty"`
 }
 
 type ProcsConfig struct {
@@ -43,6 +46,18 @@ type RuntimeConfig struct {
 	Procs   ProcsConfig               `json:"procs,omitempty" yaml:"procs,omitempty"`
 	Workers WorkersConfig             `json:"workers,omitempty" yaml:"workers,omitempty"`
 	Shared  shareds.LocalSharedConfig `json:"shared,omitempty" yaml:"shared,omitempty"`
+	// MaxRequestDepth
+	// max number of internal hops of one request, default is 32.
+	MaxRequestDepth int `json:"maxRequestDepth,omitempty" yaml:"maxRequestDepth,omitempty"`
+	// TimeFormat
+	// encoding of time.Time, rfc3339 (default), unix or unixmilli.
+	TimeFormat string `json:"timeFormat,omitempty" yaml:"timeFormat,omitempty"`
+	// DurationFormat
+	// encoding of time.Duration, string (default, such as 1h30m), nanosecond, millisecond or second.
+	DurationFormat string `json:"durationFormat,omitempty" yaml:"durationFormat,omitempty"`
+	// MaxJsonDepth
+	// max nesting depth of json request bodies and decoding, default is 64.
+	MaxJsonDepth int `json:"maxJsonDepth,omitempty" yaml:"maxJsonDepth,omitempty"`
 }
 
 type Config struct {
@@ -50,9 +65,22 @@ type Config struct {
 	Log       logs.Config       `json:"log,omitempty" yaml:"log,omitempty"`
 	Cluster   clusters.Config   `json:"cluster,omitempty" yaml:"cluster,omitempty"`
 	Transport transports.Config `json:"transport,omitempty" yaml:"transport,omitempty"`
-	Proxy     proxies.Config    `json:"proxy,omitempty" yaml:"proxy,omitempty"`
-	Services  services.Config   `json:"services,omitempty" yaml:"services,omitempty"`
-	Hooks     hooks.Config      `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	// Internal
+	// when it is set, internal and cluster traffic is served by it, and transport only serves external services.
+	Internal *transports.Config `json:"internal,omitempty" yaml:"internal,omitempty"`
+	// Management
+	// when it is set, management handlers such as stats and debug are served by it instead of transport.
+	Management *transports.Config `json:"management,omitempty" yaml:"management,omitempty"`
+	// ExposeManagement
+	// when Management is not set, management handlers are served by transport only when it is true.
+	// they have no authorization of their own, so keep it false when transport is public.
+	ExposeManagement bool            `json:"exposeManagement,omitempty" yaml:"exposeManagement,omitempty"`
+	Proxy            proxies.Config  `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	Services         services.Config `json:"services,omitempty" yaml:"services,omitempty"`
+	Hooks            hooks.Config    `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	// Dependencies
+	// config of shared dependencies, keyed by names of providers, see services.Provider.
+	Dependencies services.Config `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
 }
 
 func (config Config) AddService(name string, conf any) Config {
@@ -78,6 +106,16 @@ func (config Config) SetTransport(transport transports.Config) Config {
 	return config
 }
 
+func (config Config) SetInternal(transport transports.Config) Config {
+	config.Internal = &transport
+	return config
+}
+
+func (config Config) SetManagement(transport transports.Config) Config {
+	config.Management = &transport
+	return config
+}
+
 func (config Config) SetLoggerLevel(level logs.Level) Config {
 	config.Log.Level = level
 	return config
@@ -0,0 +1,227 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package configs
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Interpolate
+// replaces ${NAME} and ${NAME:-default} in every string value of raw (json of config) with environment variables.
+// default can reference another variable, such as ${PORT:-${DEFAULT_PORT:-18080}}, and $${ is kept as a literal ${.
+// when a string value is exactly one reference and the field of Config is a number or bool, the resolved value is not quoted,
+// so that `port: ${PORT:-18080}` is decoded as number. in raw sections such as options, which type is unknown,
+// resolved number, bool and null are not quoted.
+// reference without default to an unset variable is an error.
+func Interpolate(raw []byte) (p []byte, err error) {
+	if !bytes.Contains(raw, []byte("${")) {
+		p = raw
+		return
+	}
+	decoder := stdjson.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var tree any
+	if err = decoder.Decode(&tree); err != nil {
+		err = errors.Warning("fns: interpolate config failed").WithCause(err)
+		return
+	}
+	tree, err = interpolateNode("", tree, reflect.TypeOf(Config{}))
+	if err != nil {
+		err = errors.Warning("fns: interpolate config failed").WithCause(err)
+		return
+	}
+	buf := bytes.Buffer{}
+	encoder := stdjson.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err = encoder.Encode(tree); err != nil {
+		err = errors.Warning("fns: interpolate config failed").WithCause(err)
+		return
+	}
+	p = bytes.TrimSpace(buf.Bytes())
+	return
+}
+
+// interpolateNode
+// typ is nil when type of node is unknown.
+func interpolateNode(path string, node any, typ reflect.Type) (v any, err error) {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == rawMessageType {
+		typ = nil
+	}
+	switch value := node.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key], err = interpolateNode(joinPath(path, key), item, memberType(typ, key))
+			if err != nil {
+				return
+			}
+		}
+		v = value
+		break
+	case []any:
+		for i, item := range value {
+			var elem reflect.Type
+			if typ != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+				elem = typ.Elem()
+			}
+			value[i], err = interpolateNode(fmt.Sprintf("%s[%d]", path, i), item, elem)
+			if err != nil {
+				return
+			}
+		}
+		v = value
+		break
+	case string:
+		s, whole, expandErr := expandEnv(value)
+		if expandErr != nil {
+			err = errors.Warning(expandErr.Error()).WithMeta("path", path)
+			return
+		}
+		v = s
+		if whole && (typ == nil || typ.Kind() != reflect.String) {
+			var literal any
+			decoder := stdjson.NewDecoder(strings.NewReader(s))
+			decoder.UseNumber()
+			if decoder.Decode(&literal) == nil && !decoder.More() {
+				switch literal.(type) {
+				case stdjson.Number, bool, nil:
+					v = literal
+					break
+				default:
+					break
+				}
+			}
+		}
+		break
+	default:
+		v = node
+		break
+	}
+	return
+}
+
+func memberType(typ reflect.Type, key string) reflect.Type {
+	if typ == nil {
+		return nil
+	}
+	switch typ.Kind() {
+	case reflect.Map:
+		return typ.Elem()
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			if strings.EqualFold(name, key) {
+				return field.Type
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// expandEnv
+// whole is true when s is exactly one reference.
+func expandEnv(s string) (v string, whole bool, err error) {
+	if !strings.Contains(s, "${") {
+		v = s
+		return
+	}
+	b := strings.Builder{}
+	references := 0
+	literals := 0
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "$${") {
+			b.WriteString("${")
+			literals++
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "${") {
+			b.WriteByte(s[i])
+			literals++
+			i++
+			continue
+		}
+		end := closingBrace(s, i+2)
+		if end < 0 {
+			err = fmt.Errorf("fns: %q has unclosed ${", s)
+			return
+		}
+		resolved, resolveErr := resolveEnv(s[i+2 : end])
+		if resolveErr != nil {
+			err = resolveErr
+			return
+		}
+		b.WriteString(resolved)
+		references++
+		i = end + 1
+	}
+	v = b.String()
+	whole = references == 1 && literals == 0
+	return
+}
+
+func resolveEnv(expr string) (v string, err error) {
+	name, def, hasDefault := strings.Cut(expr, ":-")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		err = fmt.Errorf("fns: ${%s} has no variable name", expr)
+		return
+	}
+	if value, has := os.LookupEnv(name); has && value != "" {
+		v = value
+		return
+	}
+	if !hasDefault {
+		err = fmt.Errorf("fns: environment variable %s is not set and has no default", name)
+		return
+	}
+	v, _, err = expandEnv(def)
+	return
+}
+
+func closingBrace(s string, from int) int {
+	depth := 1
+	for i := from; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+			break
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+			break
+		}
+	}
+	return -1
+}
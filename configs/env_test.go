@@ -0,0 +1,66 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package configs_test
+
+import (
+	"github.com/aacfactory/fns/configs"
+	"github.com/aacfactory/json"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("FNS_TEST_HOST", "10.0.0.1")
+	t.Setenv("FNS_TEST_PASSWORD", "123456")
+	raw := []byte(`{
+		"transport": {"host": "${FNS_TEST_HOST}", "port": "${FNS_TEST_PORT:-${FNS_TEST_DEFAULT_PORT:-18080}}"},
+		"cluster": {"secret": "${FNS_TEST_PASSWORD}", "name": "$${literal}"},
+		"services": {"db": {"dsns": ["postgres://${FNS_TEST_HOST}:5432/db"], "pool": "${FNS_TEST_POOL:-8}"}}
+	}`)
+	p, err := configs.Interpolate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := configs.Config{}
+	if err = json.Unmarshal(p, &config); err != nil {
+		t.Fatal(err, string(p))
+	}
+	if config.Transport.Host != "10.0.0.1" || config.Transport.Port != 18080 {
+		t.Error(config.Transport.Host, config.Transport.Port)
+	}
+	if config.Cluster.Secret != "123456" || config.Cluster.Name != "${literal}" {
+		t.Error(config.Cluster.Secret, config.Cluster.Name)
+	}
+	db := struct {
+		DSNs []string `json:"dsns"`
+		Pool int      `json:"pool"`
+	}{}
+	if err = json.Unmarshal(config.Services["db"], &db); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.DSNs) != 1 || db.DSNs[0] != "postgres://10.0.0.1:5432/db" || db.Pool != 8 {
+		t.Error(db)
+	}
+}
+
+func TestInterpolateUnresolved(t *testing.T) {
+	_, err := configs.Interpolate([]byte(`{"log": {"writers": [{"name": "${FNS_TEST_UNSET}"}]}}`))
+	if err == nil {
+		t.Fatal("want error")
+	}
+	t.Log(err)
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package configs
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"github.com/aacfactory/configures"
+	"github.com/aacfactory/errors"
+	"strings"
+)
+
+// Merge
+// deep merges override into base, both are json objects.
+// objects are merged key by key, arrays and scalars of override replace those of base,
+// and null of override removes the key from base.
+func Merge(base []byte, override []byte) (p []byte, err error) {
+	dst, dstErr := decodeObject(base)
+	if dstErr != nil {
+		err = errors.Warning("fns: merge config failed, base is not an object").WithCause(dstErr)
+		return
+	}
+	src, srcErr := decodeObject(override)
+	if srcErr != nil {
+		err = errors.Warning("fns: merge config failed, override is not an object").WithCause(srcErr)
+		return
+	}
+	mergeObject(dst, src)
+	buf := bytes.Buffer{}
+	encoder := stdjson.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err = encoder.Encode(dst); err != nil {
+		err = errors.Warning("fns: merge config failed").WithCause(err)
+		return
+	}
+	p = bytes.TrimSpace(buf.Bytes())
+	return
+}
+
+func decodeObject(p []byte) (v map[string]any, err error) {
+	v = make(map[string]any)
+	if len(bytes.TrimSpace(p)) == 0 {
+		return
+	}
+	decoder := stdjson.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+	err = decoder.Decode(&v)
+	return
+}
+
+func mergeObject(dst map[string]any, src map[string]any) {
+	for key, value := range src {
+		if value == nil {
+			delete(dst, key)
+			continue
+		}
+		srcObject, srcIsObject := value.(map[string]any)
+		dstObject, dstIsObject := dst[key].(map[string]any)
+		if srcIsObject && dstIsObject {
+			mergeObject(dstObject, srcObject)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// NewRetriever
+// returns a retriever which merges root with active by Merge.
+// format is format of contents of store, they are JSON when store is created by NewFileStore.
+func NewRetriever(option configures.RetrieverOption) (retriever *Retriever, err error) {
+	format := strings.ToUpper(strings.TrimSpace(option.Format))
+	switch format {
+	case "":
+		format = "JSON"
+		break
+	case "JSON", "YAML", "TOML":
+		break
+	default:
+		err = errors.Warning("fns: new config retriever failed, format is not supported").WithMeta("format", option.Format)
+		return
+	}
+	if option.Store == nil {
+		err = errors.Warning("fns: new config retriever failed, store is nil")
+		return
+	}
+	retriever = &Retriever{
+		active: strings.ToUpper(strings.TrimSpace(option.Active)),
+		format: format,
+		store:  option.Store,
+	}
+	return
+}
+
+type Retriever struct {
+	active string
+	format string
+	store  configures.Store
+}
+
+func (retriever *Retriever) Get() (v configures.Config, err error) {
+	root, subs, readErr := retriever.store.Read()
+	if readErr != nil {
+		err = errors.Warning("fns: retrieve config failed").WithCause(readErr)
+		return
+	}
+	if len(root) == 0 {
+		err = errors.Warning("fns: retrieve config failed, root config is not found")
+		return
+	}
+	root, err = retriever.decode(root)
+	if err != nil {
+		err = errors.Warning("fns: retrieve config failed, decode root config failed").WithCause(err)
+		return
+	}
+	if retriever.active != "" {
+		sub, has := subs[retriever.active]
+		if !has {
+			err = errors.Warning("fns: retrieve config failed, active config is not found").WithMeta("active", retriever.active)
+			return
+		}
+		sub, err = retriever.decode(sub)
+		if err != nil {
+			err = errors.Warning("fns: retrieve config failed, decode active config failed").WithMeta("active", retriever.active).WithCause(err)
+			return
+		}
+		root, err = Merge(root, sub)
+		if err != nil {
+			err = errors.Warning("fns: retrieve config failed").WithMeta("active", retriever.active).WithCause(err)
+			return
+		}
+	}
+	v, err = configures.NewJsonConfig(root)
+	if err != nil {
+		err = errors.Warning("fns: retrieve config failed").WithCause(err)
+		return
+	}
+	return
+}
+
+func (retriever *Retriever) decode(p []byte) ([]byte, error) {
+	return DecodeFile(fmt.Sprintf("fns.%s", strings.ToLower(retriever.format)), p)
+}
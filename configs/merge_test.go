@@ -0,0 +1,82 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package configs_test
+
+import (
+	"github.com/aacfactory/configures"
+	"github.com/aacfactory/fns/configs"
+	"github.com/aacfactory/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	base := []byte(`{"transport":{"port":18080,"basePath":"/api","middlewares":{"cors":{"allowedOrigins":["a","b"]}}},"log":{"level":"debug"},"cluster":{"name":"members"}}`)
+	override := []byte(`{"transport":{"port":80,"middlewares":{"cors":{"allowedOrigins":["c"]}}},"log":{"formatter":"json"},"cluster":null}`)
+	p, err := configs.Merge(base, override)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]any{
+		"transport": map[string]any{
+			"port":        float64(80),
+			"basePath":    "/api",
+			"middlewares": map[string]any{"cors": map[string]any{"allowedOrigins": []any{"c"}}},
+		},
+		"log": map[string]any{"level": "debug", "formatter": "json"},
+	}
+	merged := make(map[string]any)
+	if err = json.Unmarshal(p, &merged); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expect, merged) {
+		t.Error(string(p))
+	}
+}
+
+func TestRetriever(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "fns.yaml"), []byte("transport:\n  port: 18080\n  basePath: /api\nlog:\n  level: debug\n"), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "fns-prod.toml"), []byte("[transport]\nport = 80\n[log]\nlevel = \"error\"\n"), 0600)
+	for _, active := range []struct {
+		name  string
+		port  int
+		level string
+	}{{"", 18080, "debug"}, {"prod", 80, "error"}} {
+		retriever, retrieverErr := configs.NewRetriever(configures.RetrieverOption{
+			Active: active.name,
+			Store:  configs.NewFileStore(dir, "fns", '-'),
+		})
+		if retrieverErr != nil {
+			t.Fatal(retrieverErr)
+		}
+		conf, confErr := retriever.Get()
+		if confErr != nil {
+			t.Fatal(confErr)
+		}
+		config := configs.Config{}
+		if err := conf.As(&config); err != nil {
+			t.Fatal(err)
+		}
+		if config.Transport.Port != active.port || string(config.Log.Level) != active.level || config.Transport.BasePath != "/api" {
+			t.Error(active.name, config.Transport, config.Log.Level)
+		}
+	}
+}
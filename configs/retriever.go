@@ -30,6 +30,19 @@ const (
 	activeSystemEnvKey = "FNS-ACTIVE"
 )
 
+// Source
+// returns a readable description of where config is retrieved from.
+func Source(option configures.RetrieverOption) string {
+	switch store := option.Store.(type) {
+	case *FileStore:
+		return "file://" + store.path
+	case fmt.Stringer:
+		return store.String()
+	default:
+		return fmt.Sprintf("%T", option.Store)
+	}
+}
+
 func DefaultConfigRetrieverOption() (option configures.RetrieverOption) {
 	path, pathErr := filepath.Abs("./configs")
 	if pathErr != nil {
@@ -38,10 +51,10 @@ func DefaultConfigRetrieverOption() (option configures.RetrieverOption) {
 	}
 	active, _ := os.LookupEnv(activeSystemEnvKey)
 	active = strings.TrimSpace(active)
-	store := configures.NewFileStore(path, "fns", '-')
+	store := NewFileStore(path, "fns", '-')
 	option = configures.RetrieverOption{
 		Active: active,
-		Format: "YAML",
+		Format: "JSON",
 		Store:  store,
 	}
 	return
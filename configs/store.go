@@ -0,0 +1,148 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package configs
+
+import (
+	"fmt"
+	"github.com/aacfactory/configures"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DecodeFile
+// converts content to json by extension of filename, .yaml, .yml and .toml are converted, others are treated as json.
+func DecodeFile(filename string, content []byte) (p []byte, err error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		conf, confErr := configures.NewYamlConfig(content)
+		if confErr != nil {
+			err = errors.Warning("fns: decode config file failed").WithMeta("file", filename).WithCause(confErr)
+			return
+		}
+		p = conf.Raw()
+		break
+	case ".toml":
+		p, err = TOMLToJSON(content)
+		if err != nil {
+			err = errors.Warning("fns: decode config file failed").WithMeta("file", filename).WithCause(err)
+			return
+		}
+		break
+	default:
+		if !json.Validate(content) {
+			err = errors.Warning("fns: decode config file failed, content is not json").WithMeta("file", filename)
+			return
+		}
+		p = content
+		break
+	}
+	return
+}
+
+var (
+	configFileExtensions = map[string]bool{".json": true, ".yaml": true, ".yml": true, ".toml": true}
+)
+
+// NewFileStore
+// returns a configures.Store which reads {prefix}.{ext} as root and {prefix}{splitter}{active}.{ext} as actives,
+// ext can be json, yaml, yml or toml, and contents are converted to json, so retriever must use JSON format.
+// path can be a file, then it is the root.
+func NewFileStore(path string, prefix string, splitter byte) configures.Store {
+	return &FileStore{
+		path:     path,
+		prefix:   prefix,
+		splitter: splitter,
+	}
+}
+
+type FileStore struct {
+	path     string
+	prefix   string
+	splitter byte
+}
+
+func (store *FileStore) Read() (root []byte, subs map[string][]byte, err error) {
+	info, statErr := os.Stat(store.path)
+	if statErr != nil {
+		err = errors.Warning("fns: config file store read failed").WithMeta("path", store.path).WithCause(statErr)
+		return
+	}
+	if !info.IsDir() {
+		content, readErr := os.ReadFile(store.path)
+		if readErr != nil {
+			err = errors.Warning("fns: config file store read failed").WithMeta("path", store.path).WithCause(readErr)
+			return
+		}
+		root, err = DecodeFile(store.path, content)
+		return
+	}
+	subs = make(map[string][]byte)
+	files := make(map[string]string)
+	walkErr := filepath.Walk(store.path, func(path string, info fs.FileInfo, cause error) (err error) {
+		if cause != nil {
+			err = cause
+			return
+		}
+		if info.IsDir() {
+			return
+		}
+		filename := filepath.Base(path)
+		ext := filepath.Ext(filename)
+		if !strings.HasPrefix(filename, store.prefix) || !configFileExtensions[strings.ToLower(ext)] {
+			return
+		}
+		name := strings.TrimSuffix(filename, ext)
+		key := ""
+		if name != store.prefix {
+			if len(name) <= len(store.prefix)+1 || name[len(store.prefix)] != store.splitter {
+				return
+			}
+			key = strings.ToUpper(strings.TrimSpace(name[len(store.prefix)+1:]))
+		}
+		if exist, has := files[key]; has {
+			err = fmt.Errorf("%s and %s are both config of %s", exist, filename, name)
+			return
+		}
+		files[key] = filename
+		content, readErr := os.ReadFile(path)
+		if readErr != nil {
+			err = readErr
+			return
+		}
+		p, decodeErr := DecodeFile(filename, content)
+		if decodeErr != nil {
+			err = decodeErr
+			return
+		}
+		if key == "" {
+			root = p
+		} else {
+			subs[key] = p
+		}
+		return
+	})
+	if walkErr != nil {
+		err = errors.Warning("fns: config file store read failed").WithMeta("path", store.path).WithCause(walkErr)
+		return
+	}
+	return
+}
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package configs_test
+
+import (
+	"github.com/aacfactory/configures"
+	"github.com/aacfactory/fns/configs"
+	"github.com/aacfactory/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const (
+	jsonConfig = `{
+  "runtime": {"procs": {"min": 2}, "workers": {"max": 64}},
+  "log": {"level": "info", "sendTimeout": "1s", "writers": [{"name": "file", "options": {"path": "/var/log/fns.log"}}]},
+  "transport": {"port": 18080, "basePath": "/api", "tls": {"kind": "DEFAULT", "options": {"cert": "C:\\certs\\a.pem"}}},
+  "services": {"sql": {"kind": "standalone", "dsn": ["postgres://127.0.0.1:5432/db"], "maxIdles": 8, "ratio": 0.5, "debugLog": true}}
+}`
+	yamlConfig = `
+runtime:
+  procs:
+    min: 2
+  workers:
+    max: 64
+log:
+  level: info
+  sendTimeout: 1s
+  writers:
+    - name: file
+      options:
+        path: /var/log/fns.log
+transport:
+  port: 18080
+  basePath: /api
+  tls:
+    kind: DEFAULT
+    options:
+      cert: 'C:\certs\a.pem'
+services:
+  sql:
+    kind: standalone
+    dsn:
+      - postgres://127.0.0.1:5432/db
+    maxIdles: 8
+    ratio: 0.5
+    debugLog: true
+`
+	tomlConfig = `
+# runtime
+runtime.procs.min = 2
+runtime.workers = { max = 64 }
+
+[log]
+level = "info"
+sendTimeout = "1s"
+
+[[log.writers]]
+name = "file"
+options.path = '/var/log/fns.log'
+
+[transport]
+port = 18_080
+basePath = "/api" # comment
+tls = { kind = "DEFAULT", options = { cert = 'C:\certs\a.pem' } }
+
+[services.sql]
+kind = """
+standalone"""
+dsn = [
+  "postgres://127.0.0.1:5432/db", # trailing comma
+]
+maxIdles = 8
+ratio = 0.5
+debugLog = true
+`
+)
+
+func TestFileStore(t *testing.T) {
+	var expect map[string]any
+	for _, file := range []struct {
+		name    string
+		content string
+	}{{"fns.json", jsonConfig}, {"fns.yaml", yamlConfig}, {"fns.toml", tomlConfig}} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, file.name), []byte(file.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		retriever, retrieverErr := configures.NewRetriever(configures.RetrieverOption{
+			Format: "JSON",
+			Store:  configs.NewFileStore(dir, "fns", '-'),
+		})
+		if retrieverErr != nil {
+			t.Fatal(retrieverErr)
+		}
+		conf, confErr := retriever.Get()
+		if confErr != nil {
+			t.Fatal(file.name, confErr)
+		}
+		config := configs.Config{}
+		if err := conf.As(&config); err != nil {
+			t.Fatal(file.name, err)
+		}
+		if config.Runtime.Procs.Min != 2 || config.Transport.Port != 18080 || len(config.Log.Writers) != 1 {
+			t.Fatal(file.name, config)
+		}
+		tree := make(map[string]any)
+		if err := json.Unmarshal(conf.Raw(), &tree); err != nil {
+			t.Fatal(file.name, err)
+		}
+		if expect == nil {
+			expect = tree
+			continue
+		}
+		if !reflect.DeepEqual(expect, tree) {
+			t.Error(file.name, "is not same as json", string(conf.Raw()))
+		}
+	}
+}
+
+func TestFileStoreDuplicated(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "fns.yaml"), []byte(yamlConfig), 0600)
+	_ = os.WriteFile(filepath.Join(dir, "fns.toml"), []byte(tomlConfig), 0600)
+	_, _, err := configs.NewFileStore(dir, "fns", '-').Read()
+	if err == nil {
+		t.Fatal("want error")
+	}
+}
+
+func TestTOMLToJSON(t *testing.T) {
+	p, err := configs.TOMLToJSON([]byte("date = 1979-05-27\ntime = 07:32:00\nlocal = 1979-05-27T07:32:00\nat = 1979-05-27T07:32:00-08:00\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := make(map[string]string)
+	if err = json.Unmarshal(p, &tree); err != nil {
+		t.Fatal(err)
+	}
+	if tree["date"] != "1979-05-27" || tree["time"] != "07:32:00" || tree["local"] != "1979-05-27T07:32:00" || tree["at"] != "1979-05-27T07:32:00-08:00" {
+		t.Fatal("unexpected", string(p))
+	}
+	if _, err = configs.TOMLToJSON([]byte("v = inf\n")); err == nil {
+		t.Fatal("inf must be rejected")
+	}
+	if _, err = configs.TOMLToJSON([]byte("v = \n")); err == nil {
+		t.Fatal("invalid toml must be rejected")
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package configs
+
+import (
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/json"
+	"math"
+	"time"
+)
+
+// TOMLToJSON
+// converts toml document to json, so that it can be used as configures.Config.
+// date and time values are kept as strings, inf and nan are not supported because json can not represent them.
+func TOMLToJSON(p []byte) (v []byte, err error) {
+	root := make(map[string]any)
+	_, decodeErr := toml.Decode(string(p), &root)
+	if decodeErr != nil {
+		err = errors.Warning("fns: decode toml failed").WithCause(decodeErr)
+		return
+	}
+	value, convertErr := tomlValueToJSON(root)
+	if convertErr != nil {
+		err = errors.Warning("fns: decode toml failed").WithCause(convertErr)
+		return
+	}
+	v, err = json.Marshal(value)
+	if err != nil {
+		err = errors.Warning("fns: decode toml failed").WithCause(err)
+		return
+	}
+	return
+}
+
+func tomlValueToJSON(v any) (value any, err error) {
+	switch x := v.(type) {
+	case map[string]any:
+		for key, element := range x {
+			x[key], err = tomlValueToJSON(element)
+			if err != nil {
+				return
+			}
+		}
+		value = x
+		break
+	case []map[string]any:
+		elements := make([]any, len(x))
+		for i, element := range x {
+			elements[i], err = tomlValueToJSON(element)
+			if err != nil {
+				return
+			}
+		}
+		value = elements
+		break
+	case []any:
+		for i, element := range x {
+			x[i], err = tomlValueToJSON(element)
+			if err != nil {
+				return
+			}
+		}
+		value = x
+		break
+	case float64:
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			err = fmt.Errorf("%v is not supported", x)
+			return
+		}
+		value = x
+		break
+	case time.Time:
+		// local date and time have no offset, so they are formatted as they are written
+		switch x.Location().String() {
+		case "datetime-local":
+			value = x.Format("2006-01-02T15:04:05.999999999")
+			break
+		case "date-local":
+			value = x.Format("2006-01-02")
+			break
+		case "time-local":
+			value = x.Format("15:04:05.999999999")
+			break
+		default:
+			value = x.Format(time.RFC3339Nano)
+			break
+		}
+		break
+	default:
+		value = v
+		break
+	}
+	return
+}
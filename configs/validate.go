@@ -0,0 +1,288 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package configs
+
+import (
+	"fmt"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Problem
+// one invalid item of config, path is dotted, such as transport.port or log.writers[0].name.
+type Problem struct {
+	Path    string
+	Message string
+}
+
+func (problem Problem) String() string {
+	return fmt.Sprintf("%s: %s", problem.Path, problem.Message)
+}
+
+// InvalidError
+// aggregated problems of config, so that all of them can be fixed at once.
+type InvalidError struct {
+	Problems []Problem
+}
+
+func (err *InvalidError) Error() string {
+	b := strings.Builder{}
+	b.WriteString(fmt.Sprintf("fns: config is invalid, %d problem(s) found", len(err.Problems)))
+	for _, problem := range err.Problems {
+		b.WriteString("\n\t")
+		b.WriteString(problem.String())
+	}
+	return b.String()
+}
+
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) add(path string, format string, args ...any) {
+	v.problems = append(v.problems, Problem{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Validate
+// checks raw (json of config after active is merged) and decoded config.
+// it reports unknown keys, malformed durations and byte sizes, out of range values and missing required fields.
+// string values of keys which end with timeout, duration, interval, period, threshold, latency or ttl must be durations,
+// and which end with size must be byte sizes, this is applied to raw options of transports, handlers and middlewares too.
+// err is *InvalidError when there are problems.
+func Validate(raw []byte, config Config) (err error) {
+	v := &validator{}
+	if len(raw) > 0 {
+		var tree any
+		if decodeErr := json.Unmarshal(raw, &tree); decodeErr != nil {
+			v.add("$", "config is not a valid document, %s", decodeErr.Error())
+		} else {
+			v.unknownKeys("", tree, reflect.TypeOf(config))
+			v.formats("", tree)
+		}
+	}
+	v.config(config)
+	if len(v.problems) > 0 {
+		sort.SliceStable(v.problems, func(i, j int) bool {
+			return v.problems[i].Path < v.problems[j].Path
+		})
+		err = &InvalidError{
+			Problems: v.problems,
+		}
+		return
+	}
+	return
+}
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (v *validator) unknownKeys(path string, node any, typ reflect.Type) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == rawMessageType {
+		return
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return
+		}
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			fields[strings.ToLower(name)] = field.Type
+		}
+		for key, value := range obj {
+			fieldType, has := fields[strings.ToLower(key)]
+			if !has {
+				v.add(joinPath(path, key), "unknown key")
+				continue
+			}
+			v.unknownKeys(joinPath(path, key), value, fieldType)
+		}
+		break
+	case reflect.Slice, reflect.Array:
+		items, ok := node.([]any)
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			v.unknownKeys(fmt.Sprintf("%s[%d]", path, i), item, typ.Elem())
+		}
+		break
+	default:
+		break
+	}
+}
+
+func (v *validator) formats(path string, node any) {
+	switch value := node.(type) {
+	case map[string]any:
+		for key, item := range value {
+			itemPath := joinPath(path, key)
+			s, isString := item.(string)
+			if !isString {
+				v.formats(itemPath, item)
+				continue
+			}
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			lower := strings.ToLower(key)
+			if isDurationKey(lower) {
+				if _, parseErr := time.ParseDuration(s); parseErr != nil {
+					v.add(itemPath, "%q is not a duration, use such as 500ms, 10s or 1m", s)
+				}
+			} else if strings.HasSuffix(lower, "size") {
+				if _, parseErr := bytex.ParseBytes(s); parseErr != nil {
+					v.add(itemPath, "%q is not a byte size, use such as 512KB, 4MB or 1GB", s)
+				}
+			}
+		}
+		break
+	case []any:
+		for i, item := range value {
+			v.formats(fmt.Sprintf("%s[%d]", path, i), item)
+		}
+		break
+	default:
+		break
+	}
+}
+
+func isDurationKey(key string) bool {
+	for _, suffix := range []string{"timeout", "duration", "interval", "period", "threshold", "latency", "ttl"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) config(config Config) {
+	// runtime
+	if config.Runtime.Procs.Min < 0 {
+		v.add("runtime.procs.min", "must not be negative")
+	}
+	if config.Runtime.Workers.Max < 0 {
+		v.add("runtime.workers.max", "must not be negative")
+	}
+	if config.Runtime.Workers.MaxIdleSeconds < 0 {
+		v.add("runtime.workers.maxIdleSeconds", "must not be negative")
+	}
+	if config.Runtime.MaxRequestDepth < 0 {
+		v.add("runtime.maxRequestDepth", "must not be negative")
+	}
+	if config.Runtime.MaxJsonDepth < 0 {
+		v.add("runtime.maxJsonDepth", "must not be negative")
+	}
+	if _, formatErr := jsons.ParseTimeFormat(config.Runtime.TimeFormat); formatErr != nil {
+		v.add("runtime.timeFormat", "%q is unknown, use rfc3339, unix or unixmilli", config.Runtime.TimeFormat)
+	}
+	if _, formatErr := jsons.ParseDurationFormat(config.Runtime.DurationFormat); formatErr != nil {
+		v.add("runtime.durationFormat", "%q is unknown, use string, nanosecond, millisecond or second", config.Runtime.DurationFormat)
+	}
+	// log
+	switch config.Log.Level {
+	case "", logs.Debug, logs.Info, logs.Warn, logs.Error:
+		break
+	default:
+		v.add("log.level", "%q is unknown, use debug, info, warn or error", config.Log.Level)
+		break
+	}
+	if config.Log.Consumes < 0 {
+		v.add("log.consumes", "must not be negative")
+	}
+	if config.Log.Buffer < 0 {
+		v.add("log.buffer", "must not be negative")
+	}
+	for i, writer := range config.Log.Writers {
+		if strings.TrimSpace(writer.Name) == "" {
+			v.add(fmt.Sprintf("log.writers[%d].name", i), "is required")
+		}
+	}
+	// cluster
+	if config.Cluster.Chaos != nil {
+		for i, target := range config.Cluster.Chaos.Targets {
+			if strings.TrimSpace(target.Service) == "" {
+				v.add(fmt.Sprintf("cluster.chaos.targets[%d].service", i), "is required")
+			}
+			if target.FailureRate < 0 || target.FailureRate > 1 {
+				v.add(fmt.Sprintf("cluster.chaos.targets[%d].failureRate", i), "must be between 0 and 1")
+			}
+			if target.LatencyRate < 0 || target.LatencyRate > 1 {
+				v.add(fmt.Sprintf("cluster.chaos.targets[%d].latencyRate", i), "must be between 0 and 1")
+			}
+		}
+	}
+	if config.Cluster.Warmup != nil && config.Cluster.Warmup.Connections < 0 {
+		v.add("cluster.warmup.connections", "must not be negative")
+	}
+	// transports
+	v.transport("transport", config.Transport)
+	if config.Internal != nil {
+		v.transport("internal", *config.Internal)
+	}
+	if config.Management != nil {
+		v.transport("management", *config.Management)
+	}
+}
+
+func (v *validator) transport(path string, config transports.Config) {
+	if config.Port < 0 || config.Port > 65535 {
+		v.add(path+".port", "%d is out of range, port must be between 1 and 65535", config.Port)
+		return
+	}
+	if _, _, err := config.GetListenAddress(); err != nil {
+		v.add(path, "%s", err.Error())
+	}
+	if config.TLS != nil && strings.TrimSpace(config.TLS.Kind) == "" {
+		v.add(path+".tls.kind", "is required")
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
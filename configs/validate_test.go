@@ -0,0 +1,45 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package configs_test
+
+import (
+	"errors"
+	"github.com/aacfactory/fns/configs"
+	"github.com/aacfactory/json"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	raw := []byte(`{"runtime":{"procz":{}},"log":{"level":"verbose","sendTimeout":"10"},"transport":{"port":70000,"options":{"maxRequestBodySize":"4XB"}}}`)
+	config := configs.Config{}
+	if err := json.Unmarshal(raw, &config); err != nil {
+		t.Fatal(err)
+	}
+	err := configs.Validate(raw, config)
+	invalid := &configs.InvalidError{}
+	if !errors.As(err, &invalid) {
+		t.Fatal("want invalid error", err)
+	}
+	if len(invalid.Problems) != 5 {
+		t.Error(len(invalid.Problems), err)
+	}
+	t.Log(err)
+	if err = configs.Validate([]byte(`{"transport":{"port":18080}}`), configs.New()); err != nil {
+		t.Error(err)
+	}
+}
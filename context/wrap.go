@@ -90,6 +90,24 @@ func WithoutCancel(parent Context) Context {
 	return Wrap(context.WithoutCancel(parent))
 }
 
+// Detach
+// returns a context which is never canceled with copies of user and local values of ctx,
+// it is used by work which outlives ctx, such as handling after the request is responded.
+func Detach(ctx Context) Context {
+	v := &context_{
+		Context: context.TODO(),
+		users:   make(Entries, 0, 1),
+		locals:  make(Entries, 0, 1),
+	}
+	ctx.UserValues(func(key []byte, val any) {
+		v.users.Set(append([]byte{}, key...), val)
+	})
+	ctx.LocalValues(func(key []byte, val any) {
+		v.locals.Set(append([]byte{}, key...), val)
+	})
+	return v
+}
+
 func AfterFunc(ctx Context, f func()) (stop func() bool) {
 	stop = context.AfterFunc(ctx, f)
 	return
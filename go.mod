@@ -3,6 +3,7 @@ module github.com/aacfactory/fns
 go 1.22.1
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/aacfactory/afssl v1.12.0
 	github.com/aacfactory/avro v1.2.12
 	github.com/aacfactory/cases v1.1.0
@@ -18,6 +19,7 @@ require (
 	github.com/fatih/color v1.17.0
 	github.com/go-playground/validator/v10 v10.20.0
 	github.com/goccy/go-yaml v1.11.3
+	github.com/json-iterator/go v1.1.12
 	github.com/mattn/go-isatty v0.0.20
 	github.com/rs/xid v1.5.0
 	github.com/tidwall/btree v1.7.0
@@ -37,7 +39,6 @@ require (
 	github.com/gabriel-vasile/mimetype v1.4.4 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
-	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/klauspost/compress v1.17.7 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
@@ -0,0 +1,298 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package graphqls
+
+import (
+	"bytes"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/json"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+var (
+	graphqlPath          = []byte("/graphql")
+	defaultMaxSelections = 32
+	defaultConcurrency   = 8
+	ErrUnauthorized      = errors.Unauthorized("fns: graphql field requires authorization")
+	ErrFieldNotFound     = errors.NotFound("fns: graphql field was not found")
+	ErrInvalidOperation  = errors.BadRequest("fns: invalid graphql operation")
+	ErrTooManySelections = errors.New(http.StatusRequestEntityTooLarge, "***TOO LARGE***", "fns: graphql operation has too many fields")
+)
+
+type Config struct {
+	// MaxSelections
+	// max number of top level fields in one operation, default is 32.
+	MaxSelections int `json:"maxSelections,omitempty" yaml:"maxSelections,omitempty"`
+	// Concurrency
+	// max number of fields of queries executed at the same time by the handler, default is 8.
+	Concurrency int `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+}
+
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+type Error struct {
+	Message    string         `json:"message"`
+	Path       []string       `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+type Response struct {
+	Data   map[string]any `json:"data"`
+	Errors []Error        `json:"errors,omitempty"`
+}
+
+// Handler
+// exposes services as graphql schema, it is not registered by default, use fns.Handler(graphqls.Handler()) to register it.
+// `GET /graphql` returns schema definition language, `POST /graphql` executes operation.
+// field of fn with @authorization is rejected at once when Authorization header is absent,
+// it is only a presence check, the token is validated by the fn itself, as it is done for `POST /{service}/{fn}`.
+// number of fields of one operation and fields executed at the same time are limited, see Config.
+func Handler() transports.MuxHandler {
+	return &handler{}
+}
+
+type handler struct {
+	once          sync.Once
+	schema        *Schema
+	maxSelections int
+	tokens        chan struct{}
+}
+
+func (handler *handler) Name() string {
+	return "graphql"
+}
+
+func (handler *handler) Construct(options transports.MuxHandlerOptions) error {
+	config := Config{}
+	if err := options.Config.As(&config); err != nil {
+		return errors.Warning("fns: construct graphql handler failed").WithCause(err)
+	}
+	handler.maxSelections = config.MaxSelections
+	if handler.maxSelections < 1 {
+		handler.maxSelections = defaultMaxSelections
+	}
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = defaultConcurrency
+	}
+	handler.tokens = make(chan struct{}, concurrency)
+	return nil
+}
+
+func (handler *handler) Match(_ context.Context, method []byte, path []byte, header transports.Header) bool {
+	if !bytes.Equal(path, graphqlPath) {
+		return false
+	}
+	if bytes.Equal(method, transports.MethodGet) {
+		return true
+	}
+	return bytes.Equal(method, transports.MethodPost) &&
+		bytes.Equal(header.Get(transports.ContentTypeHeaderName), transports.ContentTypeJsonHeaderValue)
+}
+
+func (handler *handler) Handle(w transports.ResponseWriter, r transports.Request) {
+	endpoints := runtime.Endpoints(r)
+	handler.once.Do(func() {
+		handler.schema = NewSchema(endpoints.Info())
+	})
+	if bytes.Equal(r.Method(), transports.MethodGet) {
+		w.Header().Set(transports.ContentTypeHeaderName, transports.ContentTypeTextHeaderValue)
+		w.SetStatus(http.StatusOK)
+		_, _ = w.Write(bytex.FromString(handler.schema.String()))
+		return
+	}
+	options, optionsErr := services.TransportRequestOptions(r)
+	if optionsErr != nil {
+		w.Failed(optionsErr)
+		return
+	}
+	body, bodyErr := r.Body()
+	if bodyErr != nil {
+		w.Failed(services.ErrInvalidBody.WithCause(bodyErr))
+		return
+	}
+	req := Request{}
+	if decodeErr := jsons.Unmarshal(body, &req); decodeErr != nil {
+		w.Failed(services.ErrInvalidBody.WithCause(decodeErr))
+		return
+	}
+	op, parseErr := parse(req.Query, req.OperationName)
+	if parseErr != nil {
+		w.Failed(ErrInvalidOperation.WithCause(parseErr))
+		return
+	}
+	if len(op.selections) > handler.maxSelections {
+		w.Failed(ErrTooManySelections.WithMeta("max", strconv.Itoa(handler.maxSelections)))
+		return
+	}
+	// presence only, see Handler
+	hasAuthorization := len(r.Header().Get(transports.AuthorizationHeaderName)) > 0
+	results := make([]any, len(op.selections))
+	errs := make([]error, len(op.selections))
+	execute := func(i int) {
+		// each field has its own context, cause entries of r are not safe for concurrent writes
+		results[i], errs[i] = handler.execute(context.Fork(r), endpoints, op, op.selections[i], req.Variables, hasAuthorization, options)
+	}
+	if op.kind == "query" {
+		// fields of query are executed in parallel, bounded by tokens which are shared by all requests
+		wg := new(sync.WaitGroup)
+		for i := range op.selections {
+			handler.tokens <- struct{}{}
+			wg.Add(1)
+			go func(i int) {
+				execute(i)
+				<-handler.tokens
+				wg.Done()
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		// fields of mutation are executed in series
+		for i := range op.selections {
+			execute(i)
+		}
+	}
+	response := Response{
+		Data: make(map[string]any, len(op.selections)),
+	}
+	for i, s := range op.selections {
+		response.Data[s.key()] = results[i]
+		if errs[i] != nil {
+			err := errors.Wrap(errs[i])
+			response.Errors = append(response.Errors, Error{
+				Message: err.Message(),
+				Path:    []string{s.key()},
+				Extensions: map[string]any{
+					"code": err.Code(),
+					"name": err.Name(),
+				},
+			})
+		}
+	}
+	w.Succeed(response)
+}
+
+func (handler *handler) execute(ctx context.Context, endpoints services.Endpoints, op operation, s selection, variables map[string]any, hasAuthorization bool, options []services.RequestOption) (v any, err error) {
+	if s.name == "__typename" {
+		if op.kind == "query" {
+			v = "Query"
+		} else {
+			v = "Mutation"
+		}
+		return
+	}
+	f, has := handler.schema.field(s.name)
+	if !has {
+		err = ErrFieldNotFound.WithMeta("field", s.name)
+		return
+	}
+	if f.readonly != (op.kind == "query") {
+		err = ErrInvalidOperation.WithMeta("field", s.name).WithMeta("operation", op.kind)
+		return
+	}
+	if f.authorization && !hasAuthorization {
+		err = ErrUnauthorized.WithMeta("field", s.name)
+		return
+	}
+	var param any
+	if arg, hasArg := s.arguments["param"]; hasArg {
+		p, encodeErr := jsons.Marshal(resolve(arg, variables))
+		if encodeErr != nil {
+			err = ErrInvalidOperation.WithMeta("field", s.name).WithCause(encodeErr)
+			return
+		}
+		param = json.RawMessage(p)
+	}
+	response, requestErr := endpoints.Request(ctx, bytex.FromString(f.service), bytex.FromString(f.fn), param, options...)
+	if requestErr != nil {
+		err = requestErr
+		return
+	}
+	if !response.Valid() {
+		return
+	}
+	p, encodeErr := jsons.Marshal(response.Value())
+	if encodeErr != nil {
+		err = errors.Warning("fns: encode graphql field value failed").WithMeta("field", s.name).WithCause(encodeErr)
+		return
+	}
+	var value any
+	if decodeErr := jsons.Unmarshal(p, &value); decodeErr != nil {
+		err = errors.Warning("fns: decode graphql field value failed").WithMeta("field", s.name).WithCause(decodeErr)
+		return
+	}
+	v = project(value, s.selections)
+	return
+}
+
+func resolve(v any, variables map[string]any) any {
+	switch value := v.(type) {
+	case variable:
+		return variables[string(value)]
+	case []any:
+		items := make([]any, len(value))
+		for i, item := range value {
+			items[i] = resolve(item, variables)
+		}
+		return items
+	case map[string]any:
+		object := make(map[string]any, len(value))
+		for k, item := range value {
+			object[k] = resolve(item, variables)
+		}
+		return object
+	default:
+		return v
+	}
+}
+
+// project
+// keeps selected fields of value, value without selections is returned as it is.
+func project(v any, selections []selection) any {
+	if len(selections) == 0 {
+		return v
+	}
+	switch value := v.(type) {
+	case []any:
+		items := make([]any, len(value))
+		for i, item := range value {
+			items[i] = project(item, selections)
+		}
+		return items
+	case map[string]any:
+		object := make(map[string]any, len(selections))
+		for _, s := range selections {
+			object[s.key()] = project(value[s.name], s.selections)
+		}
+		return object
+	default:
+		return v
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package graphqls_test
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/aacfactory/configures"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/graphqls"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/documents"
+	"github.com/aacfactory/fns/shareds"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/fns/transports/fast"
+	"github.com/valyala/fasthttp"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type resultWriter struct {
+	context.Context
+	*transports.ResultResponseWriter
+}
+
+func (w *resultWriter) SetCookie(_ *transports.Cookie) {}
+
+func (w *resultWriter) Hijack(_ func(ctx context.Context, conn net.Conn, rw *bufio.ReadWriter) (err error)) (async bool, err error) {
+	return
+}
+
+func (w *resultWriter) Hijacked() bool {
+	return false
+}
+
+// countFn
+// records max number of running fns.
+type countFn struct {
+	running *atomic.Int64
+	max     *atomic.Int64
+}
+
+func (fn *countFn) Name() string {
+	return "count"
+}
+
+func (fn *countFn) Internal() bool {
+	return false
+}
+
+func (fn *countFn) Readonly() bool {
+	return true
+}
+
+func (fn *countFn) Handle(_ services.Request) (v any, err error) {
+	n := fn.running.Add(1)
+	for {
+		max := fn.max.Load()
+		if n <= max || fn.max.CompareAndSwap(max, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	fn.running.Add(-1)
+	v = n
+	return
+}
+
+type countService struct {
+	services.Abstract
+}
+
+func (svc *countService) Document() (document documents.Endpoint) {
+	document = documents.New(svc.Name(), "", "", svc.Version())
+	document.AddFn(documents.NewFn("count").SetReadonly(true))
+	return
+}
+
+func TestHandler_Limits(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	fn := &countFn{running: new(atomic.Int64), max: new(atomic.Int64)}
+	svc := &countService{Abstract: services.NewAbstract("counter", false)}
+	svc.AddFunction(fn)
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	if err := manager.Add(svc); err != nil {
+		t.Fatal(err)
+	}
+	rt := runtime.New("id", "name", versions.Origin(), nil, log, nil, manager, nil, shared)
+
+	handler := graphqls.Handler()
+	config, _ := configures.NewJsonConfig([]byte(`{"maxSelections":8,"concurrency":2}`))
+	if err := handler.Construct(transports.MuxHandlerOptions{Log: log, Config: config}); err != nil {
+		t.Fatal(err)
+	}
+	query := func(n int) *resultWriter {
+		fields := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			fields = append(fields, fmt.Sprintf("f%d: counter_count", i))
+		}
+		rc := &fasthttp.RequestCtx{}
+		rc.Request.Header.SetMethod(http.MethodPost)
+		rc.Request.SetRequestURI("/graphql")
+		rc.Request.Header.SetContentType("application/json")
+		rc.Request.Header.Set("X-Fns-Device-Id", "device")
+		rc.Request.SetBody([]byte(fmt.Sprintf(`{"query":"query { %s }"}`, strings.Join(fields, " "))))
+		r := &fast.Request{Context: &fast.Context{RequestCtx: rc}}
+		runtime.With(r, rt)
+		w := &resultWriter{
+			Context:              context.TODO(),
+			ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+		}
+		handler.Handle(w, r)
+		return w
+	}
+
+	w := query(6)
+	if w.Status() != http.StatusOK {
+		t.Fatal("query failed", w.Status(), string(w.Body()))
+	}
+	if strings.Contains(string(w.Body()), `"errors"`) || !strings.Contains(string(w.Body()), `"f5"`) {
+		t.Fatal("fields were not executed", string(w.Body()))
+	}
+	if max := fn.max.Load(); max > 2 {
+		t.Fatal("concurrency is not limited", max)
+	}
+	transports.ReleaseResultResponseWriter(w.ResultResponseWriter)
+
+	w = query(9)
+	if w.Status() != http.StatusRequestEntityTooLarge {
+		t.Fatal("too many fields should be rejected", w.Status(), string(w.Body()))
+	}
+	transports.ReleaseResultResponseWriter(w.ResultResponseWriter)
+}
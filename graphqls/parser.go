@@ -0,0 +1,431 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package graphqls
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"strconv"
+	"strings"
+)
+
+// parser
+// supports operations with fields, aliases, arguments and variables.
+// fragments, subscriptions and directives on fields are not supported.
+
+type variable string
+
+type selection struct {
+	alias      string
+	name       string
+	arguments  map[string]any
+	selections []selection
+}
+
+func (s selection) key() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.name
+}
+
+type operation struct {
+	kind       string
+	name       string
+	selections []selection
+}
+
+func parse(query string, operationName string) (op operation, err error) {
+	p := &parser{src: query}
+	ops := make([]operation, 0, 1)
+	for {
+		p.skip()
+		if p.eof() {
+			break
+		}
+		o, opErr := p.operation()
+		if opErr != nil {
+			err = errors.Warning("fns: parse graphql query failed").WithCause(opErr)
+			return
+		}
+		ops = append(ops, o)
+	}
+	if len(ops) == 0 {
+		err = errors.Warning("fns: parse graphql query failed").WithCause(fmt.Errorf("no operation"))
+		return
+	}
+	if operationName == "" {
+		if len(ops) > 1 {
+			err = errors.Warning("fns: parse graphql query failed").WithCause(fmt.Errorf("operation name is required when query has more than one operation"))
+			return
+		}
+		op = ops[0]
+		return
+	}
+	for _, o := range ops {
+		if o.name == operationName {
+			op = o
+			return
+		}
+	}
+	err = errors.Warning("fns: parse graphql query failed").WithCause(fmt.Errorf("operation was not found")).WithMeta("operation", operationName)
+	return
+}
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) skip() {
+	for !p.eof() {
+		c := p.src[p.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			p.pos++
+			continue
+		}
+		if c == '#' {
+			for !p.eof() && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+			continue
+		}
+		break
+	}
+}
+
+func (p *parser) peek() byte {
+	p.skip()
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) expect(c byte) (err error) {
+	if p.peek() != c {
+		err = fmt.Errorf("expected '%c' at %d", c, p.pos)
+		return
+	}
+	p.pos++
+	return
+}
+
+func (p *parser) name() (name string, err error) {
+	p.skip()
+	start := p.pos
+	for !p.eof() {
+		c := p.src[p.pos]
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (p.pos > start && c >= '0' && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	if start == p.pos {
+		err = fmt.Errorf("expected name at %d", p.pos)
+		return
+	}
+	name = p.src[start:p.pos]
+	return
+}
+
+func (p *parser) operation() (op operation, err error) {
+	if p.peek() == '{' {
+		op.kind = "query"
+		op.selections, err = p.selectionSet()
+		return
+	}
+	op.kind, err = p.name()
+	if err != nil {
+		return
+	}
+	if op.kind != "query" && op.kind != "mutation" {
+		err = fmt.Errorf("%s is not supported", op.kind)
+		return
+	}
+	if c := p.peek(); c != '{' && c != '(' && c != '@' {
+		op.name, err = p.name()
+		if err != nil {
+			return
+		}
+	}
+	// variable definitions and directives are skipped, variables are read from request
+	if p.peek() == '(' {
+		if err = p.skipBlock('(', ')'); err != nil {
+			return
+		}
+	}
+	if err = p.skipDirectives(); err != nil {
+		return
+	}
+	op.selections, err = p.selectionSet()
+	return
+}
+
+func (p *parser) skipBlock(open byte, close byte) (err error) {
+	if err = p.expect(open); err != nil {
+		return
+	}
+	depth := 1
+	for !p.eof() && depth > 0 {
+		c := p.src[p.pos]
+		if c == '"' {
+			if _, err = p.string(); err != nil {
+				return
+			}
+			continue
+		}
+		if c == open {
+			depth++
+		} else if c == close {
+			depth--
+		}
+		p.pos++
+	}
+	if depth > 0 {
+		err = fmt.Errorf("expected '%c' at %d", close, p.pos)
+		return
+	}
+	return
+}
+
+func (p *parser) skipDirectives() (err error) {
+	for p.peek() == '@' {
+		p.pos++
+		if _, err = p.name(); err != nil {
+			return
+		}
+		if p.peek() == '(' {
+			if err = p.skipBlock('(', ')'); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+func (p *parser) selectionSet() (selections []selection, err error) {
+	if err = p.expect('{'); err != nil {
+		return
+	}
+	selections = make([]selection, 0, 1)
+	for {
+		c := p.peek()
+		if c == 0 {
+			err = fmt.Errorf("expected '}' at %d", p.pos)
+			return
+		}
+		if c == '}' {
+			p.pos++
+			break
+		}
+		if c == '.' {
+			err = fmt.Errorf("fragment is not supported")
+			return
+		}
+		s := selection{}
+		s.name, err = p.name()
+		if err != nil {
+			return
+		}
+		if p.peek() == ':' {
+			p.pos++
+			s.alias = s.name
+			s.name, err = p.name()
+			if err != nil {
+				return
+			}
+		}
+		if p.peek() == '(' {
+			p.pos++
+			s.arguments = make(map[string]any)
+			for p.peek() != ')' {
+				if p.eof() {
+					err = fmt.Errorf("expected ')' at %d", p.pos)
+					return
+				}
+				argName, argNameErr := p.name()
+				if argNameErr != nil {
+					err = argNameErr
+					return
+				}
+				if err = p.expect(':'); err != nil {
+					return
+				}
+				s.arguments[argName], err = p.value()
+				if err != nil {
+					return
+				}
+			}
+			p.pos++
+		}
+		if p.peek() == '@' {
+			err = fmt.Errorf("directive on field is not supported")
+			return
+		}
+		if p.peek() == '{' {
+			s.selections, err = p.selectionSet()
+			if err != nil {
+				return
+			}
+		}
+		selections = append(selections, s)
+	}
+	return
+}
+
+func (p *parser) value() (v any, err error) {
+	c := p.peek()
+	switch {
+	case c == '$':
+		p.pos++
+		name, nameErr := p.name()
+		if nameErr != nil {
+			err = nameErr
+			return
+		}
+		v = variable(name)
+		break
+	case c == '"':
+		v, err = p.string()
+		break
+	case c == '-' || (c >= '0' && c <= '9'):
+		v, err = p.number()
+		break
+	case c == '[':
+		p.pos++
+		list := make([]any, 0, 1)
+		for p.peek() != ']' {
+			if p.eof() {
+				err = fmt.Errorf("expected ']' at %d", p.pos)
+				return
+			}
+			item, itemErr := p.value()
+			if itemErr != nil {
+				err = itemErr
+				return
+			}
+			list = append(list, item)
+		}
+		p.pos++
+		v = list
+		break
+	case c == '{':
+		p.pos++
+		object := make(map[string]any)
+		for p.peek() != '}' {
+			if p.eof() {
+				err = fmt.Errorf("expected '}' at %d", p.pos)
+				return
+			}
+			key, keyErr := p.name()
+			if keyErr != nil {
+				err = keyErr
+				return
+			}
+			if err = p.expect(':'); err != nil {
+				return
+			}
+			object[key], err = p.value()
+			if err != nil {
+				return
+			}
+		}
+		p.pos++
+		v = object
+		break
+	default:
+		name, nameErr := p.name()
+		if nameErr != nil {
+			err = nameErr
+			return
+		}
+		switch name {
+		case "true":
+			v = true
+			break
+		case "false":
+			v = false
+			break
+		case "null":
+			v = nil
+			break
+		default:
+			// enum value
+			v = name
+			break
+		}
+		break
+	}
+	return
+}
+
+func (p *parser) number() (v any, err error) {
+	start := p.pos
+	float := false
+	for !p.eof() {
+		c := p.src[p.pos]
+		if c == '.' || c == 'e' || c == 'E' || c == '+' {
+			float = true
+		} else if c != '-' && (c < '0' || c > '9') {
+			break
+		}
+		p.pos++
+	}
+	s := p.src[start:p.pos]
+	if float {
+		v, err = strconv.ParseFloat(s, 64)
+	} else {
+		v, err = strconv.ParseInt(s, 10, 64)
+	}
+	return
+}
+
+func (p *parser) string() (v string, err error) {
+	if strings.HasPrefix(p.src[p.pos:], `"""`) {
+		end := strings.Index(p.src[p.pos+3:], `"""`)
+		if end < 0 {
+			err = fmt.Errorf("unterminated block string at %d", p.pos)
+			return
+		}
+		v = p.src[p.pos+3 : p.pos+3+end]
+		p.pos = p.pos + 6 + end
+		return
+	}
+	start := p.pos
+	p.pos++
+	for !p.eof() {
+		c := p.src[p.pos]
+		if c == '\\' {
+			p.pos += 2
+			continue
+		}
+		p.pos++
+		if c == '"' {
+			v, err = strconv.Unquote(p.src[start:p.pos])
+			return
+		}
+	}
+	err = fmt.Errorf("unterminated string at %d", start)
+	return
+}
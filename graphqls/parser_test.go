@@ -0,0 +1,64 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package graphqls
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	query := `
+# list users
+query List($offset: Int = 0) {
+	users: users_list(param: {offset: $offset, limit: 10, name: "a\"b"}) {
+		id
+		name
+	}
+	__typename
+}
+mutation Remove {
+	users_remove(param: {id: "1"})
+}
+`
+	op, err := parse(query, "List")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if op.kind != "query" || op.name != "List" || len(op.selections) != 2 {
+		t.Fatal("invalid operation", op)
+	}
+	users := op.selections[0]
+	if users.key() != "users" || users.name != "users_list" || len(users.selections) != 2 {
+		t.Fatal("invalid selection", users)
+	}
+	param := resolve(users.arguments["param"], map[string]any{"offset": 5}).(map[string]any)
+	if param["offset"] != 5 || param["limit"] != int64(10) || param["name"] != `a"b` {
+		t.Fatal("invalid param", param)
+	}
+	value := project([]any{map[string]any{"id": "1", "name": "a", "age": 1}}, users.selections)
+	item := value.([]any)[0].(map[string]any)
+	if len(item) != 2 || item["id"] != "1" {
+		t.Fatal("invalid projection", item)
+	}
+	if _, err = parse(query, ""); err == nil {
+		t.Fatal("operation name should be required")
+	}
+	if _, err = parse(`{ ...users }`, ""); err == nil {
+		t.Fatal("fragment should not be supported")
+	}
+}
@@ -0,0 +1,304 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package graphqls
+
+import (
+	"fmt"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/documents"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// field
+// root field of schema, which is named `{service}_{fn}`.
+type field struct {
+	name          string
+	service       string
+	fn            string
+	readonly      bool
+	authorization bool
+	deprecated    bool
+	description   string
+	param         string
+	result        string
+}
+
+// Schema
+// each non-internal fn of non-internal endpoint is a root field, readonly fn is a query, others are mutations.
+// param of fn is the `param` argument of field.
+type Schema struct {
+	fields    map[string]field
+	queries   []field
+	mutations []field
+	objects   map[string]string
+	inputs    map[string]string
+	names     map[string]string
+}
+
+func NewSchema(infos services.EndpointInfos) (schema *Schema) {
+	schema = &Schema{
+		fields:    make(map[string]field),
+		queries:   make([]field, 0, 1),
+		mutations: make([]field, 0, 1),
+		objects:   make(map[string]string),
+		inputs:    make(map[string]string),
+		names:     make(map[string]string),
+	}
+	for _, info := range infos {
+		if info.Internal || !info.Document.Defined() {
+			continue
+		}
+		document := info.Document
+		for _, fn := range document.Functions {
+			if fn.Internal {
+				continue
+			}
+			f := field{
+				name:          fieldName(document.Name, fn.Name),
+				service:       document.Name,
+				fn:            fn.Name,
+				readonly:      fn.Readonly,
+				authorization: fn.Authorization,
+				deprecated:    fn.Deprecated,
+				description:   fn.Description,
+			}
+			if fn.Param.Exist() {
+				f.param = schema.typeOf(document, fn.Param, true)
+			}
+			if fn.Result.Exist() {
+				f.result = schema.typeOf(document, fn.Result, false)
+			} else {
+				f.result = "JSON"
+			}
+			schema.fields[f.name] = f
+			if f.readonly {
+				schema.queries = append(schema.queries, f)
+			} else {
+				schema.mutations = append(schema.mutations, f)
+			}
+		}
+	}
+	return
+}
+
+func (schema *Schema) field(name string) (f field, has bool) {
+	f, has = schema.fields[name]
+	return
+}
+
+// String
+// returns schema definition language of schema.
+func (schema *Schema) String() string {
+	b := new(strings.Builder)
+	b.WriteString("scalar Long\n\nscalar JSON\n\ndirective @authorization on FIELD_DEFINITION\n\n")
+	b.WriteString("type Query {\n")
+	if len(schema.queries) == 0 {
+		b.WriteString("  _empty: Boolean\n")
+	}
+	writeFields(b, schema.queries)
+	b.WriteString("}\n")
+	if len(schema.mutations) > 0 {
+		b.WriteString("\ntype Mutation {\n")
+		writeFields(b, schema.mutations)
+		b.WriteString("}\n")
+	}
+	writeTypes(b, schema.objects)
+	writeTypes(b, schema.inputs)
+	return b.String()
+}
+
+func writeFields(b *strings.Builder, fields []field) {
+	for _, f := range fields {
+		if f.description != "" {
+			b.WriteString(fmt.Sprintf("  %q\n", f.description))
+		}
+		b.WriteString("  ")
+		b.WriteString(f.name)
+		if f.param != "" {
+			b.WriteString("(param: ")
+			b.WriteString(f.param)
+			b.WriteString(")")
+		}
+		b.WriteString(": ")
+		b.WriteString(f.result)
+		if f.authorization {
+			b.WriteString(" @authorization")
+		}
+		if f.deprecated {
+			b.WriteString(" @deprecated")
+		}
+		b.WriteString("\n")
+	}
+}
+
+func writeTypes(b *strings.Builder, types map[string]string) {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString("\n")
+		b.WriteString(types[name])
+	}
+}
+
+func (schema *Schema) typeOf(document documents.Endpoint, element documents.Element, input bool) (typ string) {
+	if element.IsRef() {
+		target, found := findElement(document, element)
+		if !found {
+			typ = "JSON"
+			return
+		}
+		element = target
+	}
+	switch element.Type {
+	case "string":
+		typ = "String"
+		break
+	case "boolean":
+		typ = "Boolean"
+		break
+	case "integer":
+		if element.Format == "int32" {
+			typ = "Int"
+		} else {
+			typ = "Long"
+		}
+		break
+	case "number":
+		typ = "Float"
+		break
+	case "array":
+		item, hasItem := element.GetItem()
+		if !hasItem {
+			typ = "JSON"
+			break
+		}
+		typ = "[" + schema.typeOf(document, item, input) + "]"
+		break
+	case "object":
+		if element.IsAny() || element.IsAdditional() || len(element.Properties) == 0 {
+			typ = "JSON"
+			break
+		}
+		typ = schema.objectOf(document, element, input)
+		break
+	default:
+		typ = "JSON"
+		break
+	}
+	if element.Required {
+		typ = typ + "!"
+	}
+	return
+}
+
+func (schema *Schema) objectOf(document documents.Endpoint, element documents.Element, input bool) (name string) {
+	name = schema.nameOf(element)
+	types := schema.objects
+	keyword := "type"
+	if input {
+		name = name + "Input"
+		types = schema.inputs
+		keyword = "input"
+	}
+	if _, has := types[name]; has {
+		return
+	}
+	// placeholder for recursive types
+	types[name] = ""
+	b := new(strings.Builder)
+	if element.Description != "" {
+		b.WriteString(fmt.Sprintf("%q\n", element.Description))
+	}
+	b.WriteString(keyword)
+	b.WriteString(" ")
+	b.WriteString(name)
+	b.WriteString(" {\n")
+	for _, property := range element.Properties {
+		b.WriteString("  ")
+		b.WriteString(property.Name)
+		b.WriteString(": ")
+		b.WriteString(schema.typeOf(document, property.Element, input))
+		if property.Element.Deprecated && !input {
+			b.WriteString(" @deprecated")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+	types[name] = b.String()
+	return
+}
+
+func (schema *Schema) nameOf(element documents.Element) (name string) {
+	key := element.Key()
+	name, has := schema.names[key]
+	if has {
+		return
+	}
+	name = typeName(element.Name)
+	for _, exist := range schema.names {
+		if exist == name {
+			name = typeName(element.Path[strings.LastIndexByte(element.Path, '/')+1:]) + name
+			break
+		}
+	}
+	schema.names[key] = name
+	return
+}
+
+func findElement(document documents.Endpoint, ref documents.Element) (element documents.Element, found bool) {
+	for _, e := range document.Elements {
+		if e.Path == ref.Path && e.Name == ref.Name {
+			element = e
+			element.Required = ref.Required
+			found = true
+			return
+		}
+	}
+	return
+}
+
+func fieldName(service string, fn string) string {
+	return sanitize(service) + "_" + sanitize(fn)
+}
+
+func typeName(s string) string {
+	s = sanitize(s)
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func sanitize(s string) string {
+	b := new(strings.Builder)
+	for i, r := range s {
+		if r == '_' || (r < unicode.MaxASCII && (unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)))) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
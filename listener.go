@@ -0,0 +1,110 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fns
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/transports"
+	"strconv"
+	"time"
+)
+
+// listener
+// an additional transport of application, such as internal listener.
+type listener struct {
+	name        string
+	log         logs.Logger
+	transport   transports.Transport
+	middlewares transports.Middlewares
+	mux         *transports.Mux
+}
+
+func newListener(name string, log logs.Logger, rt *runtime.Runtime, transport transports.Transport, config transports.Config, handlers []transports.MuxHandler) (ln *listener, err error) {
+	log = log.With("listener", name)
+	middleware, middlewareErr := transports.WaveMiddlewares(log, config, []transports.Middleware{runtime.Middleware(rt)})
+	if middlewareErr != nil {
+		err = errors.Warning("fns: new listener failed").WithCause(middlewareErr).WithMeta("listener", name)
+		return
+	}
+	mux := transports.NewMux()
+	mux.SetBasePath(config.GetBasePath())
+	for _, handler := range handlers {
+		handlerConfig, handlerConfigErr := config.HandlerConfig(handler.Name())
+		if handlerConfigErr != nil {
+			err = errors.Warning("fns: new listener failed").WithCause(handlerConfigErr).WithMeta("listener", name).WithMeta("handler", handler.Name())
+			return
+		}
+		handlerErr := handler.Construct(transports.MuxHandlerOptions{
+			Log:    log.With("handler", handler.Name()),
+			Config: handlerConfig,
+		})
+		if handlerErr != nil {
+			err = errors.Warning("fns: new listener failed").WithCause(handlerErr).WithMeta("listener", name).WithMeta("handler", handler.Name())
+			return
+		}
+		mux.Add(handler)
+	}
+	transportErr := transport.Construct(transports.Options{
+		Log:     log.With("transport", transport.Name()),
+		Config:  config,
+		Handler: middleware.Handler(mux),
+	})
+	if transportErr != nil {
+		err = errors.Warning("fns: new listener failed").WithCause(transportErr).WithMeta("listener", name)
+		return
+	}
+	ln = &listener{
+		name:        name,
+		log:         log,
+		transport:   transport,
+		middlewares: middleware,
+		mux:         mux,
+	}
+	return
+}
+
+func (ln *listener) serve() (err error) {
+	errs := make(chan error, 1)
+	go func(transport transports.Transport, errs chan error) {
+		lnErr := transport.ListenAndServe()
+		if lnErr != nil {
+			errs <- lnErr
+			close(errs)
+		}
+	}(ln.transport, errs)
+	select {
+	case lnErr := <-errs:
+		err = errors.Warning("fns: listener serve failed").WithCause(lnErr).WithMeta("listener", ln.name)
+		return
+	case <-time.After(3 * time.Second):
+		break
+	}
+	if ln.log.DebugEnabled() {
+		ln.log.Debug().With("port", strconv.Itoa(ln.transport.Port())).Message("fns: listener is serving...")
+	}
+	return
+}
+
+func (ln *listener) shutdown(ctx context.Context) {
+	ln.middlewares.Close()
+	ln.transport.Shutdown(ctx)
+	_ = ln.mux.Close()
+}
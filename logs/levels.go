@@ -0,0 +1,236 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/logs"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// componentKeys
+	// value of With by these keys names the component of logger, such as With("fns", "endpoints") or With("service", "users").
+	componentKeys = map[string]struct{}{"fns": {}, "service": {}}
+)
+
+// LevelAdjustable
+// is implemented by logger of New, levels of it can be changed at runtime.
+type LevelAdjustable interface {
+	// SetLevel
+	// sets level of component, empty component means the global level.
+	// empty level reverts it, the global level reverts to the configured one, and a component reverts to the global level.
+	SetLevel(component string, level Level) (err error)
+	// Levels
+	// returns the global level and levels of components.
+	Levels() (global Level, components map[string]Level)
+}
+
+// SetLevel
+// sets level of log at runtime, see LevelAdjustable.
+func SetLevel(log Logger, component string, level Level) (err error) {
+	adjustable, ok := log.(LevelAdjustable)
+	if !ok {
+		err = errors.Warning("fns: set log level failed").WithCause(fmt.Errorf("log is not adjustable"))
+		return
+	}
+	err = adjustable.SetLevel(component, level)
+	return
+}
+
+func (level Level) Valid() bool {
+	switch level {
+	case Debug, Info, Warn, Error:
+		return true
+	default:
+		return false
+	}
+}
+
+func newLevels(configured Level) *levels {
+	if !configured.Valid() {
+		configured = Info
+	}
+	v := &levels{
+		configured: configured,
+	}
+	v.global.Store(int32(configured.Code()))
+	return v
+}
+
+type levels struct {
+	configured Level
+	global     atomic.Int32
+	overrides  atomic.Int32
+	components sync.Map
+}
+
+func (lv *levels) level(component string) logs.Level {
+	if component != "" && lv.overrides.Load() > 0 {
+		if v, has := lv.components.Load(component); has {
+			return v.(logs.Level)
+		}
+	}
+	return logs.Level(lv.global.Load())
+}
+
+func (lv *levels) set(component string, level Level) (err error) {
+	if level != "" && !level.Valid() {
+		err = errors.Warning("fns: set log level failed").WithCause(fmt.Errorf("level must be debug, info, warn or error")).WithMeta("level", string(level))
+		return
+	}
+	if component == "" {
+		if level == "" {
+			level = lv.configured
+		}
+		lv.global.Store(int32(level.Code()))
+		return
+	}
+	if level == "" {
+		if _, loaded := lv.components.LoadAndDelete(component); loaded {
+			lv.overrides.Add(-1)
+		}
+		return
+	}
+	if _, loaded := lv.components.Swap(component, level.Code()); !loaded {
+		lv.overrides.Add(1)
+	}
+	return
+}
+
+func (lv *levels) snapshot() (global Level, components map[string]Level) {
+	global = levelOf(logs.Level(lv.global.Load()))
+	components = make(map[string]Level)
+	lv.components.Range(func(key, value any) bool {
+		components[key.(string)] = levelOf(value.(logs.Level))
+		return true
+	})
+	return
+}
+
+func levelOf(code logs.Level) Level {
+	switch code {
+	case logs.DebugLevel:
+		return Debug
+	case logs.WarnLevel:
+		return Warn
+	case logs.ErrorLevel:
+		return Error
+	default:
+		return Info
+	}
+}
+
+// leveledLogger
+// underlying logger accepts every level, entries are filtered by levels, so they can be lowered at runtime.
+type leveledLogger struct {
+	logs.Logger
+	levels    *levels
+	component string
+}
+
+func (log *leveledLogger) With(key string, value any) logs.Logger {
+	component := log.component
+	if _, has := componentKeys[key]; has {
+		if name, ok := value.(string); ok {
+			component = name
+		}
+	}
+	return &leveledLogger{
+		Logger:    log.Logger.With(key, value),
+		levels:    log.levels,
+		component: component,
+	}
+}
+
+func (log *leveledLogger) SetLevel(component string, level Level) (err error) {
+	err = log.levels.set(component, level)
+	return
+}
+
+func (log *leveledLogger) Levels() (global Level, components map[string]Level) {
+	global, components = log.levels.snapshot()
+	return
+}
+
+func (log *leveledLogger) DebugEnabled() bool {
+	return log.levels.level(log.component) <= logs.DebugLevel
+}
+
+func (log *leveledLogger) Debug() logs.Event {
+	if log.DebugEnabled() {
+		return log.Logger.Debug()
+	}
+	return discard
+}
+
+func (log *leveledLogger) InfoEnabled() bool {
+	return log.levels.level(log.component) <= logs.InfoLevel
+}
+
+func (log *leveledLogger) Info() logs.Event {
+	if log.InfoEnabled() {
+		return log.Logger.Info()
+	}
+	return discard
+}
+
+func (log *leveledLogger) WarnEnabled() bool {
+	return log.levels.level(log.component) <= logs.WarnLevel
+}
+
+func (log *leveledLogger) Warn() logs.Event {
+	if log.WarnEnabled() {
+		return log.Logger.Warn()
+	}
+	return discard
+}
+
+func (log *leveledLogger) Shutdown(ctx context.Context) (err error) {
+	err = log.Logger.Shutdown(ctx)
+	return
+}
+
+var (
+	discard logs.Event = &discardEvent{}
+)
+
+type discardEvent struct{}
+
+func (e *discardEvent) Message(_ string) {}
+
+func (e *discardEvent) MessageF(_ string, _ ...any) {}
+
+func (e *discardEvent) Cause(_ error) logs.Event {
+	return e
+}
+
+func (e *discardEvent) Caller() logs.Event {
+	return e
+}
+
+func (e *discardEvent) CallerWithSkip(_ int) logs.Event {
+	return e
+}
+
+func (e *discardEvent) With(_ string, _ any) logs.Event {
+	return e
+}
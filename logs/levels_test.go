@@ -0,0 +1,95 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package logs_test
+
+import (
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	entry "github.com/aacfactory/logs"
+	"testing"
+)
+
+func TestSetLevel(t *testing.T) {
+	log, err := logs.New(logs.Config{Level: logs.Info, DisableConsole: true}, []logs.Writer{&discardWriter{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Shutdown(context.TODO())
+	endpoints := log.With("fns", "endpoints")
+	users := log.With("service", "users").With("fn", "get")
+	if endpoints.DebugEnabled() || users.DebugEnabled() {
+		t.Fatal("debug is enabled at info")
+	}
+	// global
+	if err = logs.SetLevel(log, "", logs.Debug); err != nil {
+		t.Fatal(err)
+	}
+	if !endpoints.DebugEnabled() || !users.DebugEnabled() {
+		t.Fatal("debug is not enabled globally")
+	}
+	if err = logs.SetLevel(log, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if endpoints.DebugEnabled() || !endpoints.InfoEnabled() {
+		t.Fatal("global level was not reverted")
+	}
+	// component
+	if err = logs.SetLevel(endpoints, "users", logs.Debug); err != nil {
+		t.Fatal(err)
+	}
+	if !users.DebugEnabled() || endpoints.DebugEnabled() {
+		t.Fatal("level of component was not changed alone")
+	}
+	if err = logs.SetLevel(log, "endpoints", logs.Error); err != nil {
+		t.Fatal(err)
+	}
+	if endpoints.WarnEnabled() || !endpoints.ErrorEnabled() {
+		t.Fatal("level of component was not raised")
+	}
+	global, components := log.(logs.LevelAdjustable).Levels()
+	if global != logs.Info || components["users"] != logs.Debug || components["endpoints"] != logs.Error {
+		t.Fatal("unexpected levels", global, components)
+	}
+	if err = logs.SetLevel(log, "users", ""); err != nil {
+		t.Fatal(err)
+	}
+	if users.DebugEnabled() {
+		t.Fatal("level of component was not reverted")
+	}
+	if err = logs.SetLevel(log, "", "verbose"); err == nil {
+		t.Fatal("invalid level was accepted")
+	}
+}
+
+type discardWriter struct{}
+
+func (w *discardWriter) Name() string {
+	return "discard"
+}
+
+func (w *discardWriter) Construct(_ logs.WriterOptions) error {
+	return nil
+}
+
+func (w *discardWriter) Write(_ entry.Entry) {}
+
+func (w *discardWriter) Shutdown(_ context.Context) {}
+
+func (w *discardWriter) Close() error {
+	return nil
+}
@@ -109,6 +109,27 @@ type Config struct {
 	SendTimeout     string               `json:"sendTimeout,omitempty" yaml:"sendTimeout,omitempty"`
 	ShutdownTimeout string               `json:"shutdownTimeout,omitempty" yaml:"shutdownTimeout,omitempty"`
 	Writers         []WriterConfig       `json:"writers,omitempty" yaml:"writer,omitempty"`
+	Sampling        *SamplingConfig      `json:"sampling,omitempty" yaml:"sampling,omitempty"`
+	// SlowThreshold
+	// request whose latency exceeds it is logged as a warning, such as 500ms, empty means disabled.
+	SlowThreshold string `json:"slowThreshold,omitempty" yaml:"slowThreshold,omitempty"`
+	// DisableBanner
+	// disables the info log of effective settings when application is running.
+	// it is always disabled when formatter is json or level is above info.
+	DisableBanner bool `json:"disableBanner,omitempty" yaml:"disableBanner,omitempty"`
+}
+
+func (config *Config) GetSlowThreshold() (threshold time.Duration, err error) {
+	value := strings.TrimSpace(config.SlowThreshold)
+	if value == "" {
+		return
+	}
+	threshold, err = time.ParseDuration(value)
+	if err != nil {
+		err = errors.Warning("fns: get slow threshold of log failed").WithCause(err).WithMeta("slowThreshold", value)
+		return
+	}
+	return
 }
 
 func (config *Config) GetWriter(name string) (writer configures.Config, err error) {
@@ -124,7 +145,8 @@ func (config *Config) GetWriter(name string) (writer configures.Config, err erro
 
 func New(config Config, writers []Writer) (v Logger, err error) {
 	options := make([]logs.Option, 0, 1)
-	options = append(options, logs.WithLevel(config.Level.Code()))
+	// levels are filtered by leveledLogger, so the underlying one accepts every level.
+	options = append(options, logs.WithLevel(logs.DebugLevel))
 
 	if config.DisableConsole {
 		options = append(options, logs.DisableConsoleWriter())
@@ -171,11 +193,27 @@ func New(config Config, writers []Writer) (v Logger, err error) {
 			options = append(options, logs.WithWriter(writer))
 		}
 	}
-	logger, newErr := logs.New(options...)
+	underlying, newErr := logs.New(options...)
 	if newErr != nil {
 		err = errors.Warning("fns: new log failed").WithCause(newErr)
 		return
 	}
+	logger := &leveledLogger{
+		Logger: underlying,
+		levels: newLevels(config.Level),
+	}
+	if sampling := config.Sampling; sampling != nil && sampling.Enable {
+		s, samplerErr := newSampler(sampling)
+		if samplerErr != nil {
+			err = errors.Warning("fns: new log failed").WithCause(samplerErr)
+			return
+		}
+		v = &sampledLogger{
+			Logger:  logger,
+			sampler: s,
+		}
+		return
+	}
 	v = logger
 	return
 }
@@ -0,0 +1,203 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package logs
+
+import (
+	"context"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/logs"
+	"hash/fnv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	samplingCounters = 4096
+)
+
+type SamplingConfig struct {
+	Enable     bool   `json:"enable,omitempty" yaml:"enable,omitempty"`
+	Interval   string `json:"interval,omitempty" yaml:"interval,omitempty"`
+	First      uint64 `json:"first,omitempty" yaml:"first,omitempty"`
+	Thereafter uint64 `json:"thereafter,omitempty" yaml:"thereafter,omitempty"`
+}
+
+func newSampler(config *SamplingConfig) (v *sampler, err error) {
+	interval := time.Second
+	if s := strings.TrimSpace(config.Interval); s != "" {
+		interval, err = time.ParseDuration(s)
+		if err != nil {
+			err = errors.Warning("fns: new log sampler failed").WithCause(err).WithMeta("config", "sampling.interval")
+			return
+		}
+		if interval < time.Millisecond {
+			interval = time.Second
+		}
+	}
+	first := config.First
+	if first == 0 {
+		first = 100
+	}
+	thereafter := config.Thereafter
+	if thereafter == 0 {
+		thereafter = 100
+	}
+	v = &sampler{
+		interval:   int64(interval),
+		first:      first,
+		thereafter: thereafter,
+		counters:   make([]samplingCounter, samplingCounters),
+	}
+	return
+}
+
+type samplingCounter struct {
+	resetAt atomic.Int64
+	n       atomic.Uint64
+}
+
+// sampler
+// keeps the first N entries of each level and message per interval, then keeps one in every M.
+type sampler struct {
+	interval   int64
+	first      uint64
+	thereafter uint64
+	counters   []samplingCounter
+}
+
+func (s *sampler) allow(level logs.Level, message string) (ok bool) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte{byte(level)})
+	_, _ = h.Write([]byte(message))
+	counter := &s.counters[h.Sum32()%samplingCounters]
+	now := time.Now().UnixNano()
+	resetAt := counter.resetAt.Load()
+	if resetAt < now && counter.resetAt.CompareAndSwap(resetAt, now+s.interval) {
+		counter.n.Store(0)
+	}
+	n := counter.n.Add(1)
+	if n <= s.first {
+		ok = true
+		return
+	}
+	ok = (n-s.first)%s.thereafter == 0
+	return
+}
+
+// sampledLogger
+// error entries are never sampled.
+type sampledLogger struct {
+	logs.Logger
+	sampler *sampler
+}
+
+func (log *sampledLogger) With(key string, value any) logs.Logger {
+	return &sampledLogger{
+		Logger:  log.Logger.With(key, value),
+		sampler: log.sampler,
+	}
+}
+
+func (log *sampledLogger) SetLevel(component string, level Level) (err error) {
+	err = SetLevel(log.Logger, component, level)
+	return
+}
+
+func (log *sampledLogger) Levels() (global Level, components map[string]Level) {
+	if adjustable, ok := log.Logger.(LevelAdjustable); ok {
+		global, components = adjustable.Levels()
+	}
+	return
+}
+
+func (log *sampledLogger) Debug() logs.Event {
+	if log.Logger.DebugEnabled() {
+		return &sampledEvent{
+			Event:   log.Logger.Debug(),
+			level:   logs.DebugLevel,
+			sampler: log.sampler,
+		}
+	}
+	return log.Logger.Debug()
+}
+
+func (log *sampledLogger) Info() logs.Event {
+	if log.Logger.InfoEnabled() {
+		return &sampledEvent{
+			Event:   log.Logger.Info(),
+			level:   logs.InfoLevel,
+			sampler: log.sampler,
+		}
+	}
+	return log.Logger.Info()
+}
+
+func (log *sampledLogger) Warn() logs.Event {
+	if log.Logger.WarnEnabled() {
+		return &sampledEvent{
+			Event:   log.Logger.Warn(),
+			level:   logs.WarnLevel,
+			sampler: log.sampler,
+		}
+	}
+	return log.Logger.Warn()
+}
+
+func (log *sampledLogger) Shutdown(ctx context.Context) (err error) {
+	err = log.Logger.Shutdown(ctx)
+	return
+}
+
+type sampledEvent struct {
+	logs.Event
+	level   logs.Level
+	sampler *sampler
+}
+
+func (e *sampledEvent) Message(message string) {
+	if e.sampler.allow(e.level, message) {
+		e.Event.Message(message)
+	}
+}
+
+func (e *sampledEvent) MessageF(format string, a ...any) {
+	if e.sampler.allow(e.level, format) {
+		e.Event.MessageF(format, a...)
+	}
+}
+
+func (e *sampledEvent) Cause(err error) logs.Event {
+	e.Event = e.Event.Cause(err)
+	return e
+}
+
+func (e *sampledEvent) Caller() logs.Event {
+	e.Event = e.Event.CallerWithSkip(2)
+	return e
+}
+
+func (e *sampledEvent) CallerWithSkip(skip int) logs.Event {
+	e.Event = e.Event.CallerWithSkip(skip + 1)
+	return e
+}
+
+func (e *sampledEvent) With(key string, value any) logs.Event {
+	e.Event = e.Event.With(key, value)
+	return e
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package logs
+
+import (
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/logs"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  SamplingConfig
+		entries int
+		allowed int
+	}{
+		{"under first", SamplingConfig{First: 5, Thereafter: 3}, 4, 4},
+		{"first", SamplingConfig{First: 5, Thereafter: 3}, 5, 5},
+		{"thereafter", SamplingConfig{First: 5, Thereafter: 3}, 14, 8},
+		{"defaults", SamplingConfig{}, 400, 103},
+	}
+	for _, c := range cases {
+		c.config.Interval = "1h"
+		s, err := newSampler(&c.config)
+		if err != nil {
+			t.Fatal(c.name, err)
+		}
+		allowed := 0
+		for i := 0; i < c.entries; i++ {
+			if s.allow(logs.InfoLevel, "message") {
+				allowed++
+			}
+		}
+		if allowed != c.allowed {
+			t.Fatal(c.name, "want", c.allowed, "got", allowed)
+		}
+		// keyed by level and message
+		if !s.allow(logs.WarnLevel, "message") || !s.allow(logs.InfoLevel, "another") {
+			t.Fatal(c.name, "first entry of another key was sampled")
+		}
+	}
+}
+
+func TestSampler_Tick(t *testing.T) {
+	s, err := newSampler(&SamplingConfig{Interval: "50ms", First: 2, Thereafter: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tick := 0; tick < 3; tick++ {
+		allowed := 0
+		for i := 0; i < 5; i++ {
+			if s.allow(logs.InfoLevel, "message") {
+				allowed++
+			}
+		}
+		if allowed != 2 {
+			t.Fatal("counter was not reset at tick", tick, allowed)
+		}
+		time.Sleep(60 * time.Millisecond)
+	}
+}
+
+type countWriter struct {
+	entries map[logs.Level]*atomic.Int64
+}
+
+func (w *countWriter) Name() string {
+	return "count"
+}
+
+func (w *countWriter) Construct(_ WriterOptions) error {
+	return nil
+}
+
+func (w *countWriter) Write(entry logs.Entry) {
+	if n, has := w.entries[entry.Level]; has {
+		n.Add(1)
+	}
+}
+
+func (w *countWriter) Shutdown(_ context.Context) {}
+
+func (w *countWriter) Close() error {
+	return nil
+}
+
+// TestSampledLogger_Error
+// error is the highest level, its entries are never sampled.
+func TestSampledLogger_Error(t *testing.T) {
+	w := &countWriter{entries: map[logs.Level]*atomic.Int64{
+		logs.InfoLevel:  new(atomic.Int64),
+		logs.ErrorLevel: new(atomic.Int64),
+	}}
+	log, err := New(Config{
+		Level:          Info,
+		DisableConsole: true,
+		Sampling:       &SamplingConfig{Enable: true, Interval: "1h", First: 2, Thereafter: 100},
+	}, []Writer{w})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		log.Info().Message("message")
+		log.Error().Message("message")
+	}
+	_ = log.Shutdown(context.TODO())
+	if n := w.entries[logs.InfoLevel].Load(); n != 2 {
+		t.Fatal("info entries were not sampled", n)
+	}
+	if n := w.entries[logs.ErrorLevel].Load(); n != 10 {
+		t.Fatal("error entries were sampled", n)
+	}
+}
@@ -26,9 +26,9 @@ import (
 	"github.com/aacfactory/fns/hooks"
 	"github.com/aacfactory/fns/logs"
 	"github.com/aacfactory/fns/proxies"
+	"github.com/aacfactory/fns/services"
 	"github.com/aacfactory/fns/services/validators"
 	"github.com/aacfactory/fns/transports"
-	"github.com/aacfactory/fns/transports/fast"
 	"os"
 	"strings"
 	"time"
@@ -45,8 +45,11 @@ var (
 		version:               versions.New(0, 0, 1),
 		configRetrieverOption: configs.DefaultConfigRetrieverOption(),
 		logWriters:            nil,
-		transport:             fast.New(),
+		transport:             nil,
+		internalTransport:     nil,
+		managementTransport:   nil,
 		middlewares:           make([]transports.Middleware, 0, 1),
+		clientMiddlewares:     nil,
 		handlers:              make([]transports.MuxHandler, 0, 1),
 		hooks:                 nil,
 		shutdownTimeout:       60 * time.Second,
@@ -63,9 +66,15 @@ type Options struct {
 	configRetrieverOption configures.RetrieverOption
 	logWriters            []logs.Writer
 	transport             transports.Transport
+	internalTransport     transports.Transport
+	managementTransport   transports.Transport
 	middlewares           []transports.Middleware
+	clientMiddlewares     []transports.ClientMiddleware
 	handlers              []transports.MuxHandler
 	hooks                 []hooks.Hook
+	requestHooks          []services.Hook
+	panicReporter         services.PanicReporter
+	providers             []services.Provider
 	shutdownTimeout       time.Duration
 	proxyOptions          []proxies.Option
 }
@@ -80,7 +89,14 @@ func ConfigRetriever(path string, format string, active string, prefix string, s
 		}
 		active = strings.TrimSpace(active)
 		format = strings.ToUpper(strings.TrimSpace(format))
-		store := configures.NewFileStore(path, prefix, splitter)
+		// empty format means format of each file is decided by its extension
+		var store configures.Store
+		if format == "" {
+			format = "JSON"
+			store = configs.NewFileStore(path, prefix, splitter)
+		} else {
+			store = configures.NewFileStore(path, prefix, splitter)
+		}
 		o.configRetrieverOption = configures.RetrieverOption{
 			Active: active,
 			Format: format,
@@ -170,6 +186,46 @@ func Hooks(h ...hooks.Hook) Option {
 	}
 }
 
+// PanicReporter
+// sets the reporter of panics recovered from fns, see services.PanicReporter.
+func PanicReporter(reporter services.PanicReporter) Option {
+	return func(options *Options) error {
+		if reporter == nil {
+			return fmt.Errorf("panic reporter is nil")
+		}
+		options.panicReporter = reporter
+		return nil
+	}
+}
+
+// Dependencies
+// appends providers of shared dependencies, such as connection pools, see services.Provider.
+func Dependencies(providers ...services.Provider) Option {
+	return func(options *Options) error {
+		for _, provider := range providers {
+			if provider == nil {
+				continue
+			}
+			options.providers = append(options.providers, provider)
+		}
+		return nil
+	}
+}
+
+// RequestHooks
+// appends hooks which are called asynchronously after fn requests of transport are responded, see services.Hook.
+func RequestHooks(h ...services.Hook) Option {
+	return func(options *Options) error {
+		for _, hook := range h {
+			if hook == nil {
+				continue
+			}
+			options.requestHooks = append(options.requestHooks, hook)
+		}
+		return nil
+	}
+}
+
 // +-------------------------------------------------------------------------------------------------------------------+
 
 func LogWriters(writers ...logs.Writer) Option {
@@ -193,6 +249,8 @@ func ShutdownTimeout(timeout time.Duration) Option {
 
 // +-------------------------------------------------------------------------------------------------------------------+
 
+// Transport
+// set transport of listener, it takes precedence over the transport which is registered by name of transport config.
 func Transport(transport transports.Transport) Option {
 	return func(options *Options) error {
 		options.transport = transport
@@ -200,6 +258,26 @@ func Transport(transport transports.Transport) Option {
 	}
 }
 
+// InternalTransport
+// set transport of internal listener, default is the transport registered by name of internal config.
+// it is used only when internal config is set.
+func InternalTransport(transport transports.Transport) Option {
+	return func(options *Options) error {
+		options.internalTransport = transport
+		return nil
+	}
+}
+
+// ManagementTransport
+// set transport of management listener, default is the transport registered by name of management config.
+// it is used only when management config is set.
+func ManagementTransport(transport transports.Transport) Option {
+	return func(options *Options) error {
+		options.managementTransport = transport
+		return nil
+	}
+}
+
 func Middleware(middleware transports.Middleware) Option {
 	return func(options *Options) error {
 		options.middlewares = append(options.middlewares, middleware)
@@ -207,6 +285,18 @@ func Middleware(middleware transports.Middleware) Option {
 	}
 }
 
+// ClientMiddleware
+// wrap outbound internal calls of cluster and proxy, such as attaching headers or recording metrics.
+func ClientMiddleware(middleware transports.ClientMiddleware) Option {
+	return func(options *Options) error {
+		if middleware == nil {
+			return fmt.Errorf("customize client middleware failed for nil")
+		}
+		options.clientMiddlewares = append(options.clientMiddlewares, middleware)
+		return nil
+	}
+}
+
 func Handler(handler transports.MuxHandler) Option {
 	return func(options *Options) error {
 		options.handlers = append(options.handlers, handler)
@@ -92,6 +92,8 @@ func (handler *proxyHandler) Handle(w transports.ResponseWriter, r transports.Re
 		return
 	}
 	_, _ = groupKeyBuf.Write(deviceId)
+	// tenant
+	_, _ = groupKeyBuf.Write(r.Header().Get(transports.TenantHeaderName))
 
 	// discovery
 	endpointGetOptions := make([]services.EndpointGetOption, 0, 1)
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package runtime
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"github.com/aacfactory/configures"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/json"
+	"strings"
+)
+
+var (
+	configPath                    = []byte("/application/config")
+	configRedactedValue           = "******"
+	configRedactedKeywords        = []string{"password", "secret", "token", "key"}
+	ErrConfigEndpointUnauthorized = errors.Unauthorized("fns: config endpoint requires token")
+)
+
+type ConfigEndpointConfig struct {
+	Enable bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Token
+	// when it is not empty, request must have `Authorization: Bearer {token}` header.
+	Token string `json:"token,omitempty" yaml:"token,omitempty"`
+}
+
+// ConfigHandler
+// serves the effective config at /application/config, values of keys which look like secrets are redacted.
+// config is the one after env interpolation, so it shows what the process actually loaded.
+// it is disabled by default, set transport.handlers.config.enable to enable it, and it should be served by the management transport.
+func ConfigHandler(config configures.Config) transports.MuxHandler {
+	return &configHandler{
+		config: config,
+	}
+}
+
+type configHandler struct {
+	config   configures.Config
+	enable   bool
+	token    []byte
+	redacted json.RawMessage
+}
+
+func (handler *configHandler) Name() string {
+	return "config"
+}
+
+func (handler *configHandler) Construct(options transports.MuxHandlerOptions) (err error) {
+	config := ConfigEndpointConfig{}
+	configErr := options.Config.As(&config)
+	if configErr != nil {
+		err = errors.Warning("fns: construct config handler failed").WithCause(configErr)
+		return
+	}
+	handler.enable = config.Enable
+	if !handler.enable {
+		return
+	}
+	if token := strings.TrimSpace(config.Token); token != "" {
+		handler.token = []byte(token)
+	}
+	if handler.config == nil {
+		handler.redacted = json.RawMessage("{}")
+		return
+	}
+	handler.redacted, err = RedactConfig(handler.config.Raw())
+	if err != nil {
+		err = errors.Warning("fns: construct config handler failed").WithCause(err)
+		return
+	}
+	return
+}
+
+func (handler *configHandler) Match(_ context.Context, method []byte, path []byte, _ transports.Header) bool {
+	if !handler.enable {
+		return false
+	}
+	ok := bytes.Equal(method, transports.MethodGet) && bytes.Equal(path, configPath)
+	return ok
+}
+
+func (handler *configHandler) Handle(w transports.ResponseWriter, r transports.Request) {
+	if len(handler.token) > 0 {
+		authorization := r.Header().Get(transports.AuthorizationHeaderName)
+		token, _ := bytes.CutPrefix(authorization, debugBearerAuthorizationPrefix)
+		if subtle.ConstantTimeCompare(token, handler.token) != 1 {
+			w.Failed(ErrConfigEndpointUnauthorized)
+			return
+		}
+	}
+	w.Succeed(handler.redacted)
+	return
+}
+
+// RedactConfig
+// replaces values of keys which contain password, secret, token or key (case-insensitive) in json config.
+func RedactConfig(raw []byte) (p json.RawMessage, err error) {
+	var v any
+	if err = json.Unmarshal(raw, &v); err != nil {
+		err = errors.Warning("fns: redact config failed").WithCause(err)
+		return
+	}
+	redactConfigValue(v)
+	p, err = json.Marshal(v)
+	if err != nil {
+		err = errors.Warning("fns: redact config failed").WithCause(err)
+		return
+	}
+	return
+}
+
+func redactConfigValue(v any) {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, item := range value {
+			if isSecretConfigKey(key) {
+				value[key] = configRedactedValue
+				continue
+			}
+			redactConfigValue(item)
+		}
+		break
+	case []any:
+		for _, item := range value {
+			redactConfigValue(item)
+		}
+		break
+	default:
+		break
+	}
+}
+
+func isSecretConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, keyword := range configRedactedKeywords {
+		if strings.Contains(key, keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package runtime_test
+
+import (
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/json"
+	"testing"
+)
+
+func TestRedactConfig(t *testing.T) {
+	p, err := runtime.RedactConfig([]byte(`{"db":{"dsn":"x","password":"p","apiKey":"k"},"list":[{"Token":"t","port":1}],"name":"app"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	redacted := struct {
+		Db struct {
+			Dsn      string `json:"dsn"`
+			Password string `json:"password"`
+			ApiKey   string `json:"apiKey"`
+		} `json:"db"`
+		List []struct {
+			Token string `json:"Token"`
+			Port  int    `json:"port"`
+		} `json:"list"`
+		Name string `json:"name"`
+	}{}
+	if err = json.Unmarshal(p, &redacted); err != nil {
+		t.Fatal(err)
+	}
+	if redacted.Db.Dsn != "x" || redacted.Db.Password != "******" || redacted.Db.ApiKey != "******" ||
+		redacted.List[0].Token != "******" || redacted.List[0].Port != 1 || redacted.Name != "app" {
+		t.Fatal("unexpected redacted config", string(p))
+	}
+}
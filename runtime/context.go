@@ -92,6 +92,13 @@ func AcquireLocker(ctx context.Context, key []byte, ttl time.Duration) (locker s
 	return
 }
 
+// Lock
+// takes a lease of key in shared store of cluster, see shareds.Lock.
+func Lock(ctx context.Context, key []byte, ttl time.Duration) (unlock func(), err error) {
+	unlock, err = shareds.Lock(ctx, SharedStore(ctx), key, ttl)
+	return
+}
+
 func SharedStore(ctx context.Context) (store shareds.Store) {
 	rt := Load(ctx)
 	store = rt.Shared().Store()
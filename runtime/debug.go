@@ -0,0 +1,157 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package runtime
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/transports"
+	"net/http"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	debugPathPrefix                = []byte("/application/debug/pprof/")
+	debugSecondsParam              = []byte("seconds")
+	debugContentTypeHeaderValue    = []byte("application/octet-stream")
+	debugContentDispositionName    = []byte("Content-Disposition")
+	debugDefaultProfileSeconds     = 30
+	debugBearerAuthorizationPrefix = []byte("Bearer ")
+	ErrDebugProfileUnauthorized    = errors.Unauthorized("fns: debug profile requires token")
+	ErrDebugProfileNotFound        = errors.NotFound("fns: debug profile was not found")
+	ErrDebugCPUProfileRunning      = errors.New(http.StatusConflict, "***CONFLICT***", "fns: cpu profile is already running")
+)
+
+type DebugConfig struct {
+	Enable bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Token
+	// when it is not empty, request must have `Authorization: Bearer {token}` header.
+	Token string `json:"token,omitempty" yaml:"token,omitempty"`
+	// MaxSeconds
+	// max duration of cpu profile, default is 30 seconds.
+	MaxSeconds int `json:"maxSeconds,omitempty" yaml:"maxSeconds,omitempty"`
+}
+
+// DebugHandler
+// serves goroutine, heap and cpu profiles under /application/debug/pprof/.
+// it is disabled by default, set transport.handlers.debug.enable to enable it.
+// cpu profile duration is set by `seconds` query param.
+func DebugHandler() transports.MuxHandler {
+	return &debugHandler{}
+}
+
+type debugHandler struct {
+	enable     bool
+	token      []byte
+	maxSeconds int
+}
+
+func (handler *debugHandler) Name() string {
+	return "debug"
+}
+
+func (handler *debugHandler) Construct(options transports.MuxHandlerOptions) (err error) {
+	config := DebugConfig{}
+	configErr := options.Config.As(&config)
+	if configErr != nil {
+		err = errors.Warning("fns: construct debug handler failed").WithCause(configErr)
+		return
+	}
+	handler.enable = config.Enable
+	if token := strings.TrimSpace(config.Token); token != "" {
+		handler.token = []byte(token)
+	}
+	handler.maxSeconds = config.MaxSeconds
+	if handler.maxSeconds < 1 {
+		handler.maxSeconds = debugDefaultProfileSeconds
+	}
+	return
+}
+
+func (handler *debugHandler) Match(_ context.Context, method []byte, path []byte, _ transports.Header) bool {
+	if !handler.enable {
+		return false
+	}
+	ok := bytes.Equal(method, transports.MethodGet) && bytes.HasPrefix(path, debugPathPrefix)
+	return ok
+}
+
+func (handler *debugHandler) Handle(w transports.ResponseWriter, r transports.Request) {
+	if len(handler.token) > 0 {
+		authorization := r.Header().Get(transports.AuthorizationHeaderName)
+		token, _ := bytes.CutPrefix(authorization, debugBearerAuthorizationPrefix)
+		if subtle.ConstantTimeCompare(token, handler.token) != 1 {
+			w.Failed(ErrDebugProfileUnauthorized)
+			return
+		}
+	}
+	name := bytex.ToString(r.Path()[len(debugPathPrefix):])
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	switch name {
+	case "profile", "cpu":
+		seconds := debugDefaultProfileSeconds
+		if p := r.Params().Get(debugSecondsParam); len(p) > 0 {
+			n, parseErr := strconv.Atoi(bytex.ToString(p))
+			if parseErr != nil || n < 1 {
+				w.Failed(errors.BadRequest("fns: seconds of cpu profile is invalid").WithMeta("seconds", string(p)))
+				return
+			}
+			seconds = n
+		}
+		if seconds > handler.maxSeconds {
+			seconds = handler.maxSeconds
+		}
+		if startErr := pprof.StartCPUProfile(buf); startErr != nil {
+			w.Failed(ErrDebugCPUProfileRunning.WithCause(startErr))
+			return
+		}
+		timer := time.NewTimer(time.Duration(seconds) * time.Second)
+		select {
+		case <-timer.C:
+			break
+		case <-r.Done():
+			timer.Stop()
+			break
+		}
+		pprof.StopCPUProfile()
+		name = "cpu"
+		break
+	default:
+		profile := pprof.Lookup(name)
+		if profile == nil {
+			w.Failed(ErrDebugProfileNotFound.WithMeta("profile", name))
+			return
+		}
+		if writeErr := profile.WriteTo(buf, 0); writeErr != nil {
+			w.Failed(errors.Warning("fns: write debug profile failed").WithCause(writeErr).WithMeta("profile", name))
+			return
+		}
+		break
+	}
+	w.Header().Set(transports.ContentTypeHeaderName, debugContentTypeHeaderValue)
+	w.Header().Set(debugContentDispositionName, []byte("attachment; filename=\""+name+".pprof\""))
+	w.SetStatus(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+	return
+}
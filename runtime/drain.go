@@ -0,0 +1,100 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package runtime
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DrainConfig
+// config of runtime middleware when application is shutting down, it is under transport.middlewares.runtime.drain.
+//
+// By default, every request after shutdown began is rejected with 503 and `Connection: close`, so all keep-alive
+// connections are closed at once. With RequestsPerConn, each connection still serves that many requests, and the last one
+// carries `Connection: close`, so connections are closed one by one as their clients come back, instead of all together.
+type DrainConfig struct {
+	// RequestsPerConn
+	// number of requests which are served per connection after shutdown began, 0 means none.
+	RequestsPerConn int `json:"requestsPerConn,omitempty" yaml:"requestsPerConn,omitempty"`
+	// Window
+	// max duration of serving after shutdown began, such as 5s, then the rest is rejected. empty means no limit.
+	Window string `json:"window,omitempty" yaml:"window,omitempty"`
+}
+
+type MiddlewareConfig struct {
+	Drain DrainConfig `json:"drain,omitempty" yaml:"drain,omitempty"`
+}
+
+func newDrainer(config DrainConfig) (d *drainer, err error) {
+	if config.RequestsPerConn < 0 {
+		err = errors.Warning("fns: new drainer failed").WithCause(fmt.Errorf("requestsPerConn must not be negative"))
+		return
+	}
+	window := time.Duration(0)
+	if s := strings.TrimSpace(config.Window); s != "" {
+		window, err = time.ParseDuration(s)
+		if err != nil {
+			err = errors.Warning("fns: new drainer failed").WithCause(err).WithMeta("window", s)
+			return
+		}
+	}
+	d = &drainer{
+		requestsPerConn: int64(config.RequestsPerConn),
+		window:          window,
+	}
+	return
+}
+
+type drainer struct {
+	requestsPerConn int64
+	window          time.Duration
+	once            sync.Once
+	deadline        time.Time
+	conns           sync.Map
+}
+
+// admit
+// is called when application is shutting down,
+// serve means request is served, closing means response should have `Connection: close`.
+func (d *drainer) admit(conn []byte, now time.Time) (serve bool, closing bool) {
+	closing = true
+	if d.requestsPerConn < 1 {
+		return
+	}
+	d.once.Do(func() {
+		if d.window > 0 {
+			d.deadline = now.Add(d.window)
+		}
+	})
+	if !d.deadline.IsZero() && now.After(d.deadline) {
+		return
+	}
+	v, _ := d.conns.LoadOrStore(string(conn), new(atomic.Int64))
+	n := v.(*atomic.Int64).Add(1)
+	if n > d.requestsPerConn {
+		return
+	}
+	serve = true
+	closing = n == d.requestsPerConn
+	return
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package runtime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDrainer(t *testing.T) {
+	type step struct {
+		conn    string
+		after   time.Duration
+		serve   bool
+		closing bool
+	}
+	run := func(config DrainConfig, steps []step) {
+		d, err := newDrainer(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		beg := time.Now()
+		for i, s := range steps {
+			serve, closing := d.admit([]byte(s.conn), beg.Add(s.after))
+			if serve != s.serve || closing != s.closing {
+				t.Fatal(config, i, "expect", s.serve, s.closing, "but", serve, closing)
+			}
+		}
+	}
+	// default closes at once
+	run(DrainConfig{}, []step{
+		{conn: "a", serve: false, closing: true},
+	})
+	// each connection serves its own budget, the last one closes
+	run(DrainConfig{RequestsPerConn: 2}, []step{
+		{conn: "a", serve: true, closing: false},
+		{conn: "b", serve: true, closing: false},
+		{conn: "a", serve: true, closing: true},
+		{conn: "a", serve: false, closing: true},
+		{conn: "b", serve: true, closing: true},
+	})
+	// window
+	run(DrainConfig{RequestsPerConn: 3, Window: "1s"}, []step{
+		{conn: "a", serve: true, closing: false},
+		{conn: "a", after: 2 * time.Second, serve: false, closing: true},
+		{conn: "b", after: 2 * time.Second, serve: false, closing: true},
+	})
+}
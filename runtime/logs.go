@@ -0,0 +1,147 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package runtime
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/json"
+	"strings"
+)
+
+var (
+	logLevelPath             = []byte("/application/log/level")
+	ErrLogLevelUnauthorized  = errors.Unauthorized("fns: log level endpoint requires token")
+	ErrLogLevelInvalidBody   = errors.BadRequest("fns: invalid log level body")
+	ErrLogLevelNotAdjustable = errors.Warning("fns: log is not adjustable")
+)
+
+type LogLevelConfig struct {
+	Enable bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Token
+	// when it is not empty, request must have `Authorization: Bearer {token}` header.
+	Token string `json:"token,omitempty" yaml:"token,omitempty"`
+}
+
+type LogLevelParam struct {
+	// Component
+	// empty means the global level.
+	Component string `json:"component"`
+	// Level
+	// debug, info, warn or error, empty reverts it.
+	Level logs.Level `json:"level"`
+}
+
+type LogLevels struct {
+	Level      logs.Level            `json:"level"`
+	Components map[string]logs.Level `json:"components"`
+}
+
+// LogLevelHandler
+// serves levels of log at /application/log/level, GET returns them and PUT changes one of them without restarting,
+// it is disabled by default, set transport.handlers.logs.enable to enable it.
+func LogLevelHandler() transports.MuxHandler {
+	return &logLevelHandler{}
+}
+
+type logLevelHandler struct {
+	log    logs.Logger
+	enable bool
+	token  []byte
+}
+
+func (handler *logLevelHandler) Name() string {
+	return "logs"
+}
+
+func (handler *logLevelHandler) Construct(options transports.MuxHandlerOptions) (err error) {
+	config := LogLevelConfig{}
+	configErr := options.Config.As(&config)
+	if configErr != nil {
+		err = errors.Warning("fns: construct log level handler failed").WithCause(configErr)
+		return
+	}
+	handler.log = options.Log
+	handler.enable = config.Enable
+	if token := strings.TrimSpace(config.Token); token != "" {
+		handler.token = []byte(token)
+	}
+	return
+}
+
+func (handler *logLevelHandler) Match(_ context.Context, method []byte, path []byte, _ transports.Header) bool {
+	if !handler.enable {
+		return false
+	}
+	ok := (bytes.Equal(method, transports.MethodGet) || bytes.Equal(method, transports.MethodPut)) && bytes.Equal(path, logLevelPath)
+	return ok
+}
+
+func (handler *logLevelHandler) Handle(w transports.ResponseWriter, r transports.Request) {
+	if len(handler.token) > 0 {
+		authorization := r.Header().Get(transports.AuthorizationHeaderName)
+		token, _ := bytes.CutPrefix(authorization, debugBearerAuthorizationPrefix)
+		if subtle.ConstantTimeCompare(token, handler.token) != 1 {
+			w.Failed(ErrLogLevelUnauthorized)
+			return
+		}
+	}
+	adjustable, ok := handler.log.(logs.LevelAdjustable)
+	if !ok {
+		w.Failed(ErrLogLevelNotAdjustable)
+		return
+	}
+	if bytes.Equal(r.Method(), transports.MethodPut) {
+		body, bodyErr := r.Body()
+		if bodyErr != nil {
+			w.Failed(ErrLogLevelInvalidBody.WithCause(bodyErr))
+			return
+		}
+		param := LogLevelParam{}
+		if decodeErr := json.Unmarshal(body, &param); decodeErr != nil {
+			w.Failed(ErrLogLevelInvalidBody.WithCause(decodeErr))
+			return
+		}
+		param.Component = strings.TrimSpace(param.Component)
+		if setErr := adjustable.SetLevel(param.Component, param.Level); setErr != nil {
+			w.Failed(errors.BadRequest("fns: set log level failed").WithCause(setErr))
+			return
+		}
+		if handler.log.InfoEnabled() {
+			component := param.Component
+			if component == "" {
+				component = "*"
+			}
+			level := string(param.Level)
+			if level == "" {
+				level = "reverted"
+			}
+			handler.log.Info().With("component", component).With("level", level).Message("fns: log level was changed")
+		}
+	}
+	global, components := adjustable.Levels()
+	w.Succeed(LogLevels{
+		Level:      global,
+		Components: components,
+	})
+	return
+}
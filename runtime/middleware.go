@@ -18,13 +18,16 @@
 package runtime
 
 import (
+	"bytes"
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/commons/bytex"
 	"github.com/aacfactory/fns/commons/uid"
 	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
 	"github.com/aacfactory/fns/transports"
 	"net/http"
 	"sync"
+	"time"
 )
 
 var (
@@ -43,6 +46,7 @@ func Middleware(rt *Runtime) transports.Middleware {
 type middleware struct {
 	log     logs.Logger
 	rt      *Runtime
+	drainer *drainer
 	counter sync.WaitGroup
 }
 
@@ -52,6 +56,15 @@ func (middle *middleware) Name() string {
 
 func (middle *middleware) Construct(options transports.MiddlewareOptions) error {
 	middle.log = options.Log
+	config := MiddlewareConfig{}
+	if err := options.Config.As(&config); err != nil {
+		return errors.Warning("fns: construct runtime middleware failed").WithCause(err)
+	}
+	drainer, drainerErr := newDrainer(config.Drain)
+	if drainerErr != nil {
+		return errors.Warning("fns: construct runtime middleware failed").WithCause(drainerErr)
+	}
+	middle.drainer = drainer
 	return nil
 }
 
@@ -59,11 +72,19 @@ func (middle *middleware) Handler(next transports.Handler) transports.Handler {
 	return transports.HandlerFunc(func(w transports.ResponseWriter, r transports.Request) {
 		running, upped := middle.rt.Running()
 		if !running {
-			w.Header().Set(transports.ConnectionHeaderName, transports.CloseHeaderValue)
-			w.Failed(ErrUnavailable)
-			return
-		}
-		if !upped {
+			serve, closing := false, true
+			if off, closed := middle.rt.status.IsOff(); off && !closed {
+				// shutting down
+				serve, closing = middle.drainer.admit(r.RemoteAddr(), time.Now())
+			}
+			if closing {
+				w.Header().Set(transports.ConnectionHeaderName, transports.CloseHeaderValue)
+			}
+			if !serve {
+				w.Failed(ErrUnavailable)
+				return
+			}
+		} else if !upped && !readinessRequested(r) {
 			w.Header().Set(transports.ResponseRetryAfterHeaderName, bytex.FromString("3"))
 			w.Failed(ErrTooEarly)
 			return
@@ -99,3 +120,10 @@ func (middle *middleware) Close() (err error) {
 	middle.counter.Wait()
 	return
 }
+
+// readinessRequested
+// readiness is served while half-on, so probes can tell warming up from down.
+// path is not stripped by base path of mux yet, so it is matched by suffix.
+func readinessRequested(r transports.Request) bool {
+	return bytes.Equal(r.Method(), transports.MethodGet) && bytes.HasSuffix(r.Path(), services.ReadinessPath)
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package runtime_test
+
+import (
+	"bufio"
+	"github.com/aacfactory/configures"
+	"github.com/aacfactory/fns/commons/switchs"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/fns/transports/fast"
+	"github.com/valyala/fasthttp"
+	"net"
+	"net/http"
+	"testing"
+)
+
+type resultWriter struct {
+	context.Context
+	*transports.ResultResponseWriter
+}
+
+func (w *resultWriter) SetCookie(_ *transports.Cookie) {}
+
+func (w *resultWriter) Hijack(_ func(ctx context.Context, conn net.Conn, rw *bufio.ReadWriter) (err error)) (async bool, err error) {
+	return
+}
+
+func (w *resultWriter) Hijacked() bool {
+	return false
+}
+
+func TestMiddleware_HalfOn(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	status := &switchs.Switch{}
+	// on but not confirmed, that is warming up
+	status.On()
+	rt := runtime.New("id", "name", versions.Origin(), status, log, nil, nil, nil, nil)
+	middleware := runtime.Middleware(rt)
+	config, _ := configures.NewJsonConfig([]byte(`{}`))
+	if err := middleware.Construct(transports.MiddlewareOptions{Log: log, Config: config}); err != nil {
+		t.Fatal(err)
+	}
+	handler := middleware.Handler(transports.HandlerFunc(func(w transports.ResponseWriter, r transports.Request) {
+		w.Succeed("ok")
+	}))
+	do := func(path string) int {
+		rc := &fasthttp.RequestCtx{}
+		rc.Request.Header.SetMethod(http.MethodGet)
+		rc.Request.SetRequestURI(path)
+		r := &fast.Request{Context: &fast.Context{RequestCtx: rc}}
+		w := &resultWriter{
+			Context:              r,
+			ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+		}
+		handler.Handle(w, r)
+		return w.Status()
+	}
+	if code := do("/users/get"); code != http.StatusTooEarly {
+		t.Fatal("fn must be too early while warming up, got", code)
+	}
+	if code := do("/application/ready"); code != http.StatusOK {
+		t.Fatal("readiness must be served while warming up, got", code)
+	}
+	if code := do("/api/application/ready"); code != http.StatusOK {
+		t.Fatal("readiness under base path must be served while warming up, got", code)
+	}
+	status.Confirm()
+	if code := do("/users/get"); code != http.StatusOK {
+		t.Fatal("fn must be served after warmup, got", code)
+	}
+}
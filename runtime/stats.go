@@ -0,0 +1,196 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package runtime
+
+import (
+	"bytes"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/transports"
+	"golang.org/x/sync/singleflight"
+	"os"
+	goruntime "runtime"
+	"sync"
+	"time"
+)
+
+var (
+	statsPath = []byte("/application/stats")
+)
+
+const (
+	statsCacheTTL = time.Second
+)
+
+// StatsHandler
+// serves runtime stats at /application/stats,
+// stats is cached for one second, so frequent scrapes do not stop the world each time.
+func StatsHandler() transports.MuxHandler {
+	return &statsHandler{
+		group: new(singleflight.Group),
+	}
+}
+
+type statsHandler struct {
+	group    *singleflight.Group
+	mutex    sync.RWMutex
+	stats    *Stats
+	expireAt time.Time
+}
+
+func (handler *statsHandler) Name() string {
+	return "stats"
+}
+
+func (handler *statsHandler) Construct(_ transports.MuxHandlerOptions) error {
+	return nil
+}
+
+func (handler *statsHandler) Match(_ context.Context, method []byte, path []byte, _ transports.Header) bool {
+	ok := bytes.Equal(method, transports.MethodGet) && bytes.Equal(path, statsPath)
+	return ok
+}
+
+func (handler *statsHandler) Handle(w transports.ResponseWriter, r transports.Request) {
+	handler.mutex.RLock()
+	stats, expireAt := handler.stats, handler.expireAt
+	handler.mutex.RUnlock()
+	if stats != nil && time.Now().Before(expireAt) {
+		w.Succeed(stats)
+		return
+	}
+	v, _, _ := handler.group.Do("stats", func() (v interface{}, err error) {
+		rt := Load(r)
+		stats := newStats(rt)
+		handler.mutex.Lock()
+		handler.stats = stats
+		handler.expireAt = time.Now().Add(statsCacheTTL)
+		handler.mutex.Unlock()
+		v = stats
+		return
+	})
+	w.Succeed(v)
+	return
+}
+
+func newStats(rt *Runtime) *Stats {
+	mem := goruntime.MemStats{}
+	goruntime.ReadMemStats(&mem)
+	gc := GCStats{
+		Num:          mem.NumGC,
+		PauseTotal:   time.Duration(mem.PauseTotalNs),
+		CPUFraction:  mem.GCCPUFraction,
+		LastPause:    0,
+		LastPauseEnd: time.Time{},
+	}
+	if mem.NumGC > 0 {
+		idx := (mem.NumGC + 255) % 256
+		gc.LastPause = time.Duration(mem.PauseNs[idx])
+		gc.LastPauseEnd = time.Unix(0, int64(mem.PauseEnd[idx]))
+	}
+	if mem.LastGC > 0 {
+		gc.LastGC = time.Unix(0, int64(mem.LastGC))
+	}
+	running, serving := rt.Running()
+	var limit *services.AdaptiveLimiterStats
+	if limiterStats, ok := services.AdaptiveLimitStats(); ok {
+		limit = &limiterStats
+	}
+	var lanes map[string]int
+	if laned, ok := rt.Workers().(*services.Lanes); ok {
+		lanes = laned.Depths()
+	}
+	return &Stats{
+		Id:         string(rt.AppId()),
+		Name:       rt.AppName(),
+		Version:    rt.AppVersion().String(),
+		Running:    running,
+		Serving:    serving,
+		CPU:        goruntime.NumCPU(),
+		GOMAXPROCS: goruntime.GOMAXPROCS(0),
+		Goroutines: goruntime.NumGoroutine(),
+		FDS:        openFileDescriptors(),
+		Memory: MemoryStats{
+			Alloc:     mem.Alloc,
+			Sys:       mem.Sys,
+			HeapInuse: mem.HeapInuse,
+			HeapIdle:  mem.HeapIdle,
+			Objects:   mem.HeapObjects,
+		},
+		GC:        gc,
+		Endpoints: services.FnStatistics(),
+		Lanes:     lanes,
+		Limit:     limit,
+		Conns:     transports.ConnStatistics(),
+		Now:       time.Now(),
+	}
+}
+
+// openFileDescriptors
+// returns -1 when the platform does not expose /proc/self/fd.
+func openFileDescriptors() int {
+	entries, readErr := os.ReadDir("/proc/self/fd")
+	if readErr != nil {
+		return -1
+	}
+	return len(entries)
+}
+
+type Stats struct {
+	Id         string      `json:"id" avro:"id"`
+	Name       string      `json:"name" avro:"name"`
+	Version    string      `json:"version" avro:"version"`
+	Running    bool        `json:"running" avro:"running"`
+	Serving    bool        `json:"serving" avro:"serving"`
+	CPU        int         `json:"cpu" avro:"cpu"`
+	GOMAXPROCS int         `json:"gomaxprocs" avro:"gomaxprocs"`
+	Goroutines int         `json:"goroutines" avro:"goroutines"`
+	FDS        int         `json:"fds" avro:"fds"`
+	Memory     MemoryStats `json:"memory" avro:"memory"`
+	GC         GCStats     `json:"gc" avro:"gc"`
+	// Endpoints
+	// stats of requested fns, grouped by endpoint then fn.
+	Endpoints map[string]map[string]services.FnStats `json:"endpoints" avro:"endpoints"`
+	// Lanes
+	// number of queued requests of each priority lane, it is empty when lanes are disabled.
+	Lanes map[string]int `json:"lanes,omitempty" avro:"lanes"`
+	// Limit
+	// adaptive in-flight limit of endpoints handler, it is nil when adaptive limit is disabled.
+	Limit *services.AdaptiveLimiterStats `json:"limit,omitempty" avro:"limit"`
+	// Conns
+	// connection stats of listeners of the process, such as transport, internal and management.
+	Conns []transports.ListenerConnStats `json:"conns" avro:"conns"`
+	Now   time.Time                      `json:"now" avro:"now"`
+}
+
+type MemoryStats struct {
+	Alloc     uint64 `json:"alloc" avro:"alloc"`
+	Sys       uint64 `json:"sys" avro:"sys"`
+	HeapInuse uint64 `json:"heapInuse" avro:"heap_inuse"`
+	HeapIdle  uint64 `json:"heapIdle" avro:"heap_idle"`
+	Objects   uint64 `json:"objects" avro:"objects"`
+}
+
+type GCStats struct {
+	Num          uint32        `json:"num" avro:"num"`
+	PauseTotal   time.Duration `json:"pauseTotal" avro:"pause_total"`
+	LastPause    time.Duration `json:"lastPause" avro:"last_pause"`
+	LastPauseEnd time.Time     `json:"lastPauseEnd" avro:"last_pause_end"`
+	LastGC       time.Time     `json:"lastGC" avro:"last_gc"`
+	CPUFraction  float64       `json:"cpuFraction" avro:"cpu_fraction"`
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"testing"
+)
+
+func TestEndpointsHandler_Allow(t *testing.T) {
+	infos := EndpointInfos{
+		{
+			Name: "users",
+			Functions: FnInfos{
+				{Name: "get", Readonly: true, Routes: []FnRoute{{Method: "GET", Pattern: "/members/:id"}}},
+				{Name: "create", Routes: []FnRoute{{Method: "POST", Pattern: "/members/:id"}}},
+				{Name: "remove"},
+				{Name: "secret", Internal: true},
+			},
+		},
+	}
+	handler := &endpointsHandler{
+		infos:  infos,
+		routes: newRoutes(infos),
+	}
+	cases := map[string]string{
+		"/users/remove": "POST, OPTIONS",
+		"/users/get":    "GET, HEAD, OPTIONS",
+		"/members/1":    "GET, HEAD, POST, OPTIONS",
+		"/users/secret": "",
+		"/orders/list":  "",
+	}
+	for path, expect := range cases {
+		if allow := string(handler.allow([]byte(path))); allow != expect {
+			t.Error(path, "expect", expect, "but", allow)
+		}
+	}
+}
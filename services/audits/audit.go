@@ -0,0 +1,153 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package audits
+
+import (
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/authorizations"
+	"github.com/aacfactory/fns/transports"
+	"sync"
+	"time"
+)
+
+var (
+	contextLocalKey = []byte("@fns:audits:recorder")
+	prepareLocker   = new(sync.Mutex)
+)
+
+// Actor
+// is who did the action, it is empty when request is not authorized.
+type Actor struct {
+	Id         string                    `json:"id,omitempty"`
+	Account    string                    `json:"account,omitempty"`
+	Attributes authorizations.Attributes `json:"attributes,omitempty"`
+}
+
+// Entry
+// is an audit record of a fn which is marked by @audit.
+type Entry struct {
+	Actor     Actor     `json:"actor"`
+	Action    string    `json:"action"`
+	Endpoint  string    `json:"endpoint"`
+	Fn        string    `json:"fn"`
+	Resource  string    `json:"resource,omitempty"`
+	Succeed   bool      `json:"succeed"`
+	Error     string    `json:"error,omitempty"`
+	RequestId string    `json:"requestId,omitempty"`
+	DeviceId  string    `json:"deviceId,omitempty"`
+	DeviceIp  string    `json:"deviceIp,omitempty"`
+	Tenant    string    `json:"tenant,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+type recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (rec *recorder) append(entry Entry) {
+	rec.mu.Lock()
+	rec.entries = append(rec.entries, entry)
+	rec.mu.Unlock()
+}
+
+func (rec *recorder) list() (entries []Entry) {
+	rec.mu.Lock()
+	entries = append(entries, rec.entries...)
+	rec.mu.Unlock()
+	return
+}
+
+func loadRecorder(ctx context.Context) (rec *recorder, has bool) {
+	v := ctx.LocalValue(contextLocalKey)
+	if v == nil {
+		return
+	}
+	rec, has = v.(*recorder)
+	return
+}
+
+// Prepare
+// makes entries of the transport request be kept, it is called before fn is handled,
+// so that internal fns requested concurrently share the same recorder.
+func Prepare(ctx context.Context) {
+	r, ok := transports.TryLoadRequest(ctx)
+	if !ok {
+		return
+	}
+	if _, has := loadRecorder(r); has {
+		return
+	}
+	prepareLocker.Lock()
+	if _, has := loadRecorder(r); !has {
+		r.SetLocalValue(contextLocalKey, &recorder{})
+	}
+	prepareLocker.Unlock()
+}
+
+// Record
+// appends an entry of fn into the transport request, entries are written into sink by the hook after request is responded.
+// Note: entry is dropped when fn is requested by another node, because the transport request there is an internal one.
+func Record(r services.Request, action string, resource string, cause error) {
+	Prepare(r)
+	tr, ok := transports.TryLoadRequest(r)
+	if !ok {
+		return
+	}
+	rec, has := loadRecorder(tr)
+	if !has {
+		return
+	}
+	endpoint, fn := r.Fn()
+	header := r.Header()
+	entry := Entry{
+		Action:    action,
+		Endpoint:  string(endpoint),
+		Fn:        string(fn),
+		Resource:  resource,
+		Succeed:   cause == nil,
+		RequestId: string(header.RequestId()),
+		DeviceId:  string(header.DeviceId()),
+		DeviceIp:  string(header.DeviceIp()),
+		Tenant:    string(header.Tenant()),
+		Time:      time.Now(),
+	}
+	if cause != nil {
+		entry.Error = cause.Error()
+	}
+	if authorization, authorized, _ := authorizations.Load(r); authorized && authorization.Exist() {
+		entry.Actor = Actor{
+			Id:         authorization.Id.String(),
+			Account:    authorization.Account.String(),
+			Attributes: authorization.Attributes,
+		}
+	}
+	rec.append(entry)
+}
+
+// Entries
+// returns entries recorded in ctx.
+func Entries(ctx context.Context) (entries []Entry) {
+	rec, has := loadRecorder(ctx)
+	if !has {
+		return
+	}
+	entries = rec.list()
+	return
+}
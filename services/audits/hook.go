@@ -0,0 +1,124 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package audits
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"io"
+	"sync"
+	"time"
+)
+
+// Sink
+// is where entries are written into, such as log, file or a storage of compliance.
+type Sink interface {
+	Write(ctx context.Context, entries []Entry) (err error)
+}
+
+// LogSink
+// writes entries into log of ctx.
+func LogSink() Sink {
+	return &logSink{}
+}
+
+type logSink struct{}
+
+func (sink *logSink) Write(ctx context.Context, entries []Entry) (err error) {
+	log := logs.Load(ctx)
+	if !log.InfoEnabled() {
+		return
+	}
+	for _, entry := range entries {
+		p, encodeErr := jsons.Marshal(entry)
+		if encodeErr != nil {
+			err = errors.Warning("audits: write entries failed").WithCause(encodeErr)
+			return
+		}
+		log.Info().With("audits", "entry").Message(bytex.ToString(p))
+	}
+	return
+}
+
+// WriterSink
+// writes entries into writer as json lines.
+func WriterSink(writer io.Writer) Sink {
+	return &writerSink{
+		writer: writer,
+	}
+}
+
+type writerSink struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func (sink *writerSink) Write(_ context.Context, entries []Entry) (err error) {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	for _, entry := range entries {
+		p, encodeErr := jsons.Marshal(entry)
+		if encodeErr != nil {
+			err = errors.Warning("audits: write entries failed").WithCause(encodeErr)
+			return
+		}
+		p = append(p, '\n')
+		if _, err = sink.writer.Write(p); err != nil {
+			err = errors.Warning("audits: write entries failed").WithCause(err)
+			return
+		}
+	}
+	return
+}
+
+// NewHook
+// returns a services.Hook which writes entries of fns marked by @audit into sink,
+// LogSink is used when sink is nil. Use it by fns.RequestHooks.
+func NewHook(sink Sink) services.Hook {
+	if sink == nil {
+		sink = LogSink()
+	}
+	return &hook{
+		sink: sink,
+	}
+}
+
+type hook struct {
+	sink Sink
+}
+
+func (h *hook) Name() string {
+	return "audits"
+}
+
+func (h *hook) Handle(ctx context.Context, _ []byte, _ []byte, _ []byte, _ error, _ time.Duration) {
+	entries := Entries(ctx)
+	if len(entries) == 0 {
+		return
+	}
+	if err := h.sink.Write(ctx, entries); err != nil {
+		log := logs.Load(ctx)
+		if log.WarnEnabled() {
+			log.Warn().Cause(err).With("hook", h.Name()).Message("audits: write entries failed")
+		}
+	}
+}
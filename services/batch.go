@@ -0,0 +1,216 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"bytes"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	batchPath               = []byte("/batch")
+	batchDefaultMaxSize     = 32
+	batchDefaultConcurrency = 8
+	ErrBatchTooLarge        = errors.New(http.StatusRequestEntityTooLarge, "***TOO LARGE***", "fns: batch is too large")
+)
+
+type BatchConfig struct {
+	// MaxSize
+	// max number of sub requests in one batch, default is 32.
+	MaxSize int `json:"maxSize,omitempty" yaml:"maxSize,omitempty"`
+	// Concurrency
+	// max number of sub requests of all batches handled at the same time, default is 8.
+	Concurrency int `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+}
+
+type BatchRequest struct {
+	Id      string          `json:"id"`
+	Service string          `json:"service"`
+	Fn      string          `json:"fn"`
+	Body    json.RawMessage `json:"body"`
+}
+
+type BatchResult struct {
+	Id     string      `json:"id"`
+	Status int         `json:"status"`
+	Body   interface{} `json:"body"`
+}
+
+// BatchHandler
+// handles `POST /batch` whose body is an array of BatchRequest, and responds an array of BatchResult in the same order.
+// each sub request is dispatched by Endpoints.Request with headers of the batch request,
+// so authorization, permission and other options of fn are still applied.
+// handler is the one returned by Handler, sub requests share its adaptive limiter and mapping of error status with single requests.
+func BatchHandler(endpoints Endpoints, handler transports.MuxHandler) transports.MuxHandler {
+	eh, _ := handler.(*endpointsHandler)
+	return &batchHandler{
+		endpoints: endpoints,
+		handler:   eh,
+	}
+}
+
+type batchHandler struct {
+	endpoints Endpoints
+	handler   *endpointsHandler
+	maxSize   int
+	tokens    chan struct{}
+}
+
+func (handler *batchHandler) Name() string {
+	return "batch"
+}
+
+func (handler *batchHandler) Construct(options transports.MuxHandlerOptions) (err error) {
+	config := BatchConfig{}
+	configErr := options.Config.As(&config)
+	if configErr != nil {
+		err = errors.Warning("fns: construct batch handler failed").WithCause(configErr)
+		return
+	}
+	handler.maxSize = config.MaxSize
+	if handler.maxSize < 1 {
+		handler.maxSize = batchDefaultMaxSize
+	}
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = batchDefaultConcurrency
+	}
+	handler.tokens = make(chan struct{}, concurrency)
+	return
+}
+
+func (handler *batchHandler) Match(_ context.Context, method []byte, path []byte, header transports.Header) bool {
+	return bytes.Equal(method, transports.MethodPost) && bytes.Equal(path, batchPath) &&
+		bytes.Equal(header.Get(transports.ContentTypeHeaderName), transports.ContentTypeJsonHeaderValue)
+}
+
+func (handler *batchHandler) Handle(w transports.ResponseWriter, r transports.Request) {
+	path := r.Path()
+	options, optionsErr := TransportRequestOptions(r)
+	if optionsErr != nil {
+		w.Failed(optionsErr)
+		return
+	}
+	// body
+	body, bodyErr := r.Body()
+	if bodyErr != nil {
+		w.Failed(ErrInvalidBody.WithMeta("path", bytex.ToString(path)).WithCause(bodyErr))
+		return
+	}
+	if depthErr := jsons.CheckDepth(body); depthErr != nil {
+		w.Failed(ErrTooDeepBody.WithMeta("path", bytex.ToString(path)).WithMeta("max", strconv.Itoa(jsons.MaxDepth())))
+		return
+	}
+	requests := make([]BatchRequest, 0, 1)
+	if decodeErr := jsons.Unmarshal(body, &requests); decodeErr != nil {
+		w.Failed(ErrInvalidBody.WithMeta("path", bytex.ToString(path)).WithCause(decodeErr))
+		return
+	}
+	if len(requests) > handler.maxSize {
+		w.Failed(ErrBatchTooLarge.WithMeta("max", strconv.Itoa(handler.maxSize)))
+		return
+	}
+	// handle
+	infos := handler.endpoints.Info()
+	results := make([]BatchResult, len(requests))
+	wg := new(sync.WaitGroup)
+	for i, request := range requests {
+		results[i].Id = request.Id
+		ep := bytex.FromString(request.Service)
+		fn := bytex.FromString(request.Fn)
+		if !batchExposed(infos, ep, fn) {
+			results[i].failed(handler.mapError(r, errors.NotFound("fns: endpoint was not found").
+				WithMeta("endpoint", request.Service).
+				WithMeta("fn", request.Fn)))
+			continue
+		}
+		var param interface{}
+		if len(request.Body) > 0 {
+			param = request.Body
+		}
+		handler.tokens <- struct{}{}
+		wg.Add(1)
+		// each sub request has its own context, cause entries of r are not safe for concurrent writes
+		go func(ctx context.Context, result *BatchResult, ep []byte, fn []byte, param interface{}) {
+			defer func() {
+				<-handler.tokens
+				wg.Done()
+			}()
+			response, err := handler.request(ctx, ep, fn, param, options)
+			if err != nil {
+				result.failed(handler.mapError(r, err))
+				return
+			}
+			result.Status = http.StatusOK
+			if response.Valid() {
+				result.Body = response.Value()
+			}
+		}(context.Fork(r), &results[i], ep, fn, param)
+	}
+	wg.Wait()
+	w.Succeed(results)
+}
+
+// request
+// handles sub request under the adaptive limiter of endpoints handler, as single requests do.
+func (handler *batchHandler) request(ctx context.Context, ep []byte, fn []byte, param interface{}, options []RequestOption) (response Response, err error) {
+	if handler.handler == nil || handler.handler.limiter == nil {
+		response, err = handler.endpoints.Request(ctx, ep, fn, param, options...)
+		return
+	}
+	limiter := handler.handler.limiter
+	if !limiter.Acquire() {
+		err = ErrOverloaded.WithMeta("endpoint", bytex.ToString(ep)).WithMeta("fn", bytex.ToString(fn))
+		return
+	}
+	beg := time.Now()
+	response, err = handler.endpoints.Request(ctx, ep, fn, param, options...)
+	limiter.Release(time.Since(beg), err)
+	return
+}
+
+func (handler *batchHandler) mapError(r transports.Request, err error) error {
+	if handler.handler == nil {
+		return err
+	}
+	return handler.handler.mapError(r, err)
+}
+
+func (result *BatchResult) failed(cause error) {
+	err := errors.Wrap(cause)
+	result.Status = err.Code()
+	result.Body = err
+}
+
+func batchExposed(infos EndpointInfos, ep []byte, fn []byte) bool {
+	endpoint, hasEndpoint := infos.Find(ep)
+	if !hasEndpoint || endpoint.Internal {
+		return false
+	}
+	fi, hasFn := endpoint.Functions.Find(fn)
+	return hasFn && !fi.Internal
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"fmt"
+	"github.com/aacfactory/configures"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/fns/transports/fast"
+	"github.com/aacfactory/json"
+	"github.com/valyala/fasthttp"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type echoFn struct{}
+
+func (fn *echoFn) Name() string {
+	return "echo"
+}
+
+func (fn *echoFn) Internal() bool {
+	return false
+}
+
+func (fn *echoFn) Readonly() bool {
+	return false
+}
+
+func (fn *echoFn) Handle(r services.Request) (v any, err error) {
+	// touch context values like fns and middlewares do
+	for i := 0; i < 8; i++ {
+		r.SetLocalValue([]byte(fmt.Sprintf("key:%d", i)), i)
+		r.SetUserValue([]byte(fmt.Sprintf("user:%d", i)), i)
+		_ = r.LocalValue([]byte("key:0"))
+	}
+	p := json.RawMessage{}
+	if err = r.Param().Unmarshal(&p); err != nil {
+		return
+	}
+	v = p
+	return
+}
+
+type echoService struct {
+	services.Abstract
+}
+
+// TestBatchHandler
+// run with -race, sub requests of a batch are handled concurrently on one transport request.
+func TestBatchHandler(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	svc := &echoService{Abstract: services.NewAbstract("echo", false)}
+	svc.AddFunction(&echoFn{})
+	if err := manager.Add(svc); err != nil {
+		t.Fatal(err)
+	}
+	handler := services.BatchHandler(manager, services.Handler(manager, 0))
+	config, _ := configures.NewJsonConfig([]byte(`{"concurrency":8}`))
+	if err := handler.Construct(transports.MuxHandlerOptions{Log: log, Config: config}); err != nil {
+		t.Fatal(err)
+	}
+	items := make([]string, 0, 16)
+	for i := 0; i < 16; i++ {
+		items = append(items, fmt.Sprintf(`{"id":"%d","service":"echo","fn":"echo","body":{"n":%d}}`, i, i))
+	}
+	rc := &fasthttp.RequestCtx{}
+	rc.Request.Header.SetMethod(http.MethodPost)
+	rc.Request.Header.Set("X-Fns-Device-Id", "device")
+	rc.Request.SetBody([]byte("[" + strings.Join(items, ",") + "]"))
+	r := &fast.Request{Context: &fast.Context{RequestCtx: rc}}
+	w := &resultWriter{
+		Context:              context.TODO(),
+		ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+	}
+	handler.Handle(w, r)
+	if w.Status() != http.StatusOK {
+		t.Fatal("batch failed", w.Status(), string(w.Body()))
+	}
+	for i := 0; i < 16; i++ {
+		expect := fmt.Sprintf(`{"id":"%d","status":200,"body":{"n":%d}}`, i, i)
+		if !strings.Contains(string(w.Body()), expect) {
+			t.Fatal("result is not in order", string(w.Body()))
+		}
+	}
+}
+
+var errBatchConflict = errors.New(http.StatusInternalServerError, "***CONFLICT***", "conflict")
+
+// slowFn
+// records max number of running fns, and fails when param is "fail".
+type slowFn struct {
+	running atomic.Int64
+	max     atomic.Int64
+}
+
+func (fn *slowFn) Name() string {
+	return "slow"
+}
+
+func (fn *slowFn) Internal() bool {
+	return false
+}
+
+func (fn *slowFn) Readonly() bool {
+	return false
+}
+
+func (fn *slowFn) Handle(r services.Request) (v any, err error) {
+	n := fn.running.Add(1)
+	for {
+		max := fn.max.Load()
+		if n <= max || fn.max.CompareAndSwap(max, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	fn.running.Add(-1)
+	p := ""
+	_ = r.Param().Unmarshal(&p)
+	if p == "fail" {
+		err = errBatchConflict
+	}
+	return
+}
+
+func TestBatchHandler_Shared(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	fn := &slowFn{}
+	svc := &echoService{Abstract: services.NewAbstract("slow", false)}
+	svc.AddFunction(fn)
+	if err := manager.Add(svc); err != nil {
+		t.Fatal(err)
+	}
+	endpoints := services.Handler(manager, 0)
+	endpointsConfig, _ := configures.NewJsonConfig([]byte(`{"statusCodes":{"***CONFLICT***":409}}`))
+	if err := endpoints.Construct(transports.MuxHandlerOptions{Log: log, Config: endpointsConfig}); err != nil {
+		t.Fatal(err)
+	}
+	handler := services.BatchHandler(manager, endpoints)
+	config, _ := configures.NewJsonConfig([]byte(`{"concurrency":2}`))
+	if err := handler.Construct(transports.MuxHandlerOptions{Log: log, Config: config}); err != nil {
+		t.Fatal(err)
+	}
+	items := make([]string, 0, 4)
+	for i := 0; i < 4; i++ {
+		items = append(items, fmt.Sprintf(`{"id":"%d","service":"slow","fn":"slow","body":"ok"}`, i))
+	}
+	items = append(items, `{"id":"fail","service":"slow","fn":"slow","body":"fail"}`)
+	wg := sync.WaitGroup{}
+	bodies := make([]string, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rc := &fasthttp.RequestCtx{}
+			rc.Request.Header.SetMethod(http.MethodPost)
+			rc.Request.Header.Set("X-Fns-Device-Id", "device")
+			rc.Request.SetBody([]byte("[" + strings.Join(items, ",") + "]"))
+			r := &fast.Request{Context: &fast.Context{RequestCtx: rc}}
+			w := &resultWriter{
+				Context:              context.TODO(),
+				ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+			}
+			defer transports.ReleaseResultResponseWriter(w.ResultResponseWriter)
+			handler.Handle(w, r)
+			bodies[i] = string(w.Body())
+		}(i)
+	}
+	wg.Wait()
+	if max := fn.max.Load(); max > 2 {
+		t.Fatal("concurrency is not shared by batches", max)
+	}
+	for _, body := range bodies {
+		if !strings.Contains(body, `{"id":"fail","status":409`) {
+			t.Fatal("status of error was not mapped", body)
+		}
+	}
+}
@@ -30,6 +30,8 @@ type KeyParam interface {
 	CacheKey(ctx context.Context) (key []byte, err error)
 }
 
+// Store
+// ttl of Set is zero when value never expires, such as generation of group.
 type Store interface {
 	services.Component
 	Get(ctx context.Context, key []byte) (value []byte, has bool, err error)
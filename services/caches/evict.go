@@ -0,0 +1,159 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package caches
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"strconv"
+	"time"
+)
+
+var (
+	evictGroupFnName = []byte("evict-group")
+	groupKeyPrefix   = []byte("group:")
+)
+
+// GroupKeyParam
+// puts cached values of param into a group, so they can be evicted together by EvictGroup,
+// such as all pages of users which are cached by different query params.
+type GroupKeyParam interface {
+	KeyParam
+	CacheGroup(ctx context.Context) (group []byte)
+}
+
+// Evict
+// removes cached value of param, param must be the same type of the param of fn which gets the value.
+//
+// keying contract: key is the result of KeyParam.CacheKey of param, it does not contain name of fn,
+// so a write fn evicts the value cached by @cache get of a read fn by building the param of the read fn:
+//
+//	err = caches.Evict(ctx, GetUserParam{Id: param.Id})
+//
+// when param implements GroupKeyParam, key is scoped in the group.
+func Evict[P KeyParam](ctx context.Context, param P) (err error) {
+	err = Remove(ctx, param)
+	return
+}
+
+// EvictGroup
+// drops all cached values of the group by moving generation of group, values of old generation expire by their ttl.
+func EvictGroup(ctx context.Context, group []byte) (err error) {
+	if len(group) == 0 {
+		err = errors.Warning("fns: evict cache group failed").WithCause(fmt.Errorf("group is required"))
+		return
+	}
+	eps := runtime.Endpoints(ctx)
+	_, doErr := eps.Request(ctx, endpointName, evictGroupFnName, evictGroupFnParam{
+		Group: bytex.ToString(group),
+	}, services.WithInternalRequest())
+	if doErr != nil {
+		err = doErr
+		return
+	}
+	return
+}
+
+func keyOfParam(ctx context.Context, param any) (key []byte, group []byte, err error) {
+	kp, ok := param.(KeyParam)
+	if !ok {
+		err = fmt.Errorf("param dose not implement caches.KeyParam")
+		return
+	}
+	key, err = kp.CacheKey(ctx)
+	if err != nil {
+		return
+	}
+	if gp, isGroup := param.(GroupKeyParam); isGroup {
+		group = gp.CacheGroup(ctx)
+	}
+	return
+}
+
+func groupGenerationKey(group []byte) []byte {
+	return append(append(make([]byte, 0, len(groupKeyPrefix)+len(group)), groupKeyPrefix...), group...)
+}
+
+// groupedKey
+// key in group is group:{group}:{generation}:{key}
+func groupedKey(ctx context.Context, store Store, group []byte, key []byte) (v []byte, err error) {
+	if len(group) == 0 {
+		v = key
+		return
+	}
+	gk := groupGenerationKey(group)
+	generation, _, getErr := store.Get(ctx, gk)
+	if getErr != nil {
+		err = getErr
+		return
+	}
+	v = make([]byte, 0, len(gk)+len(generation)+len(key)+2)
+	v = append(v, gk...)
+	v = append(v, ':')
+	v = append(v, generation...)
+	v = append(v, ':')
+	v = append(v, key...)
+	return
+}
+
+type evictGroupFnParam struct {
+	Group string `json:"group" avro:"group"`
+}
+
+type evictGroupFn struct {
+	store Store
+}
+
+func (fn *evictGroupFn) Name() string {
+	return string(evictGroupFnName)
+}
+
+func (fn *evictGroupFn) Internal() bool {
+	return true
+}
+
+func (fn *evictGroupFn) Readonly() bool {
+	return false
+}
+
+func (fn *evictGroupFn) Handle(r services.Request) (v interface{}, err error) {
+	if !r.Param().Valid() {
+		err = errors.Warning("fns: evict cache group failed").WithCause(errors.Warning("param is invalid"))
+		return
+	}
+	param, paramErr := services.ValueOfParam[evictGroupFnParam](r.Param())
+	if paramErr != nil {
+		err = errors.Warning("fns: evict cache group failed").WithCause(paramErr)
+		return
+	}
+	if param.Group == "" {
+		err = errors.Warning("fns: evict cache group failed").WithCause(errors.Warning("param is invalid"))
+		return
+	}
+	generation := bytex.FromString(strconv.FormatInt(time.Now().UnixNano(), 16))
+	// zero ttl keeps generation until next eviction
+	if setErr := fn.store.Set(r, groupGenerationKey(bytex.FromString(param.Group)), generation, 0); setErr != nil {
+		err = errors.Warning("fns: evict cache group failed").WithCause(setErr)
+		return
+	}
+	return
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package caches_test
+
+import (
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/caches"
+	"github.com/aacfactory/fns/shareds"
+	"testing"
+	"time"
+)
+
+type userParam struct {
+	Id    string `json:"id" avro:"id"`
+	Group string `json:"group" avro:"group"`
+}
+
+func (param userParam) CacheKey(_ context.Context) (key []byte, err error) {
+	key = []byte("users:" + param.Id)
+	return
+}
+
+type groupedUserParam struct {
+	userParam
+}
+
+func (param groupedUserParam) CacheGroup(_ context.Context) (group []byte) {
+	group = []byte(param.Group)
+	return
+}
+
+func TestEvict(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	if err := manager.Add(caches.New()); err != nil {
+		t.Fatal(err)
+	}
+	rt := runtime.New("id", "name", versions.Origin(), nil, log, nil, manager, nil, shared)
+	ctx := runtime.With(context.TODO(), rt)
+	logs.With(ctx, log)
+
+	param := userParam{Id: "1"}
+	if err := caches.Set(ctx, param, "foo", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if _, has, err := caches.Load[string](ctx, param); err != nil || !has {
+		t.Fatal("want hit", has, err)
+	}
+	if err := caches.Evict(ctx, userParam{Id: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, has, err := caches.Load[string](ctx, param); err != nil || has {
+		t.Fatal("want miss after evict", has, err)
+	}
+
+	grouped := []groupedUserParam{{userParam{Id: "1", Group: "users"}}, {userParam{Id: "2", Group: "users"}}}
+	for _, p := range grouped {
+		if err := caches.Set(ctx, p, "bar", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := caches.EvictGroup(ctx, []byte("users")); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range grouped {
+		if _, has, err := caches.Load[string](ctx, p); err != nil || has {
+			t.Fatal("want miss after evict group", p.Id, has, err)
+		}
+	}
+}
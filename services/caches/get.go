@@ -51,19 +51,15 @@ func Get(ctx context.Context, param any) (p []byte, has bool, err error) {
 		err = errors.Warning("fns: get cache failed").WithCause(fmt.Errorf("param is nil"))
 		return
 	}
-	kp, ok := param.(KeyParam)
-	if !ok {
-		err = errors.Warning("fns: get cache failed").WithCause(fmt.Errorf("param dose not implement caches.KeyParam"))
-		return
-	}
-	key, keyErr := kp.CacheKey(ctx)
+	key, group, keyErr := keyOfParam(ctx, param)
 	if keyErr != nil {
 		err = errors.Warning("fns: get cache failed").WithCause(keyErr)
 		return
 	}
 	eps := runtime.Endpoints(ctx)
 	response, doErr := eps.Request(ctx, endpointName, getFnName, getFnParam{
-		Key: bytex.ToString(key),
+		Key:   bytex.ToString(key),
+		Group: bytex.ToString(group),
 	}, services.WithInternalRequest())
 	if doErr != nil {
 		err = doErr
@@ -80,7 +76,8 @@ func Get(ctx context.Context, param any) (p []byte, has bool, err error) {
 }
 
 type getFnParam struct {
-	Key string `json:"key" avro:"key"`
+	Key   string `json:"key" avro:"key"`
+	Group string `json:"group" avro:"group"`
 }
 
 type getResult struct {
@@ -119,6 +116,11 @@ func (fn *getFn) Handle(r services.Request) (v interface{}, err error) {
 		err = errors.Warning("fns: get cache failed").WithCause(errors.Warning("param is invalid"))
 		return
 	}
+	key, err = groupedKey(r, fn.store, bytex.FromString(param.Group), key)
+	if err != nil {
+		err = errors.Warning("fns: get cache failed").WithCause(err)
+		return
+	}
 	value, has, getErr := fn.store.Get(r, key)
 	if getErr != nil {
 		err = errors.Warning("fns: get cache failed").WithCause(getErr)
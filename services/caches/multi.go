@@ -0,0 +1,211 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package caches
+
+import (
+	"fmt"
+	"github.com/aacfactory/avro"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+)
+
+var (
+	getMultiFnName = []byte("get_multi")
+)
+
+// MultiStore
+// is implemented by stores which get many keys in one round trip, such as MGET of redis.
+// values and has are in the same order as keys.
+// stores which do not implement it get keys one by one.
+type MultiStore interface {
+	GetMulti(ctx context.Context, keys [][]byte) (values [][]byte, has []bool, err error)
+}
+
+// LoadMulti
+// loads cached values of params in one round trip, every param must implement KeyParam, and is keyed like Load.
+// values are in the same order as params, misses are indexes of params which are not cached, and values of them are zero.
+func LoadMulti[E any, P any](ctx context.Context, params []P) (values []E, misses []int, err error) {
+	items := make([]any, len(params))
+	for i, param := range params {
+		items[i] = param
+	}
+	p, pMisses, getErr := GetMulti(ctx, items)
+	if getErr != nil {
+		err = getErr
+		return
+	}
+	values = make([]E, len(params))
+	misses = pMisses
+	for i, raw := range p {
+		if raw == nil {
+			continue
+		}
+		if decodeErr := avro.Unmarshal(raw, &values[i]); decodeErr != nil {
+			err = errors.Warning("fns: get cache failed").WithCause(decodeErr)
+			return
+		}
+	}
+	return
+}
+
+// GetMulti
+// is LoadMulti without decoding, value of a miss is nil.
+func GetMulti(ctx context.Context, params []any) (values [][]byte, misses []int, err error) {
+	if len(params) == 0 {
+		return
+	}
+	fnParam := getMultiFnParam{
+		Keys: make([]getFnParam, len(params)),
+	}
+	for i, param := range params {
+		if param == nil {
+			err = errors.Warning("fns: get cache failed").WithCause(fmt.Errorf("param is nil")).WithMeta("index", fmt.Sprint(i))
+			return
+		}
+		key, group, keyErr := keyOfParam(ctx, param)
+		if keyErr != nil {
+			err = errors.Warning("fns: get cache failed").WithCause(keyErr).WithMeta("index", fmt.Sprint(i))
+			return
+		}
+		fnParam.Keys[i] = getFnParam{
+			Key:   bytex.ToString(key),
+			Group: bytex.ToString(group),
+		}
+	}
+	eps := runtime.Endpoints(ctx)
+	response, doErr := eps.Request(ctx, endpointName, getMultiFnName, fnParam, services.WithInternalRequest())
+	if doErr != nil {
+		err = doErr
+		return
+	}
+	result, resultErr := services.ValueOfResponse[getMultiResult](response)
+	if resultErr != nil {
+		err = errors.Warning("fns: get cache failed").WithCause(resultErr)
+		return
+	}
+	if len(result.Items) != len(params) {
+		err = errors.Warning("fns: get cache failed").WithCause(fmt.Errorf("number of results is not matched"))
+		return
+	}
+	values = make([][]byte, len(params))
+	for i, item := range result.Items {
+		if !item.Has {
+			misses = append(misses, i)
+			continue
+		}
+		values[i] = item.Value
+	}
+	return
+}
+
+type getMultiFnParam struct {
+	Keys []getFnParam `json:"keys" avro:"keys"`
+}
+
+type getMultiResult struct {
+	Items []getResult `json:"items" avro:"items"`
+}
+
+type getMultiFn struct {
+	store Store
+}
+
+func (fn *getMultiFn) Name() string {
+	return string(getMultiFnName)
+}
+
+func (fn *getMultiFn) Internal() bool {
+	return true
+}
+
+func (fn *getMultiFn) Readonly() bool {
+	return false
+}
+
+func (fn *getMultiFn) Handle(r services.Request) (v interface{}, err error) {
+	if !r.Param().Valid() {
+		err = errors.Warning("fns: get cache failed").WithCause(errors.Warning("param is invalid"))
+		return
+	}
+	param, paramErr := services.ValueOfParam[getMultiFnParam](r.Param())
+	if paramErr != nil {
+		err = errors.Warning("fns: get cache failed").WithCause(paramErr)
+		return
+	}
+	keys := make([][]byte, len(param.Keys))
+	// generation of group is loaded once for each group
+	groups := make(map[string][]byte)
+	for i, item := range param.Keys {
+		key := bytex.FromString(item.Key)
+		if len(key) == 0 {
+			err = errors.Warning("fns: get cache failed").WithCause(errors.Warning("param is invalid"))
+			return
+		}
+		if item.Group == "" {
+			keys[i] = key
+			continue
+		}
+		prefix, has := groups[item.Group]
+		if !has {
+			prefix, err = groupedKey(r, fn.store, bytex.FromString(item.Group), nil)
+			if err != nil {
+				err = errors.Warning("fns: get cache failed").WithCause(err)
+				return
+			}
+			groups[item.Group] = prefix
+		}
+		keys[i] = append(append(make([]byte, 0, len(prefix)+len(key)), prefix...), key...)
+	}
+	items := make([]getResult, len(keys))
+	if multi, ok := fn.store.(MultiStore); ok {
+		values, has, getErr := multi.GetMulti(r, keys)
+		if getErr != nil {
+			err = errors.Warning("fns: get cache failed").WithCause(getErr)
+			return
+		}
+		if len(values) != len(keys) || len(has) != len(keys) {
+			err = errors.Warning("fns: get cache failed").WithCause(fmt.Errorf("number of values is not matched"))
+			return
+		}
+		for i := range keys {
+			items[i] = getResult{
+				Has:   has[i],
+				Value: values[i],
+			}
+		}
+	} else {
+		for i, key := range keys {
+			value, has, getErr := fn.store.Get(r, key)
+			if getErr != nil {
+				err = errors.Warning("fns: get cache failed").WithCause(getErr)
+				return
+			}
+			items[i] = getResult{
+				Has:   has,
+				Value: value,
+			}
+		}
+	}
+	v = getMultiResult{
+		Items: items,
+	}
+	return
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package caches_test
+
+import (
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/caches"
+	"github.com/aacfactory/fns/shareds"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadMulti(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	if err := manager.Add(caches.New()); err != nil {
+		t.Fatal(err)
+	}
+	rt := runtime.New("id", "name", versions.Origin(), nil, log, nil, manager, nil, shared)
+	ctx := runtime.With(context.TODO(), rt)
+	logs.With(ctx, log)
+
+	if err := caches.Set(ctx, userParam{Id: "1"}, "one", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := caches.Set(ctx, groupedUserParam{userParam{Id: "3", Group: "users"}}, "three", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	params := []caches.KeyParam{
+		userParam{Id: "1"},
+		userParam{Id: "2"},
+		groupedUserParam{userParam{Id: "3", Group: "users"}},
+		userParam{Id: "4"},
+	}
+	values, misses, err := caches.LoadMulti[string](ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, []string{"one", "", "three", ""}) || !reflect.DeepEqual(misses, []int{1, 3}) {
+		t.Fatal("unexpected partial hits", values, misses)
+	}
+	// backfill misses
+	for i, value := range map[int]string{1: "two", 3: "four"} {
+		if err = caches.Set(ctx, params[i], value, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	values, misses, err = caches.LoadMulti[string](ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, []string{"one", "two", "three", "four"}) || len(misses) != 0 {
+		t.Fatal("unexpected hits after backfill", values, misses)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package caches
+
+import (
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"time"
+)
+
+var (
+	onceKeyPrefix = []byte("fns:caches:once:")
+)
+
+// Once
+// returns true only for the first call of key within ttl, so a fn can skip redelivered events.
+// it is backed by Incr of shared store, which is atomic, so concurrent first calls have only one winner.
+// when the store fails, it returns true and logs a warning, because dropping an event is worse than handling it twice.
+// when setting ttl fails, the key is removed, so the next call is first time again rather than never.
+func Once(ctx context.Context, key []byte, ttl time.Duration) (firstTime bool) {
+	store := runtime.SharedStore(ctx)
+	k := append(append(make([]byte, 0, len(onceKeyPrefix)+len(key)), onceKeyPrefix...), key...)
+	n, incrErr := store.Incr(ctx, k, 1)
+	if incrErr != nil {
+		log := logs.Load(ctx)
+		if log.WarnEnabled() {
+			log.Warn().Cause(incrErr).With("key", string(key)).Message("fns: once failed, treated as first time")
+		}
+		firstTime = true
+		return
+	}
+	if n != 1 {
+		return
+	}
+	firstTime = true
+	if expireErr := store.Expire(ctx, k, ttl); expireErr != nil {
+		// key without ttl would make later calls never be first time, so it is removed
+		rmErr := store.Remove(ctx, k)
+		log := logs.Load(ctx)
+		if log.WarnEnabled() {
+			log.Warn().Cause(expireErr).With("key", string(key)).With("removed", rmErr == nil).Message("fns: once set ttl failed")
+		}
+	}
+	return
+}
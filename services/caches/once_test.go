@@ -0,0 +1,111 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package caches_test
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services/caches"
+	"github.com/aacfactory/fns/shareds"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOnce(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	rt := runtime.New("id", "name", versions.Origin(), nil, log, nil, nil, nil, shared)
+	ctx := runtime.With(context.TODO(), rt)
+	logs.With(ctx, log)
+
+	wins := new(atomic.Int64)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if caches.Once(ctx, []byte("event:1"), 200*time.Millisecond) {
+				wins.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := wins.Load(); n != 1 {
+		t.Fatal("want one first time, got", n)
+	}
+	if caches.Once(ctx, []byte("event:1"), 200*time.Millisecond) {
+		t.Fatal("repeat within window must not be first time")
+	}
+	if !caches.Once(ctx, []byte("event:2"), 200*time.Millisecond) {
+		t.Fatal("other key must be first time")
+	}
+	time.Sleep(300 * time.Millisecond)
+	if !caches.Once(ctx, []byte("event:1"), 200*time.Millisecond) {
+		t.Fatal("key must be first time after window")
+	}
+}
+
+type expireFailedStore struct {
+	shareds.Store
+}
+
+func (store *expireFailedStore) Expire(_ context.Context, _ []byte, _ time.Duration) (err error) {
+	err = errors.Warning("expire failed")
+	return
+}
+
+type expireFailedShared struct {
+	shareds.Shared
+}
+
+func (shared *expireFailedShared) Store() shareds.Store {
+	return &expireFailedStore{Store: shared.Shared.Store()}
+}
+
+func TestOnce_ExpireFailed(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	rt := runtime.New("id", "name", versions.Origin(), nil, log, nil, nil, nil, &expireFailedShared{Shared: shared})
+	ctx := runtime.With(context.TODO(), rt)
+	logs.With(ctx, log)
+
+	if !caches.Once(ctx, []byte("event:1"), time.Minute) {
+		t.Fatal("first call must be first time")
+	}
+	// key without ttl must not be left
+	if !caches.Once(ctx, []byte("event:1"), time.Minute) {
+		t.Fatal("key without ttl was left")
+	}
+}
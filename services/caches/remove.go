@@ -31,19 +31,15 @@ func Remove(ctx context.Context, param interface{}) (err error) {
 		err = errors.Warning("fns: remove cache failed").WithCause(fmt.Errorf("param is nil"))
 		return
 	}
-	kp, ok := param.(KeyParam)
-	if !ok {
-		err = errors.Warning("fns: remove cache failed").WithCause(fmt.Errorf("param dose not implement caches.KeyParam"))
-		return
-	}
-	key, keyErr := kp.CacheKey(ctx)
+	key, group, keyErr := keyOfParam(ctx, param)
 	if keyErr != nil {
 		err = errors.Warning("fns: remove cache failed").WithCause(keyErr)
 		return
 	}
 	eps := runtime.Endpoints(ctx)
 	_, doErr := eps.Request(ctx, endpointName, remFnName, removeFnParam{
-		Key: bytex.ToString(key),
+		Key:   bytex.ToString(key),
+		Group: bytex.ToString(group),
 	}, services.WithInternalRequest())
 	if doErr != nil {
 		err = doErr
@@ -53,7 +49,8 @@ func Remove(ctx context.Context, param interface{}) (err error) {
 }
 
 type removeFnParam struct {
-	Key string `json:"key" avro:"key"`
+	Key   string `json:"key" avro:"key"`
+	Group string `json:"group" avro:"group"`
 }
 
 type removeFn struct {
@@ -87,6 +84,11 @@ func (fn *removeFn) Handle(r services.Request) (v interface{}, err error) {
 		err = errors.Warning("fns: remove cache failed").WithCause(errors.Warning("param is invalid"))
 		return
 	}
+	key, err = groupedKey(r, fn.store, bytex.FromString(param.Group), key)
+	if err != nil {
+		err = errors.Warning("fns: remove cache failed").WithCause(err)
+		return
+	}
 	removeErr := fn.store.Remove(r, key)
 	if removeErr != nil {
 		err = errors.Warning("fns: remove cache failed").WithCause(removeErr)
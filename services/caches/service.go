@@ -43,6 +43,7 @@ func NewWithStore(store Store) services.Service {
 // @cache set 10
 // @cache remove
 // @cache get-set 10
+// a write fn evicts cached values by Evict or EvictGroup.
 func New() services.Service {
 	return NewWithStore(&defaultStore{})
 }
@@ -70,11 +71,17 @@ func (s *service) Construct(options services.Options) (err error) {
 	s.AddFunction(&getFn{
 		store: store,
 	})
+	s.AddFunction(&getMultiFn{
+		store: store,
+	})
 	s.AddFunction(&setFn{
 		store: store,
 	})
 	s.AddFunction(&removeFn{
 		store: store,
 	})
+	s.AddFunction(&evictGroupFn{
+		store: store,
+	})
 	return
 }
@@ -37,12 +37,7 @@ func Set(ctx context.Context, param interface{}, value interface{}, ttl time.Dur
 		err = errors.Warning("fns: set cache failed").WithCause(fmt.Errorf("value is invalid"))
 		return
 	}
-	kp, ok := param.(KeyParam)
-	if !ok {
-		err = errors.Warning("fns: set cache failed").WithCause(fmt.Errorf("param dose not implement caches.KeyParam"))
-		return
-	}
-	key, keyErr := kp.CacheKey(ctx)
+	key, group, keyErr := keyOfParam(ctx, param)
 	if keyErr != nil {
 		err = errors.Warning("fns: set cache failed").WithCause(keyErr)
 		return
@@ -59,6 +54,7 @@ func Set(ctx context.Context, param interface{}, value interface{}, ttl time.Dur
 	eps := runtime.Endpoints(ctx)
 	_, doErr := eps.Request(ctx, endpointName, setFnName, setFnParam{
 		Key:   bytex.ToString(key),
+		Group: bytex.ToString(group),
 		Value: p,
 		TTL:   ttl,
 	}, services.WithInternalRequest())
@@ -71,6 +67,7 @@ func Set(ctx context.Context, param interface{}, value interface{}, ttl time.Dur
 
 type setFnParam struct {
 	Key   string        `json:"key" avro:"key"`
+	Group string        `json:"group" avro:"group"`
 	Value []byte        `json:"value" avro:"value"`
 	TTL   time.Duration `json:"ttl" avro:"ttl"`
 }
@@ -106,6 +103,11 @@ func (fn *setFn) Handle(r services.Request) (v interface{}, err error) {
 		err = errors.Warning("fns: set cache failed").WithCause(errors.Warning("param is invalid"))
 		return
 	}
+	key, err = groupedKey(r, fn.store, bytex.FromString(param.Group), key)
+	if err != nil {
+		err = errors.Warning("fns: set cache failed").WithCause(err)
+		return
+	}
 	value := param.Value
 	if len(value) == 0 {
 		err = errors.Warning("fns: set cache failed").WithCause(errors.Warning("param is invalid"))
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	sc "context"
+	se "errors"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+)
+
+var (
+	// ErrContextCanceled
+	// 499 is the status of nginx for clients which closed requests.
+	ErrContextCanceled         = errors.New(499, "***CANCELED***", "fns: context canceled")
+	ErrContextDeadlineExceeded = errors.Timeout("fns: context deadline exceeded")
+)
+
+// ContextErr
+// returns ctx.Err() as a CodeError, so canceled or expired requests fail the same way in every fn,
+// generated proxies call it before loading cache and requesting.
+func ContextErr(ctx context.Context) (err error) {
+	cause := ctx.Err()
+	if cause == nil {
+		return
+	}
+	if se.Is(cause, sc.DeadlineExceeded) {
+		err = ErrContextDeadlineExceeded.WithCause(cause)
+		return
+	}
+	err = ErrContextCanceled.WithCause(cause)
+	return
+}
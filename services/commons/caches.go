@@ -0,0 +1,135 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package commons
+
+import (
+	"fmt"
+	"github.com/aacfactory/avro"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/caches"
+	"reflect"
+)
+
+var (
+	keyParamType = reflect.TypeOf((*caches.KeyParam)(nil)).Elem()
+)
+
+// isCachedList
+// returns true when param is a slice whose elements implement caches.KeyParam and result is a slice,
+// then every item is cached by its param item, so items of result must be in the same order as param.
+func isCachedList(param reflect.Type, result reflect.Type) bool {
+	if param.Kind() != reflect.Slice || result.Kind() != reflect.Slice {
+		return false
+	}
+	return param.Elem().Implements(keyParamType)
+}
+
+func listItems(list reflect.Value) (items []any) {
+	items = make([]any, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		items[i] = list.Index(i).Interface()
+	}
+	return
+}
+
+// handleCachedList
+// loads cached items of param at once, only misses are handled, and handled items are cached in get-set mode.
+func (fn *Fn[P, R]) handleCachedList(r services.Request, param P) (v R, err error) {
+	log := logs.Load(r)
+	pv := reflect.ValueOf(param)
+	if pv.Len() == 0 {
+		v, err = fn.handler(r, param)
+		return
+	}
+	items := listItems(pv)
+	values, misses, cacheErr := caches.GetMulti(r, items)
+	if cacheErr != nil {
+		if log.WarnEnabled() {
+			log.Warn().Cause(cacheErr).With("fns", "caches").Message("fns: get cache failed")
+		}
+		v, err = fn.handler(r, param)
+		if err == nil && fn.cacheCommand == GetSetCacheMod {
+			fn.setCachedList(r, items, reflect.ValueOf(v), nil)
+		}
+		return
+	}
+	rv := reflect.MakeSlice(reflect.TypeOf(v), pv.Len(), pv.Len())
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		if decodeErr := avro.Unmarshal(value, rv.Index(i).Addr().Interface()); decodeErr != nil {
+			if log.WarnEnabled() {
+				log.Warn().Cause(decodeErr).With("fns", "caches").Message("fns: get cache failed")
+			}
+			misses = append(misses, i)
+		}
+	}
+	if log.DebugEnabled() {
+		log.Debug().With("cache-hit", pv.Len()-len(misses)).With("cache-miss", len(misses)).Message("fns: get fn result from cache")
+	}
+	if len(misses) > 0 {
+		missed := reflect.MakeSlice(pv.Type(), 0, len(misses))
+		for _, i := range misses {
+			missed = reflect.Append(missed, pv.Index(i))
+		}
+		handled, handleErr := fn.handler(r, missed.Interface().(P))
+		if handleErr != nil {
+			err = handleErr
+			return
+		}
+		hv := reflect.ValueOf(handled)
+		if hv.Len() != len(misses) {
+			err = errors.Warning("fns: handle cached list failed").WithCause(fmt.Errorf("result must have one item for each param item")).
+				WithMeta("params", fmt.Sprint(len(misses))).WithMeta("results", fmt.Sprint(hv.Len()))
+			return
+		}
+		for j, i := range misses {
+			rv.Index(i).Set(hv.Index(j))
+		}
+		if fn.cacheCommand == GetSetCacheMod {
+			fn.setCachedList(r, items, rv, misses)
+		}
+	}
+	v = rv.Interface().(R)
+	return
+}
+
+// setCachedList
+// caches items of list by items of param, indexes are all items when it is nil.
+func (fn *Fn[P, R]) setCachedList(r services.Request, params []any, list reflect.Value, indexes []int) {
+	if list.Len() != len(params) {
+		return
+	}
+	log := logs.Load(r)
+	if indexes == nil {
+		indexes = make([]int, len(params))
+		for i := range indexes {
+			indexes[i] = i
+		}
+	}
+	for _, i := range indexes {
+		if cacheErr := caches.Set(r, params[i], list.Index(i).Interface(), fn.cacheTTL); cacheErr != nil {
+			if log.WarnEnabled() {
+				log.Warn().Cause(cacheErr).With("fns", "caches").Message("fns: set cache failed")
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package commons_test
+
+import (
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/caches"
+	"github.com/aacfactory/fns/services/commons"
+	"github.com/aacfactory/fns/shareds"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type userId string
+
+func (id userId) CacheKey(_ context.Context) (key []byte, err error) {
+	key = []byte("users:" + id)
+	return
+}
+
+func TestCachedList(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	if err := manager.Add(caches.New()); err != nil {
+		t.Fatal(err)
+	}
+	rt := runtime.New("id", "name", versions.Origin(), nil, log, nil, manager, nil, shared)
+	ctx := runtime.With(context.TODO(), rt)
+	logs.With(ctx, log)
+
+	if err := caches.Set(ctx, userId("1"), "cached:1", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	var handled []userId
+	fn := commons.NewFn[[]userId, []string]("list", func(ctx context.Context, param []userId) (v []string, err error) {
+		handled = append(handled, param...)
+		for _, id := range param {
+			v = append(v, "handled:"+string(id))
+		}
+		return
+	}, commons.Cache(commons.GetSetCacheMod, "60"))
+
+	param := []userId{"1", "2", "3"}
+	v, err := fn.Handle(services.NewRequest(ctx, []byte("users"), []byte("list"), param))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, []string{"cached:1", "handled:2", "handled:3"}) || !reflect.DeepEqual(handled, []userId{"2", "3"}) {
+		t.Fatal("misses were not handled alone", v, handled)
+	}
+	// misses were backfilled
+	handled = nil
+	v, err = fn.Handle(services.NewRequest(ctx, []byte("users"), []byte("list"), param))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, []string{"cached:1", "handled:2", "handled:3"}) || len(handled) != 0 {
+		t.Fatal("backfilled items were not cached", v, handled)
+	}
+}
@@ -20,18 +20,24 @@ package commons
 import (
 	"fmt"
 	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/versions"
 	"github.com/aacfactory/fns/context"
 	"github.com/aacfactory/fns/logs"
 	"github.com/aacfactory/fns/runtime"
 	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/audits"
 	"github.com/aacfactory/fns/services/authorizations"
 	"github.com/aacfactory/fns/services/caches"
+	"github.com/aacfactory/fns/services/crons"
+	"github.com/aacfactory/fns/services/features"
 	"github.com/aacfactory/fns/services/metrics"
 	"github.com/aacfactory/fns/services/permissions"
 	"github.com/aacfactory/fns/services/validators"
+	"github.com/aacfactory/fns/transports"
 	"github.com/aacfactory/fns/transports/middlewares/cachecontrol"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -54,6 +60,17 @@ type FnOptions struct {
 	cacheControl    []cachecontrol.MakeOption
 	metric          bool
 	barrier         bool
+	routes          []services.FnRoute
+	headers         [][2][]byte
+	since           versions.Version
+	removed         versions.Version
+	stream          bool
+	sla             time.Duration
+	cron            string
+	feature         string
+	priority        services.Priority
+	auditAction     string
+	auditResource   string
 }
 
 type FnOption func(opt *FnOptions) (err error)
@@ -111,6 +128,81 @@ func Metric() FnOption {
 	}
 }
 
+// SLA
+// use @sla {duration}, such as @sla 200ms, latency of fn over budget is counted as a sla miss,
+// see metrics.SLAStats.
+func SLA(budget string) FnOption {
+	return func(opt *FnOptions) (err error) {
+		d, parseErr := time.ParseDuration(strings.TrimSpace(budget))
+		if parseErr != nil {
+			err = errors.Warning("invalid sla budget").WithCause(parseErr)
+			return
+		}
+		if d <= 0 {
+			err = errors.Warning("invalid sla budget").WithCause(fmt.Errorf("budget must be positive"))
+			return
+		}
+		opt.sla = d
+		return
+	}
+}
+
+// Cron
+// use @cron {spec}, such as @cron 0 */5 * * * *, fn is requested internally on schedule by crons service.
+func Cron(spec string) FnOption {
+	return func(opt *FnOptions) (err error) {
+		spec = strings.TrimSpace(spec)
+		if _, parseErr := crons.Parse(spec); parseErr != nil {
+			err = errors.Warning("invalid cron spec").WithCause(parseErr)
+			return
+		}
+		opt.cron = spec
+		return
+	}
+}
+
+// Feature
+// use @feature {name}, fn is served only when the flag is enabled by features service, otherwise 404 is returned.
+// request is checked against @since and @removed first, so a disabled flag can not resurrect a removed fn,
+// and @deprecated is marked as usual when the flag is enabled.
+func Feature(name string) FnOption {
+	return func(opt *FnOptions) (err error) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			err = errors.Warning("invalid feature name")
+			return
+		}
+		opt.feature = name
+		return
+	}
+}
+
+// Priority
+// use @priority {low|normal|high}, fn is queued in the lane of priority when workers are busy and lanes are enabled.
+func Priority(priority string) FnOption {
+	return func(opt *FnOptions) (err error) {
+		opt.priority, err = services.ParsePriority(priority)
+		return
+	}
+}
+
+// Audit
+// use @audit resource={field} action={action}, such as @audit resource=id action=update,
+// fn is recorded as an audits.Entry and written by audits hook after request is responded.
+// field is json name or name of a field of param, resource id is the value of it, resource is optional.
+func Audit(action string, resource string) FnOption {
+	return func(opt *FnOptions) (err error) {
+		action = strings.TrimSpace(action)
+		if action == "" {
+			err = errors.Warning("invalid audit action")
+			return
+		}
+		opt.auditAction = action
+		opt.auditResource = strings.TrimSpace(resource)
+		return
+	}
+}
+
 func Barrier() FnOption {
 	return func(opt *FnOptions) (err error) {
 		opt.barrier = true
@@ -118,6 +210,72 @@ func Barrier() FnOption {
 	}
 }
 
+// Route
+// use @http {method} {pattern}, method is GET or POST, path params of pattern are like :id or {id}.
+func Route(method string, pattern string) FnOption {
+	return func(opt *FnOptions) (err error) {
+		route, routeErr := services.NewFnRoute(method, pattern)
+		if routeErr != nil {
+			err = routeErr
+			return
+		}
+		opt.routes = append(opt.routes, route)
+		return
+	}
+}
+
+// Header
+// use @header {name}: {value}, header is set into response of external request.
+func Header(name string, value string) FnOption {
+	return func(opt *FnOptions) (err error) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			err = errors.Warning("invalid header name")
+			return
+		}
+		opt.headers = append(opt.headers, [2][]byte{[]byte(name), []byte(value)})
+		return
+	}
+}
+
+// Stream
+// use @stream, json body of POST request is decoded into param as stream, instead of being read into memory first.
+// it works when transport streams request body, such as fast transport with streamRequestBody.
+func Stream() FnOption {
+	return func(opt *FnOptions) (err error) {
+		opt.stream = true
+		return
+	}
+}
+
+// Since
+// use @since {version}, request pinned before the version is rejected with 404.
+func Since(version string) FnOption {
+	return func(opt *FnOptions) (err error) {
+		ver, parseErr := versions.Parse([]byte(version))
+		if parseErr != nil {
+			err = errors.Warning("invalid since version").WithCause(parseErr)
+			return
+		}
+		opt.since = ver
+		return
+	}
+}
+
+// Removed
+// use @removed {version}, request pinned at or after the version is rejected with 410.
+func Removed(version string) FnOption {
+	return func(opt *FnOptions) (err error) {
+		ver, parseErr := versions.Parse([]byte(version))
+		if parseErr != nil {
+			err = errors.Warning("invalid removed version").WithCause(parseErr)
+			return
+		}
+		opt.removed = ver
+		return
+	}
+}
+
 const (
 	GetCacheMod    = "get"
 	GetSetCacheMod = "get-set"
@@ -191,13 +349,24 @@ func CacheControl(maxAge int, public bool, mustRevalidate bool, proxyRevalidate
 }
 
 func NewFn[P any, R any](name string, handler FnHandler[P, R], options ...FnOption) services.Fn {
-	opt := FnOptions{}
+	opt := FnOptions{
+		priority: services.NormalPriority,
+	}
 	for _, option := range options {
 		if optErr := option(&opt); optErr != nil {
 			panic(fmt.Sprintf("%+v", errors.Warning("new fn failed").WithMeta("fn", name).WithCause(optErr)))
 			return nil
 		}
 	}
+	var auditResource []int
+	if opt.auditResource != "" {
+		index, has := auditResourceIndex(reflect.TypeOf(new(P)).Elem(), opt.auditResource)
+		if !has {
+			panic(fmt.Sprintf("%+v", errors.Warning("new fn failed").WithMeta("fn", name).WithCause(fmt.Errorf("audit resource field was not found in param")).WithMeta("resource", opt.auditResource)))
+			return nil
+		}
+		auditResource = index
+	}
 	return &Fn[P, R]{
 		name:                    name,
 		internal:                opt.internal,
@@ -209,6 +378,17 @@ func NewFn[P any, R any](name string, handler FnHandler[P, R], options ...FnOpti
 		permission:              opt.permission,
 		metric:                  opt.metric,
 		barrier:                 opt.barrier,
+		routes:                  opt.routes,
+		headers:                 opt.headers,
+		since:                   opt.since,
+		removed:                 opt.removed,
+		stream:                  opt.stream,
+		sla:                     opt.sla,
+		cron:                    opt.cron,
+		feature:                 opt.feature,
+		priority:                opt.priority,
+		auditAction:             opt.auditAction,
+		auditResource:           auditResource,
 		cacheCommand:            opt.cacheCommand,
 		cacheTTL:                opt.cacheTTL,
 		cacheControl:            len(opt.cacheControl) > 0,
@@ -216,6 +396,7 @@ func NewFn[P any, R any](name string, handler FnHandler[P, R], options ...FnOpti
 		handler:                 handler,
 		hasParam:                reflect.TypeOf(new(P)) != emptyType,
 		hasResult:               reflect.TypeOf(new(R)) != emptyType,
+		cachedList:              isCachedList(reflect.TypeOf(new(P)).Elem(), reflect.TypeOf(new(R)).Elem()),
 	}
 }
 
@@ -233,6 +414,16 @@ func NewFn[P any, R any](name string, handler FnHandler[P, R], options ...FnOpti
 // @cache-control {max-age=sec} {public=true} {must-revalidate} {proxy-revalidate}
 // @barrier
 // @metric
+// @sla {duration}
+// @cron {spec}
+// @feature {name}
+// @priority {low|normal|high}
+// @audit resource={field} action={action}
+// @http {GET|POST} {pattern}
+// @header {name}: {value}
+// @since {version}
+// @removed {version}
+// @stream
 // @title {title}
 // @description >>>
 // {description}
@@ -253,6 +444,17 @@ type Fn[P any, R any] struct {
 	validationTitle         string
 	metric                  bool
 	barrier                 bool
+	routes                  []services.FnRoute
+	headers                 [][2][]byte
+	since                   versions.Version
+	removed                 versions.Version
+	stream                  bool
+	sla                     time.Duration
+	cron                    string
+	feature                 string
+	priority                services.Priority
+	auditAction             string
+	auditResource           []int
 	cacheCommand            string
 	cacheTTL                time.Duration
 	cacheControl            bool
@@ -260,6 +462,7 @@ type Fn[P any, R any] struct {
 	handler                 FnHandler[P, R]
 	hasParam                bool
 	hasResult               bool
+	cachedList              bool
 }
 
 func (fn *Fn[P, R]) Name() string {
@@ -274,6 +477,39 @@ func (fn *Fn[P, R]) Readonly() bool {
 	return fn.readonly
 }
 
+func (fn *Fn[P, R]) Cron() string {
+	return fn.cron
+}
+
+func (fn *Fn[P, R]) Feature() string {
+	return fn.feature
+}
+
+func (fn *Fn[P, R]) Priority() services.Priority {
+	return fn.priority
+}
+
+func (fn *Fn[P, R]) Stream() bool {
+	return fn.stream
+}
+
+func (fn *Fn[P, R]) Validation() (title string, ok bool) {
+	title, ok = fn.validationTitle, fn.validation
+	return
+}
+
+func (fn *Fn[P, R]) Routes() []services.FnRoute {
+	return fn.routes
+}
+
+func (fn *Fn[P, R]) Since() versions.Version {
+	return fn.since
+}
+
+func (fn *Fn[P, R]) Removed() versions.Version {
+	return fn.removed
+}
+
 func (fn *Fn[P, R]) Handle(r services.Request) (v interface{}, err error) {
 	if fn.internal && !r.Header().Internal() {
 		err = errors.NotAcceptable("fns: fn cannot be accessed externally")
@@ -282,6 +518,13 @@ func (fn *Fn[P, R]) Handle(r services.Request) (v interface{}, err error) {
 	if fn.metric {
 		metrics.Begin(r)
 	}
+	if fn.auditAction != "" {
+		audits.Prepare(r)
+	}
+	var beg time.Time
+	if fn.sla > 0 {
+		beg = time.Now()
+	}
 	if fn.barrier {
 		var key []byte
 		if fn.authorization {
@@ -304,6 +547,13 @@ func (fn *Fn[P, R]) Handle(r services.Request) (v interface{}, err error) {
 	} else {
 		v, err = fn.handle(r)
 	}
+	if fn.sla > 0 {
+		ep, name := r.Fn()
+		metrics.ObserveSLA(ep, name, fn.sla, time.Since(beg))
+	}
+	if fn.auditAction != "" {
+		audits.Record(r, fn.auditAction, fn.auditResourceId(r), err)
+	}
 	if fn.metric {
 		if err != nil {
 			metrics.EndWithCause(r, err)
@@ -320,6 +570,14 @@ func (fn *Fn[P, R]) Handle(r services.Request) (v interface{}, err error) {
 		if fn.deprecated {
 			services.MarkDeprecated(r)
 		}
+		// headers
+		if len(fn.headers) > 0 {
+			if header, has := transports.TryLoadResponseHeader(r); has {
+				for _, h := range fn.headers {
+					header.Set(h[0], h[1])
+				}
+			}
+		}
 	}
 	return
 }
@@ -362,7 +620,23 @@ func (fn *Fn[P, R]) handle(r services.Request) (v R, err error) {
 			log.Debug().With("permission", true).Message("fns: fn permission is valid")
 		}
 	}
+	// feature
+	if fn.feature != "" {
+		if err = features.EnforceContext(r, fn.feature); err != nil {
+			return
+		}
+	}
 
+	// cache get or get-set of list
+	if fn.cachedList && (fn.cacheCommand == GetCacheMod || fn.cacheCommand == GetSetCacheMod) {
+		if !paramScanned {
+			if param, err = fn.param(r); err != nil {
+				return
+			}
+		}
+		v, err = fn.handleCachedList(r, param)
+		return
+	}
 	// cache get or get-set
 	if fn.hasParam && (fn.cacheCommand == GetCacheMod || fn.cacheCommand == GetSetCacheMod) {
 		if !paramScanned {
@@ -390,6 +664,12 @@ func (fn *Fn[P, R]) handle(r services.Request) (v R, err error) {
 	if fn.hasParam && fn.cacheCommand != "" {
 		switch fn.cacheCommand {
 		case SetCacheMod, GetSetCacheMod:
+			if fn.cachedList {
+				if err == nil {
+					fn.setCachedList(r, listItems(reflect.ValueOf(param)), reflect.ValueOf(v), nil)
+				}
+				break
+			}
 			if fn.hasResult {
 				if cacheErr := caches.Set(r, param, v, fn.cacheTTL); cacheErr != nil {
 					if log.WarnEnabled() {
@@ -403,6 +683,14 @@ func (fn *Fn[P, R]) handle(r services.Request) (v R, err error) {
 			}
 			break
 		case RemoveCacheMod:
+			if fn.cachedList {
+				for _, item := range listItems(reflect.ValueOf(param)) {
+					if cacheErr := caches.Remove(r, item); cacheErr != nil && log.WarnEnabled() {
+						log.Warn().Cause(cacheErr).With("fns", "caches").Message("fns: remove cache failed")
+					}
+				}
+				break
+			}
 			if cacheErr := caches.Remove(r, param); cacheErr != nil {
 				if log.WarnEnabled() {
 					log.Warn().Cause(cacheErr).With("fns", "caches").Message("fns: set cache failed")
@@ -420,6 +708,47 @@ func (fn *Fn[P, R]) handle(r services.Request) (v R, err error) {
 	return
 }
 
+func (fn *Fn[P, R]) auditResourceId(r services.Request) (id string) {
+	if len(fn.auditResource) == 0 {
+		return
+	}
+	param, err := fn.param(r)
+	if err != nil {
+		return
+	}
+	rv := reflect.ValueOf(param)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+	id = fmt.Sprint(rv.FieldByIndex(fn.auditResource).Interface())
+	return
+}
+
+func auditResourceIndex(typ reflect.Type, field string) (index []int, has bool) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == field || sf.Name == field {
+			index = sf.Index
+			has = true
+			return
+		}
+	}
+	return
+}
+
 func (fn *Fn[P, R]) param(r services.Request) (param P, err error) {
 	param, err = services.ValueOfParam[P](r.Param())
 	if err != nil {
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/context"
+	"sync"
+	"sync/atomic"
 )
 
 var (
@@ -35,17 +37,90 @@ type Component interface {
 
 type Components []Component
 
+// Get
+// returns the component named name, a lazy component which failed to construct is treated as not found, see Load.
 func (components Components) Get(name string) (v Component, has bool) {
+	v, has, _ = components.Load(name)
+	if !has || v == nil {
+		v, has = nil, false
+	}
+	return
+}
+
+// Load
+// returns the component named name, a lazy component is constructed at its first load, see Lazy.
+// err is the construct error of lazy component, and it is returned by every load after the failure.
+func (components Components) Load(name string) (v Component, has bool, err error) {
 	for _, component := range components {
-		if component.Name() == name {
+		if component.Name() != name {
+			continue
+		}
+		has = true
+		lazy, isLazy := component.(*lazyComponent)
+		if !isLazy {
 			v = component
-			has = true
 			return
 		}
+		if err = lazy.construct(); err != nil {
+			return
+		}
+		v = lazy.Component
+		return
 	}
 	return
 }
 
+// Lazy
+// marks component as lazy, its Construct runs at the first load instead of deploying, so startup is faster.
+// Construct runs once even when loads are concurrent, concurrent loads wait until it is done,
+// so Construct of component must not load itself.
+// lazy component is not checked by readiness probes, which means failures of it are found at its first use.
+func Lazy(component Component) Component {
+	return &lazyComponent{
+		Component: component,
+	}
+}
+
+type lazyComponent struct {
+	Component
+	once        sync.Once
+	options     Options
+	prepared    bool
+	constructed atomic.Bool
+	err         error
+}
+
+// Construct
+// keeps options until the first load.
+func (lazy *lazyComponent) Construct(options Options) (err error) {
+	lazy.options = options
+	lazy.prepared = true
+	return
+}
+
+func (lazy *lazyComponent) construct() error {
+	lazy.once.Do(func() {
+		if !lazy.prepared {
+			lazy.err = errors.Warning("fns: construct lazy component failed").WithMeta("component", lazy.Name()).WithCause(fmt.Errorf("service of component is not constructed"))
+			return
+		}
+		if err := lazy.Component.Construct(lazy.options); err != nil {
+			lazy.err = errors.Warning("fns: construct lazy component failed").WithMeta("component", lazy.Name()).WithCause(err)
+			return
+		}
+		lazy.constructed.Store(true)
+	})
+	return lazy.err
+}
+
+// Shutdown
+// shuts down component only when it was constructed.
+func (lazy *lazyComponent) Shutdown(ctx context.Context) {
+	if lazy.constructed.Load() {
+		lazy.Component.Shutdown(ctx)
+	}
+}
+
 func WithComponents(ctx context.Context, service []byte, components Components) {
 	ctx.SetLocalValue(append(contextComponentsKeyPrefix, service...), components)
 }
@@ -63,6 +138,28 @@ func LoadComponents(ctx context.Context, service []byte) Components {
 	return c
 }
 
+// LoadComponentOf
+// returns the first component of service which is a C, a lazy component is constructed when it is matched.
+// it is used by generated typed accessors, so callers do not need the name of component.
+func LoadComponentOf[C Component](ctx context.Context, service []byte) (c C, has bool) {
+	for _, component := range LoadComponents(ctx, service) {
+		lazy, isLazy := component.(*lazyComponent)
+		if isLazy {
+			component = lazy.Component
+		}
+		typed, ok := component.(C)
+		if !ok {
+			continue
+		}
+		if isLazy && lazy.construct() != nil {
+			return
+		}
+		c, has = typed, true
+		return
+	}
+	return
+}
+
 func LoadComponent[C Component](ctx context.Context, service []byte, name string) (c C, has bool) {
 	cc := LoadComponents(ctx, service)
 	if len(cc) == 0 {
@@ -82,3 +179,26 @@ func GetComponent[C Component](ctx context.Context, name string) (c C, has bool)
 	c, has = LoadComponent[C](ctx, service, name)
 	return
 }
+
+// RequireComponent
+// is GetComponent but returns error when component is not found, is not a C, or failed to construct lazily.
+func RequireComponent[C Component](ctx context.Context, name string) (c C, err error) {
+	req := LoadRequest(ctx)
+	service, _ := req.Fn()
+	v, has, loadErr := LoadComponents(ctx, service).Load(name)
+	if loadErr != nil {
+		err = loadErr
+		return
+	}
+	if !has {
+		err = errors.Warning("fns: require component failed").WithMeta("service", string(service)).WithMeta("component", name).WithCause(fmt.Errorf("component was not found"))
+		return
+	}
+	typed, ok := v.(C)
+	if !ok {
+		err = errors.Warning("fns: require component failed").WithMeta("service", string(service)).WithMeta("component", name).WithCause(fmt.Errorf("component is %T", v))
+		return
+	}
+	c = typed
+	return
+}
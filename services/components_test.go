@@ -0,0 +1,92 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"fmt"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type lazyClient struct {
+	name        string
+	constructed atomic.Int64
+	fail        bool
+}
+
+func (client *lazyClient) Name() string {
+	return client.name
+}
+
+func (client *lazyClient) Construct(_ services.Options) (err error) {
+	client.constructed.Add(1)
+	if client.fail {
+		err = fmt.Errorf("unavailable")
+	}
+	return
+}
+
+func (client *lazyClient) Shutdown(_ context.Context) {}
+
+func TestLazy(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	client := &lazyClient{name: "client"}
+	broken := &lazyClient{name: "broken", fail: true}
+	svc := &cycleService{Abstract: services.NewAbstract("lazy", false, services.Lazy(client), services.Lazy(broken))}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	if err := manager.Add(svc); err != nil {
+		t.Fatal(err)
+	}
+	if client.constructed.Load() != 0 || broken.constructed.Load() != 0 {
+		t.Fatal("lazy components were constructed at deploying")
+	}
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, has := svc.Components().Get("client")
+			if !has || v != client {
+				t.Error("lazy component was not loaded", v)
+			}
+		}()
+	}
+	wg.Wait()
+	if client.constructed.Load() != 1 {
+		t.Fatal("lazy component was constructed more than once", client.constructed.Load())
+	}
+	for i := 0; i < 2; i++ {
+		if _, has, err := svc.Components().Load("broken"); !has || err == nil {
+			t.Fatal("construct error was not surfaced", err)
+		}
+	}
+	if broken.constructed.Load() != 1 {
+		t.Fatal("failed lazy component was constructed again")
+	}
+	if _, has := svc.Components().Get("broken"); has {
+		t.Fatal("failed lazy component was found")
+	}
+}
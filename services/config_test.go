@@ -0,0 +1,95 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/configs"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"testing"
+)
+
+type weatherConfig struct {
+	ApiKey  string `json:"apiKey"`
+	Timeout string `json:"timeout" default:"3s"`
+}
+
+type weatherClient struct {
+	config weatherConfig
+}
+
+func (client *weatherClient) Name() string {
+	return "client"
+}
+
+func (client *weatherClient) Construct(options services.Options) (err error) {
+	client.config, err = services.ConfigAs[weatherConfig](options)
+	return
+}
+
+func (client *weatherClient) Shutdown(_ context.Context) {}
+
+type weatherService struct {
+	services.Abstract
+	config weatherConfig
+}
+
+func (svc *weatherService) Construct(options services.Options) (err error) {
+	if err = svc.Abstract.Construct(options); err != nil {
+		return
+	}
+	svc.config, err = services.ConfigAs[weatherConfig](options)
+	return
+}
+
+func TestServiceConfig(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	config := configs.New().AddService("weather", map[string]any{
+		"apiKey": "key",
+		"client": map[string]any{"apiKey": "client-key", "timeout": "1s"},
+	})
+	client := &weatherClient{}
+	svc := &weatherService{Abstract: services.NewAbstract("weather", false, &weatherComponent{}, client)}
+	manager := services.New("id", versions.Origin(), log, config.Services, nil)
+	if err := manager.Add(svc); err != nil {
+		t.Fatal(err)
+	}
+	if svc.config.ApiKey != "key" || svc.config.Timeout != "3s" {
+		t.Fatal("config of service mismatched", svc.config)
+	}
+	if client.config.ApiKey != "client-key" || client.config.Timeout != "1s" {
+		t.Fatal("config of component mismatched", client.config)
+	}
+}
+
+type weatherComponent struct{}
+
+func (component *weatherComponent) Name() string {
+	return "cache"
+}
+
+func (component *weatherComponent) Construct(_ services.Options) (err error) {
+	return
+}
+
+func (component *weatherComponent) Shutdown(_ context.Context) {}
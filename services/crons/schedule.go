@@ -0,0 +1,161 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package crons
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule
+// cron spec with six fields: second minute hour day-of-month month day-of-week,
+// five fields spec has no second field and runs at second zero.
+// each field supports *, */n, a, a-b, a-b/n and comma separated lists, day-of-week is 0-6 and 0 is sunday.
+// time matches when both day-of-month and day-of-week match.
+type Schedule struct {
+	second uint64
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+}
+
+type bounds struct {
+	min int
+	max int
+}
+
+var (
+	secondBounds = bounds{0, 59}
+	minuteBounds = bounds{0, 59}
+	hourBounds   = bounds{0, 23}
+	domBounds    = bounds{1, 31}
+	monthBounds  = bounds{1, 12}
+	dowBounds    = bounds{0, 6}
+)
+
+func Parse(spec string) (schedule Schedule, err error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 5 {
+		fields = append([]string{"0"}, fields...)
+	}
+	if len(fields) != 6 {
+		err = fmt.Errorf("fns: parse cron spec failed, expected 5 or 6 fields, got %d", len(fields))
+		return
+	}
+	items := []struct {
+		dst *uint64
+		b   bounds
+	}{
+		{&schedule.second, secondBounds},
+		{&schedule.minute, minuteBounds},
+		{&schedule.hour, hourBounds},
+		{&schedule.dom, domBounds},
+		{&schedule.month, monthBounds},
+		{&schedule.dow, dowBounds},
+	}
+	for i, item := range items {
+		*item.dst, err = parseField(fields[i], item.b)
+		if err != nil {
+			err = fmt.Errorf("fns: parse cron spec failed, field %d is invalid: %v", i+1, err)
+			return
+		}
+	}
+	return
+}
+
+func parseField(field string, b bounds) (bits uint64, err error) {
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if idx := strings.IndexByte(part, '/'); idx > 0 {
+			step, err = strconv.Atoi(part[idx+1:])
+			if err != nil || step < 1 {
+				err = fmt.Errorf("invalid step of %s", part)
+				return
+			}
+			part = part[:idx]
+		}
+		lo, hi := b.min, b.max
+		if part != "*" {
+			if idx := strings.IndexByte(part, '-'); idx > 0 {
+				lo, err = strconv.Atoi(part[:idx])
+				if err != nil {
+					return
+				}
+				hi, err = strconv.Atoi(part[idx+1:])
+				if err != nil {
+					return
+				}
+			} else {
+				lo, err = strconv.Atoi(part)
+				if err != nil {
+					return
+				}
+				if step == 1 {
+					hi = lo
+				}
+			}
+		}
+		if lo < b.min || hi > b.max || lo > hi {
+			err = fmt.Errorf("%s is out of range [%d, %d]", part, b.min, b.max)
+			return
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// Next
+// returns the first time after t matching schedule, zero time means there is no matched time in five years.
+func (schedule Schedule) Next(t time.Time) time.Time {
+	t = t.Add(time.Second - time.Duration(t.Nanosecond())).Truncate(time.Second)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !has(schedule.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(schedule.dom, t.Day()) || !has(schedule.dow, int(t.Weekday())) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(schedule.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(schedule.minute, t.Minute()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, t.Location())
+			continue
+		}
+		if !has(schedule.second, t.Second()) {
+			t = t.Add(time.Second)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package crons_test
+
+import (
+	"github.com/aacfactory/fns/services/crons"
+	"testing"
+	"time"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 3, 20, 500, time.UTC)
+	cases := map[string]time.Time{
+		"0 */5 * * * *":    time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC),
+		"*/15 * * * * *":   time.Date(2024, 1, 1, 10, 3, 30, 0, time.UTC),
+		"30 2 * * *":       time.Date(2024, 1, 2, 2, 30, 0, 0, time.UTC),
+		"0 0 9 * * 1-5":    time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+		"0 0 0 1 3 *":      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 12 29 2 *":    time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC),
+		"10,40 3 10 * * *": time.Date(2024, 1, 1, 10, 3, 40, 0, time.UTC),
+	}
+	for spec, expect := range cases {
+		schedule, err := crons.Parse(spec)
+		if err != nil {
+			t.Fatal(spec, err)
+		}
+		if next := schedule.Next(now); !next.Equal(expect) {
+			t.Error(spec, "expect", expect, "got", next)
+		}
+	}
+	for _, spec := range []string{"* * *", "60 * * * * *", "*/0 * * * * *", "a * * * * *"} {
+		if _, err := crons.Parse(spec); err == nil {
+			t.Error(spec, "should be invalid")
+		}
+	}
+}
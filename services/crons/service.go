@@ -0,0 +1,210 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package crons
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	endpointName = []byte("crons")
+	claimPrefix  = []byte("fns:crons:")
+)
+
+const (
+	// MissedSkip
+	// runs that were missed, such as previous run lasted too long, are skipped.
+	MissedSkip = "skip"
+	// MissedOnce
+	// runs that were missed are merged into one run, which starts at once.
+	MissedOnce = "once"
+)
+
+// Job
+// binds a cron spec to a fn, fns with @cron are added as jobs named `{service}.{fn}` automatically.
+type Job struct {
+	Name    string          `json:"name" yaml:"name"`
+	Spec    string          `json:"spec" yaml:"spec"`
+	Service string          `json:"service" yaml:"service"`
+	Fn      string          `json:"fn" yaml:"fn"`
+	Param   json.RawMessage `json:"param,omitempty" yaml:"param,omitempty"`
+}
+
+type Config struct {
+	Jobs []Job `json:"jobs,omitempty" yaml:"jobs,omitempty"`
+	// Missed
+	// skip (default) or once.
+	Missed string `json:"missed,omitempty" yaml:"missed,omitempty"`
+}
+
+// New
+// crons service requests fns internally on schedule.
+// each run is claimed in shared store, so only one node of cluster runs it.
+func New() services.Service {
+	return &service{
+		Abstract: services.NewAbstract(string(endpointName), true),
+		done:     make(chan struct{}),
+	}
+}
+
+type service struct {
+	services.Abstract
+	jobs   []Job
+	missed string
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func (svc *service) Renew() services.Service {
+	return New()
+}
+
+func (svc *service) Construct(options services.Options) (err error) {
+	err = svc.Abstract.Construct(options)
+	if err != nil {
+		return
+	}
+	config := Config{}
+	if configErr := options.Config.As(&config); configErr != nil {
+		err = errors.Warning("fns: crons service construct failed").WithCause(configErr)
+		return
+	}
+	switch config.Missed {
+	case "", MissedSkip:
+		svc.missed = MissedSkip
+		break
+	case MissedOnce:
+		svc.missed = MissedOnce
+		break
+	default:
+		err = errors.Warning("fns: crons service construct failed").WithCause(fmt.Errorf("missed must be skip or once")).WithMeta("missed", config.Missed)
+		return
+	}
+	svc.jobs = config.Jobs
+	return
+}
+
+func (svc *service) Listen(ctx context.Context) (err error) {
+	jobs := append(make([]Job, 0, len(svc.jobs)), svc.jobs...)
+	for _, info := range runtime.Endpoints(ctx).Info() {
+		for _, fn := range info.Functions {
+			if fn.Cron == "" {
+				continue
+			}
+			jobs = append(jobs, Job{
+				Name:    info.Name + "." + fn.Name,
+				Spec:    fn.Cron,
+				Service: info.Name,
+				Fn:      fn.Name,
+			})
+		}
+	}
+	for _, job := range jobs {
+		schedule, parseErr := Parse(job.Spec)
+		if parseErr != nil {
+			err = errors.Warning("fns: crons service listen failed").WithCause(parseErr).WithMeta("job", job.Name)
+			return
+		}
+		svc.wg.Add(1)
+		go svc.run(ctx, job, schedule)
+	}
+	return
+}
+
+func (svc *service) run(ctx context.Context, job Job, schedule Schedule) {
+	defer svc.wg.Done()
+	next := schedule.Next(time.Now())
+	for !next.IsZero() {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-svc.done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		svc.execute(ctx, job, next)
+		now := time.Now()
+		following := schedule.Next(next)
+		if svc.missed == MissedOnce && !following.IsZero() && following.Before(now) {
+			// latest missed run, it is executed at once
+			for {
+				after := schedule.Next(following)
+				if after.IsZero() || after.After(now) {
+					break
+				}
+				following = after
+			}
+			next = following
+			continue
+		}
+		next = schedule.Next(now)
+	}
+}
+
+func (svc *service) execute(ctx context.Context, job Job, at time.Time) {
+	log := svc.Log()
+	key := append(append(append(make([]byte, 0, 64), claimPrefix...), job.Name...), ':')
+	key = strconv.AppendInt(key, at.Unix(), 10)
+	store := runtime.SharedStore(ctx)
+	n, claimErr := store.Incr(ctx, key, 1)
+	if claimErr != nil {
+		if log.WarnEnabled() {
+			log.Warn().Cause(claimErr).With("job", job.Name).Message("fns: crons claim run failed")
+		}
+		return
+	}
+	if n != 1 {
+		// run is claimed by other node
+		return
+	}
+	_ = store.Expire(ctx, key, 24*time.Hour)
+	var param any
+	if len(job.Param) > 0 {
+		param = job.Param
+	}
+	beg := time.Now()
+	_, err := runtime.Endpoints(ctx).Request(ctx, bytex.FromString(job.Service), bytex.FromString(job.Fn), param, services.WithInternalRequest())
+	latency := time.Since(beg)
+	if err != nil {
+		if log.WarnEnabled() {
+			log.Warn().Cause(err).With("job", job.Name).With("latency", latency.String()).Message("fns: crons run failed")
+		}
+		return
+	}
+	if log.InfoEnabled() {
+		log.Info().With("job", job.Name).With("latency", latency.String()).Message("fns: crons run succeed")
+	}
+}
+
+func (svc *service) Shutdown(ctx context.Context) {
+	close(svc.done)
+	svc.wg.Wait()
+	svc.Abstract.Shutdown(ctx)
+}
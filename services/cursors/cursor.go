@@ -0,0 +1,175 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package cursors
+// provides opaque and tamper-evident cursors for keyset pagination.
+//
+// A cursor carries the sort key and id of the boundary row and the direction of paging,
+// it is signed by hmac, so clients can not forge a cursor to scan an arbitrary range.
+//
+// Forward (cursor is nil or not backward):
+//
+//	SELECT ... WHERE (sort_key, id) > ($key, $id) ORDER BY sort_key, id LIMIT $limit + 1
+//
+// Backward:
+//
+//	SELECT ... WHERE (sort_key, id) < ($key, $id) ORDER BY sort_key DESC, id DESC LIMIT $limit + 1
+//
+// Then pass rows to Paginate. Keyset queries are readonly, so mark the fn @readonly
+// and route it to read replica by the database annotation param of @sql, e.g. `@sql read`.
+package cursors
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/services"
+)
+
+var (
+	ErrInvalidCursor = errors.BadRequest("fns: invalid cursor")
+)
+
+const (
+	forward  = byte(0)
+	backward = byte(1)
+	macSize  = 16
+)
+
+type Cursor struct {
+	Key      string
+	Id       string
+	Backward bool
+}
+
+func New(secret []byte) *Codec {
+	return &Codec{
+		secret: secret,
+	}
+}
+
+type Codec struct {
+	secret []byte
+}
+
+func (codec *Codec) Encode(cursor Cursor) string {
+	buf := bytes.NewBuffer(make([]byte, 0, 1+binary.MaxVarintLen64*2+len(cursor.Key)+len(cursor.Id)+macSize))
+	if cursor.Backward {
+		buf.WriteByte(backward)
+	} else {
+		buf.WriteByte(forward)
+	}
+	buf.Write(binary.AppendUvarint(nil, uint64(len(cursor.Key))))
+	buf.WriteString(cursor.Key)
+	buf.Write(binary.AppendUvarint(nil, uint64(len(cursor.Id))))
+	buf.WriteString(cursor.Id)
+	buf.Write(codec.sign(buf.Bytes()))
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes())
+}
+
+func (codec *Codec) Decode(s string) (cursor Cursor, err error) {
+	p, decodeErr := base64.RawURLEncoding.DecodeString(s)
+	if decodeErr != nil || len(p) < 1+2+macSize {
+		err = ErrInvalidCursor
+		return
+	}
+	payload, mac := p[:len(p)-macSize], p[len(p)-macSize:]
+	if !hmac.Equal(mac, codec.sign(payload)) {
+		err = ErrInvalidCursor
+		return
+	}
+	switch payload[0] {
+	case forward:
+		break
+	case backward:
+		cursor.Backward = true
+		break
+	default:
+		err = ErrInvalidCursor
+		return
+	}
+	payload = payload[1:]
+	key, n := readString(payload)
+	if n < 0 {
+		err = ErrInvalidCursor
+		return
+	}
+	payload = payload[n:]
+	id, n := readString(payload)
+	if n < 0 || n != len(payload) {
+		err = ErrInvalidCursor
+		return
+	}
+	cursor.Key = key
+	cursor.Id = id
+	return
+}
+
+func (codec *Codec) sign(p []byte) []byte {
+	h := hmac.New(sha256.New, codec.secret)
+	h.Write(p)
+	return h.Sum(nil)[:macSize]
+}
+
+func readString(p []byte) (s string, n int) {
+	size, sn := binary.Uvarint(p)
+	if sn <= 0 || uint64(len(p)-sn) < size {
+		n = -1
+		return
+	}
+	n = sn + int(size)
+	s = string(p[sn:n])
+	return
+}
+
+// Paginate
+// makes a page from rows which are fetched by limit + 1 in order of the direction of from,
+// from is nil at first page. Cursor of page continues in the same direction, and prev turns back,
+// prev is empty at first page.
+func Paginate[T any](codec *Codec, from *Cursor, rows []T, limit int, key func(row T) (sortKey string, id string)) (page services.Page[T], prev string) {
+	isBackward := from != nil && from.Backward
+	if limit < 1 {
+		limit = 1
+	}
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	items := make([]T, len(rows))
+	copy(items, rows)
+	if isBackward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	page = services.NewPage[T](items, -1)
+	if len(rows) == 0 {
+		return
+	}
+	if hasMore {
+		sortKey, id := key(rows[len(rows)-1])
+		page.Cursor = codec.Encode(Cursor{Key: sortKey, Id: id, Backward: isBackward})
+	}
+	if from != nil {
+		sortKey, id := key(rows[0])
+		prev = codec.Encode(Cursor{Key: sortKey, Id: id, Backward: !isBackward})
+	}
+	return
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cursors_test
+
+import (
+	"fmt"
+	"github.com/aacfactory/fns/services/cursors"
+	"testing"
+)
+
+type row struct {
+	Key string
+	Id  string
+}
+
+func rowKey(r row) (string, string) {
+	return r.Key, r.Id
+}
+
+func compare(r row, key string, id string) int {
+	if r.Key != key {
+		if r.Key < key {
+			return -1
+		}
+		return 1
+	}
+	if r.Id < id {
+		return -1
+	} else if r.Id > id {
+		return 1
+	}
+	return 0
+}
+
+// fetch simulates the keyset query of database, rows are sorted by key and id.
+func fetch(rows []row, from *cursors.Cursor, limit int) []row {
+	matched := make([]row, 0, limit+1)
+	if from == nil || !from.Backward {
+		for _, r := range rows {
+			if from != nil && compare(r, from.Key, from.Id) <= 0 {
+				continue
+			}
+			matched = append(matched, r)
+		}
+	} else {
+		for i := len(rows) - 1; i >= 0; i-- {
+			if compare(rows[i], from.Key, from.Id) >= 0 {
+				continue
+			}
+			matched = append(matched, rows[i])
+		}
+	}
+	if len(matched) > limit+1 {
+		matched = matched[:limit+1]
+	}
+	return matched
+}
+
+func makeRows(n int) []row {
+	rows := make([]row, 0, n)
+	for i := 0; i < n; i++ {
+		// duplicated sort keys are broken by id
+		rows = append(rows, row{Key: fmt.Sprintf("k%02d", i/2), Id: fmt.Sprintf("%02d", i)})
+	}
+	return rows
+}
+
+func TestCodec(t *testing.T) {
+	codec := cursors.New([]byte("secret"))
+	s := codec.Encode(cursors.Cursor{Key: "k", Id: "1", Backward: true})
+	cursor, err := codec.Decode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor.Key != "k" || cursor.Id != "1" || !cursor.Backward {
+		t.Fatal("decoded cursor mismatched", cursor)
+	}
+	// tampered
+	p := []byte(s)
+	p[2] = p[2] ^ 1
+	if _, err = codec.Decode(string(p)); err == nil {
+		t.Fatal("tampered cursor must be invalid")
+	}
+	// signed by other secret
+	if _, err = cursors.New([]byte("other")).Decode(s); err == nil {
+		t.Fatal("cursor of other secret must be invalid")
+	}
+	if _, err = codec.Decode("!"); err == nil {
+		t.Fatal("malformed cursor must be invalid")
+	}
+	if _, err = codec.Decode(""); err == nil {
+		t.Fatal("empty cursor must be invalid")
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	codec := cursors.New([]byte("secret"))
+	rows := makeRows(10)
+	limit := 3
+	// forward
+	var from *cursors.Cursor
+	forwards := make([]row, 0, len(rows))
+	pages := 0
+	var last string
+	for {
+		page, prev := cursors.Paginate(codec, from, fetch(rows, from, limit), limit, rowKey)
+		pages++
+		if pages == 1 && prev != "" {
+			t.Fatal("first page must not have prev")
+		}
+		forwards = append(forwards, page.Items...)
+		if !page.HasMore() {
+			last = prev
+			break
+		}
+		cursor, err := codec.Decode(page.Cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		from = &cursor
+	}
+	if pages != 4 || len(forwards) != len(rows) {
+		t.Fatal("forward paging mismatched", pages, len(forwards))
+	}
+	for i := range rows {
+		if forwards[i] != rows[i] {
+			t.Fatal("forward paging order mismatched at", i)
+		}
+	}
+	// backward from last page
+	cursor, err := codec.Decode(last)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from = &cursor
+	backwards := make([]row, 0, len(rows))
+	for {
+		page, _ := cursors.Paginate(codec, from, fetch(rows, from, limit), limit, rowKey)
+		backwards = append(page.Items, backwards...)
+		if !page.HasMore() {
+			break
+		}
+		cursor, err = codec.Decode(page.Cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		from = &cursor
+	}
+	// last page holds one row
+	if len(backwards) != len(rows)-1 {
+		t.Fatal("backward paging mismatched", len(backwards))
+	}
+	for i := range backwards {
+		if backwards[i] != rows[i] {
+			t.Fatal("backward paging order mismatched at", i)
+		}
+	}
+}
+
+func TestPaginateBoundary(t *testing.T) {
+	codec := cursors.New([]byte("secret"))
+	// empty
+	page, prev := cursors.Paginate(codec, nil, fetch(nil, nil, 3), 3, rowKey)
+	if len(page.Items) != 0 || page.HasMore() || prev != "" {
+		t.Fatal("empty paging mismatched")
+	}
+	// exact multiple of limit
+	rows := makeRows(6)
+	page, _ = cursors.Paginate(codec, nil, fetch(rows, nil, 3), 3, rowKey)
+	if len(page.Items) != 3 || !page.HasMore() {
+		t.Fatal("first page mismatched")
+	}
+	cursor, _ := codec.Decode(page.Cursor)
+	page, prev = cursors.Paginate(codec, &cursor, fetch(rows, &cursor, 3), 3, rowKey)
+	if len(page.Items) != 3 || page.HasMore() || prev == "" {
+		t.Fatal("last page mismatched")
+	}
+	// past the end
+	end := cursors.Cursor{Key: rows[5].Key, Id: rows[5].Id}
+	page, _ = cursors.Paginate(codec, &end, fetch(rows, &end, 3), 3, rowKey)
+	if len(page.Items) != 0 || page.HasMore() {
+		t.Fatal("page past the end mismatched")
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"fmt"
+	"github.com/aacfactory/configures"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"strings"
+	"sync"
+)
+
+// Provider
+// provides a shared dependency, such as a connection pool which is used by components of many services.
+// Provide is called once, when the dependency is resolved for the first time,
+// options.Config is the node named by the provider under dependencies of config file,
+// and options.Dependencies resolves other dependencies, cycles are reported as errors.
+// Shutdown is called after all services are shutdown, in reverse order of providing.
+type Provider interface {
+	Name() (name string)
+	Provide(options Options) (v any, err error)
+	Shutdown(ctx context.Context)
+}
+
+// Dependencies
+// resolves shared dependencies by name, see Provider.
+type Dependencies interface {
+	Get(name string) (v any, err error)
+}
+
+// Dependency
+// resolves the dependency named name from options and asserts it as T.
+func Dependency[T any](options Options, name string) (v T, err error) {
+	if options.Dependencies == nil {
+		err = errors.Warning("fns: get dependency failed").WithMeta("dependency", name).WithCause(fmt.Errorf("there is no dependencies"))
+		return
+	}
+	value, getErr := options.Dependencies.Get(name)
+	if getErr != nil {
+		err = getErr
+		return
+	}
+	typed, ok := value.(T)
+	if !ok {
+		err = errors.Warning("fns: get dependency failed").WithMeta("dependency", name).WithCause(fmt.Errorf("dependency is %T", value))
+		return
+	}
+	v = typed
+	return
+}
+
+func newDependencies(id string, version versions.Version, log logs.Logger, config Config, providers []Provider) *dependencies {
+	named := make(map[string]Provider, len(providers))
+	for _, provider := range providers {
+		if provider == nil {
+			continue
+		}
+		named[provider.Name()] = provider
+	}
+	return &dependencies{
+		mutex:     sync.Mutex{},
+		id:        id,
+		version:   version,
+		log:       log,
+		config:    config,
+		providers: named,
+		values:    make(map[string]any),
+		order:     make([]Provider, 0, len(named)),
+	}
+}
+
+type dependencies struct {
+	mutex     sync.Mutex
+	id        string
+	version   versions.Version
+	log       logs.Logger
+	config    Config
+	providers map[string]Provider
+	values    map[string]any
+	order     []Provider
+}
+
+func (deps *dependencies) Get(name string) (v any, err error) {
+	deps.mutex.Lock()
+	v, err = deps.resolve(name, nil)
+	deps.mutex.Unlock()
+	return
+}
+
+// resolve
+// is called with mutex locked, providers resolve their dependencies by resolving which shares the lock.
+func (deps *dependencies) resolve(name string, chain []string) (v any, err error) {
+	if value, has := deps.values[name]; has {
+		v = value
+		return
+	}
+	provider, has := deps.providers[name]
+	if !has {
+		err = errors.Warning("fns: get dependency failed").WithMeta("dependency", name).WithCause(fmt.Errorf("dependency was not provided"))
+		return
+	}
+	for _, resolving := range chain {
+		if resolving == name {
+			err = errors.Warning("fns: get dependency failed").WithMeta("dependency", name).
+				WithCause(fmt.Errorf("dependencies are cyclic, %s -> %s", strings.Join(chain, " -> "), name))
+			return
+		}
+	}
+	var config configures.Config
+	config, err = deps.config.Get(name)
+	if err != nil {
+		err = errors.Warning("fns: get dependency failed").WithMeta("dependency", name).WithCause(err)
+		return
+	}
+	v, err = provider.Provide(Options{
+		Id:           deps.id,
+		Version:      deps.version,
+		Log:          deps.log.With("dependency", name),
+		Config:       config,
+		Dependencies: &resolving{deps: deps, chain: append(chain, name)},
+	})
+	if err != nil {
+		err = errors.Warning("fns: get dependency failed").WithMeta("dependency", name).WithCause(err)
+		return
+	}
+	deps.values[name] = v
+	deps.order = append(deps.order, provider)
+	return
+}
+
+func (deps *dependencies) Shutdown(ctx context.Context) {
+	deps.mutex.Lock()
+	defer deps.mutex.Unlock()
+	for i := len(deps.order) - 1; i > -1; i-- {
+		if ctx.Err() != nil {
+			if deps.log.WarnEnabled() {
+				deps.log.Warn().Cause(ctx.Err()).Message(fmt.Sprintf("fns: %d dependencies were not shutdown", i+1))
+			}
+			return
+		}
+		deps.order[i].Shutdown(ctx)
+	}
+}
+
+type resolving struct {
+	deps  *dependencies
+	chain []string
+}
+
+func (r *resolving) Get(name string) (v any, err error) {
+	v, err = r.deps.resolve(name, r.chain)
+	return
+}
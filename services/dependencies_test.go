@@ -0,0 +1,118 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"strings"
+	"testing"
+)
+
+type pool struct {
+	dsn string
+}
+
+type testProvider struct {
+	name     string
+	requires string
+	provided *int
+	recorder *shutdownRecorder
+}
+
+func (provider *testProvider) Name() string {
+	return provider.name
+}
+
+func (provider *testProvider) Provide(options services.Options) (v any, err error) {
+	*provider.provided++
+	if provider.requires != "" {
+		if _, err = options.Dependencies.Get(provider.requires); err != nil {
+			return
+		}
+	}
+	config, configErr := services.ConfigAs[struct {
+		Dsn string `json:"dsn"`
+	}](options)
+	if configErr != nil {
+		err = configErr
+		return
+	}
+	v = &pool{dsn: config.Dsn}
+	return
+}
+
+func (provider *testProvider) Shutdown(_ context.Context) {
+	provider.recorder.record(provider.name)
+}
+
+type poolComponent struct {
+	name string
+	pool *pool
+}
+
+func (component *poolComponent) Name() string {
+	return component.name
+}
+
+func (component *poolComponent) Construct(options services.Options) (err error) {
+	component.pool, err = services.Dependency[*pool](options, "pool")
+	return
+}
+
+func (component *poolComponent) Shutdown(_ context.Context) {}
+
+func TestDependencies(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	provided := 0
+	recorder := &shutdownRecorder{}
+	config := services.Config{"pool": []byte(`{"dsn":"postgres://"}`)}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil, services.WithDependencies(
+		config,
+		&testProvider{name: "metrics", provided: &provided, recorder: recorder},
+		&testProvider{name: "pool", requires: "metrics", provided: &provided, recorder: recorder},
+		&testProvider{name: "a", requires: "b", provided: &provided, recorder: recorder},
+		&testProvider{name: "b", requires: "a", provided: &provided, recorder: recorder},
+	))
+	components := []*poolComponent{{name: "users"}, {name: "orders"}}
+	for _, component := range components {
+		svc := &cycleService{Abstract: services.NewAbstract(component.name, false, component)}
+		if err := manager.Add(svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if components[0].pool == nil || components[0].pool != components[1].pool || components[0].pool.dsn != "postgres://" {
+		t.Fatal("dependency was not shared", components[0].pool, components[1].pool)
+	}
+	if provided != 2 {
+		t.Fatal("dependencies were provided more than once", provided)
+	}
+	_, err := services.Dependency[*pool](services.Options{Dependencies: manager.(*services.Manager).Dependencies()}, "a")
+	if err == nil || !strings.Contains(err.Error(), "cyclic") {
+		t.Fatal("cycle was not reported", err)
+	}
+	manager.Shutdown(context.TODO())
+	if strings.Join(recorder.names, ",") != "pool,metrics" {
+		t.Fatal("dependencies were not shutdown in reverse order", recorder.names)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/services/tracings"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	DefaultMaxRequestDepth = 32
+)
+
+var (
+	ErrRequestTooDeep = errors.New(http.StatusLoopDetected, "***LOOP DETECTED***", "fns: request is too deep")
+	maxRequestDepth   = new(atomic.Int64)
+)
+
+func init() {
+	maxRequestDepth.Store(DefaultMaxRequestDepth)
+}
+
+// SetMaxRequestDepth
+// sets the max number of internal hops of one transport request, it protects services from calling each other endlessly.
+// n less than 1 resets it to DefaultMaxRequestDepth.
+func SetMaxRequestDepth(n int) {
+	if n < 1 {
+		n = DefaultMaxRequestDepth
+	}
+	maxRequestDepth.Store(int64(n))
+}
+
+// CheckRequestDepth
+// returns ErrRequestTooDeep when depth of r is over the max,
+// the call chain is taken from the tracer of r, so it is complete only when tracing is enabled.
+func CheckRequestDepth(r Request) (err error) {
+	depth := r.Header().Depth()
+	if int64(depth) <= maxRequestDepth.Load() {
+		return
+	}
+	service, fn := r.Fn()
+	var chain []string
+	if trace, hasTrace := tracings.Load(r); hasTrace {
+		chain = trace.Chain()
+	}
+	chain = append(chain, fmt.Sprintf("%s.%s", bytex.ToString(service), bytex.ToString(fn)))
+	err = ErrRequestTooDeep.
+		WithMeta("endpoint", bytex.ToString(service)).WithMeta("fn", bytex.ToString(fn)).
+		WithMeta("depth", strconv.Itoa(depth)).
+		WithMeta("chain", strings.Join(chain, " -> "))
+	return
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"net/http"
+	"testing"
+)
+
+type cycleFn struct {
+	endpoints services.Endpoints
+	name      []byte
+	next      []byte
+}
+
+func (fn *cycleFn) Name() string {
+	return string(fn.name)
+}
+
+func (fn *cycleFn) Internal() bool {
+	return true
+}
+
+func (fn *cycleFn) Readonly() bool {
+	return true
+}
+
+func (fn *cycleFn) Handle(r services.Request) (v any, err error) {
+	// a calls b, b calls a
+	_, err = fn.endpoints.Request(r, fn.next, fn.next, nil)
+	return
+}
+
+type cycleService struct {
+	services.Abstract
+}
+
+func TestCheckRequestDepth(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	services.SetMaxRequestDepth(8)
+	defer services.SetMaxRequestDepth(0)
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	for _, pair := range [][2]string{{"a", "b"}, {"b", "a"}} {
+		svc := &cycleService{Abstract: services.NewAbstract(pair[0], true)}
+		svc.AddFunction(&cycleFn{endpoints: manager, name: []byte(pair[0]), next: []byte(pair[1])})
+		if err := manager.Add(svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := manager.Request(context.TODO(), []byte("a"), []byte("a"), nil)
+	if err == nil {
+		t.Fatal("cycle was not stopped")
+	}
+	codeErr := errors.Wrap(err)
+	if codeErr.Code() != http.StatusLoopDetected {
+		t.Fatal("unexpected error", codeErr)
+	}
+	t.Log(codeErr)
+}
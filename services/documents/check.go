@@ -0,0 +1,225 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package documents
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Check
+// checks json value against element, refs are resolved by elements of endpoint.
+// it returns problems of mismatches, such as missing required property, unknown property and kind mismatch,
+// it is used to find drift between documents and payloads, so it is not fast.
+func (endpoint *Endpoint) Check(element Element, value []byte) (problems []string) {
+	if !element.Exist() {
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		problems = append(problems, fmt.Sprintf("$: invalid json, %s", err.Error()))
+		return
+	}
+	problems = endpoint.check("$", element, v, problems, 0)
+	return
+}
+
+func (endpoint *Endpoint) lookup(ref Element) (element Element, has bool) {
+	key := ref.Key()
+	for _, e := range endpoint.Elements {
+		if e.Key() == key {
+			element = e
+			has = true
+			return
+		}
+	}
+	return
+}
+
+// PropertyType
+// returns type of property of element, such as string, integer, number and boolean, refs are resolved by elements of endpoint.
+// it is empty when element has no such property.
+func (endpoint *Endpoint) PropertyType(element Element, name string) (typ string) {
+	if element.IsRef() {
+		target, has := endpoint.lookup(element)
+		if !has {
+			return
+		}
+		element = target
+	}
+	property, has := element.Properties.Get(name)
+	if !has {
+		return
+	}
+	prop := property.Element
+	if prop.IsRef() {
+		target, hasTarget := endpoint.lookup(prop)
+		if !hasTarget {
+			return
+		}
+		prop = target
+	}
+	typ = prop.Type
+	return
+}
+
+func (endpoint *Endpoint) check(path string, element Element, value any, problems []string, depth int) []string {
+	if depth > 32 {
+		return problems
+	}
+	if element.IsRef() {
+		target, has := endpoint.lookup(element)
+		if !has {
+			return problems
+		}
+		element = target
+	}
+	if !element.Exist() || element.IsAny() || (element.IsBuiltin() && element.Name == "unknown") {
+		return problems
+	}
+	if value == nil {
+		return problems
+	}
+	switch element.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: expected string, got %s", path, kindOf(value)))
+		}
+		if len(element.Enums) > 0 {
+			matched := false
+			for _, enum := range element.Enums {
+				if enum == s {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				problems = append(problems, fmt.Sprintf("%s: %q is not in enums [%s]", path, s, strings.Join(element.Enums, ", ")))
+			}
+		}
+		break
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: expected integer, got %s", path, kindOf(value)))
+		}
+		if _, err := n.Int64(); err != nil {
+			if _, uErr := strconv.ParseUint(n.String(), 10, 64); uErr != nil {
+				problems = append(problems, fmt.Sprintf("%s: expected integer, got %s", path, n.String()))
+			}
+		}
+		break
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return append(problems, fmt.Sprintf("%s: expected number, got %s", path, kindOf(value)))
+		}
+		break
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return append(problems, fmt.Sprintf("%s: expected boolean, got %s", path, kindOf(value)))
+		}
+		break
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: expected array, got %s", path, kindOf(value)))
+		}
+		item, hasItem := element.GetItem()
+		if !hasItem {
+			break
+		}
+		for i, v := range items {
+			problems = endpoint.check(fmt.Sprintf("%s[%d]", path, i), item, v, problems, depth+1)
+		}
+		break
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: expected object, got %s", path, kindOf(value)))
+		}
+		if element.IsAdditional() {
+			if element.Key() == JsonRaw().Key() {
+				break
+			}
+			item, hasItem := element.GetItem()
+			if !hasItem {
+				break
+			}
+			keys := make([]string, 0, len(obj))
+			for k := range obj {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				problems = endpoint.check(fmt.Sprintf("%s.%s", path, k), item, obj[k], problems, depth+1)
+			}
+			break
+		}
+		if element.Key() == Empty().Key() {
+			break
+		}
+		for _, property := range element.Properties {
+			v, has := obj[property.Name]
+			if !has {
+				if property.Element.Required {
+					problems = append(problems, fmt.Sprintf("%s.%s: required but missing", path, property.Name))
+				}
+				continue
+			}
+			problems = endpoint.check(fmt.Sprintf("%s.%s", path, property.Name), property.Element, v, problems, depth+1)
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			if _, has := element.Properties.Get(k); !has {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			problems = append(problems, fmt.Sprintf("%s.%s: unknown property", path, k))
+		}
+		break
+	default:
+		break
+	}
+	return problems
+}
+
+func kindOf(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return "null"
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package documents_test
+
+import (
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/services/documents"
+	"testing"
+)
+
+func TestEndpoint_Check(t *testing.T) {
+	user := documents.Struct("users", "User").
+		AddProperty("id", documents.Int64().AsRequired()).
+		AddProperty("name", documents.String()).
+		AddProperty("role", documents.String().AddEnum("admin", "member")).
+		AddProperty("tags", documents.Array(documents.String()))
+	endpoint := documents.New("users", "", "", versions.Origin())
+	endpoint.AddFn(documents.NewFn("get").SetResult(user))
+	result := endpoint.Functions[0].Result
+	if problems := endpoint.Check(result, []byte(`{"id":1,"name":"a","role":"admin","tags":["x"]}`)); len(problems) > 0 {
+		t.Fatal("matched payload has problems", problems)
+	}
+	problems := endpoint.Check(result, []byte(`{"name":1,"role":"guest","tags":[1],"age":3}`))
+	expects := []string{
+		"$.id: required but missing",
+		"$.name: expected string, got number",
+		"$.role: \"guest\" is not in enums [admin, member]",
+		"$.tags[0]: expected string, got number",
+		"$.age: unknown property",
+	}
+	if len(problems) != len(expects) {
+		t.Fatal("problems mismatched", problems)
+	}
+	for i, expect := range expects {
+		if problems[i] != expect {
+			t.Fatal("problem mismatched, want", expect, "got", problems[i])
+		}
+	}
+}
+
+func TestEndpoint_Prevalidate(t *testing.T) {
+	user := documents.Struct("users", "User").
+		AddProperty("id", documents.Int64().AsRequired().SetValidation(documents.NewValidation("id is required"))).
+		AddProperty("name", documents.String()).
+		AddProperty("timeout", documents.Duration()).
+		AddProperty("tags", documents.Array(documents.String()))
+	endpoint := documents.New("users", "", "", versions.Origin())
+	endpoint.AddFn(documents.NewFn("create").SetParam(user))
+	param := endpoint.Functions[0].Param
+	if violations := endpoint.Prevalidate(param, []byte(`{"id":1,"timeout":1500,"age":3}`), true); len(violations) > 0 {
+		t.Fatal("matched payload has violations", violations)
+	}
+	if violations := endpoint.Prevalidate(param, []byte(`{"name":"a"}`), false); len(violations) > 0 {
+		t.Fatal("required was checked without validation", violations)
+	}
+	violations := endpoint.Prevalidate(param, []byte(`{"id":null,"tags":[1]}`), true)
+	if len(violations) != 2 {
+		t.Fatal("violations mismatched", violations)
+	}
+	if violations[0].Key != "id" || violations[0].Message != "id is required" {
+		t.Fatal("unexpected", violations[0])
+	}
+	if violations[1].Key != "tags[0]" || violations[1].Expected != "string" {
+		t.Fatal("unexpected", violations[1])
+	}
+}
@@ -19,6 +19,7 @@ package documents
 
 import (
 	"fmt"
+	"github.com/aacfactory/fns/commons/jsons"
 	"reflect"
 	"sort"
 )
@@ -53,6 +54,12 @@ func Bytes() Element {
 	return NewElement("_", "bytes", "string", "byte", "Bytes", "Base64 string")
 }
 
+// Binary
+// is the element of services.File, exporters should document it as application/octet-stream response.
+func Binary() Element {
+	return NewElement("_", "binary", "string", "binary", "Binary", "Binary content")
+}
+
 func Bool() Element {
 	return NewElement("_", "bool", "boolean", "", "Bool", "Bool")
 }
@@ -109,12 +116,41 @@ func Time() Element {
 	return NewElement("_", "time", "string", "", "Time", "Time format, such as 15:04:05")
 }
 
+// Duration
+// is the element of time.Duration, it follows the duration format of jsons.
 func Duration() Element {
-	return NewElement("_", "duration", "integer", "int64", "Duration", "Nanosecond")
+	format := jsons.CurrentDurationFormat()
+	if format.Unit() == 0 {
+		return NewElement("_", "duration", "string", "duration", "Duration", "Duration string, such as 1h30m, 1.5s or 300ms")
+	}
+	return NewElement("_", "duration", "number", "", "Duration", fmt.Sprintf("Number of %ss, duration string such as 1h30m is accepted too", format))
 }
 
+// DateTime
+// is the element of time.Time, it follows the time format of jsons.
 func DateTime() Element {
-	return NewElement("_", "datetime", "string", "2006-01-02T15:04:05Z07:00", "Datetime", "RFC3339 format, such as 2022-01-10T19:13:07+08:00")
+	return DateTimeOf(string(jsons.CurrentTimeFormat()))
+}
+
+// DateTimeOf
+// is the element of time.Time field which has timeFormat tag.
+func DateTimeOf(format string) Element {
+	switch jsons.TimeFormat(format) {
+	case jsons.UnixTimeFormat:
+		return NewElement("_", "datetime", "integer", "int64", "Datetime", "Unix seconds, such as 1641813187")
+	case jsons.UnixMilliTimeFormat:
+		return NewElement("_", "datetime", "integer", "int64", "Datetime", "Unix milliseconds, such as 1641813187000")
+	default:
+		return NewElement("_", "datetime", "string", "2006-01-02T15:04:05Z07:00", "Datetime", "RFC3339 format, such as 2022-01-10T19:13:07+08:00")
+	}
+}
+
+func Decimal() Element {
+	return NewElement("_", "decimal", "string", "decimal", "Decimal", "Fixed-point decimal string, such as 19.99")
+}
+
+func UUID() Element {
+	return NewElement("_", "uuid", "string", "uuid", "UUID", "UUID, such as 6ba7b810-9dad-11d1-80b4-00c04fd430c8")
 }
 
 func Any() Element {
@@ -211,6 +247,8 @@ type Element struct {
 	Properties  Properties `json:"properties,omitempty" avro:"properties"`
 	Additional  bool       `json:"additional,omitempty" avro:"additional"`
 	Deprecated  bool       `json:"deprecated,omitempty" avro:"deprecated"`
+	Default     string     `json:"default,omitempty" avro:"default"`
+	Nullable    bool       `json:"nullable,omitempty" avro:"nullable"`
 }
 
 func (element Element) Exist() bool {
@@ -235,6 +273,20 @@ func (element Element) AsRequired() Element {
 	return element
 }
 
+// AsNullable
+// value of element can be null, such as services.Optional.
+func (element Element) AsNullable() Element {
+	element.Nullable = true
+	return element
+}
+
+// SetDefault
+// value is json literal of default, such as `20` or `"name"`.
+func (element Element) SetDefault(value string) Element {
+	element.Default = value
+	return element
+}
+
 func (element Element) AsDeprecated() Element {
 	element.Deprecated = true
 	return element
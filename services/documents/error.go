@@ -144,3 +144,26 @@ func (pp Errors) Add(e Error) Errors {
 	sort.Sort(n)
 	return n
 }
+
+var (
+	// ErrorContentTypes
+	// are media types of error responses, openapi exporters should document both,
+	// application/problem+json is returned when request accepts it, see Problem.
+	ErrorContentTypes = []string{"application/json", "application/problem+json"}
+)
+
+// Problem
+// returns element of RFC 7807 problem details which is rendered by transports.Problem.
+func Problem() Element {
+	v := Struct("github.com/aacfactory/fns/transports", "Problem").
+		SetTitle("Problem").
+		SetDescription("RFC 7807 problem details")
+	v = v.AddProperty("type", String().SetTitle("Type").SetDescription("URI reference of problem type").AsRequired())
+	v = v.AddProperty("title", String().SetTitle("Title").SetDescription("Short summary of problem type").AsRequired())
+	v = v.AddProperty("status", Int().SetTitle("Status").SetDescription("HTTP status code").AsRequired())
+	v = v.AddProperty("detail", String().SetTitle("Detail").SetDescription("Message of error"))
+	v = v.AddProperty("instance", String().SetTitle("Instance").SetDescription("Path of request"))
+	v = v.AddProperty("id", String().SetTitle("Id").SetDescription("Id of error"))
+	v = v.AddProperty("meta", Map(String()).SetTitle("Meta").SetDescription("Meta of error"))
+	return v
+}
@@ -48,6 +48,8 @@ type Fn struct {
 	Param         Element `json:"argument,omitempty" avro:"param"`
 	Result        Element `json:"result,omitempty" avro:"result"`
 	Errors        Errors  `json:"errors,omitempty" avro:"errors"`
+	Since         string  `json:"since,omitempty" avro:"since"`
+	Removed       string  `json:"removed,omitempty" avro:"removed"`
 }
 
 func (fn Fn) SetInfo(title string, description string) Fn {
@@ -81,6 +83,16 @@ func (fn Fn) SetPermission(permission bool) Fn {
 	return fn
 }
 
+func (fn Fn) SetSince(version string) Fn {
+	fn.Since = version
+	return fn
+}
+
+func (fn Fn) SetRemoved(version string) Fn {
+	fn.Removed = version
+	return fn
+}
+
 func (fn Fn) SetParam(param Element) Fn {
 	fn.Param = param
 	return fn
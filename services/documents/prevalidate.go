@@ -0,0 +1,166 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package documents
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Violation
+// is one mismatch found by Prevalidate.
+// Key is the dotted json path of the field, such as user.name, it is empty for the root value.
+// Expected is the expected type of a mismatched value, it is empty when a required property is missing,
+// and Message is the validation message of the missing property.
+type Violation struct {
+	Key      string
+	Expected string
+	Message  string
+}
+
+// Prevalidate
+// is the fast subset of Check which is used before dispatching,
+// it reports mismatched types, and missing required properties when required is true.
+// unknown properties are ignored as decoders do, and builtins decoded from more than one kind,
+// such as duration and decimal, are not checked.
+func (endpoint *Endpoint) Prevalidate(element Element, value []byte, required bool) (violations []Violation) {
+	if !element.Exist() {
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		violations = append(violations, Violation{Expected: "json"})
+		return
+	}
+	violations = endpoint.prevalidate("", element, v, required, violations, 0)
+	return
+}
+
+func (endpoint *Endpoint) prevalidate(key string, element Element, value any, required bool, violations []Violation, depth int) []Violation {
+	if depth > 32 {
+		return violations
+	}
+	if element.IsRef() {
+		target, has := endpoint.lookup(element)
+		if !has {
+			return violations
+		}
+		element = target
+	}
+	if !element.Exist() || element.IsAny() || value == nil {
+		return violations
+	}
+	if element.IsBuiltin() {
+		switch element.Name {
+		case "unknown", "duration", "decimal", "datetime":
+			return violations
+		default:
+			break
+		}
+	}
+	mismatched := func() []Violation {
+		return append(violations, Violation{Key: key, Expected: element.Type})
+	}
+	switch element.Type {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return mismatched()
+		}
+		break
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return mismatched()
+		}
+		if _, err := n.Int64(); err != nil {
+			if _, uErr := strconv.ParseUint(n.String(), 10, 64); uErr != nil {
+				return mismatched()
+			}
+		}
+		break
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return mismatched()
+		}
+		break
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatched()
+		}
+		break
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return mismatched()
+		}
+		item, hasItem := element.GetItem()
+		if !hasItem {
+			break
+		}
+		for i, v := range items {
+			violations = endpoint.prevalidate(fmt.Sprintf("%s[%d]", key, i), item, v, required, violations, depth+1)
+		}
+		break
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return mismatched()
+		}
+		if element.IsAdditional() {
+			if element.Key() == JsonRaw().Key() {
+				break
+			}
+			item, hasItem := element.GetItem()
+			if !hasItem {
+				break
+			}
+			for k, v := range obj {
+				violations = endpoint.prevalidate(joinViolationKey(key, k), item, v, required, violations, depth+1)
+			}
+			break
+		}
+		for _, property := range element.Properties {
+			v := obj[property.Name]
+			if v == nil {
+				if required && property.Element.Required {
+					violations = append(violations, Violation{
+						Key:     joinViolationKey(key, property.Name),
+						Message: property.Element.Validation.Name,
+					})
+				}
+				continue
+			}
+			violations = endpoint.prevalidate(joinViolationKey(key, property.Name), property.Element, v, required, violations, depth+1)
+		}
+		break
+	default:
+		break
+	}
+	return violations
+}
+
+func joinViolationKey(key string, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "." + name
+}
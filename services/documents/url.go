@@ -0,0 +1,105 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package documents
+
+import (
+	"bytes"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/ipx"
+	"github.com/aacfactory/fns/transports"
+	"os"
+	"strings"
+)
+
+const (
+	PublicHostEnv = "FNS-PUBLIC-HOST"
+)
+
+// ServersConfig
+// is the servers part of documents handler config.
+//
+//	documents:
+//	  publicUrl: "https://api.example.com"
+type ServersConfig struct {
+	PublicURL   string `json:"publicUrl,omitempty" yaml:"publicUrl,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Server
+// is the servers entry of openapi document.
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Servers
+// builds servers of openapi document for the request, documents handlers should use it rather than host of listener.
+// url of server is PublicURL with publicUrl of config.
+func Servers(r transports.Request, config ServersConfig) (servers []Server) {
+	servers = []Server{
+		{
+			URL:         PublicURL(r, config.PublicURL),
+			Description: strings.TrimSpace(config.Description),
+		},
+	}
+	return
+}
+
+// PublicURL
+// returns url of servers entry of openapi document, it is used by Servers, precedence order is
+// 1. publicURL, such as documents.publicUrl of handler config, it is used as it is.
+// 2. X-Forwarded-Host and X-Forwarded-Proto of reverse proxy, the first value is used when proxies are chained.
+// 3. FNS-PUBLIC-HOST env.
+// 4. Host of request.
+// 5. global unicast ip of hostname, it is often useless outside of container.
+// scheme is https when X-Forwarded-Proto is absent and request is tls, otherwise http.
+// base path of transport (see transports.BasePath) is appended to host when url is not from publicURL.
+func PublicURL(r transports.Request, publicURL string) (url string) {
+	if publicURL = strings.TrimSpace(publicURL); publicURL != "" {
+		url = strings.TrimSuffix(publicURL, "/")
+		return
+	}
+	scheme := "http"
+	if r.TLS() {
+		scheme = "https"
+	}
+	if proto := firstForwardedValue(r.Header().Get(transports.XForwardedProtoHeaderName)); len(proto) > 0 {
+		scheme = strings.ToLower(bytex.ToString(proto))
+	}
+	host := bytex.ToString(firstForwardedValue(r.Header().Get(transports.XForwardedHostHeaderName)))
+	if host == "" {
+		host = strings.TrimSpace(os.Getenv(PublicHostEnv))
+	}
+	if host == "" {
+		host = bytex.ToString(r.Host())
+	}
+	if host == "" {
+		if ip := ipx.GetGlobalUniCastIpFromHostname(); ip != nil {
+			host = ip.String()
+		}
+	}
+	url = scheme + "://" + host + bytex.ToString(transports.BasePath(r))
+	return
+}
+
+func firstForwardedValue(p []byte) []byte {
+	if idx := bytes.IndexByte(p, ','); idx > -1 {
+		p = p[:idx]
+	}
+	return bytes.TrimSpace(p)
+}
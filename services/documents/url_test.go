@@ -0,0 +1,118 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package documents_test
+
+import (
+	"bufio"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/services/documents"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/fns/transports/fast"
+	"github.com/valyala/fasthttp"
+	"net"
+	"testing"
+)
+
+type resultWriter struct {
+	context.Context
+	*transports.ResultResponseWriter
+}
+
+func (w *resultWriter) SetCookie(_ *transports.Cookie) {}
+
+func (w *resultWriter) Hijack(_ func(ctx context.Context, conn net.Conn, rw *bufio.ReadWriter) (err error)) (async bool, err error) {
+	return
+}
+
+func (w *resultWriter) Hijacked() bool {
+	return false
+}
+
+type publicURLHandler struct {
+	url string
+}
+
+func (handler *publicURLHandler) Name() string {
+	return "documents"
+}
+
+func (handler *publicURLHandler) Construct(_ transports.MuxHandlerOptions) error {
+	return nil
+}
+
+func (handler *publicURLHandler) Match(_ context.Context, _ []byte, path []byte, _ transports.Header) bool {
+	return string(path) == "/documents"
+}
+
+func (handler *publicURLHandler) Handle(w transports.ResponseWriter, r transports.Request) {
+	handler.url = documents.PublicURL(r, "")
+	w.Succeed(handler.url)
+}
+
+func TestPublicURL(t *testing.T) {
+	rc := &fasthttp.RequestCtx{}
+	rc.Request.Header.SetHost("10.0.0.8:8080")
+	r := &fast.Request{Context: &fast.Context{RequestCtx: rc}}
+	if url := documents.PublicURL(r, ""); url != "http://10.0.0.8:8080" {
+		t.Fatal("host of request:", url)
+	}
+	rc.Request.Header.Set("X-Forwarded-Host", "api.example.com, 10.0.0.1")
+	rc.Request.Header.Set("X-Forwarded-Proto", "https")
+	if url := documents.PublicURL(r, ""); url != "https://api.example.com" {
+		t.Fatal("forwarded:", url)
+	}
+	if url := documents.PublicURL(r, "https://example.com/fns/"); url != "https://example.com/fns" {
+		t.Fatal("public url:", url)
+	}
+}
+
+func TestPublicURL_BasePath(t *testing.T) {
+	handler := &publicURLHandler{}
+	mux := transports.NewMux()
+	mux.SetBasePath("api/")
+	mux.Add(handler)
+	rc := &fasthttp.RequestCtx{}
+	rc.Request.Header.SetHost("10.0.0.8:8080")
+	rc.Request.Header.SetMethod("GET")
+	rc.Request.SetRequestURI("/api/documents")
+	r := &fast.Request{Context: &fast.Context{RequestCtx: rc}}
+	w := &resultWriter{
+		Context:              r,
+		ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+	}
+	mux.Handle(w, r)
+	if handler.url != "http://10.0.0.8:8080/api" {
+		t.Fatal("base path was not in url:", handler.url, w.Status())
+	}
+}
+
+func TestServers(t *testing.T) {
+	rc := &fasthttp.RequestCtx{}
+	rc.Request.Header.SetHost("10.0.0.8:8080")
+	rc.Request.Header.Set("X-Forwarded-Host", "api.example.com")
+	rc.Request.Header.Set("X-Forwarded-Proto", "https")
+	r := &fast.Request{Context: &fast.Context{RequestCtx: rc}}
+	servers := documents.Servers(r, documents.ServersConfig{})
+	if len(servers) != 1 || servers[0].URL != "https://api.example.com" {
+		t.Fatal("forwarded:", servers)
+	}
+	servers = documents.Servers(r, documents.ServersConfig{PublicURL: "https://example.com/fns", Description: "prod"})
+	if len(servers) != 1 || servers[0].URL != "https://example.com/fns" || servers[0].Description != "prod" {
+		t.Fatal("public url:", servers)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"github.com/aacfactory/errors"
+	"net/http"
+)
+
+const (
+	badRequestErrorName      = "***BAD REQUEST***"
+	unauthorizedErrorName    = "***UNAUTHORIZED***"
+	forbiddenErrorName       = "***FORBIDDEN***"
+	notFoundErrorName        = "***NOT FOUND***"
+	notAcceptableErrorName   = "***NOT ACCEPTABLE***"
+	timeoutErrorName         = "***TIMEOUT***"
+	conflictErrorName        = "***CONFLICT***"
+	tooManyRequestsErrorName = "***TOO MANY REQUEST***"
+	serviceErrorName         = "***SERVICE EXECUTE FAILED***"
+	notImplementedErrorName  = "***SERVICE NOT IMPLEMENTED***"
+	unavailableErrorName     = "***SERVICE UNAVAILABLE***"
+	warningErrorName         = "***WARNING***"
+)
+
+// ErrorStatus
+// is a status of error responses which are declared in openapi documents, Name is the name of error which has the status.
+type ErrorStatus struct {
+	Status int
+	Name   string
+}
+
+// ErrorStatuses
+// are statuses of errors made by constructors in this file, sorted by status.
+// the code of error is used as status of response, so errors made by them are always mapped to declared responses.
+//
+//	| status | constructor     | name                          |
+//	|--------|-----------------|-------------------------------|
+//	| 400    | BadRequest      | ***BAD REQUEST***             |
+//	| 401    | Unauthorized    | ***UNAUTHORIZED***            |
+//	| 403    | Forbidden       | ***FORBIDDEN***               |
+//	| 404    | NotFound        | ***NOT FOUND***               |
+//	| 406    | NotAcceptable   | ***NOT ACCEPTABLE***          |
+//	| 408    | Timeout         | ***TIMEOUT***                 |
+//	| 409    | Conflict        | ***CONFLICT***                |
+//	| 429    | TooManyRequests | ***TOO MANY REQUEST***        |
+//	| 500    | ServiceError    | ***SERVICE EXECUTE FAILED***  |
+//	| 501    | NotImplemented  | ***SERVICE NOT IMPLEMENTED*** |
+//	| 503    | Unavailable     | ***SERVICE UNAVAILABLE***     |
+//	| 555    | Warning         | ***WARNING***                 |
+var ErrorStatuses = []ErrorStatus{
+	{Status: http.StatusBadRequest, Name: badRequestErrorName},
+	{Status: http.StatusUnauthorized, Name: unauthorizedErrorName},
+	{Status: http.StatusForbidden, Name: forbiddenErrorName},
+	{Status: http.StatusNotFound, Name: notFoundErrorName},
+	{Status: http.StatusNotAcceptable, Name: notAcceptableErrorName},
+	{Status: http.StatusRequestTimeout, Name: timeoutErrorName},
+	{Status: http.StatusConflict, Name: conflictErrorName},
+	{Status: http.StatusTooManyRequests, Name: tooManyRequestsErrorName},
+	{Status: http.StatusInternalServerError, Name: serviceErrorName},
+	{Status: http.StatusNotImplemented, Name: notImplementedErrorName},
+	{Status: http.StatusServiceUnavailable, Name: unavailableErrorName},
+	{Status: 555, Name: warningErrorName},
+}
+
+// newError
+// skips itself and the constructor, so stacktrace of error is at the caller of constructor.
+func newError(status int, name string, message string) errors.CodeError {
+	return errors.NewWithDepth(status, name, message, 4)
+}
+
+// BadRequest
+// is 400, such as invalid params.
+func BadRequest(message string) errors.CodeError {
+	return newError(http.StatusBadRequest, badRequestErrorName, message)
+}
+
+// Unauthorized
+// is 401, such as missing or invalid token.
+func Unauthorized(message string) errors.CodeError {
+	return newError(http.StatusUnauthorized, unauthorizedErrorName, message)
+}
+
+// Forbidden
+// is 403, such as missing permission.
+func Forbidden(message string) errors.CodeError {
+	return newError(http.StatusForbidden, forbiddenErrorName, message)
+}
+
+// NotFound
+// is 404.
+func NotFound(message string) errors.CodeError {
+	return newError(http.StatusNotFound, notFoundErrorName, message)
+}
+
+// NotAcceptable
+// is 406, such as internal fns requested externally.
+func NotAcceptable(message string) errors.CodeError {
+	return newError(http.StatusNotAcceptable, notAcceptableErrorName, message)
+}
+
+// Timeout
+// is 408.
+func Timeout(message string) errors.CodeError {
+	return newError(http.StatusRequestTimeout, timeoutErrorName, message)
+}
+
+// Conflict
+// is 409, such as duplicated entities or stale versions.
+func Conflict(message string) errors.CodeError {
+	return newError(http.StatusConflict, conflictErrorName, message)
+}
+
+// TooManyRequests
+// is 429.
+func TooManyRequests(message string) errors.CodeError {
+	return newError(http.StatusTooManyRequests, tooManyRequestsErrorName, message)
+}
+
+// ServiceError
+// is 500, it is for unexpected failures.
+func ServiceError(message string) errors.CodeError {
+	return newError(http.StatusInternalServerError, serviceErrorName, message)
+}
+
+// NotImplemented
+// is 501.
+func NotImplemented(message string) errors.CodeError {
+	return newError(http.StatusNotImplemented, notImplementedErrorName, message)
+}
+
+// Unavailable
+// is 503, such as dependencies are down.
+func Unavailable(message string) errors.CodeError {
+	return newError(http.StatusServiceUnavailable, unavailableErrorName, message)
+}
+
+// Warning
+// is 555, it is for expected failures of business which are not described by other statuses.
+func Warning(message string) errors.CodeError {
+	return newError(555, warningErrorName, message)
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/services"
+	"strings"
+	"testing"
+)
+
+func TestErrorStatuses(t *testing.T) {
+	constructors := []func(message string) errors.CodeError{
+		services.BadRequest, services.Unauthorized, services.Forbidden, services.NotFound,
+		services.NotAcceptable, services.Timeout, services.Conflict, services.TooManyRequests,
+		services.ServiceError, services.NotImplemented, services.Unavailable, services.Warning,
+	}
+	if len(constructors) != len(services.ErrorStatuses) {
+		t.Fatal("constructors are not matched with statuses")
+	}
+	for i, constructor := range constructors {
+		err := constructor("failed")
+		status := services.ErrorStatuses[i]
+		if err.Code() != status.Status || err.Name() != status.Name {
+			t.Fatal("unexpected error", status.Status, err.Code(), err.Name())
+		}
+		if _, file, _ := err.Stacktrace(); !strings.HasSuffix(file, "errors_test.go") {
+			t.Fatal("stacktrace is not at caller", file)
+		}
+	}
+	// names are the same as errors package
+	if services.BadRequest("").Name() != errors.BadRequest("").Name() || services.Warning("").Name() != errors.Warning("").Name() {
+		t.Fatal("names are not matched with errors package")
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package features
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/authorizations"
+)
+
+var (
+	ErrFeatureDisabled = errors.NotFound("fns: feature is disabled")
+)
+
+// Enabled
+// asks features service whether the feature is enabled for the request,
+// the feature is disabled when features service is not deployed.
+func Enabled(ctx context.Context, name string) (ok bool, err error) {
+	rt := runtime.Load(ctx)
+	if _, has := rt.Endpoints().Get(ctx, endpointName); !has {
+		return
+	}
+	param := EnabledParam{
+		Name: name,
+	}
+	if authorization, has, _ := authorizations.Load(ctx); has && authorization.Exist() {
+		param.Account = authorization.Account
+	}
+	response, handleErr := rt.Endpoints().Request(ctx, endpointName, enabledFnName, param)
+	if handleErr != nil {
+		err = handleErr
+		return
+	}
+	ok, err = services.ValueOfResponse[bool](response)
+	if err != nil {
+		err = errors.Warning("features: check enabled failed").WithCause(err)
+		return
+	}
+	return
+}
+
+// EnforceContext
+// returns ErrFeatureDisabled when the feature is not enabled for the request.
+func EnforceContext(ctx context.Context, name string) (err error) {
+	ok, enabledErr := Enabled(ctx, name)
+	if enabledErr != nil {
+		err = errors.Warning("features: enforce failed").WithMeta("feature", name).WithCause(enabledErr)
+		return
+	}
+	if !ok {
+		err = ErrFeatureDisabled.WithMeta("feature", name)
+		return
+	}
+	return
+}
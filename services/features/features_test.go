@@ -0,0 +1,93 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package features_test
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/commons"
+	"github.com/aacfactory/fns/services/features"
+	"github.com/aacfactory/fns/shareds"
+	"github.com/aacfactory/json"
+	"testing"
+)
+
+func setup(t *testing.T, svc ...services.Service) (ctx context.Context) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	config := services.Config{
+		"features": json.RawMessage(`{"config":{"flags":{"on":{"enabled":true},"off":{"enabled":false}}}}`),
+	}
+	manager := services.New("id", versions.Origin(), log, config, nil)
+	for _, s := range svc {
+		if err := manager.Add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rt := runtime.New("id", "name", versions.Origin(), nil, log, nil, manager, nil, shared)
+	ctx = runtime.With(context.TODO(), rt)
+	logs.With(ctx, log)
+	return
+}
+
+func handle(ctx context.Context, feature string) (v string, err error) {
+	fn := commons.NewFn[services.Empty, string]("checkout", func(ctx context.Context, param services.Empty) (v string, err error) {
+		v = "handled"
+		return
+	}, commons.Feature(feature))
+	result, handleErr := fn.Handle(services.NewRequest(ctx, []byte("orders"), []byte("checkout"), services.Empty{}))
+	if handleErr != nil {
+		err = handleErr
+		return
+	}
+	v = result.(string)
+	return
+}
+
+func TestFeature(t *testing.T) {
+	ctx := setup(t, features.New(features.ConfigFlags()))
+	v, err := handle(ctx, "on")
+	if err != nil || v != "handled" {
+		t.Fatal("enabled feature must be served", v, err)
+	}
+	for _, name := range []string{"off", "absent"} {
+		if _, err = handle(ctx, name); err == nil || !errors.Wrap(err).Contains(features.ErrFeatureDisabled) {
+			t.Fatal("disabled feature must not be served", name, err)
+		}
+	}
+}
+
+func TestFeature_WithoutService(t *testing.T) {
+	ctx := setup(t)
+	if ok, err := features.Enabled(ctx, "on"); ok || err != nil {
+		t.Fatal("feature must be disabled when features service is absent", ok, err)
+	}
+	if _, err := handle(ctx, "on"); err == nil || !errors.Wrap(err).Contains(features.ErrFeatureDisabled) {
+		t.Fatal("fn must not be served when features service is absent", err)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package features
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/authorizations"
+)
+
+type EnabledParam struct {
+	Name    string            `json:"name" avro:"name"`
+	Account authorizations.Id `json:"account" avro:"account"`
+}
+
+// Flags
+// decides whether a feature is enabled, account is present when request is authorized, so flags can target users.
+type Flags interface {
+	services.Component
+	Enabled(ctx context.Context, param EnabledParam) (ok bool, err error)
+}
+
+type Flag struct {
+	Enabled  bool     `json:"enabled" yaml:"enabled,omitempty"`
+	Accounts []string `json:"accounts" yaml:"accounts,omitempty"`
+}
+
+type ConfigFlagsConfig struct {
+	Flags map[string]Flag `json:"flags" yaml:"flags,omitempty"`
+}
+
+// ConfigFlags
+// reads flags from config, a flag is enabled for all, or only for listed accounts.
+//
+//	features:
+//	  config:
+//	    flags:
+//	      new-checkout:
+//	        enabled: false
+//	        accounts: ["1", "2"]
+func ConfigFlags() Flags {
+	return &configFlags{}
+}
+
+type configFlags struct {
+	flags map[string]Flag
+}
+
+func (flags *configFlags) Name() (name string) {
+	return "config"
+}
+
+func (flags *configFlags) Construct(options services.Options) (err error) {
+	config := ConfigFlagsConfig{}
+	if err = options.Config.As(&config); err != nil {
+		err = errors.Warning("features: construct config flags failed").WithCause(err)
+		return
+	}
+	flags.flags = config.Flags
+	if flags.flags == nil {
+		flags.flags = make(map[string]Flag)
+	}
+	return
+}
+
+func (flags *configFlags) Shutdown(_ context.Context) {
+	return
+}
+
+func (flags *configFlags) Enabled(_ context.Context, param EnabledParam) (ok bool, err error) {
+	flag, has := flags.flags[param.Name]
+	if !has {
+		return
+	}
+	if flag.Enabled {
+		ok = true
+		return
+	}
+	if len(param.Account) == 0 {
+		return
+	}
+	account := param.Account.String()
+	for _, target := range flag.Accounts {
+		if target == account {
+			ok = true
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package features
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/services"
+)
+
+var (
+	endpointName  = []byte("features")
+	enabledFnName = []byte("enabled")
+)
+
+type enabledFn struct {
+	flags Flags
+}
+
+func (fn *enabledFn) Name() string {
+	return string(enabledFnName)
+}
+
+func (fn *enabledFn) Internal() bool {
+	return true
+}
+
+func (fn *enabledFn) Readonly() bool {
+	return true
+}
+
+func (fn *enabledFn) Handle(r services.Request) (v interface{}, err error) {
+	param, paramErr := services.ValueOfParam[EnabledParam](r.Param())
+	if paramErr != nil {
+		err = errors.BadRequest("features: invalid enabled param").WithCause(paramErr)
+		return
+	}
+	v, err = fn.flags.Enabled(r, param)
+	if err != nil {
+		err = errors.ServiceError("features: check enabled failed").WithCause(err)
+		return
+	}
+	return
+}
+
+func New(flags Flags) (v services.Service) {
+	if flags == nil {
+		panic(fmt.Sprintf("%+v", errors.Warning("features: service requires flags component")))
+		return
+	}
+	v = &service{
+		Abstract: services.NewAbstract(string(endpointName), true, flags),
+		flags:    flags,
+	}
+	return
+}
+
+// service
+// use @feature {name}
+type service struct {
+	services.Abstract
+	flags Flags
+}
+
+func (svc *service) Renew() services.Service {
+	return New(svc.flags)
+}
+
+func (svc *service) Construct(options services.Options) (err error) {
+	err = svc.Abstract.Construct(options)
+	if err != nil {
+		return
+	}
+	var flags Flags
+	for _, component := range svc.Components() {
+		if c, ok := component.(Flags); ok {
+			flags = c
+			break
+		}
+	}
+	if flags == nil {
+		err = errors.Warning("features: construct failed").WithMeta("endpoint", svc.Name()).WithCause(errors.Warning("features: flags is required"))
+		return
+	}
+	svc.Abstract.AddFunction(&enabledFn{
+		flags: flags,
+	})
+	return
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/transports"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	contentDispositionHeaderName = []byte("Content-Disposition")
+	octetStreamContentType       = "application/octet-stream"
+	bytesRangeUnit               = []byte("bytes")
+)
+
+// File
+// result of fn which is responded as attachment by endpoints handler.
+// Content is used when Reader is nil, Reader is read to the end and closed when it is io.Closer.
+// ContentType is detected by extension of Name when it is empty, and application/octet-stream is the last choice.
+// Reader can not be encoded, so internal requests get Content only.
+// @title File
+// @description Binary content of file
+type File struct {
+	// Name
+	// @title Name
+	Name string `json:"name"`
+	// ContentType
+	// @title Content type
+	ContentType string `json:"contentType,omitempty"`
+	// Content
+	// @title Content
+	Content []byte `json:"content,omitempty"`
+	// Reader
+	// @title Reader
+	Reader io.Reader `json:"-"`
+}
+
+// WriteFile
+// writes file into w with Content-Type and Content-Disposition headers.
+// byteRange is the value of Range header, it is honored when content is seekable,
+// that is Content, or Reader which implements io.ReadSeeker.
+// a single range is responded with 206 and Content-Range, a range out of content is responded with ErrRangeNotSatisfiable,
+// multiple or malformed ranges are ignored, then the whole content is responded.
+// content is streamed when w is transports.BodyStreamResponseWriter, its length is known by seeking when it is seekable,
+// otherwise it is copied into w.
+func WriteFile(w transports.ResponseWriter, file File, byteRange []byte) (err error) {
+	reader := file.Reader
+	if reader == nil {
+		reader = bytes.NewReader(file.Content)
+	}
+	closer, isCloser := reader.(io.Closer)
+	status := http.StatusOK
+	body := reader
+	length := int64(-1)
+	if seeker, seekable := reader.(io.ReadSeeker); seekable {
+		w.Header().Set(transports.AcceptRangesHeaderName, bytesRangeUnit)
+		size, seekErr := seeker.Seek(0, io.SeekEnd)
+		if seekErr != nil {
+			if isCloser {
+				_ = closer.Close()
+			}
+			err = errors.Warning("fns: write file failed").WithCause(seekErr).WithMeta("file", file.Name)
+			return
+		}
+		start, end, ranged, satisfiable := parseByteRange(bytex.ToString(byteRange), size)
+		if ranged && !satisfiable {
+			if isCloser {
+				_ = closer.Close()
+			}
+			w.Header().Set(transports.ContentRangeHeaderName, bytex.FromString(fmt.Sprintf("bytes */%d", size)))
+			w.Failed(ErrRangeNotSatisfiable.WithMeta("range", bytex.ToString(byteRange)).WithMeta("size", strconv.FormatInt(size, 10)))
+			return
+		}
+		if !ranged {
+			start, end = 0, size-1
+		}
+		if _, seekErr = seeker.Seek(start, io.SeekStart); seekErr != nil {
+			if isCloser {
+				_ = closer.Close()
+			}
+			err = errors.Warning("fns: write file failed").WithCause(seekErr).WithMeta("file", file.Name)
+			return
+		}
+		length = end - start + 1
+		if ranged {
+			status = http.StatusPartialContent
+			body = io.LimitReader(seeker, length)
+			w.Header().Set(transports.ContentRangeHeaderName, bytex.FromString(fmt.Sprintf("bytes %d-%d/%d", start, end, size)))
+		}
+	}
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(file.Name))
+	}
+	if contentType == "" {
+		contentType = octetStreamContentType
+	}
+	disposition := "attachment"
+	if file.Name != "" {
+		disposition = mime.FormatMediaType("attachment", map[string]string{"filename": file.Name})
+	}
+	w.Header().Set(transports.ContentTypeHeaderName, bytex.FromString(contentType))
+	w.Header().Set(contentDispositionHeaderName, bytex.FromString(disposition))
+	w.SetStatus(status)
+	if stream, ok := w.(transports.BodyStreamResponseWriter); ok {
+		if isCloser && body != reader {
+			// keep closer of reader which is limited by range
+			body = &fileBody{Reader: body, Closer: closer}
+		}
+		stream.SetBodyStream(body, length)
+		return
+	}
+	if isCloser {
+		defer closer.Close()
+	}
+	if _, copyErr := io.Copy(w, body); copyErr != nil {
+		err = errors.Warning("fns: write file failed").WithCause(copyErr).WithMeta("file", file.Name)
+		return
+	}
+	return
+}
+
+type fileBody struct {
+	io.Reader
+	io.Closer
+}
+
+// parseByteRange
+// parses single range of value, such as bytes=0-499, bytes=500- and bytes=-500, end is inclusive.
+// ranged is false when value is malformed or has multiple ranges.
+func parseByteRange(value string, size int64) (start int64, end int64, ranged bool, satisfiable bool) {
+	spec, hasUnit := strings.CutPrefix(strings.TrimSpace(value), "bytes=")
+	if !hasUnit || strings.Contains(spec, ",") {
+		return
+	}
+	first, last, hasDash := strings.Cut(strings.TrimSpace(spec), "-")
+	if !hasDash {
+		return
+	}
+	first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+	if first == "" {
+		// suffix
+		n, parseErr := strconv.ParseInt(last, 10, 64)
+		if parseErr != nil || n < 0 {
+			return
+		}
+		ranged = true
+		if n == 0 || size == 0 {
+			return
+		}
+		if n > size {
+			n = size
+		}
+		start, end, satisfiable = size-n, size-1, true
+		return
+	}
+	n, parseErr := strconv.ParseInt(first, 10, 64)
+	if parseErr != nil || n < 0 {
+		return
+	}
+	start, end = n, size-1
+	if last != "" {
+		m, parseLastErr := strconv.ParseInt(last, 10, 64)
+		if parseLastErr != nil || m < start {
+			return
+		}
+		if m < end {
+			end = m
+		}
+	}
+	ranged = true
+	satisfiable = start < size
+	return
+}
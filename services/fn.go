@@ -18,17 +18,60 @@
 package services
 
 import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/versions"
 	"github.com/aacfactory/fns/context"
 	"github.com/aacfactory/fns/transports"
+	"net/http"
 	"sort"
 	"strings"
 	"unsafe"
 )
 
 type FnInfo struct {
-	Name     string `json:"name"`
-	Readonly bool   `json:"readonly"`
-	Internal bool   `json:"internal"`
+	Name     string           `json:"name"`
+	Readonly bool             `json:"readonly"`
+	Internal bool             `json:"internal"`
+	Routes   []FnRoute        `json:"routes,omitempty"`
+	Since    versions.Version `json:"since"`
+	Removed  versions.Version `json:"removed"`
+	Stream   bool             `json:"stream"`
+	Cron     string           `json:"cron,omitempty"`
+	Feature  string           `json:"feature,omitempty"`
+	// Validation
+	// title of validation error, empty means param of fn is not validated.
+	Validation string `json:"validation,omitempty"`
+}
+
+func NewFnInfo(fn Fn, internal bool) FnInfo {
+	info := FnInfo{
+		Name:     fn.Name(),
+		Readonly: fn.Readonly(),
+		Internal: internal || fn.Internal(),
+	}
+	if routable, ok := fn.(RoutableFn); ok {
+		info.Routes = routable.Routes()
+	}
+	if versioned, ok := fn.(VersionedFn); ok {
+		info.Since = versioned.Since()
+		info.Removed = versioned.Removed()
+	}
+	if streamable, ok := fn.(StreamableFn); ok {
+		info.Stream = streamable.Stream()
+	}
+	if scheduled, ok := fn.(ScheduledFn); ok {
+		info.Cron = scheduled.Cron()
+	}
+	if featured, ok := fn.(FeaturedFn); ok {
+		info.Feature = featured.Feature()
+	}
+	if validated, ok := fn.(ValidatedFn); ok {
+		if title, validation := validated.Validation(); validation {
+			info.Validation = title
+		}
+	}
+	return info
 }
 
 type FnInfos []FnInfo
@@ -81,6 +124,83 @@ type Fn interface {
 	Handle(ctx Request) (v any, err error)
 }
 
+// ScheduledFn
+// Cron returns cron spec of fn, empty means it is not scheduled.
+type ScheduledFn interface {
+	Fn
+	Cron() string
+}
+
+// FeaturedFn
+// Feature returns name of feature flag which gates fn, empty means it is always served.
+type FeaturedFn interface {
+	Fn
+	Feature() string
+}
+
+// ValidatedFn
+// Validation returns title of validation error and true when param of fn is validated.
+type ValidatedFn interface {
+	Fn
+	Validation() (title string, ok bool)
+}
+
+// StreamableFn
+// Stream returns true when json body of request can be decoded into param as stream.
+type StreamableFn interface {
+	Fn
+	Stream() bool
+}
+
+// VersionedFn
+// Since returns the version since which the fn is available, origin means it is always available.
+// Removed returns the version since which the fn is removed, origin means it is not removed.
+type VersionedFn interface {
+	Fn
+	Since() versions.Version
+	Removed() versions.Version
+}
+
+var (
+	ErrFnRemoved      = errors.New(http.StatusGone, "***GONE***", "fns: fn was removed")
+	ErrFnNotAvailable = errors.NotFound("fns: fn is not available in accepted versions")
+)
+
+// CheckFnVersions
+// request whose accepted versions of endpoint end at or before the since version of fn is rejected with 404,
+// request whose accepted versions of endpoint begin at or after the removed version of fn is rejected with 410.
+func CheckFnVersions(endpoint []byte, fn Fn, intervals versions.Intervals) (err error) {
+	versioned, ok := fn.(VersionedFn)
+	if !ok {
+		return
+	}
+	interval, has := intervals.Get(endpoint)
+	if !has || len(interval) == 0 {
+		return
+	}
+	// one version interval is [left, latest), its upper bound is open, so fn since any version is available.
+	upper := versions.Latest()
+	if len(interval) > 1 && !interval[1].IsLatest() {
+		upper = interval[1]
+	}
+	if since := versioned.Since(); !since.IsOrigin() && !upper.IsLatest() && !since.LessThan(upper) {
+		err = ErrFnNotAvailable.
+			WithMeta("endpoint", bytex.ToString(endpoint)).
+			WithMeta("fn", fn.Name()).
+			WithMeta("since", since.String())
+		return
+	}
+	removed := versioned.Removed()
+	if removed.IsOrigin() || interval[0].LessThan(removed) {
+		return
+	}
+	err = ErrFnRemoved.
+		WithMeta("endpoint", bytex.ToString(endpoint)).
+		WithMeta("fn", fn.Name()).
+		WithMeta("removed", removed.String())
+	return
+}
+
 type Fns []Fn
 
 func (f Fns) Len() int {
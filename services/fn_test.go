@@ -0,0 +1,88 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/services"
+	"testing"
+)
+
+type versionedFn struct {
+	echoFn
+	since   versions.Version
+	removed versions.Version
+}
+
+func (fn *versionedFn) Since() versions.Version {
+	return fn.since
+}
+
+func (fn *versionedFn) Removed() versions.Version {
+	return fn.removed
+}
+
+func version(t *testing.T, s string) versions.Version {
+	v, err := versions.Parse([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func interval(t *testing.T, s string) versions.Intervals {
+	v, err := versions.ParseInterval([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return versions.Intervals{{Name: []byte("users"), Value: v}}
+}
+
+func TestCheckFnVersions(t *testing.T) {
+	since := &versionedFn{since: version(t, "v1.2.0")}
+	removed := &versionedFn{removed: version(t, "v2.0.0")}
+	for _, c := range []struct {
+		name      string
+		fn        services.Fn
+		intervals versions.Intervals
+		expect    error
+	}{
+		{"not versioned", &echoFn{}, interval(t, "v1.0.0:v1.1.0"), nil},
+		{"no intervals", since, nil, nil},
+		{"since before upper", since, interval(t, "v1.0.0:v1.3.0"), nil},
+		{"since at upper", since, interval(t, "v1.0.0:v1.2.0"), services.ErrFnNotAvailable},
+		{"since after upper", since, interval(t, "v1.0.0:v1.1.0"), services.ErrFnNotAvailable},
+		{"since with open upper", since, interval(t, "v1.0.0"), nil},
+		{"since with one version interval", since, versions.Intervals{{Name: []byte("users"), Value: versions.Interval{version(t, "v1.0.0")}}}, nil},
+		{"removed after lower", removed, interval(t, "v1.0.0:v3.0.0"), nil},
+		{"removed at lower", removed, interval(t, "v2.0.0"), services.ErrFnRemoved},
+		{"removed before lower", removed, interval(t, "v2.1.0:v3.0.0"), services.ErrFnRemoved},
+	} {
+		err := services.CheckFnVersions([]byte("users"), c.fn, c.intervals)
+		if c.expect == nil {
+			if err != nil {
+				t.Error(c.name, "must be available", err)
+			}
+			continue
+		}
+		if err == nil || errors.Wrap(err).Code() != errors.Wrap(c.expect).Code() {
+			t.Error(c.name, "must be rejected by", c.expect, err)
+		}
+	}
+}
@@ -19,23 +19,30 @@ package services
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/commons/avros"
 	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
 	"github.com/aacfactory/fns/commons/mmhash"
 	"github.com/aacfactory/fns/commons/objects"
 	"github.com/aacfactory/fns/commons/versions"
 	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
 	"github.com/aacfactory/fns/transports"
 	"github.com/aacfactory/json"
 	"github.com/valyala/bytebufferpool"
 	"golang.org/x/sync/singleflight"
+	"net/http"
 	"strconv"
+	"strings"
 	"sync/atomic"
+	"time"
 )
 
 var (
-	slashBytes = []byte{'/'}
+	slashBytes      = []byte{'/'}
+	commaSpaceBytes = []byte(", ")
 )
 
 var (
@@ -43,37 +50,326 @@ var (
 	ErrInvalidPath            = errors.Warning("fns: invalid path")
 	ErrInvalidBody            = errors.Warning("fns: invalid body")
 	ErrInvalidRequestVersions = errors.Warning("fns: invalid request versions")
+	ErrResponseTooLarge       = errors.ServiceError("fns: response body is too large")
+	ErrTooDeepBody            = errors.New(http.StatusBadRequest, "***TOO DEEP BODY***", "fns: request body is nested too deeply")
+	ErrRangeNotSatisfiable    = errors.New(http.StatusRequestedRangeNotSatisfiable, "***OUT OF RANGE***", "fns: range of request is out of content")
+	ErrMethodNotAllowed       = errors.New(http.StatusMethodNotAllowed, "***METHOD NOT ALLOWED***", "fns: method is not allowed")
 )
 
-func Handler(endpoints Endpoints) transports.MuxHandler {
+// TransportRequestOptions
+// reads device id, device ip, tenant, request id, accepted versions and authorization of transport request as request options.
+func TransportRequestOptions(r transports.Request) (options []RequestOption, err error) {
+	path := r.Path()
+	options = make([]RequestOption, 0, 1)
+	deviceId := r.Header().Get(transports.DeviceIdHeaderName)
+	if len(deviceId) == 0 {
+		err = ErrDeviceId.WithMeta("path", bytex.ToString(path))
+		return
+	}
+	options = append(options, WithDeviceId(deviceId))
+	if deviceIp := transports.DeviceIp(r); len(deviceIp) > 0 {
+		options = append(options, WithDeviceIp(deviceIp))
+	}
+	if tenant := r.Header().Get(transports.TenantHeaderName); len(tenant) > 0 {
+		options = append(options, WithTenant(tenant))
+	}
+	if requestId := r.Header().Get(transports.RequestIdHeaderName); len(requestId) > 0 {
+		options = append(options, WithRequestId(requestId))
+	}
+	if acceptedVersions := r.Header().Get(transports.RequestVersionsHeaderName); len(acceptedVersions) > 0 {
+		intervals, intervalsErr := versions.ParseIntervals(acceptedVersions)
+		if intervalsErr != nil {
+			err = ErrInvalidRequestVersions.WithMeta("path", bytex.ToString(path)).WithMeta("versions", bytex.ToString(acceptedVersions)).WithCause(intervalsErr)
+			return
+		}
+		options = append(options, WithRequestVersions(intervals))
+	}
+	if authorization := r.Header().Get(transports.AuthorizationHeaderName); len(authorization) > 0 {
+		options = append(options, WithToken(authorization))
+	}
+	return
+}
+
+type HandlerConfig struct {
+	// Coalesce
+	// identical concurrent requests (same path, device, versions, authorization and param) are handled once.
+	// readonly: coalesce requests of readonly fn (default), all: coalesce requests of all fn, none: disable coalescing.
+	Coalesce string `json:"coalesce,omitempty" yaml:"coalesce,omitempty"`
+	// Hooks
+	// queue of hooks, see Hook.
+	Hooks HooksConfig `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	// MaxResponseBodySize
+	// bytes format, such as 8MB, a result whose marshaled body is larger is answered with ErrResponseTooLarge.
+	// it is disabled by default. Results are marshaled as a whole, there is no streaming result to count bytes of.
+	MaxResponseBodySize string `json:"maxResponseBodySize,omitempty" yaml:"maxResponseBodySize,omitempty"`
+	// StatusCodes
+	// maps names of errors to http status codes, such as {"conflict": 409},
+	// errors whose names are not mapped are responded with their own codes.
+	StatusCodes map[string]int `json:"statusCodes,omitempty" yaml:"statusCodes,omitempty"`
+	// Prevalidate
+	// checks json body against the document of fn before dispatching, so malformed requests do not take workers.
+	// it reports mismatched types and missing required fields with the same errors as fn does.
+	Prevalidate bool `json:"prevalidate,omitempty" yaml:"prevalidate,omitempty"`
+	// Messages
+	// localized messages of errors, the message matched by Accept-Language of request replaces message of error.
+	Messages Messages `json:"messages,omitempty" yaml:"messages,omitempty"`
+	// AdaptiveLimit
+	// caps in-flight requests by latency, requests over the cap are rejected with ErrOverloaded, see AdaptiveLimiter.
+	AdaptiveLimit AdaptiveLimitConfig `json:"adaptiveLimit,omitempty" yaml:"adaptiveLimit,omitempty"`
+}
+
+const (
+	coalesceReadonly = "readonly"
+	coalesceAll      = "all"
+	coalesceNone     = "none"
+)
+
+// Handler
+// param of GET request is bound from query string, body of GET request is ignored.
+// param of POST request is decoded from body, query string of POST request is ignored.
+// path params of @http route take precedence over query string or body fields with the same name.
+// json body of POST request to fn with @stream is decoded as stream when transport streams request body.
+// requests whose latency exceeds slowThreshold are logged as warnings, see logs.Config.SlowThreshold.
+// hooks are called asynchronously after requests are responded.
+func Handler(endpoints Endpoints, slowThreshold time.Duration, hooks ...Hook) transports.MuxHandler {
 	return &endpointsHandler{
-		endpoints: endpoints,
-		loaded:    atomic.Bool{},
-		infos:     nil,
-		group:     singleflight.Group{},
+		endpoints:     endpoints,
+		slowThreshold: slowThreshold,
+		hooks:         hooks,
+		loaded:        atomic.Bool{},
+		infos:         nil,
+		routes:        nil,
+		group:         singleflight.Group{},
 	}
 }
 
 type endpointsHandler struct {
-	endpoints Endpoints
-	loaded    atomic.Bool
-	infos     EndpointInfos
-	group     singleflight.Group
+	log           logs.Logger
+	endpoints     Endpoints
+	slowThreshold time.Duration
+	hooks         []Hook
+	dispatcher    *hookDispatcher
+	coalesce      string
+	maxBodySize   int
+	statusCodes   map[string]int
+	messages      Messages
+	limiter       *AdaptiveLimiter
+	prevalidation bool
+	loaded        atomic.Bool
+	infos         EndpointInfos
+	routes        routes
+	group         singleflight.Group
 }
 
 func (handler *endpointsHandler) Name() string {
 	return "endpoints"
 }
 
-func (handler *endpointsHandler) Construct(_ transports.MuxHandlerOptions) error {
-	return nil
+func (handler *endpointsHandler) Construct(options transports.MuxHandlerOptions) (err error) {
+	config := HandlerConfig{}
+	configErr := options.Config.As(&config)
+	if configErr != nil {
+		err = errors.Warning("fns: construct endpoints handler failed").WithCause(configErr)
+		return
+	}
+	handler.log = options.Log
+	switch config.Coalesce {
+	case "", coalesceReadonly:
+		handler.coalesce = coalesceReadonly
+		break
+	case coalesceAll, coalesceNone:
+		handler.coalesce = config.Coalesce
+		break
+	default:
+		err = errors.Warning("fns: construct endpoints handler failed").WithCause(fmt.Errorf("coalesce must be readonly, all or none")).WithMeta("coalesce", config.Coalesce)
+		return
+	}
+	if s := strings.TrimSpace(config.MaxResponseBodySize); s != "" {
+		size, sizeErr := bytex.ParseBytes(s)
+		if sizeErr != nil {
+			err = errors.Warning("fns: construct endpoints handler failed").WithCause(sizeErr).WithMeta("maxResponseBodySize", s)
+			return
+		}
+		handler.maxBodySize = int(size)
+	}
+	handler.prevalidation = config.Prevalidate
+	for name, code := range config.StatusCodes {
+		if code < 100 || code > 999 {
+			err = errors.Warning("fns: construct endpoints handler failed").WithCause(fmt.Errorf("status code must be in [100, 999]")).WithMeta("name", name).WithMeta("code", strconv.Itoa(code))
+			return
+		}
+	}
+	handler.statusCodes = config.StatusCodes
+	handler.messages = config.Messages
+	if config.AdaptiveLimit.Enable {
+		handler.limiter, err = NewAdaptiveLimiter(config.AdaptiveLimit)
+		if err != nil {
+			err = errors.Warning("fns: construct endpoints handler failed").WithCause(err)
+			return
+		}
+	}
+	if len(handler.hooks) > 0 {
+		handler.dispatcher, err = newHookDispatcher(handler.log.With("hooks", "dispatcher"), config.Hooks, handler.hooks)
+		if err != nil {
+			err = errors.Warning("fns: construct endpoints handler failed").WithCause(err)
+			return
+		}
+	}
+	return
+}
+
+func (handler *endpointsHandler) Close() (err error) {
+	if handler.dispatcher != nil {
+		handler.dispatcher.close()
+	}
+	return
+}
+
+func (handler *endpointsHandler) streamable(ep []byte, fn []byte) bool {
+	endpoint, hasEndpoint := handler.infos.Find(ep)
+	if !hasEndpoint {
+		return false
+	}
+	fi, hasFn := endpoint.Functions.Find(fn)
+	return hasFn && fi.Stream
+}
+
+// prevalidate
+// returns the error which fn would return for body, errors of types are same as scanning param,
+// and errors of required fields are same as validators.
+func (handler *endpointsHandler) prevalidate(ep []byte, fn []byte, body []byte) (err error) {
+	endpoint, hasEndpoint := handler.infos.Find(ep)
+	if !hasEndpoint || !endpoint.Document.Defined() {
+		return
+	}
+	fi, hasFn := endpoint.Functions.Find(fn)
+	if !hasFn {
+		return
+	}
+	document := endpoint.Document
+	for _, fd := range document.Functions {
+		if fd.Name != fi.Name {
+			continue
+		}
+		violations := document.Prevalidate(fd.Param, body, fi.Validation != "")
+		var invalid errors.CodeError
+		for _, violation := range violations {
+			if violation.Expected != "" {
+				key := violation.Key
+				if key == "" {
+					key = "$"
+				}
+				err = errors.BadRequest("scan params failed").WithMeta(key, fmt.Sprintf("expected %s", violation.Expected))
+				return
+			}
+			if invalid == nil {
+				invalid = errors.BadRequest(fi.Validation)
+			}
+			message := violation.Message
+			if message == "" {
+				message = "required"
+			}
+			invalid = invalid.WithMeta(violation.Key, message)
+		}
+		if invalid != nil {
+			err = invalid
+		}
+		return
+	}
+	return
+}
+
+func (handler *endpointsHandler) coalesced(ep []byte, fn []byte) bool {
+	switch handler.coalesce {
+	case coalesceAll:
+		return true
+	case coalesceNone:
+		return false
+	default:
+		endpoint, hasEndpoint := handler.infos.Find(ep)
+		if !hasEndpoint {
+			return false
+		}
+		fi, hasFn := endpoint.Functions.Find(fn)
+		return hasFn && fi.Readonly
+	}
 }
 
 func (handler *endpointsHandler) Match(_ context.Context, method []byte, path []byte, header transports.Header) bool {
 	if !handler.loaded.Load() {
 		handler.infos = handler.endpoints.Info()
+		handler.routes = newRoutes(handler.infos)
 		handler.loaded.Store(true)
 	}
+	if bytes.Equal(method, transports.MethodOptions) {
+		return len(handler.allow(path)) > 0
+	}
+	if bytes.Equal(method, transports.MethodHead) {
+		// head of fns which are not readonly is matched too, then it will be rejected with 405 in handle
+		if _, exposed := handler.exposedFn(path); exposed {
+			return true
+		}
+		method = transports.MethodGet
+	}
+	if handler.matchFn(method, path, header) {
+		return true
+	}
+	_, _, _, routed := handler.routes.match(method, path)
+	return routed
+}
+
+// allow
+// returns value of Allow header of path, empty means path is not matched.
+func (handler *endpointsHandler) allow(path []byte) []byte {
+	methods := make([][]byte, 0, 4)
+	if fi, exposed := handler.exposedFn(path); exposed {
+		if fi.Readonly {
+			methods = append(methods, transports.MethodGet, transports.MethodHead)
+		} else {
+			methods = append(methods, transports.MethodPost)
+		}
+	}
+	for _, method := range handler.routes.methods(path) {
+		exist := false
+		for _, m := range methods {
+			if bytes.Equal(m, method) {
+				exist = true
+				break
+			}
+		}
+		if exist {
+			continue
+		}
+		methods = append(methods, method)
+		if bytes.Equal(method, transports.MethodGet) {
+			methods = append(methods, transports.MethodHead)
+		}
+	}
+	if len(methods) == 0 {
+		return nil
+	}
+	methods = append(methods, transports.MethodOptions)
+	return bytes.Join(methods, commaSpaceBytes)
+}
+
+func (handler *endpointsHandler) exposedFn(path []byte) (fi FnInfo, has bool) {
+	pathItems := bytes.Split(path, slashBytes)
+	if len(pathItems) != 3 {
+		return
+	}
+	endpoint, hasEndpoint := handler.infos.Find(pathItems[1])
+	if !hasEndpoint || endpoint.Internal {
+		return
+	}
+	fi, has = endpoint.Functions.Find(pathItems[2])
+	if !has || fi.Internal {
+		has = false
+		return
+	}
+	return
+}
+
+func (handler *endpointsHandler) matchFn(method []byte, path []byte, header transports.Header) bool {
 	pathItems := bytes.Split(path, slashBytes)
 	if len(pathItems) != 3 {
 		return false
@@ -108,18 +404,50 @@ func (handler *endpointsHandler) Match(_ context.Context, method []byte, path []
 }
 
 func (handler *endpointsHandler) Handle(w transports.ResponseWriter, r transports.Request) {
+	beg := time.Now()
 	groupKeyBuf := bytebufferpool.Get()
 
 	// path
 	path := r.Path()
-	pathItems := bytes.Split(path, slashBytes)
-	if len(pathItems) != 3 {
+	method := r.Method()
+	if bytes.Equal(method, transports.MethodOptions) {
+		// cors preflight is answered by cors middleware, so here is bare options which requires nothing
 		bytebufferpool.Put(groupKeyBuf)
-		w.Failed(ErrInvalidPath.WithMeta("path", bytex.ToString(path)))
+		w.Header().Set(transports.AllowHeaderName, handler.allow(path))
+		w.SetStatus(http.StatusNoContent)
 		return
 	}
-	ep := pathItems[1]
-	fn := pathItems[2]
+	if bytes.Equal(method, transports.MethodHead) {
+		// head is handled as get, body is dropped by transport
+		if fi, exposed := handler.exposedFn(path); exposed && !fi.Readonly {
+			bytebufferpool.Put(groupKeyBuf)
+			w.Header().Set(transports.AllowHeaderName, handler.allow(path))
+			handler.failed(w, r, ErrMethodNotAllowed.WithMeta("path", bytex.ToString(path)))
+			return
+		}
+		method = transports.MethodGet
+	}
+	var ep, fn []byte
+	var pathParams []routePathParam
+	if handler.matchFn(method, path, r.Header()) {
+		pathItems := bytes.Split(path, slashBytes)
+		ep = pathItems[1]
+		fn = pathItems[2]
+	} else {
+		routed := false
+		routePath := path
+		if er, ok := r.(transports.EscapedPathRequest); ok {
+			if escaped := er.EscapedPath(); len(escaped) > 0 {
+				routePath = escaped
+			}
+		}
+		ep, fn, pathParams, routed = handler.routes.match(method, routePath)
+		if !routed {
+			bytebufferpool.Put(groupKeyBuf)
+			handler.failed(w, r, ErrInvalidPath.WithMeta("path", bytex.ToString(path)))
+			return
+		}
+	}
 	_, _ = groupKeyBuf.Write(path)
 
 	// header >>>
@@ -128,7 +456,7 @@ func (handler *endpointsHandler) Handle(w transports.ResponseWriter, r transport
 	deviceId := r.Header().Get(transports.DeviceIdHeaderName)
 	if len(deviceId) == 0 {
 		bytebufferpool.Put(groupKeyBuf)
-		w.Failed(ErrDeviceId.WithMeta("path", bytex.ToString(path)))
+		handler.failed(w, r, ErrDeviceId.WithMeta("path", bytex.ToString(path)))
 		return
 	}
 	options = append(options, WithDeviceId(deviceId))
@@ -138,6 +466,12 @@ func (handler *endpointsHandler) Handle(w transports.ResponseWriter, r transport
 	if len(deviceIp) > 0 {
 		options = append(options, WithDeviceIp(deviceIp))
 	}
+	// tenant
+	tenant := r.Header().Get(transports.TenantHeaderName)
+	if len(tenant) > 0 {
+		options = append(options, WithTenant(tenant))
+		_, _ = groupKeyBuf.Write(tenant)
+	}
 	// request id
 	requestId := r.Header().Get(transports.RequestIdHeaderName)
 	if len(requestId) > 0 {
@@ -149,7 +483,7 @@ func (handler *endpointsHandler) Handle(w transports.ResponseWriter, r transport
 		intervals, intervalsErr := versions.ParseIntervals(acceptedVersions)
 		if intervalsErr != nil {
 			bytebufferpool.Put(groupKeyBuf)
-			w.Failed(ErrInvalidRequestVersions.WithMeta("path", bytex.ToString(path)).WithMeta("versions", bytex.ToString(acceptedVersions)).WithCause(intervalsErr))
+			handler.failed(w, r, ErrInvalidRequestVersions.WithMeta("path", bytex.ToString(path)).WithMeta("versions", bytex.ToString(acceptedVersions)).WithCause(intervalsErr))
 			return
 		}
 		options = append(options, WithRequestVersions(intervals))
@@ -161,15 +495,35 @@ func (handler *endpointsHandler) Handle(w transports.ResponseWriter, r transport
 		options = append(options, WithToken(authorization))
 		_, _ = groupKeyBuf.Write(authorization)
 	}
+	// range, different ranges of one file can not share one response
+	byteRange := r.Header().Get(transports.RangeHeaderName)
+	if len(byteRange) > 0 {
+		_, _ = groupKeyBuf.Write(byteRange)
+	}
 
 	// header <<<
 
 	// param
 	var param objects.Object
-	method := r.Method()
-	if bytes.Equal(method, transports.MethodGet) {
+	payload := 0
+	streamed := false
+	if bytes.Equal(method, transports.MethodPost) && len(pathParams) == 0 && handler.streamable(ep, fn) &&
+		bytes.Equal(r.Header().Get(transports.ContentTypeHeaderName), transports.ContentTypeJsonHeaderValue) {
+		if sr, ok := r.(transports.BodyStreamRequest); ok {
+			if reader, hasReader := sr.BodyStream(); hasReader {
+				param = NewStreamParam(reader)
+				streamed = true
+			}
+		}
+	}
+	if streamed {
+		// body is not read, so request can not be coalesced
+	} else if bytes.Equal(method, transports.MethodGet) {
 		// query
 		queryParams := r.Params()
+		for _, pathParam := range pathParams {
+			queryParams.Set(pathParam.name, pathParam.value)
+		}
 		param = transports.ObjectParams(queryParams)
 		_, _ = groupKeyBuf.Write(queryParams.Encode())
 	} else {
@@ -177,11 +531,31 @@ func (handler *endpointsHandler) Handle(w transports.ResponseWriter, r transport
 		body, bodyErr := r.Body()
 		if bodyErr != nil {
 			bytebufferpool.Put(groupKeyBuf)
-			w.Failed(ErrInvalidBody.WithMeta("path", bytex.ToString(path)))
+			if codeErr, ok := errors.As(bodyErr); ok && codeErr.Code() < 500 {
+				// such as truncated or too large body
+				handler.failed(w, r, codeErr.WithMeta("path", bytex.ToString(path)))
+				return
+			}
+			handler.failed(w, r, ErrInvalidBody.WithMeta("path", bytex.ToString(path)).WithCause(bodyErr))
 			return
 		}
 		contentType := r.Header().Get(transports.ContentTypeHeaderName)
-		if bytes.Equal(contentType, transports.ContentTypeJsonHeaderValue) {
+		if !bytes.Equal(contentType, transports.ContentTypeAvroHeaderValue) {
+			if depthErr := jsons.CheckDepth(body); depthErr != nil {
+				bytebufferpool.Put(groupKeyBuf)
+				handler.failed(w, r, ErrTooDeepBody.WithMeta("path", bytex.ToString(path)).WithMeta("max", strconv.Itoa(jsons.MaxDepth())))
+				return
+			}
+		}
+		if len(pathParams) > 0 && !bytes.Equal(contentType, transports.ContentTypeAvroHeaderValue) {
+			merged, mergeErr := mergeRoutePathParams(body, pathParams)
+			if mergeErr != nil {
+				bytebufferpool.Put(groupKeyBuf)
+				handler.failed(w, r, ErrInvalidBody.WithMeta("path", bytex.ToString(path)).WithCause(mergeErr))
+				return
+			}
+			param = json.RawMessage(merged)
+		} else if bytes.Equal(contentType, transports.ContentTypeJsonHeaderValue) {
 			param = json.RawMessage(body)
 		} else if bytes.Equal(contentType, transports.ContentTypeAvroHeaderValue) {
 			param = avros.RawMessage(body)
@@ -190,36 +564,150 @@ func (handler *endpointsHandler) Handle(w transports.ResponseWriter, r transport
 				param = json.RawMessage(body)
 			} else {
 				bytebufferpool.Put(groupKeyBuf)
-				w.Failed(ErrInvalidBody.WithMeta("path", bytex.ToString(path)))
+				handler.failed(w, r, ErrInvalidBody.WithMeta("path", bytex.ToString(path)))
 				return
 			}
 		}
+		if handler.prevalidation {
+			if raw, isJson := param.(json.RawMessage); isJson {
+				if invalidErr := handler.prevalidate(ep, fn, raw); invalidErr != nil {
+					bytebufferpool.Put(groupKeyBuf)
+					handler.failed(w, r, invalidErr)
+					return
+				}
+			}
+		}
 		_, _ = groupKeyBuf.Write(body)
+		payload = len(body)
 	}
 
-	// handle
-	groupKey := strconv.FormatUint(mmhash.Sum64(groupKeyBuf.Bytes()), 16)
-	bytebufferpool.Put(groupKeyBuf)
-	v, err, _ := handler.group.Do(groupKey, func() (v interface{}, err error) {
-		v, err = handler.endpoints.Request(
-			r, ep, fn,
-			param,
-			options...,
-		)
-		return
-	})
-	handler.group.Forget(groupKey)
-	if err != nil {
-		w.Failed(err)
+	// limit
+	if handler.limiter != nil && !handler.limiter.Acquire() {
+		bytebufferpool.Put(groupKeyBuf)
+		handler.failed(w, r, ErrOverloaded.WithMeta("path", bytex.ToString(path)))
 		return
 	}
-	response := v.(Response)
 
-	if response.Valid() {
-		w.Succeed(response.Value())
+	// handle
+	counter := FnCounterOf(ep, fn)
+	counter.Begin()
+	var response Response
+	var err error
+	if !streamed && handler.coalesced(ep, fn) {
+		groupKey := strconv.FormatUint(mmhash.Sum64(groupKeyBuf.Bytes()), 16)
+		bytebufferpool.Put(groupKeyBuf)
+		leader := false
+		v, doErr, _ := handler.group.Do(groupKey, func() (v interface{}, err error) {
+			leader = true
+			v, err = handler.endpoints.Request(
+				r, ep, fn,
+				param,
+				options...,
+			)
+			return
+		})
+		handler.group.Forget(groupKey)
+		if doErr == nil {
+			response = v.(Response)
+		}
+		err = doErr
+		if !leader && err == nil && response.Valid() {
+			// reader of file is read by the leader, so waiters request their own
+			if file, isFile := response.Value().(File); isFile && file.Reader != nil {
+				response, err = handler.endpoints.Request(r, ep, fn, param, options...)
+			}
+		}
+	} else {
+		bytebufferpool.Put(groupKeyBuf)
+		response, err = handler.endpoints.Request(r, ep, fn, param, options...)
+	}
+	latency := time.Since(beg)
+	if handler.limiter != nil {
+		handler.limiter.Release(latency, err)
+	}
+	LogSlowRequest(handler.log, handler.slowThreshold, latency, ep, fn, requestId)
+	if err != nil {
+		handler.failed(w, r, err)
+	} else if response.Valid() {
+		switch value := response.Value().(type) {
+		case Redirection, *Redirection:
+			WriteRedirection(w, value)
+			break
+		case File:
+			if err = WriteFile(w, value, byteRange); err != nil {
+				handler.failed(w, r, err)
+			}
+			break
+		default:
+			w.Succeed(value)
+			if LimitResponseBody(w, handler.maxBodySize) {
+				err = ErrResponseTooLarge
+				if handler.log.ErrorEnabled() {
+					handler.log.Error().
+						With("service", bytex.ToString(ep)).With("fn", bytex.ToString(fn)).With("requestId", bytex.ToString(requestId)).
+						Message(fmt.Sprintf("fns: size of response body is over %d bytes", handler.maxBodySize))
+				}
+			}
+			break
+		}
 	} else {
 		w.Succeed(nil)
 	}
+	counter.End(latency, err, payload)
+	if handler.dispatcher != nil {
+		handler.dispatcher.send(r, ep, fn, w.Body(), err, latency)
+	}
+}
+
+func (handler *endpointsHandler) failed(w transports.ResponseWriter, r transports.Request, err error) {
+	w.Failed(handler.mapError(r, err))
+}
+
+// mapError
+// maps status code and localizes message of err, it is shared by failed and items of batch.
+func (handler *endpointsHandler) mapError(r transports.Request, err error) error {
+	err = MapErrorStatus(err, handler.statusCodes)
+	if len(handler.messages) > 0 {
+		err = LocalizeError(err, handler.messages, r.Header().Get(transports.AcceptLanguageHeaderName))
+	}
+	return err
+}
+
+// MapErrorStatus
+// returns err with the status code which is mapped by name of err,
+// err is returned as it is when its name is not mapped.
+func MapErrorStatus(err error, statusCodes map[string]int) error {
+	if err == nil || len(statusCodes) == 0 {
+		return err
+	}
+	codeErr := errors.Wrap(err)
+	code, mapped := statusCodes[codeErr.Name()]
+	if !mapped || code == codeErr.Code() {
+		return err
+	}
+	p, encodeErr := jsons.Marshal(codeErr)
+	if encodeErr != nil {
+		return err
+	}
+	impl := errors.CodeErrorImpl{}
+	if decodeErr := jsons.Unmarshal(p, &impl); decodeErr != nil {
+		return err
+	}
+	impl.Code_ = code
+	return impl
+}
+
+// LimitResponseBody
+// replaces body of w with ErrResponseTooLarge when it is larger than max, max less than 1 means no limit.
+func LimitResponseBody(w transports.ResponseWriter, max int) (exceeded bool) {
+	if max < 1 || w.BodyLen() <= max {
+		return
+	}
+	size := w.BodyLen()
+	w.ResetBody()
+	w.Failed(ErrResponseTooLarge.WithMeta("size", strconv.Itoa(size)).WithMeta("max", strconv.Itoa(max)))
+	exceeded = true
+	return
 }
 
 type MuxHandler interface {
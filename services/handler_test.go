@@ -0,0 +1,374 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/aacfactory/configures"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/fns/transports/fast"
+	"github.com/valyala/fasthttp"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type resultWriter struct {
+	context.Context
+	*transports.ResultResponseWriter
+}
+
+func (w *resultWriter) SetCookie(_ *transports.Cookie) {}
+
+func (w *resultWriter) Hijack(_ func(ctx context.Context, conn net.Conn, rw *bufio.ReadWriter) (err error)) (async bool, err error) {
+	return
+}
+
+func (w *resultWriter) Hijacked() bool {
+	return false
+}
+
+func TestLimitResponseBody(t *testing.T) {
+	body := []byte(`"0123456789"`)
+	for _, c := range []struct {
+		max      int
+		exceeded bool
+	}{{0, false}, {len(body), false}, {len(body) - 1, true}} {
+		w := &resultWriter{
+			Context:              context.TODO(),
+			ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+		}
+		w.Succeed("0123456789")
+		if w.BodyLen() != len(body) {
+			t.Fatal("unexpected body", string(w.Body()))
+		}
+		exceeded := services.LimitResponseBody(w, c.max)
+		if exceeded != c.exceeded {
+			t.Fatal("max", c.max, "want exceeded", c.exceeded)
+		}
+		if exceeded && w.Status() != http.StatusInternalServerError {
+			t.Fatal("want 500, got", w.Status())
+		}
+		if !exceeded && string(w.Body()) != string(body) {
+			t.Fatal("body was changed", string(w.Body()))
+		}
+		transports.ReleaseResultResponseWriter(w.ResultResponseWriter)
+	}
+}
+
+func TestMapErrorStatus(t *testing.T) {
+	statusCodes := map[string]int{"conflict": http.StatusConflict}
+	conflict := errors.New(555, "conflict", "user exists").WithMeta("user", "1")
+	mapped := errors.Wrap(services.MapErrorStatus(conflict, statusCodes))
+	if mapped.Code() != http.StatusConflict || mapped.Name() != "conflict" || mapped.Message() != "user exists" || mapped.Id() != conflict.Id() {
+		t.Fatal("unexpected mapped error", mapped)
+	}
+	w := &resultWriter{
+		Context:              context.TODO(),
+		ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+	}
+	w.Failed(mapped)
+	if w.Status() != http.StatusConflict {
+		t.Fatal("want 409, got", w.Status())
+	}
+	transports.ReleaseResultResponseWriter(w.ResultResponseWriter)
+	// fallback
+	notFound := errors.NotFound("user was not found")
+	if code := errors.Wrap(services.MapErrorStatus(notFound, statusCodes)).Code(); code != http.StatusNotFound {
+		t.Fatal("want 404, got", code)
+	}
+	if code := errors.Wrap(services.MapErrorStatus(conflict, nil)).Code(); code != 555 {
+		t.Fatal("want 555, got", code)
+	}
+}
+
+func TestContextErr(t *testing.T) {
+	if err := services.ContextErr(context.TODO()); err != nil {
+		t.Fatal("alive context failed", err)
+	}
+	canceled, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if err, ok := errors.As(services.ContextErr(canceled)); !ok || err.Code() != 499 {
+		t.Fatal("unexpected error of canceled context", err)
+	}
+	expired, cancelExpired := context.WithTimeout(context.TODO(), time.Nanosecond)
+	defer cancelExpired()
+	<-expired.Done()
+	if err, ok := errors.As(services.ContextErr(expired)); !ok || err.Code() != http.StatusRequestTimeout {
+		t.Fatal("unexpected error of expired context", err)
+	}
+}
+
+func TestWriteRedirection(t *testing.T) {
+	w := &resultWriter{
+		Context:              context.TODO(),
+		ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+	}
+	defer transports.ReleaseResultResponseWriter(w.ResultResponseWriter)
+	if !services.WriteRedirection(w, services.Redirect("https://example.com/callback?code=1", http.StatusSeeOther)) {
+		t.Fatal("redirection was not written")
+	}
+	if w.Status() != http.StatusSeeOther {
+		t.Fatal("want 303, got", w.Status())
+	}
+	if location := string(w.Header().Get(transports.LocationHeaderName)); location != "https://example.com/callback?code=1" {
+		t.Fatal("unexpected location", location)
+	}
+	if w.BodyLen() != 0 {
+		t.Fatal("body of redirection must be empty", string(w.Body()))
+	}
+	if services.Redirect("/", http.StatusOK).Status != http.StatusFound {
+		t.Fatal("status out of 3xx must be replaced by 302")
+	}
+	if services.WriteRedirection(w, services.Empty{}) {
+		t.Fatal("non redirection was written")
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	w := &resultWriter{
+		Context:              context.TODO(),
+		ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+	}
+	defer transports.ReleaseResultResponseWriter(w.ResultResponseWriter)
+	err := services.WriteFile(w, services.File{
+		Name:   "报表.csv",
+		Reader: io.NopCloser(strings.NewReader("id,name\n1,foo\n")),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType := string(w.Header().Get(transports.ContentTypeHeaderName)); !strings.HasPrefix(contentType, "text/csv") {
+		t.Fatal("unexpected content type", contentType)
+	}
+	if disposition := string(w.Header().Get([]byte("Content-Disposition"))); disposition != "attachment; filename*=utf-8''%E6%8A%A5%E8%A1%A8.csv" {
+		t.Fatal("unexpected disposition", disposition)
+	}
+	if string(w.Body()) != "id,name\n1,foo\n" {
+		t.Fatal("unexpected body", string(w.Body()))
+	}
+}
+
+type streamWriter struct {
+	resultWriter
+	stream io.Reader
+	size   int64
+}
+
+func (w *streamWriter) SetBodyStream(reader io.Reader, size int64) {
+	w.stream = reader
+	w.size = size
+}
+
+type closeRecorder struct {
+	io.ReadSeeker
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestWriteFileStream(t *testing.T) {
+	for _, c := range []struct {
+		file      services.File
+		byteRange string
+		status    int
+		size      int64
+		body      string
+	}{
+		{services.File{Name: "digits.txt", Content: []byte("0123456789")}, "", http.StatusOK, 10, "0123456789"},
+		{services.File{Name: "digits.txt", Reader: &closeRecorder{ReadSeeker: strings.NewReader("0123456789")}}, "bytes=2-5", http.StatusPartialContent, 4, "2345"},
+		{services.File{Name: "digits.txt", Reader: io.NopCloser(strings.NewReader("0123456789"))}, "bytes=2-5", http.StatusOK, -1, "0123456789"},
+	} {
+		w := &streamWriter{
+			resultWriter: resultWriter{
+				Context:              context.TODO(),
+				ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+			},
+		}
+		if err := services.WriteFile(w, c.file, []byte(c.byteRange)); err != nil {
+			t.Fatal(err)
+		}
+		if w.Status() != c.status || w.size != c.size || w.BodyLen() != 0 {
+			t.Fatal("unexpected stream", w.Status(), w.size, w.BodyLen())
+		}
+		body, readErr := io.ReadAll(w.stream)
+		if readErr != nil || string(body) != c.body {
+			t.Fatal("unexpected body", string(body), readErr)
+		}
+		if recorder, ok := c.file.Reader.(*closeRecorder); ok {
+			if recorder.closed {
+				t.Fatal("reader must be closed by transport after responded")
+			}
+			if closer, isCloser := w.stream.(io.Closer); !isCloser || closer.Close() != nil || !recorder.closed {
+				t.Fatal("closer of ranged reader was lost")
+			}
+		}
+		transports.ReleaseResultResponseWriter(w.ResultResponseWriter)
+	}
+}
+
+func TestWriteFileRange(t *testing.T) {
+	content := []byte("0123456789")
+	for _, c := range []struct {
+		byteRange    string
+		status       int
+		contentRange string
+		body         string
+	}{
+		{"bytes=2-5", http.StatusPartialContent, "bytes 2-5/10", "2345"},
+		{"bytes=7-", http.StatusPartialContent, "bytes 7-9/10", "789"},
+		{"bytes=-3", http.StatusPartialContent, "bytes 7-9/10", "789"},
+		{"bytes=8-100", http.StatusPartialContent, "bytes 8-9/10", "89"},
+		{"bytes=0-1,4-5", http.StatusOK, "", "0123456789"},
+		{"items=0-1", http.StatusOK, "", "0123456789"},
+		{"bytes=10-", http.StatusRequestedRangeNotSatisfiable, "bytes */10", ""},
+	} {
+		w := &resultWriter{
+			Context:              context.TODO(),
+			ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+		}
+		err := services.WriteFile(w, services.File{Name: "digits.txt", Reader: bytes.NewReader(content)}, []byte(c.byteRange))
+		if err != nil {
+			t.Fatal(c.byteRange, err)
+		}
+		if w.Status() != c.status {
+			t.Fatal(c.byteRange, "want", c.status, "got", w.Status())
+		}
+		if contentRange := string(w.Header().Get(transports.ContentRangeHeaderName)); contentRange != c.contentRange {
+			t.Fatal(c.byteRange, "unexpected content range", contentRange)
+		}
+		if string(w.Header().Get(transports.AcceptRangesHeaderName)) != "bytes" {
+			t.Fatal(c.byteRange, "accept ranges is lost")
+		}
+		if c.status != http.StatusRequestedRangeNotSatisfiable && string(w.Body()) != c.body {
+			t.Fatal(c.byteRange, "unexpected body", string(w.Body()))
+		}
+		transports.ReleaseResultResponseWriter(w.ResultResponseWriter)
+	}
+	// not seekable
+	w := &resultWriter{
+		Context:              context.TODO(),
+		ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+	}
+	defer transports.ReleaseResultResponseWriter(w.ResultResponseWriter)
+	_ = services.WriteFile(w, services.File{Name: "digits.txt", Reader: io.NopCloser(bytes.NewReader(content))}, []byte("bytes=2-5"))
+	if w.Status() != http.StatusOK || string(w.Body()) != string(content) || len(w.Header().Get(transports.AcceptRangesHeaderName)) != 0 {
+		t.Fatal("range of not seekable content must be ignored")
+	}
+}
+
+type downloadFn struct{}
+
+func (fn *downloadFn) Name() string {
+	return "download"
+}
+
+func (fn *downloadFn) Internal() bool {
+	return false
+}
+
+func (fn *downloadFn) Readonly() bool {
+	return true
+}
+
+func (fn *downloadFn) Handle(_ services.Request) (v any, err error) {
+	// wait for waiters of coalescing
+	time.Sleep(50 * time.Millisecond)
+	v = services.File{Name: "digits.txt", Reader: strings.NewReader("0123456789")}
+	return
+}
+
+type downloadService struct {
+	services.Abstract
+}
+
+// TestHandlerConcurrentDownload
+// run with -race, reader of file must not be shared by coalesced requests.
+func TestHandlerConcurrentDownload(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	svc := &downloadService{Abstract: services.NewAbstract("files", false)}
+	svc.AddFunction(&downloadFn{})
+	if err := manager.Add(svc); err != nil {
+		t.Fatal(err)
+	}
+	handler := services.Handler(manager, 0)
+	config, _ := configures.NewJsonConfig([]byte(`{}`))
+	if err := handler.Construct(transports.MuxHandlerOptions{Log: log, Config: config}); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		byteRange string
+		body      string
+	}{
+		{"", "0123456789"},
+		{"bytes=2-5", "2345"},
+	}
+	wg := sync.WaitGroup{}
+	failures := make(chan string, 16)
+	for i := 0; i < 16; i++ {
+		c := cases[i%len(cases)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rc := &fasthttp.RequestCtx{}
+			rc.Request.Header.SetMethod(http.MethodGet)
+			rc.Request.SetRequestURI("/files/download")
+			rc.Request.Header.Set("X-Fns-Device-Id", "device")
+			if c.byteRange != "" {
+				rc.Request.Header.Set("Range", c.byteRange)
+			}
+			r := &fast.Request{Context: &fast.Context{RequestCtx: rc}}
+			w := &resultWriter{
+				Context:              context.TODO(),
+				ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+			}
+			defer transports.ReleaseResultResponseWriter(w.ResultResponseWriter)
+			if !handler.Match(r, r.Method(), r.Path(), r.Header()) {
+				failures <- "not matched"
+				return
+			}
+			handler.Handle(w, r)
+			if body := string(w.Body()); body != c.body {
+				failures <- fmt.Sprintf("%s: want %s, got %s", c.byteRange, c.body, body)
+			}
+		}()
+	}
+	wg.Wait()
+	close(failures)
+	for failure := range failures {
+		t.Error(failure)
+	}
+}
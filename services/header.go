@@ -25,8 +25,10 @@ type Header struct {
 	endpointId       []byte
 	deviceId         []byte
 	deviceIp         []byte
+	tenant           []byte
 	token            []byte
 	acceptedVersions versions.Intervals
+	depth            int
 	internal         bool
 }
 
@@ -50,6 +52,12 @@ func (header Header) DeviceIp() []byte {
 	return header.deviceIp
 }
 
+// Tenant
+// returns the tenant id carried by X-Fns-Tenant, it is empty when the request is not tenant-scoped.
+func (header Header) Tenant() []byte {
+	return header.tenant
+}
+
 func (header Header) Token() []byte {
 	return header.token
 }
@@ -58,6 +66,12 @@ func (header Header) AcceptedVersions() versions.Intervals {
 	return header.acceptedVersions
 }
 
+// Depth
+// returns hops from the request which came from transport, it is 0 for that request and grows by one on each internal request.
+func (header Header) Depth() int {
+	return header.depth
+}
+
 func (header Header) Internal() bool {
 	return header.internal
 }
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	hookPolicyDrop          = "drop"
+	hookPolicyBlock         = "block"
+	defaultHookQueueSize    = 1024
+	defaultHookWorkers      = 4
+	defaultHookBlockTimeout = 100 * time.Millisecond
+)
+
+// Hook
+// handles fn requests of transport after they are responded, such as audit logging, analytics or anomaly detection.
+// It runs asynchronously, ctx is detached from the request, so it outlives the request and keeps runtime, log and request values.
+// body is the encoded response, err is nil when fn succeeded.
+type Hook interface {
+	Name() string
+	Handle(ctx context.Context, endpoint []byte, fn []byte, body []byte, err error, latency time.Duration)
+}
+
+// HooksConfig
+// Policy decides what happens when the queue is full,
+// drop (default) drops the unit at once, block waits for at most BlockTimeout (default 100ms) and then drops it.
+type HooksConfig struct {
+	QueueSize    int    `json:"queueSize,omitempty" yaml:"queueSize,omitempty"`
+	Workers      int    `json:"workers,omitempty" yaml:"workers,omitempty"`
+	Policy       string `json:"policy,omitempty" yaml:"policy,omitempty"`
+	BlockTimeout string `json:"blockTimeout,omitempty" yaml:"blockTimeout,omitempty"`
+}
+
+type hookUnit struct {
+	ctx      context.Context
+	endpoint []byte
+	fn       []byte
+	body     []byte
+	err      error
+	latency  time.Duration
+}
+
+func newHookDispatcher(log logs.Logger, config HooksConfig, hooks []Hook) (dispatcher *hookDispatcher, err error) {
+	size := config.QueueSize
+	if size < 1 {
+		size = defaultHookQueueSize
+	}
+	workers := config.Workers
+	if workers < 1 {
+		workers = defaultHookWorkers
+	}
+	policy := strings.ToLower(strings.TrimSpace(config.Policy))
+	switch policy {
+	case "":
+		policy = hookPolicyDrop
+		break
+	case hookPolicyDrop, hookPolicyBlock:
+		break
+	default:
+		err = errors.Warning("fns: new hook dispatcher failed").WithCause(fmt.Errorf("policy must be drop or block")).WithMeta("policy", config.Policy)
+		return
+	}
+	blockTimeout := defaultHookBlockTimeout
+	if s := strings.TrimSpace(config.BlockTimeout); s != "" {
+		blockTimeout, err = time.ParseDuration(s)
+		if err != nil {
+			err = errors.Warning("fns: new hook dispatcher failed").WithCause(err).WithMeta("blockTimeout", s)
+			return
+		}
+	}
+	dispatcher = &hookDispatcher{
+		log:          log,
+		hooks:        hooks,
+		units:        make(chan hookUnit, size),
+		block:        policy == hookPolicyBlock,
+		blockTimeout: blockTimeout,
+		dropped:      new(atomic.Int64),
+		wg:           new(sync.WaitGroup),
+	}
+	for i := 0; i < workers; i++ {
+		dispatcher.wg.Add(1)
+		go dispatcher.work()
+	}
+	return
+}
+
+type hookDispatcher struct {
+	log          logs.Logger
+	hooks        []Hook
+	units        chan hookUnit
+	block        bool
+	blockTimeout time.Duration
+	dropped      *atomic.Int64
+	wg           *sync.WaitGroup
+}
+
+func (dispatcher *hookDispatcher) send(r context.Context, endpoint []byte, fn []byte, body []byte, err error, latency time.Duration) {
+	unit := hookUnit{
+		ctx:      context.Detach(context.Wrap(r)),
+		endpoint: append([]byte{}, endpoint...),
+		fn:       append([]byte{}, fn...),
+		body:     append([]byte{}, body...),
+		err:      err,
+		latency:  latency,
+	}
+	select {
+	case dispatcher.units <- unit:
+		return
+	default:
+	}
+	if dispatcher.block {
+		timer := time.NewTimer(dispatcher.blockTimeout)
+		select {
+		case dispatcher.units <- unit:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+	if n := dispatcher.dropped.Add(1); n%1000 == 1 && dispatcher.log.WarnEnabled() {
+		dispatcher.log.Warn().Message(fmt.Sprintf("fns: hook queue is full, %d units were dropped", n))
+	}
+}
+
+func (dispatcher *hookDispatcher) work() {
+	defer dispatcher.wg.Done()
+	for unit := range dispatcher.units {
+		for _, hook := range dispatcher.hooks {
+			dispatcher.handle(hook, unit)
+		}
+	}
+}
+
+func (dispatcher *hookDispatcher) handle(hook Hook, unit hookUnit) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			if dispatcher.log.ErrorEnabled() {
+				dispatcher.log.Error().With("hook", hook.Name()).
+					Cause(errors.Warning(fmt.Sprintf("fns: hook panicked, %v", recovered))).
+					Message("fns: hook handle failed")
+			}
+		}
+	}()
+	hook.Handle(unit.ctx, unit.endpoint, unit.fn, unit.body, unit.err, unit.latency)
+}
+
+// close
+// waits for queued units to be handled.
+func (dispatcher *hookDispatcher) close() {
+	close(dispatcher.units)
+	dispatcher.wg.Wait()
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"sync"
+	"testing"
+	"time"
+)
+
+type blockingHook struct {
+	release chan struct{}
+	mutex   sync.Mutex
+	values  []any
+}
+
+func (hook *blockingHook) Name() string {
+	return "blocking"
+}
+
+func (hook *blockingHook) Handle(ctx context.Context, _ []byte, _ []byte, _ []byte, _ error, _ time.Duration) {
+	<-hook.release
+	hook.mutex.Lock()
+	hook.values = append(hook.values, ctx.UserValue([]byte("user")))
+	hook.mutex.Unlock()
+}
+
+func TestHookDispatcher(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	hook := &blockingHook{release: make(chan struct{})}
+	dispatcher, err := newHookDispatcher(log, HooksConfig{QueueSize: 1, Workers: 1}, []Hook{hook})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	ctx.SetUserValue([]byte("user"), "u")
+	// first is taken by worker, second is queued, others are dropped
+	for i := 0; i < 4; i++ {
+		dispatcher.send(ctx, []byte("users"), []byte("get"), nil, nil, time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if dropped := dispatcher.dropped.Load(); dropped != 2 {
+		t.Fatal("expected 2 dropped, got", dropped)
+	}
+	close(hook.release)
+	dispatcher.close()
+	if len(hook.values) != 2 {
+		t.Fatal("expected 2 handled, got", len(hook.values))
+	}
+	for _, v := range hook.values {
+		if v != "u" {
+			t.Fatal("user value is lost in detached context", v)
+		}
+	}
+}
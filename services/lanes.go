@@ -0,0 +1,305 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	sc "context"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/workers"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Priority int
+
+const (
+	LowPriority Priority = iota
+	NormalPriority
+	HighPriority
+)
+
+const (
+	lowPriorityName    = "low"
+	normalPriorityName = "normal"
+	highPriorityName   = "high"
+)
+
+func (priority Priority) String() string {
+	switch priority {
+	case LowPriority:
+		return lowPriorityName
+	case HighPriority:
+		return highPriorityName
+	default:
+		return normalPriorityName
+	}
+}
+
+// ParsePriority
+// parses low, normal or high, case-insensitive.
+func ParsePriority(s string) (priority Priority, err error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case lowPriorityName:
+		priority = LowPriority
+		break
+	case normalPriorityName:
+		priority = NormalPriority
+		break
+	case highPriorityName:
+		priority = HighPriority
+		break
+	default:
+		err = errors.Warning("fns: invalid priority").WithCause(fmt.Errorf("priority must be low, normal or high")).WithMeta("priority", s)
+		break
+	}
+	return
+}
+
+// PrioritizedFn
+// Priority returns lane of fn when workers are contended, fn which is not PrioritizedFn is in normal lane.
+type PrioritizedFn interface {
+	Fn
+	Priority() Priority
+}
+
+// TaskPriority
+// returns priority of fn of FnTask, other tasks are normal.
+func TaskPriority(task workers.Task) Priority {
+	fnTask, isFnTask := task.(FnTask)
+	if !isFnTask {
+		return NormalPriority
+	}
+	prioritized, ok := fnTask.Fn.(PrioritizedFn)
+	if !ok {
+		return NormalPriority
+	}
+	priority := prioritized.Priority()
+	if priority < LowPriority || priority > HighPriority {
+		return NormalPriority
+	}
+	return priority
+}
+
+const (
+	defaultLaneCapacity = 1024
+	laneRetryInterval   = time.Millisecond
+)
+
+var (
+	defaultLaneWeights = [3]int{1, 2, 4}
+	ErrLanesClosed     = errors.Unavailable("fns: workers are closed")
+)
+
+type LanesConfig struct {
+	// Enable
+	// queues requests in priority lanes when workers are busy, instead of rejecting them with 429.
+	Enable bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// Capacity
+	// max number of queued requests of each lane, requests are rejected with 429 when lane is full, default is 1024.
+	Capacity int `json:"capacity,omitempty" yaml:"capacity,omitempty"`
+	// Weights
+	// dispatch ratio of lanes under contention, keyed by low, normal and high, default is 1:2:4.
+	// every lane takes turns by its weight, so low lane is never starved.
+	Weights map[string]int `json:"weights,omitempty" yaml:"weights,omitempty"`
+}
+
+// NewLanes
+// wraps worker with priority lanes.
+// task is dispatched to worker directly when no task of same or higher priority is waiting,
+// otherwise it is queued in its lane, then queued tasks are dispatched by weighted round-robin of lanes.
+func NewLanes(worker workers.Workers, config LanesConfig) (lanes *Lanes, err error) {
+	capacity := config.Capacity
+	if capacity < 1 {
+		capacity = defaultLaneCapacity
+	}
+	weights := defaultLaneWeights
+	for name, weight := range config.Weights {
+		priority, parseErr := ParsePriority(name)
+		if parseErr != nil {
+			err = errors.Warning("fns: new lanes failed").WithCause(parseErr)
+			return
+		}
+		if weight < 1 {
+			err = errors.Warning("fns: new lanes failed").WithCause(fmt.Errorf("weight must be greater than 0")).WithMeta("priority", name)
+			return
+		}
+		weights[priority] = weight
+	}
+	lanes = &Lanes{
+		worker:  worker,
+		weights: weights,
+		credits: weights,
+		signal:  make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	for i := range lanes.queues {
+		lanes.queues[i] = make(chan laneTask, capacity)
+	}
+	go lanes.drain()
+	return
+}
+
+type laneTask struct {
+	ctx  sc.Context
+	task workers.Task
+}
+
+type Lanes struct {
+	worker  workers.Workers
+	queues  [3]chan laneTask
+	weights [3]int
+	credits [3]int
+	signal  chan struct{}
+	closed  chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+func (lanes *Lanes) Dispatch(ctx sc.Context, task workers.Task) (ok bool) {
+	priority := TaskPriority(task)
+	if !lanes.waiting(priority) && lanes.worker.Dispatch(ctx, task) {
+		ok = true
+		return
+	}
+	select {
+	case <-lanes.closed:
+		return
+	case lanes.queues[priority] <- laneTask{ctx: ctx, task: task}:
+		ok = true
+		break
+	default:
+		return
+	}
+	select {
+	case lanes.signal <- struct{}{}:
+		break
+	default:
+		break
+	}
+	return
+}
+
+func (lanes *Lanes) MustDispatch(ctx sc.Context, task workers.Task) {
+	lanes.worker.MustDispatch(ctx, task)
+}
+
+func (lanes *Lanes) Group() (group workers.Group) {
+	group = lanes.worker.Group()
+	return
+}
+
+// Close
+// fails queued tasks with ErrLanesClosed, then closes worker.
+func (lanes *Lanes) Close() {
+	lanes.once.Do(func() {
+		close(lanes.closed)
+		<-lanes.done
+		for _, queue := range lanes.queues {
+			for len(queue) > 0 {
+				lanes.discard(<-queue, ErrLanesClosed)
+			}
+		}
+		lanes.worker.Close()
+	})
+}
+
+// Depths
+// returns number of queued tasks of each lane, keyed by name of priority.
+func (lanes *Lanes) Depths() map[string]int {
+	return map[string]int{
+		lowPriorityName:    len(lanes.queues[LowPriority]),
+		normalPriorityName: len(lanes.queues[NormalPriority]),
+		highPriorityName:   len(lanes.queues[HighPriority]),
+	}
+}
+
+func (lanes *Lanes) waiting(priority Priority) bool {
+	for p := priority; p <= HighPriority; p++ {
+		if len(lanes.queues[p]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// next
+// takes task from the highest lane which has credits, credits are refilled by weights when every non-empty lane runs out.
+// it is only called by drain, so credits are not guarded.
+func (lanes *Lanes) next() (lt laneTask, ok bool) {
+	for round := 0; round < 2; round++ {
+		for p := HighPriority; p >= LowPriority; p-- {
+			if lanes.credits[p] < 1 {
+				continue
+			}
+			select {
+			case lt = <-lanes.queues[p]:
+				lanes.credits[p]--
+				ok = true
+				return
+			default:
+				break
+			}
+		}
+		if !lanes.waiting(LowPriority) {
+			return
+		}
+		lanes.credits = lanes.weights
+	}
+	return
+}
+
+func (lanes *Lanes) drain() {
+	defer close(lanes.done)
+	for {
+		lt, ok := lanes.next()
+		if !ok {
+			select {
+			case <-lanes.closed:
+				return
+			case <-lanes.signal:
+				continue
+			}
+		}
+		for {
+			if lt.ctx.Err() != nil {
+				lanes.discard(lt, ContextErr(context.Wrap(lt.ctx)))
+				break
+			}
+			if lanes.worker.Dispatch(lt.ctx, lt.task) {
+				break
+			}
+			select {
+			case <-lanes.closed:
+				lanes.discard(lt, ErrLanesClosed)
+				return
+			case <-time.After(laneRetryInterval):
+				break
+			}
+		}
+	}
+}
+
+func (lanes *Lanes) discard(lt laneTask, cause error) {
+	if fnTask, isFnTask := lt.task.(FnTask); isFnTask {
+		fnTask.Promise.Failed(cause)
+	}
+}
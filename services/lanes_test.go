@@ -0,0 +1,116 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	sc "context"
+	"github.com/aacfactory/fns/commons/futures"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/workers"
+	"sync"
+	"testing"
+	"time"
+)
+
+type prioritizedFn struct {
+	name     string
+	priority services.Priority
+}
+
+func (fn prioritizedFn) Name() string                                 { return fn.name }
+func (fn prioritizedFn) Internal() bool                               { return false }
+func (fn prioritizedFn) Readonly() bool                               { return false }
+func (fn prioritizedFn) Handle(_ services.Request) (v any, err error) { return }
+func (fn prioritizedFn) Priority() services.Priority                  { return fn.priority }
+
+type busyWorkers struct {
+	mutex      sync.Mutex
+	busy       bool
+	dispatched []string
+}
+
+func (w *busyWorkers) Dispatch(_ sc.Context, task workers.Task) (ok bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.busy {
+		return
+	}
+	w.dispatched = append(w.dispatched, task.(services.FnTask).Fn.Name())
+	ok = true
+	return
+}
+
+func (w *busyWorkers) MustDispatch(ctx sc.Context, task workers.Task) { w.Dispatch(ctx, task) }
+func (w *busyWorkers) Group() workers.Group                           { return nil }
+func (w *busyWorkers) Close()                                         {}
+
+func (w *busyWorkers) setBusy(busy bool) {
+	w.mutex.Lock()
+	w.busy = busy
+	w.mutex.Unlock()
+}
+
+func (w *busyWorkers) names() []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return append([]string{}, w.dispatched...)
+}
+
+func TestLanes(t *testing.T) {
+	worker := &busyWorkers{busy: true}
+	lanes, err := services.NewLanes(worker, services.LanesConfig{Enable: true, Capacity: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lanes.Close()
+	dispatch := func(name string, priority services.Priority) {
+		promise, _ := futures.New()
+		if !lanes.Dispatch(sc.TODO(), services.FnTask{Fn: prioritizedFn{name: name, priority: priority}, Promise: promise}) {
+			t.Fatal("dispatch failed", name)
+		}
+	}
+	dispatch("l1", services.LowPriority)
+	for _, name := range []string{"h1", "h2", "h3", "h4", "h5", "h6"} {
+		dispatch(name, services.HighPriority)
+	}
+	dispatch("n1", services.NormalPriority)
+	if depths := lanes.Depths(); depths["low"] != 1 || depths["normal"] != 1 || depths["high"] != 6 {
+		t.Fatal("unexpected depths", depths)
+	}
+	worker.setBusy(false)
+	deadline := time.Now().Add(time.Second)
+	for len(worker.names()) < 8 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	// weights are 1:2:4, so low and normal lanes are served after four high tasks
+	expected := []string{"h1", "h2", "h3", "h4", "n1", "l1", "h5", "h6"}
+	names := worker.names()
+	if len(names) != len(expected) {
+		t.Fatal("unexpected dispatched", names)
+	}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Fatal("unexpected order", names)
+		}
+	}
+	// idle lanes dispatch directly
+	dispatch("n2", services.NormalPriority)
+	if names = worker.names(); names[len(names)-1] != "n2" {
+		t.Fatal("idle lanes must dispatch directly", names)
+	}
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"github.com/aacfactory/errors"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultAdaptiveInitialLimit = 20
+	defaultAdaptiveMinLimit     = 1
+	defaultAdaptiveMaxLimit     = 1000
+	defaultAdaptiveTolerance    = 2.0
+	defaultAdaptiveBackoff      = 0.9
+	defaultAdaptiveWindow       = 1000
+)
+
+var (
+	ErrOverloaded = errors.New(http.StatusServiceUnavailable, "***OVERLOADED***", "fns: server is overloaded, try again later")
+)
+
+// AdaptiveLimitConfig
+// in-flight cap of endpoints handler which follows latency, see AdaptiveLimiter.
+type AdaptiveLimitConfig struct {
+	Enable bool `json:"enable,omitempty" yaml:"enable,omitempty"`
+	// InitialLimit
+	// default is 20.
+	InitialLimit int `json:"initialLimit,omitempty" yaml:"initialLimit,omitempty"`
+	// MinLimit
+	// default is 1.
+	MinLimit int `json:"minLimit,omitempty" yaml:"minLimit,omitempty"`
+	// MaxLimit
+	// default is 1000.
+	MaxLimit int `json:"maxLimit,omitempty" yaml:"maxLimit,omitempty"`
+	// Tolerance
+	// latency over minimal latency * tolerance is degraded, default is 2.
+	Tolerance float64 `json:"tolerance,omitempty" yaml:"tolerance,omitempty"`
+	// Backoff
+	// ratio which limit is multiplied by when latency is degraded, it must be in (0, 1), default is 0.9.
+	Backoff float64 `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	// Window
+	// number of samples of one minimal latency window, default is 1000.
+	Window int `json:"window,omitempty" yaml:"window,omitempty"`
+}
+
+// NewAdaptiveLimiter
+// returns limiter of config, it is also the one which AdaptiveLimiterStats reports.
+func NewAdaptiveLimiter(config AdaptiveLimitConfig) (limiter *AdaptiveLimiter, err error) {
+	minLimit := config.MinLimit
+	if minLimit < 1 {
+		minLimit = defaultAdaptiveMinLimit
+	}
+	maxLimit := config.MaxLimit
+	if maxLimit < 1 {
+		maxLimit = defaultAdaptiveMaxLimit
+	}
+	if maxLimit < minLimit {
+		err = errors.Warning("fns: new adaptive limiter failed").WithCause(errors.Warning("maxLimit must not be less than minLimit"))
+		return
+	}
+	initialLimit := config.InitialLimit
+	if initialLimit < 1 {
+		initialLimit = defaultAdaptiveInitialLimit
+	}
+	initialLimit = min(max(initialLimit, minLimit), maxLimit)
+	tolerance := config.Tolerance
+	if tolerance == 0 {
+		tolerance = defaultAdaptiveTolerance
+	}
+	if tolerance < 1 {
+		err = errors.Warning("fns: new adaptive limiter failed").WithCause(errors.Warning("tolerance must not be less than 1"))
+		return
+	}
+	backoff := config.Backoff
+	if backoff == 0 {
+		backoff = defaultAdaptiveBackoff
+	}
+	if backoff <= 0 || backoff >= 1 {
+		err = errors.Warning("fns: new adaptive limiter failed").WithCause(errors.Warning("backoff must be in (0, 1)"))
+		return
+	}
+	window := config.Window
+	if window < 1 {
+		window = defaultAdaptiveWindow
+	}
+	limiter = &AdaptiveLimiter{
+		minLimit:  float64(minLimit),
+		maxLimit:  float64(maxLimit),
+		tolerance: tolerance,
+		backoff:   backoff,
+		window:    window,
+		limit:     float64(initialLimit),
+	}
+	limiter.current.Store(int64(initialLimit))
+	adaptiveLimiter.Store(limiter)
+	return
+}
+
+// AdaptiveLimiter
+// is an AIMD limiter which takes latency as the signal of congestion.
+// the baseline is the minimal latency of the previous window of samples, so it follows slow changes of fns.
+// a request is degraded when its latency is over baseline * tolerance, or it fails with timeout, 429 or 503,
+// then limit is multiplied by backoff, otherwise limit grows by 1/limit when in-flight is over half of limit,
+// that is one per limit of requests, and limit is kept in [minLimit, maxLimit].
+// request is shed with ErrOverloaded when in-flight reaches limit.
+type AdaptiveLimiter struct {
+	minLimit  float64
+	maxLimit  float64
+	tolerance float64
+	backoff   float64
+	window    int
+	inflight  atomic.Int64
+	current   atomic.Int64
+	shed      atomic.Int64
+	mutex     sync.Mutex
+	limit     float64
+	samples   int
+	windowMin time.Duration
+	baseline  time.Duration
+}
+
+// Acquire
+// returns false when in-flight reaches limit, Release must be called after request when it returns true.
+func (limiter *AdaptiveLimiter) Acquire() (ok bool) {
+	if limiter.inflight.Add(1) > limiter.current.Load() {
+		limiter.inflight.Add(-1)
+		limiter.shed.Add(1)
+		return
+	}
+	ok = true
+	return
+}
+
+// Release
+// feeds latency and error of request back to limiter.
+func (limiter *AdaptiveLimiter) Release(latency time.Duration, err error) {
+	inflight := limiter.inflight.Add(-1) + 1
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	if limiter.windowMin == 0 || latency < limiter.windowMin {
+		limiter.windowMin = latency
+	}
+	limiter.samples++
+	if limiter.samples >= limiter.window {
+		limiter.baseline = limiter.windowMin
+		limiter.windowMin = 0
+		limiter.samples = 0
+	}
+	baseline := limiter.baseline
+	if baseline == 0 {
+		baseline = limiter.windowMin
+	}
+	limit := limiter.limit
+	if overloaded(err) || float64(latency) > float64(baseline)*limiter.tolerance {
+		limit = limit * limiter.backoff
+	} else if float64(inflight)*2 >= limit {
+		limit = limit + 1/limit
+	}
+	limit = math.Min(math.Max(limit, limiter.minLimit), limiter.maxLimit)
+	limiter.limit = limit
+	limiter.current.Store(int64(limit))
+}
+
+func (limiter *AdaptiveLimiter) Stats() AdaptiveLimiterStats {
+	limiter.mutex.Lock()
+	baseline := limiter.baseline
+	if baseline == 0 {
+		baseline = limiter.windowMin
+	}
+	limiter.mutex.Unlock()
+	return AdaptiveLimiterStats{
+		Limit:    limiter.current.Load(),
+		Inflight: limiter.inflight.Load(),
+		Shed:     limiter.shed.Load(),
+		Baseline: baseline,
+	}
+}
+
+func overloaded(err error) bool {
+	if err == nil {
+		return false
+	}
+	switch errors.Wrap(err).Code() {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+var (
+	adaptiveLimiter atomic.Pointer[AdaptiveLimiter]
+)
+
+type AdaptiveLimiterStats struct {
+	// Limit
+	// current in-flight cap.
+	Limit    int64 `json:"limit" avro:"limit"`
+	Inflight int64 `json:"inflight" avro:"inflight"`
+	// Shed
+	// number of requests which are rejected with ErrOverloaded.
+	Shed int64 `json:"shed" avro:"shed"`
+	// Baseline
+	// minimal latency which degraded latency is compared with.
+	Baseline time.Duration `json:"baseline" avro:"baseline"`
+}
+
+// AdaptiveLimitStats
+// returns stats of adaptive limiter of endpoints handler, ok is false when it is disabled.
+func AdaptiveLimitStats() (stats AdaptiveLimiterStats, ok bool) {
+	limiter := adaptiveLimiter.Load()
+	if limiter == nil {
+		return
+	}
+	stats, ok = limiter.Stats(), true
+	return
+}
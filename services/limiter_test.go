@@ -0,0 +1,66 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"github.com/aacfactory/fns/services"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveLimiter(t *testing.T) {
+	limiter, err := services.NewAdaptiveLimiter(services.AdaptiveLimitConfig{Enable: true, InitialLimit: 4, MinLimit: 2, MaxLimit: 8, Window: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if !limiter.Acquire() {
+			t.Fatal("acquire failed under limit")
+		}
+	}
+	if limiter.Acquire() {
+		t.Fatal("acquire succeed over limit")
+	}
+	// healthy and busy, limit grows
+	for i := 0; i < 4; i++ {
+		limiter.Release(10*time.Millisecond, nil)
+	}
+	for i := 0; i < 40; i++ {
+		limiter.Acquire()
+		limiter.Acquire()
+		limiter.Acquire()
+		limiter.Release(10*time.Millisecond, nil)
+		limiter.Release(10*time.Millisecond, nil)
+		limiter.Release(10*time.Millisecond, nil)
+	}
+	grown := limiter.Stats()
+	if grown.Limit <= 4 || grown.Shed != 1 || grown.Inflight != 0 || grown.Baseline != 10*time.Millisecond {
+		t.Fatal("unexpected stats after healthy requests", grown)
+	}
+	// degraded, limit shrinks to min
+	for i := 0; i < 40; i++ {
+		limiter.Acquire()
+		limiter.Release(time.Second, nil)
+	}
+	if shrunk := limiter.Stats(); shrunk.Limit != 2 {
+		t.Fatal("unexpected limit after degraded requests", shrunk)
+	}
+	if stats, ok := services.AdaptiveLimitStats(); !ok || stats.Limit != 2 {
+		t.Fatal("limiter is not registered")
+	}
+}
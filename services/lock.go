@@ -0,0 +1,47 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/shareds"
+	"time"
+)
+
+var (
+	// runtimeContextKey is key of runtime in context, see runtime.With.
+	runtimeContextKey = []byte("@fns:context:runtime")
+)
+
+type sharedRuntime interface {
+	Shared() shareds.Shared
+}
+
+// Lock
+// takes a lease of key in shared store of cluster, the lease is renewed while held, unlock releases it.
+// it returns shareds.ErrLockContended when the lease is held by another until deadline of ctx or ttl, see shareds.Lock.
+func Lock(ctx context.Context, key []byte, ttl time.Duration) (unlock func(), err error) {
+	rt, ok := ctx.LocalValue(runtimeContextKey).(sharedRuntime)
+	if !ok {
+		err = errors.Warning("fns: lock failed").WithCause(errors.Warning("there is no runtime in context"))
+		return
+	}
+	unlock, err = shareds.Lock(ctx, rt.Shared().Store(), key, ttl)
+	return
+}
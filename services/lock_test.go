@@ -0,0 +1,66 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/shareds"
+	"testing"
+	"time"
+)
+
+type sharedRuntime struct {
+	shared shareds.Shared
+}
+
+func (rt *sharedRuntime) Shared() shareds.Shared {
+	return rt.shared
+}
+
+func TestLock(t *testing.T) {
+	if _, err := services.Lock(context.TODO(), []byte("lock"), time.Second); err == nil {
+		t.Fatal("lock without runtime must fail")
+	}
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	ctx := context.TODO()
+	ctx.SetLocalValue([]byte("@fns:context:runtime"), &sharedRuntime{shared: shared})
+	unlock, err := services.Lock(ctx, []byte("lock"), 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, contendedErr := services.Lock(ctx, []byte("lock"), 200*time.Millisecond)
+	if contendedErr == nil || !errors.Wrap(contendedErr).Contains(shareds.ErrLockContended) {
+		t.Fatal("want ErrLockContended, got", contendedErr)
+	}
+	unlock()
+	unlock, err = services.Lock(ctx, []byte("lock"), 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/logs"
+	"time"
+)
+
+// WithRequestLog
+// set a log that carries requestId, service, fn and deviceId of the request into context,
+// so logs.Load(r) returns a correlated log.
+func WithRequestLog(r Request, log logs.Logger) {
+	service, fn := r.Fn()
+	log = log.With("service", bytex.ToString(service)).With("fn", bytex.ToString(fn))
+	header := r.Header()
+	if requestId := header.RequestId(); len(requestId) > 0 {
+		log = log.With("requestId", string(requestId))
+	}
+	if deviceId := header.DeviceId(); len(deviceId) > 0 {
+		log = log.With("deviceId", string(deviceId))
+	}
+	logs.With(r, log)
+}
+
+// LogSlowRequest
+// logs a warning with service, fn, latency and requestId when latency exceeds threshold, non-positive threshold disables it.
+func LogSlowRequest(log logs.Logger, threshold time.Duration, latency time.Duration, service []byte, fn []byte, requestId []byte) (logged bool) {
+	if threshold <= 0 || latency <= threshold || !log.WarnEnabled() {
+		return
+	}
+	log.Warn().
+		With("service", bytex.ToString(service)).
+		With("fn", bytex.ToString(fn)).
+		With("latency", latency.String()).
+		With("requestId", string(requestId)).
+		Message("fns: slow request")
+	logged = true
+	return
+}
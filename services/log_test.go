@@ -0,0 +1,111 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	sc "context"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	alogs "github.com/aacfactory/logs"
+	"sync"
+	"testing"
+	"time"
+)
+
+type captureWriter struct {
+	mutex   sync.Mutex
+	entries []alogs.Entry
+}
+
+func (w *captureWriter) Name() string {
+	return "capture"
+}
+
+func (w *captureWriter) Construct(_ logs.WriterOptions) (err error) {
+	return
+}
+
+func (w *captureWriter) Write(entry alogs.Entry) {
+	w.mutex.Lock()
+	w.entries = append(w.entries, entry)
+	w.mutex.Unlock()
+}
+
+func (w *captureWriter) Shutdown(_ context.Context) {
+}
+
+func (w *captureWriter) Close() (err error) {
+	return
+}
+
+func TestWithRequestLog(t *testing.T) {
+	writer := &captureWriter{}
+	log, logErr := logs.New(logs.Config{DisableConsole: true}, []logs.Writer{writer})
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	ctx := context.TODO()
+	r := services.NewRequest(
+		ctx, []byte("users"), []byte("get"), nil,
+		services.WithRequestId([]byte("rid")), services.WithDeviceId([]byte("did")),
+	)
+	services.WithRequestLog(r, log)
+	logs.Load(r).Info().Message("hello")
+	_ = log.Shutdown(sc.TODO())
+	if len(writer.entries) != 1 {
+		t.Fatal("entry was not written")
+	}
+	expects := map[string]string{"requestId": "rid", "deviceId": "did", "service": "users", "fn": "get"}
+	for _, field := range writer.entries[0].Fields {
+		if expect, has := expects[field.Key]; has && expect == field.Value {
+			delete(expects, field.Key)
+		}
+	}
+	if len(expects) > 0 {
+		t.Fatal("fields are absent:", expects)
+	}
+}
+
+func TestLogSlowRequest(t *testing.T) {
+	writer := &captureWriter{}
+	log, logErr := logs.New(logs.Config{DisableConsole: true, SlowThreshold: "500ms"}, []logs.Writer{writer})
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	threshold, thresholdErr := (&logs.Config{SlowThreshold: "500ms"}).GetSlowThreshold()
+	if thresholdErr != nil {
+		t.Fatal(thresholdErr)
+	}
+	if services.LogSlowRequest(log, threshold, 100*time.Millisecond, []byte("users"), []byte("get"), []byte("rid")) {
+		t.Fatal("request below threshold should not be logged")
+	}
+	if !services.LogSlowRequest(log, threshold, 600*time.Millisecond, []byte("users"), []byte("get"), []byte("rid")) {
+		t.Fatal("request above threshold should be logged")
+	}
+	if services.LogSlowRequest(log, 0, time.Hour, []byte("users"), []byte("get"), []byte("rid")) {
+		t.Fatal("zero threshold should disable slow request log")
+	}
+	_ = log.Shutdown(sc.TODO())
+	if len(writer.entries) != 1 {
+		t.Fatal("expected one warning, got", len(writer.entries))
+	}
+	if writer.entries[0].Level != alogs.WarnLevel {
+		t.Fatal("slow request should be logged as warning")
+	}
+}
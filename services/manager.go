@@ -33,15 +33,42 @@ import (
 	"time"
 )
 
-func New(id string, version versions.Version, log logs.Logger, config Config, worker workers.Workers) EndpointsManager {
+// New
+// options sets shared dependencies of services, see WithDependencies.
+func New(id string, version versions.Version, log logs.Logger, config Config, worker workers.Workers, options ...ManagerOption) EndpointsManager {
+	opt := ManagerOptions{}
+	for _, option := range options {
+		option(&opt)
+	}
 	return &Manager{
 		log:     log,
 		config:  config,
 		id:      id,
 		version: version,
 		values:  make(Services, 0, 1),
-		infos:   make(EndpointInfos, 0, 1),
-		worker:  worker,
+		order:   make(Services, 0, 1),
+		readiness: &readinessRecorder{
+			values: make([]Readiness, 0, 1),
+		},
+		infos:        make(EndpointInfos, 0, 1),
+		worker:       worker,
+		dependencies: newDependencies(id, version, log.With("fns", "dependencies"), opt.dependenciesConfig, opt.providers),
+	}
+}
+
+type ManagerOptions struct {
+	dependenciesConfig Config
+	providers          []Provider
+}
+
+type ManagerOption func(options *ManagerOptions)
+
+// WithDependencies
+// sets providers of shared dependencies and their config, which is keyed by names of providers.
+func WithDependencies(config Config, providers ...Provider) ManagerOption {
+	return func(options *ManagerOptions) {
+		options.dependenciesConfig = config
+		options.providers = append(options.providers, providers...)
 	}
 }
 
@@ -49,17 +76,31 @@ type EndpointsManager interface {
 	Endpoints
 	Add(service Service) (err error)
 	Listen(ctx context.Context) (err error)
+	Warmup(ctx context.Context) (err error)
+	Readiness() (v Readinesses)
 	Shutdown(ctx context.Context)
 }
 
 type Manager struct {
-	log     logs.Logger
-	config  Config
-	id      string
-	version versions.Version
-	values  Services
-	infos   EndpointInfos
-	worker  workers.Workers
+	log          logs.Logger
+	config       Config
+	id           string
+	version      versions.Version
+	mutex        sync.RWMutex
+	values       Services
+	order        Services
+	readiness    *readinessRecorder
+	infos        EndpointInfos
+	worker       workers.Workers
+	dependencies *dependencies
+	// listenCtx is ctx of Listen, reloaded services listen with it, cause ctx of Reload is ended with request.
+	listenCtx context.Context
+}
+
+// Dependencies
+// returns shared dependencies of services, see WithDependencies.
+func (manager *Manager) Dependencies() Dependencies {
+	return manager.dependencies
 }
 
 func (manager *Manager) Add(service Service) (err error) {
@@ -74,25 +115,24 @@ func (manager *Manager) Add(service Service) (err error) {
 		return
 	}
 	constructErr := service.Construct(Options{
-		Id:      manager.id,
-		Version: manager.version,
-		Log:     manager.log.With("service", name),
-		Config:  config,
+		Id:           manager.id,
+		Version:      manager.version,
+		Log:          manager.log.With("service", name),
+		Config:       config,
+		Dependencies: manager.dependencies,
 	})
 	if constructErr != nil {
 		err = errors.Warning("fns: services add service failed").WithMeta("service", name).WithCause(constructErr)
 		return
 	}
 	manager.values = manager.values.Add(service)
+	manager.order = append(manager.order, service)
+	manager.readiness.add(service.Name())
 	// info
 	internal := service.Internal()
 	functions := make(FnInfos, 0, len(service.Functions()))
 	for _, fn := range service.Functions() {
-		functions = append(functions, FnInfo{
-			Name:     fn.Name(),
-			Readonly: fn.Readonly(),
-			Internal: internal || fn.Internal(),
-		})
+		functions = append(functions, NewFnInfo(fn, internal))
 	}
 	sort.Sort(functions)
 	manager.infos = append(manager.infos, EndpointInfo{
@@ -108,7 +148,9 @@ func (manager *Manager) Add(service Service) (err error) {
 }
 
 func (manager *Manager) Info() (infos EndpointInfos) {
+	manager.mutex.RLock()
 	infos = manager.infos
+	manager.mutex.RUnlock()
 	return
 }
 
@@ -132,7 +174,9 @@ func (manager *Manager) Get(_ context.Context, name []byte, options ...EndpointG
 			}
 		}
 	}
+	manager.mutex.RLock()
 	endpoint, has = manager.values.Find(name)
+	manager.mutex.RUnlock()
 	return
 }
 
@@ -148,6 +192,13 @@ func (manager *Manager) RequestAsync(ctx context.Context, name []byte, fn []byte
 	}
 	// request
 	req := NewRequest(ctx, name, fn, param, options...)
+	// depth
+	if err = CheckRequestDepth(req); err != nil {
+		if manager.log.WarnEnabled() {
+			manager.log.Warn().Cause(err).Message("fns: request is too deep, it may be a cycle of services")
+		}
+		return
+	}
 	// get endpoint
 	var endpointGetOptions []EndpointGetOption
 	if endpointId := req.Header().EndpointId(); len(endpointId) > 0 {
@@ -175,8 +226,11 @@ func (manager *Manager) RequestAsync(ctx context.Context, name []byte, fn []byte
 			WithMeta("fn", bytex.ToString(fn))
 		return
 	}
+	if err = CheckFnVersions(name, function, req.Header().AcceptedVersions()); err != nil {
+		return
+	}
 	// log
-	logs.With(req, manager.log.With("service", bytex.ToString(name)).With("fn", bytex.ToString(fn)))
+	WithRequestLog(req, manager.log)
 	// components
 	service, ok := endpoint.(Service)
 	if ok {
@@ -226,6 +280,13 @@ func (manager *Manager) Request(ctx context.Context, name []byte, fn []byte, par
 	// request
 	req := AcquireRequest(ctx, name, fn, param, options...)
 	defer ReleaseRequest(req)
+	// depth
+	if err = CheckRequestDepth(req); err != nil {
+		if manager.log.WarnEnabled() {
+			manager.log.Warn().Cause(err).Message("fns: request is too deep, it may be a cycle of services")
+		}
+		return
+	}
 	// get endpoint
 	var endpointGetOptions []EndpointGetOption
 	if endpointId := req.Header().EndpointId(); len(endpointId) > 0 {
@@ -253,8 +314,11 @@ func (manager *Manager) Request(ctx context.Context, name []byte, fn []byte, par
 			WithMeta("fn", bytex.ToString(fn))
 		return
 	}
+	if err = CheckFnVersions(name, function, req.Header().AcceptedVersions()); err != nil {
+		return
+	}
 	// log
-	logs.With(req, manager.log.With("service", bytex.ToString(name)).With("fn", bytex.ToString(fn)))
+	WithRequestLog(req, manager.log)
 	// components
 	service, ok := endpoint.(Service)
 	if ok {
@@ -287,6 +351,7 @@ func (manager *Manager) Request(ctx context.Context, name []byte, fn []byte, par
 }
 
 func (manager *Manager) Listen(ctx context.Context) (err error) {
+	manager.listenCtx = ctx
 	errs := errors.MakeErrors()
 	for _, endpoint := range manager.values {
 		ln, ok := endpoint.(Listenable)
@@ -324,25 +389,163 @@ func (manager *Manager) Listen(ctx context.Context) (err error) {
 	return
 }
 
+// Reload
+// constructs a new instance of the named service by Renewable with the same config, swaps it in, then shuts the old one down and listens the new one,
+// it is for development only. services which are not Renewable are rejected.
+// when constructing the new instance failed, the old one keeps serving.
+func (manager *Manager) Reload(ctx context.Context, name string) (err error) {
+	manager.mutex.RLock()
+	service, has := manager.values.Find(bytex.FromString(name))
+	manager.mutex.RUnlock()
+	if !has {
+		err = errors.Warning("fns: services reload service failed").WithMeta("service", name).WithCause(fmt.Errorf("service was not found"))
+		return
+	}
+	renewable, ok := service.(Renewable)
+	if !ok {
+		err = errors.Warning("fns: services reload service failed").WithMeta("service", name).WithCause(fmt.Errorf("service is not renewable"))
+		return
+	}
+	config, configErr := manager.config.Get(name)
+	if configErr != nil {
+		err = errors.Warning("fns: services reload service failed").WithMeta("service", name).WithCause(configErr)
+		return
+	}
+	renewed := renewable.Renew()
+	if renewed == nil || renewed.Name() != service.Name() {
+		err = errors.Warning("fns: services reload service failed").WithMeta("service", name).WithCause(fmt.Errorf("renewed service is nil or has another name"))
+		return
+	}
+	constructErr := renewed.Construct(Options{
+		Id:           manager.id,
+		Version:      manager.version,
+		Log:          manager.log.With("service", name),
+		Config:       config,
+		Dependencies: manager.dependencies,
+	})
+	if constructErr != nil {
+		err = errors.Warning("fns: services reload service failed").WithMeta("service", name).WithCause(constructErr)
+		return
+	}
+	manager.replace(renewed)
+	// requests which got the old one are served by it until it is shutdown
+	manager.shutdown(ctx, service)
+	service = renewed
+	if ln, ok := service.(Listenable); ok && manager.listenCtx != nil {
+		lnCtx := context.WithValue(manager.listenCtx, "listener", name)
+		logs.With(lnCtx, manager.log.With("service", name))
+		if components := ln.Components(); len(components) > 0 {
+			WithComponents(lnCtx, bytex.FromString(name), components)
+		}
+		go func(ctx context.Context, ln Listenable) {
+			if lnErr := ln.Listen(ctx); lnErr != nil {
+				if manager.log.WarnEnabled() {
+					manager.log.Warn().With("service", ln.Name()).Cause(lnErr).Message("fns: service listen failed after reload")
+				}
+			}
+		}(lnCtx, ln)
+	}
+	if manager.log.DebugEnabled() {
+		manager.log.Debug().With("service", name).Message("fns: service is reloaded")
+	}
+	return
+}
+
+// replace
+// swaps the deployed service which has same name with service.
+// values, order and infos are copied before swapping, cause slices returned by Info and read by Get are not guarded.
+func (manager *Manager) replace(service Service) {
+	name := service.Name()
+	internal := service.Internal()
+	functions := make(FnInfos, 0, len(service.Functions()))
+	for _, fn := range service.Functions() {
+		functions = append(functions, NewFnInfo(fn, internal))
+	}
+	sort.Sort(functions)
+	document := service.Document()
+
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	values := make(Services, len(manager.values))
+	copy(values, manager.values)
+	for i, value := range values {
+		if value.Name() == name {
+			values[i] = service
+			break
+		}
+	}
+	order := make(Services, len(manager.order))
+	copy(order, manager.order)
+	for i, value := range order {
+		if value.Name() == name {
+			order[i] = service
+			break
+		}
+	}
+	infos := make(EndpointInfos, len(manager.infos))
+	copy(infos, manager.infos)
+	for i, info := range infos {
+		if info.Name == name {
+			infos[i].Internal = internal
+			infos[i].Functions = functions
+			infos[i].Document = document
+			break
+		}
+	}
+	manager.values = values
+	manager.order = order
+	manager.infos = infos
+}
+
+// Shutdown
+// shuts services down one by one in reverse order of deployment, so a service is closed before the services it was built upon.
+// Each shutdown is bounded by DefaultShutdownTimeout or by Drainable.ShutdownTimeout, and by ctx.
 func (manager *Manager) Shutdown(ctx context.Context) {
-	wg := new(sync.WaitGroup)
+	manager.mutex.RLock()
+	order := manager.order
+	manager.mutex.RUnlock()
+	for i := len(order) - 1; i > -1; i-- {
+		if ctx.Err() != nil {
+			if manager.log.WarnEnabled() {
+				manager.log.Warn().Cause(ctx.Err()).Message(fmt.Sprintf("fns: %d services were not shutdown", i+1))
+			}
+			return
+		}
+		manager.shutdown(ctx, order[i])
+	}
+	manager.dependencies.Shutdown(ctx)
+}
+
+func (manager *Manager) shutdown(ctx context.Context, service Service) {
+	timeout := DefaultShutdownTimeout
+	if drainable, ok := service.(Drainable); ok {
+		if n := drainable.ShutdownTimeout(); n > 0 {
+			timeout = n
+		}
+	}
+	sctx, cancel := context.WithTimeout(ctx, timeout)
 	ch := make(chan struct{}, 1)
-	for _, endpoint := range manager.values {
-		wg.Add(1)
-		go func(ctx context.Context, endpoint Endpoint, wg *sync.WaitGroup) {
-			endpoint.Shutdown(ctx)
-			wg.Done()
-		}(ctx, endpoint, wg)
-	}
-	go func(ch chan struct{}, wg *sync.WaitGroup) {
-		wg.Wait()
+	beg := time.Now()
+	go func(ctx context.Context, service Service, ch chan struct{}) {
+		service.Shutdown(ctx)
 		ch <- struct{}{}
 		close(ch)
-	}(ch, wg)
+	}(sctx, service, ch)
 	select {
-	case <-ctx.Done():
+	case <-sctx.Done():
+		if manager.log.WarnEnabled() {
+			manager.log.Warn().With("service", service.Name()).Cause(sctx.Err()).
+				Message(fmt.Sprintf("fns: service was not shutdown in %s, skip it", timeout))
+		}
 		break
 	case <-ch:
+		if latency := time.Since(beg); latency > timeout/2 {
+			if manager.log.WarnEnabled() {
+				manager.log.Warn().With("service", service.Name()).
+					Message(fmt.Sprintf("fns: service shutdown is slow, it took %s of %s", latency, timeout))
+			}
+		}
 		break
 	}
+	cancel()
 }
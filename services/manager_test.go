@@ -0,0 +1,274 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"fmt"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/crons"
+	"github.com/aacfactory/fns/services/features"
+	"sync"
+	"testing"
+	"time"
+)
+
+type shutdownRecorder struct {
+	mutex sync.Mutex
+	names []string
+}
+
+func (r *shutdownRecorder) record(name string) {
+	r.mutex.Lock()
+	r.names = append(r.names, name)
+	r.mutex.Unlock()
+}
+
+type orderedService struct {
+	services.Abstract
+	recorder *shutdownRecorder
+	delay    time.Duration
+	timeout  time.Duration
+}
+
+func (svc *orderedService) Shutdown(ctx context.Context) {
+	if svc.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(svc.delay):
+		}
+	}
+	svc.recorder.record(svc.Name())
+}
+
+func (svc *orderedService) ShutdownTimeout() time.Duration {
+	return svc.timeout
+}
+
+func TestManager_Shutdown(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	recorder := &shutdownRecorder{}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	for _, svc := range []*orderedService{
+		{Abstract: services.NewAbstract("b", true), recorder: recorder},
+		{Abstract: services.NewAbstract("a", true), recorder: recorder},
+		{Abstract: services.NewAbstract("slow", true), recorder: recorder, delay: time.Second, timeout: 10 * time.Millisecond},
+		{Abstract: services.NewAbstract("c", true), recorder: recorder},
+	} {
+		if err := manager.Add(svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	beg := time.Now()
+	manager.Shutdown(context.TODO())
+	if time.Since(beg) > 500*time.Millisecond {
+		t.Fatal("slow service was not bounded")
+	}
+	if len(recorder.names) != 3 || recorder.names[0] != "c" || recorder.names[1] != "a" || recorder.names[2] != "b" {
+		t.Fatal("shutdown order is not reversed", recorder.names)
+	}
+}
+
+type warmableService struct {
+	services.Abstract
+	release chan struct{}
+}
+
+func (svc *warmableService) Warmup(_ context.Context) (err error) {
+	<-svc.release
+	return
+}
+
+func TestManager_Warmup(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	warmable := &warmableService{Abstract: services.NewAbstract("pool", true), release: make(chan struct{})}
+	for _, svc := range []services.Service{warmable, &orderedService{Abstract: services.NewAbstract("plain", true)}} {
+		if err := manager.Add(svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- manager.Warmup(context.TODO())
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if readiness := manager.Readiness(); readiness.Ready {
+		t.Fatal("ready before warmup finished", readiness)
+	}
+	close(warmable.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if readiness := manager.Readiness(); !readiness.Ready {
+		t.Fatal("not ready after warmup", readiness)
+	}
+}
+
+type reloadableService struct {
+	orderedService
+	constructs *int
+}
+
+func (svc *reloadableService) Construct(options services.Options) (err error) {
+	*svc.constructs++
+	return svc.Abstract.Construct(options)
+}
+
+func (svc *reloadableService) Renew() services.Service {
+	return &reloadableService{orderedService: orderedService{Abstract: services.NewAbstract(svc.Name(), true), recorder: svc.recorder}, constructs: svc.constructs}
+}
+
+func TestManager_Reload(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	recorder := &shutdownRecorder{}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	constructs := 0
+	svc := &reloadableService{orderedService: orderedService{Abstract: services.NewAbstract("dev", true), recorder: recorder}, constructs: &constructs}
+	if err := manager.Add(svc); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Add(&orderedService{Abstract: services.NewAbstract("plain", true), recorder: recorder}); err != nil {
+		t.Fatal(err)
+	}
+	reloadable, ok := manager.(services.Reloadable)
+	if !ok {
+		t.Fatal("manager is not reloadable")
+	}
+	// run with -race, requests find the service while it is reloaded
+	stop := make(chan struct{})
+	readers := sync.WaitGroup{}
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_, _ = manager.Get(context.TODO(), []byte("dev"))
+				_ = manager.Info()
+			}
+		}
+	}()
+	err := reloadable.Reload(context.TODO(), "dev")
+	close(stop)
+	readers.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if constructs != 2 || len(recorder.names) != 1 || recorder.names[0] != "dev" {
+		t.Fatal("service was not reloaded", constructs, recorder.names)
+	}
+	endpoint, has := manager.Get(context.TODO(), []byte("dev"))
+	if !has || endpoint == services.Endpoint(svc) {
+		t.Fatal("reloaded service was not swapped in")
+	}
+	if err := reloadable.Reload(context.TODO(), "plain"); err == nil {
+		t.Fatal("reload not renewable service should fail")
+	}
+	if err := reloadable.Reload(context.TODO(), "missing"); err == nil {
+		t.Fatal("reload missing service should fail")
+	}
+}
+
+// brokenService
+// renewed instances of it fail to construct.
+type brokenService struct {
+	orderedService
+	broken bool
+}
+
+func (svc *brokenService) Renew() services.Service {
+	return &brokenService{orderedService: orderedService{Abstract: services.NewAbstract(svc.Name(), true), recorder: svc.recorder}, broken: true}
+}
+
+func (svc *brokenService) Construct(options services.Options) (err error) {
+	if svc.broken {
+		err = fmt.Errorf("broken")
+		return
+	}
+	return svc.Abstract.Construct(options)
+}
+
+func TestManager_ReloadFailed(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	recorder := &shutdownRecorder{}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	svc := &brokenService{orderedService: orderedService{Abstract: services.NewAbstract("broken", true), recorder: recorder}}
+	if err := manager.Add(svc); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.(services.Reloadable).Reload(context.TODO(), "broken"); err == nil {
+		t.Fatal("reload should fail")
+	}
+	if len(recorder.names) != 0 {
+		t.Fatal("old service was shutdown", recorder.names)
+	}
+	endpoint, has := manager.Get(context.TODO(), []byte("broken"))
+	if !has || endpoint != services.Endpoint(svc) {
+		t.Fatal("old service was not kept")
+	}
+}
+
+func TestManager_ReloadBuiltin(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	for _, svc := range []services.Service{features.New(features.ConfigFlags()), crons.New()} {
+		if err := manager.Add(svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reloadable := manager.(services.Reloadable)
+	for i := 0; i < 2; i++ {
+		for _, name := range []string{"features", "crons"} {
+			if err := reloadable.Reload(context.TODO(), name); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, info := range manager.Info() {
+		if info.Name == "features" && len(info.Functions) != 1 {
+			t.Fatal("functions of features were duplicated", len(info.Functions))
+		}
+	}
+	endpoint, _ := manager.Get(context.TODO(), []byte("features"))
+	if n := len(endpoint.Functions()); n != 1 {
+		t.Fatal("functions of features were duplicated", n)
+	}
+	// old instances were shutdown by reload, so shutdown must close the current ones only
+	manager.Shutdown(context.TODO())
+}
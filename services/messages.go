@@ -0,0 +1,129 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Messages
+// catalog of localized messages of errors, keyed by name of error and then by language tag,
+// such as {"***NOT FOUND***": {"zh-CN": "不存在", "en": "not found"}}.
+type Messages map[string]map[string]string
+
+// Localize
+// returns the message of name which matches acceptLanguage best,
+// a tag of acceptLanguage matches a language of catalog exactly or by primary subtag, such as zh-CN and zh.
+func (messages Messages) Localize(name string, acceptLanguage []byte) (message string, has bool) {
+	if len(messages) == 0 || len(acceptLanguage) == 0 {
+		return
+	}
+	languages, hasName := messages[name]
+	if !hasName || len(languages) == 0 {
+		return
+	}
+	for _, tag := range parseAcceptLanguage(bytex.ToString(acceptLanguage)) {
+		for language, localized := range languages {
+			if strings.EqualFold(language, tag) {
+				message, has = localized, true
+				return
+			}
+		}
+		primary := primaryLanguage(tag)
+		for language, localized := range languages {
+			if strings.EqualFold(primaryLanguage(language), primary) {
+				message, has = localized, true
+				return
+			}
+		}
+	}
+	return
+}
+
+type acceptedLanguage struct {
+	tag     string
+	quality float64
+}
+
+// parseAcceptLanguage
+// returns tags of value ordered by quality, wildcard and tags whose quality is 0 are dropped.
+func parseAcceptLanguage(value string) (tags []string) {
+	items := strings.Split(value, ",")
+	accepted := make([]acceptedLanguage, 0, len(items))
+	for _, item := range items {
+		tag, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if q, hasQ := strings.CutPrefix(strings.TrimSpace(params), "q="); hasQ {
+			n, parseErr := strconv.ParseFloat(strings.TrimSpace(q), 64)
+			if parseErr != nil {
+				continue
+			}
+			quality = n
+		}
+		if quality <= 0 {
+			continue
+		}
+		accepted = append(accepted, acceptedLanguage{tag: tag, quality: quality})
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+	tags = make([]string, 0, len(accepted))
+	for _, language := range accepted {
+		tags = append(tags, language.tag)
+	}
+	return
+}
+
+func primaryLanguage(tag string) string {
+	primary, _, _ := strings.Cut(tag, "-")
+	return primary
+}
+
+// LocalizeError
+// returns err with the message of messages which matches acceptLanguage, name and code of err are kept,
+// err is returned as it is when no message is matched.
+func LocalizeError(err error, messages Messages, acceptLanguage []byte) error {
+	if err == nil || len(messages) == 0 || len(acceptLanguage) == 0 {
+		return err
+	}
+	codeErr := errors.Wrap(err)
+	message, has := messages.Localize(codeErr.Name(), acceptLanguage)
+	if !has || message == codeErr.Message() {
+		return err
+	}
+	p, encodeErr := jsons.Marshal(codeErr)
+	if encodeErr != nil {
+		return err
+	}
+	impl := errors.CodeErrorImpl{}
+	if decodeErr := jsons.Unmarshal(p, &impl); decodeErr != nil {
+		return err
+	}
+	impl.Message_ = message
+	return impl
+}
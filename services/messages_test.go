@@ -0,0 +1,47 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/services"
+	"testing"
+)
+
+func TestLocalizeError(t *testing.T) {
+	cause := services.NotFound("user was not found")
+	messages := services.Messages{
+		cause.Name(): {"zh-CN": "不存在", "en": "not found"},
+	}
+	cases := map[string]string{
+		"zh-CN,zh;q=0.9,en;q=0.8":  "不存在",
+		"en-US;q=0.5, zh-TW;q=0.9": "不存在",
+		"en-GB":                    "not found",
+		"fr, *":                    "user was not found",
+		"zh;q=0,en;q=0.1":          "not found",
+	}
+	for acceptLanguage, expected := range cases {
+		err := errors.Wrap(services.LocalizeError(cause, messages, []byte(acceptLanguage)))
+		if err.Message() != expected {
+			t.Fatal(acceptLanguage, "expected", expected, "but", err.Message())
+		}
+		if err.Code() != cause.Code() || err.Name() != cause.Name() {
+			t.Fatal("code or name is changed")
+		}
+	}
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package metrics
+
+import (
+	"bytes"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/transports"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	slaPath   = []byte("/application/metrics")
+	connsPath = []byte("/application/metrics/conns")
+	slaStats  = sync.Map{}
+)
+
+type slaCounter struct {
+	budget time.Duration
+	total  atomic.Int64
+	missed atomic.Int64
+}
+
+// ObserveSLA
+// counts request of fn with @sla, request whose latency exceeds budget is counted as a miss.
+// it only observes, request is never cancelled by budget, so request stopped by deadline of context is counted by its latency too.
+func ObserveSLA(endpoint []byte, fn []byte, budget time.Duration, latency time.Duration) (missed bool) {
+	if budget <= 0 {
+		return
+	}
+	key := string(endpoint) + "." + string(fn)
+	v, has := slaStats.Load(key)
+	if !has {
+		v, _ = slaStats.LoadOrStore(key, &slaCounter{budget: budget})
+	}
+	counter := v.(*slaCounter)
+	counter.total.Add(1)
+	if latency > budget {
+		counter.missed.Add(1)
+		missed = true
+	}
+	return
+}
+
+type SLAStat struct {
+	Endpoint string  `json:"endpoint" avro:"endpoint"`
+	Fn       string  `json:"fn" avro:"fn"`
+	Budget   string  `json:"budget" avro:"budget"`
+	Total    int64   `json:"total" avro:"total"`
+	Missed   int64   `json:"missed" avro:"missed"`
+	Ratio    float64 `json:"ratio" avro:"ratio"`
+}
+
+// SLAStats
+// returns sla stats of fns since application started, ratio is missed / total.
+func SLAStats() (stats []SLAStat) {
+	stats = make([]SLAStat, 0, 1)
+	slaStats.Range(func(key, value any) bool {
+		name := key.(string)
+		counter := value.(*slaCounter)
+		stat := SLAStat{
+			Budget: counter.budget.String(),
+			Total:  counter.total.Load(),
+			Missed: counter.missed.Load(),
+		}
+		if idx := bytes.IndexByte(bytex.FromString(name), '.'); idx > 0 {
+			stat.Endpoint = name[:idx]
+			stat.Fn = name[idx+1:]
+		}
+		if stat.Total > 0 {
+			stat.Ratio = float64(stat.Missed) / float64(stat.Total)
+		}
+		stats = append(stats, stat)
+		return true
+	})
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Endpoint == stats[j].Endpoint {
+			return stats[i].Fn < stats[j].Fn
+		}
+		return stats[i].Endpoint < stats[j].Endpoint
+	})
+	return
+}
+
+// SLAHandler
+// serves sla stats of fns at /application/metrics, and connection stats of listeners at /application/metrics/conns.
+func SLAHandler() transports.MuxHandler {
+	return &slaHandler{}
+}
+
+type slaHandler struct{}
+
+func (handler *slaHandler) Name() string {
+	return "metrics"
+}
+
+func (handler *slaHandler) Construct(_ transports.MuxHandlerOptions) error {
+	return nil
+}
+
+func (handler *slaHandler) Match(_ context.Context, method []byte, path []byte, _ transports.Header) bool {
+	return bytes.Equal(method, transports.MethodGet) && (bytes.Equal(path, slaPath) || bytes.Equal(path, connsPath))
+}
+
+func (handler *slaHandler) Handle(w transports.ResponseWriter, r transports.Request) {
+	if bytes.Equal(r.Path(), connsPath) {
+		w.Succeed(transports.ConnStatistics())
+		return
+	}
+	w.Succeed(SLAStats())
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package metrics_test
+
+import (
+	"github.com/aacfactory/fns/services/metrics"
+	"testing"
+	"time"
+)
+
+func TestObserveSLA(t *testing.T) {
+	budget := 100 * time.Millisecond
+	if metrics.ObserveSLA([]byte("sla"), []byte("unbudgeted"), 0, time.Second) {
+		t.Fatal("fn without budget must not be observed")
+	}
+	for _, c := range []struct {
+		latency time.Duration
+		missed  bool
+	}{
+		{10 * time.Millisecond, false},
+		{budget, false},
+		{budget + time.Millisecond, true},
+	} {
+		if missed := metrics.ObserveSLA([]byte("sla"), []byte("get"), budget, c.latency); missed != c.missed {
+			t.Fatal("unexpected miss", c.latency, missed)
+		}
+	}
+	var stat *metrics.SLAStat
+	for _, s := range metrics.SLAStats() {
+		if s.Endpoint != "sla" {
+			continue
+		}
+		if s.Fn != "get" {
+			t.Fatal("fn without budget must not be in stats", s)
+		}
+		stat = &s
+	}
+	if stat == nil {
+		t.Fatal("stat of fn was not found")
+	}
+	if stat.Budget != budget.String() || stat.Total != 3 || stat.Missed != 1 || stat.Ratio != float64(1)/3 {
+		t.Fatal("unexpected stat", *stat)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"bytes"
+	"github.com/aacfactory/fns/commons/jsons"
+)
+
+var (
+	nullJson = []byte("null")
+)
+
+// Optional
+// tells omitted field from null field and valued field of json, it is used by partial update.
+// field of Optional is nullable in document.
+//
+//	type PatchUserParam struct {
+//		Id       string                   `json:"id"`
+//		Nickname services.Optional[string] `json:"nickname"`
+//	}
+type Optional[T any] struct {
+	value T
+	set   bool
+	null  bool
+}
+
+// Some
+// makes a valued optional.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{
+		value: value,
+		set:   true,
+		null:  false,
+	}
+}
+
+// Null
+// makes a null optional.
+func Null[T any]() Optional[T] {
+	return Optional[T]{
+		set:  true,
+		null: true,
+	}
+}
+
+// Present
+// is true when field is in json, even if it is null.
+func (o Optional[T]) Present() bool {
+	return o.set
+}
+
+// IsNull
+// is true when field is null in json.
+func (o Optional[T]) IsNull() bool {
+	return o.set && o.null
+}
+
+// Get
+// returns value when field is valued.
+func (o Optional[T]) Get() (value T, ok bool) {
+	if o.set && !o.null {
+		value = o.value
+		ok = true
+	}
+	return
+}
+
+func (o Optional[T]) OrElse(value T) T {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return value
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.set || o.null {
+		return nullJson, nil
+	}
+	return jsons.Marshal(o.value)
+}
+
+func (o *Optional[T]) UnmarshalJSON(p []byte) error {
+	o.set = true
+	if bytes.Equal(bytes.TrimSpace(p), nullJson) {
+		o.null = true
+		var zero T
+		o.value = zero
+		return nil
+	}
+	o.null = false
+	return jsons.Unmarshal(p, &o.value)
+}
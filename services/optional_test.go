@@ -0,0 +1,56 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/json"
+	"testing"
+)
+
+type PatchParam struct {
+	Name services.Optional[string] `json:"name"`
+	Age  services.Optional[int]    `json:"age"`
+	Nick services.Optional[string] `json:"nick"`
+}
+
+func TestOptional(t *testing.T) {
+	v, err := services.ValueOfParam[PatchParam](services.NewParam(json.RawMessage(`{"name":"x","age":null}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, ok := v.Name.Get(); !ok || name != "x" {
+		t.Fatal("name must be valued")
+	}
+	if !v.Age.Present() || !v.Age.IsNull() {
+		t.Fatal("age must be null")
+	}
+	if v.Nick.Present() || v.Nick.IsNull() {
+		t.Fatal("nick must be omitted")
+	}
+	if v.Nick.OrElse("y") != "y" {
+		t.Fatal("omitted must be else")
+	}
+	p, encodeErr := json.Marshal(PatchParam{Name: services.Some("x"), Age: services.Null[int]()})
+	if encodeErr != nil {
+		t.Fatal(encodeErr)
+	}
+	if string(p) != `{"name":"x","age":null,"nick":null}` {
+		t.Fatal("encoded mismatched", string(p))
+	}
+}
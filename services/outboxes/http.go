@@ -0,0 +1,69 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package outboxes
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"net/http"
+	"time"
+)
+
+// HttpSink
+// posts payload of event to url, Idempotency-Key header is the id of event and X-Outbox-Topic is the topic.
+// response status must be 2xx.
+func HttpSink(url string, timeout time.Duration) Sink {
+	if timeout < 1 {
+		timeout = 10 * time.Second
+	}
+	return &httpSink{
+		url: url,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func (sink *httpSink) Publish(ctx context.Context, event Event) (err error) {
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, sink.url, bytes.NewReader(event.Payload))
+	if reqErr != nil {
+		err = errors.Warning("fns: outboxes http sink publish failed").WithCause(reqErr)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", event.Id)
+	req.Header.Set("X-Outbox-Topic", event.Topic)
+	resp, doErr := sink.client.Do(req)
+	if doErr != nil {
+		err = errors.Warning("fns: outboxes http sink publish failed").WithCause(doErr)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err = errors.Warning("fns: outboxes http sink publish failed").WithCause(fmt.Errorf("status is %d", resp.StatusCode))
+		return
+	}
+	return
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package outboxes
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/jsons"
+	"github.com/aacfactory/fns/commons/uid"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/json"
+	"time"
+)
+
+// Event
+// Id is the idempotency key, sink should use it to drop duplicated deliveries.
+type Event struct {
+	Id       string          `json:"id" avro:"id"`
+	Topic    string          `json:"topic" avro:"topic"`
+	Payload  json.RawMessage `json:"payload" avro:"payload"`
+	CreateAt time.Time       `json:"createAt" avro:"createAt"`
+}
+
+// Store
+// Save must write events with the transaction in ctx, such as the sql transaction of @transactional fn,
+// so events are committed or rolled back with data.
+// Pending returns events which are committed but not acknowledged, ordered by CreateAt.
+type Store interface {
+	services.Component
+	Save(ctx context.Context, events []Event) (err error)
+	Pending(ctx context.Context, limit int) (events []Event, err error)
+	Acknowledge(ctx context.Context, ids []string) (err error)
+}
+
+// Sink
+// publishes event to kafka, nats, http and so on.
+type Sink interface {
+	Publish(ctx context.Context, event Event) (err error)
+}
+
+// Publish
+// saves an event into outbox store in the transaction of ctx, the relay of outbox service publishes it after committed.
+// outbox service must be deployed in the same application.
+func Publish(ctx context.Context, topic string, payload any) (id string, err error) {
+	p, encodeErr := jsons.Marshal(payload)
+	if encodeErr != nil {
+		err = errors.Warning("fns: publish outbox event failed").WithCause(encodeErr).WithMeta("topic", topic)
+		return
+	}
+	ep, has := runtime.Endpoints(ctx).Get(ctx, endpointName)
+	if !has {
+		err = errors.Warning("fns: publish outbox event failed").WithCause(fmt.Errorf("outbox service was not found")).WithMeta("topic", topic)
+		return
+	}
+	svc, ok := ep.(*service)
+	if !ok {
+		err = errors.Warning("fns: publish outbox event failed").WithCause(fmt.Errorf("outbox service is not local")).WithMeta("topic", topic)
+		return
+	}
+	event := Event{
+		Id:       uid.UID(),
+		Topic:    topic,
+		Payload:  p,
+		CreateAt: time.Now(),
+	}
+	if saveErr := svc.store.Save(ctx, []Event{event}); saveErr != nil {
+		err = errors.Warning("fns: publish outbox event failed").WithCause(saveErr).WithMeta("topic", topic)
+		return
+	}
+	id = event.Id
+	return
+}
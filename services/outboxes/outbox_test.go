@@ -0,0 +1,140 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package outboxes
+
+import (
+	"fmt"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/shareds"
+	"github.com/aacfactory/json"
+	"reflect"
+	"testing"
+)
+
+type memoryStore struct {
+	events []Event
+	acked  map[string]bool
+}
+
+func (store *memoryStore) Name() (name string) {
+	return "store"
+}
+
+func (store *memoryStore) Construct(_ services.Options) (err error) {
+	store.acked = make(map[string]bool)
+	return
+}
+
+func (store *memoryStore) Shutdown(_ context.Context) {
+}
+
+func (store *memoryStore) Save(_ context.Context, events []Event) (err error) {
+	store.events = append(store.events, events...)
+	return
+}
+
+func (store *memoryStore) Pending(_ context.Context, limit int) (events []Event, err error) {
+	for _, event := range store.events {
+		if len(events) == limit {
+			break
+		}
+		if !store.acked[event.Id] {
+			events = append(events, event)
+		}
+	}
+	return
+}
+
+func (store *memoryStore) Acknowledge(_ context.Context, ids []string) (err error) {
+	for _, id := range ids {
+		store.acked[id] = true
+	}
+	return
+}
+
+type memorySink struct {
+	published []string
+	fails     map[string]int
+}
+
+func (sink *memorySink) Publish(_ context.Context, event Event) (err error) {
+	if n := sink.fails[event.Topic]; n > 0 {
+		sink.fails[event.Topic] = n - 1
+		err = fmt.Errorf("publish %s failed", event.Topic)
+		return
+	}
+	sink.published = append(sink.published, event.Topic)
+	return
+}
+
+func TestRelay(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	store := &memoryStore{}
+	sink := &memorySink{fails: map[string]int{"e2": 1}}
+	svc := New(store, sink).(*service)
+	manager := services.New("id", versions.Origin(), log, services.Config{"outboxes": json.RawMessage(`{"batchSize":2}`)}, nil)
+	if err := manager.Add(svc); err != nil {
+		t.Fatal(err)
+	}
+	rt := runtime.New("id", "name", versions.Origin(), nil, log, nil, manager, nil, shared)
+	ctx := runtime.With(context.TODO(), rt)
+	logs.With(ctx, log)
+
+	ids := make([]string, 0, 3)
+	for _, topic := range []string{"e1", "e2", "e3"} {
+		id, err := Publish(ctx, topic, map[string]string{"topic": topic})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	// stops at first failure, so e3 is not published before e2
+	svc.relay(ctx)
+	if !reflect.DeepEqual(sink.published, []string{"e1"}) {
+		t.Fatal("relay must stop at first failure", sink.published)
+	}
+	if !store.acked[ids[0]] || store.acked[ids[1]] || store.acked[ids[2]] {
+		t.Fatal("only published events must be acknowledged", store.acked)
+	}
+	// remains are retried in order
+	svc.relay(ctx)
+	if !reflect.DeepEqual(sink.published, []string{"e1", "e2", "e3"}) {
+		t.Fatal("events must be published in order", sink.published)
+	}
+	for _, id := range ids {
+		if !store.acked[id] {
+			t.Fatal("published events must be acknowledged", id)
+		}
+	}
+	// nothing is pending
+	svc.relay(ctx)
+	if len(sink.published) != 3 {
+		t.Fatal("acknowledged events must not be published again", sink.published)
+	}
+}
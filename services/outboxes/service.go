@@ -0,0 +1,146 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package outboxes
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/services"
+	"time"
+)
+
+var (
+	endpointName = []byte("outboxes")
+)
+
+type Config struct {
+	// Interval
+	// interval of relay polling pending events, default is 1s.
+	Interval string `json:"interval,omitempty" yaml:"interval,omitempty"`
+	// BatchSize
+	// max number of events in each polling, default is 64.
+	BatchSize int `json:"batchSize,omitempty" yaml:"batchSize,omitempty"`
+}
+
+// New
+// outbox service relays committed events of store to sink, it is at least once,
+// event is acknowledged only after sink publishes it, failed events are retried in next polling.
+func New(store Store, sink Sink) services.Service {
+	return &service{
+		Abstract: services.NewAbstract(string(endpointName), true, store),
+		store:    store,
+		sink:     sink,
+		done:     make(chan struct{}),
+	}
+}
+
+type service struct {
+	services.Abstract
+	store     Store
+	sink      Sink
+	interval  time.Duration
+	batchSize int
+	done      chan struct{}
+}
+
+func (svc *service) Renew() services.Service {
+	return New(svc.store, svc.sink)
+}
+
+func (svc *service) Construct(options services.Options) (err error) {
+	err = svc.Abstract.Construct(options)
+	if err != nil {
+		return
+	}
+	if svc.sink == nil {
+		err = errors.Warning("fns: outboxes service construct failed").WithCause(fmt.Errorf("sink is nil"))
+		return
+	}
+	config := Config{}
+	if configErr := options.Config.As(&config); configErr != nil {
+		err = errors.Warning("fns: outboxes service construct failed").WithCause(configErr)
+		return
+	}
+	svc.interval = time.Second
+	if config.Interval != "" {
+		svc.interval, err = time.ParseDuration(config.Interval)
+		if err != nil {
+			err = errors.Warning("fns: outboxes service construct failed").WithCause(err).WithMeta("interval", config.Interval)
+			return
+		}
+	}
+	svc.batchSize = config.BatchSize
+	if svc.batchSize < 1 {
+		svc.batchSize = 64
+	}
+	return
+}
+
+func (svc *service) Listen(ctx context.Context) (err error) {
+	ticker := time.NewTicker(svc.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-svc.done:
+			return
+		case <-ticker.C:
+			svc.relay(ctx)
+		}
+	}
+}
+
+func (svc *service) relay(ctx context.Context) {
+	log := svc.Log()
+	events, pendingErr := svc.store.Pending(ctx, svc.batchSize)
+	if pendingErr != nil {
+		if log.WarnEnabled() {
+			log.Warn().Cause(pendingErr).Message("fns: outboxes get pending events failed")
+		}
+		return
+	}
+	if len(events) == 0 {
+		return
+	}
+	published := make([]string, 0, len(events))
+	for _, event := range events {
+		if publishErr := svc.sink.Publish(ctx, event); publishErr != nil {
+			if log.WarnEnabled() {
+				log.Warn().Cause(publishErr).With("id", event.Id).With("topic", event.Topic).Message("fns: outboxes publish event failed")
+			}
+			// keep order of events, remains are retried in next polling
+			break
+		}
+		published = append(published, event.Id)
+	}
+	if len(published) == 0 {
+		return
+	}
+	if ackErr := svc.store.Acknowledge(ctx, published); ackErr != nil {
+		if log.WarnEnabled() {
+			log.Warn().Cause(ackErr).Message("fns: outboxes acknowledge events failed")
+		}
+	}
+}
+
+func (svc *service) Shutdown(ctx context.Context) {
+	close(svc.done)
+	svc.Abstract.Shutdown(ctx)
+}
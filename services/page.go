@@ -0,0 +1,80 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+// Page
+// @title Page
+// @description Paginated items
+type Page[T any] struct {
+	// Items
+	// @title Items
+	// @description Items of current page
+	Items []T `json:"items"`
+	// Total
+	// @title Total
+	// @description Count of all items, it is -1 when not counted
+	Total int64 `json:"total"`
+	// Cursor
+	// @title Cursor
+	// @description Cursor of next page, it is empty when there is no more
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// HasMore
+// is true when cursor of next page is present.
+func (page Page[T]) HasMore() bool {
+	return page.Cursor != ""
+}
+
+// NewPage
+// makes a page of items, total is -1 when it is not counted.
+func NewPage[T any](items []T, total int64) Page[T] {
+	if items == nil {
+		items = make([]T, 0)
+	}
+	return Page[T]{
+		Items:  items,
+		Total:  total,
+		Cursor: "",
+	}
+}
+
+// NewCursorPage
+// makes a page of items with cursor of next page.
+func NewCursorPage[T any](items []T, total int64, cursor string) Page[T] {
+	page := NewPage[T](items, total)
+	page.Cursor = cursor
+	return page
+}
+
+// SlicePage
+// cuts a page from all items by offset and limit.
+func SlicePage[T any](items []T, offset int, limit int) Page[T] {
+	total := len(items)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= total || limit < 1 {
+		return NewPage[T](nil, int64(total))
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return NewPage[T](items[offset:end], int64(total))
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	sc "context"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
+	"github.com/aacfactory/fns/commons/masks"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/json"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	panicMaskedKeys = masks.New()
+	panicReporting  = new(atomic.Pointer[panicReporter])
+)
+
+// PanicReport
+// describes a panic of fn, Params is the json of param whose sensitive fields are masked.
+type PanicReport struct {
+	Service   string           `json:"service"`
+	Fn        string           `json:"fn"`
+	RequestId string           `json:"requestId"`
+	Version   versions.Version `json:"version"`
+	Message   string           `json:"message"`
+	Stack     []byte           `json:"stack"`
+	Params    json.RawMessage  `json:"params"`
+	At        time.Time        `json:"at"`
+}
+
+// PanicReporter
+// sends panics recovered from fns to an external sink, such as sentry.
+// Report is called synchronously after the promise of fn is failed,
+// so it should be quick. Errors and panics of it are logged and never replace the error of fn.
+type PanicReporter interface {
+	Report(ctx sc.Context, report PanicReport) (err error)
+}
+
+type noopPanicReporter struct{}
+
+func (reporter noopPanicReporter) Report(_ sc.Context, _ PanicReport) (err error) {
+	return
+}
+
+// NoopPanicReporter
+// is the default reporter, it drops reports.
+func NoopPanicReporter() PanicReporter {
+	return noopPanicReporter{}
+}
+
+type panicReporter struct {
+	log      logs.Logger
+	version  versions.Version
+	reporter PanicReporter
+}
+
+// SetPanicReporter
+// sets the reporter of fn panics, nil reporter resets it to NoopPanicReporter.
+func SetPanicReporter(log logs.Logger, version versions.Version, reporter PanicReporter) {
+	if reporter == nil {
+		panicReporting.Store(nil)
+		return
+	}
+	panicReporting.Store(&panicReporter{
+		log:      log,
+		version:  version,
+		reporter: reporter,
+	})
+}
+
+func reportPanic(ctx sc.Context, r Request, recovered any, stack []byte) {
+	reporting := panicReporting.Load()
+	if reporting == nil {
+		return
+	}
+	service, fn := r.Fn()
+	report := PanicReport{
+		Service:   string(service),
+		Fn:        string(fn),
+		RequestId: string(r.Header().RequestId()),
+		Version:   reporting.version,
+		Message:   fmt.Sprintf("%v", recovered),
+		Stack:     stack,
+		Params:    maskPanicParam(r.Param()),
+		At:        time.Now(),
+	}
+	defer func() {
+		if rr := recover(); rr != nil {
+			if reporting.log != nil && reporting.log.ErrorEnabled() {
+				reporting.log.Error().
+					With("service", report.Service).With("fn", report.Fn).
+					Cause(errors.Warning(fmt.Sprintf("fns: panic reporter panicked, %v", rr))).
+					Message("fns: report panic failed")
+			}
+		}
+	}()
+	if err := reporting.reporter.Report(ctx, report); err != nil {
+		if reporting.log != nil && reporting.log.ErrorEnabled() {
+			reporting.log.Error().
+				With("service", report.Service).With("fn", report.Fn).
+				Cause(err).
+				Message("fns: report panic failed")
+		}
+	}
+}
+
+func maskPanicParam(param Param) (p json.RawMessage) {
+	if param == nil || !param.Valid() {
+		return
+	}
+	var raw []byte
+	switch value := param.Value().(type) {
+	case []byte:
+		raw = value
+		break
+	case json.RawMessage:
+		raw = value
+		break
+	default:
+		encoded, encodeErr := jsons.Marshal(value)
+		if encodeErr != nil {
+			return
+		}
+		raw = encoded
+		break
+	}
+	if !json.Validate(raw) {
+		return
+	}
+	var v any
+	if decodeErr := jsons.Unmarshal(raw, &v); decodeErr != nil {
+		return
+	}
+	if !panicMaskedKeys.Mask(v) {
+		p = raw
+		return
+	}
+	masked, maskedErr := jsons.Marshal(v)
+	if maskedErr != nil {
+		return
+	}
+	p = masked
+	return
+}
+
+func panicError(r Request, recovered any) (err errors.CodeError) {
+	service, fn := r.Fn()
+	err = errors.Warning("fns: fn panicked").
+		WithMeta("endpoint", bytex.ToString(service)).WithMeta("fn", bytex.ToString(fn)).
+		WithCause(fmt.Errorf("%v", recovered))
+	return
+}
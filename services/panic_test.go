@@ -0,0 +1,93 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	sc "context"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/futures"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/services"
+	"strings"
+	"testing"
+)
+
+type panicFn struct{}
+
+func (fn *panicFn) Name() string {
+	return "boom"
+}
+
+func (fn *panicFn) Internal() bool {
+	return false
+}
+
+func (fn *panicFn) Readonly() bool {
+	return false
+}
+
+func (fn *panicFn) Handle(_ services.Request) (v any, err error) {
+	panic("boom")
+}
+
+type recordPanicReporter struct {
+	reports []services.PanicReport
+}
+
+func (reporter *recordPanicReporter) Report(_ sc.Context, report services.PanicReport) (err error) {
+	reporter.reports = append(reporter.reports, report)
+	err = fmt.Errorf("sink is down")
+	return
+}
+
+func TestFnTask_Panic(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	reporter := &recordPanicReporter{}
+	services.SetPanicReporter(log, versions.New(1, 2, 3), reporter)
+	defer services.SetPanicReporter(nil, versions.Origin(), nil)
+
+	param := map[string]any{"name": "fns", "password": "secret"}
+	r := services.NewRequest(context.TODO(), []byte("panics"), []byte("boom"), param)
+	promise, future := futures.New()
+	services.FnTask{Fn: &panicFn{}, Promise: promise}.Execute(r)
+	_, err := future.Await(context.TODO())
+	if err == nil {
+		t.Fatal("panic was not recovered")
+	}
+	codeErr, ok := errors.As(err)
+	if !ok || codeErr.Message() != "fns: fn panicked" {
+		t.Fatal("reporter failure masked the error", err)
+	}
+	if len(reporter.reports) != 1 {
+		t.Fatal("panic was not reported")
+	}
+	report := reporter.reports[0]
+	if report.Service != "panics" || report.Fn != "boom" || report.Message != "boom" || len(report.Stack) == 0 {
+		t.Fatal("unexpected report", report)
+	}
+	if strings.Contains(string(report.Params), "secret") {
+		t.Fatal("params were not masked", string(report.Params))
+	}
+	t.Log(string(report.Params), report.Version)
+}
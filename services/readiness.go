@@ -0,0 +1,182 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/transports"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ReadinessPath
+	// is path of readiness handler, it is served while the application is half-on, see runtime.Middleware.
+	ReadinessPath = []byte("/application/ready")
+)
+
+// Warmable
+// is implemented by services which have work to do after Construct and before traffic, such as filling pools or caches.
+// The application stays half-on, so requests except readiness are answered with 425, until Warmup of every service returns.
+// ctx of Warmup has runtime.
+type Warmable interface {
+	Service
+	Warmup(ctx context.Context) (err error)
+}
+
+type Readiness struct {
+	Service string        `json:"service"`
+	Ready   bool          `json:"ready"`
+	Latency time.Duration `json:"latency,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+type Readinesses struct {
+	Ready    bool        `json:"ready"`
+	Services []Readiness `json:"services"`
+}
+
+type readinessRecorder struct {
+	mutex  sync.RWMutex
+	values []Readiness
+}
+
+func (recorder *readinessRecorder) add(name string) {
+	recorder.mutex.Lock()
+	recorder.values = append(recorder.values, Readiness{
+		Service: name,
+	})
+	recorder.mutex.Unlock()
+}
+
+func (recorder *readinessRecorder) set(readiness Readiness) {
+	recorder.mutex.Lock()
+	for i, value := range recorder.values {
+		if value.Service == readiness.Service {
+			recorder.values[i] = readiness
+			break
+		}
+	}
+	recorder.mutex.Unlock()
+}
+
+func (recorder *readinessRecorder) get() (v Readinesses) {
+	recorder.mutex.RLock()
+	v.Ready = true
+	v.Services = make([]Readiness, len(recorder.values))
+	copy(v.Services, recorder.values)
+	recorder.mutex.RUnlock()
+	for _, readiness := range v.Services {
+		if !readiness.Ready {
+			v.Ready = false
+			break
+		}
+	}
+	return
+}
+
+// Warmup
+// runs Warmup of Warmable services concurrently, services which are not Warmable are ready once they are constructed.
+func (manager *Manager) Warmup(ctx context.Context) (err error) {
+	wg := new(sync.WaitGroup)
+	errs := errors.MakeErrors()
+	errsMutex := new(sync.Mutex)
+	for _, service := range manager.order {
+		name := service.Name()
+		warmable, ok := service.(Warmable)
+		if !ok {
+			manager.readiness.set(Readiness{Service: name, Ready: true})
+			continue
+		}
+		wg.Add(1)
+		go func(ctx context.Context, name string, warmable Warmable) {
+			defer wg.Done()
+			wctx := context.WithValue(ctx, "warmup", name)
+			logs.With(wctx, manager.log.With("service", name))
+			if components := warmable.Components(); len(components) > 0 {
+				WithComponents(wctx, []byte(name), components)
+			}
+			beg := time.Now()
+			warmErr := warmable.Warmup(wctx)
+			readiness := Readiness{
+				Service: name,
+				Ready:   warmErr == nil,
+				Latency: time.Since(beg),
+			}
+			if warmErr != nil {
+				readiness.Error = warmErr.Error()
+				errsMutex.Lock()
+				errs.Append(errors.Warning("fns: service warmup failed").WithMeta("service", name).WithCause(warmErr))
+				errsMutex.Unlock()
+			}
+			manager.readiness.set(readiness)
+			if manager.log.DebugEnabled() {
+				manager.log.Debug().With("service", name).Message(fmt.Sprintf("fns: service warmed up in %s", readiness.Latency))
+			}
+		}(ctx, name, warmable)
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		err = errs.Error()
+	}
+	return
+}
+
+func (manager *Manager) Readiness() (v Readinesses) {
+	v = manager.readiness.get()
+	return
+}
+
+// ReadinessHandler
+// serves readiness of each service at /application/ready,
+// status is 503 until every service is ready, so it can be used as readiness probe.
+func ReadinessHandler(manager EndpointsManager) transports.MuxHandler {
+	return &readinessHandler{
+		manager: manager,
+	}
+}
+
+type readinessHandler struct {
+	manager EndpointsManager
+}
+
+func (handler *readinessHandler) Name() string {
+	return "readiness"
+}
+
+func (handler *readinessHandler) Construct(_ transports.MuxHandlerOptions) error {
+	return nil
+}
+
+func (handler *readinessHandler) Match(_ context.Context, method []byte, path []byte, _ transports.Header) bool {
+	return bytes.Equal(method, transports.MethodGet) && bytes.Equal(path, ReadinessPath)
+}
+
+func (handler *readinessHandler) Handle(w transports.ResponseWriter, _ transports.Request) {
+	readiness := handler.manager.Readiness()
+	w.Succeed(readiness)
+	if !readiness.Ready {
+		w.SetStatus(http.StatusServiceUnavailable)
+	}
+	return
+}
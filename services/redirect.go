@@ -0,0 +1,76 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/transports"
+	"net/http"
+)
+
+// Redirect
+// returns the result which is responded as http redirect by endpoints handler,
+// status must be 3xx, such as 302 and 303, others are replaced by 302.
+// it only takes effect at the http edge, callers of internal requests get it as a plain value.
+func Redirect(url string, status int) Redirection {
+	if status < http.StatusMultipleChoices || status > http.StatusPermanentRedirect {
+		status = http.StatusFound
+	}
+	return Redirection{
+		Location: url,
+		Status:   status,
+	}
+}
+
+// Redirection
+// @title Redirection
+// @description Redirect to location
+type Redirection struct {
+	// Location
+	// @title Location
+	Location string `json:"location"`
+	// Status
+	// @title Status
+	Status int `json:"status"`
+}
+
+// WriteRedirection
+// writes v as redirect into w when v is Redirection.
+func WriteRedirection(w transports.ResponseWriter, v any) (ok bool) {
+	var redirection Redirection
+	switch r := v.(type) {
+	case Redirection:
+		redirection = r
+		break
+	case *Redirection:
+		if r == nil {
+			return
+		}
+		redirection = *r
+		break
+	default:
+		return
+	}
+	if redirection.Status == 0 {
+		redirection.Status = http.StatusFound
+	}
+	w.Header().Set(transports.LocationHeaderName, bytex.FromString(redirection.Location))
+	w.SetStatus(redirection.Status)
+	ok = true
+	return
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"bytes"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/transports"
+	"strings"
+	"time"
+)
+
+var (
+	reloadPath         = []byte("/application/dev/reload")
+	reloadServiceParam = []byte("service")
+	ErrReloadService   = errors.BadRequest("fns: service is required")
+)
+
+type Reloadable interface {
+	Reload(ctx context.Context, name string) (err error)
+}
+
+// Renewable
+// is the reload contract of service, Renew returns a new instance which is not constructed yet,
+// such as New of the service package with same arguments.
+// Manager.Reload only reloads renewable services, cause a constructed instance can not be constructed twice,
+// e.g. its functions would be added again and its channels would be closed twice.
+type Renewable interface {
+	Renew() (service Service)
+}
+
+// ReloadConfig
+// Dev must be true, cause reload is for development only, such as working with file-watch tooling.
+type ReloadConfig struct {
+	Dev bool `json:"dev,omitempty" yaml:"dev,omitempty"`
+}
+
+type ReloadResult struct {
+	Service string `json:"service"`
+	Latency string `json:"latency"`
+}
+
+// ReloadHandler
+// serves POST /application/dev/reload?service={name}, which reloads the named service, see Manager.Reload.
+// it is disabled unless transport.handlers.reload.dev is true.
+func ReloadHandler(manager EndpointsManager) transports.MuxHandler {
+	return &reloadHandler{
+		manager: manager,
+	}
+}
+
+type reloadHandler struct {
+	manager EndpointsManager
+	enable  bool
+}
+
+func (handler *reloadHandler) Name() string {
+	return "reload"
+}
+
+func (handler *reloadHandler) Construct(options transports.MuxHandlerOptions) error {
+	config := ReloadConfig{}
+	if err := options.Config.As(&config); err != nil {
+		return errors.Warning("fns: construct reload handler failed").WithCause(err)
+	}
+	_, reloadable := handler.manager.(Reloadable)
+	handler.enable = config.Dev && reloadable
+	if handler.enable && options.Log.WarnEnabled() {
+		options.Log.Warn().Message("fns: reload handler is enabled, it is for development only")
+	}
+	return nil
+}
+
+func (handler *reloadHandler) Match(_ context.Context, method []byte, path []byte, _ transports.Header) bool {
+	return handler.enable && bytes.Equal(method, transports.MethodPost) && bytes.Equal(path, reloadPath)
+}
+
+func (handler *reloadHandler) Handle(w transports.ResponseWriter, r transports.Request) {
+	name := strings.TrimSpace(bytex.ToString(r.Params().Get(reloadServiceParam)))
+	if name == "" {
+		w.Failed(ErrReloadService)
+		return
+	}
+	beg := time.Now()
+	if err := handler.manager.(Reloadable).Reload(r, name); err != nil {
+		w.Failed(err)
+		return
+	}
+	w.Succeed(ReloadResult{
+		Service: name,
+		Latency: time.Since(beg).String(),
+	})
+	return
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/defaults"
 	"github.com/aacfactory/fns/commons/objects"
 	"github.com/aacfactory/fns/commons/uid"
 	"github.com/aacfactory/fns/commons/versions"
@@ -41,9 +42,21 @@ func NewParam(src interface{}) Param {
 }
 
 // ValueOfParam
-// type of T must be struct value or slice, can not be ptr
+// type of T must be struct value or slice, can not be ptr,
+// fields which have `default` tag are filled before unmarshalling, see defaults.Fill.
 func ValueOfParam[T any](param Param) (v T, err error) {
-	v, err = objects.Value[T](param)
+	if value, ok := objects.TryValue[T](param); ok {
+		v = value
+		return
+	}
+	if err = defaults.Fill(&v); err != nil {
+		err = errors.Warning("fns: get value of param failed").WithCause(err)
+		return
+	}
+	if param == nil {
+		return
+	}
+	err = param.Unmarshal(&v)
 	if err != nil {
 		err = errors.Warning("fns: get value of param failed").WithCause(err)
 		return
@@ -91,12 +104,24 @@ func WithDeviceIp(ip []byte) RequestOption {
 	}
 }
 
+func WithTenant(tenant []byte) RequestOption {
+	return func(options *RequestOptions) {
+		options.header.tenant = tenant
+	}
+}
+
 func WithInternalRequest() RequestOption {
 	return func(options *RequestOptions) {
 		options.header.internal = true
 	}
 }
 
+func WithRequestDepth(depth int) RequestOption {
+	return func(options *RequestOptions) {
+		options.header.depth = depth
+	}
+}
+
 func WithRequestVersions(acceptedVersions versions.Intervals) RequestOption {
 	return func(options *RequestOptions) {
 		options.header.acceptedVersions = acceptedVersions
@@ -144,12 +169,18 @@ func NewRequest(ctx context.Context, service []byte, fn []byte, param interface{
 		if len(opt.header.deviceIp) == 0 && len(header.deviceIp) > 0 {
 			opt.header.deviceIp = header.deviceIp
 		}
+		if len(opt.header.tenant) == 0 && len(header.tenant) > 0 {
+			opt.header.tenant = header.tenant
+		}
 		if len(opt.header.token) == 0 && len(header.token) > 0 {
 			opt.header.token = header.token
 		}
 		if len(opt.header.acceptedVersions) == 0 && len(header.acceptedVersions) > 0 {
 			opt.header.acceptedVersions = header.acceptedVersions
 		}
+		if opt.header.depth == 0 {
+			opt.header.depth = header.depth + 1
+		}
 		opt.header.internal = true
 	}
 	r := new(request)
@@ -186,12 +217,18 @@ func AcquireRequest(ctx context.Context, service []byte, fn []byte, param interf
 		if len(opt.header.deviceIp) == 0 && len(header.deviceIp) > 0 {
 			opt.header.deviceIp = header.deviceIp
 		}
+		if len(opt.header.tenant) == 0 && len(header.tenant) > 0 {
+			opt.header.tenant = header.tenant
+		}
 		if len(opt.header.token) == 0 && len(header.token) > 0 {
 			opt.header.token = header.token
 		}
 		if len(opt.header.acceptedVersions) == 0 && len(header.acceptedVersions) > 0 {
 			opt.header.acceptedVersions = header.acceptedVersions
 		}
+		if opt.header.depth == 0 {
+			opt.header.depth = header.depth + 1
+		}
 		opt.header.internal = true
 	}
 	var r *request
@@ -313,6 +350,7 @@ func HashRequest(r Request, options ...HashRequestOption) (p []byte, err error)
 	_, _ = buf.Write(service)
 	_, _ = buf.Write(fn)
 	_, _ = buf.Write(r.Header().AcceptedVersions().Bytes())
+	_, _ = buf.Write(r.Header().Tenant())
 	if opt.withToken {
 		token := r.Header().Token()
 		if len(token) == 0 {
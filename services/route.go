@@ -0,0 +1,274 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
+	"github.com/aacfactory/fns/services/documents"
+	"github.com/aacfactory/json"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// FnRoute
+// rest style route of fn, such as `GET /users/:id` or `POST /users/{id}/roles`.
+type FnRoute struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+}
+
+func NewFnRoute(method string, pattern string) (route FnRoute, err error) {
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if method != http.MethodGet && method != http.MethodPost {
+		err = errors.Warning("fns: new fn route failed").WithCause(fmt.Errorf("method must be GET or POST")).WithMeta("method", method)
+		return
+	}
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || pattern[0] != '/' {
+		err = errors.Warning("fns: new fn route failed").WithCause(fmt.Errorf("pattern must start with /")).WithMeta("pattern", pattern)
+		return
+	}
+	route = FnRoute{
+		Method:  method,
+		Pattern: pattern,
+	}
+	return
+}
+
+type RoutableFn interface {
+	Fn
+	Routes() []FnRoute
+}
+
+type routePathParam struct {
+	name  []byte
+	value []byte
+	// typ is type of field in document of param, such as integer and boolean, see documents.Endpoint PropertyType.
+	typ string
+}
+
+type route struct {
+	method   []byte
+	segments [][]byte
+	endpoint []byte
+	fn       []byte
+	types    map[string]string
+}
+
+// match
+// path may be escaped, such as /users/a%2Fb, items of path are unescaped after split, so values of path params can hold slash.
+func (r route) match(method []byte, path []byte) (params []routePathParam, ok bool) {
+	if !bytes.Equal(r.method, method) {
+		return
+	}
+	items := bytes.Split(path, slashBytes)
+	if len(items) != len(r.segments) {
+		return
+	}
+	for i, segment := range r.segments {
+		item := items[i]
+		if bytes.IndexByte(item, '%') > -1 {
+			unescaped, unescapeErr := url.PathUnescape(bytex.ToString(item))
+			if unescapeErr != nil {
+				params = nil
+				return
+			}
+			item = bytex.FromString(unescaped)
+		}
+		if name, isParam := routeParamName(segment); isParam {
+			if len(item) == 0 {
+				params = nil
+				return
+			}
+			params = append(params, routePathParam{
+				name:  name,
+				value: item,
+				typ:   r.types[bytex.ToString(name)],
+			})
+			continue
+		}
+		if !bytes.Equal(segment, item) {
+			params = nil
+			return
+		}
+	}
+	ok = true
+	return
+}
+
+func (r route) matchPath(path []byte) bool {
+	_, ok := r.match(r.method, path)
+	return ok
+}
+
+func routeParamName(segment []byte) (name []byte, ok bool) {
+	if len(segment) > 1 && segment[0] == ':' {
+		name = segment[1:]
+		ok = true
+		return
+	}
+	if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+		name = segment[1 : len(segment)-1]
+		ok = true
+		return
+	}
+	return
+}
+
+// routeParamTypes
+// returns types of path params by document of fn param, params which are not documented are strings.
+func routeParamTypes(document documents.Endpoint, fn string, segments [][]byte) (types map[string]string) {
+	types = make(map[string]string)
+	for _, doc := range document.Functions {
+		if doc.Name != fn {
+			continue
+		}
+		for _, segment := range segments {
+			if name, isParam := routeParamName(segment); isParam {
+				if typ := document.PropertyType(doc.Param, string(name)); typ != "" {
+					types[string(name)] = typ
+				}
+			}
+		}
+		break
+	}
+	return
+}
+
+type routes []route
+
+func newRoutes(infos EndpointInfos) routes {
+	v := make(routes, 0, 1)
+	for _, info := range infos {
+		if info.Internal {
+			continue
+		}
+		for _, fn := range info.Functions {
+			if fn.Internal {
+				continue
+			}
+			for _, fr := range fn.Routes {
+				segments := bytes.Split([]byte(fr.Pattern), slashBytes)
+				v = append(v, route{
+					method:   []byte(fr.Method),
+					segments: segments,
+					endpoint: []byte(info.Name),
+					fn:       []byte(fn.Name),
+					types:    routeParamTypes(info.Document, fn.Name, segments),
+				})
+			}
+		}
+	}
+	return v
+}
+
+func (rs routes) match(method []byte, path []byte) (endpoint []byte, fn []byte, params []routePathParam, ok bool) {
+	for _, r := range rs {
+		params, ok = r.match(method, path)
+		if ok {
+			endpoint, fn = r.endpoint, r.fn
+			return
+		}
+	}
+	return
+}
+
+// methods
+// returns methods of routes which pattern is matched with path.
+func (rs routes) methods(path []byte) (methods [][]byte) {
+	for _, r := range rs {
+		if !r.matchPath(path) {
+			continue
+		}
+		exist := false
+		for _, method := range methods {
+			if bytes.Equal(method, r.method) {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			methods = append(methods, r.method)
+		}
+	}
+	return
+}
+
+// mergeRoutePathParams
+// path params are merged into json object body, values are coerced to types in document of param,
+// integer, number and boolean are json numbers and bools, others are strings.
+func mergeRoutePathParams(body []byte, params []routePathParam) (p []byte, err error) {
+	obj := make(map[string]json.RawMessage)
+	if len(bytes.TrimSpace(body)) > 0 {
+		err = jsons.Unmarshal(body, &obj)
+		if err != nil {
+			return
+		}
+	}
+	for _, param := range params {
+		value, encodeErr := param.json()
+		if encodeErr != nil {
+			err = encodeErr
+			return
+		}
+		obj[string(param.name)] = value
+	}
+	p, err = jsons.Marshal(obj)
+	return
+}
+
+func (param routePathParam) json() (p []byte, err error) {
+	s := bytex.ToString(param.value)
+	switch param.typ {
+	case "integer":
+		n, parseErr := strconv.ParseInt(s, 10, 64)
+		if parseErr != nil {
+			err = errors.Warning("fns: path param must be integer").WithMeta("param", string(param.name)).WithCause(parseErr)
+			return
+		}
+		p = []byte(strconv.FormatInt(n, 10))
+		break
+	case "number":
+		f, parseErr := strconv.ParseFloat(s, 64)
+		if parseErr != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			err = errors.Warning("fns: path param must be number").WithMeta("param", string(param.name)).WithCause(fmt.Errorf("%s is not a number", s))
+			return
+		}
+		p = []byte(strconv.FormatFloat(f, 'f', -1, 64))
+		break
+	case "boolean":
+		b, parseErr := strconv.ParseBool(s)
+		if parseErr != nil {
+			err = errors.Warning("fns: path param must be boolean").WithMeta("param", string(param.name)).WithCause(parseErr)
+			return
+		}
+		p = []byte(strconv.FormatBool(b))
+		break
+	default:
+		p, err = jsons.Marshal(s)
+		break
+	}
+	return
+}
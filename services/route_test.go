@@ -0,0 +1,90 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/services/documents"
+	"github.com/aacfactory/json"
+	"testing"
+)
+
+func TestRoutes_PathParams(t *testing.T) {
+	route, routeErr := NewFnRoute("post", "/users/:id/roles/{name}/{active}")
+	if routeErr != nil {
+		t.Fatal(routeErr)
+	}
+	document := documents.New("users", "", "", versions.Origin())
+	fn := documents.NewFn("roles")
+	fn.Param = documents.Struct("users", "RolesParam").
+		AddProperty("id", documents.Int64()).
+		AddProperty("name", documents.String()).
+		AddProperty("active", documents.Bool())
+	document.AddFn(fn)
+	rs := newRoutes(EndpointInfos{{
+		Name:      "users",
+		Functions: FnInfos{{Name: "roles", Routes: []FnRoute{route}}},
+		Document:  document,
+	}})
+	// escaped slash is kept in value
+	_, _, params, ok := rs.match([]byte("POST"), []byte("/users/1/roles/a%2Fb/true"))
+	if !ok || len(params) != 3 || string(params[1].value) != "a/b" {
+		t.Fatal("escaped path was not matched", ok, params)
+	}
+	merged, mergeErr := mergeRoutePathParams([]byte(`{"note":"x"}`), params)
+	if mergeErr != nil {
+		t.Fatal(mergeErr)
+	}
+	v := struct {
+		Id     int64  `json:"id"`
+		Name   string `json:"name"`
+		Active bool   `json:"active"`
+		Note   string `json:"note"`
+	}{}
+	if err := json.Unmarshal(merged, &v); err != nil {
+		t.Fatal(string(merged), err)
+	}
+	if v.Id != 1 || v.Name != "a/b" || !v.Active || v.Note != "x" {
+		t.Fatal("unexpected merged param", string(merged))
+	}
+	// value which does not fit type is rejected
+	_, _, params, ok = rs.match([]byte("POST"), []byte("/users/x/roles/a/true"))
+	if !ok {
+		t.Fatal("path was not matched")
+	}
+	if _, mergeErr = mergeRoutePathParams(nil, params); mergeErr == nil {
+		t.Fatal("integer param must be checked")
+	}
+	// malformed escape is not matched
+	if _, _, _, ok = rs.match([]byte("POST"), []byte("/users/1/roles/%zz/true")); ok {
+		t.Fatal("malformed escape must not be matched")
+	}
+}
+
+func TestNewFnRoute(t *testing.T) {
+	route, err := NewFnRoute(" get ", " /users/:id ")
+	if err != nil || route.Method != "GET" || route.Pattern != "/users/:id" {
+		t.Fatal("unexpected route", route, err)
+	}
+	if _, err = NewFnRoute("PUT", "/users"); err == nil {
+		t.Fatal("method must be GET or POST")
+	}
+	if _, err = NewFnRoute("GET", "users"); err == nil {
+		t.Fatal("pattern must start with /")
+	}
+}
@@ -0,0 +1,269 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sagas
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
+	"github.com/aacfactory/fns/commons/uid"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/json"
+	"time"
+)
+
+var (
+	keyPrefix = []byte("fns:sagas:")
+)
+
+const (
+	defaultTTL = 24 * time.Hour
+)
+
+// Compensation
+// fn which undoes a succeeded step, it is requested internally with param when saga is compensated.
+type Compensation struct {
+	Service string          `json:"service"`
+	Fn      string          `json:"fn"`
+	Param   json.RawMessage `json:"param,omitempty"`
+}
+
+func Compensate(service string, fn string, param any) (compensation Compensation, err error) {
+	compensation = Compensation{
+		Service: service,
+		Fn:      fn,
+	}
+	if param != nil {
+		compensation.Param, err = jsons.Marshal(param)
+		if err != nil {
+			err = errors.Warning("fns: make saga compensation failed").WithCause(err).WithMeta("service", service).WithMeta("fn", fn)
+			return
+		}
+	}
+	return
+}
+
+// State
+// ttl is the max life of persisted state, it is kept when state is resumed.
+type State struct {
+	Id            string         `json:"id"`
+	Name          string         `json:"name"`
+	TTL           time.Duration  `json:"ttl,omitempty"`
+	Compensations []Compensation `json:"compensations"`
+}
+
+type Options struct {
+	persist bool
+	ttl     time.Duration
+}
+
+type Option func(options *Options)
+
+// Persist
+// state of saga is saved into shared store after each step, so Resume can compensate it after crash.
+// ttl is the max life of state, default is one day.
+func Persist(ttl time.Duration) Option {
+	return func(options *Options) {
+		options.persist = true
+		if ttl > 0 {
+			options.ttl = ttl
+		}
+	}
+}
+
+// New
+// saga coordinates steps over services in a fn, each succeeded step records a compensation,
+// when a later step fails, call Compensate to undo succeeded steps in reverse order, otherwise call Commit.
+//
+//	saga := sagas.New("order", sagas.Persist(0))
+//	_, err = saga.Request(ctx, "stocks", "reserve", param, reserveCompensation)
+//	if err != nil {
+//		_ = saga.Compensate(ctx)
+//		return
+//	}
+//	err = saga.Commit(ctx)
+func New(name string, options ...Option) (saga *Saga) {
+	opt := Options{
+		ttl: defaultTTL,
+	}
+	for _, option := range options {
+		option(&opt)
+	}
+	saga = &Saga{
+		state: State{
+			Id:            uid.UID(),
+			Name:          name,
+			TTL:           opt.ttl,
+			Compensations: make([]Compensation, 0, 1),
+		},
+		persist: opt.persist,
+		ttl:     opt.ttl,
+	}
+	return
+}
+
+type Saga struct {
+	state   State
+	persist bool
+	ttl     time.Duration
+}
+
+func (saga *Saga) Id() string {
+	return saga.state.Id
+}
+
+// Request
+// requests fn internally, compensation is recorded when it succeeds.
+func (saga *Saga) Request(ctx context.Context, service string, fn string, param any, compensation Compensation) (response services.Response, err error) {
+	eps := runtime.Endpoints(ctx)
+	response, err = eps.Request(ctx, bytex.FromString(service), bytex.FromString(fn), param, services.WithInternalRequest())
+	if err != nil {
+		return
+	}
+	saga.state.Compensations = append(saga.state.Compensations, compensation)
+	if saga.persist {
+		if saveErr := save(ctx, saga.state, saga.ttl); saveErr != nil {
+			err = errors.Warning("fns: saga request failed").WithCause(saveErr).WithMeta("saga", saga.state.Name).WithMeta("id", saga.state.Id)
+			return
+		}
+	}
+	return
+}
+
+// Compensate
+// requests compensations in reverse order, failed compensations are kept, so it can be called again or resumed.
+func (saga *Saga) Compensate(ctx context.Context) (err error) {
+	saga.state, err = compensate(ctx, saga.state, saga.persist, saga.ttl)
+	return
+}
+
+// Commit
+// ends saga without compensation.
+func (saga *Saga) Commit(ctx context.Context) (err error) {
+	saga.state.Compensations = saga.state.Compensations[:0]
+	if saga.persist {
+		err = remove(ctx, saga.state.Id)
+	}
+	return
+}
+
+// Resume
+// compensates persisted saga by id, such as a saga whose fn crashed before compensating or committing.
+// failed compensations are saved with ttl of saga, use Persist to replace it.
+func Resume(ctx context.Context, id string, options ...Option) (err error) {
+	state, has, loadErr := load(ctx, id)
+	if loadErr != nil {
+		err = errors.Warning("fns: resume saga failed").WithCause(loadErr).WithMeta("id", id)
+		return
+	}
+	if !has {
+		return
+	}
+	opt := Options{
+		ttl: state.TTL,
+	}
+	for _, option := range options {
+		option(&opt)
+	}
+	if opt.ttl < 1 {
+		opt.ttl = defaultTTL
+	}
+	state.TTL = opt.ttl
+	_, err = compensate(ctx, state, true, opt.ttl)
+	return
+}
+
+func compensate(ctx context.Context, state State, persist bool, ttl time.Duration) (remains State, err error) {
+	eps := runtime.Endpoints(ctx)
+	errs := errors.MakeErrors()
+	failed := make([]Compensation, 0, 1)
+	for i := len(state.Compensations) - 1; i >= 0; i-- {
+		compensation := state.Compensations[i]
+		var param any
+		if len(compensation.Param) > 0 {
+			param = compensation.Param
+		}
+		_, doErr := eps.Request(ctx, bytex.FromString(compensation.Service), bytex.FromString(compensation.Fn), param, services.WithInternalRequest())
+		if doErr != nil {
+			errs.Append(errors.Wrap(doErr).WithMeta("service", compensation.Service).WithMeta("fn", compensation.Fn))
+			failed = append([]Compensation{compensation}, failed...)
+		}
+	}
+	remains = state
+	remains.Compensations = failed
+	if len(failed) == 0 {
+		if persist {
+			if rmErr := remove(ctx, state.Id); rmErr != nil {
+				errs.Append(rmErr)
+			}
+		}
+	} else if persist {
+		if saveErr := save(ctx, remains, ttl); saveErr != nil {
+			errs.Append(saveErr)
+		}
+	}
+	if len(errs) > 0 {
+		err = errors.Warning("fns: compensate saga failed").WithCause(errs.Error()).WithMeta("saga", state.Name).WithMeta("id", state.Id)
+		return
+	}
+	return
+}
+
+func key(id string) []byte {
+	return append(append(make([]byte, 0, len(keyPrefix)+len(id)), keyPrefix...), id...)
+}
+
+func save(ctx context.Context, state State, ttl time.Duration) (err error) {
+	p, encodeErr := jsons.Marshal(state)
+	if encodeErr != nil {
+		err = errors.Warning("fns: save saga state failed").WithCause(encodeErr)
+		return
+	}
+	if setErr := runtime.SharedStore(ctx).SetWithTTL(ctx, key(state.Id), p, ttl); setErr != nil {
+		err = errors.Warning("fns: save saga state failed").WithCause(setErr)
+		return
+	}
+	return
+}
+
+func load(ctx context.Context, id string) (state State, has bool, err error) {
+	p, exist, getErr := runtime.SharedStore(ctx).Get(ctx, key(id))
+	if getErr != nil {
+		err = errors.Warning("fns: load saga state failed").WithCause(getErr)
+		return
+	}
+	if !exist {
+		return
+	}
+	if decodeErr := jsons.Unmarshal(p, &state); decodeErr != nil {
+		err = errors.Warning("fns: load saga state failed").WithCause(decodeErr)
+		return
+	}
+	has = true
+	return
+}
+
+func remove(ctx context.Context, id string) (err error) {
+	if rmErr := runtime.SharedStore(ctx).Remove(ctx, key(id)); rmErr != nil {
+		err = errors.Warning("fns: remove saga state failed").WithCause(rmErr).WithMeta("id", id)
+		return
+	}
+	return
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sagas_test
+
+import (
+	"fmt"
+	"github.com/aacfactory/fns/commons/jsons"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/commons"
+	"github.com/aacfactory/fns/services/sagas"
+	"github.com/aacfactory/fns/shareds"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type stepParam struct {
+	Id string `json:"id"`
+}
+
+type recorder struct {
+	calls []string
+	fails map[string]int
+}
+
+func (r *recorder) fn(name string) services.Fn {
+	return commons.NewFn[stepParam, services.Empty](name, func(ctx context.Context, param stepParam) (v services.Empty, err error) {
+		if n := r.fails[name]; n > 0 {
+			r.fails[name] = n - 1
+			err = fmt.Errorf("%s failed", name)
+			return
+		}
+		r.calls = append(r.calls, name+":"+param.Id)
+		return
+	}, commons.Internal())
+}
+
+func setup(t *testing.T) (ctx context.Context, r *recorder) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	r = &recorder{fails: make(map[string]int)}
+	manager := services.New("id", versions.Origin(), log, services.Config{}, nil)
+	stocks := services.NewAbstract("stocks", true)
+	stocks.AddFunction(r.fn("reserve"))
+	stocks.AddFunction(r.fn("release"))
+	payments := services.NewAbstract("payments", true)
+	payments.AddFunction(r.fn("charge"))
+	payments.AddFunction(r.fn("refund"))
+	for _, svc := range []services.Service{&stocks, &payments} {
+		if err := manager.Add(svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rt := runtime.New("id", "name", versions.Origin(), nil, log, nil, manager, nil, shared)
+	ctx = runtime.With(context.TODO(), rt)
+	logs.With(ctx, log)
+	return
+}
+
+func steps(t *testing.T, ctx context.Context, saga *sagas.Saga) {
+	release, _ := sagas.Compensate("stocks", "release", stepParam{Id: "1"})
+	if _, err := saga.Request(ctx, "stocks", "reserve", stepParam{Id: "1"}, release); err != nil {
+		t.Fatal(err)
+	}
+	refund, _ := sagas.Compensate("payments", "refund", stepParam{Id: "1"})
+	if _, err := saga.Request(ctx, "payments", "charge", stepParam{Id: "1"}, refund); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaga_Compensate(t *testing.T) {
+	ctx, r := setup(t)
+	saga := sagas.New("order")
+	steps(t, ctx, saga)
+	r.fails["release"] = 1
+	if err := saga.Compensate(ctx); err == nil {
+		t.Fatal("failed compensation must be returned")
+	}
+	// compensations are requested in reverse order
+	if !reflect.DeepEqual(r.calls, []string{"reserve:1", "charge:1", "refund:1"}) {
+		t.Fatal("unexpected order", r.calls)
+	}
+	// failed one is kept and requested again
+	if err := saga.Compensate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.calls, []string{"reserve:1", "charge:1", "refund:1", "release:1"}) {
+		t.Fatal("failed compensation was not kept", r.calls)
+	}
+}
+
+func TestResume(t *testing.T) {
+	ctx, r := setup(t)
+	// fn crashed before compensating
+	saga := sagas.New("order", sagas.Persist(time.Hour))
+	steps(t, ctx, saga)
+	p, has, getErr := runtime.SharedStore(ctx).Get(ctx, []byte("fns:sagas:"+saga.Id()))
+	if getErr != nil || !has {
+		t.Fatal("state was not persisted", has, getErr)
+	}
+	state := sagas.State{}
+	if err := jsons.Unmarshal(p, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.TTL != time.Hour || len(state.Compensations) != 2 {
+		t.Fatal("unexpected state", state)
+	}
+	r.fails["release"] = 1
+	if err := sagas.Resume(ctx, saga.Id(), sagas.Persist(2*time.Hour)); err == nil {
+		t.Fatal("failed compensation must be returned")
+	}
+	p, has, _ = runtime.SharedStore(ctx).Get(ctx, []byte("fns:sagas:"+saga.Id()))
+	state = sagas.State{}
+	if err := jsons.Unmarshal(p, &state); err != nil || !has {
+		t.Fatal("failed compensation was not kept", has, err)
+	}
+	if state.TTL != 2*time.Hour || len(state.Compensations) != 1 || state.Compensations[0].Fn != "release" {
+		t.Fatal("unexpected state", state)
+	}
+	if err := sagas.Resume(ctx, saga.Id()); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.calls, []string{"reserve:1", "charge:1", "refund:1", "release:1"}) {
+		t.Fatal("unexpected order", r.calls)
+	}
+	if _, has, _ = runtime.SharedStore(ctx).Get(ctx, []byte("fns:sagas:"+saga.Id())); has {
+		t.Fatal("state must be removed after compensated")
+	}
+	// resuming a finished saga does nothing
+	if err := sagas.Resume(ctx, saga.Id()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -21,18 +21,42 @@ import (
 	"fmt"
 	"github.com/aacfactory/configures"
 	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/defaults"
 	"github.com/aacfactory/fns/commons/versions"
 	"github.com/aacfactory/fns/context"
 	"github.com/aacfactory/fns/logs"
 	"github.com/aacfactory/fns/services/documents"
 	"sort"
+	"time"
 )
 
+// Options
+// Config of service is the node named by the service under services of config file,
+// and Config of component is the node named by the component under the node of its service.
+// Dependencies resolves shared dependencies, see Provider.
 type Options struct {
-	Id      string
-	Version versions.Version
-	Log     logs.Logger
-	Config  configures.Config
+	Id           string
+	Version      versions.Version
+	Log          logs.Logger
+	Config       configures.Config
+	Dependencies Dependencies
+}
+
+// ConfigAs
+// returns the config of options as T, fields which have `default` tag are filled before decoding, see defaults.Fill.
+func ConfigAs[T any](options Options) (v T, err error) {
+	if err = defaults.Fill(&v); err != nil {
+		err = errors.Warning("fns: get config failed").WithCause(err)
+		return
+	}
+	if options.Config == nil {
+		return
+	}
+	if err = options.Config.As(&v); err != nil {
+		err = errors.Warning("fns: get config failed").WithCause(err)
+		return
+	}
+	return
 }
 
 type Service interface {
@@ -48,6 +72,18 @@ type Listenable interface {
 	Listen(ctx context.Context) (err error)
 }
 
+const (
+	DefaultShutdownTimeout = 10 * time.Second
+)
+
+// Drainable
+// is implemented by services which need a shutdown bound other than DefaultShutdownTimeout,
+// e.g. a relay which flushes buffered messages before closing its producer.
+type Drainable interface {
+	Service
+	ShutdownTimeout() (timeout time.Duration)
+}
+
 func NewAbstract(name string, internal bool, components ...Component) Abstract {
 	svc := Abstract{
 		name:       name,
@@ -68,19 +104,23 @@ func NewAbstract(name string, internal bool, components ...Component) Abstract {
 }
 
 type Abstract struct {
-	id         string
-	name       string
-	version    versions.Version
-	internal   bool
-	log        logs.Logger
-	components Components
-	functions  Fns
+	id           string
+	name         string
+	version      versions.Version
+	internal     bool
+	log          logs.Logger
+	config       configures.Config
+	dependencies Dependencies
+	components   Components
+	functions    Fns
 }
 
 func (abstract *Abstract) Construct(options Options) (err error) {
 	abstract.log = options.Log
 	abstract.id = options.Id
 	abstract.version = options.Version
+	abstract.config = options.Config
+	abstract.dependencies = options.Dependencies
 	if abstract.components != nil {
 		for _, component := range abstract.components {
 			config, hasConfig := options.Config.Node(component.Name())
@@ -88,10 +128,11 @@ func (abstract *Abstract) Construct(options Options) (err error) {
 				config, _ = configures.NewJsonConfig([]byte{'{', '}'})
 			}
 			constructErr := component.Construct(Options{
-				Id:      abstract.id,
-				Version: abstract.version,
-				Log:     abstract.log.With("component", component.Name()),
-				Config:  config,
+				Id:           abstract.id,
+				Version:      abstract.version,
+				Log:          abstract.log.With("component", component.Name()),
+				Config:       config,
+				Dependencies: abstract.dependencies,
 			})
 			if constructErr != nil {
 				if abstract.log.ErrorEnabled() {
@@ -100,7 +141,6 @@ func (abstract *Abstract) Construct(options Options) (err error) {
 				err = errors.Warning(fmt.Sprintf("fns: %s construct failed", abstract.name)).WithMeta("service", abstract.name).WithCause(constructErr)
 				return
 			}
-			return
 		}
 	}
 	return
@@ -143,11 +183,11 @@ func (abstract *Abstract) Functions() (functions Fns) {
 	return
 }
 
+// Shutdown
+// shuts components down in reverse order of construction.
 func (abstract *Abstract) Shutdown(ctx context.Context) {
-	if abstract.components != nil && len(abstract.components) > 0 {
-		for _, component := range abstract.components {
-			component.Shutdown(ctx)
-		}
+	for i := len(abstract.components) - 1; i > -1; i-- {
+		abstract.components[i].Shutdown(ctx)
 	}
 	if abstract.log.DebugEnabled() {
 		abstract.log.Debug().Message(fmt.Sprintf("%s: closed", abstract.name))
@@ -155,6 +195,13 @@ func (abstract *Abstract) Shutdown(ctx context.Context) {
 	return
 }
 
+// Config
+// returns the config node of service, see Options.
+func (abstract *Abstract) Config() (config configures.Config) {
+	config = abstract.config
+	return
+}
+
 func (abstract *Abstract) Log() (log logs.Logger) {
 	log = abstract.log
 	return
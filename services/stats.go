@@ -0,0 +1,168 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	fnLatencyBounds = []time.Duration{
+		time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond,
+		10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+		100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+		time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+	}
+	fnCounters sync.Map
+)
+
+// FnCounterOf
+// returns the counter of fn, counters are kept for the lifetime of the process.
+func FnCounterOf(ep []byte, fn []byte) *FnCounter {
+	key := string(ep) + "/" + string(fn)
+	v, has := fnCounters.Load(key)
+	if !has {
+		v, _ = fnCounters.LoadOrStore(key, &FnCounter{
+			endpoint: string(ep),
+			fn:       string(fn),
+			buckets:  make([]atomic.Uint64, len(fnLatencyBounds)+1),
+		})
+	}
+	return v.(*FnCounter)
+}
+
+// FnCounter
+// counts requests of a fn without locks, it is fed by the endpoints handler.
+type FnCounter struct {
+	endpoint   string
+	fn         string
+	requests   atomic.Uint64
+	inflight   atomic.Int64
+	failed     atomic.Uint64
+	latency    atomic.Int64
+	maxLatency atomic.Int64
+	payload    atomic.Uint64
+	buckets    []atomic.Uint64
+}
+
+func (counter *FnCounter) Begin() {
+	counter.inflight.Add(1)
+}
+
+// End
+// records a finished request, payload is the size of request body in bytes.
+func (counter *FnCounter) End(latency time.Duration, err error, payload int) {
+	counter.inflight.Add(-1)
+	counter.requests.Add(1)
+	if err != nil {
+		counter.failed.Add(1)
+	}
+	counter.latency.Add(int64(latency))
+	for {
+		peak := counter.maxLatency.Load()
+		if int64(latency) <= peak || counter.maxLatency.CompareAndSwap(peak, int64(latency)) {
+			break
+		}
+	}
+	if payload > 0 {
+		counter.payload.Add(uint64(payload))
+	}
+	idx := sort.Search(len(fnLatencyBounds), func(i int) bool {
+		return latency <= fnLatencyBounds[i]
+	})
+	counter.buckets[idx].Add(1)
+}
+
+// Stats
+// returns a snapshot of counter, percentiles are upper bounds of latency buckets, so they are approximate.
+func (counter *FnCounter) Stats() (stats FnStats) {
+	requests := counter.requests.Load()
+	stats.Requests = requests
+	stats.InFlight = counter.inflight.Load()
+	stats.Failed = counter.failed.Load()
+	if requests == 0 {
+		return
+	}
+	stats.ErrorRate = float64(stats.Failed) / float64(requests)
+	stats.AvgLatency = time.Duration(counter.latency.Load() / int64(requests))
+	stats.AvgPayloadSize = counter.payload.Load() / requests
+	counts := make([]uint64, len(counter.buckets))
+	total := uint64(0)
+	for i := range counter.buckets {
+		counts[i] = counter.buckets[i].Load()
+		total += counts[i]
+	}
+	stats.P50 = counter.percentile(counts, total, 0.50)
+	stats.P90 = counter.percentile(counts, total, 0.90)
+	stats.P99 = counter.percentile(counts, total, 0.99)
+	return
+}
+
+func (counter *FnCounter) percentile(counts []uint64, total uint64, q float64) time.Duration {
+	if total == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(total))
+	if rank == 0 {
+		rank = 1
+	}
+	seen := uint64(0)
+	for i, count := range counts {
+		seen += count
+		if seen < rank {
+			continue
+		}
+		if i < len(fnLatencyBounds) {
+			return fnLatencyBounds[i]
+		}
+		break
+	}
+	return time.Duration(counter.maxLatency.Load())
+}
+
+type FnStats struct {
+	Requests       uint64        `json:"requests" avro:"requests"`
+	InFlight       int64         `json:"inFlight" avro:"in_flight"`
+	Failed         uint64        `json:"failed" avro:"failed"`
+	ErrorRate      float64       `json:"errorRate" avro:"error_rate"`
+	AvgLatency     time.Duration `json:"avgLatency" avro:"avg_latency"`
+	P50            time.Duration `json:"p50" avro:"p50"`
+	P90            time.Duration `json:"p90" avro:"p90"`
+	P99            time.Duration `json:"p99" avro:"p99"`
+	AvgPayloadSize uint64        `json:"avgPayloadSize" avro:"avg_payload_size"`
+}
+
+// FnStatistics
+// returns stats of every requested fn, grouped by endpoint then fn.
+func FnStatistics() map[string]map[string]FnStats {
+	statistics := make(map[string]map[string]FnStats)
+	fnCounters.Range(func(_, value any) bool {
+		counter := value.(*FnCounter)
+		fns, has := statistics[counter.endpoint]
+		if !has {
+			fns = make(map[string]FnStats)
+			statistics[counter.endpoint] = fns
+		}
+		fns[counter.fn] = counter.Stats()
+		return true
+	})
+	return statistics
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"fmt"
+	"github.com/aacfactory/fns/services"
+	"testing"
+	"time"
+)
+
+func TestFnCounter(t *testing.T) {
+	counter := services.FnCounterOf([]byte("stats"), []byte("get"))
+	for i := 1; i <= 100; i++ {
+		counter.Begin()
+		var err error
+		if i%10 == 0 {
+			err = fmt.Errorf("failed")
+		}
+		counter.End(time.Duration(i)*time.Millisecond, err, 100)
+	}
+	counter.Begin()
+	stats := services.FnStatistics()["stats"]["get"]
+	if stats.Requests != 100 || stats.InFlight != 1 || stats.Failed != 10 || stats.ErrorRate != 0.1 {
+		t.Fatal("unexpected counts", stats)
+	}
+	if stats.AvgPayloadSize != 100 || stats.AvgLatency != 50500*time.Microsecond {
+		t.Fatal("unexpected averages", stats)
+	}
+	if stats.P50 != 50*time.Millisecond || stats.P90 != 100*time.Millisecond || stats.P99 != 100*time.Millisecond {
+		t.Fatal("unexpected percentiles", stats)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	stderrors "errors"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/jsons"
+	"github.com/aacfactory/json"
+	"io"
+	"strconv"
+)
+
+// NewStreamParam
+// param which decodes json from reader on first Unmarshal, so the body is never buffered as a whole.
+// Value reads the rest of reader as json.RawMessage when it is not decoded yet, such as param is sent to other node.
+func NewStreamParam(reader io.Reader) Param {
+	return &streamParam{
+		reader: reader,
+	}
+}
+
+type streamParam struct {
+	reader  io.Reader
+	read    bool
+	raw     json.RawMessage
+	decoded any
+	err     error
+}
+
+func (param *streamParam) Valid() (ok bool) {
+	return true
+}
+
+func (param *streamParam) Unmarshal(dst any) (err error) {
+	if param.read {
+		if param.err != nil {
+			err = param.err
+			return
+		}
+		if param.raw != nil {
+			err = param.raw.Unmarshal(dst)
+			return
+		}
+		err = NewParam(param.decoded).Unmarshal(dst)
+		return
+	}
+	param.read = true
+	decodeErr := jsons.Decode(param.reader, dst)
+	if decodeErr != nil {
+		if stderrors.Is(decodeErr, jsons.ErrTooDeep) {
+			param.err = ErrTooDeepBody.WithMeta("max", strconv.Itoa(jsons.MaxDepth()))
+		} else if codeErr, ok := errors.As(decodeErr); ok {
+			// such as truncated or too large body, which keeps its status
+			param.err = codeErr
+		} else {
+			param.err = errors.Warning("fns: decode stream param failed").WithCause(decodeErr)
+		}
+		err = param.err
+		return
+	}
+	param.decoded = dst
+	return
+}
+
+func (param *streamParam) Value() (v any) {
+	if !param.read {
+		param.read = true
+		p, readErr := io.ReadAll(param.reader)
+		if readErr != nil {
+			param.err = errors.Warning("fns: read stream param failed").WithCause(readErr)
+			return
+		}
+		param.raw = p
+	}
+	if param.raw != nil {
+		v = param.raw
+		return
+	}
+	v = param.decoded
+	return
+}
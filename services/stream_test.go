@@ -0,0 +1,67 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services_test
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/json"
+	"strings"
+	"testing"
+)
+
+type streamParam struct {
+	Id   string `json:"id"`
+	Size int    `json:"size"`
+}
+
+func TestNewStreamParam(t *testing.T) {
+	param := services.NewStreamParam(strings.NewReader(`{"id":"1","size":2}`))
+	v := streamParam{}
+	if err := param.Unmarshal(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Id != "1" || v.Size != 2 {
+		t.Fatal("invalid param", v)
+	}
+	again := streamParam{}
+	if err := param.Unmarshal(&again); err != nil {
+		t.Fatal(err)
+	}
+	if again != v {
+		t.Fatal("invalid param", again)
+	}
+
+	param = services.NewStreamParam(strings.NewReader(`{"id":"2"}`))
+	raw, ok := param.Value().(json.RawMessage)
+	if !ok || string(raw) != `{"id":"2"}` {
+		t.Fatal("invalid value", param.Value())
+	}
+}
+
+func TestNewStreamParam_TooDeep(t *testing.T) {
+	param := services.NewStreamParam(strings.NewReader(strings.Repeat(`{"a":`, 100) + "1" + strings.Repeat("}", 100)))
+	var v any
+	err := param.Unmarshal(&v)
+	if err == nil {
+		t.Fatal("too deep stream param should fail")
+	}
+	if name := errors.Wrap(err).Name(); name != errors.Wrap(services.ErrTooDeepBody).Name() {
+		t.Fatal("unexpected error", err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/aacfactory/fns/commons/futures"
 	"github.com/aacfactory/fns/context"
 	"github.com/aacfactory/fns/services/tracings"
+	"runtime/debug"
 )
 
 type FnTask struct {
@@ -38,6 +39,17 @@ func (task FnTask) Execute(ctx sc.Context) {
 	if hasTrace {
 		trace.Waited()
 	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			stack := debug.Stack()
+			codeErr := panicError(r, recovered)
+			if hasTrace {
+				trace.Finish("succeed", "false", "cause", codeErr.Name())
+			}
+			task.Promise.Failed(codeErr)
+			reportPanic(ctx, r, recovered, stack)
+		}
+	}()
 	v, err := task.Fn.Handle(r)
 	if err != nil {
 		ep, fn := r.Fn()
@@ -0,0 +1,44 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package services
+
+import (
+	"github.com/aacfactory/fns/context"
+)
+
+// Tenant
+// returns the tenant id of the request in ctx, the value is taken from the X-Fns-Tenant header.
+//
+// Propagation:
+//
+//   - requests created by endpoints.Request inherit the tenant of the parent request unless WithTenant is given,
+//     so every hop of a fan-out, whether it stays in the process or crosses the cluster, runs under the same tenant.
+//   - the tenant is a part of the request hash, the singleflight group key and the cache-control etag key,
+//     so results of one tenant are never served to another.
+//   - goroutines forked from ctx keep the tenant, a request built from a fresh context does not.
+//
+// Components such as the @sql database can use it to select schema or database,
+// and validation or permission checks can use it to scope their rules.
+func Tenant(ctx context.Context) (tenant []byte) {
+	r, ok := TryLoadRequest(ctx)
+	if !ok {
+		return
+	}
+	tenant = r.Header().Tenant()
+	return
+}
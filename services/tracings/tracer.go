@@ -19,6 +19,7 @@ package tracings
 
 import (
 	"github.com/aacfactory/fns/commons/bytex"
+	"slices"
 	"time"
 )
 
@@ -124,3 +125,13 @@ func (trace *Tracer) Mount(child *Span) {
 	child.mountChildrenParent()
 	trace.current.Children = append(trace.current.Children, child)
 }
+
+// Chain
+// returns `endpoint.fn` of spans from root to the current one.
+func (trace *Tracer) Chain() (chain []string) {
+	for span := trace.current; span != nil; span = span.parent {
+		chain = append(chain, span.Endpoint+"."+span.Fn)
+	}
+	slices.Reverse(chain)
+	return
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package triggers
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/json"
+)
+
+var (
+	endpointName = []byte("triggers")
+)
+
+// Message
+// message of queue, payload is json which is mapped to param of fn.
+type Message interface {
+	Payload() []byte
+	Ack() (err error)
+	Nack() (err error)
+}
+
+type MessageHandler func(ctx context.Context, message Message)
+
+// Consumer
+// subscribes subject of queue, such as nats or kafka.
+// consumer is a component of triggers service, its config is the node named by consumer in config of triggers service.
+type Consumer interface {
+	services.Component
+	Subscribe(ctx context.Context, subject string, group string, handler MessageHandler) (err error)
+}
+
+// DeadLetterPublisher
+// implemented by consumer which can publish failed messages to dead letter subject.
+type DeadLetterPublisher interface {
+	PublishDeadLetter(ctx context.Context, subject string, message Message) (err error)
+}
+
+// Binding
+// binds subject of queue to a fn, the fn stays ordinary, it is requested internally with payload of message.
+// message is acked when fn succeeds, otherwise it is sent to DeadLetter subject when it is set, or nacked.
+type Binding struct {
+	Subject    string `json:"subject" yaml:"subject"`
+	Group      string `json:"group,omitempty" yaml:"group,omitempty"`
+	Service    string `json:"service" yaml:"service"`
+	Fn         string `json:"fn" yaml:"fn"`
+	DeadLetter string `json:"deadLetter,omitempty" yaml:"deadLetter,omitempty"`
+}
+
+type Config struct {
+	Bindings []Binding `json:"bindings,omitempty" yaml:"bindings,omitempty"`
+}
+
+// New
+// triggers service, bindings are read from config of triggers service.
+func New(consumer Consumer) services.Service {
+	return &service{
+		Abstract: services.NewAbstract(string(endpointName), true, consumer),
+		consumer: consumer,
+	}
+}
+
+type service struct {
+	services.Abstract
+	consumer Consumer
+	bindings []Binding
+}
+
+func (svc *service) Construct(options services.Options) (err error) {
+	err = svc.Abstract.Construct(options)
+	if err != nil {
+		return
+	}
+	if svc.consumer == nil {
+		err = errors.Warning("fns: triggers service construct failed").WithCause(fmt.Errorf("consumer is nil"))
+		return
+	}
+	config := Config{}
+	if configErr := options.Config.As(&config); configErr != nil {
+		err = errors.Warning("fns: triggers service construct failed").WithCause(configErr)
+		return
+	}
+	for _, binding := range config.Bindings {
+		if binding.Subject == "" || binding.Service == "" || binding.Fn == "" {
+			err = errors.Warning("fns: triggers service construct failed").WithCause(fmt.Errorf("subject, service and fn of binding are required")).WithMeta("subject", binding.Subject)
+			return
+		}
+	}
+	svc.bindings = config.Bindings
+	return
+}
+
+func (svc *service) Listen(ctx context.Context) (err error) {
+	for _, binding := range svc.bindings {
+		subErr := svc.consumer.Subscribe(ctx, binding.Subject, binding.Group, svc.handler(binding))
+		if subErr != nil {
+			err = errors.Warning("fns: triggers service listen failed").WithCause(subErr).WithMeta("subject", binding.Subject)
+			return
+		}
+	}
+	return
+}
+
+func (svc *service) handler(binding Binding) MessageHandler {
+	service := bytex.FromString(binding.Service)
+	fn := bytex.FromString(binding.Fn)
+	return func(ctx context.Context, message Message) {
+		log := svc.Log()
+		var param any
+		if payload := message.Payload(); len(payload) > 0 {
+			param = json.RawMessage(payload)
+		}
+		_, err := runtime.Endpoints(ctx).Request(ctx, service, fn, param, services.WithInternalRequest())
+		if err == nil {
+			if ackErr := message.Ack(); ackErr != nil && log.WarnEnabled() {
+				log.Warn().Cause(ackErr).With("subject", binding.Subject).Message("fns: triggers ack message failed")
+			}
+			return
+		}
+		if log.WarnEnabled() {
+			log.Warn().Cause(err).With("subject", binding.Subject).With("service", binding.Service).With("fn", binding.Fn).Message("fns: triggers handle message failed")
+		}
+		if binding.DeadLetter != "" {
+			if publisher, ok := svc.consumer.(DeadLetterPublisher); ok {
+				if dlErr := publisher.PublishDeadLetter(ctx, binding.DeadLetter, message); dlErr == nil {
+					_ = message.Ack()
+					return
+				} else if log.WarnEnabled() {
+					log.Warn().Cause(dlErr).With("subject", binding.DeadLetter).Message("fns: triggers publish dead letter failed")
+				}
+			}
+		}
+		if nackErr := message.Nack(); nackErr != nil && log.WarnEnabled() {
+			log.Warn().Cause(nackErr).With("subject", binding.Subject).Message("fns: triggers nack message failed")
+		}
+	}
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package triggers_test
+
+import (
+	"fmt"
+	"github.com/aacfactory/fns/commons/versions"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services"
+	"github.com/aacfactory/fns/services/commons"
+	"github.com/aacfactory/fns/services/triggers"
+	"github.com/aacfactory/fns/shareds"
+	"github.com/aacfactory/json"
+	"testing"
+)
+
+type memoryMessage struct {
+	payload []byte
+	acked   bool
+	nacked  bool
+}
+
+func (message *memoryMessage) Payload() []byte {
+	return message.payload
+}
+
+func (message *memoryMessage) Ack() (err error) {
+	message.acked = true
+	return
+}
+
+func (message *memoryMessage) Nack() (err error) {
+	message.nacked = true
+	return
+}
+
+type memoryConsumer struct {
+	ctx         context.Context
+	handlers    map[string]triggers.MessageHandler
+	deadLetters map[string][]string
+	deadFailed  bool
+}
+
+func (consumer *memoryConsumer) Name() (name string) {
+	return "queue"
+}
+
+func (consumer *memoryConsumer) Construct(_ services.Options) (err error) {
+	consumer.handlers = make(map[string]triggers.MessageHandler)
+	consumer.deadLetters = make(map[string][]string)
+	return
+}
+
+func (consumer *memoryConsumer) Shutdown(_ context.Context) {
+}
+
+func (consumer *memoryConsumer) Subscribe(ctx context.Context, subject string, _ string, handler triggers.MessageHandler) (err error) {
+	consumer.ctx = ctx
+	consumer.handlers[subject] = handler
+	return
+}
+
+func (consumer *memoryConsumer) PublishDeadLetter(_ context.Context, subject string, message triggers.Message) (err error) {
+	if consumer.deadFailed {
+		err = fmt.Errorf("dead letter is unavailable")
+		return
+	}
+	consumer.deadLetters[subject] = append(consumer.deadLetters[subject], string(message.Payload()))
+	return
+}
+
+func (consumer *memoryConsumer) publish(subject string, payload string) (message *memoryMessage) {
+	message = &memoryMessage{payload: []byte(payload)}
+	consumer.handlers[subject](consumer.ctx, message)
+	return
+}
+
+type orderParam struct {
+	Id   string `json:"id"`
+	Fail bool   `json:"fail"`
+}
+
+func TestTriggers(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	config := services.Config{
+		"triggers": json.RawMessage(`{"bindings":[
+			{"subject":"orders.created","service":"orders","fn":"handle"},
+			{"subject":"orders.paid","service":"orders","fn":"handle","deadLetter":"orders.dead"}
+		]}`),
+	}
+	manager := services.New("id", versions.Origin(), log, config, nil)
+	handled := make([]string, 0, 1)
+	orders := services.NewAbstract("orders", true)
+	orders.AddFunction(commons.NewFn[orderParam, services.Empty]("handle", func(ctx context.Context, param orderParam) (v services.Empty, err error) {
+		if param.Fail {
+			err = fmt.Errorf("order %s failed", param.Id)
+			return
+		}
+		handled = append(handled, param.Id)
+		return
+	}, commons.Internal()))
+	consumer := &memoryConsumer{}
+	trigger := triggers.New(consumer)
+	for _, svc := range []services.Service{&orders, trigger} {
+		if err := manager.Add(svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rt := runtime.New("id", "name", versions.Origin(), nil, log, nil, manager, nil, shared)
+	ctx := runtime.With(context.TODO(), rt)
+	logs.With(ctx, log)
+	if err := trigger.(services.Listenable).Listen(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// ack
+	message := consumer.publish("orders.created", `{"id":"1"}`)
+	if !message.acked || message.nacked || len(handled) != 1 || handled[0] != "1" {
+		t.Fatal("succeeded message must be acked", message, handled)
+	}
+	// nack without dead letter
+	message = consumer.publish("orders.created", `{"id":"2","fail":true}`)
+	if message.acked || !message.nacked {
+		t.Fatal("failed message must be nacked", message)
+	}
+	// dead letter
+	message = consumer.publish("orders.paid", `{"id":"3","fail":true}`)
+	if !message.acked || message.nacked || len(consumer.deadLetters["orders.dead"]) != 1 {
+		t.Fatal("failed message must be sent to dead letter and acked", message, consumer.deadLetters)
+	}
+	// nack when dead letter failed
+	consumer.deadFailed = true
+	message = consumer.publish("orders.paid", `{"id":"4","fail":true}`)
+	if message.acked || !message.nacked || len(consumer.deadLetters["orders.dead"]) != 1 {
+		t.Fatal("message must be nacked when dead letter failed", message, consumer.deadLetters)
+	}
+}
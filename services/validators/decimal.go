@@ -0,0 +1,79 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package validators
+
+import (
+	"fmt"
+	"github.com/aacfactory/fns/commons/decimals"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strconv"
+)
+
+// validateRegisterDecimal
+// decimals.Decimal is validated as its string, so required, omitempty and the following tags work on it.
+// decimal_min=0 and decimal_max=100 are inclusive bounds, decimal_scale=2 limits digits after the decimal point.
+func validateRegisterDecimal(validate *validator.Validate) *validator.Validate {
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		d, ok := field.Interface().(decimals.Decimal)
+		if !ok {
+			return nil
+		}
+		return d.String()
+	}, decimals.Decimal{})
+	for tag, fn := range map[string]func(d decimals.Decimal, param string) bool{
+		"decimal_min": func(d decimals.Decimal, param string) bool {
+			bound, err := decimals.Parse(param)
+			if err != nil {
+				panic(fmt.Errorf("fns: invalid decimal_min %s, %v", param, err))
+			}
+			return !d.LessThan(bound)
+		},
+		"decimal_max": func(d decimals.Decimal, param string) bool {
+			bound, err := decimals.Parse(param)
+			if err != nil {
+				panic(fmt.Errorf("fns: invalid decimal_max %s, %v", param, err))
+			}
+			return !d.GreaterThan(bound)
+		},
+		"decimal_scale": func(d decimals.Decimal, param string) bool {
+			scale, err := strconv.Atoi(param)
+			if err != nil {
+				panic(fmt.Errorf("fns: invalid decimal_scale %s, %v", param, err))
+			}
+			return d.Round(int32(scale)).Equal(d)
+		},
+	} {
+		check := fn
+		err := validate.RegisterValidation(tag, func(fl validator.FieldLevel) (ok bool) {
+			if fl.Field().Type().Kind() != reflect.String {
+				return
+			}
+			d, parseErr := decimals.Parse(fl.Field().String())
+			if parseErr != nil {
+				return
+			}
+			ok = check(d, fl.Param())
+			return
+		})
+		if err != nil {
+			panic(fmt.Errorf("fns: validate register %s failed, %v", tag, err))
+		}
+	}
+	return validate
+}
@@ -21,12 +21,14 @@ import "github.com/go-playground/validator/v10"
 
 func init() {
 	_validator = &validate{
-		validate: validateRegisterRegex(
-			validateRegisterIsUID(
-				validateRegisterNotBlank(
-					validateRegisterNotEmpty(
-						validateRegisterDefault(
-							validator.New(),
+		validate: validateRegisterDecimal(
+			validateRegisterRegex(
+				validateRegisterIsUID(
+					validateRegisterNotBlank(
+						validateRegisterNotEmpty(
+							validateRegisterDefault(
+								validator.New(),
+							),
 						),
 					),
 				),
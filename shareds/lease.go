@@ -0,0 +1,214 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package shareds
+
+import (
+	"bytes"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/uid"
+	"github.com/aacfactory/fns/context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrLockContended = errors.New(http.StatusLocked, "***LOCKED***", "fns: lock is held by another")
+)
+
+var (
+	leaseKeyPrefix   = []byte("fns:shareds:leases:")
+	leaseOwnerSuffix = []byte(":owner")
+)
+
+// Lease
+// is a held lease of key in store, it is renewed every third of ttl until it is released, lost or ctx is done.
+// the owner token of lease is stored beside key with the same ttl, lease is renewed and removed only when the token in store is its own,
+// so a holder which was paused longer than ttl can not extend or remove the lease taken over by another.
+// Store has no compare-and-set, so the compare is best effort, there is a small window between get and expire or remove.
+type Lease struct {
+	store Store
+	key   []byte
+	owner []byte
+	token []byte
+	ttl   time.Duration
+	done  chan struct{}
+	lost  chan struct{}
+	once  sync.Once
+}
+
+// Lost
+// is closed when lease is taken by another or can not be renewed in ttl, or ctx of acquiring is done.
+// it is not closed by Release.
+func (lease *Lease) Lost() <-chan struct{} {
+	return lease.lost
+}
+
+// Release
+// stops renewing and removes the lease when it is still owned, so others can take it at once.
+func (lease *Lease) Release(ctx context.Context) {
+	lease.once.Do(func() {
+		close(lease.done)
+		if owned, _ := lease.owned(ctx); !owned {
+			return
+		}
+		_ = lease.store.Remove(ctx, lease.owner)
+		_ = lease.store.Remove(ctx, lease.key)
+	})
+}
+
+func (lease *Lease) owned(ctx context.Context) (ok bool, err error) {
+	token, has, getErr := lease.store.Get(ctx, lease.owner)
+	if getErr != nil {
+		err = getErr
+		return
+	}
+	ok = has && bytes.Equal(token, lease.token)
+	return
+}
+
+func (lease *Lease) renew(ctx context.Context) (owned bool, err error) {
+	owned, err = lease.owned(ctx)
+	if err != nil || !owned {
+		return
+	}
+	if err = lease.store.Expire(ctx, lease.owner, lease.ttl); err != nil {
+		return
+	}
+	err = lease.store.Expire(ctx, lease.key, lease.ttl)
+	return
+}
+
+func (lease *Lease) renewing(ctx context.Context) {
+	defer close(lease.lost)
+	ticker := time.NewTicker(lease.ttl / 3)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-lease.done:
+			// lost is closed too, but no one is waiting it after release
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			owned, renewErr := lease.renew(ctx)
+			if renewErr != nil {
+				if time.Since(renewed) < lease.ttl {
+					break
+				}
+				return
+			}
+			if !owned {
+				return
+			}
+			renewed = time.Now()
+		}
+	}
+}
+
+// AcquireLease
+// tries to take the lease of key once, ok is false when the lease is held by another.
+// the lease of a dead holder is expired by ttl, contenders never renew it, so it can be taken over after ttl at most.
+// when a holder died before setting ttl, which is known by absent owner token, contenders set ttl of key sometimes.
+func AcquireLease(ctx context.Context, store Store, key []byte, ttl time.Duration) (lease *Lease, ok bool, err error) {
+	if len(key) == 0 {
+		err = errors.Warning("fns: acquire lease failed").WithCause(errors.Warning("key is required"))
+		return
+	}
+	if ttl < 1 {
+		err = errors.Warning("fns: acquire lease failed").WithCause(errors.Warning("ttl is required"))
+		return
+	}
+	key = append(append(make([]byte, 0, len(leaseKeyPrefix)+len(key)), leaseKeyPrefix...), key...)
+	owner := append(append(make([]byte, 0, len(key)+len(leaseOwnerSuffix)), key...), leaseOwnerSuffix...)
+	n, incrErr := store.Incr(ctx, key, 1)
+	if incrErr != nil {
+		err = errors.Warning("fns: acquire lease failed").WithCause(incrErr)
+		return
+	}
+	if n != 1 {
+		if n%1024 == 0 {
+			if _, hasOwner, getErr := store.Get(ctx, owner); getErr == nil && !hasOwner {
+				_ = store.Expire(ctx, key, ttl)
+			}
+		}
+		return
+	}
+	token := uid.Bytes()
+	if setErr := store.SetWithTTL(ctx, owner, token, ttl); setErr != nil {
+		_ = store.Remove(ctx, key)
+		err = errors.Warning("fns: acquire lease failed").WithCause(setErr)
+		return
+	}
+	if expireErr := store.Expire(ctx, key, ttl); expireErr != nil {
+		_ = store.Remove(ctx, owner)
+		_ = store.Remove(ctx, key)
+		err = errors.Warning("fns: acquire lease failed").WithCause(expireErr)
+		return
+	}
+	lease = &Lease{
+		store: store,
+		key:   key,
+		owner: owner,
+		token: token,
+		ttl:   ttl,
+		done:  make(chan struct{}),
+		lost:  make(chan struct{}),
+	}
+	go lease.renewing(ctx)
+	ok = true
+	return
+}
+
+// Lock
+// takes a lease of key in store, see Lease, unlock releases it.
+// it waits until deadline of ctx or ttl when ctx has no deadline, then returns ErrLockContended.
+func Lock(ctx context.Context, store Store, key []byte, ttl time.Duration) (unlock func(), err error) {
+	deadline, hasDeadline := ctx.Deadline()
+	if !hasDeadline {
+		deadline = time.Now().Add(ttl)
+	}
+	backoff := 5 * time.Millisecond
+	for {
+		lease, ok, acquireErr := AcquireLease(ctx, store, key, ttl)
+		if acquireErr != nil {
+			err = errors.Warning("fns: shared lock failed").WithCause(acquireErr)
+			return
+		}
+		if ok {
+			unlock = func() {
+				lease.Release(ctx)
+			}
+			return
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			err = ErrLockContended.WithMeta("key", string(key))
+			return
+		}
+		select {
+		case <-ctx.Done():
+			err = ErrLockContended.WithMeta("key", string(key)).WithCause(ctx.Err())
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 100*time.Millisecond {
+			backoff = backoff * 2
+		}
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package shareds_test
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/shareds"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	store := shared.Store()
+	ctx := context.TODO()
+	key := []byte("lock")
+	unlock, lockErr := shareds.Lock(ctx, store, key, 300*time.Millisecond)
+	if lockErr != nil {
+		t.Fatal(lockErr)
+	}
+	// contended until timeout, the lease is renewed while held
+	_, contendedErr := shareds.Lock(ctx, store, key, 500*time.Millisecond)
+	if contendedErr == nil {
+		t.Fatal("lock must be contended")
+	}
+	if !errors.Wrap(contendedErr).Contains(shareds.ErrLockContended) {
+		t.Fatal("want ErrLockContended, got", contendedErr)
+	}
+	unlock()
+	unlock()
+	unlock, lockErr = shareds.Lock(ctx, store, key, 300*time.Millisecond)
+	if lockErr != nil {
+		t.Fatal(lockErr)
+	}
+	unlock()
+	// mutual exclusion
+	wg := sync.WaitGroup{}
+	holding := 0
+	n := 0
+	mutex := sync.Mutex{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := shareds.Lock(ctx, store, key, 2*time.Second)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mutex.Lock()
+			holding++
+			if holding > 1 {
+				t.Error("lock is held by more than one")
+			}
+			mutex.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mutex.Lock()
+			holding--
+			n++
+			mutex.Unlock()
+			release()
+		}()
+	}
+	wg.Wait()
+	if n != 10 {
+		t.Fatal("want 10, got", n)
+	}
+}
+
+func TestAcquireLease_Takeover(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	shared, sharedErr := shareds.Local(log, shareds.LocalSharedConfig{})
+	if sharedErr != nil {
+		t.Fatal(sharedErr)
+	}
+	store := shared.Store()
+	key := []byte("takeover")
+	ttl := 300 * time.Millisecond
+	// holder stops renewing when its ctx is done, as if it died
+	holderCtx, cancel := context.WithCancel(context.TODO())
+	holder, ok, err := shareds.AcquireLease(holderCtx, store, key, ttl)
+	if err != nil || !ok {
+		t.Fatal("holder must take the lease", ok, err)
+	}
+	ctx := context.TODO()
+	// contenders must not extend the lease of holder
+	for i := 0; i < 2048; i++ {
+		if _, ok, err = shareds.AcquireLease(ctx, store, key, ttl); err != nil || ok {
+			t.Fatal("lease is held by holder", ok, err)
+		}
+	}
+	cancel()
+	select {
+	case <-holder.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("holder was not notified")
+	}
+	time.Sleep(ttl + 50*time.Millisecond)
+	taker, ok, err := shareds.AcquireLease(ctx, store, key, ttl)
+	if err != nil || !ok {
+		t.Fatal("lease must be taken over after ttl", ok, err)
+	}
+	// release of old holder must not remove the lease of taker
+	holder.Release(ctx)
+	if _, ok, err = shareds.AcquireLease(ctx, store, key, ttl); err != nil || ok {
+		t.Fatal("lease of taker was removed by old holder", ok, err)
+	}
+	// renewed by taker
+	time.Sleep(2 * ttl)
+	if _, ok, _ = shareds.AcquireLease(ctx, store, key, ttl); ok {
+		t.Fatal("lease of taker was not renewed")
+	}
+	select {
+	case <-taker.Lost():
+		t.Fatal("taker must not lose the lease")
+	default:
+	}
+	taker.Release(ctx)
+	if _, ok, err = shareds.AcquireLease(ctx, store, key, ttl); err != nil || !ok {
+		t.Fatal("lease must be taken after release", ok, err)
+	}
+}
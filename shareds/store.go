@@ -170,6 +170,11 @@ func (store *localStore) Incr(_ context.Context, key []byte, delta int64) (v int
 	if exist {
 		if entry.Expired() {
 			n = new(atomic.Int64)
+			entry = Entry{
+				key:      sk,
+				Value:    n,
+				Deadline: time.Time{},
+			}
 		} else {
 			nn, ok := entry.Value.(*atomic.Int64)
 			if !ok {
@@ -23,6 +23,7 @@ import (
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/barriers"
 	"github.com/aacfactory/fns/clusters"
+	"github.com/aacfactory/fns/commons/jsons"
 	"github.com/aacfactory/fns/commons/switchs"
 	"github.com/aacfactory/fns/commons/versions"
 	"github.com/aacfactory/fns/configs"
@@ -57,6 +58,7 @@ var (
 
 type Options struct {
 	deps                  []services.Service
+	mocks                 []services.Service
 	config                *configs.Config
 	configRetrieverOption configures.RetrieverOption
 	configActive          string
@@ -72,6 +74,16 @@ func WithDependence(dep ...services.Service) Option {
 	}
 }
 
+// WithMock
+// installs mocked services, e.g. (&users.Mock{Get: ...}).Service(),
+// a dependence which has the same name as a mock is replaced by the mock.
+func WithMock(mock ...services.Service) Option {
+	return func(options *Options) (err error) {
+		options.mocks = append(options.mocks, mock...)
+		return
+	}
+}
+
 func Config() configs.Config {
 	return configs.New()
 }
@@ -99,7 +111,14 @@ func WithConfigRetriever(path string, format string, active string, prefix strin
 		}
 		active = strings.TrimSpace(active)
 		format = strings.ToUpper(strings.TrimSpace(format))
-		store := configures.NewFileStore(path, prefix, splitter)
+		// empty format means format of each file is decided by its extension
+		var store configures.Store
+		if format == "" {
+			format = "JSON"
+			store = configs.NewFileStore(path, prefix, splitter)
+		} else {
+			store = configures.NewFileStore(path, prefix, splitter)
+		}
 		options.configRetrieverOption = configures.RetrieverOption{
 			Active: active,
 			Format: format,
@@ -152,6 +171,7 @@ func getConfigDir(src string) (dir string, err error) {
 func Setup(service services.Service, options ...Option) (err error) {
 	opt := Options{
 		deps:                  nil,
+		mocks:                 nil,
 		config:                nil,
 		configRetrieverOption: configures.RetrieverOption{},
 		configActive:          "local",
@@ -181,11 +201,11 @@ func Setup(service services.Service, options ...Option) (err error) {
 			}
 			opt.configRetrieverOption = configures.RetrieverOption{
 				Active: opt.configActive,
-				Format: "YAML",
-				Store:  configures.NewFileStore(configDir, "fns", '-'),
+				Format: "JSON",
+				Store:  configs.NewFileStore(configDir, "fns", '-'),
 			}
 		}
-		configRetriever, configRetrieverErr := configures.NewRetriever(opt.configRetrieverOption)
+		configRetriever, configRetrieverErr := configs.NewRetriever(opt.configRetrieverOption)
 		if configRetrieverErr != nil {
 			err = errors.Warning("fns: setup testing failed").WithCause(configRetrieverErr)
 			return
@@ -223,6 +243,20 @@ func Setup(service services.Service, options ...Option) (err error) {
 	// manager
 	var manager services.EndpointsManager
 
+	timeFormat, timeFormatErr := jsons.ParseTimeFormat(config.Runtime.TimeFormat)
+	if timeFormatErr != nil {
+		err = errors.Warning("fns: setup testing failed").WithCause(timeFormatErr)
+		return
+	}
+	jsons.SetTimeFormat(timeFormat)
+	durationFormat, durationFormatErr := jsons.ParseDurationFormat(config.Runtime.DurationFormat)
+	if durationFormatErr != nil {
+		err = errors.Warning("fns: setup testing failed").WithCause(durationFormatErr)
+		return
+	}
+	jsons.SetDurationFormat(durationFormat)
+	jsons.SetMaxDepth(config.Runtime.MaxJsonDepth)
+	services.SetMaxRequestDepth(config.Runtime.MaxRequestDepth)
 	local := services.New(appId, appVersion, logger.With("fns", "endpoints"), config.Services, worker)
 
 	// barrier
@@ -251,7 +285,7 @@ func Setup(service services.Service, options ...Option) (err error) {
 			Log:     logger.With("fns", "cluster"),
 			Worker:  worker,
 			Local:   local,
-			Dialer:  opt.transport,
+			Dialer:  transports.BasePathDialer(opt.transport, config.Transport.GetBasePath()),
 			Config:  clusterConfig,
 		})
 		if clusterErr != nil {
@@ -275,12 +309,22 @@ func Setup(service services.Service, options ...Option) (err error) {
 		return
 	}
 	for _, dep := range opt.deps {
+		if isMocked(opt.mocks, dep.Name()) {
+			continue
+		}
 		depErr := manager.Add(dep)
 		if depErr != nil {
 			err = errors.Warning("fns: setup testing failed").WithCause(depErr)
 			return
 		}
 	}
+	for _, mock := range opt.mocks {
+		mockErr := manager.Add(mock)
+		if mockErr != nil {
+			err = errors.Warning("fns: setup testing failed").WithCause(mockErr).WithMeta("mock", mock.Name())
+			return
+		}
+	}
 
 	// runtime
 	rt := runtime.New(
@@ -295,6 +339,13 @@ func Setup(service services.Service, options ...Option) (err error) {
 		manager: manager,
 	}
 
+	// warmup
+	warmErr := manager.Warmup(TODO())
+	if warmErr != nil {
+		err = errors.Warning("fns: setup testing failed").WithCause(warmErr)
+		return
+	}
+
 	return
 }
 
@@ -309,3 +360,12 @@ func TODO() context.Context {
 	logs.With(ctx, app.rt.RootLog().With("tests", "testing"))
 	return ctx
 }
+
+func isMocked(mocks []services.Service, name string) bool {
+	for _, mock := range mocks {
+		if mock.Name() == name {
+			return true
+		}
+	}
+	return false
+}
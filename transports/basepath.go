@@ -0,0 +1,135 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transports
+
+import (
+	"bytes"
+	"github.com/aacfactory/fns/context"
+	"strings"
+)
+
+var (
+	slashBytes         = []byte{'/'}
+	basePathContextKey = []byte("@fns:context:transports:basePath")
+)
+
+// BasePath
+// returns base path of request which was stripped by mux, such as `/api`, it is empty when there is no base path.
+// handlers use it to build public urls, such as servers of openapi document.
+func BasePath(ctx context.Context) (basePath []byte) {
+	basePath, _ = ctx.LocalValue(basePathContextKey).([]byte)
+	return
+}
+
+// NormalizeBasePath
+// returns base path like `/api`, empty means no base path.
+func NormalizeBasePath(basePath string) string {
+	basePath = strings.Trim(strings.TrimSpace(basePath), "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// StripBasePath
+// returns path without base path, ok is false when path is not under base path.
+func StripBasePath(basePath []byte, path []byte) (stripped []byte, ok bool) {
+	if len(basePath) == 0 {
+		stripped, ok = path, true
+		return
+	}
+	stripped, ok = bytes.CutPrefix(path, basePath)
+	if !ok {
+		return
+	}
+	if len(stripped) == 0 {
+		stripped = slashBytes
+		return
+	}
+	if stripped[0] != '/' {
+		stripped, ok = nil, false
+		return
+	}
+	return
+}
+
+type basePathRequest struct {
+	Request
+	basePath []byte
+	path     []byte
+}
+
+func (r *basePathRequest) Path() []byte {
+	return r.path
+}
+
+func (r *basePathRequest) EscapedPath() []byte {
+	er, ok := r.Request.(EscapedPathRequest)
+	if !ok {
+		return nil
+	}
+	stripped, _ := StripBasePath(r.basePath, er.EscapedPath())
+	return stripped
+}
+
+// BasePathDialer
+// prefixes base path to paths of dialed clients.
+func BasePathDialer(dialer Dialer, basePath string) Dialer {
+	basePath = NormalizeBasePath(basePath)
+	if basePath == "" {
+		return dialer
+	}
+	return &basePathDialer{
+		dialer:   dialer,
+		basePath: []byte(basePath),
+	}
+}
+
+type basePathDialer struct {
+	dialer   Dialer
+	basePath []byte
+}
+
+func (dialer *basePathDialer) Dial(address []byte) (client Client, err error) {
+	client, err = dialer.dialer.Dial(address)
+	if err != nil {
+		return
+	}
+	client = &basePathClient{
+		client:   client,
+		basePath: dialer.basePath,
+	}
+	return
+}
+
+type basePathClient struct {
+	client   Client
+	basePath []byte
+}
+
+func (client *basePathClient) Do(ctx context.Context, method []byte, path []byte, header Header, body []byte) (status int, responseHeader Header, responseBody []byte, err error) {
+	p := make([]byte, 0, len(client.basePath)+len(path))
+	p = append(p, client.basePath...)
+	p = append(p, path...)
+	status, responseHeader, responseBody, err = client.client.Do(ctx, method, p, header, body)
+	return
+}
+
+func (client *basePathClient) Close() {
+	client.client.Close()
+}
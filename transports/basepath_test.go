@@ -0,0 +1,48 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transports_test
+
+import (
+	"github.com/aacfactory/fns/transports"
+	"testing"
+)
+
+func TestStripBasePath(t *testing.T) {
+	basePath := []byte(transports.NormalizeBasePath(" api/ "))
+	if string(basePath) != "/api" {
+		t.Fatal("normalize base path failed:", string(basePath))
+	}
+	cases := map[string]string{
+		"/api/users/get": "/users/get",
+		"/api":           "/",
+		"/apix/users":    "",
+		"/users/get":     "",
+	}
+	for path, expect := range cases {
+		stripped, ok := transports.StripBasePath(basePath, []byte(path))
+		if expect == "" {
+			if ok {
+				t.Error(path, "should not be stripped")
+			}
+			continue
+		}
+		if !ok || string(stripped) != expect {
+			t.Error(path, "stripped to", string(stripped), "but expect", expect)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transports
+
+import (
+	"github.com/aacfactory/fns/context"
+)
+
+type ClientDo func(ctx context.Context, method []byte, path []byte, header Header, body []byte) (status int, responseHeader Header, responseBody []byte, err error)
+
+// ClientMiddleware
+// outbound interceptor of client, it is like Middleware of server.
+type ClientMiddleware func(next ClientDo) ClientDo
+
+// ClientMiddlewaresDialer
+// wraps clients of dialer by middlewares, the first middleware is the outermost.
+func ClientMiddlewaresDialer(dialer Dialer, middlewares ...ClientMiddleware) Dialer {
+	if len(middlewares) == 0 {
+		return dialer
+	}
+	return &clientMiddlewaresDialer{
+		dialer:      dialer,
+		middlewares: middlewares,
+	}
+}
+
+type clientMiddlewaresDialer struct {
+	dialer      Dialer
+	middlewares []ClientMiddleware
+}
+
+func (dialer *clientMiddlewaresDialer) Dial(address []byte) (client Client, err error) {
+	client, err = dialer.dialer.Dial(address)
+	if err != nil {
+		return
+	}
+	do := client.Do
+	for i := len(dialer.middlewares) - 1; i > -1; i-- {
+		do = dialer.middlewares[i](do)
+	}
+	client = &middlewareClient{
+		client: client,
+		do:     do,
+	}
+	return
+}
+
+type middlewareClient struct {
+	client Client
+	do     ClientDo
+}
+
+func (client *middlewareClient) Do(ctx context.Context, method []byte, path []byte, header Header, body []byte) (status int, responseHeader Header, responseBody []byte, err error) {
+	status, responseHeader, responseBody, err = client.do(ctx, method, path, header, body)
+	return
+}
+
+func (client *middlewareClient) Close() {
+	client.client.Close()
+}
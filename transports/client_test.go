@@ -0,0 +1,89 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transports_test
+
+import (
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/transports"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type recordClient struct {
+	calls  *[]string
+	closed bool
+}
+
+func (client *recordClient) Do(_ context.Context, _ []byte, _ []byte, header transports.Header, _ []byte) (status int, responseHeader transports.Header, responseBody []byte, err error) {
+	*client.calls = append(*client.calls, "client:"+string(header.Get([]byte("X-Trace"))))
+	status = http.StatusOK
+	responseHeader = transports.NewHeader()
+	responseBody = []byte("ok")
+	return
+}
+
+func (client *recordClient) Close() {
+	client.closed = true
+}
+
+type recordDialer struct {
+	client *recordClient
+}
+
+func (dialer *recordDialer) Dial(_ []byte) (client transports.Client, err error) {
+	client = dialer.client
+	return
+}
+
+func recordMiddleware(name string, calls *[]string) transports.ClientMiddleware {
+	return func(next transports.ClientDo) transports.ClientDo {
+		return func(ctx context.Context, method []byte, path []byte, header transports.Header, body []byte) (status int, responseHeader transports.Header, responseBody []byte, err error) {
+			*calls = append(*calls, name+">")
+			header.Add([]byte("X-Trace"), []byte(name))
+			status, responseHeader, responseBody, err = next(ctx, method, path, header, body)
+			*calls = append(*calls, "<"+name)
+			return
+		}
+	}
+}
+
+func TestClientMiddlewaresDialer(t *testing.T) {
+	calls := make([]string, 0, 1)
+	origin := &recordDialer{client: &recordClient{calls: &calls}}
+	if transports.ClientMiddlewaresDialer(origin) != origin {
+		t.Fatal("dialer without middlewares must not be wrapped")
+	}
+	dialer := transports.ClientMiddlewaresDialer(origin, recordMiddleware("a", &calls), recordMiddleware("b", &calls))
+	client, dialErr := dialer.Dial([]byte("127.0.0.1:18080"))
+	if dialErr != nil {
+		t.Fatal(dialErr)
+	}
+	status, _, body, err := client.Do(context.TODO(), []byte(http.MethodGet), []byte("/"), transports.NewHeader(), nil)
+	if err != nil || status != http.StatusOK || string(body) != "ok" {
+		t.Fatal("unexpected response", status, string(body), err)
+	}
+	// first middleware is the outermost
+	if !reflect.DeepEqual(calls, []string{"a>", "b>", "client:a", "<b", "<a"}) {
+		t.Fatal("unexpected order", calls)
+	}
+	client.Close()
+	if !origin.client.closed {
+		t.Fatal("close must be passed to wrapped client")
+	}
+}
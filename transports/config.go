@@ -23,6 +23,8 @@ import (
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/transports/ssl"
 	"github.com/aacfactory/json"
+	"net"
+	"strconv"
 	"strings"
 )
 
@@ -67,13 +69,27 @@ func (config *TLSConfig) Config() (conf ssl.Config, err error) {
 }
 
 type Config struct {
+	// Name
+	// name of registered transport, such as fasthttp (default) and standard, see Register.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Host
+	// bind host, such as 127.0.0.1 for local only, or ip of an interface, default is all interfaces.
+	Host string `json:"host,omitempty" yaml:"host,omitempty"`
+	// Network
+	// address family of listener, tcp (default), tcp4 or tcp6.
+	Network     string          `json:"network,omitempty" yaml:"network,omitempty"`
 	Port        int             `json:"port,omitempty" yaml:"port,omitempty"`
+	BasePath    string          `json:"basePath,omitempty" yaml:"basePath,omitempty"`
 	TLS         *TLSConfig      `json:"tls,omitempty" yaml:"tls,omitempty"`
 	Options     json.RawMessage `json:"options,omitempty" yaml:"options,omitempty"`
 	Middlewares json.RawMessage `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
 	Handlers    json.RawMessage `json:"handlers,omitempty" yaml:"handlers,omitempty"`
 }
 
+func (config *Config) GetBasePath() string {
+	return NormalizeBasePath(config.BasePath)
+}
+
 func (config *Config) GetPort() (port int, err error) {
 	port = config.Port
 	if port == 0 {
@@ -90,6 +106,56 @@ func (config *Config) GetPort() (port int, err error) {
 	return
 }
 
+// GetListenAddress
+// returns network and address of listener, host is validated against network,
+// and host which is not an ip must be resolvable.
+func (config *Config) GetListenAddress() (network string, address string, err error) {
+	port, portErr := config.GetPort()
+	if portErr != nil {
+		err = portErr
+		return
+	}
+	network = strings.ToLower(strings.TrimSpace(config.Network))
+	switch network {
+	case "":
+		network = "tcp"
+		break
+	case "tcp", "tcp4", "tcp6":
+		break
+	default:
+		err = errors.Warning("network is invalid, network must be tcp, tcp4 or tcp6").WithMeta("network", config.Network)
+		return
+	}
+	host := strings.TrimSpace(config.Host)
+	if host != "" {
+		var ips []net.IP
+		if ip := net.ParseIP(host); ip != nil {
+			ips = append(ips, ip)
+		} else {
+			resolved, lookupErr := net.LookupIP(host)
+			if lookupErr != nil {
+				err = errors.Warning("host is invalid, host must be ip or resolvable").WithMeta("host", host).WithCause(lookupErr)
+				return
+			}
+			ips = resolved
+		}
+		matched := false
+		for _, ip := range ips {
+			isV4 := ip.To4() != nil
+			if network == "tcp" || (network == "tcp4" && isV4) || (network == "tcp6" && !isV4) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			err = errors.Warning("host is invalid, host does not match network").WithMeta("host", host).WithMeta("network", network)
+			return
+		}
+	}
+	address = net.JoinHostPort(host, strconv.Itoa(port))
+	return
+}
+
 func (config *Config) GetTLS() (tls ssl.Config, err error) {
 	if config.TLS == nil {
 		return
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transports_test
+
+import (
+	"github.com/aacfactory/fns/transports"
+	"testing"
+)
+
+func TestConfig_GetListenAddress(t *testing.T) {
+	for _, c := range []struct {
+		config  transports.Config
+		network string
+		address string
+		failed  bool
+	}{
+		{config: transports.Config{Port: 8080}, network: "tcp", address: ":8080"},
+		{config: transports.Config{Host: "127.0.0.1", Port: 8080}, network: "tcp", address: "127.0.0.1:8080"},
+		{config: transports.Config{Host: "::1", Network: "tcp6", Port: 8080}, network: "tcp6", address: "[::1]:8080"},
+		{config: transports.Config{Host: "::1", Network: "tcp4", Port: 8080}, failed: true},
+		{config: transports.Config{Network: "udp", Port: 8080}, failed: true},
+	} {
+		network, address, err := c.config.GetListenAddress()
+		if c.failed {
+			if err == nil {
+				t.Fatal("want failed", c.config.Host, c.config.Network)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if network != c.network || address != c.address {
+			t.Fatal("unexpected", network, address)
+		}
+	}
+}
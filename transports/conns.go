@@ -0,0 +1,193 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transports
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type ConnState int
+
+const (
+	ConnNew ConnState = iota
+	ConnActive
+	ConnIdle
+	ConnHijacked
+	ConnClosed
+)
+
+var (
+	connMetrics = sync.Map{}
+)
+
+// ConnMetricsOf
+// returns metrics of connections of listener at address, servers of transports feed it by their conn state callbacks.
+func ConnMetricsOf(address string) *ConnMetrics {
+	v, has := connMetrics.Load(address)
+	if !has {
+		v, _ = connMetrics.LoadOrStore(address, &ConnMetrics{})
+	}
+	return v.(*ConnMetrics)
+}
+
+// ConnMetrics
+// counts connections by state, a connection is active while a request of it is handling, and idle while it is kept alive.
+type ConnMetrics struct {
+	states           sync.Map
+	active           atomic.Int64
+	idle             atomic.Int64
+	accepted         atomic.Int64
+	closed           atomic.Int64
+	closedOnShutdown atomic.Int64
+	hijacked         atomic.Int64
+	shutdown         atomic.Bool
+	rateMutex        sync.Mutex
+	rateSecond       int64
+	rateCurrent      int64
+	ratePrevious     int64
+}
+
+func (metrics *ConnMetrics) SetState(conn net.Conn, state ConnState) {
+	prev, hasPrev := metrics.states.Load(conn)
+	if hasPrev {
+		switch prev.(ConnState) {
+		case ConnActive:
+			metrics.active.Add(-1)
+			break
+		case ConnIdle:
+			metrics.idle.Add(-1)
+			break
+		default:
+			break
+		}
+	}
+	switch state {
+	case ConnNew:
+		metrics.accepted.Add(1)
+		metrics.accept(time.Now().Unix())
+		metrics.states.Store(conn, state)
+		break
+	case ConnActive:
+		metrics.active.Add(1)
+		metrics.states.Store(conn, state)
+		break
+	case ConnIdle:
+		metrics.idle.Add(1)
+		metrics.states.Store(conn, state)
+		break
+	case ConnHijacked:
+		metrics.hijacked.Add(1)
+		metrics.states.Delete(conn)
+		break
+	case ConnClosed:
+		metrics.closed.Add(1)
+		if metrics.shutdown.Load() {
+			metrics.closedOnShutdown.Add(1)
+		}
+		metrics.states.Delete(conn)
+		break
+	}
+}
+
+// ShuttingDown
+// marks listener is shutting down, then closed connections are counted as closed on shutdown too.
+func (metrics *ConnMetrics) ShuttingDown() {
+	metrics.shutdown.Store(true)
+}
+
+func (metrics *ConnMetrics) accept(second int64) {
+	metrics.rateMutex.Lock()
+	metrics.roll(second)
+	metrics.rateCurrent++
+	metrics.rateMutex.Unlock()
+}
+
+func (metrics *ConnMetrics) roll(second int64) {
+	if metrics.rateSecond == second {
+		return
+	}
+	if metrics.rateSecond == second-1 {
+		metrics.ratePrevious = metrics.rateCurrent
+	} else {
+		metrics.ratePrevious = 0
+	}
+	metrics.rateCurrent = 0
+	metrics.rateSecond = second
+}
+
+func (metrics *ConnMetrics) Stats() ConnStats {
+	metrics.rateMutex.Lock()
+	metrics.roll(time.Now().Unix())
+	rate := metrics.ratePrevious
+	metrics.rateMutex.Unlock()
+	active, idle := metrics.active.Load(), metrics.idle.Load()
+	accepted, closed, hijacked := metrics.accepted.Load(), metrics.closed.Load(), metrics.hijacked.Load()
+	return ConnStats{
+		Open:             accepted - closed - hijacked,
+		Active:           active,
+		Idle:             idle,
+		Accepted:         accepted,
+		AcceptRate:       rate,
+		Closed:           closed,
+		ClosedOnShutdown: metrics.closedOnShutdown.Load(),
+		Hijacked:         hijacked,
+	}
+}
+
+type ConnStats struct {
+	// Open
+	// connections which are not closed or hijacked, including ones which are neither active nor idle yet.
+	Open   int64 `json:"open" avro:"open"`
+	Active int64 `json:"active" avro:"active"`
+	Idle   int64 `json:"idle" avro:"idle"`
+	// Accepted
+	// total of accepted connections since listener started.
+	Accepted int64 `json:"accepted" avro:"accepted"`
+	// AcceptRate
+	// connections accepted in the last whole second.
+	AcceptRate       int64 `json:"acceptRate" avro:"accept_rate"`
+	Closed           int64 `json:"closed" avro:"closed"`
+	ClosedOnShutdown int64 `json:"closedOnShutdown" avro:"closed_on_shutdown"`
+	Hijacked         int64 `json:"hijacked" avro:"hijacked"`
+}
+
+type ListenerConnStats struct {
+	Address string `json:"address" avro:"address"`
+	ConnStats
+}
+
+// ConnStatistics
+// returns connection stats of listeners, sorted by address.
+func ConnStatistics() (stats []ListenerConnStats) {
+	stats = make([]ListenerConnStats, 0, 1)
+	connMetrics.Range(func(key, value any) bool {
+		stats = append(stats, ListenerConnStats{
+			Address:   key.(string),
+			ConnStats: value.(*ConnMetrics).Stats(),
+		})
+		return true
+	})
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Address < stats[j].Address
+	})
+	return
+}
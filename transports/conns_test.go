@@ -0,0 +1,55 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transports_test
+
+import (
+	"github.com/aacfactory/fns/transports"
+	"net"
+	"testing"
+)
+
+func TestConnMetrics(t *testing.T) {
+	metrics := transports.ConnMetricsOf("127.0.0.1:18080")
+	a, _ := net.Pipe()
+	b, _ := net.Pipe()
+	metrics.SetState(a, transports.ConnNew)
+	metrics.SetState(b, transports.ConnNew)
+	metrics.SetState(a, transports.ConnActive)
+	metrics.SetState(b, transports.ConnActive)
+	metrics.SetState(b, transports.ConnIdle)
+	stats := metrics.Stats()
+	if stats.Open != 2 || stats.Active != 1 || stats.Idle != 1 || stats.Accepted != 2 {
+		t.Fatal("unexpected stats", stats)
+	}
+	metrics.SetState(a, transports.ConnClosed)
+	metrics.ShuttingDown()
+	metrics.SetState(b, transports.ConnClosed)
+	stats = metrics.Stats()
+	if stats.Open != 0 || stats.Active != 0 || stats.Idle != 0 || stats.Closed != 2 || stats.ClosedOnShutdown != 1 {
+		t.Fatal("unexpected stats after closed", stats)
+	}
+	found := false
+	for _, listener := range transports.ConnStatistics() {
+		if listener.Address == "127.0.0.1:18080" {
+			found = listener.Accepted == 2
+		}
+	}
+	if !found {
+		t.Fatal("listener is not in statistics")
+	}
+}
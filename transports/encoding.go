@@ -20,14 +20,14 @@ package transports
 import (
 	"bytes"
 	"github.com/aacfactory/avro"
-	"github.com/aacfactory/json"
+	"github.com/aacfactory/fns/commons/jsons"
 )
 
 type Marshal func(v any) (p []byte, err error)
 
 func GetMarshaler(ct []byte) (v Marshal, contentType []byte) {
 	if len(ct) == 0 {
-		v = json.Marshal
+		v = jsons.Marshal
 		contentType = ContentTypeJsonHeaderValue
 		return
 	}
@@ -36,7 +36,7 @@ func GetMarshaler(ct []byte) (v Marshal, contentType []byte) {
 		contentType = ContentTypeAvroHeaderValue
 		return
 	}
-	v = json.Marshal
+	v = jsons.Marshal
 	contentType = ContentTypeJsonHeaderValue
 	return
 }
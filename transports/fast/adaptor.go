@@ -29,7 +29,19 @@ var (
 	ctxPool = sync.Pool{}
 )
 
-func handlerAdaptor(h transports.Handler, writeTimeout time.Duration) fasthttp.RequestHandler {
+// headerGuard
+// answers requests whose header is larger than maxSize or has more fields than maxCount with 431.
+func headerGuard(h fasthttp.RequestHandler, maxSize int, maxCount int) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if len(ctx.Request.Header.RawHeaders()) > maxSize || ctx.Request.Header.Len() > maxCount {
+			failed(ctx, transports.ErrTooLargeRequestHeader)
+			return
+		}
+		h(ctx)
+	}
+}
+
+func handlerAdaptor(h transports.Handler, writeTimeout time.Duration, disconnectCheckInterval time.Duration) fasthttp.RequestHandler {
 	return func(ctx *fasthttp.RequestCtx) {
 		var c *Context
 		cc := ctxPool.Get()
@@ -44,12 +56,19 @@ func handlerAdaptor(h transports.Handler, writeTimeout time.Duration) fasthttp.R
 			Context: c,
 		}
 		result := transports.AcquireResultResponseWriter(writeTimeout, r.Header().Get(transports.ContentTypeHeaderName))
+		transports.NegotiateProblem(result, &r)
 		w := ResponseWriter{
 			Context: c,
 			result:  result,
 		}
 
-		h.Handle(&w, &r)
+		if disconnectCheckInterval > 0 {
+			stop := c.watch(disconnectCheckInterval)
+			h.Handle(&w, &r)
+			stop()
+		} else {
+			h.Handle(&w, &r)
+		}
 		ctx.SetStatusCode(w.Status())
 		w.result.Header().Foreach(func(key []byte, values [][]byte) {
 			for _, value := range values {
@@ -225,11 +225,30 @@ func (client *Client) Do(ctx context.Context, method []byte, path []byte, header
 	resp := fasthttp.AcquireResponse()
 
 	// do
-	deadline, hasDeadline := ctx.Deadline()
-	if hasDeadline {
-		err = client.host.DoDeadline(req, resp, deadline)
+	deadline, _ := ctx.Deadline()
+	if done := ctx.Done(); done == nil {
+		err = client.do(deadline, req, resp)
 	} else {
-		err = client.host.Do(req, resp)
+		// fasthttp does not watch ctx, so wait in another goroutine and give up on cancellation,
+		// req and resp are released when the abandoned call returns.
+		ch := make(chan error, 1)
+		go func(deadline time.Time, req *fasthttp.Request, resp *fasthttp.Response, ch chan error) {
+			ch <- client.do(deadline, req, resp)
+		}(deadline, req, resp, ch)
+		select {
+		case err = <-ch:
+			break
+		case <-done:
+			go func(req *fasthttp.Request, resp *fasthttp.Response, ch chan error) {
+				<-ch
+				fasthttp.ReleaseRequest(req)
+				fasthttp.ReleaseResponse(resp)
+			}(req, resp, ch)
+			err = errors.Warning("fns: transport client do failed").
+				WithCause(ctx.Err()).
+				WithMeta("transport", transportName).WithMeta("method", bytex.ToString(method)).WithMeta("path", bytex.ToString(path))
+			return
+		}
 	}
 
 	if err != nil {
@@ -255,6 +274,15 @@ func (client *Client) Do(ctx context.Context, method []byte, path []byte, header
 	return
 }
 
+func (client *Client) do(deadline time.Time, req *fasthttp.Request, resp *fasthttp.Response) (err error) {
+	if !deadline.IsZero() {
+		err = client.host.DoDeadline(req, resp, deadline)
+	} else {
+		err = client.host.Do(req, resp)
+	}
+	return
+}
+
 func (client *Client) Close() {
 	client.host.CloseIdleConnections()
 }
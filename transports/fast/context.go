@@ -18,13 +18,23 @@
 package fast
 
 import (
+	"fmt"
 	"github.com/aacfactory/fns/context"
 	"github.com/valyala/fasthttp"
+	"time"
+)
+
+var (
+	// ErrClientDisconnected
+	// is the cause of a canceled request context whose client has gone, it wraps context.Canceled.
+	ErrClientDisconnected = fmt.Errorf("fns: client disconnected, %w", context.Canceled)
 )
 
 type Context struct {
 	*fasthttp.RequestCtx
 	locals context.Entries
+	done   chan struct{}
+	cause  error
 }
 
 func (ctx *Context) UserValue(key []byte) any {
@@ -62,3 +72,60 @@ func (ctx *Context) RemoveLocalValue(key []byte) {
 func (ctx *Context) LocalValues(fn func(key []byte, val any)) {
 	ctx.locals.Foreach(fn)
 }
+
+func (ctx *Context) Done() <-chan struct{} {
+	if ctx.done == nil {
+		return ctx.RequestCtx.Done()
+	}
+	return ctx.done
+}
+
+func (ctx *Context) Err() error {
+	if ctx.done == nil {
+		return ctx.RequestCtx.Err()
+	}
+	select {
+	case <-ctx.done:
+		return ctx.cause
+	default:
+		return nil
+	}
+}
+
+// watch
+// closes Done when client disconnects or server shuts down, it is checked every interval.
+// The returned stop must be called before ctx is reused.
+func (ctx *Context) watch(interval time.Duration) (stop func()) {
+	ctx.done = make(chan struct{})
+	ctx.cause = nil
+	stopCh := make(chan struct{})
+	exited := make(chan struct{})
+	go func(ctx *Context, done chan struct{}, stopCh chan struct{}, exited chan struct{}) {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ctx.RequestCtx.Done():
+				ctx.cause = ctx.RequestCtx.Err()
+				close(done)
+				return
+			case <-ticker.C:
+				if connClosed(ctx.RequestCtx.Conn()) {
+					ctx.cause = ErrClientDisconnected
+					close(done)
+					return
+				}
+			}
+		}
+	}(ctx, ctx.done, stopCh, exited)
+	stop = func() {
+		close(stopCh)
+		<-exited
+		ctx.done = nil
+		ctx.cause = nil
+	}
+	return
+}
@@ -0,0 +1,80 @@
+//go:build unix
+
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fast
+
+import (
+	"errors"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/transports"
+	"github.com/valyala/fasthttp"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestClientDisconnect(t *testing.T) {
+	ln, lnErr := net.Listen("tcp", "127.0.0.1:0")
+	if lnErr != nil {
+		t.Fatal(lnErr)
+	}
+	started := make(chan struct{})
+	canceled := make(chan error, 1)
+	handler := transports.HandlerFunc(func(w transports.ResponseWriter, r transports.Request) {
+		close(started)
+		select {
+		case <-r.Done():
+			canceled <- r.Err()
+		case <-time.After(3 * time.Second):
+			canceled <- nil
+		}
+		w.Succeed("ok")
+	})
+	srv := &fasthttp.Server{
+		Handler: handlerAdaptor(handler, time.Second, 10*time.Millisecond),
+	}
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	defer func() {
+		_ = srv.Shutdown()
+	}()
+
+	conn, dialErr := net.Dial("tcp", ln.Addr().String())
+	if dialErr != nil {
+		t.Fatal(dialErr)
+	}
+	if _, err := conn.Write([]byte("GET /slow HTTP/1.1\r\nHost: localhost\r\n\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	// client goes away mid request
+	_ = conn.Close()
+	select {
+	case err := <-canceled:
+		if err == nil {
+			t.Fatal("request context was not canceled")
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Fatal("unexpected cause", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("request context was not canceled in time")
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fast
+
+import (
+	"bufio"
+	"github.com/aacfactory/fns/transports"
+	"github.com/valyala/fasthttp"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeadRequest(t *testing.T) {
+	ln, lnErr := net.Listen("tcp", "127.0.0.1:0")
+	if lnErr != nil {
+		t.Fatal(lnErr)
+	}
+	handler := transports.HandlerFunc(func(w transports.ResponseWriter, r transports.Request) {
+		w.Header().Set(transports.ETagHeaderName, []byte("etag"))
+		w.Succeed("ok")
+	})
+	srv := &fasthttp.Server{
+		Handler: handlerAdaptor(handler, time.Second, 0),
+	}
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	defer func() {
+		_ = srv.Shutdown()
+	}()
+
+	do := func(method string) (resp *http.Response, body []byte) {
+		conn, dialErr := net.Dial("tcp", ln.Addr().String())
+		if dialErr != nil {
+			t.Fatal(dialErr)
+		}
+		defer conn.Close()
+		req, _ := http.NewRequest(method, "http://"+ln.Addr().String()+"/ep/fn", nil)
+		if err := req.Write(conn); err != nil {
+			t.Fatal(err)
+		}
+		var readErr error
+		resp, readErr = http.ReadResponse(bufio.NewReader(conn), req)
+		if readErr != nil {
+			t.Fatal(readErr)
+		}
+		body = make([]byte, 0, 64)
+		buf := make([]byte, 64)
+		for {
+			n, err := resp.Body.Read(buf)
+			body = append(body, buf[:n]...)
+			if err != nil {
+				break
+			}
+		}
+		return
+	}
+	get, getBody := do(http.MethodGet)
+	head, headBody := do(http.MethodHead)
+	if len(getBody) == 0 {
+		t.Fatal("body of get is empty")
+	}
+	if len(headBody) != 0 {
+		t.Fatal("body of head is not empty", string(headBody))
+	}
+	if get.StatusCode != head.StatusCode {
+		t.Fatal("status is not matched", get.StatusCode, head.StatusCode)
+	}
+	for _, name := range []string{"Content-Length", "Content-Type", "Etag"} {
+		if get.Header.Get(name) != head.Header.Get(name) {
+			t.Fatal(name, "is not matched", get.Header.Get(name), head.Header.Get(name))
+		}
+	}
+}
+
+func TestBodyStream(t *testing.T) {
+	ln, lnErr := net.Listen("tcp", "127.0.0.1:0")
+	if lnErr != nil {
+		t.Fatal(lnErr)
+	}
+	handler := transports.HandlerFunc(func(w transports.ResponseWriter, r transports.Request) {
+		w.SetStatus(http.StatusPartialContent)
+		w.(transports.BodyStreamResponseWriter).SetBodyStream(strings.NewReader("hello"), 5)
+	})
+	srv := &fasthttp.Server{
+		Handler: handlerAdaptor(handler, time.Second, 0),
+	}
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	defer func() {
+		_ = srv.Shutdown()
+	}()
+	resp, err := http.Get("http://" + ln.Addr().String() + "/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusPartialContent || resp.Header.Get("Content-Length") != "5" || string(body) != "hello" {
+		t.Fatal("unexpected response", resp.StatusCode, resp.Header.Get("Content-Length"), string(body))
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fast
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/aacfactory/fns/transports"
+	"github.com/valyala/fasthttp"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeaderGuard(t *testing.T) {
+	ln, lnErr := net.Listen("tcp", "127.0.0.1:0")
+	if lnErr != nil {
+		t.Fatal(lnErr)
+	}
+	handler := transports.HandlerFunc(func(w transports.ResponseWriter, r transports.Request) {
+		w.Succeed("ok")
+	})
+	srv := &fasthttp.Server{
+		Handler:      headerGuard(handlerAdaptor(handler, time.Second, 0), 1024, 5),
+		ErrorHandler: errorHandler,
+	}
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	defer func() {
+		_ = srv.Shutdown()
+	}()
+	status := func(headers string) int {
+		conn, dialErr := net.Dial("tcp", ln.Addr().String())
+		if dialErr != nil {
+			t.Fatal(dialErr)
+		}
+		defer conn.Close()
+		if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n" + headers + "\r\n")); err != nil {
+			t.Fatal(err)
+		}
+		resp, respErr := http.ReadResponse(bufio.NewReader(conn), nil)
+		if respErr != nil {
+			t.Fatal(respErr)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := status("X-A: 1\r\n"); code != http.StatusOK {
+		t.Fatal("want 200, got", code)
+	}
+	many := ""
+	for i := 0; i < 6; i++ {
+		many += fmt.Sprintf("X-%d: %d\r\n", i, i)
+	}
+	if code := status(many); code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Fatal("too many headers, want 431, got", code)
+	}
+	if code := status("X-Large: " + strings.Repeat("a", 2048) + "\r\n"); code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Fatal("too large header, want 431, got", code)
+	}
+}
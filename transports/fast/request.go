@@ -21,6 +21,7 @@ import (
 	"crypto/tls"
 	"github.com/aacfactory/fns/commons/bytex"
 	"github.com/aacfactory/fns/transports"
+	"io"
 )
 
 type Request struct {
@@ -78,6 +79,10 @@ func (r *Request) Path() []byte {
 	return r.Context.URI().Path()
 }
 
+func (r *Request) EscapedPath() []byte {
+	return r.Context.URI().PathOriginal()
+}
+
 func (r *Request) Params() transports.Params {
 	return &Params{args: r.Context.QueryArgs()}
 }
@@ -87,7 +92,20 @@ func (r *Request) FormValue(name []byte) (value []byte) {
 }
 
 func (r *Request) Body() ([]byte, error) {
-	return r.Context.PostBody(), nil
+	body := r.Context.PostBody()
+	if err := transports.CheckContentLength(int64(r.Context.Request.Header.ContentLength()), len(body)); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (r *Request) BodyStream() (reader io.Reader, ok bool) {
+	if !r.Context.Request.IsBodyStream() {
+		return
+	}
+	reader = transports.NewContentLengthReader(r.Context.RequestBodyStream(), int64(r.Context.Request.Header.ContentLength()))
+	ok = true
+	return
 }
 
 func (r *Request) SetBody(body []byte) {
@@ -23,6 +23,7 @@ import (
 	"github.com/aacfactory/fns/context"
 	"github.com/aacfactory/fns/transports"
 	"github.com/valyala/fasthttp"
+	"io"
 	"net"
 	"time"
 )
@@ -75,6 +76,11 @@ func (w *ResponseWriter) Write(body []byte) (int, error) {
 	return w.result.Write(body)
 }
 
+func (w *ResponseWriter) SetBodyStream(reader io.Reader, size int64) {
+	w.result.ResetBody()
+	w.Context.Response.SetBodyStream(reader, int(size))
+}
+
 func (w *ResponseWriter) Body() []byte {
 	return w.result.Body()
 }
@@ -19,7 +19,6 @@ package fast
 
 import (
 	"crypto/tls"
-	"fmt"
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/commons/bytex"
 	"github.com/aacfactory/fns/context"
@@ -34,7 +33,7 @@ import (
 	"time"
 )
 
-func newServer(log logs.Logger, port int, tlsConfig ssl.Config, config *Config, handler transports.Handler) (srv *Server, err error) {
+func newServer(log logs.Logger, network string, address string, port int, tlsConfig ssl.Config, config *Config, handler transports.Handler) (srv *Server, err error) {
 	var srvTLS *tls.Config
 	var lnf ssl.ListenerFunc
 	if tlsConfig != nil {
@@ -99,10 +98,37 @@ func newServer(log logs.Logger, port int, tlsConfig ssl.Config, config *Config,
 		}
 	}
 
+	maxRequestHeaderSize := uint64(transports.DefaultMaxRequestHeaderSize)
+	if config.MaxRequestHeaderSize != "" {
+		maxRequestHeaderSize, err = bytex.ParseBytes(strings.TrimSpace(config.MaxRequestHeaderSize))
+		if err != nil {
+			err = errors.Warning("fns: build server failed").WithCause(errors.Warning("maxRequestHeaderSize must be bytes format")).WithCause(err).WithMeta("transport", transportName)
+			return
+		}
+	}
+	if readBufferSize < maxRequestHeaderSize {
+		readBufferSize = maxRequestHeaderSize
+	}
+	maxRequestHeaderCount := config.MaxRequestHeaderCount
+	if maxRequestHeaderCount < 1 {
+		maxRequestHeaderCount = transports.DefaultMaxRequestHeaderCount
+	}
+
+	disconnectCheckInterval := defaultDisconnectCheckInterval
+	if config.DisconnectCheckInterval != "" {
+		disconnectCheckInterval, err = time.ParseDuration(strings.TrimSpace(config.DisconnectCheckInterval))
+		if err != nil {
+			err = errors.Warning("fns: build server failed").WithCause(errors.Warning("disconnectCheckInterval must be time.Duration format")).WithCause(err).WithMeta("transport", transportName)
+			return
+		}
+	}
+
 	reduceMemoryUsage := config.ReduceMemoryUsage
 
+	connMetrics := transports.ConnMetricsOf(address)
+
 	server := &fasthttp.Server{
-		Handler:                            handlerAdaptor(handler, writeTimeout),
+		Handler:                            headerGuard(handlerAdaptor(handler, writeTimeout, disconnectCheckInterval), int(maxRequestHeaderSize), maxRequestHeaderCount),
 		ErrorHandler:                       errorHandler,
 		Name:                               "",
 		Concurrency:                        0,
@@ -129,7 +155,7 @@ func newServer(log logs.Logger, port int, tlsConfig ssl.Config, config *Config,
 		KeepHijackedConns:                  config.KeepHijackedConns,
 		CloseOnShutdown:                    true,
 		StreamRequestBody:                  config.StreamRequestBody,
-		ConnState:                          nil,
+		ConnState:                          connState(connMetrics),
 		Logger:                             logs.ConvertToStandardLogger(log, logs.DebugLevel, false),
 		TLSConfig:                          srvTLS,
 	}
@@ -144,18 +170,46 @@ func newServer(log logs.Logger, port int, tlsConfig ssl.Config, config *Config,
 
 	srv = &Server{
 		port:    port,
+		network: network,
+		address: address,
 		preFork: config.Prefork,
 		lnf:     lnf,
 		srv:     server,
+		conns:   connMetrics,
 	}
 	return
 }
 
+func connState(metrics *transports.ConnMetrics) func(conn net.Conn, state fasthttp.ConnState) {
+	return func(conn net.Conn, state fasthttp.ConnState) {
+		switch state {
+		case fasthttp.StateNew:
+			metrics.SetState(conn, transports.ConnNew)
+			break
+		case fasthttp.StateActive:
+			metrics.SetState(conn, transports.ConnActive)
+			break
+		case fasthttp.StateIdle:
+			metrics.SetState(conn, transports.ConnIdle)
+			break
+		case fasthttp.StateHijacked:
+			metrics.SetState(conn, transports.ConnHijacked)
+			break
+		case fasthttp.StateClosed:
+			metrics.SetState(conn, transports.ConnClosed)
+			break
+		}
+	}
+}
+
 type Server struct {
 	port    int
+	network string
+	address string
 	preFork bool
 	lnf     ssl.ListenerFunc
 	srv     *fasthttp.Server
+	conns   *transports.ConnMetrics
 }
 
 func (srv *Server) preforkServe(ln net.Listener) (err error) {
@@ -170,16 +224,19 @@ func (srv *Server) ListenAndServe() (err error) {
 	if srv.preFork {
 		pf := prefork.New(srv.srv)
 		pf.ServeFunc = srv.preforkServe
-		err = pf.ListenAndServe(fmt.Sprintf(":%d", srv.port))
+		if srv.network != "tcp" {
+			pf.Network = srv.network
+		}
+		err = pf.ListenAndServe(srv.address)
 		if err != nil {
-			err = errors.Warning("fns: transport perfork listen and serve failed").WithCause(err)
+			err = errors.Warning("fns: transport perfork listen and serve failed").WithCause(err).WithMeta("address", srv.address)
 			return
 		}
 		return
 	}
-	ln, lnErr := net.Listen("tcp", fmt.Sprintf(":%d", srv.port))
+	ln, lnErr := net.Listen(srv.network, srv.address)
 	if lnErr != nil {
-		err = errors.Warning("fns: transport listen and serve failed").WithCause(lnErr)
+		err = errors.Warning("fns: transport listen and serve failed").WithCause(lnErr).WithMeta("network", srv.network).WithMeta("address", srv.address)
 		return
 	}
 	if srv.lnf != nil {
@@ -194,6 +251,7 @@ func (srv *Server) ListenAndServe() (err error) {
 }
 
 func (srv *Server) Shutdown(ctx context.Context) (err error) {
+	srv.conns.ShuttingDown()
 	err = srv.srv.ShutdownWithContext(ctx)
 	if err != nil {
 		err = errors.Warning("fns: transport shutdown failed").WithCause(err).WithMeta("transport", transportName)
@@ -24,10 +24,12 @@ import (
 	"github.com/aacfactory/fns/transports"
 	"github.com/aacfactory/json"
 	"github.com/valyala/fasthttp"
+	"time"
 )
 
 const (
-	transportName = "fasthttp"
+	transportName                  = "fasthttp"
+	defaultDisconnectCheckInterval = 200 * time.Millisecond
 )
 
 type Http2Config struct {
@@ -38,21 +40,36 @@ type Http2Config struct {
 }
 
 type Config struct {
-	ReadBufferSize        string       `json:"readBufferSize"`
-	ReadTimeout           string       `json:"readTimeout"`
-	WriteBufferSize       string       `json:"writeBufferSize"`
-	WriteTimeout          string       `json:"writeTimeout"`
-	MaxIdleWorkerDuration string       `json:"maxIdleWorkerDuration"`
-	TCPKeepalive          bool         `json:"tcpKeepalive"`
-	TCPKeepalivePeriod    string       `json:"tcpKeepalivePeriod"`
-	MaxRequestBodySize    string       `json:"maxRequestBodySize"`
-	ReduceMemoryUsage     bool         `json:"reduceMemoryUsage"`
-	MaxRequestsPerConn    int          `json:"maxRequestsPerConn"`
-	KeepHijackedConns     bool         `json:"keepHijackedConns"`
-	StreamRequestBody     bool         `json:"streamRequestBody"`
-	Prefork               bool         `json:"prefork"`
-	Http2                 Http2Config  `json:"http2"`
-	Client                ClientConfig `json:"client"`
+	ReadBufferSize        string `json:"readBufferSize"`
+	ReadTimeout           string `json:"readTimeout"`
+	WriteBufferSize       string `json:"writeBufferSize"`
+	WriteTimeout          string `json:"writeTimeout"`
+	MaxIdleWorkerDuration string `json:"maxIdleWorkerDuration"`
+	TCPKeepalive          bool   `json:"tcpKeepalive"`
+	TCPKeepalivePeriod    string `json:"tcpKeepalivePeriod"`
+	MaxRequestBodySize    string `json:"maxRequestBodySize"`
+	// MaxRequestHeaderSize
+	// bytes format, default is 32KB, requests with larger header are answered with 431.
+	// readBufferSize is enlarged to it when it is smaller, because fasthttp reads the whole header into read buffer.
+	MaxRequestHeaderSize string `json:"maxRequestHeaderSize"`
+	// MaxRequestHeaderCount
+	// default is 100, requests with more header fields are answered with 431.
+	MaxRequestHeaderCount int  `json:"maxRequestHeaderCount"`
+	ReduceMemoryUsage     bool `json:"reduceMemoryUsage"`
+	MaxRequestsPerConn    int  `json:"maxRequestsPerConn"`
+	KeepHijackedConns     bool `json:"keepHijackedConns"`
+	StreamRequestBody     bool `json:"streamRequestBody"`
+	Prefork               bool `json:"prefork"`
+	// DisconnectCheckInterval
+	// interval of checking whether client has disconnected while request is handling,
+	// context of request is canceled once it has, default is 200ms and 0s disables it.
+	DisconnectCheckInterval string       `json:"disconnectCheckInterval"`
+	Http2                   Http2Config  `json:"http2"`
+	Client                  ClientConfig `json:"client"`
+}
+
+func init() {
+	transports.Register(transportName, New)
 }
 
 func New() transports.Transport {
@@ -91,6 +108,11 @@ func (tr *Transport) Construct(options transports.Options) (err error) {
 		err = errors.Warning("fns: fast transport construct failed").WithCause(portErr).WithMeta("transport", transportName)
 		return
 	}
+	network, address, addressErr := options.Config.GetListenAddress()
+	if addressErr != nil {
+		err = errors.Warning("fns: fast transport construct failed").WithCause(addressErr).WithMeta("transport", transportName)
+		return
+	}
 	// config
 	optConfig, optConfigErr := options.Config.OptionsConfig()
 	if optConfigErr != nil {
@@ -104,7 +126,7 @@ func (tr *Transport) Construct(options transports.Options) (err error) {
 		return
 	}
 	// server
-	srv, srvErr := newServer(log, port, tlsConfig, config, options.Handler)
+	srv, srvErr := newServer(log, network, address, port, tlsConfig, config, options.Handler)
 	if srvErr != nil {
 		err = errors.Warning("fns: fast transport construct failed").WithCause(srvErr).WithMeta("transport", transportName)
 		return
@@ -158,8 +180,19 @@ func (tr *Transport) Shutdown(ctx context.Context) {
 // +-------------------------------------------------------------------------------------------------------------------+
 
 func errorHandler(ctx *fasthttp.RequestCtx, err error) {
+	if _, isSmallBuffer := err.(*fasthttp.ErrSmallBuffer); isSmallBuffer {
+		failed(ctx, transports.ErrTooLargeRequestHeader.WithMeta("transport", transportName))
+		return
+	}
 	ctx.SetStatusCode(555)
 	ctx.SetContentTypeBytes(transports.ContentTypeJsonHeaderValue)
 	p, _ := json.Marshal(errors.Warning("fns: transport receiving or parsing the request failed").WithCause(err).WithMeta("transport", transportName))
 	ctx.SetBody(p)
 }
+
+func failed(ctx *fasthttp.RequestCtx, err errors.CodeError) {
+	ctx.SetStatusCode(err.Code())
+	ctx.SetContentTypeBytes(transports.ContentTypeJsonHeaderValue)
+	p, _ := json.Marshal(err)
+	ctx.SetBody(p)
+}
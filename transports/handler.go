@@ -22,6 +22,7 @@ import (
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/context"
 	"github.com/aacfactory/logs"
+	"io"
 )
 
 type Handler interface {
@@ -53,16 +54,53 @@ func NewMux() *Mux {
 }
 
 type Mux struct {
+	basePath []byte
 	handlers []MuxHandler
 }
 
+// SetBasePath
+// base path is stripped before matching, request which is not under base path is not found.
+func (mux *Mux) SetBasePath(basePath string) {
+	if basePath = NormalizeBasePath(basePath); basePath != "" {
+		mux.basePath = []byte(basePath)
+	}
+}
+
 func (mux *Mux) Add(handler MuxHandler) {
 	mux.handlers = append(mux.handlers, handler)
 }
 
+// Close
+// closes handlers which are io.Closer.
+func (mux *Mux) Close() (err error) {
+	for _, handler := range mux.handlers {
+		if closer, ok := handler.(io.Closer); ok {
+			if closeErr := closer.Close(); closeErr != nil {
+				err = errors.Warning("fns: close mux failed").WithMeta("handler", handler.Name()).WithCause(closeErr)
+			}
+		}
+	}
+	return
+}
+
 func (mux *Mux) Handle(w ResponseWriter, r Request) {
+	path := r.Path()
+	if len(mux.basePath) > 0 {
+		stripped, ok := StripBasePath(mux.basePath, path)
+		if !ok {
+			w.Failed(errors.NotFound("fns: not found").WithMeta("handler", "mux"))
+			return
+		}
+		path = stripped
+		r.SetLocalValue(basePathContextKey, mux.basePath)
+		r = &basePathRequest{
+			Request:  r,
+			basePath: mux.basePath,
+			path:     stripped,
+		}
+	}
 	for _, handler := range mux.handlers {
-		matched := handler.Match(r, r.Method(), r.Path(), r.Header())
+		matched := handler.Match(r, r.Method(), path, r.Header())
 		if matched {
 			handler.Handle(w, r)
 			return
@@ -31,6 +31,7 @@ var (
 	ContentTypeJsonHeaderValue                   = []byte("application/json")
 	ContentTypeTextHeaderValue                   = []byte("text/plain")
 	ContentTypeAvroHeaderValue                   = []byte("application/avro")
+	ContentTypeProblemJsonHeaderValue            = []byte("application/problem+json")
 	ContentLengthHeaderName                      = []byte("Content-Length")
 	AuthorizationHeaderName                      = []byte("Authorization")
 	CookieHeaderName                             = []byte("Cookie")
@@ -47,8 +48,14 @@ var (
 	ETagHeaderName                               = []byte("ETag")
 	CacheControlHeaderIfNonMatch                 = []byte("If-None-Match")
 	VaryHeaderName                               = []byte("Vary")
+	LocationHeaderName                           = []byte("Location")
+	AllowHeaderName                              = []byte("Allow")
+	RangeHeaderName                              = []byte("Range")
+	AcceptRangesHeaderName                       = []byte("Accept-Ranges")
+	ContentRangeHeaderName                       = []byte("Content-Range")
 	OriginHeaderName                             = []byte("Origin")
 	AcceptHeaderName                             = []byte("Accept")
+	AcceptLanguageHeaderName                     = []byte("Accept-Language")
 	AccessControlRequestMethodHeaderName         = []byte("Access-Control-Request-Method")
 	AccessControlRequestHeadersHeaderName        = []byte("Access-Control-Request-Headers")
 	AccessControlRequestPrivateNetworkHeaderName = []byte("Access-Control-Request-Private-Network")
@@ -63,15 +70,20 @@ var (
 	TrueClientIpHeaderName                       = []byte("True-Client-Ip")
 	XRealIpHeaderName                            = []byte("X-Real-IP")
 	XForwardedForHeaderName                      = []byte("X-Forwarded-For")
+	XForwardedProtoHeaderName                    = []byte("X-Forwarded-Proto")
+	XForwardedHostHeaderName                     = []byte("X-Forwarded-Host")
 	RequestIdHeaderName                          = []byte("X-Fns-Request-Id")
 	SignatureHeaderName                          = []byte("X-Fns-Signature")
 	EndpointIdHeaderName                         = []byte("X-Fns-Endpoint-Id")
 	EndpointVersionHeaderName                    = []byte("X-Fns-Endpoint-Version")
 	RequestTimeoutHeaderName                     = []byte("X-Fns-Request-Timeout")
 	RequestVersionsHeaderName                    = []byte("X-Fns-Request-Version")
+	RequestDepthHeaderName                       = []byte("X-Fns-Request-Depth")
 	HandleLatencyHeaderName                      = []byte("X-Fns-Handle-Latency")
 	DeviceIdHeaderName                           = []byte("X-Fns-Device-Id")
 	DeviceIpHeaderName                           = []byte("X-Fns-Device-Ip")
+	CompressLevelHeaderName                      = []byte("X-Fns-Compress-Level")
+	TenantHeaderName                             = []byte("X-Fns-Tenant")
 	DeprecatedHeaderName                         = []byte("X-Fns-Deprecated")
 	ResponseRetryAfterHeaderName                 = []byte("Retry-After")
 	UserHeaderNamePrefix                         = []byte("XU-")
@@ -136,9 +148,9 @@ func (h *defaultHeader) Add(key []byte, value []byte) {
 func (h *defaultHeader) Set(key []byte, value []byte) {
 	hh := *h
 	key = bytex.FromString(textproto.CanonicalMIMEHeaderKey(bytex.ToString(key)))
-	for _, entry := range hh {
+	for i, entry := range hh {
 		if bytes.Equal(entry.name, key) {
-			entry.value = [][]byte{value}
+			hh[i].value = [][]byte{value}
 			return
 		}
 	}
@@ -278,7 +290,7 @@ func GetAcceptEncodings(header Header) (encodings AcceptEncodings) {
 			}
 			encodings = append(encodings, AcceptEncoding{
 				Name:    item,
-				Quality: 0,
+				Quality: 1,
 			})
 			continue
 		}
@@ -289,7 +301,7 @@ func GetAcceptEncodings(header Header) (encodings AcceptEncodings) {
 			}
 			encodings = append(encodings, AcceptEncoding{
 				Name:    item,
-				Quality: 0,
+				Quality: 1,
 			})
 			continue
 		}
@@ -297,7 +309,10 @@ func GetAcceptEncodings(header Header) (encodings AcceptEncodings) {
 		if len(name) == 0 {
 			continue
 		}
-		qp := bytes.TrimSpace(bytes.TrimSpace(item[idx+1:]))
+		qp := bytes.TrimSpace(item[idx+1:])
+		if len(qp) > 1 && (qp[0] == 'q' || qp[0] == 'Q') && qp[1] == '=' {
+			qp = qp[2:]
+		}
 		quality, qualityErr := strconv.ParseFloat(bytex.ToString(qp), 64)
 		if qualityErr != nil {
 			continue
@@ -310,7 +325,7 @@ func GetAcceptEncodings(header Header) (encodings AcceptEncodings) {
 	if len(encodings) == 0 {
 		return
 	}
-	sort.Sort(encodings)
+	sort.Stable(encodings)
 	return
 }
 
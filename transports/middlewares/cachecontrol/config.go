@@ -18,6 +18,15 @@
 package cachecontrol
 
 type Config struct {
-	Enable bool `json:"enable"`
-	MaxAge int  `json:"maxAge"`
+	Enable bool       `json:"enable"`
+	MaxAge int        `json:"maxAge"`
+	Edge   EdgeConfig `json:"edge"`
+}
+
+// EdgeConfig
+// shared response cache of public fns, see Edge.
+type EdgeConfig struct {
+	Enable      bool     `json:"enable"`
+	Vary        []string `json:"vary"`
+	MaxBodySize int      `json:"maxBodySize"`
 }
@@ -0,0 +1,246 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cachecontrol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/transports"
+	"github.com/cespare/xxhash/v2"
+	"github.com/valyala/bytebufferpool"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultEdgeMaxBodySize = 1024 * 1024
+)
+
+var (
+	edgeKeyPrefix        = []byte("edge:")
+	edgeGenerationPrefix = []byte("edge:generation:")
+	edgeHeaderName       = []byte("X-Fns-Edge-Cache")
+	edgeHit              = []byte("hit")
+	edgeMiss             = []byte("miss")
+	edgeStatsPath        = []byte("/application/edge-cache")
+	edgeHits             = new(atomic.Int64)
+	edgeMisses           = new(atomic.Int64)
+	edgeStores           = new(atomic.Int64)
+)
+
+type edgeEntry struct {
+	ContentType  []byte `json:"contentType"`
+	CacheControl []byte `json:"cacheControl"`
+	ETag         []byte `json:"etag"`
+	Body         []byte `json:"body"`
+}
+
+// Edge
+// caches responses of fns with @cache-control public in the cache of middleware, which is the shared store by default,
+// so repeated GET requests are responded before they are dispatched to fns.
+// key is made of path, query, authorization, tenant and headers of EdgeConfig.Vary, and generation of path, see InvalidateEdge.
+// response is cached only when status is 200, cache control is public with max-age, and body is not larger than MaxBodySize,
+// it expires at max-age, and request with no-cache or no-store cache control skips it.
+type Edge struct {
+	cache       Cache
+	vary        [][]byte
+	maxBodySize int
+}
+
+func newEdge(cache Cache, config EdgeConfig) *Edge {
+	vary := make([][]byte, 0, len(config.Vary))
+	for _, name := range config.Vary {
+		if name == "" {
+			continue
+		}
+		vary = append(vary, []byte(name))
+	}
+	maxBodySize := config.MaxBodySize
+	if maxBodySize < 1 {
+		maxBodySize = defaultEdgeMaxBodySize
+	}
+	return &Edge{
+		cache:       cache,
+		vary:        vary,
+		maxBodySize: maxBodySize,
+	}
+}
+
+func (edge *Edge) key(ctx context.Context, r transports.Request) (key []byte, err error) {
+	generation, _, getErr := edge.cache.Get(ctx, edgeGenerationKey(r.Path()))
+	if getErr != nil {
+		err = getErr
+		return
+	}
+	// parts are length prefixed, so that different parts can not be concatenated into the same key
+	b := bytebufferpool.Get()
+	writeEdgeKeyPart(b, generation)
+	writeEdgeKeyPart(b, r.Header().Get(transports.TenantHeaderName))
+	writeEdgeKeyPart(b, r.Path())
+	writeEdgeKeyPart(b, r.Params().Encode())
+	writeEdgeKeyPart(b, r.Header().Get(transports.AuthorizationHeaderName))
+	for _, name := range edge.vary {
+		writeEdgeKeyPart(b, name)
+		writeEdgeKeyPart(b, r.Header().Get(name))
+	}
+	key = append(append(make([]byte, 0, 24), edgeKeyPrefix...), strconv.FormatUint(xxhash.Sum64(b.Bytes()), 16)...)
+	bytebufferpool.Put(b)
+	return
+}
+
+func writeEdgeKeyPart(b *bytebufferpool.ByteBuffer, p []byte) {
+	b.B = binary.AppendUvarint(b.B, uint64(len(p)))
+	_, _ = b.Write(p)
+}
+
+func edgeGenerationKey(path []byte) []byte {
+	return append(append(make([]byte, 0, len(edgeGenerationPrefix)+len(path)), edgeGenerationPrefix...), path...)
+}
+
+// load
+// writes cached response when hit, 304 is written when If-None-Match matches etag of cached response.
+func (edge *Edge) load(w transports.ResponseWriter, r transports.Request, key []byte) (hit bool, err error) {
+	p, has, getErr := edge.cache.Get(r, key)
+	if getErr != nil {
+		err = getErr
+		return
+	}
+	if !has {
+		edgeMisses.Add(1)
+		return
+	}
+	entry := edgeEntry{}
+	if decodeErr := jsons.Unmarshal(p, &entry); decodeErr != nil {
+		err = decodeErr
+		edgeMisses.Add(1)
+		return
+	}
+	hit = true
+	edgeHits.Add(1)
+	w.Header().Set(edgeHeaderName, edgeHit)
+	w.Header().Set(transports.CacheControlHeaderName, entry.CacheControl)
+	w.Header().Set(transports.ETagHeaderName, entry.ETag)
+	if inm := r.Header().Get(transports.CacheControlHeaderIfNonMatch); len(inm) > 0 && bytes.Equal(inm, entry.ETag) {
+		w.SetStatus(http.StatusNotModified)
+		return
+	}
+	if len(entry.ContentType) > 0 {
+		w.Header().Set(transports.ContentTypeHeaderName, entry.ContentType)
+	}
+	w.SetStatus(http.StatusOK)
+	_, _ = w.Write(entry.Body)
+	return
+}
+
+// store
+// is called after etag and max-age of response are made.
+func (edge *Edge) store(w transports.ResponseWriter, r transports.Request, key []byte, maxAgeValue int) (err error) {
+	if maxAgeValue < 1 || w.Status() != http.StatusOK {
+		return
+	}
+	cch := w.Header().Get(transports.CacheControlHeaderName)
+	if !bytes.Contains(cch, public) || bytes.Contains(cch, noStore) || bytes.Contains(cch, noCache) {
+		return
+	}
+	if w.BodyLen() > edge.maxBodySize {
+		return
+	}
+	entry := edgeEntry{
+		ContentType:  w.Header().Get(transports.ContentTypeHeaderName),
+		CacheControl: cch,
+		ETag:         w.Header().Get(transports.ETagHeaderName),
+		Body:         w.Body(),
+	}
+	p, encodeErr := jsons.Marshal(entry)
+	if encodeErr != nil {
+		err = encodeErr
+		return
+	}
+	if err = edge.cache.Set(r, key, p, time.Duration(maxAgeValue)*time.Second); err != nil {
+		return
+	}
+	edgeStores.Add(1)
+	w.Header().Set(edgeHeaderName, edgeMiss)
+	return
+}
+
+// InvalidateEdge
+// drops edge cached responses of path, such as /users/get, by moving generation of path,
+// so it works with the default cache of middleware, and old responses expire by their max-age.
+func InvalidateEdge(ctx context.Context, cache Cache, path []byte) (err error) {
+	if cache == nil {
+		cache = new(DefaultCache)
+	}
+	generation := bytex.FromString(strconv.FormatInt(time.Now().UnixNano(), 16))
+	if err = cache.Set(ctx, edgeGenerationKey(path), generation, 0); err != nil {
+		err = errors.Warning("fns: invalidate edge cache failed").WithCause(err).WithMeta("path", string(path))
+		return
+	}
+	return
+}
+
+type EdgeStat struct {
+	Hits   int64   `json:"hits"`
+	Misses int64   `json:"misses"`
+	Stores int64   `json:"stores"`
+	Ratio  float64 `json:"ratio"`
+}
+
+// EdgeStats
+// returns hit and miss counts of edge cache since application started, ratio is hits / (hits + misses).
+func EdgeStats() (stat EdgeStat) {
+	stat = EdgeStat{
+		Hits:   edgeHits.Load(),
+		Misses: edgeMisses.Load(),
+		Stores: edgeStores.Load(),
+	}
+	if total := stat.Hits + stat.Misses; total > 0 {
+		stat.Ratio = float64(stat.Hits) / float64(total)
+	}
+	return
+}
+
+// EdgeStatsHandler
+// serves EdgeStats at /application/edge-cache.
+func EdgeStatsHandler() transports.MuxHandler {
+	return &edgeStatsHandler{}
+}
+
+type edgeStatsHandler struct{}
+
+func (handler *edgeStatsHandler) Name() string {
+	return "edge-cache"
+}
+
+func (handler *edgeStatsHandler) Construct(_ transports.MuxHandlerOptions) error {
+	return nil
+}
+
+func (handler *edgeStatsHandler) Match(_ context.Context, method []byte, path []byte, _ transports.Header) bool {
+	return bytes.Equal(method, transports.MethodGet) && bytes.Equal(path, edgeStatsPath)
+}
+
+func (handler *edgeStatsHandler) Handle(w transports.ResponseWriter, _ transports.Request) {
+	w.Succeed(EdgeStats())
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cachecontrol_test
+
+import (
+	"bufio"
+	"github.com/aacfactory/configures"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/fns/transports/fast"
+	"github.com/aacfactory/fns/transports/middlewares/cachecontrol"
+	"github.com/valyala/fasthttp"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryCache struct {
+	mutex  sync.Mutex
+	values map[string][]byte
+}
+
+func (cache *memoryCache) Get(_ context.Context, key []byte) (value []byte, has bool, err error) {
+	cache.mutex.Lock()
+	value, has = cache.values[string(key)]
+	cache.mutex.Unlock()
+	return
+}
+
+func (cache *memoryCache) Set(_ context.Context, key []byte, value []byte, _ time.Duration) (err error) {
+	cache.mutex.Lock()
+	cache.values[string(key)] = append([]byte{}, value...)
+	cache.mutex.Unlock()
+	return
+}
+
+func (cache *memoryCache) Close() {}
+
+type resultWriter struct {
+	context.Context
+	*transports.ResultResponseWriter
+}
+
+func (w *resultWriter) SetCookie(_ *transports.Cookie) {}
+
+func (w *resultWriter) Hijack(_ func(ctx context.Context, conn net.Conn, rw *bufio.ReadWriter) (err error)) (async bool, err error) {
+	return
+}
+
+func (w *resultWriter) Hijacked() bool {
+	return false
+}
+
+func TestEdge(t *testing.T) {
+	log, logErr := logs.New(logs.Config{}, nil)
+	if logErr != nil {
+		t.Fatal(logErr)
+	}
+	config, configErr := configures.NewJsonConfig([]byte(`{"enable":true,"edge":{"enable":true}}`))
+	if configErr != nil {
+		t.Fatal(configErr)
+	}
+	cache := &memoryCache{values: make(map[string][]byte)}
+	middleware := cachecontrol.NewWithCache(cache)
+	if err := middleware.Construct(transports.MiddlewareOptions{Log: log, Config: config}); err != nil {
+		t.Fatal(err)
+	}
+	handled := 0
+	handler := middleware.Handler(transports.HandlerFunc(func(w transports.ResponseWriter, r transports.Request) {
+		handled++
+		w.Header().Set(transports.CacheControlHeaderName, []byte("public, max-age=60"))
+		w.Succeed("users")
+	}))
+	do := func(uri string, header map[string]string) (w *resultWriter) {
+		rc := &fasthttp.RequestCtx{}
+		rc.Request.Header.SetMethod(http.MethodGet)
+		rc.Request.SetRequestURI(uri)
+		for name, value := range header {
+			rc.Request.Header.Set(name, value)
+		}
+		r := &fast.Request{Context: &fast.Context{RequestCtx: rc}}
+		w = &resultWriter{
+			Context:              r,
+			ResultResponseWriter: transports.AcquireResultResponseWriter(0, transports.ContentTypeJsonHeaderValue),
+		}
+		handler.Handle(w, r)
+		return
+	}
+	// miss
+	w := do("/users/get?a=1", nil)
+	if handled != 1 || string(w.Header().Get([]byte("X-Fns-Edge-Cache"))) != "miss" {
+		t.Fatal("first request must miss", handled, string(w.Header().Get([]byte("X-Fns-Edge-Cache"))))
+	}
+	body := string(w.Body())
+	etag := string(w.Header().Get(transports.ETagHeaderName))
+	// hit
+	w = do("/users/get?a=1", nil)
+	if handled != 1 || string(w.Header().Get([]byte("X-Fns-Edge-Cache"))) != "hit" || string(w.Body()) != body {
+		t.Fatal("second request must hit", handled, string(w.Body()))
+	}
+	// 304
+	w = do("/users/get?a=1", map[string]string{"If-None-Match": etag})
+	if handled != 1 || w.Status() != http.StatusNotModified || w.BodyLen() != 0 {
+		t.Fatal("matched etag must be 304", handled, w.Status())
+	}
+	// parts of key are not concatenated, query a=1 and authorization a=1 are different
+	w = do("/users/get", map[string]string{"Authorization": "a=1"})
+	if handled != 2 {
+		t.Fatal("different parts must not share key", handled)
+	}
+	// invalidate
+	if err := cachecontrol.InvalidateEdge(context.TODO(), cache, []byte("/users/get")); err != nil {
+		t.Fatal(err)
+	}
+	w = do("/users/get?a=1", nil)
+	if handled != 3 || string(w.Header().Get([]byte("X-Fns-Edge-Cache"))) != "miss" {
+		t.Fatal("invalidated path must miss", handled)
+	}
+	if stat := cachecontrol.EdgeStats(); stat.Hits < 2 || stat.Stores < 3 {
+		t.Fatal("unexpected stats", stat)
+	}
+}
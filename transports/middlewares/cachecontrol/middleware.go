@@ -46,16 +46,25 @@ func New() transports.Middleware {
 }
 
 var (
-	getMethod = []byte("GET")
+	getMethod  = []byte("GET")
+	headMethod = []byte("HEAD")
 )
 
 // Middleware
 // use @cache-control max-age=10 public=true must-revalidate=false proxy-revalidate=false
+// responses of public fns are cached at edge when edge of config is enabled, see Edge.
+//
+//	cachecontrol:
+//	  enable: true
+//	  edge:
+//	    enable: true
+//	    vary: ["Accept-Language"]
 type Middleware struct {
 	log    logs.Logger
 	cache  Cache
 	enable bool
 	maxAge int
+	edge   *Edge
 }
 
 func (middleware *Middleware) Name() string {
@@ -76,6 +85,9 @@ func (middleware *Middleware) Construct(options transports.MiddlewareOptions) (e
 		if middleware.maxAge < 1 {
 			middleware.maxAge = 60
 		}
+		if config.Edge.Enable {
+			middleware.edge = newEdge(middleware.cache, config.Edge)
+		}
 	}
 	return
 }
@@ -83,7 +95,8 @@ func (middleware *Middleware) Construct(options transports.MiddlewareOptions) (e
 func (middleware *Middleware) Handler(next transports.Handler) transports.Handler {
 	if middleware.enable {
 		return transports.HandlerFunc(func(writer transports.ResponseWriter, request transports.Request) {
-			isGet := bytes.Equal(request.Method(), getMethod)
+			// head shares etag of get, cause hashRequest does not take method
+			isGet := bytes.Equal(request.Method(), getMethod) || bytes.Equal(request.Method(), headMethod)
 			if !isGet {
 				next.Handle(writer, request)
 				return
@@ -93,6 +106,28 @@ func (middleware *Middleware) Handler(next transports.Handler) transports.Handle
 				next.Handle(writer, request)
 				return
 			}
+			// edge
+			var edgeKey []byte
+			if middleware.edge != nil {
+				var edgeErr error
+				edgeKey, edgeErr = middleware.edge.key(request, request)
+				if edgeErr == nil {
+					var hit bool
+					hit, edgeErr = middleware.edge.load(writer, request, edgeKey)
+					if hit {
+						return
+					}
+				}
+				if edgeErr != nil {
+					edgeKey = nil
+					if middleware.log.WarnEnabled() {
+						middleware.log.Warn().
+							With("middleware", "cachecontrol").
+							Cause(errors.Warning("fns: load edge cache failed").WithCause(edgeErr)).
+							Message("load edge cache failed")
+					}
+				}
+			}
 			// request key
 			var key []byte
 			// if-no-match
@@ -166,6 +201,16 @@ func (middleware *Middleware) Handler(next transports.Handler) transports.Handle
 			setErr := middleware.cache.Set(request, key, etag, time.Duration(maxAgeValue)*time.Second)
 			if setErr == nil {
 				writer.Header().Set(transports.ETagHeaderName, etag)
+				if len(edgeKey) > 0 {
+					if storeErr := middleware.edge.store(writer, request, edgeKey, maxAgeValue); storeErr != nil {
+						if middleware.log.WarnEnabled() {
+							middleware.log.Warn().
+								With("middleware", "cachecontrol").
+								Cause(errors.Warning("fns: store edge cache failed").WithCause(storeErr)).
+								Message("store edge cache failed")
+						}
+					}
+				}
 			} else {
 				if middleware.log.WarnEnabled() {
 					middleware.log.Warn().
@@ -192,6 +237,8 @@ func hashRequest(r transports.Request) (p []byte) {
 	// device id
 	deviceId := r.Header().Get(transports.DeviceIdHeaderName)
 	_, _ = b.Write(deviceId)
+	// tenant
+	_, _ = b.Write(r.Header().Get(transports.TenantHeaderName))
 	// path
 	_, _ = b.Write(r.Path())
 	// param
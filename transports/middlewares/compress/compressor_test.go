@@ -0,0 +1,90 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package compress
+
+import (
+	"bytes"
+	"github.com/aacfactory/fns/transports"
+	"github.com/valyala/fasthttp"
+	"testing"
+)
+
+func TestGetKind(t *testing.T) {
+	cases := []struct {
+		accept    string
+		preferred Kind
+		expect    Kind
+	}{
+		{accept: "", preferred: Deflate, expect: No},
+		{accept: "gzip, br", preferred: Deflate, expect: Gzip},
+		{accept: "gzip, br", preferred: Brotli, expect: Brotli},
+		{accept: "gzip;q=0.5, br;q=0.8", preferred: Gzip, expect: Brotli},
+		{accept: "br;q=0, gzip;q=0.1", preferred: Brotli, expect: Gzip},
+		{accept: "zstd, deflate;q=0.2", preferred: Gzip, expect: Deflate},
+		{accept: "identity", preferred: Gzip, expect: No},
+	}
+	for _, c := range cases {
+		header := transports.NewHeader()
+		if c.accept != "" {
+			header.Set(transports.AcceptEncodingHeaderName, []byte(c.accept))
+		}
+		if kind := getKind(header, c.preferred); kind != c.expect {
+			t.Error(c.accept, "expect", c.expect.String(), "but", kind.String())
+		}
+	}
+}
+
+func TestGetLevel(t *testing.T) {
+	header := transports.NewHeader()
+	if level := getLevel(header, DefaultLevel); level != DefaultLevel {
+		t.Error("expect default but", level)
+	}
+	header.Set(transports.CompressLevelHeaderName, []byte("best"))
+	if level := getLevel(header, DefaultLevel); level != DefaultLevel {
+		t.Error("best should be capped by default but", level)
+	}
+	header.Set(transports.CompressLevelHeaderName, []byte("none"))
+	if level := getLevel(header, BestLevel); level != NoneLevel {
+		t.Error("expect none but", level)
+	}
+}
+
+// BenchmarkCompressor
+// shows cpu cost of each algorithm at each level for a json body of 64KB.
+func BenchmarkCompressor(b *testing.B) {
+	body := bytes.Repeat([]byte(`{"id":"0123456789","name":"fns","tags":["a","b","c"],"score":3.1415926},`), 1024)
+	compressors := map[string]Compressor{
+		"gzip/speed":    &GzipCompressor{level: fasthttp.CompressBestSpeed},
+		"gzip/default":  &GzipCompressor{level: fasthttp.CompressDefaultCompression},
+		"gzip/best":     &GzipCompressor{level: fasthttp.CompressBestCompression},
+		"deflate/speed": &DeflateCompressor{level: fasthttp.CompressBestSpeed},
+		"deflate/best":  &DeflateCompressor{level: fasthttp.CompressBestCompression},
+		"br/speed":      &BrotliCompressor{level: fasthttp.CompressBrotliBestSpeed},
+		"br/default":    &BrotliCompressor{level: fasthttp.CompressBrotliDefaultCompression},
+		"br/best":       &BrotliCompressor{level: fasthttp.CompressBrotliBestCompression},
+	}
+	for name, c := range compressors {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(body)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = c.Compress(body)
+			}
+		})
+	}
+}
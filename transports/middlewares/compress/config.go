@@ -23,4 +23,18 @@ type Config struct {
 	GzipLevel    int    `json:"gzipLevel"`
 	DeflateLevel int    `json:"deflateLevel"`
 	BrotliLevel  int    `json:"brotliLevel"`
+	// MaxLevel
+	// cap of level which is hinted by client in X-Fns-Compress-Level header, they are none, speed, default (default) and best.
+	// levels of config are used for default, speed and best are the fastest and the smallest of each algorithm.
+	MaxLevel string `json:"maxLevel"`
+	// Request
+	// decompresses gzip or deflate encoded request body, it works when Enable is false.
+	Request RequestConfig `json:"request"`
+}
+
+// RequestConfig
+// MaxSize is the max size of decompressed body in bytes format, default is 4MB.
+type RequestConfig struct {
+	Enable  bool   `json:"enable"`
+	MaxSize string `json:"maxSize"`
 }
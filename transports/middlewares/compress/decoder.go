@@ -18,9 +18,23 @@
 package compress
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/commons/bytex"
 	"github.com/aacfactory/fns/transports"
 	"github.com/valyala/fasthttp"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrInvalidRequestBody         = errors.BadRequest("fns: invalid compressed request body")
+	ErrRequestBodyTooLarge        = errors.New(http.StatusRequestEntityTooLarge, "***TOO LARGE***", "fns: decompressed request body is too large")
+	ErrUnsupportedContentEncoding = errors.New(http.StatusUnsupportedMediaType, "***UNSUPPORTED MEDIA TYPE***", "fns: content encoding of request is not supported")
 )
 
 func DecodeResponse(header transports.Header, body []byte) (p []byte, err error) {
@@ -41,3 +55,48 @@ func DecodeResponse(header transports.Header, body []byte) (p []byte, err error)
 	}
 	return
 }
+
+// DecodeRequest
+// decompresses body of request which is gzip or deflate encoded, and removes content encoding of request.
+// decompressed body larger than max is rejected with ErrRequestBodyTooLarge, so a zip bomb is never fully inflated.
+func DecodeRequest(r transports.Request, max int) (err error) {
+	contentEncoding := strings.ToLower(strings.TrimSpace(bytex.ToString(r.Header().Get(transports.ContentEncodingHeaderName))))
+	if contentEncoding == "" || contentEncoding == "identity" {
+		return
+	}
+	body, bodyErr := r.Body()
+	if bodyErr != nil {
+		err = ErrInvalidRequestBody.WithCause(bodyErr)
+		return
+	}
+	var reader io.ReadCloser
+	var readerErr error
+	switch contentEncoding {
+	case GzipName, "x-gzip":
+		reader, readerErr = gzip.NewReader(bytes.NewReader(body))
+		break
+	case DeflateName:
+		reader, readerErr = zlib.NewReader(bytes.NewReader(body))
+		break
+	default:
+		err = ErrUnsupportedContentEncoding.WithMeta("encoding", contentEncoding)
+		return
+	}
+	if readerErr != nil {
+		err = ErrInvalidRequestBody.WithMeta("encoding", contentEncoding).WithCause(readerErr)
+		return
+	}
+	p, readErr := io.ReadAll(io.LimitReader(reader, int64(max)+1))
+	_ = reader.Close()
+	if readErr != nil {
+		err = ErrInvalidRequestBody.WithMeta("encoding", contentEncoding).WithCause(readErr)
+		return
+	}
+	if len(p) > max {
+		err = ErrRequestBodyTooLarge.WithMeta("encoding", contentEncoding).WithCause(fmt.Errorf("max is %d bytes", max))
+		return
+	}
+	r.SetBody(p)
+	r.Header().Del(transports.ContentEncodingHeaderName)
+	return
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package compress_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/fns/transports/fast"
+	"github.com/aacfactory/fns/transports/middlewares/compress"
+	"github.com/valyala/fasthttp"
+	"net/http"
+	"testing"
+)
+
+func newRequest(encoding string, body []byte) transports.Request {
+	rc := &fasthttp.RequestCtx{}
+	rc.Request.Header.SetMethod(http.MethodPost)
+	rc.Request.Header.Set("Content-Encoding", encoding)
+	rc.Request.SetBody(body)
+	return &fast.Request{Context: &fast.Context{RequestCtx: rc}}
+}
+
+func TestDecodeRequest(t *testing.T) {
+	body := []byte(`{"name":"fns"}`)
+	gz := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(gz)
+	_, _ = gw.Write(body)
+	_ = gw.Close()
+	zz := bytes.NewBuffer(nil)
+	zw := zlib.NewWriter(zz)
+	_, _ = zw.Write(body)
+	_ = zw.Close()
+	for encoding, p := range map[string][]byte{"gzip": gz.Bytes(), "deflate": zz.Bytes()} {
+		r := newRequest(encoding, p)
+		if err := compress.DecodeRequest(r, 1024); err != nil {
+			t.Fatal(encoding, err)
+		}
+		decoded, _ := r.Body()
+		if !bytes.Equal(decoded, body) {
+			t.Fatal(encoding, "unexpected body", string(decoded))
+		}
+		if len(r.Header().Get(transports.ContentEncodingHeaderName)) > 0 {
+			t.Fatal(encoding, "content encoding was not removed")
+		}
+	}
+	// malformed
+	err := compress.DecodeRequest(newRequest("gzip", body), 1024)
+	if err == nil || errors.Wrap(err).Code() != http.StatusBadRequest {
+		t.Fatal("want bad request, got", err)
+	}
+	// too large
+	err = compress.DecodeRequest(newRequest("gzip", gz.Bytes()), len(body)-1)
+	if err == nil || errors.Wrap(err).Code() != http.StatusRequestEntityTooLarge {
+		t.Fatal("want too large, got", err)
+	}
+	// unsupported
+	err = compress.DecodeRequest(newRequest("br", body), 1024)
+	if err == nil || errors.Wrap(err).Code() != http.StatusUnsupportedMediaType {
+		t.Fatal("want unsupported, got", err)
+	}
+}
@@ -58,28 +58,44 @@ func (kind Kind) String() string {
 	}
 }
 
-func getKind(r transports.Request) Kind {
-	accepts := transports.GetAcceptEncodings(r.Header())
-	acceptsLen := len(accepts)
-	if acceptsLen == 0 {
+func kindOf(name []byte) Kind {
+	switch bytex.ToString(name) {
+	case DefaultName:
+		return Default
+	case GzipName:
+		return Gzip
+	case DeflateName:
+		return Deflate
+	case BrotliName:
+		return Brotli
+	case AnyName:
+		return Any
+	default:
 		return No
 	}
-	for i := acceptsLen - 1; i >= 0; i-- {
+}
+
+// getKind
+// returns the supported kind which has the highest quality in Accept-Encoding, q=0 means not acceptable.
+// when qualities are equal, preferred is chosen if it is accepted, otherwise the first one.
+func getKind(header transports.Header, preferred Kind) Kind {
+	accepts := transports.GetAcceptEncodings(header)
+	kind := No
+	quality := 0.0
+	for i := len(accepts) - 1; i >= 0; i-- {
 		accept := accepts[i]
-		switch bytex.ToString(accept.Name) {
-		case DefaultName:
-			return Default
-		case GzipName:
-			return Gzip
-		case DeflateName:
-			return Deflate
-		case BrotliName:
-			return Brotli
-		case AnyName:
-			return Any
-		default:
+		if accept.Quality <= 0 || accept.Quality < quality {
 			break
 		}
+		k := kindOf(accept.Name)
+		if k == No {
+			continue
+		}
+		if k == preferred {
+			return k
+		}
+		kind = k
+		quality = accept.Quality
 	}
-	return No
+	return kind
 }
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package compress
+
+import (
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/transports"
+	"strings"
+)
+
+const (
+	NoneLevelName    = "none"
+	SpeedLevelName   = "speed"
+	DefaultLevelName = "default"
+	BestLevelName    = "best"
+)
+
+// Level
+// is the hint of compression cost which is sent by client in X-Fns-Compress-Level header.
+// levels are tiers of each algorithm, cause numbers of levels are different between gzip and brotli.
+type Level int
+
+const (
+	NoneLevel Level = iota
+	SpeedLevel
+	DefaultLevel
+	BestLevel
+)
+
+func (level Level) String() string {
+	switch level {
+	case NoneLevel:
+		return NoneLevelName
+	case SpeedLevel:
+		return SpeedLevelName
+	case BestLevel:
+		return BestLevelName
+	default:
+		return DefaultLevelName
+	}
+}
+
+func ParseLevel(s string) (level Level, ok bool) {
+	ok = true
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case NoneLevelName:
+		level = NoneLevel
+		break
+	case SpeedLevelName:
+		level = SpeedLevel
+		break
+	case DefaultLevelName:
+		level = DefaultLevel
+		break
+	case BestLevelName:
+		level = BestLevel
+		break
+	default:
+		ok = false
+		break
+	}
+	return
+}
+
+// getLevel
+// returns level of request, it is capped by max, invalid hint means DefaultLevel.
+func getLevel(header transports.Header, max Level) Level {
+	level := DefaultLevel
+	if hint := header.Get(transports.CompressLevelHeaderName); len(hint) > 0 {
+		if hinted, ok := ParseLevel(bytex.ToString(hint)); ok {
+			level = hinted
+		}
+	}
+	if level > max {
+		level = max
+	}
+	return level
+}
@@ -19,12 +19,14 @@ package compress
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/commons/bytex"
 	"github.com/aacfactory/fns/transports"
 	"github.com/aacfactory/logs"
 	"github.com/valyala/fasthttp"
 	"slices"
+	"strings"
 )
 
 var (
@@ -34,10 +36,25 @@ var (
 	strFontSlash        = []byte("font/")
 	strMultipartSlash   = []byte("multipart/")
 	strTextSlash        = []byte("text/")
+	// compressed already
+	compressedContentTypes = [][]byte{
+		[]byte("application/zip"),
+		[]byte("application/gzip"),
+		[]byte("application/x-gzip"),
+		[]byte("application/x-bzip2"),
+		[]byte("application/x-xz"),
+		[]byte("application/x-7z-compressed"),
+		[]byte("application/x-rar-compressed"),
+		[]byte("application/x-brotli"),
+		[]byte("application/zstd"),
+		[]byte("font/woff"),
+		[]byte("font/woff2"),
+	}
 )
 
 const (
-	minCompressLen = 200
+	minCompressLen        = 200
+	defaultMaxRequestSize = 4 * bytex.MEGABYTE
 )
 
 func New() transports.Middleware {
@@ -47,10 +64,13 @@ func New() transports.Middleware {
 type Middleware struct {
 	log        logs.Logger
 	enable     bool
-	compressor Compressor
-	gzip       *GzipCompressor
-	deflate    *DeflateCompressor
-	brotli     *BrotliCompressor
+	kind       Kind
+	maxLevel   Level
+	gzip       [4]Compressor
+	deflate    [4]Compressor
+	brotli     [4]Compressor
+	decompress bool
+	maxSize    int
 }
 
 func (middle *Middleware) Name() string {
@@ -64,6 +84,17 @@ func (middle *Middleware) Construct(options transports.MiddlewareOptions) error
 	if configErr != nil {
 		return errors.Warning("fns: construct compress middleware failed").WithCause(configErr)
 	}
+	if config.Request.Enable {
+		middle.decompress = true
+		middle.maxSize = defaultMaxRequestSize
+		if s := strings.TrimSpace(config.Request.MaxSize); s != "" {
+			size, sizeErr := bytex.ParseBytes(s)
+			if sizeErr != nil {
+				return errors.Warning("fns: construct compress middleware failed").WithCause(sizeErr).WithMeta("maxSize", s)
+			}
+			middle.maxSize = int(size)
+		}
+	}
 	if !config.Enable {
 		return nil
 	}
@@ -72,78 +103,78 @@ func (middle *Middleware) Construct(options transports.MiddlewareOptions) error
 	if !slices.Contains([]int{fasthttp.CompressBestSpeed, fasthttp.CompressBestCompression, fasthttp.CompressDefaultCompression, fasthttp.CompressHuffmanOnly}, gzipLevel) {
 		gzipLevel = fasthttp.CompressDefaultCompression
 	}
-	middle.gzip = &GzipCompressor{
-		level: gzipLevel,
+	middle.gzip = [4]Compressor{
+		nil,
+		&GzipCompressor{level: fasthttp.CompressBestSpeed},
+		&GzipCompressor{level: gzipLevel},
+		&GzipCompressor{level: fasthttp.CompressBestCompression},
 	}
 	// deflate
 	deflateLevel := config.DeflateLevel
 	if !slices.Contains([]int{fasthttp.CompressBestSpeed, fasthttp.CompressBestCompression, fasthttp.CompressDefaultCompression, fasthttp.CompressHuffmanOnly}, deflateLevel) {
 		deflateLevel = fasthttp.CompressDefaultCompression
 	}
-	middle.deflate = &DeflateCompressor{
-		level: deflateLevel,
+	middle.deflate = [4]Compressor{
+		nil,
+		&DeflateCompressor{level: fasthttp.CompressBestSpeed},
+		&DeflateCompressor{level: deflateLevel},
+		&DeflateCompressor{level: fasthttp.CompressBestCompression},
 	}
 	// brotli
 	brotliLevel := config.BrotliLevel
 	if !slices.Contains([]int{fasthttp.CompressBrotliBestSpeed, fasthttp.CompressBrotliBestCompression, fasthttp.CompressBrotliDefaultCompression}, brotliLevel) {
 		brotliLevel = fasthttp.CompressBrotliDefaultCompression
 	}
-	middle.brotli = &BrotliCompressor{
-		level: brotliLevel,
+	middle.brotli = [4]Compressor{
+		nil,
+		&BrotliCompressor{level: fasthttp.CompressBrotliBestSpeed},
+		&BrotliCompressor{level: brotliLevel},
+		&BrotliCompressor{level: fasthttp.CompressBrotliBestCompression},
 	}
 	switch config.Default {
 	case BrotliName:
-		middle.compressor = middle.brotli
-		break
-	case DeflateName:
-		middle.compressor = middle.deflate
+		middle.kind = Brotli
 		break
 	case GzipName:
-		middle.compressor = middle.gzip
+		middle.kind = Gzip
 		break
 	default:
-		middle.compressor = middle.deflate
+		middle.kind = Deflate
 		break
 	}
+	// max level
+	middle.maxLevel = DefaultLevel
+	if s := strings.TrimSpace(config.MaxLevel); s != "" {
+		maxLevel, ok := ParseLevel(s)
+		if !ok {
+			return errors.Warning("fns: construct compress middleware failed").WithCause(fmt.Errorf("invalid max level")).WithMeta("maxLevel", s)
+		}
+		middle.maxLevel = maxLevel
+	}
 	middle.enable = true
 	return nil
 }
 
 func (middle *Middleware) Handler(next transports.Handler) transports.Handler {
+	if middle.decompress {
+		next = middle.decompressHandler(next)
+	}
 	if middle.enable {
 		return transports.HandlerFunc(func(w transports.ResponseWriter, r transports.Request) {
 			next.Handle(w, r)
 			if w.BodyLen() < minCompressLen {
 				return
 			}
-			contentType := w.Header().Get(transports.ContentTypeHeaderName)
-			canCompress := bytes.HasPrefix(contentType, strTextSlash) ||
-				bytes.HasPrefix(contentType, strApplicationSlash) ||
-				bytes.HasPrefix(contentType, strImageSVG) ||
-				bytes.HasPrefix(contentType, strImageIcon) ||
-				bytes.HasPrefix(contentType, strFontSlash) ||
-				bytes.HasPrefix(contentType, strMultipartSlash)
-			if !canCompress {
+			if len(w.Header().Get(transports.ContentEncodingHeaderName)) > 0 {
 				return
 			}
-			kind := getKind(r)
-			var c Compressor = nil
-			switch kind {
-			case Any, Default:
-				c = middle.compressor
-				break
-			case Gzip:
-				c = middle.gzip
-				break
-			case Deflate:
-				c = middle.deflate
-				break
-			case Brotli:
-				c = middle.brotli
-				break
-			default:
-				break
+			contentType := w.Header().Get(transports.ContentTypeHeaderName)
+			if !canCompress(contentType) {
+				return
 			}
+			// body depends on Accept-Encoding from now on, even if it is not compressed
+			w.Header().Add(transports.VaryHeaderName, transports.AcceptEncodingHeaderName)
+			c := middle.compressor(getKind(r.Header(), middle.kind), getLevel(r.Header(), middle.maxLevel))
 			if c == nil {
 				return
 			}
@@ -151,13 +182,12 @@ func (middle *Middleware) Handler(next transports.Handler) transports.Handler {
 			compressed, compressErr := c.Compress(body)
 			if compressErr != nil {
 				if middle.log.WarnEnabled() {
-					middle.log.Warn().Cause(compressErr).With("compress", kind.String()).Message("fns: compress response body failed")
+					middle.log.Warn().Cause(compressErr).With("compress", c.Name()).Message("fns: compress response body failed")
 				}
 				return
 			}
 			// header
 			w.Header().Set(transports.ContentEncodingHeaderName, bytex.FromString(c.Name()))
-			w.Header().Add(transports.VaryHeaderName, transports.AcceptEncodingHeaderName)
 			// body
 			w.ResetBody()
 			_, _ = w.Write(compressed)
@@ -166,6 +196,53 @@ func (middle *Middleware) Handler(next transports.Handler) transports.Handler {
 	return next
 }
 
+func (middle *Middleware) compressor(kind Kind, level Level) (c Compressor) {
+	switch kind {
+	case Any, Default:
+		c = middle.compressor(middle.kind, level)
+		break
+	case Gzip:
+		c = middle.gzip[level]
+		break
+	case Deflate:
+		c = middle.deflate[level]
+		break
+	case Brotli:
+		c = middle.brotli[level]
+		break
+	default:
+		break
+	}
+	return
+}
+
+func canCompress(contentType []byte) bool {
+	for _, compressed := range compressedContentTypes {
+		if bytes.HasPrefix(contentType, compressed) {
+			return false
+		}
+	}
+	return bytes.HasPrefix(contentType, strTextSlash) ||
+		bytes.HasPrefix(contentType, strApplicationSlash) ||
+		bytes.HasPrefix(contentType, strImageSVG) ||
+		bytes.HasPrefix(contentType, strImageIcon) ||
+		bytes.HasPrefix(contentType, strFontSlash) ||
+		bytes.HasPrefix(contentType, strMultipartSlash)
+}
+
 func (middle *Middleware) Close() (err error) {
 	return
 }
+
+func (middle *Middleware) decompressHandler(next transports.Handler) transports.Handler {
+	return transports.HandlerFunc(func(w transports.ResponseWriter, r transports.Request) {
+		if err := DecodeRequest(r, middle.maxSize); err != nil {
+			if middle.log.DebugEnabled() {
+				middle.log.Debug().Cause(err).Message("fns: decompress request body failed")
+			}
+			w.Failed(err)
+			return
+		}
+		next.Handle(w, r)
+	})
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package contracts
+// checks params and results of fns against their documents at runtime, so drift between struct and document is found early.
+// it decodes payloads twice, so only enable it in development or staging.
+package contracts
+
+import (
+	"bytes"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/runtime"
+	"github.com/aacfactory/fns/services/documents"
+	"github.com/aacfactory/fns/transports"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrParamMismatched  = errors.New(http.StatusBadRequest, "***CONTRACT MISMATCHED***", "fns: param mismatched with document")
+	ErrResultMismatched = errors.New(http.StatusInternalServerError, "***CONTRACT MISMATCHED***", "fns: result mismatched with document")
+)
+
+var (
+	slashBytes = []byte{'/'}
+)
+
+// Config
+// Raise is false by default, then mismatches are only logged.
+type Config struct {
+	Enabled bool `json:"enabled" yaml:"enabled,omitempty"`
+	Raise   bool `json:"raise" yaml:"raise,omitempty"`
+}
+
+func New() transports.Middleware {
+	return &middleware{}
+}
+
+type middleware struct {
+	log     logs.Logger
+	enabled bool
+	raise   bool
+}
+
+func (m *middleware) Name() string {
+	return "contracts"
+}
+
+func (m *middleware) Construct(options transports.MiddlewareOptions) error {
+	config := Config{}
+	err := options.Config.As(&config)
+	if err != nil {
+		err = errors.Warning("fns: construct contracts middleware failed").WithCause(err)
+		return err
+	}
+	m.log = options.Log
+	m.enabled = config.Enabled
+	m.raise = config.Raise
+	if m.enabled && m.log.WarnEnabled() {
+		m.log.Warn().Message("fns: contracts middleware is enabled, do not use it in production")
+	}
+	return nil
+}
+
+func (m *middleware) Handler(next transports.Handler) transports.Handler {
+	if !m.enabled {
+		return next
+	}
+	return transports.HandlerFunc(func(w transports.ResponseWriter, r transports.Request) {
+		// only checks POST /{endpoint}/{fn} with json body
+		pathItems := bytes.Split(r.Path(), slashBytes)
+		if len(pathItems) != 3 || !bytes.Equal(r.Method(), transports.MethodPost) {
+			next.Handle(w, r)
+			return
+		}
+		endpointName, fnName := pathItems[1], pathItems[2]
+		endpoint, has := runtime.Endpoints(r).Get(r, endpointName)
+		if !has {
+			next.Handle(w, r)
+			return
+		}
+		document := endpoint.Document()
+		fn, hasFn := findFn(document, bytex.ToString(fnName))
+		if !hasFn {
+			next.Handle(w, r)
+			return
+		}
+		// param
+		streaming := false
+		if sr, ok := r.(transports.BodyStreamRequest); ok {
+			_, streaming = sr.BodyStream()
+		}
+		if !streaming && bytes.Contains(r.Header().Get(transports.ContentTypeHeaderName), transports.ContentTypeJsonHeaderValue) {
+			body, bodyErr := r.Body()
+			if bodyErr == nil && len(body) > 0 {
+				if problems := document.Check(fn.Param, body); len(problems) > 0 {
+					m.report("param", document.Name, fn.Name, problems)
+					if m.raise {
+						w.Failed(ErrParamMismatched.
+							WithMeta("endpoint", document.Name).WithMeta("fn", fn.Name).
+							WithMeta("problems", strings.Join(problems, "; ")))
+						return
+					}
+				}
+			}
+		}
+		next.Handle(w, r)
+		if w.Hijacked() || w.Status() != http.StatusOK || w.BodyLen() == 0 {
+			return
+		}
+		if !bytes.Contains(w.Header().Get(transports.ContentTypeHeaderName), transports.ContentTypeJsonHeaderValue) {
+			return
+		}
+		if problems := document.Check(fn.Result, w.Body()); len(problems) > 0 {
+			m.report("result", document.Name, fn.Name, problems)
+			if m.raise {
+				w.ResetBody()
+				w.Failed(ErrResultMismatched.
+					WithMeta("endpoint", document.Name).WithMeta("fn", fn.Name).
+					WithMeta("problems", strings.Join(problems, "; ")))
+			}
+		}
+	})
+}
+
+func (m *middleware) report(kind string, endpoint string, fn string, problems []string) {
+	if m.log.WarnEnabled() {
+		m.log.Warn().
+			With("endpoint", endpoint).With("fn", fn).With("kind", kind).
+			Message("fns: contract mismatched\n" + strings.Join(problems, "\n"))
+	}
+}
+
+func (m *middleware) Close() (err error) {
+	return
+}
+
+func findFn(document documents.Endpoint, name string) (fn documents.Fn, has bool) {
+	for _, f := range document.Functions {
+		if f.Name == name {
+			fn = f
+			has = true
+			return
+		}
+	}
+	return
+}
@@ -72,7 +72,7 @@ func (c *corsMiddleware) Construct(options transports.MiddlewareOptions) (err er
 			string(transports.XRequestedWithHeaderName),
 			string(transports.ConnectionHeaderName), string(transports.UpgradeHeaderName),
 			string(transports.XForwardedForHeaderName), string(transports.TrueClientIpHeaderName), string(transports.XRealIpHeaderName),
-			string(transports.DeviceIpHeaderName), string(transports.DeviceIdHeaderName),
+			string(transports.DeviceIpHeaderName), string(transports.DeviceIdHeaderName), string(transports.TenantHeaderName),
 			string(transports.RequestIdHeaderName),
 			string(transports.RequestTimeoutHeaderName), string(transports.RequestVersionsHeaderName),
 			string(transports.CacheControlHeaderIfNonMatch), string(transports.CacheControlHeaderName),
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package recordings
+
+import (
+	"bytes"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/context"
+	"github.com/aacfactory/fns/transports"
+	"strconv"
+)
+
+var (
+	recordingsPath = []byte("/application/recordings")
+	limitParamName = []byte("limit")
+)
+
+// Handler
+// serves records at /application/recordings, the newest is the first, `limit` query limits the number of records.
+// Records contain payloads, so serve it by the management transport.
+func Handler() transports.MuxHandler {
+	return &handler{}
+}
+
+type handler struct{}
+
+func (h *handler) Name() string {
+	return "recordings"
+}
+
+func (h *handler) Construct(_ transports.MuxHandlerOptions) error {
+	return nil
+}
+
+func (h *handler) Match(_ context.Context, method []byte, path []byte, _ transports.Header) bool {
+	return bytes.Equal(method, transports.MethodGet) && bytes.Equal(path, recordingsPath)
+}
+
+func (h *handler) Handle(w transports.ResponseWriter, r transports.Request) {
+	limit := 0
+	if p := r.Params().Get(limitParamName); len(p) > 0 {
+		n, parseErr := strconv.Atoi(bytex.ToString(p))
+		if parseErr == nil {
+			limit = n
+		}
+	}
+	w.Succeed(Records(limit))
+	return
+}
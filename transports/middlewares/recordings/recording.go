@@ -0,0 +1,315 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+// Package recordings
+// records the last requests with their params, results, errors and latency for postmortems,
+// records are kept in a ring buffer, optionally appended to a file as json lines, and served by Handler.
+// Values of masked fields are replaced before records are kept, authorization and cookies are never recorded.
+package recordings
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/masks"
+	"github.com/aacfactory/fns/logs"
+	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/json"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSize        = 256
+	defaultMaxBodySize = 64 * bytex.KILOBYTE
+)
+
+var (
+	skipPaths = [][]byte{[]byte("/application/"), []byte("/health")}
+)
+
+// Config
+// Size is the number of kept records, default is 256.
+// File is optional, records are also appended to it as json lines.
+// Mask lists field names (case-insensitive) of params and results whose values are replaced, it adds to the default list.
+type Config struct {
+	Enabled     bool     `json:"enabled" yaml:"enabled,omitempty"`
+	Size        int      `json:"size" yaml:"size,omitempty"`
+	MaxBodySize string   `json:"maxBodySize" yaml:"maxBodySize,omitempty"`
+	File        string   `json:"file" yaml:"file,omitempty"`
+	Mask        []string `json:"mask" yaml:"mask,omitempty"`
+}
+
+type Record struct {
+	Id       string          `json:"id"`
+	Time     time.Time       `json:"time"`
+	Method   string          `json:"method"`
+	Path     string          `json:"path"`
+	DeviceId string          `json:"deviceId"`
+	Params   json.RawMessage `json:"params,omitempty"`
+	Status   int             `json:"status"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    json.RawMessage `json:"error,omitempty"`
+	Latency  time.Duration   `json:"latency"`
+}
+
+var (
+	records = &ring{}
+)
+
+// Records
+// returns kept records, the newest is the first.
+func Records(limit int) (v []Record) {
+	v = records.list(limit)
+	return
+}
+
+func New() transports.Middleware {
+	return &middleware{}
+}
+
+type middleware struct {
+	log         logs.Logger
+	enabled     bool
+	maxBodySize int
+	mask        masks.Keys
+	file        *os.File
+	lines       chan []byte
+	wg          sync.WaitGroup
+}
+
+func (m *middleware) Name() string {
+	return "recordings"
+}
+
+func (m *middleware) Construct(options transports.MiddlewareOptions) (err error) {
+	config := Config{}
+	err = options.Config.As(&config)
+	if err != nil {
+		err = errors.Warning("fns: construct recordings middleware failed").WithCause(err)
+		return
+	}
+	m.log = options.Log
+	m.enabled = config.Enabled
+	if !m.enabled {
+		return
+	}
+	size := config.Size
+	if size < 1 {
+		size = defaultSize
+	}
+	records.reset(size)
+	m.maxBodySize = defaultMaxBodySize
+	if s := strings.TrimSpace(config.MaxBodySize); s != "" {
+		n, parseErr := bytex.ParseBytes(s)
+		if parseErr != nil {
+			err = errors.Warning("fns: construct recordings middleware failed").WithCause(parseErr).WithMeta("maxBodySize", s)
+			return
+		}
+		m.maxBodySize = int(n)
+	}
+	m.mask = masks.New(config.Mask...)
+	if file := strings.TrimSpace(config.File); file != "" {
+		m.file, err = os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			err = errors.Warning("fns: construct recordings middleware failed").WithCause(err).WithMeta("file", file)
+			return
+		}
+		m.lines = make(chan []byte, 1024)
+		m.wg.Add(1)
+		go m.writing()
+	}
+	if m.log.WarnEnabled() {
+		m.log.Warn().Message("fns: recordings middleware is enabled, payloads are kept in memory")
+	}
+	return
+}
+
+func (m *middleware) Handler(next transports.Handler) transports.Handler {
+	if !m.enabled {
+		return next
+	}
+	return transports.HandlerFunc(func(w transports.ResponseWriter, r transports.Request) {
+		path := r.Path()
+		for _, skip := range skipPaths {
+			if bytes.HasPrefix(path, skip) {
+				next.Handle(w, r)
+				return
+			}
+		}
+		record := Record{
+			Id:       string(r.Header().Get(transports.RequestIdHeaderName)),
+			Time:     time.Now(),
+			Method:   string(r.Method()),
+			Path:     string(path),
+			DeviceId: string(r.Header().Get(transports.DeviceIdHeaderName)),
+		}
+		if r.Params().Len() > 0 {
+			record.Params = m.maskQuery(r.Params().Encode())
+		} else {
+			streaming := false
+			if sr, ok := r.(transports.BodyStreamRequest); ok {
+				_, streaming = sr.BodyStream()
+			}
+			if !streaming {
+				if body, bodyErr := r.Body(); bodyErr == nil && len(body) > 0 {
+					record.Params = m.maskBody(body)
+				}
+			}
+		}
+		next.Handle(w, r)
+		record.Latency = time.Since(record.Time)
+		record.Status = w.Status()
+		if !w.Hijacked() && w.BodyLen() > 0 &&
+			bytes.Contains(w.Header().Get(transports.ContentTypeHeaderName), transports.ContentTypeJsonHeaderValue) {
+			if record.Status == 200 {
+				record.Result = m.maskBody(w.Body())
+			} else {
+				record.Error = m.maskBody(w.Body())
+			}
+		}
+		records.add(record)
+		if m.lines != nil {
+			line, encodeErr := json.Marshal(record)
+			if encodeErr == nil {
+				select {
+				case m.lines <- line:
+				default:
+					if m.log.DebugEnabled() {
+						m.log.Debug().Message("fns: recordings file is busy, record is dropped")
+					}
+				}
+			}
+		}
+	})
+}
+
+func (m *middleware) Close() (err error) {
+	if m.lines != nil {
+		close(m.lines)
+		m.wg.Wait()
+		err = m.file.Close()
+	}
+	return
+}
+
+func (m *middleware) writing() {
+	defer m.wg.Done()
+	writer := bufio.NewWriter(m.file)
+	for line := range m.lines {
+		_, _ = writer.Write(line)
+		_ = writer.WriteByte('\n')
+		if len(m.lines) == 0 {
+			if err := writer.Flush(); err != nil && m.log.WarnEnabled() {
+				m.log.Warn().Cause(err).Message("fns: write recordings file failed")
+			}
+		}
+	}
+	_ = writer.Flush()
+}
+
+func (m *middleware) maskBody(p []byte) json.RawMessage {
+	if len(p) > m.maxBodySize {
+		return json.RawMessage(strconv.Quote("<" + strconv.Itoa(len(p)) + " bytes>"))
+	}
+	if !json.Validate(p) {
+		return json.RawMessage(strconv.Quote(string(p)))
+	}
+	var v any
+	if err := json.Unmarshal(p, &v); err != nil {
+		return json.RawMessage(strconv.Quote(string(p)))
+	}
+	if !m.mask.Mask(v) {
+		return json.RawMessage(append([]byte{}, p...))
+	}
+	masked, encodeErr := json.Marshal(v)
+	if encodeErr != nil {
+		return nil
+	}
+	return masked
+}
+
+func (m *middleware) maskQuery(p []byte) json.RawMessage {
+	values, parseErr := url.ParseQuery(string(p))
+	if parseErr != nil {
+		return json.RawMessage(strconv.Quote(string(p)))
+	}
+	v := make(map[string]any, len(values))
+	for key, vv := range values {
+		if len(vv) == 1 {
+			v[key] = vv[0]
+		} else {
+			v[key] = vv
+		}
+	}
+	m.mask.Mask(v)
+	masked, encodeErr := json.Marshal(v)
+	if encodeErr != nil {
+		return nil
+	}
+	return masked
+}
+
+type ring struct {
+	mutex  sync.RWMutex
+	values []Record
+	next   int
+	full   bool
+}
+
+func (r *ring) reset(size int) {
+	r.mutex.Lock()
+	r.values = make([]Record, size)
+	r.next = 0
+	r.full = false
+	r.mutex.Unlock()
+}
+
+func (r *ring) add(record Record) {
+	r.mutex.Lock()
+	if len(r.values) > 0 {
+		r.values[r.next] = record
+		r.next++
+		if r.next == len(r.values) {
+			r.next = 0
+			r.full = true
+		}
+	}
+	r.mutex.Unlock()
+}
+
+func (r *ring) list(limit int) (v []Record) {
+	r.mutex.RLock()
+	n := r.next
+	if r.full {
+		n = len(r.values)
+	}
+	if limit < 1 || limit > n {
+		limit = n
+	}
+	v = make([]Record, 0, limit)
+	for i := 0; i < limit; i++ {
+		idx := (r.next - 1 - i + len(r.values)) % len(r.values)
+		v = append(v, r.values[idx])
+	}
+	r.mutex.RUnlock()
+	return
+}
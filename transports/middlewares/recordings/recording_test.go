@@ -0,0 +1,57 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package recordings
+
+import (
+	"github.com/aacfactory/fns/commons/masks"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware_Mask(t *testing.T) {
+	m := &middleware{
+		maxBodySize: 1024,
+		mask:        masks.New(),
+	}
+	body := string(m.maskBody([]byte(`{"name":"a","Password":"p","items":[{"token":"t","n":1}]}`)))
+	if strings.Contains(body, `"p"`) || strings.Contains(body, `"t"`) || !strings.Contains(body, `"a"`) {
+		t.Fatal("body is not masked", body)
+	}
+	query := string(m.maskQuery([]byte("name=a&password=p")))
+	if strings.Contains(query, `"p"`) || !strings.Contains(query, masks.Value) {
+		t.Fatal("query is not masked", query)
+	}
+	if large := string(m.maskBody(make([]byte, 2048))); large != `"<2048 bytes>"` {
+		t.Fatal("large body is not truncated", large)
+	}
+}
+
+func TestRing(t *testing.T) {
+	r := &ring{}
+	r.reset(3)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		r.add(Record{Id: id})
+	}
+	list := r.list(0)
+	if len(list) != 3 || list[0].Id != "4" || list[2].Id != "2" {
+		t.Fatal("unexpected records", list)
+	}
+	if list = r.list(1); len(list) != 1 || list[0].Id != "4" {
+		t.Fatal("unexpected limited records", list)
+	}
+}
@@ -288,6 +288,7 @@ func DecodeParams(params Params, dst interface{}) (err error) {
 			if err != nil {
 				return
 			}
+			continue
 		}
 		name := ft.Name
 		tag, hasTag := ft.Tag.Lookup("json")
@@ -308,7 +309,7 @@ func DecodeParams(params Params, dst interface{}) (err error) {
 		fv := rv.Field(i)
 		switch ft.Type.Kind() {
 		case reflect.String:
-			s := bytex.ToString(pv)
+			s := string(pv)
 			if ft.Type == stringType {
 				fv.SetString(s)
 			} else {
@@ -394,7 +395,7 @@ func DecodeParams(params Params, dst interface{}) (err error) {
 			case reflect.String:
 				ss := reflect.MakeSlice(ft.Type, 0, 1)
 				for _, pvx := range pvv {
-					s := bytex.ToString(pvx)
+					s := string(pvx)
 					e := reflect.New(eft).Elem()
 					if e.Type() == stringType {
 						e.SetString(s)
@@ -505,6 +506,14 @@ func DecodeParams(params Params, dst interface{}) (err error) {
 				return
 			}
 			break
+		case reflect.Pointer:
+			pe := reflect.New(ft.Type.Elem())
+			err = decodeParamValue(name, pv, pe.Elem())
+			if err != nil {
+				return
+			}
+			fv.Set(pe)
+			break
 		default:
 			err = errors.Warning("fns: decode param failed").WithCause(fmt.Errorf("type of %s is not supported", name))
 			return
@@ -512,3 +521,61 @@ func DecodeParams(params Params, dst interface{}) (err error) {
 	}
 	return
 }
+
+func decodeParamValue(name string, pv []byte, fv reflect.Value) (err error) {
+	ft := fv.Type()
+	switch ft.Kind() {
+	case reflect.String:
+		fv.Set(reflect.ValueOf(string(pv)).Convert(ft))
+		break
+	case reflect.Bool:
+		b, parseErr := strconv.ParseBool(bytex.ToString(pv))
+		if parseErr != nil {
+			err = errors.Warning("fns: decode param failed").WithCause(fmt.Errorf("%s is not bool", name))
+			return
+		}
+		fv.Set(reflect.ValueOf(b).Convert(ft))
+		break
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, parseErr := strconv.ParseInt(bytex.ToString(pv), 10, 64)
+		if parseErr != nil {
+			err = errors.Warning("fns: decode param failed").WithCause(fmt.Errorf("%s is not int", name))
+			return
+		}
+		fv.Set(reflect.ValueOf(n).Convert(ft))
+		break
+	case reflect.Float32, reflect.Float64:
+		f, parseErr := strconv.ParseFloat(bytex.ToString(pv), 64)
+		if parseErr != nil {
+			err = errors.Warning("fns: decode param failed").WithCause(fmt.Errorf("%s is not float", name))
+			return
+		}
+		fv.Set(reflect.ValueOf(f).Convert(ft))
+		break
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, parseErr := strconv.ParseUint(bytex.ToString(pv), 10, 64)
+		if parseErr != nil {
+			err = errors.Warning("fns: decode param failed").WithCause(fmt.Errorf("%s is not uint", name))
+			return
+		}
+		fv.Set(reflect.ValueOf(u).Convert(ft))
+		break
+	case reflect.Struct:
+		if ft == timeType || timeType.ConvertibleTo(ft) {
+			t, parseErr := time.Parse(time.RFC3339, bytex.ToString(pv))
+			if parseErr != nil {
+				err = errors.Warning("fns: decode param failed").WithCause(fmt.Errorf("%s is not RFC3339 time", name))
+				return
+			}
+			fv.Set(reflect.ValueOf(t).Convert(ft))
+		} else {
+			err = errors.Warning("fns: decode param failed").WithCause(fmt.Errorf("type of %s is not supported", name))
+			return
+		}
+		break
+	default:
+		err = errors.Warning("fns: decode param failed").WithCause(fmt.Errorf("type of %s is not supported", name))
+		return
+	}
+	return
+}
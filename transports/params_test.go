@@ -43,6 +43,8 @@ type Param struct {
 	Age    uint        `json:"age"`
 	Date   Date        `json:"date"`
 	Dates  []time.Time `json:"dates"`
+	Limit  *int        `json:"limit"`
+	Active *bool       `json:"active"`
 }
 
 func TestDecodeParams(t *testing.T) {
@@ -59,6 +61,8 @@ func TestDecodeParams(t *testing.T) {
 
 	params.Set([]byte("offset"), []byte("10"))
 	params.Set([]byte("length"), []byte("50"))
+	params.Set([]byte("limit"), []byte("5"))
+	params.Set([]byte("active"), []byte("true"))
 
 	fmt.Println(string(params.Encode()))
 
@@ -69,4 +73,7 @@ func TestDecodeParams(t *testing.T) {
 		return
 	}
 	fmt.Println(fmt.Sprintf("%+v", param))
+	if param.Limit == nil || *param.Limit != 5 || param.Active == nil || !*param.Active {
+		t.Error("pointer fields were not decoded")
+	}
 }
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transports
+
+import (
+	"bytes"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/commons/jsons"
+	"net/http"
+	"strings"
+)
+
+// Problem
+// is problem details of RFC 7807, it is rendered instead of CodeError when request accepts application/problem+json.
+// id and meta of CodeError are kept as extension members.
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Id       string            `json:"id,omitempty"`
+	Meta     map[string]string `json:"meta,omitempty"`
+}
+
+// NewProblem
+// maps CodeError to Problem, title is the name of error without stars, or the status text when name is empty.
+func NewProblem(err errors.CodeError, instance string) Problem {
+	title := strings.TrimSpace(strings.Trim(err.Name(), "*"))
+	if title == "" {
+		title = http.StatusText(err.Code())
+	}
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    title,
+		Status:   err.Code(),
+		Detail:   err.Message(),
+		Instance: instance,
+		Id:       err.Id(),
+		Meta:     nil,
+	}
+	p, encodeErr := jsons.Marshal(err)
+	if encodeErr != nil {
+		return problem
+	}
+	impl := errors.CodeErrorImpl{}
+	if decodeErr := jsons.Unmarshal(p, &impl); decodeErr != nil {
+		return problem
+	}
+	if len(impl.Meta_) > 0 {
+		problem.Meta = make(map[string]string, len(impl.Meta_))
+		for _, pair := range impl.Meta_ {
+			problem.Meta[pair.Key] = pair.Value
+		}
+	}
+	return problem
+}
+
+// AcceptsProblem
+// returns true when Accept header of request contains application/problem+json.
+func AcceptsProblem(header Header) bool {
+	for _, accept := range header.Values(AcceptHeaderName) {
+		for _, item := range bytes.Split(accept, []byte{','}) {
+			if idx := bytes.IndexByte(item, ';'); idx > -1 {
+				item = item[:idx]
+			}
+			if bytes.EqualFold(bytes.TrimSpace(item), ContentTypeProblemJsonHeaderValue) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NegotiateProblem
+// makes the writer render errors as Problem when request accepts it, the path of request is used as instance.
+func NegotiateProblem(w *ResultResponseWriter, r Request) {
+	if AcceptsProblem(r.Header()) {
+		w.problem = true
+		w.instance = string(r.Path())
+	}
+}
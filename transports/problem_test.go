@@ -0,0 +1,48 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transports_test
+
+import (
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/transports"
+	"testing"
+)
+
+func TestAcceptsProblem(t *testing.T) {
+	header := transports.NewHeader()
+	header.Set(transports.AcceptHeaderName, []byte("application/json"))
+	if transports.AcceptsProblem(header) {
+		t.Error("application/json must not accept problem")
+	}
+	header = transports.NewHeader()
+	header.Set(transports.AcceptHeaderName, []byte("application/json;q=0.9, Application/Problem+JSON; q=1"))
+	if !transports.AcceptsProblem(header) {
+		t.Error("problem must be accepted")
+	}
+}
+
+func TestNewProblem(t *testing.T) {
+	err := errors.NotFound("user was not found").WithMeta("user", "1")
+	problem := transports.NewProblem(err, "/users/get")
+	if problem.Status != 404 || problem.Title != "NOT FOUND" || problem.Detail != "user was not found" || problem.Instance != "/users/get" {
+		t.Error(problem)
+	}
+	if problem.Id == "" || problem.Meta["user"] != "1" {
+		t.Error(problem)
+	}
+}
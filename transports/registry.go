@@ -0,0 +1,89 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transports
+
+import (
+	"fmt"
+	"github.com/aacfactory/errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultTransportName
+	// is the name of fasthttp transport, it is used when name of Config is empty.
+	DefaultTransportName = "fasthttp"
+)
+
+type TransportConstructor func() Transport
+
+var (
+	registry      = map[string]TransportConstructor{}
+	registryMutex = sync.RWMutex{}
+)
+
+// Register
+// registers constructor of transport by name, then Config selects it by name, such as transport.name: "quic".
+// constructor must return a new transport every time, because listeners of transport, internal and management do not share it.
+// it panics when name is registered, so register it in init of package of transport, builtins are fasthttp and standard (nethttp).
+func Register(name string, constructor TransportConstructor) {
+	name = strings.TrimSpace(name)
+	if name == "" || constructor == nil {
+		panic(fmt.Errorf("fns: register transport failed for empty name or nil constructor"))
+		return
+	}
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if _, has := registry[name]; has {
+		panic(fmt.Errorf("fns: register transport failed for %s is registered", name))
+		return
+	}
+	registry[name] = constructor
+}
+
+// New
+// returns a new transport of name, empty name means DefaultTransportName.
+func New(name string) (transport Transport, err error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = DefaultTransportName
+	}
+	registryMutex.RLock()
+	constructor, has := registry[name]
+	registryMutex.RUnlock()
+	if !has {
+		err = errors.Warning("fns: new transport failed").WithCause(fmt.Errorf("%s was not registered", name)).WithMeta("registered", strings.Join(Registered(), ","))
+		return
+	}
+	transport = constructor()
+	return
+}
+
+// Registered
+// returns sorted names of registered transports.
+func Registered() (names []string) {
+	registryMutex.RLock()
+	names = make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	registryMutex.RUnlock()
+	sort.Strings(names)
+	return
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transports_test
+
+import (
+	"github.com/aacfactory/fns/transports"
+	_ "github.com/aacfactory/fns/transports/fast"
+	_ "github.com/aacfactory/fns/transports/standard"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	for name, expected := range map[string]string{"": "fasthttp", "fasthttp": "fasthttp", "standard": "standard", "nethttp": "standard"} {
+		transport, err := transports.New(name)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if transport.Name() != expected {
+			t.Fatal(name, "unexpected transport", transport.Name())
+		}
+	}
+	if _, err := transports.New("quic"); err == nil {
+		t.Fatal("unregistered transport was created")
+	}
+	a, _ := transports.New("fasthttp")
+	b, _ := transports.New("fasthttp")
+	if a == b {
+		t.Fatal("transport must be created every time")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering twice must panic")
+		}
+	}()
+	transports.Register("fasthttp", func() transports.Transport { return nil })
+}
@@ -21,16 +21,74 @@ import (
 	"crypto/tls"
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/context"
+	"io"
 	"net/http"
+	"strconv"
 )
 
 var (
-	ErrTooBigRequestBody = errors.New(http.StatusRequestEntityTooLarge, "***TOO LARGE BODY***", "fns: request body is too large")
+	ErrTooBigRequestBody     = errors.New(http.StatusRequestEntityTooLarge, "***TOO LARGE BODY***", "fns: request body is too large")
+	ErrTruncatedRequestBody  = errors.New(http.StatusBadRequest, "***TRUNCATED BODY***", "fns: request body is shorter than Content-Length")
+	ErrTooLargeRequestHeader = errors.New(http.StatusRequestHeaderFieldsTooLarge, "***TOO LARGE HEADER***", "fns: request header fields are too large")
 )
 
+const (
+	// DefaultMaxRequestHeaderSize
+	// is the same as total of large_client_header_buffers of nginx (4 * 8k).
+	DefaultMaxRequestHeaderSize = 32 * 1024
+	// DefaultMaxRequestHeaderCount
+	// is the same as LimitRequestFields of apache httpd and tune.http.maxhdr of haproxy.
+	DefaultMaxRequestHeaderCount = 100
+)
+
+// CheckContentLength
+// returns ErrTruncatedRequestBody when body is shorter than content length, content length less than 1 means it is absent.
+func CheckContentLength(contentLength int64, bodyLen int) error {
+	if contentLength > 0 && int64(bodyLen) < contentLength {
+		return ErrTruncatedRequestBody.
+			WithMeta("contentLength", strconv.FormatInt(contentLength, 10)).
+			WithMeta("read", strconv.Itoa(bodyLen))
+	}
+	return nil
+}
+
+// NewContentLengthReader
+// returns a reader which fails with ErrTruncatedRequestBody instead of EOF when reader ends before content length bytes,
+// so a broken upload is not decoded as partial json.
+func NewContentLengthReader(reader io.Reader, contentLength int64) io.Reader {
+	if contentLength < 1 {
+		return reader
+	}
+	return &contentLengthReader{
+		reader:        reader,
+		contentLength: contentLength,
+		read:          0,
+	}
+}
+
+type contentLengthReader struct {
+	reader        io.Reader
+	contentLength int64
+	read          int64
+}
+
+func (r *contentLengthReader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	r.read += int64(n)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		if truncated := CheckContentLength(r.contentLength, int(r.read)); truncated != nil {
+			err = truncated
+		}
+	}
+	return
+}
+
 var (
-	MethodGet  = []byte(http.MethodGet)
-	MethodPost = []byte(http.MethodPost)
+	MethodGet     = []byte(http.MethodGet)
+	MethodPost    = []byte(http.MethodPost)
+	MethodPut     = []byte(http.MethodPut)
+	MethodHead    = []byte(http.MethodHead)
+	MethodOptions = []byte(http.MethodOptions)
 )
 
 type Request interface {
@@ -53,6 +111,20 @@ type Request interface {
 	SetBody(body []byte)
 }
 
+// BodyStreamRequest
+// implemented by request of transport which can read body as stream, such as fast transport with streamRequestBody.
+// ok is false when body is already read or transport does not stream it.
+type BodyStreamRequest interface {
+	BodyStream() (reader io.Reader, ok bool)
+}
+
+// EscapedPathRequest
+// implemented by request of transport which keeps the escaped path, such as /users/a%2Fb,
+// Path of request is unescaped, so it can not tell escaped slash from separator.
+type EscapedPathRequest interface {
+	EscapedPath() []byte
+}
+
 var (
 	requestContextKey       = []byte("@fns:context:transports:request")
 	requestHeaderContextKey = []byte("@fns:context:transports:request:header")
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package transports_test
+
+import (
+	"bytes"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/transports"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestContentLengthReader(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	p, err := io.ReadAll(transports.NewContentLengthReader(bytes.NewReader(body), int64(len(body))))
+	if err != nil || !bytes.Equal(p, body) {
+		t.Fatal("complete body must be read", string(p), err)
+	}
+	_, err = io.ReadAll(transports.NewContentLengthReader(bytes.NewReader(body[:5]), int64(len(body))))
+	codeErr, ok := errors.As(err)
+	if !ok || codeErr.Code() != http.StatusBadRequest {
+		t.Fatal("truncated body must fail with 400", err)
+	}
+	if err = transports.CheckContentLength(0, 0); err != nil {
+		t.Fatal("absent content length must be passed", err)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/commons/bytex"
+	"github.com/aacfactory/fns/commons/jsons"
 	"github.com/aacfactory/fns/commons/objects"
 	"github.com/aacfactory/fns/context"
 	"github.com/valyala/bytebufferpool"
@@ -51,6 +52,14 @@ type ResponseWriter interface {
 	WriteDeadline() time.Time
 }
 
+// BodyStreamResponseWriter
+// implemented by response writer of transport which can respond body from reader without buffering it, such as fast and standard transports.
+// size is length of body, -1 means unknown. reader is closed after responded when it is io.Closer.
+// body which is written by Write is ignored when body stream is set.
+type BodyStreamResponseWriter interface {
+	SetBodyStream(reader io.Reader, size int64)
+}
+
 type WriteBuffer interface {
 	io.Writer
 	Bytes() []byte
@@ -93,6 +102,8 @@ func ReleaseResultResponseWriter(w *ResultResponseWriter) {
 	w.status = 0
 	w.timeout = 0
 	w.deadline = time.Time{}
+	w.problem = false
+	w.instance = ""
 	responseWriterPool.Put(w)
 }
 
@@ -103,6 +114,8 @@ type ResultResponseWriter struct {
 	deadline    time.Time
 	header      Header
 	body        *bytebufferpool.ByteBuffer
+	problem     bool
+	instance    string
 }
 
 func (w *ResultResponseWriter) Status() int {
@@ -160,6 +173,10 @@ func (w *ResultResponseWriter) Failed(cause error) {
 		cause = errors.Warning("fns: error is lost")
 	}
 	err := errors.Wrap(cause)
+	if w.problem {
+		w.failedWithProblem(err)
+		return
+	}
 	encoder, contentType := GetMarshaler(w.contentType)
 	body, bodyErr := encoder(err)
 	if bodyErr != nil {
@@ -174,6 +191,20 @@ func (w *ResultResponseWriter) Failed(cause error) {
 	return
 }
 
+func (w *ResultResponseWriter) failedWithProblem(err errors.CodeError) {
+	body, bodyErr := jsons.Marshal(NewProblem(err, w.instance))
+	if bodyErr != nil {
+		w.status = 666
+		w.header.Set(ContentTypeHeaderName, ContentTypeTextHeaderValue)
+		_, _ = w.Write([]byte(fmt.Sprintf("%+v", bodyErr)))
+		return
+	}
+	w.status = err.Code()
+	w.header.Set(ContentTypeHeaderName, ContentTypeProblemJsonHeaderValue)
+	_, _ = w.Write(body)
+	return
+}
+
 func (w *ResultResponseWriter) Write(body []byte) (int, error) {
 	bodyLen := len(body)
 	w.Header().Set(ContentLengthHeaderName, bytex.FromString(strconv.Itoa(bodyLen)))
@@ -21,7 +21,10 @@ import (
 	"github.com/aacfactory/fns/commons/bytex"
 	"github.com/aacfactory/fns/context"
 	"github.com/aacfactory/fns/transports"
+	"github.com/aacfactory/json"
+	"io"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -31,6 +34,26 @@ var (
 	responsePool = sync.Pool{}
 )
 
+// headerGuard
+// answers requests with more header fields than maxCount with 431,
+// size of header is guarded by MaxHeaderBytes of http.Server.
+func headerGuard(h http.Handler, maxCount int) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		count := 0
+		for _, values := range request.Header {
+			count += len(values)
+		}
+		if count > maxCount {
+			p, _ := json.Marshal(transports.ErrTooLargeRequestHeader)
+			writer.Header().Set(bytex.ToString(transports.ContentTypeHeaderName), bytex.ToString(transports.ContentTypeJsonHeaderValue))
+			writer.WriteHeader(transports.ErrTooLargeRequestHeader.Code())
+			_, _ = writer.Write(p)
+			return
+		}
+		h.ServeHTTP(writer, request)
+	})
+}
+
 func HttpTransportHandlerAdaptor(h transports.Handler, maxRequestBody int, writeTimeout time.Duration) http.Handler {
 	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		ctx := context.Acquire(request.Context())
@@ -57,6 +80,7 @@ func HttpTransportHandlerAdaptor(h transports.Handler, maxRequestBody int, write
 		w.writer = writer
 		w.header = WrapHttpHeader(writer.Header())
 		w.result = transports.AcquireResultResponseWriter(writeTimeout, r.Header().Get(transports.ContentTypeHeaderName))
+		transports.NegotiateProblem(w.result, r)
 
 		h.Handle(w, r)
 		w.result.Header().Foreach(func(key []byte, values [][]byte) {
@@ -64,16 +88,27 @@ func HttpTransportHandlerAdaptor(h transports.Handler, maxRequestBody int, write
 				writer.Header().Add(bytex.ToString(key), bytex.ToString(value))
 			}
 		})
-		writer.WriteHeader(w.Status())
-		if bodyLen := w.BodyLen(); bodyLen > 0 {
-			body := w.Body()
-			n := 0
-			for n < bodyLen {
-				nn, writeErr := writer.Write(body[n:])
-				if writeErr != nil {
-					break
+		if w.stream != nil {
+			if w.size > -1 {
+				writer.Header().Set(bytex.ToString(transports.ContentLengthHeaderName), strconv.FormatInt(w.size, 10))
+			}
+			writer.WriteHeader(w.Status())
+			_, _ = io.Copy(writer, w.stream)
+			if closer, ok := w.stream.(io.Closer); ok {
+				_ = closer.Close()
+			}
+		} else {
+			writer.WriteHeader(w.Status())
+			if bodyLen := w.BodyLen(); bodyLen > 0 {
+				body := w.Body()
+				n := 0
+				for n < bodyLen {
+					nn, writeErr := writer.Write(body[n:])
+					if writeErr != nil {
+						break
+					}
+					n += nn
 				}
-				n += nn
 			}
 		}
 
@@ -84,6 +119,8 @@ func HttpTransportHandlerAdaptor(h transports.Handler, maxRequestBody int, write
 			w.header = nil
 			w.result = nil
 			w.hijacked = false
+			w.stream = nil
+			w.size = 0
 			responsePool.Put(w)
 
 			r.Context = nil
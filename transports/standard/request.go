@@ -95,6 +95,10 @@ func (r *Request) Path() []byte {
 	return bytex.FromString(r.request.URL.Path)
 }
 
+func (r *Request) EscapedPath() []byte {
+	return bytex.FromString(r.request.URL.EscapedPath())
+}
+
 func (r *Request) Params() transports.Params {
 	return &Params{
 		values: r.request.URL.Query(),
@@ -110,8 +114,9 @@ func (r *Request) Body() ([]byte, error) {
 	defer bytebufferpool.Put(buf)
 	b := bytex.Acquire4KBuffer()
 	defer bytex.Release4KBuffer(b)
+	reader := transports.NewContentLengthReader(r.request.Body, r.request.ContentLength)
 	for {
-		n, readErr := r.request.Body.Read(b)
+		n, readErr := reader.Read(b)
 		if n > 0 {
 			_, _ = buf.Write(b[0:n])
 		}
@@ -119,6 +124,9 @@ func (r *Request) Body() ([]byte, error) {
 			if readErr == io.EOF {
 				break
 			}
+			if codeErr, ok := errors.As(readErr); ok {
+				return nil, codeErr
+			}
 			return nil, errors.Warning("fns: read request body failed").WithCause(readErr)
 		}
 		if r.maxBodySize > 0 {
@@ -130,6 +138,39 @@ func (r *Request) Body() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+func (r *Request) BodyStream() (reader io.Reader, ok bool) {
+	if r.request.Body == nil || r.request.Body == http.NoBody {
+		return
+	}
+	reader = &limitedBodyReader{
+		reader: transports.NewContentLengthReader(r.request.Body, r.request.ContentLength),
+		max:    r.maxBodySize,
+	}
+	ok = true
+	return
+}
+
+// limitedBodyReader
+// fails every read once more than max bytes are read, max less than 1 means no limit.
+type limitedBodyReader struct {
+	reader io.Reader
+	max    int
+	read   int
+}
+
+func (r *limitedBodyReader) Read(p []byte) (n int, err error) {
+	if r.max > 0 && r.read > r.max {
+		err = transports.ErrTooBigRequestBody
+		return
+	}
+	n, err = r.reader.Read(p)
+	r.read += n
+	if r.max > 0 && r.read > r.max {
+		err = transports.ErrTooBigRequestBody
+	}
+	return
+}
+
 func (r *Request) SetBody(body []byte) {
 	if len(body) == 0 {
 		return
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2023 Wang Min Xiang
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * 	http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package standard
+
+import (
+	"bytes"
+	"github.com/aacfactory/errors"
+	"github.com/aacfactory/fns/transports"
+	"io"
+	"testing"
+)
+
+func isTooBigRequestBody(err error) bool {
+	codeErr, ok := errors.As(err)
+	return ok && codeErr.Name() == errors.Wrap(transports.ErrTooBigRequestBody).Name()
+}
+
+func TestLimitedBodyReader(t *testing.T) {
+	read := func(size int, max int) (n int, err error) {
+		r := &limitedBodyReader{
+			reader: bytes.NewReader(bytes.Repeat([]byte{'a'}, size)),
+			max:    max,
+		}
+		p := make([]byte, 3)
+		for {
+			nn, readErr := r.Read(p)
+			n += nn
+			if readErr != nil {
+				if readErr != io.EOF {
+					err = readErr
+				}
+				return
+			}
+		}
+	}
+	// exactly max
+	if n, err := read(8, 8); err != nil || n != 8 {
+		t.Fatal("exactly max should be read", n, err)
+	}
+	// one byte over max, which was read unbounded when remain was 0
+	if _, err := read(9, 8); !isTooBigRequestBody(err) {
+		t.Fatal("over max should fail", err)
+	}
+	if _, err := read(64, 8); !isTooBigRequestBody(err) {
+		t.Fatal("over max should fail", err)
+	}
+	// no limit
+	if n, err := read(64, 0); err != nil || n != 64 {
+		t.Fatal("no limit should be read", n, err)
+	}
+	// every read fails after max is passed
+	r := &limitedBodyReader{reader: bytes.NewReader(bytes.Repeat([]byte{'a'}, 16)), max: 4}
+	p := make([]byte, 8)
+	_, _ = r.Read(p)
+	if n, err := r.Read(p); n != 0 || !isTooBigRequestBody(err) {
+		t.Fatal("read after max should fail", n, err)
+	}
+}
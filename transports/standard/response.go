@@ -23,6 +23,7 @@ import (
 	"github.com/aacfactory/fns/commons/bytex"
 	"github.com/aacfactory/fns/context"
 	"github.com/aacfactory/fns/transports"
+	"io"
 	"net"
 	"net/http"
 	"time"
@@ -34,6 +35,14 @@ type ResponseWriter struct {
 	header   transports.Header
 	result   *transports.ResultResponseWriter
 	hijacked bool
+	stream   io.Reader
+	size     int64
+}
+
+func (w *ResponseWriter) SetBodyStream(reader io.Reader, size int64) {
+	w.result.ResetBody()
+	w.stream = reader
+	w.size = size
 }
 
 func (w *ResponseWriter) Status() int {
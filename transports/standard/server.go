@@ -19,7 +19,6 @@ package standard
 
 import (
 	"crypto/tls"
-	"fmt"
 	"github.com/aacfactory/errors"
 	"github.com/aacfactory/fns/commons/bytex"
 	"github.com/aacfactory/fns/context"
@@ -32,14 +31,14 @@ import (
 	"time"
 )
 
-func newServer(log logs.Logger, port int, tlsConfig ssl.Config, config *Config, handler transports.Handler) (srv *Server, err error) {
+func newServer(log logs.Logger, network string, address string, port int, tlsConfig ssl.Config, config *Config, handler transports.Handler) (srv *Server, err error) {
 	var srvTLS *tls.Config
 	var lnf ssl.ListenerFunc
 	if tlsConfig != nil {
 		srvTLS, lnf = tlsConfig.Server()
 	}
 
-	maxRequestHeaderSize := uint64(0)
+	maxRequestHeaderSize := uint64(transports.DefaultMaxRequestHeaderSize)
 	if config.MaxRequestHeaderSize != "" {
 		maxRequestHeaderSize, err = bytex.ParseBytes(strings.TrimSpace(config.MaxRequestHeaderSize))
 		if err != nil {
@@ -47,6 +46,10 @@ func newServer(log logs.Logger, port int, tlsConfig ssl.Config, config *Config,
 			return
 		}
 	}
+	maxRequestHeaderCount := config.MaxRequestHeaderCount
+	if maxRequestHeaderCount < 1 {
+		maxRequestHeaderCount = transports.DefaultMaxRequestHeaderCount
+	}
 	maxRequestBodySize := uint64(0)
 	if config.MaxRequestBodySize != "" {
 		maxRequestBodySize, err = bytex.ParseBytes(strings.TrimSpace(config.MaxRequestBodySize))
@@ -91,9 +94,10 @@ func newServer(log logs.Logger, port int, tlsConfig ssl.Config, config *Config,
 		}
 	}
 
+	connMetrics := transports.ConnMetricsOf(address)
 	server := &http.Server{
-		Addr:                         fmt.Sprintf(":%d", port),
-		Handler:                      HttpTransportHandlerAdaptor(handler, int(maxRequestBodySize), writeTimeout),
+		Addr:                         address,
+		Handler:                      headerGuard(HttpTransportHandlerAdaptor(handler, int(maxRequestBodySize), writeTimeout), maxRequestHeaderCount),
 		DisableGeneralOptionsHandler: false,
 		TLSConfig:                    srvTLS,
 		ReadTimeout:                  readTimeout,
@@ -102,26 +106,53 @@ func newServer(log logs.Logger, port int, tlsConfig ssl.Config, config *Config,
 		IdleTimeout:                  idleTimeout,
 		MaxHeaderBytes:               int(maxRequestHeaderSize),
 		ErrorLog:                     logs.ConvertToStandardLogger(log, logs.DebugLevel, false),
+		ConnState:                    connState(connMetrics),
 	}
 
 	srv = &Server{
-		port: port,
-		lnf:  lnf,
-		srv:  server,
+		port:    port,
+		network: network,
+		lnf:     lnf,
+		srv:     server,
+		conns:   connMetrics,
 	}
 	return
 }
 
+func connState(metrics *transports.ConnMetrics) func(conn net.Conn, state http.ConnState) {
+	return func(conn net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			metrics.SetState(conn, transports.ConnNew)
+			break
+		case http.StateActive:
+			metrics.SetState(conn, transports.ConnActive)
+			break
+		case http.StateIdle:
+			metrics.SetState(conn, transports.ConnIdle)
+			break
+		case http.StateHijacked:
+			metrics.SetState(conn, transports.ConnHijacked)
+			break
+		case http.StateClosed:
+			metrics.SetState(conn, transports.ConnClosed)
+			break
+		}
+	}
+}
+
 type Server struct {
-	port int
-	lnf  ssl.ListenerFunc
-	srv  *http.Server
+	port    int
+	network string
+	lnf     ssl.ListenerFunc
+	srv     *http.Server
+	conns   *transports.ConnMetrics
 }
 
 func (srv *Server) ListenAndServe() (err error) {
-	ln, lnErr := net.Listen("tcp", fmt.Sprintf(":%d", srv.port))
+	ln, lnErr := net.Listen(srv.network, srv.srv.Addr)
 	if lnErr != nil {
-		err = errors.Warning("fns: transport listen and serve failed").WithCause(lnErr)
+		err = errors.Warning("fns: transport listen and serve failed").WithCause(lnErr).WithMeta("network", srv.network).WithMeta("address", srv.srv.Addr)
 		return
 	}
 	if srv.lnf != nil {
@@ -140,6 +171,7 @@ func (srv *Server) ListenAndServe() (err error) {
 }
 
 func (srv *Server) Shutdown(ctx context.Context) (err error) {
+	srv.conns.ShuttingDown()
 	err = srv.srv.Shutdown(ctx)
 	if err != nil {
 		err = errors.Warning("fns: transport shutdown failed").WithCause(err).WithMeta("transport", transportName)
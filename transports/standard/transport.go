@@ -25,17 +25,23 @@ import (
 )
 
 const (
-	transportName = "standard"
+	transportName      = "standard"
+	transportAliasName = "nethttp"
 )
 
 type Config struct {
-	MaxRequestHeaderSize string        `json:"maxRequestHeaderSize"`
-	MaxRequestBodySize   string        `json:"maxRequestBodySize"`
-	ReadTimeout          string        `json:"readTimeout"`
-	ReadHeaderTimeout    string        `json:"readHeaderTimeout"`
-	WriteTimeout         string        `json:"writeTimeout"`
-	IdleTimeout          string        `json:"idleTimeout"`
-	Client               *ClientConfig `json:"client"`
+	// MaxRequestHeaderSize
+	// bytes format, default is 32KB, requests with larger header are answered with 431.
+	MaxRequestHeaderSize string `json:"maxRequestHeaderSize"`
+	// MaxRequestHeaderCount
+	// default is 100, requests with more header fields are answered with 431.
+	MaxRequestHeaderCount int           `json:"maxRequestHeaderCount"`
+	MaxRequestBodySize    string        `json:"maxRequestBodySize"`
+	ReadTimeout           string        `json:"readTimeout"`
+	ReadHeaderTimeout     string        `json:"readHeaderTimeout"`
+	WriteTimeout          string        `json:"writeTimeout"`
+	IdleTimeout           string        `json:"idleTimeout"`
+	Client                *ClientConfig `json:"client"`
 }
 
 func (config *Config) ClientConfig() *ClientConfig {
@@ -45,6 +51,11 @@ func (config *Config) ClientConfig() *ClientConfig {
 	return config.Client
 }
 
+func init() {
+	transports.Register(transportName, New)
+	transports.Register(transportAliasName, New)
+}
+
 func New() transports.Transport {
 	return &Transport{}
 }
@@ -80,6 +91,11 @@ func (tr *Transport) Construct(options transports.Options) (err error) {
 		err = errors.Warning("fns: standard transport construct failed").WithCause(portErr).WithMeta("transport", transportName)
 		return
 	}
+	network, address, addressErr := options.Config.GetListenAddress()
+	if addressErr != nil {
+		err = errors.Warning("fns: standard transport construct failed").WithCause(addressErr).WithMeta("transport", transportName)
+		return
+	}
 	// config
 	optConfig, optConfigErr := options.Config.OptionsConfig()
 	if optConfigErr != nil {
@@ -93,7 +109,7 @@ func (tr *Transport) Construct(options transports.Options) (err error) {
 		return
 	}
 	// server
-	srv, srvErr := newServer(log, port, tlsConfig, config, options.Handler)
+	srv, srvErr := newServer(log, network, address, port, tlsConfig, config, options.Handler)
 	if srvErr != nil {
 		err = errors.Warning("fns: standard transport construct failed").WithCause(srvErr).WithMeta("transport", transportName)
 		return